@@ -13,8 +13,8 @@ import (
 var DB *sql.DB
 
 func Load() error {
-	appDir := settings.AppDir
-	dbPath := filepath.Join(appDir, "app.db")
+	networkDir := settings.NetworkDir
+	dbPath := filepath.Join(networkDir, "app.db")
 
 	firstLoad := false
 	_, err := os.Stat(dbPath)
@@ -46,6 +46,11 @@ func Load() error {
 		return err
 	}
 
+	err = initDatabaseXSWDApps()
+	if err != nil {
+		return err
+	}
+
 	err = delWalletInfoIfNoFolder()
 	if err != nil {
 		fmt.Println(err)
@@ -0,0 +1,741 @@
+package app_db
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/secretsystems/secret-wallet/app_db/schema_version"
+	"github.com/secretsystems/secret-wallet/mock_daemon"
+	"github.com/secretsystems/secret-wallet/settings"
+
+	_ "modernc.org/sqlite"
+)
+
+func setupAppDir(t *testing.T) {
+	appDir := t.TempDir()
+	settings.AppDir = appDir
+	settings.NetworkDir = appDir
+	settings.WalletsDir = filepath.Join(appDir, "wallets")
+}
+
+func loadDB(t *testing.T) {
+	t.Helper()
+
+	err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { DB.Close() })
+}
+
+func checkVersion(t *testing.T, schema string, expected int) {
+	t.Helper()
+
+	version, err := schema_version.GetVersion(DB, schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if version != expected {
+		t.Fatalf("%s schema version is %d instead of %d", schema, version, expected)
+	}
+}
+
+func checkNodes(t *testing.T, endpoints ...string) {
+	t.Helper()
+
+	nodes, err := GetNodeConnections()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(nodes) != len(endpoints) {
+		t.Fatalf("%d nodes instead of %d", len(nodes), len(endpoints))
+	}
+
+	for i, node := range nodes {
+		if node.Endpoint != endpoints[i] || node.OrderNumber != i {
+			t.Fatalf("node %d is %s with order %d", i, node.Endpoint, node.OrderNumber)
+		}
+	}
+}
+
+func trustedEndpoints() []string {
+	var endpoints []string
+	for _, node := range TRUSTED_NODE_CONNECTIONS {
+		endpoints = append(endpoints, node.Endpoint)
+	}
+
+	return endpoints
+}
+
+func TestLoadNewDatabase(t *testing.T) {
+	setupAppDir(t)
+	loadDB(t)
+
+	checkVersion(t, "nodes", 1)
+	checkVersion(t, "wallets", 2)
+	checkVersion(t, "xswd_apps", 1)
+	checkNodes(t, trustedEndpoints()...)
+
+	wallets, err := GetWallets()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(wallets) != 0 {
+		t.Fatalf("%d wallets in a new database", len(wallets))
+	}
+}
+
+func TestLoadNetworkDatabase(t *testing.T) {
+	setupAppDir(t)
+	settings.App.Network = settings.NetworkTestnet
+	defer func() { settings.App.Network = "" }()
+
+	loadDB(t)
+	checkNodes(t, TESTNET_NODE_CONNECTIONS[0].Endpoint)
+}
+
+func TestLoadExistingDatabase(t *testing.T) {
+	setupAppDir(t)
+	loadDB(t)
+
+	err := DelNodeConnection(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	DB.Close()
+
+	// migrations already ran and the nodes are not reset
+	loadDB(t)
+	checkVersion(t, "nodes", 1)
+	checkVersion(t, "wallets", 2)
+	checkNodes(t, trustedEndpoints()[1:]...)
+}
+
+func TestMigrateNodesVersion0(t *testing.T) {
+	setupAppDir(t)
+
+	// nodes table created before the schema versions and the order column
+	db, err := sql.Open("sqlite", filepath.Join(settings.NetworkDir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE nodes (
+			id INTEGER PRIMARY KEY,
+			endpoint VARCHAR,
+			name VARCHAR
+		);
+
+		INSERT INTO nodes (endpoint, name) VALUES
+		('ws://node1:10102/ws', 'Node 1'),
+		('ws://node2:10102/ws', 'Node 2');
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	loadDB(t)
+	checkVersion(t, "nodes", 1)
+	checkNodes(t, "ws://node1:10102/ws", "ws://node2:10102/ws")
+}
+
+func TestMigrateWalletsVersion1(t *testing.T) {
+	setupAppDir(t)
+
+	// wallets table before the watch-only column
+	db, err := sql.Open("sqlite", filepath.Join(settings.NetworkDir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = schema_version.Init(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addr := mock_daemon.RandomAddress()
+	_, err = db.Exec(`
+		CREATE TABLE wallets (
+			addr VARCHAR PRIMARY KEY,
+			name VARCHAR NOT NULL,
+			registration_tx_hex VARCHAR NOT NULL,
+			timestamp BIGINT NOT NULL,
+			order_number INT NOT NULL
+		);
+
+		INSERT INTO wallets (addr, name, registration_tx_hex, timestamp, order_number) VALUES
+		(?, 'old wallet', '', 1690000000, 0);
+	`, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = schema_version.StoreVersion(db, "wallets", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	err = os.MkdirAll(filepath.Join(settings.WalletsDir, addr), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loadDB(t)
+	checkVersion(t, "wallets", 2)
+
+	info, err := GetWalletInfo(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Name != "old wallet" || info.WatchOnly {
+		t.Fatalf("unexpected wallet info %+v", info)
+	}
+
+	watchAddr := mock_daemon.RandomAddress()
+	err = InsertWalletInfo(WalletInfo{Addr: watchAddr, Name: "watch", WatchOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err = GetWalletInfo(watchAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !info.WatchOnly {
+		t.Fatal("wallet is not watch-only")
+	}
+}
+
+func TestMigrateJsonWalletsInfo(t *testing.T) {
+	setupAppDir(t)
+
+	addr := mock_daemon.RandomAddress()
+	walletDir := filepath.Join(settings.WalletsDir, addr)
+	err := os.MkdirAll(walletDir, os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(JsonWalletInfo{
+		Name:              "old wallet",
+		Addr:              addr,
+		RegistrationTxHex: "abcd",
+		Timestamp:         1690000000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	infoPath := filepath.Join(walletDir, "info.json")
+	err = os.WriteFile(infoPath, data, os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// not a wallet folder - it is ignored
+	err = os.MkdirAll(filepath.Join(settings.WalletsDir, "not_an_address"), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loadDB(t)
+	checkVersion(t, "wallets", 2)
+
+	info, err := GetWalletInfo(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Name != "old wallet" || info.RegistrationTxHex != "abcd" || info.Timestamp != 1690000000 {
+		t.Fatalf("unexpected wallet info %+v", info)
+	}
+
+	_, err = os.Stat(infoPath)
+	if !os.IsNotExist(err) {
+		t.Fatal("info.json was not removed after migration")
+	}
+
+	wallets, err := GetWallets()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(wallets) != 1 {
+		t.Fatalf("%d wallets instead of 1", len(wallets))
+	}
+}
+
+func TestDelWalletInfoIfNoFolder(t *testing.T) {
+	setupAppDir(t)
+	loadDB(t)
+
+	addr := mock_daemon.RandomAddress()
+	err := InsertWalletInfo(WalletInfo{Addr: addr, Name: "deleted wallet"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	DB.Close()
+
+	// the wallet folder doesn't exist so the info is removed on load
+	loadDB(t)
+
+	wallets, err := GetWallets()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(wallets) != 0 {
+		t.Fatalf("%d wallets instead of 0", len(wallets))
+	}
+}
+
+func TestXSWDApps(t *testing.T) {
+	setupAppDir(t)
+	loadDB(t)
+
+	app := XSWDApp{
+		ID:          "a1b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7f8a9b0c1d2e3f4a5b6c7d8e9f0a1b2",
+		Name:        "dApp",
+		Description: "test app",
+		Url:         "http://localhost:8080",
+		Origin:      "http://localhost:8080",
+		Permissions: map[string]XSWDPermission{
+			"GetAddress": XSWD_PERMISSION_ALWAYS,
+			"Transfer":   XSWD_PERMISSION_DENY,
+		},
+		Timestamp:         1,
+		LastSeenTimestamp: 1,
+	}
+
+	err := StoreXSWDApp(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = SetXSWDPermission(app.ID, "Transfer", XSWD_PERMISSION_ASK)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = SetXSWDPermission(app.ID, "QueryKey", XSWD_PERMISSION_DENY)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = UpdateXSWDAppLastSeen(app.ID, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stored, found, err := GetXSWDApp(app.ID)
+	if err != nil || !found {
+		t.Fatalf("app not found %v", err)
+	}
+
+	if stored.Name != app.Name || stored.Url != app.Url || stored.Origin != app.Origin || stored.LastSeenTimestamp != 2 ||
+		stored.Permission("GetAddress") != XSWD_PERMISSION_ALWAYS ||
+		stored.Permission("Transfer") != XSWD_PERMISSION_ASK ||
+		stored.Permission("QueryKey") != XSWD_PERMISSION_DENY ||
+		stored.Permission("GetBalance") != XSWD_PERMISSION_ASK {
+		t.Fatalf("unexpected app %+v", stored)
+	}
+
+	// storing again replaces the permissions
+	app.Permissions = nil
+	err = StoreXSWDApp(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	apps, err := GetXSWDApps()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(apps) != 1 || len(apps[0].Permissions) != 0 {
+		t.Fatalf("unexpected apps %+v", apps)
+	}
+
+	err = DelXSWDApp(app.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, found, err = GetXSWDApp(app.ID)
+	if err != nil || found {
+		t.Fatalf("app was not deleted %v", err)
+	}
+}
+
+func createTestWalletFiles(t *testing.T, addr string, contacts ...string) {
+	t.Helper()
+
+	dir := filepath.Join(settings.WalletsDir, addr)
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "wallet.db"), []byte(addr), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = InsertWalletInfo(WalletInfo{Addr: addr, Name: addr})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addTestContacts(t, addr, contacts...)
+}
+
+func addTestContacts(t *testing.T, addr string, contacts ...string) {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", filepath.Join(settings.WalletsDir, addr, "data.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS contacts (addr VARCHAR PRIMARY KEY, name VARCHAR NOT NULL);`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, contact := range contacts {
+		_, err = db.Exec(`INSERT INTO contacts (addr,name) VALUES (?,?);`, contact, contact)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func countTestContacts(t *testing.T, addr string) int {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", filepath.Join(settings.WalletsDir, addr, "data.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var count int
+	err = db.QueryRow(`SELECT COUNT(*) FROM contacts;`).Scan(&count)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return count
+}
+
+func findBackupChange(t *testing.T, changes []BackupChange, name string) BackupChange {
+	t.Helper()
+
+	for _, change := range changes {
+		if change.Name == name {
+			return change
+		}
+	}
+
+	t.Fatalf("no change for %s in %+v", name, changes)
+	return BackupChange{}
+}
+
+func TestBackup(t *testing.T) {
+	setupAppDir(t)
+	loadDB(t)
+
+	appSettings := settings.App
+	t.Cleanup(func() { settings.App = appSettings })
+
+	err := os.WriteFile(filepath.Join(settings.AppDir, "settings.json"), []byte(`{"language":"en","theme":"dark","network":"mainnet"}`), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	walletA := mock_daemon.RandomAddress()
+	createTestWalletFiles(t, walletA, "contact1", "contact2")
+
+	err = StoreXSWDApp(XSWDApp{ID: "app1", Name: "app1", Permissions: map[string]XSWDPermission{"GetAddress": XSWD_PERMISSION_ALWAYS}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var archive bytes.Buffer
+	_, err = CreateBackup(&archive, "backup password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = OpenBackup(bytes.NewReader(archive.Bytes()), "wrong password")
+	if err != ErrBackupPassword {
+		t.Fatalf("expected a password error got %v", err)
+	}
+
+	backup, err := OpenBackup(bytes.NewReader(archive.Bytes()), "backup password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(backup.Files()) != 4 {
+		t.Fatalf("unexpected backup files %+v", backup.Files())
+	}
+
+	// changed after the backup
+	err = DelXSWDApp("app1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = StoreXSWDApp(XSWDApp{ID: "app2", Name: "app2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addTestContacts(t, walletA, "contact3")
+	walletB := mock_daemon.RandomAddress()
+	createTestWalletFiles(t, walletB, "contact4")
+
+	changes, err := backup.Preview(BACKUP_MODE_MERGE)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dataName := "wallets/" + walletA + "/data.db"
+	if findBackupChange(t, changes, "settings.json").Action != BACKUP_ACTION_KEEP ||
+		findBackupChange(t, changes, "wallets/"+walletA+"/wallet.db").Action != BACKUP_ACTION_KEEP ||
+		findBackupChange(t, changes, dataName).Action != BACKUP_ACTION_MERGE ||
+		findBackupChange(t, changes, dataName).Rows != 0 {
+		t.Fatalf("unexpected merge preview %+v", changes)
+	}
+
+	// app1 and its permission
+	appChange := findBackupChange(t, changes, "app.db")
+	if appChange.Action != BACKUP_ACTION_MERGE || appChange.Rows != 2 {
+		t.Fatalf("unexpected app.db change %+v", appChange)
+	}
+
+	// the preview doesn't change anything
+	_, found, err := GetXSWDApp("app1")
+	if err != nil || found {
+		t.Fatalf("app1 was restored by the preview %v", err)
+	}
+
+	err = backup.Restore(BACKUP_MODE_MERGE)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	apps, err := GetXSWDApps()
+	if err != nil || len(apps) != 2 {
+		t.Fatalf("unexpected merged apps %+v %v", apps, err)
+	}
+
+	if countTestContacts(t, walletA) != 3 || countTestContacts(t, walletB) != 1 {
+		t.Fatal("unexpected merged contacts")
+	}
+
+	changes, err = backup.Preview(BACKUP_MODE_REPLACE)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	removed := findBackupChange(t, changes, "wallets/"+walletB+"/wallet.db")
+	if removed.Action != BACKUP_ACTION_REMOVE || findBackupChange(t, changes, "app.db").Action != BACKUP_ACTION_REPLACE {
+		t.Fatalf("unexpected replace preview %+v", changes)
+	}
+
+	err = backup.Restore(BACKUP_MODE_REPLACE)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	apps, err = GetXSWDApps()
+	if err != nil || len(apps) != 1 || apps[0].ID != "app1" {
+		t.Fatalf("unexpected replaced apps %+v %v", apps, err)
+	}
+
+	if countTestContacts(t, walletA) != 2 {
+		t.Fatal("unexpected replaced contacts")
+	}
+
+	_, err = os.Stat(filepath.Join(settings.WalletsDir, walletB))
+	if !os.IsNotExist(err) {
+		t.Fatal("wallet missing from the backup was not removed")
+	}
+
+	wallets, err := GetWallets()
+	if err != nil || len(wallets) != 1 || wallets[0].Addr != walletA {
+		t.Fatalf("unexpected wallets %+v %v", wallets, err)
+	}
+}
+
+func TestParseBackupName(t *testing.T) {
+	for name, expected := range map[string]bool{
+		"settings.json":                      true,
+		"app.db":                             true,
+		"testnet/app.db":                     true,
+		"wallets/dero1abc/wallet.db":         true,
+		"simulator/wallets/dero1abc/data.db": true,
+		"wallets/dero1abc/other.db":          false,
+		"wallets//wallet.db":                 false,
+		"wallets/../wallet.db":               false,
+		"wallets/./data.db":                  false,
+		`wallets/..\..\..\x/wallet.db`:       false,
+		`wallets/C:\x/wallet.db`:             false,
+		"wallets/dero1abc/sub/wallet.db":     false,
+		"../app.db":                          false,
+	} {
+		_, ok := ParseBackupName(name)
+		if ok != expected {
+			t.Fatalf("%s is parsed %v instead of %v", name, ok, expected)
+		}
+	}
+}
+
+func createTestTokenDB(t *testing.T, dbPath string, statements ...string) []byte {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	statements = append([]string{`
+		CREATE TABLE token_folders (id INTEGER PRIMARY KEY AUTOINCREMENT, name VARCHAR NOT NULL, parent_id INTEGER);
+		CREATE TABLE tokens (id INTEGER PRIMARY KEY AUTOINCREMENT, sc_id VARCHAR NOT NULL, folder_id INTEGER);
+		CREATE TABLE sc_watchlist (id INTEGER PRIMARY KEY AUTOINCREMENT, sc_id VARCHAR, key VARCHAR, uint64_key BOOLEAN, UNIQUE (sc_id,key,uint64_key));
+		CREATE TABLE sc_watchlist_changes (id INTEGER PRIMARY KEY AUTOINCREMENT, watch_id INTEGER, new_value VARCHAR, timestamp BIGINT);
+		CREATE TABLE invoices (id INTEGER PRIMARY KEY, dst_port VARCHAR UNIQUE);
+	`}, statements...)
+
+	for _, statement := range statements {
+		_, err = db.Exec(statement)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+// the ids of the backup are also used by other rows on this device
+func TestMergeBackupIds(t *testing.T) {
+	setupAppDir(t)
+	dir := t.TempDir()
+
+	backup := createTestTokenDB(t, filepath.Join(dir, "backup.db"), `
+		INSERT INTO token_folders (id,name,parent_id) VALUES (1,'games',NULL), (2,'cards',1), (3,'shared',NULL);
+		INSERT INTO tokens (id,sc_id,folder_id) VALUES (1,'sc1',2), (2,'sc2',3), (3,'sc3',9);
+		INSERT INTO sc_watchlist (id,sc_id,key,uint64_key) VALUES (1,'sc1','owner',false), (2,'sc4','owner',false);
+		INSERT INTO sc_watchlist_changes (id,watch_id,new_value,timestamp) VALUES (1,1,'a',1), (2,2,'b',2);
+		INSERT INTO invoices (id,dst_port) VALUES (1,'100'), (2,'200');
+	`)
+
+	localPath := filepath.Join(dir, "local.db")
+	createTestTokenDB(t, localPath, `
+		INSERT INTO token_folders (id,name,parent_id) VALUES (1,'shared',NULL), (2,'local',NULL);
+		INSERT INTO tokens (id,sc_id,folder_id) VALUES (1,'sc2',1), (2,'local',2);
+		INSERT INTO sc_watchlist (id,sc_id,key,uint64_key) VALUES (1,'sc4','owner',false);
+		INSERT INTO sc_watchlist_changes (id,watch_id,new_value,timestamp) VALUES (1,1,'b',2);
+		INSERT INTO invoices (id,dst_port) VALUES (1,'200');
+	`)
+
+	// 2 folders, 1 token, 1 watch, 1 change and 1 invoice - sc3 is in a folder missing from the backup
+	preview, err := mergeBackupDB(localPath, backup, false)
+	if err != nil || preview != 6 {
+		t.Fatalf("unexpected preview %d %v", preview, err)
+	}
+
+	rows, err := mergeBackupDB(localPath, backup, true)
+	if err != nil || rows != 6 {
+		t.Fatalf("unexpected merge %d %v", rows, err)
+	}
+
+	// the plain copies are removed from the app dir
+	entries, err := os.ReadDir(settings.AppDir)
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("unexpected app dir files %+v %v", entries, err)
+	}
+
+	// merging again adds nothing
+	rows, err = mergeBackupDB(localPath, backup, true)
+	if err != nil || rows != 0 {
+		t.Fatalf("unexpected second merge %d %v", rows, err)
+	}
+
+	db, err := sql.Open("sqlite", localPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	queryString := func(query string) string {
+		t.Helper()
+
+		var value string
+		err := db.QueryRow(query).Scan(&value)
+		if err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+
+		return value
+	}
+
+	paths := map[string]string{
+		"sc1":   "games/cards",
+		"sc2":   "shared",
+		"local": "local",
+	}
+
+	for scId, path := range paths {
+		folderPath := queryString(`
+			WITH RECURSIVE path (id, parent_id, name) AS (
+				SELECT f.id, f.parent_id, f.name FROM tokens t JOIN token_folders f ON f.id = t.folder_id WHERE t.sc_id = '` + scId + `'
+				UNION ALL
+				SELECT f.id, f.parent_id, f.name || '/' || path.name FROM token_folders f JOIN path ON f.id = path.parent_id
+			)
+			SELECT name FROM path WHERE parent_id IS NULL;
+		`)
+
+		if folderPath != path {
+			t.Fatalf("%s is in %s instead of %s", scId, folderPath, path)
+		}
+	}
+
+	if queryString(`SELECT COUNT(*) FROM tokens;`) != "3" || queryString(`SELECT COUNT(*) FROM token_folders;`) != "4" {
+		t.Fatal("unexpected tokens")
+	}
+
+	changes := queryString(`
+		SELECT group_concat(w.sc_id || ':' || c.new_value) FROM sc_watchlist_changes c
+		JOIN sc_watchlist w ON w.id = c.watch_id ORDER BY c.id;
+	`)
+	if changes != "sc4:b,sc1:a" {
+		t.Fatalf("unexpected watchlist changes %s", changes)
+	}
+
+	if queryString(`SELECT group_concat(dst_port) FROM invoices ORDER BY id;`) != "200,100" {
+		t.Fatal("unexpected invoices")
+	}
+}
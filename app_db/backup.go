@@ -0,0 +1,840 @@
+package app_db
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/secretsystems/secret-wallet/settings"
+	"golang.org/x/crypto/scrypt"
+)
+
+// header of the archive - the salt and nonce follow the version byte
+const BACKUP_MAGIC = "SWBACKUP"
+const BACKUP_VERSION = 1
+
+// file extension used when the archive is saved
+const BACKUP_EXT = ".swbackup"
+
+const backupInfoName = "backup.json"
+
+var ErrBackupPassword = errors.New("invalid password or corrupted backup")
+
+type BackupMode int
+
+const (
+	// adds what is missing and keeps the local rows, settings and wallet files
+	BACKUP_MODE_MERGE BackupMode = iota
+	// the networks in the backup become exactly what it contains
+	BACKUP_MODE_REPLACE
+)
+
+type BackupAction string
+
+const (
+	BACKUP_ACTION_ADD     BackupAction = "add"
+	BACKUP_ACTION_REPLACE BackupAction = "replace"
+	BACKUP_ACTION_MERGE   BackupAction = "merge"
+	BACKUP_ACTION_KEEP    BackupAction = "keep"
+	BACKUP_ACTION_REMOVE  BackupAction = "remove"
+)
+
+type BackupInfo struct {
+	Timestamp  int64  `json:"timestamp"`
+	AppVersion string `json:"app_version"`
+}
+
+// one file of the app state - wallet is empty for settings.json and app.db
+type BackupFile struct {
+	Name    string
+	Network string
+	Wallet  string
+	File    string
+}
+
+// what a restore will do to a file - rows is the number of rows a merge adds
+type BackupChange struct {
+	BackupFile
+	Action BackupAction
+	Rows   int64
+}
+
+// decrypted archive
+type Backup struct {
+	Info  BackupInfo
+	files map[string][]byte
+}
+
+// settings.json, then app.db and the wallet files of every network
+func ParseBackupName(name string) (file BackupFile, ok bool) {
+	file.Name = name
+	if name == "settings.json" {
+		file.File = name
+		return file, true
+	}
+
+	parts := strings.Split(name, "/")
+	file.Network = settings.NetworkMainnet
+	for _, network := range settings.Networks {
+		if network != settings.NetworkMainnet && parts[0] == network {
+			file.Network = network
+			parts = parts[1:]
+			break
+		}
+	}
+
+	switch {
+	case len(parts) == 1 && parts[0] == "app.db":
+		file.File = parts[0]
+		return file, true
+	case len(parts) == 3 && parts[0] == "wallets" && (parts[2] == "wallet.db" || parts[2] == "data.db"):
+		// the name is joined to the wallets dir on every os - a \ is a separator on windows
+		wallet := parts[1]
+		if wallet == "." || filepath.Base(wallet) != wallet || strings.Contains(wallet, `\`) || !filepath.IsLocal(wallet) {
+			return file, false
+		}
+
+		file.Wallet = wallet
+		file.File = parts[2]
+		return file, true
+	}
+
+	return file, false
+}
+
+func backupName(network string, wallet string, file string) string {
+	var parts []string
+	if network != settings.NetworkMainnet {
+		parts = append(parts, network)
+	}
+
+	if wallet != "" {
+		parts = append(parts, "wallets", wallet)
+	}
+
+	return path.Join(append(parts, file)...)
+}
+
+func (f BackupFile) localPath() string {
+	if f.Network == "" {
+		return filepath.Join(settings.AppDir, f.File)
+	}
+
+	dir := settings.GetNetworkDir(f.Network)
+	if f.Wallet != "" {
+		return filepath.Join(dir, "wallets", f.Wallet, f.File)
+	}
+
+	return filepath.Join(dir, f.File)
+}
+
+func (f BackupFile) isDB() bool {
+	return f.File == "app.db" || f.File == "data.db"
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// files of the app state that exist on this device
+func localBackupFiles() ([]BackupFile, error) {
+	var files []BackupFile
+	add := func(network string, wallet string, file string) {
+		f, _ := ParseBackupName(backupName(network, wallet, file))
+		if fileExists(f.localPath()) {
+			files = append(files, f)
+		}
+	}
+
+	add("", "", "settings.json")
+
+	for _, network := range settings.Networks {
+		add(network, "", "app.db")
+
+		entries, err := os.ReadDir(filepath.Join(settings.GetNetworkDir(network), "wallets"))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() {
+				add(network, entry.Name(), "wallet.db")
+				add(network, entry.Name(), "data.db")
+			}
+		}
+	}
+
+	return files, nil
+}
+
+// the snapshots are not encrypted - they stay in the app dir and only the user can read them
+const backupFilePerm = 0600
+const backupDirPerm = 0700
+
+func backupTempDir(pattern string) (string, error) {
+	return os.MkdirTemp(settings.AppDir, pattern)
+}
+
+// copy of a sqlite file that is consistent even if the database is opened
+func snapshotDB(dbPath string) ([]byte, error) {
+	dir, err := backupTempDir("backup-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	snapshotPath := filepath.Join(dir, "snapshot.db")
+	_, err = db.Exec(`VACUUM INTO ?;`, snapshotPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.ReadFile(snapshotPath)
+}
+
+func backupKey(password string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(password), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// zip of the app state encrypted with a key derived from the password
+func CreateBackup(w io.Writer, password string) (info BackupInfo, err error) {
+	if password == "" {
+		err = fmt.Errorf("a password is required")
+		return
+	}
+
+	files, err := localBackupFiles()
+	if err != nil {
+		return
+	}
+
+	info = BackupInfo{Timestamp: time.Now().Unix(), AppVersion: settings.Version}
+
+	var archive bytes.Buffer
+	zipWriter := zip.NewWriter(&archive)
+
+	infoWriter, err := zipWriter.Create(backupInfoName)
+	if err != nil {
+		return
+	}
+
+	err = json.NewEncoder(infoWriter).Encode(info)
+	if err != nil {
+		return
+	}
+
+	for _, file := range files {
+		var data []byte
+		if file.isDB() {
+			data, err = snapshotDB(file.localPath())
+		} else {
+			data, err = os.ReadFile(file.localPath())
+		}
+
+		if err != nil {
+			return
+		}
+
+		var fileWriter io.Writer
+		fileWriter, err = zipWriter.Create(file.Name)
+		if err != nil {
+			return
+		}
+
+		_, err = fileWriter.Write(data)
+		if err != nil {
+			return
+		}
+	}
+
+	err = zipWriter.Close()
+	if err != nil {
+		return
+	}
+
+	header := make([]byte, len(BACKUP_MAGIC)+1+16)
+	copy(header, BACKUP_MAGIC)
+	header[len(BACKUP_MAGIC)] = BACKUP_VERSION
+	salt := header[len(BACKUP_MAGIC)+1:]
+	_, err = rand.Read(salt)
+	if err != nil {
+		return
+	}
+
+	aead, err := backupKey(password, salt)
+	if err != nil {
+		return
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return
+	}
+
+	encrypted := aead.Seal(nil, nonce, archive.Bytes(), header)
+	for _, data := range [][]byte{header, nonce, encrypted} {
+		_, err = w.Write(data)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func OpenBackup(r io.Reader, password string) (*Backup, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	headerSize := len(BACKUP_MAGIC) + 1 + 16
+	if len(data) < headerSize || string(data[:len(BACKUP_MAGIC)]) != BACKUP_MAGIC {
+		return nil, fmt.Errorf("not a backup file")
+	}
+
+	version := data[len(BACKUP_MAGIC)]
+	if version != BACKUP_VERSION {
+		return nil, fmt.Errorf("unsupported backup version %d", version)
+	}
+
+	header := data[:headerSize]
+	aead, err := backupKey(password, header[len(BACKUP_MAGIC)+1:])
+	if err != nil {
+		return nil, err
+	}
+
+	data = data[headerSize:]
+	if len(data) < aead.NonceSize() {
+		return nil, ErrBackupPassword
+	}
+
+	archive, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], header)
+	if err != nil {
+		return nil, ErrBackupPassword
+	}
+
+	zipReader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return nil, err
+	}
+
+	backup := &Backup{files: make(map[string][]byte)}
+	for _, zipFile := range zipReader.File {
+		reader, err := zipFile.Open()
+		if err != nil {
+			return nil, err
+		}
+
+		fileData, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		if zipFile.Name == backupInfoName {
+			err = json.Unmarshal(fileData, &backup.Info)
+			if err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		// unknown names are ignored so a file can't be written outside of the app dir
+		_, ok := ParseBackupName(zipFile.Name)
+		if ok {
+			backup.files[zipFile.Name] = fileData
+		}
+	}
+
+	return backup, nil
+}
+
+// files in the archive sorted by name
+func (b *Backup) Files() []BackupFile {
+	var files []BackupFile
+	for name := range b.files {
+		file, _ := ParseBackupName(name)
+		files = append(files, file)
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name < files[j].Name
+	})
+
+	return files
+}
+
+func (b *Backup) networks() map[string]bool {
+	networks := make(map[string]bool)
+	for _, file := range b.Files() {
+		if file.Network != "" {
+			networks[file.Network] = true
+		}
+	}
+
+	return networks
+}
+
+// rows a merge adds are counted on a copy of the local database
+func (b *Backup) Preview(mode BackupMode) ([]BackupChange, error) {
+	return b.changes(mode, false)
+}
+
+// the wallet must be closed - app.db is closed during the restore and loaded again even if it fails
+func (b *Backup) Restore(mode BackupMode) error {
+	if DB != nil {
+		DB.Close()
+	}
+
+	err := b.restore(mode)
+	if err != nil {
+		Load()
+		return err
+	}
+
+	return Load()
+}
+
+func (b *Backup) restore(mode BackupMode) error {
+	changes, err := b.changes(mode, true)
+	if err != nil {
+		return err
+	}
+
+	for _, change := range changes {
+		if change.File == "settings.json" && change.Action != BACKUP_ACTION_KEEP {
+			return settings.Reload()
+		}
+	}
+
+	return nil
+}
+
+func (b *Backup) changes(mode BackupMode, apply bool) ([]BackupChange, error) {
+	localFiles, err := localBackupFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []BackupChange
+
+	// replaced networks lose the wallets missing from the backup
+	if mode == BACKUP_MODE_REPLACE {
+		networks := b.networks()
+		removed := make(map[string]bool)
+		for _, file := range localFiles {
+			_, inBackup := b.files[file.Name]
+			if !networks[file.Network] || inBackup || file.Wallet == "" {
+				continue
+			}
+
+			walletName := backupName(file.Network, file.Wallet, "")
+			_, walletInBackup := b.files[backupName(file.Network, file.Wallet, "wallet.db")]
+			if walletInBackup || removed[walletName] {
+				continue
+			}
+
+			removed[walletName] = true
+			changes = append(changes, BackupChange{BackupFile: file, Action: BACKUP_ACTION_REMOVE})
+			if apply {
+				err = os.RemoveAll(filepath.Dir(file.localPath()))
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	for _, file := range b.Files() {
+		change := BackupChange{BackupFile: file}
+		data := b.files[file.Name]
+		exists := fileExists(file.localPath())
+
+		switch {
+		case !exists:
+			change.Action = BACKUP_ACTION_ADD
+		case mode == BACKUP_MODE_REPLACE:
+			change.Action = BACKUP_ACTION_REPLACE
+		case file.isDB():
+			change.Action = BACKUP_ACTION_MERGE
+		default:
+			change.Action = BACKUP_ACTION_KEEP
+		}
+
+		switch change.Action {
+		case BACKUP_ACTION_ADD, BACKUP_ACTION_REPLACE:
+			if apply {
+				err = writeBackupFile(file.localPath(), data)
+			}
+		case BACKUP_ACTION_MERGE:
+			change.Rows, err = mergeBackupDB(file.localPath(), data, apply)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file.Name, err)
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+// written next to the file first so a failed write doesn't leave half a file
+func writeBackupFile(filePath string, data []byte) error {
+	err := os.MkdirAll(filepath.Dir(filePath), backupDirPerm)
+	if err != nil {
+		return err
+	}
+
+	tmpPath := filePath + ".restore"
+	err = os.WriteFile(tmpPath, data, backupFilePerm)
+	if err != nil {
+		return err
+	}
+
+	// the journal of the replaced database would be applied to the new one
+	os.Remove(filePath + "-journal")
+	return os.Rename(tmpPath, filePath)
+}
+
+// tables with an id given by the database - the same id can be another row on each device so the backup
+// rows are matched on a natural key instead, and inserted with a new id that the referencing columns follow
+type backupIdTable struct {
+	name string
+	// a backup row equal to a local row on these columns is already there
+	key []string
+	// column -> table of the id it references, a row referencing a row missing from the backup is skipped
+	references map[string]string
+}
+
+// ordered so the referenced rows are merged first
+var backupIdTables = []backupIdTable{
+	{name: "nodes", key: []string{"endpoint"}},
+	{name: "token_folders", key: []string{"name", "parent_id"}, references: map[string]string{"parent_id": "token_folders"}},
+	{name: "tokens", key: []string{"sc_id", "folder_id"}, references: map[string]string{"folder_id": "token_folders"}},
+	{name: "sc_watchlist", key: []string{"sc_id", "key", "uint64_key"}},
+	{name: "sc_watchlist_changes", key: []string{"watch_id", "new_value", "timestamp"}, references: map[string]string{"watch_id": "sc_watchlist"}},
+	{name: "rpc_log", key: []string{"timestamp", "client", "method", "tx_id"}},
+	{name: "invoices", key: []string{"dst_port"}},
+}
+
+func quoteColumns(columns []string) string {
+	var quoted []string
+	for _, column := range columns {
+		quoted = append(quoted, fmt.Sprintf(`"%s"`, column))
+	}
+
+	return strings.Join(quoted, ",")
+}
+
+// inserts the rows of the backup that are missing - rows with a key that already exists are kept as they are
+// tables and columns unknown to the local database are skipped, it's migrated when opened
+func mergeBackupDB(dbPath string, data []byte, apply bool) (rows int64, err error) {
+	dir, err := backupTempDir("restore-")
+	if err != nil {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	backupPath := filepath.Join(dir, "backup.db")
+	err = os.WriteFile(backupPath, data, backupFilePerm)
+	if err != nil {
+		return
+	}
+
+	// the preview merges into a copy
+	if !apply {
+		var local []byte
+		local, err = snapshotDB(dbPath)
+		if err != nil {
+			return
+		}
+
+		dbPath = filepath.Join(dir, "local.db")
+		err = os.WriteFile(dbPath, local, backupFilePerm)
+		if err != nil {
+			return
+		}
+	}
+
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return
+	}
+	defer db.Close()
+
+	// attach is per connection
+	db.SetMaxOpenConns(1)
+
+	_, err = db.Exec(`ATTACH DATABASE ? AS backup;`, backupPath)
+	if err != nil {
+		return
+	}
+
+	tables, err := queryStrings(db, `
+		SELECT name FROM backup.sqlite_master
+		WHERE type = 'table' AND name NOT LIKE 'sqlite_%' AND name != 'schema_version';
+	`)
+	if err != nil {
+		return
+	}
+
+	columns := make(map[string][]string)
+	for _, table := range tables {
+		var localColumns, backupColumns []string
+		localColumns, err = queryStrings(db, `SELECT name FROM pragma_table_info(?, 'main');`, table)
+		if err != nil {
+			return
+		}
+
+		backupColumns, err = queryStrings(db, `SELECT name FROM pragma_table_info(?, 'backup');`, table)
+		if err != nil {
+			return
+		}
+
+		inBackup := make(map[string]bool)
+		for _, column := range backupColumns {
+			inBackup[column] = true
+		}
+
+		for _, column := range localColumns {
+			if inBackup[column] {
+				columns[table] = append(columns[table], column)
+			}
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return
+	}
+
+	// backup id -> local id of every merged row
+	ids := make(map[string]map[int64]int64)
+	merged := make(map[string]bool)
+
+	for _, table := range backupIdTables {
+		merged[table.name] = true
+		if len(columns[table.name]) == 0 {
+			continue
+		}
+
+		var count int64
+		count, err = mergeBackupIdTable(tx, table, columns[table.name], ids)
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+
+		rows += count
+	}
+
+	for _, table := range tables {
+		if merged[table] || len(columns[table]) == 0 {
+			continue
+		}
+
+		quoted := quoteColumns(columns[table])
+		var result sql.Result
+		result, err = tx.Exec(fmt.Sprintf(`
+			INSERT OR IGNORE INTO main."%s" (%s)
+			SELECT %s FROM backup."%s";
+		`, table, quoted, quoted, table))
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+
+		var count int64
+		count, err = result.RowsAffected()
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+
+		rows += count
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return
+	}
+
+	_, err = db.Exec(`DETACH DATABASE backup;`)
+	return
+}
+
+// the rows are read in the order of their id so a folder is merged before its subfolders
+func mergeBackupIdTable(tx *sql.Tx, table backupIdTable, columns []string, ids map[string]map[int64]int64) (count int64, err error) {
+	var values []string
+	hasId := false
+	for _, column := range columns {
+		if column == "id" {
+			hasId = true
+		} else {
+			values = append(values, column)
+		}
+	}
+
+	if !hasId {
+		return
+	}
+
+	rows, err := tx.Query(fmt.Sprintf(`SELECT "id",%s FROM backup."%s" ORDER BY "id";`, quoteColumns(values), table.name))
+	if err != nil {
+		return
+	}
+
+	type backupRow struct {
+		id     int64
+		values []interface{}
+	}
+
+	var backupRows []backupRow
+	for rows.Next() {
+		row := backupRow{values: make([]interface{}, len(values))}
+		dest := []interface{}{&row.id}
+		for i := range row.values {
+			dest = append(dest, &row.values[i])
+		}
+
+		err = rows.Scan(dest...)
+		if err != nil {
+			rows.Close()
+			return
+		}
+
+		backupRows = append(backupRows, row)
+	}
+
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return
+	}
+
+	var keyWhere []string
+	for _, column := range table.key {
+		keyWhere = append(keyWhere, fmt.Sprintf(`"%s" IS ?`, column))
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(values)), ",")
+	insertQuery := fmt.Sprintf(`INSERT INTO main."%s" (%s) VALUES (%s);`, table.name, quoteColumns(values), placeholders)
+	selectQuery := fmt.Sprintf(`SELECT "id" FROM main."%s" WHERE %s;`, table.name, strings.Join(keyWhere, " AND "))
+
+	tableIds := make(map[int64]int64)
+	ids[table.name] = tableIds
+
+next_row:
+	for _, row := range backupRows {
+		byColumn := make(map[string]interface{})
+		for i, column := range values {
+			referenced, ok := table.references[column]
+			if ok {
+				// NULL and 0 don't reference anything
+				id, isId := row.values[i].(int64)
+				if isId && id != 0 {
+					localId, found := ids[referenced][id]
+					if !found {
+						continue next_row
+					}
+
+					row.values[i] = localId
+				}
+			}
+
+			byColumn[column] = row.values[i]
+		}
+
+		var keyArgs []interface{}
+		for _, column := range table.key {
+			keyArgs = append(keyArgs, byColumn[column])
+		}
+
+		var localId int64
+		err = tx.QueryRow(selectQuery, keyArgs...).Scan(&localId)
+		switch err {
+		case nil:
+			tableIds[row.id] = localId
+			continue
+		case sql.ErrNoRows:
+		default:
+			return
+		}
+
+		var result sql.Result
+		result, err = tx.Exec(insertQuery, row.values...)
+		if err != nil {
+			return
+		}
+
+		localId, err = result.LastInsertId()
+		if err != nil {
+			return
+		}
+
+		tableIds[row.id] = localId
+		count++
+	}
+
+	return count, nil
+}
+
+func queryStrings(db *sql.DB, query string, args ...interface{}) ([]string, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var value string
+		err = rows.Scan(&value)
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, value)
+	}
+
+	return values, rows.Err()
+}
@@ -6,6 +6,7 @@ import (
 	sq "github.com/Masterminds/squirrel"
 	"github.com/secretsystems/secret-wallet/app_db/order_column"
 	"github.com/secretsystems/secret-wallet/app_db/schema_version"
+	"github.com/secretsystems/secret-wallet/settings"
 )
 
 type NodeConnection struct {
@@ -21,6 +22,26 @@ var TRUSTED_NODE_CONNECTIONS = []NodeConnection{
 	{Endpoint: "ws://node.derofoundation.org:11012/ws", Name: "The DERO Foundation"},
 }
 
+var TESTNET_NODE_CONNECTIONS = []NodeConnection{
+	{Endpoint: "ws://127.0.0.1:40402/ws", Name: "Local Testnet"},
+}
+
+var SIMULATOR_NODE_CONNECTIONS = []NodeConnection{
+	{Endpoint: "ws://127.0.0.1:20000/ws", Name: "Local Simulator"},
+}
+
+// node list of a new app.db for the current network
+func DefaultNodeConnections() []NodeConnection {
+	switch settings.App.Network {
+	case settings.NetworkTestnet:
+		return TESTNET_NODE_CONNECTIONS
+	case settings.NetworkSimulator:
+		return SIMULATOR_NODE_CONNECTIONS
+	}
+
+	return TRUSTED_NODE_CONNECTIONS
+}
+
 var nodeOrderer = order_column.Orderer{
 	TableName:  "nodes",
 	ColumnName: "order_number",
@@ -79,7 +100,7 @@ func ResetNodeConnections() error {
 		return err
 	}
 
-	for i, node := range TRUSTED_NODE_CONNECTIONS {
+	for i, node := range DefaultNodeConnections() {
 		_, err = tx.Exec(`
 			INSERT INTO nodes (endpoint, name, order_number)
 			VALUES (?,?,?);
@@ -19,6 +19,7 @@ type WalletInfo struct {
 	RegistrationTxHex string
 	Timestamp         int64
 	OrderNumber       int
+	WatchOnly         bool
 }
 
 var walletOrderer = order_column.Orderer{
@@ -45,13 +46,29 @@ func initDatabaseWallets() error {
 		if err != nil {
 			return err
 		}
+	}
+
+	if version < 2 {
+		// watch-only wallets are created from a public address and can't sign txs
+		_, err := DB.Exec(`
+			ALTER TABLE wallets
+			ADD COLUMN watch_only BOOLEAN NOT NULL DEFAULT false;
+		`)
+		if err != nil {
+			return err
+		}
+	}
 
+	if version == 0 {
+		// json wallets are inserted with the latest columns
 		err = migrateJsonWalletsInfo()
 		if err != nil {
 			return err
 		}
+	}
 
-		version = 1
+	if version < 2 {
+		version = 2
 		err = schema_version.StoreVersion(DB, "wallets", version)
 		if err != nil {
 			return err
@@ -161,6 +178,7 @@ func GetWallets() ([]WalletInfo, error) {
 			&wallet.RegistrationTxHex,
 			&wallet.Timestamp,
 			&wallet.OrderNumber,
+			&wallet.WatchOnly,
 		)
 		if err != nil {
 			return nil, err
@@ -183,6 +201,7 @@ func GetWalletInfo(addr string) (WalletInfo, error) {
 		&walletInfo.RegistrationTxHex,
 		&walletInfo.Timestamp,
 		&walletInfo.OrderNumber,
+		&walletInfo.WatchOnly,
 	)
 	return walletInfo, err
 }
@@ -205,9 +224,9 @@ func InsertWalletInfo(walletInfo WalletInfo) error {
 	}
 
 	_, err = tx.Exec(`
-		INSERT INTO wallets (addr,name,registration_tx_hex,timestamp,order_number)
-		VALUES (?,?,?,?,?);
-	`, walletInfo.Addr, walletInfo.Name, walletInfo.RegistrationTxHex, walletInfo.Timestamp, walletInfo.OrderNumber)
+		INSERT INTO wallets (addr,name,registration_tx_hex,timestamp,order_number,watch_only)
+		VALUES (?,?,?,?,?,?);
+	`, walletInfo.Addr, walletInfo.Name, walletInfo.RegistrationTxHex, walletInfo.Timestamp, walletInfo.OrderNumber, walletInfo.WatchOnly)
 	if err != nil {
 		tx.Rollback()
 		return err
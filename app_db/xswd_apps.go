@@ -0,0 +1,256 @@
+package app_db
+
+import (
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/secretsystems/secret-wallet/app_db/schema_version"
+)
+
+// what an app can do with a wallet method without asking again
+type XSWDPermission int
+
+const (
+	XSWD_PERMISSION_ASK XSWDPermission = iota
+	XSWD_PERMISSION_ALWAYS
+	XSWD_PERMISSION_DENY
+)
+
+func (p XSWDPermission) String() string {
+	switch p {
+	case XSWD_PERMISSION_ALWAYS:
+		return "always"
+	case XSWD_PERMISSION_DENY:
+		return "deny"
+	}
+
+	return "ask"
+}
+
+// dApp accepted by the user - permissions are by method name and missing methods are asked
+type XSWDApp struct {
+	ID          string
+	Name        string
+	Description string
+	Url         string
+	// scheme://host of the page that was accepted - empty for apps that are not in a browser
+	Origin            string
+	Permissions       map[string]XSWDPermission
+	Timestamp         int64
+	LastSeenTimestamp int64
+}
+
+func (app XSWDApp) Permission(method string) XSWDPermission {
+	return app.Permissions[method]
+}
+
+func initDatabaseXSWDApps() error {
+	version, err := schema_version.GetVersion(DB, "xswd_apps")
+	if err != nil {
+		return err
+	}
+
+	if version == 0 {
+		_, err := DB.Exec(`
+			CREATE TABLE IF NOT EXISTS xswd_apps (
+				id VARCHAR PRIMARY KEY,
+				name VARCHAR NOT NULL,
+				description VARCHAR NOT NULL,
+				url VARCHAR NOT NULL,
+				origin VARCHAR NOT NULL,
+				timestamp BIGINT NOT NULL,
+				last_seen_timestamp BIGINT NOT NULL
+			);
+
+			CREATE TABLE IF NOT EXISTS xswd_permissions (
+				app_id VARCHAR NOT NULL,
+				method VARCHAR NOT NULL,
+				permission INT NOT NULL,
+				PRIMARY KEY (app_id, method)
+			);
+		`)
+		if err != nil {
+			return err
+		}
+
+		version = 1
+		err = schema_version.StoreVersion(DB, "xswd_apps", version)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// replaces the app and its permissions
+func StoreXSWDApp(app XSWDApp) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO xswd_apps (id,name,description,url,origin,timestamp,last_seen_timestamp)
+		VALUES (?,?,?,?,?,?,?)
+		ON CONFLICT (id) DO UPDATE SET
+		name = excluded.name,
+		description = excluded.description,
+		url = excluded.url,
+		origin = excluded.origin,
+		last_seen_timestamp = excluded.last_seen_timestamp;
+	`, app.ID, app.Name, app.Description, app.Url, app.Origin, app.Timestamp, app.LastSeenTimestamp)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec(`
+		DELETE FROM xswd_permissions
+		WHERE app_id = ?;
+	`, app.ID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for method, permission := range app.Permissions {
+		_, err = tx.Exec(`
+			INSERT INTO xswd_permissions (app_id,method,permission)
+			VALUES (?,?,?);
+		`, app.ID, method, permission)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func SetXSWDPermission(appId string, method string, permission XSWDPermission) error {
+	_, err := DB.Exec(`
+		INSERT INTO xswd_permissions (app_id,method,permission)
+		VALUES (?,?,?)
+		ON CONFLICT (app_id,method) DO UPDATE SET
+		permission = excluded.permission;
+	`, appId, method, permission)
+	return err
+}
+
+func UpdateXSWDAppLastSeen(appId string, timestamp int64) error {
+	_, err := DB.Exec(`
+		UPDATE xswd_apps
+		SET last_seen_timestamp = ?
+		WHERE id = ?;
+	`, timestamp, appId)
+	return err
+}
+
+func getXSWDPermissions(appId string) (map[string]XSWDPermission, error) {
+	query := sq.Select("method", "permission").
+		From("xswd_permissions").
+		Where(sq.Eq{"app_id": appId})
+
+	rows, err := query.RunWith(DB).Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	permissions := make(map[string]XSWDPermission)
+	for rows.Next() {
+		var method string
+		var permission XSWDPermission
+		err = rows.Scan(&method, &permission)
+		if err != nil {
+			return nil, err
+		}
+
+		permissions[method] = permission
+	}
+
+	return permissions, nil
+}
+
+// last seen first
+func GetXSWDApps() ([]XSWDApp, error) {
+	query := sq.Select("id", "name", "description", "url", "origin", "timestamp", "last_seen_timestamp").
+		From("xswd_apps").
+		OrderBy("last_seen_timestamp DESC")
+
+	rows, err := query.RunWith(DB).Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var apps []XSWDApp
+	for rows.Next() {
+		var app XSWDApp
+		err = rows.Scan(&app.ID, &app.Name, &app.Description, &app.Url, &app.Origin, &app.Timestamp, &app.LastSeenTimestamp)
+		if err != nil {
+			return nil, err
+		}
+
+		apps = append(apps, app)
+	}
+
+	for i := range apps {
+		apps[i].Permissions, err = getXSWDPermissions(apps[i].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return apps, nil
+}
+
+// found is false if the app was never accepted or was revoked
+func GetXSWDApp(appId string) (app XSWDApp, found bool, err error) {
+	row := DB.QueryRow(`
+		SELECT id, name, description, url, origin, timestamp, last_seen_timestamp FROM xswd_apps
+		WHERE id = ?;
+	`, appId)
+
+	err = row.Scan(&app.ID, &app.Name, &app.Description, &app.Url, &app.Origin, &app.Timestamp, &app.LastSeenTimestamp)
+	if err == sql.ErrNoRows {
+		err = nil
+		return
+	}
+
+	if err != nil {
+		return
+	}
+
+	app.Permissions, err = getXSWDPermissions(appId)
+	found = err == nil
+	return
+}
+
+func DelXSWDApp(appId string) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		DELETE FROM xswd_permissions
+		WHERE app_id = ?;
+	`, appId)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec(`
+		DELETE FROM xswd_apps
+		WHERE id = ?;
+	`, appId)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -3,19 +3,65 @@ package caching
 import (
 	"bytes"
 	"encoding/gob"
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/secretsystems/secret-wallet/settings"
 )
 
-func cachePath(relPath string, name string) (cachePath string) {
-	cachePath = filepath.Join(settings.CacheDir, relPath, name+".cache")
+// bump when the entry format or a cached type changes - the whole cache is discarded on load
+const SCHEMA_VERSION = 1
+
+type entry struct {
+	Timestamp int64 // unix seconds
+	TTL       int64 // seconds - 0 never expires
+	Data      []byte
+}
+
+// total size of the cache files - unknown (-1) until the cache dir is walked once
+var totalSize int64 = -1
+var totalSizeMutex sync.Mutex
+
+func cachePath(category Category, relPath string, name string) (cachePath string) {
+	cachePath = filepath.Join(settings.CacheDir, category.Key, relPath, name+".cache")
 	return
 }
 
-func Store(relPath string, name string, value interface{}) error {
-	fullPath := cachePath(relPath, name)
+func versionPath() string {
+	return filepath.Join(settings.CacheDir, "version")
+}
+
+func writeVersion() error {
+	err := os.MkdirAll(settings.CacheDir, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(versionPath(), []byte(strconv.Itoa(SCHEMA_VERSION)), os.ModePerm)
+}
+
+// clears the cache if it was written with another schema version (or before versioning)
+// called again when the network and its cache dir change
+func Load() error {
+	resetSize()
+
+	data, err := os.ReadFile(versionPath())
+	if err == nil && strings.TrimSpace(string(data)) == strconv.Itoa(SCHEMA_VERSION) {
+		return nil
+	}
+
+	return ClearAll()
+}
+
+func Store(category Category, relPath string, name string, value interface{}) error {
+	fullPath := cachePath(category, relPath, name)
 
 	buffer := &bytes.Buffer{}
 	enc := gob.NewEncoder(buffer)
@@ -24,6 +70,17 @@ func Store(relPath string, name string, value interface{}) error {
 		return err
 	}
 
+	entryBuffer := &bytes.Buffer{}
+	enc = gob.NewEncoder(entryBuffer)
+	err = enc.Encode(entry{
+		Timestamp: time.Now().Unix(),
+		TTL:       int64(category.TTL / time.Second),
+		Data:      buffer.Bytes(),
+	})
+	if err != nil {
+		return err
+	}
+
 	dirPath := filepath.Dir(fullPath)
 
 	err = os.MkdirAll(dirPath, os.ModePerm)
@@ -31,49 +88,206 @@ func Store(relPath string, name string, value interface{}) error {
 		return err
 	}
 
-	data := buffer.Bytes()
+	var oldSize int64
+	info, err := os.Stat(fullPath)
+	if err == nil {
+		oldSize = info.Size()
+	}
+
+	data := entryBuffer.Bytes()
 	err = os.WriteFile(fullPath, data, os.ModePerm)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return addSize(int64(len(data)) - oldSize)
 }
 
-func Get(relPath string, name string, value interface{}) (exists bool, err error) {
-	fullPath := cachePath(relPath, name)
+func Get(category Category, relPath string, name string, value interface{}) (exists bool, err error) {
+	fullPath := cachePath(category, relPath, name)
 
-	_, err = os.Stat(fullPath)
+	data, err := os.ReadFile(fullPath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			err = nil
-			return
 		}
 		return
 	}
+
+	e := entry{}
+	dec := gob.NewDecoder(bytes.NewBuffer(data))
+	err = dec.Decode(&e)
+	if err == nil {
+		dec = gob.NewDecoder(bytes.NewBuffer(e.Data))
+		err = dec.Decode(value)
+	}
+
+	// an unreadable entry is treated like an expired one and fetched again
+	expired := e.TTL > 0 && time.Now().Unix() > e.Timestamp+e.TTL
+	if err != nil || expired {
+		err = remove(fullPath)
+		return
+	}
+
+	// the modification time is the last use for the lru eviction
+	now := time.Now()
+	os.Chtimes(fullPath, now, now)
+
 	exists = true
+	return
+}
 
-	data, err := os.ReadFile(fullPath)
+func remove(fullPath string) error {
+	info, err := os.Stat(fullPath)
 	if err != nil {
-		return
+		return nil
 	}
 
-	buffer := bytes.NewBuffer(data)
-	dec := gob.NewDecoder(buffer)
-	err = dec.Decode(value)
+	err = os.Remove(fullPath)
 	if err != nil {
-		return
+		return err
+	}
+
+	return addSize(-info.Size())
+}
+
+type cacheFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+func walkFiles(dir string) (files []cacheFile, err error) {
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+
+		if d.IsDir() || !strings.HasSuffix(path, ".cache") {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		files = append(files, cacheFile{path: path, size: info.Size(), modTime: info.ModTime()})
+		return nil
+	})
+
+	return
+}
+
+func addSize(delta int64) error {
+	totalSizeMutex.Lock()
+	defer totalSizeMutex.Unlock()
+
+	if totalSize >= 0 {
+		totalSize += delta
+	}
+
+	maxSize := settings.App.CacheMaxSize
+	if maxSize <= 0 || (totalSize >= 0 && totalSize <= maxSize) {
+		return nil
+	}
+
+	return evict(maxSize)
+}
+
+// removes the least recently used files until the cache is 10% under the max size
+func evict(maxSize int64) error {
+	files, err := walkFiles(settings.CacheDir)
+	if err != nil {
+		return err
+	}
+
+	totalSize = 0
+	for _, file := range files {
+		totalSize += file.size
+	}
+
+	if totalSize <= maxSize {
+		return nil
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+
+	target := maxSize / 10 * 9
+	for _, file := range files {
+		if totalSize <= target {
+			break
+		}
+
+		err = os.Remove(file.path)
+		if err != nil {
+			return err
+		}
+
+		totalSize -= file.size
+	}
+
+	return nil
+}
+
+type Usage struct {
+	Category Category
+	Files    int
+	Size     int64
+}
+
+func GetUsage() (usages []Usage, err error) {
+	for _, category := range Categories {
+		files, err := walkFiles(filepath.Join(settings.CacheDir, category.Key))
+		if err != nil {
+			return nil, err
+		}
+
+		usage := Usage{Category: category, Files: len(files)}
+		for _, file := range files {
+			usage.Size += file.size
+		}
+
+		usages = append(usages, usage)
 	}
 
 	return
 }
 
-func Clear(relCachePath string, name string) error {
-	fullPath := cachePath(relCachePath, name)
-	dirPath := filepath.Dir(fullPath)
-	return os.RemoveAll(dirPath)
+func Clear(category Category, relPath string) error {
+	if relPath == "" {
+		return fmt.Errorf("empty cache path")
+	}
+
+	err := os.RemoveAll(filepath.Join(settings.CacheDir, category.Key, relPath))
+	resetSize()
+	return err
+}
+
+func ClearCategory(category Category) error {
+	err := os.RemoveAll(filepath.Join(settings.CacheDir, category.Key))
+	resetSize()
+	return err
 }
 
 func ClearAll() error {
-	return os.RemoveAll(settings.CacheDir)
+	err := os.RemoveAll(settings.CacheDir)
+	resetSize()
+	if err != nil {
+		return err
+	}
+
+	return writeVersion()
+}
+
+// the size is walked again on the next eviction check
+func resetSize() {
+	totalSizeMutex.Lock()
+	totalSize = -1
+	totalSizeMutex.Unlock()
 }
@@ -0,0 +1,219 @@
+package caching
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/secretsystems/secret-wallet/settings"
+)
+
+var testCategory = Category{Key: "test", Name: "Test", TTL: time.Minute}
+
+func setTestCache(t *testing.T, maxSize int64) {
+	t.Helper()
+
+	cacheDir := settings.CacheDir
+	appSettings := settings.App
+	t.Cleanup(func() {
+		settings.CacheDir = cacheDir
+		settings.App = appSettings
+		resetSize()
+	})
+
+	settings.CacheDir = t.TempDir()
+	settings.App.CacheMaxSize = maxSize
+
+	err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writes an entry as if it was stored at the given time
+func storeTestEntry(t *testing.T, name string, value string, timestamp time.Time) {
+	t.Helper()
+
+	err := Store(testCategory, "", name, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buffer := &bytes.Buffer{}
+	err = gob.NewEncoder(buffer).Encode(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entryBuffer := &bytes.Buffer{}
+	err = gob.NewEncoder(entryBuffer).Encode(entry{
+		Timestamp: timestamp.Unix(),
+		TTL:       int64(testCategory.TTL / time.Second),
+		Data:      buffer.Bytes(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(cachePath(testCategory, "", name), entryBuffer.Bytes(), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTTL(t *testing.T) {
+	setTestCache(t, 0)
+
+	storeTestEntry(t, "fresh", "dero", time.Now().Add(-30*time.Second))
+	storeTestEntry(t, "expired", "dero", time.Now().Add(-2*time.Minute))
+
+	var value string
+	exists, err := Get(testCategory, "", "fresh", &value)
+	if err != nil || !exists || value != "dero" {
+		t.Fatalf("unexpected entry [%s] %t %v", value, exists, err)
+	}
+
+	exists, err = Get(testCategory, "", "expired", &value)
+	if err != nil || exists {
+		t.Fatalf("expired entry was returned %v", err)
+	}
+
+	_, err = os.Stat(cachePath(testCategory, "", "expired"))
+	if !os.IsNotExist(err) {
+		t.Fatalf("expired entry was not removed %v", err)
+	}
+
+	// never expires
+	err = Store(SC_CODE, "", "code", "dero")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exists, err = Get(SC_CODE, "", "code", &value)
+	if err != nil || !exists {
+		t.Fatalf("entry without ttl was not returned %v", err)
+	}
+}
+
+func TestEviction(t *testing.T) {
+	setTestCache(t, 0)
+
+	value := string(make([]byte, 1000))
+	for i := 0; i < 10; i++ {
+		err := Store(testCategory, "", fmt.Sprint(i), value)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// 0 is the oldest
+		modTime := time.Now().Add(time.Duration(i-10) * time.Minute)
+		err = os.Chtimes(cachePath(testCategory, "", fmt.Sprint(i)), modTime, modTime)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// a read is a use
+	var result string
+	exists, err := Get(testCategory, "", "0", &result)
+	if err != nil || !exists {
+		t.Fatalf("entry was not returned %v", err)
+	}
+
+	info, err := os.Stat(cachePath(testCategory, "", "0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fileSize := info.Size()
+	maxSize := fileSize*10 + fileSize/2
+	settings.App.CacheMaxSize = maxSize
+
+	// the 11th file goes over the max size
+	err = Store(testCategory, "", "10", value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := walkFiles(settings.CacheDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var size int64
+	for _, file := range files {
+		size += file.size
+	}
+
+	if size > maxSize/10*9 {
+		t.Fatalf("cache is still %d bytes for a max of %d", size, maxSize)
+	}
+
+	for i := 0; i <= 10; i++ {
+		_, err := os.Stat(cachePath(testCategory, "", fmt.Sprint(i)))
+		evicted := os.IsNotExist(err)
+		if evicted != (i == 1 || i == 2) {
+			t.Fatalf("unexpected eviction of %d: %t", i, evicted)
+		}
+	}
+}
+
+func TestVersion(t *testing.T) {
+	setTestCache(t, 0)
+
+	exists := func() bool {
+		t.Helper()
+
+		var value string
+		exists, err := Get(testCategory, "", "entry", &value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return exists
+	}
+
+	err := Store(testCategory, "", "entry", "dero")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// same version
+	err = Load()
+	if err != nil || !exists() {
+		t.Fatalf("cache was cleared %v", err)
+	}
+
+	err = os.WriteFile(versionPath(), []byte(fmt.Sprint(SCHEMA_VERSION+1)), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Load()
+	if err != nil || exists() {
+		t.Fatalf("cache of another version was kept %v", err)
+	}
+
+	data, err := os.ReadFile(versionPath())
+	if err != nil || string(data) != fmt.Sprint(SCHEMA_VERSION) {
+		t.Fatalf("unexpected version [%s] %v", data, err)
+	}
+
+	// written before versioning
+	err = Store(testCategory, "", "entry", "dero")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.Remove(versionPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Load()
+	if err != nil || exists() {
+		t.Fatalf("cache without a version was kept %v", err)
+	}
+}
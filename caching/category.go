@@ -0,0 +1,22 @@
+package caching
+
+import "time"
+
+// every cache entry belongs to a category which decides how long it stays valid
+type Category struct {
+	Key  string
+	Name string
+	// 0 never expires
+	TTL time.Duration
+}
+
+var (
+	// the code of a deployed contract never changes
+	SC_CODE = Category{Key: "sc_code", Name: "Smart contract code", TTL: 0}
+	// supply, owner and metadata can change at any time
+	SC_VARIABLES = Category{Key: "sc_variables", Name: "Smart contract variables", TTL: 5 * time.Minute}
+	// token images and nft media
+	IMAGES = Category{Key: "images", Name: "Images", TTL: 30 * 24 * time.Hour}
+)
+
+var Categories = []Category{SC_CODE, SC_VARIABLES, IMAGES}
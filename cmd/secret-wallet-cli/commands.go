@@ -0,0 +1,907 @@
+package main
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/deroproject/derohe/rpc"
+	"github.com/deroproject/derohe/transaction"
+	"github.com/secretsystems/secret-wallet/app_db"
+	"github.com/secretsystems/secret-wallet/lookup_table"
+	"github.com/secretsystems/secret-wallet/settings"
+	"github.com/secretsystems/secret-wallet/utils"
+	"github.com/secretsystems/secret-wallet/wallet_manager"
+	"golang.org/x/term"
+)
+
+type walletFlags struct {
+	wallet   *string
+	password *string
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.BoolVar(&jsonOutput, "json", jsonOutput, "output as json instead of tables")
+	return fs
+}
+
+// flags are allowed before and after the action (e.g contacts add --name ...)
+func parseAction(fs *flag.FlagSet, args []string, defaultAction string) string {
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		return defaultAction
+	}
+
+	action := fs.Arg(0)
+	fs.Parse(fs.Args()[1:])
+	return action
+}
+
+func addWalletFlags(fs *flag.FlagSet) walletFlags {
+	return walletFlags{
+		wallet:   fs.String("wallet", "", "wallet name or address"),
+		password: fs.String("password", "", "wallet password (or env SECRET_WALLET_PASSWORD) - visible in the process list, prompted if empty"),
+	}
+}
+
+func readPassword(password string) (string, error) {
+	if password != "" {
+		return password, nil
+	}
+
+	password = os.Getenv("SECRET_WALLET_PASSWORD")
+	if password != "" {
+		return password, nil
+	}
+
+	fmt.Fprint(os.Stderr, "Password: ")
+
+	// the password is not echoed in a terminal - a piped stdin is read as a line
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		value, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", err
+		}
+
+		return string(value), nil
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func findWallet(nameOrAddr string) (walletInfo app_db.WalletInfo, err error) {
+	wallets, err := app_db.GetWallets()
+	if err != nil {
+		return
+	}
+
+	if nameOrAddr == "" {
+		if len(wallets) == 0 {
+			err = fmt.Errorf("no wallets, use the create command first")
+			return
+		}
+
+		if len(wallets) == 1 {
+			walletInfo = wallets[0]
+			return
+		}
+
+		err = fmt.Errorf("multiple wallets, use --wallet to pick one")
+		return
+	}
+
+	for _, info := range wallets {
+		if info.Addr == nameOrAddr || info.Name == nameOrAddr {
+			walletInfo = info
+			return
+		}
+	}
+
+	err = fmt.Errorf("wallet [%s] not found", nameOrAddr)
+	return
+}
+
+func openWallet(flags walletFlags, online bool) (*wallet_manager.Wallet, error) {
+	walletInfo, err := findWallet(*flags.wallet)
+	if err != nil {
+		return nil, err
+	}
+
+	password, err := readPassword(*flags.password)
+	if err != nil {
+		return nil, err
+	}
+
+	if online {
+		err = connect()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = wallet_manager.OpenWallet(walletInfo.Addr, password)
+	if err != nil {
+		return nil, err
+	}
+
+	wallet := wallet_manager.OpenedWallet
+	if online {
+		// the balances of a watch-only wallet can't be decrypted
+		if wallet.IsWatchOnly() {
+			err = wallet.SyncWatchOnly()
+		} else {
+			err = wallet.Memory.Sync_Wallet_Memory_With_Daemon()
+		}
+		if err != nil {
+			closeWallet(wallet)
+			return nil, err
+		}
+	}
+
+	return wallet, nil
+}
+
+func closeWallet(wallet *wallet_manager.Wallet) {
+	// don't use CloseOpenedWallet - it closes in a goroutine and we are about to exit
+	wallet.Memory.Save_Wallet()
+	wallet.DB.Close()
+	wallet_manager.OpenedWallet = nil
+}
+
+func cmdWallets(args []string) error {
+	fs := newFlagSet("wallets")
+	fs.Parse(args)
+
+	wallets, err := app_db.GetWallets()
+	if err != nil {
+		return err
+	}
+
+	type walletJson struct {
+		Name      string `json:"name"`
+		Addr      string `json:"addr"`
+		Timestamp int64  `json:"timestamp"`
+		WatchOnly bool   `json:"watch_only"`
+	}
+
+	data := []walletJson{}
+	t := &table{headers: []string{"NAME", "ADDRESS", "CREATED", "WATCH-ONLY"}}
+	for _, info := range wallets {
+		data = append(data, walletJson{Name: info.Name, Addr: info.Addr, Timestamp: info.Timestamp, WatchOnly: info.WatchOnly})
+		t.add(info.Name, info.Addr, time.Unix(info.Timestamp, 0).Format("2006-01-02 15:04"), info.WatchOnly)
+	}
+
+	return output(data, t)
+}
+
+func cmdCreate(args []string) error {
+	fs := newFlagSet("create")
+	name := fs.String("name", "", "wallet name")
+	password := fs.String("password", "", "wallet password (or env SECRET_WALLET_PASSWORD) - visible in the process list, prompted if empty")
+	seed := fs.String("seed", "", "recover from seed words")
+	hexSeed := fs.String("hexseed", "", "recover from hex seed")
+	path := fs.String("file", "", "import a wallet file")
+	watch := fs.String("watch", "", "create a watch-only wallet from an address")
+	fs.Parse(args)
+
+	if *name == "" {
+		return fmt.Errorf("--name is required")
+	}
+
+	pass, err := readPassword(*password)
+	if err != nil {
+		return err
+	}
+
+	if pass == "" {
+		return fmt.Errorf("password is empty")
+	}
+
+	before, err := app_db.GetWallets()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case *seed != "":
+		err = wallet_manager.CreateWalletFromSeed(*name, pass, *seed)
+	case *hexSeed != "":
+		err = wallet_manager.CreateWalletFromHexSeed(*name, pass, *hexSeed)
+	case *path != "":
+		err = wallet_manager.CreateWalletFromPath(*name, pass, *path)
+	case *watch != "":
+		err = wallet_manager.CreateWatchOnlyWallet(*name, pass, *watch)
+	default:
+		err = wallet_manager.CreateRandomWallet(*name, pass)
+	}
+	if err != nil {
+		return err
+	}
+
+	after, err := app_db.GetWallets()
+	if err != nil {
+		return err
+	}
+
+	exists := make(map[string]bool)
+	for _, info := range before {
+		exists[info.Addr] = true
+	}
+
+	addr := ""
+	for _, info := range after {
+		if !exists[info.Addr] {
+			addr = info.Addr
+		}
+	}
+
+	t := &table{headers: []string{"NAME", "ADDRESS"}}
+	t.add(*name, addr)
+	return output(map[string]string{"name": *name, "addr": addr}, t)
+}
+
+func cmdInfo(args []string) error {
+	fs := newFlagSet("info")
+	wFlags := addWalletFlags(fs)
+	fs.Parse(args)
+
+	wallet, err := openWallet(wFlags, true)
+	if err != nil {
+		return err
+	}
+	defer closeWallet(wallet)
+
+	data := map[string]interface{}{
+		"name":          wallet.Info.Name,
+		"addr":          wallet.Memory.GetAddress().String(),
+		"height":        wallet.Memory.Get_Height(),
+		"daemon_height": wallet.Memory.Get_Daemon_Height(),
+		"registered":    wallet.Memory.IsRegistered(),
+		"node":          settings.App.NodeEndpoint,
+		"network":       settings.App.Network,
+	}
+
+	t := &table{headers: []string{"KEY", "VALUE"}}
+	t.add("Name", data["name"])
+	t.add("Address", data["addr"])
+	t.add("Height", data["height"])
+	t.add("Daemon height", data["daemon_height"])
+	t.add("Registered", data["registered"])
+	t.add("Node", data["node"])
+	t.add("Network", data["network"])
+	return output(data, t)
+}
+
+func cmdBalance(args []string) error {
+	fs := newFlagSet("balance")
+	wFlags := addWalletFlags(fs)
+	scId := fs.String("scid", "", "only show the balance of this token")
+	fs.Parse(args)
+
+	wallet, err := openWallet(wFlags, true)
+	if err != nil {
+		return err
+	}
+	defer closeWallet(wallet)
+
+	tokens := []wallet_manager.Token{*wallet_manager.DeroToken()}
+	walletTokens, err := wallet.GetTokens(wallet_manager.GetTokensParams{})
+	if err != nil {
+		return err
+	}
+
+	added := make(map[string]bool)
+	for _, token := range walletTokens {
+		// the same token can be in multiple folders
+		if !added[token.SCID] {
+			tokens = append(tokens, token)
+			added[token.SCID] = true
+		}
+	}
+
+	type balanceJson struct {
+		SCID    string `json:"scid"`
+		Name    string `json:"name"`
+		Symbol  string `json:"symbol"`
+		Balance uint64 `json:"balance"`
+		Amount  string `json:"amount"`
+		Known   bool   `json:"known"`
+	}
+
+	data := []balanceJson{}
+	t := &table{headers: []string{"NAME", "SYMBOL", "SCID", "BALANCE"}}
+	for _, token := range tokens {
+		if *scId != "" && token.SCID != *scId {
+			continue
+		}
+
+		hash := token.GetHash()
+		if !hash.IsZero() && !wallet.IsWatchOnly() {
+			wallet.Memory.TokenAdd(hash)
+			err = wallet.Memory.Sync_Wallet_Memory_With_Daemon_internal(hash)
+			if err != nil {
+				return err
+			}
+		}
+
+		balance, known := wallet.GetBalance(hash)
+		amount := utils.ShiftNumber{Number: balance, Decimals: int(token.Decimals)}.Format()
+		if !known {
+			amount = "encrypted"
+		}
+
+		data = append(data, balanceJson{
+			SCID:    token.SCID,
+			Name:    token.Name,
+			Symbol:  token.Symbol.String,
+			Balance: balance,
+			Amount:  amount,
+			Known:   known,
+		})
+		t.add(token.Name, token.Symbol.String, utils.ReduceTxId(token.SCID), amount)
+	}
+
+	return output(data, t)
+}
+
+func cmdSend(args []string) error {
+	fs := newFlagSet("send")
+	wFlags := addWalletFlags(fs)
+	to := fs.String("to", "", "destination address, integrated address or registered name")
+	amountValue := fs.String("amount", "", "amount to send")
+	scId := fs.String("scid", "", "token to send (default to Dero)")
+	ringsize := fs.Uint64("ringsize", 0, "ring size (default to the app setting)")
+	comment := fs.String("comment", "", "comment attached to the transfer")
+	dstPort := fs.Uint64("dst-port", 0, "destination port")
+	dryRun := fs.Bool("dry-run", false, "build the transaction and show fees without sending it")
+	offlineOut := fs.String("offline-out", "", "write the unsigned transaction to a file for offline signing")
+	fs.Parse(args)
+
+	if *to == "" {
+		return fmt.Errorf("--to is required")
+	}
+
+	wallet, err := openWallet(wFlags, true)
+	if err != nil {
+		return err
+	}
+	defer closeWallet(wallet)
+
+	if wallet.IsWatchOnly() && *offlineOut == "" {
+		return wallet_manager.ErrWatchOnly
+	}
+
+	token := wallet_manager.DeroToken()
+	if *scId != "" {
+		token, err = wallet_manager.GetTokenBySCID(*scId)
+		if err != nil {
+			return err
+		}
+
+		// the ring balances of a watch-only wallet are fetched when preparing the tx
+		if !wallet.IsWatchOnly() {
+			hash := token.GetHash()
+			wallet.Memory.TokenAdd(hash)
+			err = wallet.Memory.Sync_Wallet_Memory_With_Daemon_internal(hash)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	address, err := rpc.NewAddress(*to)
+	if err != nil {
+		addr, err := wallet.Memory.NameToAddress(*to)
+		if err != nil {
+			if utils.IsErrLeafNotFound(err) {
+				return fmt.Errorf("address not found for [%s]", *to)
+			}
+
+			return err
+		}
+
+		address, err = rpc.NewAddress(addr)
+		if err != nil {
+			return err
+		}
+	}
+
+	amount := utils.ShiftNumber{Decimals: int(token.Decimals)}
+	var arguments rpc.Arguments
+
+	if address.IsIntegratedAddress() {
+		err = address.Arguments.Validate_Arguments()
+		if err != nil {
+			return err
+		}
+
+		if address.Arguments.Has(rpc.RPC_EXPIRY, rpc.DataTime) {
+			expireTime := address.Arguments.Value(rpc.RPC_EXPIRY, rpc.DataTime).(time.Time)
+			if expireTime.Before(time.Now().UTC()) {
+				return fmt.Errorf("the integrated address has expired")
+			}
+		}
+
+		if address.Arguments.Has(rpc.RPC_NEEDS_REPLYBACK_ADDRESS, rpc.DataUint64) {
+			arguments = append(arguments, rpc.Argument{Name: rpc.RPC_REPLYBACK_ADDRESS, DataType: rpc.DataAddress, Value: wallet.Memory.GetAddress()})
+		}
+
+		if address.Arguments.Has(rpc.RPC_VALUE_TRANSFER, rpc.DataUint64) {
+			amount.Number = address.Arguments.Value(rpc.RPC_VALUE_TRANSFER, rpc.DataUint64).(uint64)
+			arguments = append(arguments, rpc.Argument{Name: rpc.RPC_VALUE_TRANSFER, DataType: rpc.DataUint64, Value: amount.Number})
+		}
+
+		if !address.Arguments.Has(rpc.RPC_DESTINATION_PORT, rpc.DataUint64) {
+			return fmt.Errorf("the integrated address does not contain a destination port")
+		}
+
+		port := address.Arguments.Value(rpc.RPC_DESTINATION_PORT, rpc.DataUint64).(uint64)
+		arguments = append(arguments, rpc.Argument{Name: rpc.RPC_DESTINATION_PORT, DataType: rpc.DataUint64, Value: port})
+
+		if address.Arguments.Has(rpc.RPC_COMMENT, rpc.DataString) {
+			addrComment := address.Arguments.Value(rpc.RPC_COMMENT, rpc.DataString).(string)
+			arguments = append(arguments, rpc.Argument{Name: rpc.RPC_COMMENT, DataType: rpc.DataString, Value: addrComment})
+		}
+	} else {
+		if *comment != "" {
+			arguments = append(arguments, rpc.Argument{Name: rpc.RPC_COMMENT, DataType: rpc.DataString, Value: *comment})
+		}
+
+		if *dstPort > 0 {
+			arguments = append(arguments, rpc.Argument{Name: rpc.RPC_DESTINATION_PORT, DataType: rpc.DataUint64, Value: *dstPort})
+		}
+	}
+
+	if amount.Number == 0 {
+		if *amountValue == "" {
+			return fmt.Errorf("--amount is required")
+		}
+
+		err = amount.Parse(*amountValue)
+		if err != nil {
+			return err
+		}
+	}
+
+	if amount.Number == 0 {
+		return fmt.Errorf("amount must be greater than 0")
+	}
+
+	_, err = arguments.CheckPack(transaction.PAYLOAD0_LIMIT)
+	if err != nil {
+		return err
+	}
+
+	size := *ringsize
+	if size == 0 {
+		size = uint64(settings.App.SendRingSize)
+	}
+
+	transfers := []rpc.Transfer{
+		{SCID: token.GetHash(), Destination: address.String(), Amount: amount.Number, Payload_RPC: arguments},
+	}
+
+	if *offlineOut != "" {
+		return prepareOfflineTx(wallet, transfers, size, *offlineOut)
+	}
+
+	tx, txFees, gasFees, err := wallet.BuildTransaction(transfers, size, nil, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	txId := ""
+	if !*dryRun {
+		err = wallet.InsertOutgoingTx(tx)
+		if err != nil {
+			return err
+		}
+
+		err = wallet.Memory.SendTransaction(tx)
+		if err != nil {
+			return err
+		}
+
+		txId = tx.GetHash().String()
+	}
+
+	fees := utils.ShiftNumber{Number: txFees + gasFees, Decimals: 5}.Format()
+	data := map[string]interface{}{
+		"txid":     txId,
+		"amount":   amount.Format(),
+		"fees":     fees,
+		"dry_run":  *dryRun,
+		"ringsize": size,
+	}
+
+	t := &table{headers: []string{"KEY", "VALUE"}}
+	if txId != "" {
+		t.add("TxId", txId)
+	}
+	t.add("Amount", amount.Format())
+	t.add("Fees", fees)
+	t.add("Ring size", size)
+	t.add("Dry run", *dryRun)
+	return output(data, t)
+}
+
+// the tx fees are only known when the offline wallet signs the tx
+func prepareOfflineTx(wallet *wallet_manager.Wallet, transfers []rpc.Transfer, ringsize uint64, path string) error {
+	unsignedTx, err := wallet.PrepareTransaction(transfers, ringsize, nil)
+	if err != nil {
+		return err
+	}
+
+	value, err := unsignedTx.Encode()
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(path, []byte(value), 0600)
+	if err != nil {
+		return err
+	}
+
+	gasFees := utils.ShiftNumber{Number: unsignedTx.GasFees, Decimals: 5}.Format()
+	data := map[string]interface{}{
+		"file":     path,
+		"height":   unsignedTx.Height,
+		"gas_fees": gasFees,
+		"ringsize": ringsize,
+	}
+
+	t := &table{headers: []string{"KEY", "VALUE"}}
+	t.add("File", path)
+	t.add("Height", unsignedTx.Height)
+	t.add("Gas fees", gasFees)
+	t.add("Ring size", ringsize)
+	return output(data, t)
+}
+
+func cmdSign(args []string) error {
+	fs := newFlagSet("sign")
+	wFlags := addWalletFlags(fs)
+	in := fs.String("in", "", "unsigned transaction file")
+	out := fs.String("out", "", "signed transaction file (default to stdout)")
+	fs.Parse(args)
+
+	if *in == "" {
+		return fmt.Errorf("--in is required")
+	}
+
+	value, err := os.ReadFile(*in)
+	if err != nil {
+		return err
+	}
+
+	unsignedTx, err := wallet_manager.DecodeUnsignedTx(string(value))
+	if err != nil {
+		return err
+	}
+
+	// signing never connects to a node
+	wallet, err := openWallet(wFlags, false)
+	if err != nil {
+		return err
+	}
+	defer closeWallet(wallet)
+
+	err = lookup_table.Load(nil)
+	if err != nil {
+		return err
+	}
+
+	tx, txFees, err := wallet.SignTransaction(unsignedTx, false)
+	if err != nil {
+		return err
+	}
+
+	txHex := hex.EncodeToString(tx.Serialize())
+	if *out != "" {
+		err = os.WriteFile(*out, []byte(txHex), 0600)
+		if err != nil {
+			return err
+		}
+	}
+
+	fees := utils.ShiftNumber{Number: txFees + unsignedTx.GasFees, Decimals: 5}.Format()
+	data := map[string]interface{}{
+		"txid": tx.GetHash().String(),
+		"fees": fees,
+	}
+
+	t := &table{headers: []string{"KEY", "VALUE"}}
+	t.add("TxId", tx.GetHash().String())
+	t.add("Fees", fees)
+
+	if *out == "" {
+		data["tx"] = txHex
+		t.add("Tx", txHex)
+	} else {
+		data["file"] = *out
+		t.add("File", *out)
+	}
+
+	return output(data, t)
+}
+
+func cmdBroadcast(args []string) error {
+	fs := newFlagSet("broadcast")
+	wFlags := addWalletFlags(fs)
+	in := fs.String("in", "", "signed transaction file")
+	fs.Parse(args)
+
+	if *in == "" {
+		return fmt.Errorf("--in is required")
+	}
+
+	data, err := os.ReadFile(*in)
+	if err != nil {
+		return err
+	}
+
+	tx, err := wallet_manager.DecodeSignedTx(string(data))
+	if err != nil {
+		return err
+	}
+
+	wallet, err := openWallet(wFlags, true)
+	if err != nil {
+		return err
+	}
+	defer closeWallet(wallet)
+
+	err = wallet.BroadcastSignedTx(tx)
+	if err != nil {
+		return err
+	}
+
+	txId := tx.GetHash().String()
+	t := &table{headers: []string{"KEY", "VALUE"}}
+	t.add("TxId", txId)
+	return output(map[string]interface{}{"txid": txId}, t)
+}
+
+func cmdHistory(args []string) error {
+	fs := newFlagSet("history")
+	wFlags := addWalletFlags(fs)
+	scId := fs.String("scid", "", "token history (default to Dero)")
+	filter := fs.String("filter", "all", "all, in, out or coinbase")
+	limit := fs.Int64("limit", 0, "max number of transactions")
+	offset := fs.Int64("offset", 0, "number of transactions to skip")
+	offline := fs.Bool("offline", false, "don't sync with the node before listing")
+	fs.Parse(args)
+
+	wallet, err := openWallet(wFlags, !*offline)
+	if err != nil {
+		return err
+	}
+	defer closeWallet(wallet)
+
+	token := wallet_manager.DeroToken()
+	if *scId != "" {
+		token, err = wallet_manager.GetTokenBySCID(*scId)
+		if err != nil {
+			return err
+		}
+
+		if !*offline && !wallet.IsWatchOnly() {
+			err = wallet.Memory.Sync_Wallet_Memory_With_Daemon_internal(token.GetHash())
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	var params wallet_manager.GetEntriesParams
+	switch *filter {
+	case "all":
+	case "in":
+		params.In = sql.NullBool{Bool: true, Valid: true}
+	case "out":
+		params.Out = sql.NullBool{Bool: true, Valid: true}
+	case "coinbase":
+		params.Coinbase = sql.NullBool{Bool: true, Valid: true}
+	default:
+		return fmt.Errorf("invalid filter [%s]", *filter)
+	}
+
+	if *limit > 0 {
+		params.Limit = sql.NullInt64{Int64: *limit, Valid: true}
+	}
+
+	if *offset > 0 {
+		params.Offset = sql.NullInt64{Int64: *offset, Valid: true}
+	}
+
+	hash := token.GetHash()
+	entries, err := wallet.GetEntries(&hash, params)
+	if err != nil {
+		return err
+	}
+
+	type entryJson struct {
+		TXID        string    `json:"txid"`
+		Height      uint64    `json:"height"`
+		Time        time.Time `json:"time"`
+		Incoming    bool      `json:"incoming"`
+		Coinbase    bool      `json:"coinbase"`
+		Amount      uint64    `json:"amount"`
+		Burn        uint64    `json:"burn"`
+		Fees        uint64    `json:"fees"`
+		Sender      string    `json:"sender,omitempty"`
+		Destination string    `json:"destination,omitempty"`
+	}
+
+	data := []entryJson{}
+	t := &table{headers: []string{"DATE", "DIRECTION", "TXID", "AMOUNT"}}
+	for _, entry := range entries {
+		direction := "out"
+		if entry.Coinbase {
+			direction = "coinbase"
+		} else if entry.Incoming {
+			direction = "in"
+		}
+
+		data = append(data, entryJson{
+			TXID:        entry.TXID,
+			Height:      entry.Height,
+			Time:        entry.Time,
+			Incoming:    entry.Incoming,
+			Coinbase:    entry.Coinbase,
+			Amount:      entry.Amount,
+			Burn:        entry.Burn,
+			Fees:        entry.Fees,
+			Sender:      entry.Sender,
+			Destination: entry.Destination,
+		})
+
+		amount := utils.ShiftNumber{Number: entry.Amount, Decimals: int(token.Decimals)}
+		t.add(entry.Time.Format("2006-01-02 15:04"), direction, entry.TXID, amount.Format())
+	}
+
+	return output(data, t)
+}
+
+func cmdContacts(args []string) error {
+	fs := newFlagSet("contacts")
+	wFlags := addWalletFlags(fs)
+	name := fs.String("name", "", "contact name (add)")
+	addr := fs.String("addr", "", "contact address (add, del)")
+	note := fs.String("note", "", "contact note (add)")
+	action := parseAction(fs, args, "list")
+
+	wallet, err := openWallet(wFlags, false)
+	if err != nil {
+		return err
+	}
+	defer closeWallet(wallet)
+
+	switch action {
+	case "list":
+	case "add":
+		if *name == "" || *addr == "" {
+			return fmt.Errorf("--name and --addr are required")
+		}
+
+		_, err = rpc.NewAddress(*addr)
+		if err != nil {
+			return err
+		}
+
+		err = wallet.StoreContact(wallet_manager.Contact{Name: *name, Addr: *addr, Note: *note})
+		if err != nil {
+			return err
+		}
+	case "del":
+		if *addr == "" {
+			return fmt.Errorf("--addr is required")
+		}
+
+		err = wallet.DelContact(*addr)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("invalid action [%s], use list, add or del", action)
+	}
+
+	contacts, err := wallet.GetContacts(wallet_manager.GetContactsParams{})
+	if err != nil {
+		return err
+	}
+
+	if contacts == nil {
+		contacts = []wallet_manager.Contact{}
+	}
+
+	t := &table{headers: []string{"NAME", "ADDRESS", "NOTE"}}
+	for _, contact := range contacts {
+		t.add(contact.Name, contact.Addr, contact.Note)
+	}
+
+	return output(contacts, t)
+}
+
+func cmdTokens(args []string) error {
+	fs := newFlagSet("tokens")
+	wFlags := addWalletFlags(fs)
+	scId := fs.String("scid", "", "token scid (add)")
+	favorite := fs.Bool("favorite", false, "add as favorite (add)")
+	action := parseAction(fs, args, "list")
+
+	wallet, err := openWallet(wFlags, action == "add")
+	if err != nil {
+		return err
+	}
+	defer closeWallet(wallet)
+
+	switch action {
+	case "list":
+	case "add":
+		if *scId == "" {
+			return fmt.Errorf("--scid is required")
+		}
+
+		token, err := wallet_manager.GetTokenBySCID(*scId)
+		if err != nil {
+			return err
+		}
+
+		token.IsFavorite = sql.NullBool{Bool: *favorite, Valid: true}
+		token.AddedTimestamp = sql.NullInt64{Int64: time.Now().Unix(), Valid: true}
+		err = wallet.InsertToken(*token)
+		if err != nil {
+			return err
+		}
+
+		wallet.Memory.TokenAdd(crypto.HashHexToHash(*scId))
+	default:
+		return fmt.Errorf("invalid action [%s], use list or add", action)
+	}
+
+	tokens, err := wallet.GetTokens(wallet_manager.GetTokensParams{})
+	if err != nil {
+		return err
+	}
+
+	type tokenJson struct {
+		SCID         string `json:"scid"`
+		Name         string `json:"name"`
+		Symbol       string `json:"symbol"`
+		Decimals     int64  `json:"decimals"`
+		StandardType string `json:"standard_type"`
+		IsFavorite   bool   `json:"is_favorite"`
+	}
+
+	data := []tokenJson{}
+	t := &table{headers: []string{"NAME", "SYMBOL", "SCID", "TYPE", "FAVORITE"}}
+	for _, token := range tokens {
+		data = append(data, tokenJson{
+			SCID:         token.SCID,
+			Name:         token.Name,
+			Symbol:       token.Symbol.String,
+			Decimals:     token.Decimals,
+			StandardType: string(token.StandardType),
+			IsFavorite:   token.IsFavorite.Bool,
+		})
+		t.add(token.Name, token.Symbol.String, token.SCID, token.StandardType, token.IsFavorite.Bool)
+	}
+
+	return output(data, t)
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/deroproject/derohe/globals"
+	"github.com/secretsystems/secret-wallet/app_db"
+	"github.com/secretsystems/secret-wallet/caching"
+	"github.com/secretsystems/secret-wallet/lookup_table"
+	"github.com/secretsystems/secret-wallet/node_manager"
+	"github.com/secretsystems/secret-wallet/settings"
+)
+
+// headless version of the wallet
+// it uses the same app dir, app.db and wallets as the gui but never opens a window
+
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+var commands = []command{
+	{name: "wallets", usage: "list wallets", run: cmdWallets},
+	{name: "create", usage: "create a new wallet (random, seed, hex seed or wallet file)", run: cmdCreate},
+	{name: "info", usage: "show wallet address, height and registration", run: cmdInfo},
+	{name: "balance", usage: "show Dero and token balances", run: cmdBalance},
+	{name: "send", usage: "send Dero or tokens", run: cmdSend},
+	{name: "sign", usage: "sign an unsigned transaction offline", run: cmdSign},
+	{name: "broadcast", usage: "broadcast a signed transaction", run: cmdBroadcast},
+	{name: "history", usage: "list wallet transactions", run: cmdHistory},
+	{name: "contacts", usage: "list, add or remove contacts", run: cmdContacts},
+	{name: "tokens", usage: "list or add tokens", run: cmdTokens},
+}
+
+var (
+	jsonOutput   bool
+	nodeEndpoint string
+	network      string
+	testnet      bool
+)
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: secret-wallet-cli [flags] <command> [command flags]\n\n")
+	fmt.Fprintf(out, "Commands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "  %-10s %s\n", cmd.name, cmd.usage)
+	}
+
+	fmt.Fprintf(out, "\nFlags:\n")
+	flag.PrintDefaults()
+}
+
+func load() error {
+	globals.Arguments["--debug"] = false
+	globals.Arguments["--flog-level"] = nil
+	globals.Arguments["--log-dir"] = nil
+	globals.Arguments["--help"] = false
+	globals.Arguments["--version"] = false
+
+	err := settings.Load()
+	if err != nil {
+		return err
+	}
+
+	if testnet && network == "" {
+		network = settings.NetworkTestnet
+	}
+
+	// the network selected in the app unless a flag is set - never saved
+	if network != "" {
+		err = settings.SetNetwork(network)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = caching.Load()
+	if err != nil {
+		return err
+	}
+
+	err = app_db.Load()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// only commands talking to the blockchain need the lookup table and a node
+func connect() error {
+	err := lookup_table.Load(nil)
+	if err != nil {
+		return err
+	}
+
+	endpoint := nodeEndpoint
+	if endpoint == "" {
+		endpoint = settings.App.NodeEndpoint
+	}
+
+	if endpoint == "" {
+		return fmt.Errorf("no node endpoint, use --node or select a node in the app")
+	}
+
+	return node_manager.Connect(app_db.NodeConnection{Endpoint: endpoint}, false)
+}
+
+func main() {
+	flag.Usage = usage
+	flag.BoolVar(&jsonOutput, "json", false, "output as json instead of tables")
+	flag.StringVar(&nodeEndpoint, "node", "", "node endpoint (default to the one selected in the app)")
+	flag.StringVar(&network, "network", "", "mainnet, testnet or simulator (default to the one selected in the app)")
+	flag.BoolVar(&testnet, "testnet", false, "use testnet, same as --network testnet")
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == args[0] {
+			cmd = &commands[i]
+			break
+		}
+	}
+
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", args[0])
+		usage()
+		os.Exit(2)
+	}
+
+	err := load()
+	if err != nil {
+		fatal(err)
+	}
+
+	err = cmd.run(args[1:])
+	if err != nil {
+		fatal(err)
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintf(os.Stderr, "error: %s\n", err)
+	os.Exit(1)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+type table struct {
+	headers []string
+	rows    [][]string
+}
+
+func (t *table) add(values ...interface{}) {
+	var row []string
+	for _, value := range values {
+		row = append(row, fmt.Sprint(value))
+	}
+
+	t.rows = append(t.rows, row)
+}
+
+func (t *table) print() {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(t.headers, "\t"))
+	for _, row := range t.rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+
+	w.Flush()
+}
+
+// print json when --json is set otherwise the table
+func output(data interface{}, t *table) error {
+	if jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(data)
+	}
+
+	t.print()
+	return nil
+}
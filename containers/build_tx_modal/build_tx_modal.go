@@ -35,6 +35,7 @@ type TxPayload struct {
 	Ringsize   uint64
 	SCArgs     rpc.Arguments
 	TokensInfo []*wallet_manager.Token
+	TxType     *transaction.TransactionType // stored in outgoing_txs instead of the transaction type
 }
 
 func (t TxPayload) GetTokenInfo(scId crypto.Hash) *wallet_manager.Token {
@@ -82,11 +83,13 @@ type BuildTxModal struct {
 	animationLoading *animation.Animation
 	buttonClose      *components.Button
 
-	building bool
-	builtTx  *transaction.Transaction
-	txFees   uint64
-	gasFees  uint64
-	txSent   bool
+	building   bool
+	builtTx    *transaction.Transaction
+	unsignedTx *wallet_manager.UnsignedTx // watch-only wallets export the tx for offline signing
+	txFees     uint64
+	gasFees    uint64
+	txSent     bool
+	sentTx     *transaction.Transaction
 
 	txPayload TxPayload
 }
@@ -171,11 +174,30 @@ func (b *BuildTxModal) Open(txPayload TxPayload) {
 	b.txSent = false
 	b.txPayload = txPayload
 	b.builtTx = nil
+	b.unsignedTx = nil
 
 	b.modal.SetVisible(true)
 	b.animationLoading.Reset().Start()
 	b.building = true
 
+	if wallet.IsWatchOnly() {
+		unsignedTx, err := wallet.PrepareTransaction(txPayload.Transfers, txPayload.Ringsize, txPayload.SCArgs)
+		b.animationLoading.Pause()
+
+		if err != nil {
+			b.modal.SetVisible(false)
+			notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+			notification_modals.ErrorInstance.SetVisible(true, 0)
+		} else {
+			b.building = false
+			b.unsignedTx = &unsignedTx
+			b.gasFees = unsignedTx.GasFees
+			b.txFees = 0
+		}
+
+		return
+	}
+
 	tx, _, gasFees, err := wallet.BuildTransaction(txPayload.Transfers, txPayload.Ringsize, txPayload.SCArgs, false)
 	b.animationLoading.Pause()
 
@@ -200,11 +222,21 @@ func (b *BuildTxModal) TxSent() bool {
 	return false
 }
 
+// the id of an installed contract is the txid
+func (b *BuildTxModal) SentTx() *transaction.Transaction {
+	return b.sentTx
+}
+
 func (b *BuildTxModal) sendTx() error {
 	b.buttonSend.SetLoading(true)
 	wallet := wallet_manager.OpenedWallet
 
-	err := wallet.InsertOutgoingTx(b.builtTx)
+	txType := b.builtTx.TransactionType
+	if b.txPayload.TxType != nil {
+		txType = *b.txPayload.TxType
+	}
+
+	err := wallet.InsertOutgoingTxWithType(b.builtTx, txType)
 	if err != nil {
 		b.buttonSend.SetLoading(false)
 		return err
@@ -220,6 +252,29 @@ func (b *BuildTxModal) sendTx() error {
 	b.modal.SetVisible(false)
 	recent_txs_modal.Instance.SetVisible(true)
 	b.txSent = true
+	b.sentTx = b.builtTx
+	return nil
+}
+
+// the file is signed by the offline wallet and the signed tx is broadcasted from the wallet settings
+func (b *BuildTxModal) exportUnsignedTx() error {
+	data, err := b.unsignedTx.Encode()
+	if err != nil {
+		return err
+	}
+
+	file, err := app_instance.Explorer.CreateFile(fmt.Sprintf("unsigned_tx_%d.json", b.unsignedTx.Timestamp))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write([]byte(data))
+	if err != nil {
+		return err
+	}
+
+	b.modal.SetVisible(false)
 	return nil
 }
 
@@ -227,7 +282,20 @@ func (b *BuildTxModal) layout(gtx layout.Context, th *material.Theme) {
 	wallet := wallet_manager.OpenedWallet
 
 	if b.buttonSend.Clicked() {
-		password_modal.Instance.SetVisible(true)
+		if b.unsignedTx != nil {
+			go func() {
+				err := b.exportUnsignedTx()
+				if err != nil {
+					notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+					notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+				} else {
+					notification_modals.SuccessInstance.SetText(lang.Translate("Success"), lang.Translate("Sign the exported file with the offline wallet."))
+					notification_modals.SuccessInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+				}
+			}()
+		} else {
+			password_modal.Instance.SetVisible(true)
+		}
 	}
 
 	if b.buttonClose.Clicked() {
@@ -288,7 +356,7 @@ func (b *BuildTxModal) layout(gtx layout.Context, th *material.Theme) {
 							}),
 						)
 					}))
-			} else if b.builtTx != nil {
+			} else if b.builtTx != nil || b.unsignedTx != nil {
 				totalDero := b.txPayload.TotalDeroAmount()
 
 				childs = append(childs,
@@ -390,8 +458,13 @@ func (b *BuildTxModal) layout(gtx layout.Context, th *material.Theme) {
 								return lbl.Layout(gtx)
 							}),
 							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-								fees := globals.FormatMoney(b.txFees)
-								lbl := material.Label(th, unit.Sp(16), fmt.Sprintf("%s DERO", fees))
+								// the tx size is only known once signed
+								txt := fmt.Sprintf("%s DERO", globals.FormatMoney(b.txFees))
+								if b.unsignedTx != nil {
+									txt = lang.Translate("When signing")
+								}
+
+								lbl := material.Label(th, unit.Sp(16), txt)
 								return lbl.Layout(gtx)
 							}),
 						)
@@ -502,6 +575,12 @@ func (b *BuildTxModal) layout(gtx layout.Context, th *material.Theme) {
 					}),
 					layout.Rigid(layout.Spacer{Height: unit.Dp(15)}.Layout),
 					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						if b.unsignedTx != nil {
+							b.buttonSend.Text = lang.Translate("EXPORT UNSIGNED TX")
+							b.buttonSend.Style.Colors = theme.Current.ButtonPrimaryColors
+							return b.buttonSend.Layout(gtx, th)
+						}
+
 						b.buttonSend.Text = lang.Translate("SEND TRANSACTION")
 						b.buttonSend.Style.Colors = theme.Current.ButtonPrimaryColors
 						return b.buttonSend.Layout(gtx, th)
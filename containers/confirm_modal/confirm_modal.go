@@ -15,6 +15,8 @@ import (
 
 type ConfirmText struct {
 	Prompt string
+	// smaller text under the prompt
+	Detail string
 	Yes    string
 	No     string
 }
@@ -88,21 +90,36 @@ func (c *ConfirmModal) Open(confirmText ConfirmText) chan bool {
 	}
 
 	c.Modal.SetVisible(true)
-	c.resChan = make(chan bool)
+	// buffered so answering never blocks the ui if nobody reads it anymore
+	c.resChan = make(chan bool, 1)
 	return c.resChan
 }
 
+func (c *ConfirmModal) answer(yes bool) {
+	if c.resChan == nil {
+		return
+	}
+
+	c.resChan <- yes
+	close(c.resChan)
+	c.resChan = nil
+}
+
 func (c *ConfirmModal) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
 	if c.buttonYes.Clicked() {
-		c.resChan <- true
+		c.answer(true)
 		c.Modal.SetVisible(false)
-		close(c.resChan)
 	}
 
 	if c.buttonNo.Clicked() {
-		c.resChan <- false
+		c.answer(false)
 		c.Modal.SetVisible(false)
+	}
+
+	// closed with an outside click or the escape key
+	if c.Modal.Closed() && c.resChan != nil {
 		close(c.resChan)
+		c.resChan = nil
 	}
 
 	var lblSize layout.Dimensions
@@ -115,6 +132,22 @@ func (c *ConfirmModal) Layout(gtx layout.Context, th *material.Theme) layout.Dim
 					lblSize = label.Layout(gtx)
 					return lblSize
 				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					if c.confirmText.Detail == "" {
+						return layout.Dimensions{}
+					}
+
+					return layout.Inset{Top: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						label := material.Label(th, unit.Sp(14), c.confirmText.Detail)
+						label.Color = theme.Current.TextMuteColor
+						dims := label.Layout(gtx)
+						if dims.Size.X > lblSize.Size.X {
+							lblSize = dims
+						}
+
+						return dims
+					})
+				}),
 				layout.Rigid(layout.Spacer{Height: unit.Dp(10)}.Layout),
 				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 					gtx.Constraints.Min.X = lblSize.Size.X
@@ -12,6 +12,7 @@ import (
 	"github.com/secretsystems/secret-wallet/containers/prompt_modal"
 	"github.com/secretsystems/secret-wallet/containers/qrcode_scan_modal"
 	"github.com/secretsystems/secret-wallet/containers/recent_txs_modal"
+	"github.com/secretsystems/secret-wallet/containers/tx_filter_modal"
 )
 
 func Load() {
@@ -26,4 +27,5 @@ func Load() {
 	password_modal.LoadInstance()
 	prompt_modal.LoadInstance()
 	listselect_modal.LoadInstance()
+	tx_filter_modal.LoadInstance()
 }
@@ -3,8 +3,10 @@ package node_status_bar
 import (
 	"fmt"
 	"image/color"
+	"strings"
 	"time"
 
+	"gioui.org/font"
 	"gioui.org/io/pointer"
 	"gioui.org/layout"
 	"gioui.org/op"
@@ -13,12 +15,16 @@ import (
 	"gioui.org/unit"
 	"gioui.org/widget"
 	"gioui.org/widget/material"
+	"github.com/secretsystems/secret-wallet/app_db"
 	"github.com/secretsystems/secret-wallet/app_instance"
+	"github.com/secretsystems/secret-wallet/containers/notification_modals"
 
 	"github.com/secretsystems/secret-wallet/lang"
 	"github.com/secretsystems/secret-wallet/node_manager"
 	"github.com/secretsystems/secret-wallet/pages"
 	page_node "github.com/secretsystems/secret-wallet/pages/node"
+	"github.com/secretsystems/secret-wallet/prefabs"
+	"github.com/secretsystems/secret-wallet/settings"
 	"github.com/secretsystems/secret-wallet/theme"
 	"github.com/secretsystems/secret-wallet/wallet_manager"
 )
@@ -35,6 +41,19 @@ func LoadInstance() *NodeStatusBar {
 		clickable:      new(widget.Clickable),
 		RemoteNodeInfo: page_node.NewRemoteNodeInfo(3 * time.Second),
 	}
+
+	node_manager.OnSwitch = func(node app_db.NodeConnection, reason string) {
+		name := node.Name
+		if name == "" {
+			name = node.Endpoint
+		}
+
+		nodeStatusBar.Update()
+		notification_modals.InfoInstance.SetText(lang.Translate("Node switched"), fmt.Sprintf("%s (%s)", name, reason))
+		notification_modals.InfoInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		app_instance.Window.Invalidate()
+	}
+
 	Instance = nodeStatusBar
 	return nodeStatusBar
 }
@@ -111,6 +130,18 @@ func (n *NodeStatusBar) Layout(gtx layout.Context, th *material.Theme) layout.Di
 					lbl.Color = theme.Current.NodeStatusTextColor
 					return lbl.Layout(gtx)
 				}),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					// test funds must not be mistaken for real ones
+					lbl := material.Label(th, unit.Sp(16), strings.ToUpper(prefabs.NetworkName(settings.App.Network)))
+					lbl.Color = theme.Current.NodeStatusTextColor
+					if settings.App.Network != settings.NetworkMainnet {
+						lbl.Color = theme.Current.NodeStatusDotYellowColor
+						lbl.Font.Weight = font.Bold
+					}
+
+					return lbl.Layout(gtx)
+				}),
 			)
 		})
 	})
@@ -17,6 +17,7 @@ import (
 	"gioui.org/widget"
 	"gioui.org/widget/material"
 	"gioui.org/x/browser"
+	"github.com/deroproject/derohe/transaction"
 	"github.com/deroproject/derohe/walletapi"
 	"github.com/secretsystems/secret-wallet/app_instance"
 	"github.com/secretsystems/secret-wallet/components"
@@ -250,6 +251,15 @@ func NewTxItem(tx wallet_manager.OutgoingTx) *TxItem {
 	}
 }
 
+// the wallet types look like any other sc call on chain
+func txTypeText(tx wallet_manager.OutgoingTx) string {
+	if tx.TxType.Valid && transaction.TransactionType(tx.TxType.Int32) == wallet_manager.OUTGOING_TX_DEX_BRIDGE_OUT {
+		return lang.Translate("Bridge out")
+	}
+
+	return ""
+}
+
 func (item *TxItem) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
 	var status string
 	confirmations := uint64(0)
@@ -320,6 +330,16 @@ func (item *TxItem) Layout(gtx layout.Context, th *material.Theme) layout.Dimens
 						lbl.Font.Weight = font.Bold
 						return lbl.Layout(gtx)
 					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						typeText := txTypeText(item.tx)
+						if typeText == "" {
+							return layout.Dimensions{}
+						}
+
+						lbl := material.Label(th, unit.Sp(14), typeText)
+						lbl.Color = theme.Current.TextMuteColor
+						return lbl.Layout(gtx)
+					}),
 					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 						lbl := material.Label(th, unit.Sp(16), status)
 						return lbl.Layout(gtx)
@@ -0,0 +1,348 @@
+package tx_filter_modal
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/secretsystems/secret-wallet/app_instance"
+	"github.com/secretsystems/secret-wallet/components"
+	"github.com/secretsystems/secret-wallet/containers/notification_modals"
+	"github.com/secretsystems/secret-wallet/lang"
+	"github.com/secretsystems/secret-wallet/prefabs"
+	"github.com/secretsystems/secret-wallet/router"
+	"github.com/secretsystems/secret-wallet/theme"
+	"github.com/secretsystems/secret-wallet/utils"
+	"github.com/secretsystems/secret-wallet/wallet_manager"
+)
+
+const DATE_FORMAT = "2006-01-02"
+
+// values of the filter form - converted to entries params with the token decimals
+type TxFilter struct {
+	Search     string
+	Address    string
+	MinAmount  string
+	MaxAmount  string
+	DateFrom   string
+	DateTo     string
+	MinDstPort string
+	MaxDstPort string
+}
+
+func (f TxFilter) IsEmpty() bool {
+	return f == TxFilter{}
+}
+
+func parseAmount(value string, decimals int) (sql.NullInt64, error) {
+	amount := utils.ShiftNumber{Decimals: decimals}
+	err := amount.Parse(value)
+	if err != nil {
+		return sql.NullInt64{}, fmt.Errorf("invalid amount [%s]", value)
+	}
+
+	number := int64(math.MaxInt64)
+	if amount.Number < math.MaxInt64 {
+		number = int64(amount.Number)
+	}
+
+	return sql.NullInt64{Int64: number, Valid: true}, nil
+}
+
+func parseDate(value string) (time.Time, error) {
+	date, err := time.ParseInLocation(DATE_FORMAT, value, time.Local)
+	if err != nil {
+		return date, fmt.Errorf("invalid date [%s] expected format is YYYY-MM-DD", value)
+	}
+
+	return date, nil
+}
+
+func parsePort(value string) (*uint64, error) {
+	port, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid destination port [%s]", value)
+	}
+
+	return &port, nil
+}
+
+func (f TxFilter) Params(decimals int) (params wallet_manager.GetEntriesParams, err error) {
+	if f.Search != "" {
+		params.Search = sql.NullString{String: f.Search, Valid: true}
+	}
+
+	// the address can be the sender or the destination
+	if f.Address != "" {
+		params.Or = []wallet_manager.GetEntriesParams{
+			{Sender: sql.NullString{String: f.Address, Valid: true}},
+			{Receiver: sql.NullString{String: f.Address, Valid: true}},
+		}
+	}
+
+	if f.MinAmount != "" {
+		params.AmountGreaterOrEqualThan, err = parseAmount(f.MinAmount, decimals)
+		if err != nil {
+			return
+		}
+	}
+
+	if f.MaxAmount != "" {
+		params.AmountLessOrEqualThan, err = parseAmount(f.MaxAmount, decimals)
+		if err != nil {
+			return
+		}
+	}
+
+	if f.DateFrom != "" {
+		date, err := parseDate(f.DateFrom)
+		if err != nil {
+			return params, err
+		}
+
+		params.DateFrom = sql.NullTime{Time: date, Valid: true}
+	}
+
+	if f.DateTo != "" {
+		date, err := parseDate(f.DateTo)
+		if err != nil {
+			return params, err
+		}
+
+		// include the whole day
+		params.DateTo = sql.NullTime{Time: date.AddDate(0, 0, 1).Add(-time.Second), Valid: true}
+	}
+
+	if f.MinDstPort != "" {
+		params.DstPortFrom, err = parsePort(f.MinDstPort)
+		if err != nil {
+			return
+		}
+	}
+
+	if f.MaxDstPort != "" {
+		params.DstPortTo, err = parsePort(f.MaxDstPort)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+type TxFilterModal struct {
+	Modal *components.Modal
+
+	list         *widget.List
+	txtSearch    *prefabs.TextField
+	txtAddress   *prefabs.TextField
+	txtMinAmount *prefabs.TextField
+	txtMaxAmount *prefabs.TextField
+	txtDateFrom  *prefabs.TextField
+	txtDateTo    *prefabs.TextField
+	txtMinPort   *prefabs.TextField
+	txtMaxPort   *prefabs.TextField
+	buttonApply  *components.Button
+	buttonReset  *components.Button
+
+	decimals   int
+	filterChan chan TxFilter
+}
+
+var Instance *TxFilterModal
+
+func LoadInstance() {
+	list := new(widget.List)
+	list.Axis = layout.Vertical
+
+	modal := components.NewModal(components.ModalStyle{
+		CloseOnOutsideClick: true,
+		CloseOnInsideClick:  false,
+		Direction:           layout.S,
+		Rounded:             components.UniformRounded(unit.Dp(10)),
+		Inset:               layout.UniformInset(25),
+		Animation:           components.NewModalAnimationUp(),
+	})
+
+	buttonApply := components.NewButton(components.ButtonStyle{
+		Rounded:   components.UniformRounded(unit.Dp(5)),
+		TextSize:  unit.Sp(14),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+	})
+	buttonApply.Label.Alignment = text.Middle
+	buttonApply.Style.Font.Weight = font.Bold
+
+	buttonReset := components.NewButton(components.ButtonStyle{
+		Rounded:   components.UniformRounded(unit.Dp(5)),
+		TextSize:  unit.Sp(14),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+	})
+	buttonReset.Label.Alignment = text.Middle
+	buttonReset.Style.Font.Weight = font.Bold
+
+	Instance = &TxFilterModal{
+		Modal:        modal,
+		list:         list,
+		txtSearch:    prefabs.NewTextField(),
+		txtAddress:   prefabs.NewTextField(),
+		txtMinAmount: prefabs.NewNumberTextField(),
+		txtMaxAmount: prefabs.NewNumberTextField(),
+		txtDateFrom:  prefabs.NewTextField(),
+		txtDateTo:    prefabs.NewTextField(),
+		txtMinPort:   prefabs.NewNumberTextField(),
+		txtMaxPort:   prefabs.NewNumberTextField(),
+		buttonApply:  buttonApply,
+		buttonReset:  buttonReset,
+	}
+
+	app_instance.Router.AddLayout(router.KeyLayout{
+		DrawIndex: 2,
+		Layout: func(gtx layout.Context, th *material.Theme) {
+			Instance.Layout(gtx, th)
+		},
+	})
+}
+
+// the filter is sent back when applied - the decimals are used to validate amounts
+func (t *TxFilterModal) Open(filter TxFilter, decimals int) chan TxFilter {
+	t.txtSearch.SetValue(filter.Search)
+	t.txtAddress.SetValue(filter.Address)
+	t.txtMinAmount.SetValue(filter.MinAmount)
+	t.txtMaxAmount.SetValue(filter.MaxAmount)
+	t.txtDateFrom.SetValue(filter.DateFrom)
+	t.txtDateTo.SetValue(filter.DateTo)
+	t.txtMinPort.SetValue(filter.MinDstPort)
+	t.txtMaxPort.SetValue(filter.MaxDstPort)
+	t.decimals = decimals
+
+	t.Modal.SetVisible(true)
+	t.filterChan = make(chan TxFilter)
+	return t.filterChan
+}
+
+func (t *TxFilterModal) value() TxFilter {
+	return TxFilter{
+		Search:     strings.TrimSpace(t.txtSearch.Value()),
+		Address:    strings.TrimSpace(t.txtAddress.Value()),
+		MinAmount:  strings.TrimSpace(t.txtMinAmount.Value()),
+		MaxAmount:  strings.TrimSpace(t.txtMaxAmount.Value()),
+		DateFrom:   strings.TrimSpace(t.txtDateFrom.Value()),
+		DateTo:     strings.TrimSpace(t.txtDateTo.Value()),
+		MinDstPort: strings.TrimSpace(t.txtMinPort.Value()),
+		MaxDstPort: strings.TrimSpace(t.txtMaxPort.Value()),
+	}
+}
+
+func (t *TxFilterModal) submit(filter TxFilter) {
+	_, err := filter.Params(t.decimals)
+	if err != nil {
+		notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+		notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		return
+	}
+
+	t.Modal.SetVisible(false)
+	filterChan := t.filterChan
+	go func() {
+		filterChan <- filter
+		close(filterChan)
+	}()
+}
+
+func (t *TxFilterModal) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	if t.buttonApply.Clicked() {
+		t.submit(t.value())
+	}
+
+	if t.buttonReset.Clicked() {
+		t.submit(TxFilter{})
+	}
+
+	t.Modal.Style.Colors = theme.Current.ModalColors
+	return t.Modal.Layout(gtx, nil, func(gtx layout.Context) layout.Dimensions {
+		gtx.Constraints.Max.Y = gtx.Constraints.Max.Y * 7 / 10
+
+		widgets := []layout.Widget{
+			func(gtx layout.Context) layout.Dimensions {
+				lbl := material.Label(th, unit.Sp(20), lang.Translate("Filter transactions"))
+				lbl.Font.Weight = font.Bold
+				return lbl.Layout(gtx)
+			},
+			func(gtx layout.Context) layout.Dimensions {
+				return t.txtSearch.Layout(gtx, th, lang.Translate("Search"), lang.Translate("Comment, payload, txid or address"))
+			},
+			func(gtx layout.Context) layout.Dimensions {
+				return t.txtAddress.Layout(gtx, th, lang.Translate("Address"), lang.Translate("Sender or destination"))
+			},
+			func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						return t.txtMinAmount.Layout(gtx, th, lang.Translate("Min amount"), "")
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						return t.txtMaxAmount.Layout(gtx, th, lang.Translate("Max amount"), "")
+					}),
+				)
+			},
+			func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						return t.txtDateFrom.Layout(gtx, th, lang.Translate("From"), "YYYY-MM-DD")
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						return t.txtDateTo.Layout(gtx, th, lang.Translate("To"), "YYYY-MM-DD")
+					}),
+				)
+			},
+			func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						return t.txtMinPort.Layout(gtx, th, lang.Translate("Min port"), "")
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						return t.txtMaxPort.Layout(gtx, th, lang.Translate("Max port"), "")
+					}),
+				)
+			},
+			func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						t.buttonReset.Text = lang.Translate("RESET")
+						t.buttonReset.Style.Colors = theme.Current.ButtonSecondaryColors
+						return t.buttonReset.Layout(gtx, th)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						t.buttonApply.Text = lang.Translate("APPLY")
+						t.buttonApply.Style.Colors = theme.Current.ButtonPrimaryColors
+						return t.buttonApply.Layout(gtx, th)
+					}),
+				)
+			},
+		}
+
+		listStyle := material.List(th, t.list)
+		listStyle.AnchorStrategy = material.Overlay
+
+		return listStyle.Layout(gtx, len(widgets), func(gtx layout.Context, index int) layout.Dimensions {
+			return layout.Inset{
+				Top: unit.Dp(10), Bottom: unit.Dp(10),
+				Left: unit.Dp(20), Right: unit.Dp(20),
+			}.Layout(gtx, widgets[index])
+		})
+	})
+}
@@ -12,6 +12,7 @@ require (
 	gioui.org v0.2.0
 	github.com/Masterminds/squirrel v1.5.4
 	github.com/deroproject/derohe v0.0.0-20240229002921-e9df1205b660
+	github.com/deroproject/graviton v0.0.0-20220130070622-2c248a53b2e1
 	github.com/gio-eui/ivgconv v0.0.0-20230728141110-3b7424472495
 	github.com/holiman/uint256 v1.2.3
 	github.com/makiuchi-d/gozxing v0.1.1
@@ -20,6 +21,7 @@ require (
 	github.com/tanema/gween v0.0.0-20221212145351-621cc8a459d1
 	github.com/xeonx/timeago v1.0.0-rc5
 	golang.org/x/exp/shiny v0.0.0-20230725093048-515e97ebf090
+	golang.org/x/term v0.23.0
 )
 
 require (
@@ -73,13 +75,12 @@ require (
 	github.com/creachadair/jrpc2 v0.36.0
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/dchest/siphash v1.2.3 // indirect
-	github.com/deroproject/graviton v0.0.0-20220130070622-2c248a53b2e1 // indirect
 	github.com/dustin/go-humanize v1.0.1 // indirect
 	github.com/fxamacker/cbor/v2 v2.4.0 // indirect
 	github.com/go-logr/logr v1.2.4 // indirect
 	github.com/go-logr/zapr v1.2.4 // indirect
 	github.com/go-text/typesetting v0.0.0-20230803102845-24e03d8b5372 // indirect
-	github.com/gorilla/websocket v1.5.0 // indirect
+	github.com/gorilla/websocket v1.5.0
 	github.com/klauspost/compress v1.15.15 // indirect
 	github.com/klauspost/cpuid/v2 v2.2.8 // indirect
 	github.com/lesismal/llib v1.1.13 // indirect
@@ -2,35 +2,47 @@ package main
 
 import (
 	"bytes"
-	"encoding/gob"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 
-	"github.com/deroproject/derohe/walletapi"
+	"github.com/secretsystems/secret-wallet/lookup_table/table_format"
 )
 
 // go run ./lookup_table/create
+// go run ./lookup_table/create -size 2097152
 
 func main() {
-	walletapi.Initialize_LookupTable(1, 1<<21)
+	size := flag.Int("size", table_format.DEFAULT_TABLE_SIZE, "lookup table size (multiple of 256)")
+	output := flag.String("output", "./lookup_table/lookup_table", "output file")
+	flag.Parse()
 
 	fmt.Println("Creating lookup table...")
-	var buffer bytes.Buffer
-	enc := gob.NewEncoder(&buffer)
-	t := walletapi.Balance_lookup_table
-	err := enc.Encode(t)
+	table, err := table_format.Generate(1, *size, func(value float64) {
+		fmt.Printf("\r%.0f%%", value*100)
+	})
 	if err != nil {
 		log.Fatal(err)
+	}
+	fmt.Println()
 
+	var buffer bytes.Buffer
+	err = table_format.Encode(&buffer, table)
+	if err != nil {
+		log.Fatal(err)
 	}
 
+	err = os.WriteFile(*output, buffer.Bytes(), os.ModePerm)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	err = os.WriteFile("./lookup_table/lookup_table", buffer.Bytes(), os.ModePerm)
+	// make sure we can read it back
+	_, header, err := table_format.Decode(buffer.Bytes())
 	if err != nil {
 		log.Fatal(err)
 	}
+
+	fmt.Printf("Lookup table created [version %d, size %d, checksum %x]\n", header.Version, header.Size, header.Checksum)
 }
@@ -3,25 +3,151 @@ package lookup_table
 import (
 	"bytes"
 	_ "embed"
-	"encoding/gob"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
 
 	"github.com/deroproject/derohe/walletapi"
+	"github.com/secretsystems/secret-wallet/lookup_table/table_format"
+	"github.com/secretsystems/secret-wallet/settings"
 )
 
+// file created with go run ./lookup_table/create
+//
 //go:embed lookup_table
 var LOOKUP_TABLE []byte
 
-func Load() error {
+type StatusFunc func(status string, err error)
 
-	var (
-		lookupTable walletapi.LookupTable
-		buffer      bytes.Buffer
-		enc         = gob.NewEncoder(&buffer)
-		err         = enc.Encode(lookupTable)
-	)
+var (
+	generating bool
+	mutex      sync.Mutex
+)
+
+func tablePath(size int) string {
+	return filepath.Join(settings.AppDir, fmt.Sprintf("lookup_table_%d", size))
+}
+
+func loadFile(path string) (walletapi.LookupTable, table_format.Header, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, table_format.Header{}, err
+	}
+
+	return table_format.Decode(data)
+}
+
+func saveFile(path string, table walletapi.LookupTable) error {
+	var buffer bytes.Buffer
+	err := table_format.Encode(&buffer, table)
+	if err != nil {
+		return err
+	}
+
+	// write to tmp first - we don't want a partial file if the app is closed
+	tmpPath := path + ".tmp"
+	err = os.WriteFile(tmpPath, buffer.Bytes(), 0600)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, path)
+}
+
+func use(table walletapi.LookupTable) {
+	walletapi.Balance_lookup_table = &table
+}
+
+// Load the lookup table with the size from settings.
+// It tries the embedded table, then the table stored in the app dir and finally generates it.
+// Generating a table can take minutes - it's done in the background while the embedded table is used
+// and swapped in once saved. The progress is given to onStatus, it can be nil.
+func Load(onStatus StatusFunc) error {
+	setStatus := func(status string, err error) {
+		if onStatus != nil {
+			onStatus(status, err)
+		}
+	}
+
+	size := settings.App.LookupTableSize
+	err := table_format.ValidateSize(size)
 	if err != nil {
 		return err
 	}
-	walletapi.Balance_lookup_table = &lookupTable
+
+	embeddedTable, embeddedHeader, embeddedErr := table_format.Decode(LOOKUP_TABLE)
+	if embeddedErr == nil {
+		use(embeddedTable)
+
+		if int(embeddedHeader.Size) == size {
+			return nil
+		}
+	}
+
+	path := tablePath(size)
+	_, err = os.Stat(path)
+	if err == nil {
+		table, _, err := loadFile(path)
+		if err == nil {
+			use(table)
+			return nil
+		}
+
+		// the file is corrupted - remove it and generate a new one
+		err = os.Remove(path)
+		if err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	generate := func() error {
+		lastPercent := -1
+		table, err := table_format.Generate(1, size, func(value float64) {
+			// the splash waits a bit on every status
+			percent := int(value * 100)
+			if percent != lastPercent {
+				lastPercent = percent
+				setStatus(fmt.Sprintf("Generating lookup table %d%%", percent), nil)
+			}
+		})
+		if err != nil {
+			return err
+		}
+
+		err = saveFile(path, table)
+		if err != nil {
+			return err
+		}
+
+		use(table)
+		return nil
+	}
+
+	// without the embedded table there is nothing to use in the meantime
+	if embeddedErr != nil {
+		return generate()
+	}
+
+	mutex.Lock()
+	if generating {
+		mutex.Unlock()
+		return nil
+	}
+	generating = true
+	mutex.Unlock()
+
+	go func() {
+		err := generate()
+
+		mutex.Lock()
+		generating = false
+		mutex.Unlock()
+
+		setStatus("Lookup table ready", err)
+	}()
+
 	return nil
 }
@@ -0,0 +1,186 @@
+package table_format
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+
+	"github.com/deroproject/derohe/cryptography/bn256"
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/deroproject/derohe/walletapi"
+)
+
+// header | gob encoded walletapi.LookupTable
+// header = magic (4 bytes) + version (uint32) + count (uint32) + size (uint32) + sha256 of the gob data (32 bytes)
+var MAGIC = []byte("SWLT")
+
+const VERSION = 1
+const HEADER_SIZE = 4 + 4 + 4 + 4 + sha256.Size
+
+const DEFAULT_TABLE_SIZE = 1 << 21
+const MIN_TABLE_SIZE = 1 << 16
+const MAX_TABLE_SIZE = 1 << 24
+
+type Header struct {
+	Version  uint32
+	Count    uint32
+	Size     uint32
+	Checksum [sha256.Size]byte
+}
+
+func ValidateSize(size int) error {
+	if size < MIN_TABLE_SIZE || size > MAX_TABLE_SIZE {
+		return fmt.Errorf("lookup table size must be between %d and %d", MIN_TABLE_SIZE, MAX_TABLE_SIZE)
+	}
+
+	if size&0xff != 0 {
+		return fmt.Errorf("lookup table size must be a multiple of 256")
+	}
+
+	return nil
+}
+
+func Encode(w io.Writer, table walletapi.LookupTable) error {
+	if len(table) == 0 {
+		return fmt.Errorf("lookup table is empty")
+	}
+
+	var data bytes.Buffer
+	err := gob.NewEncoder(&data).Encode(table)
+	if err != nil {
+		return err
+	}
+
+	header := make([]byte, HEADER_SIZE)
+	copy(header[0:4], MAGIC)
+	binary.BigEndian.PutUint32(header[4:8], VERSION)
+	binary.BigEndian.PutUint32(header[8:12], uint32(len(table)))
+	binary.BigEndian.PutUint32(header[12:16], uint32(len(table[0])))
+	checksum := sha256.Sum256(data.Bytes())
+	copy(header[16:], checksum[:])
+
+	_, err = w.Write(header)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data.Bytes())
+	return err
+}
+
+func DecodeHeader(data []byte) (header Header, err error) {
+	if len(data) < HEADER_SIZE {
+		err = fmt.Errorf("lookup table is too small")
+		return
+	}
+
+	if !bytes.Equal(data[0:4], MAGIC) {
+		err = fmt.Errorf("lookup table has an invalid header")
+		return
+	}
+
+	header.Version = binary.BigEndian.Uint32(data[4:8])
+	header.Count = binary.BigEndian.Uint32(data[8:12])
+	header.Size = binary.BigEndian.Uint32(data[12:16])
+	copy(header.Checksum[:], data[16:HEADER_SIZE])
+
+	if header.Version != VERSION {
+		err = fmt.Errorf("lookup table version [%d] is not supported", header.Version)
+		return
+	}
+
+	return
+}
+
+func Decode(data []byte) (table walletapi.LookupTable, header Header, err error) {
+	header, err = DecodeHeader(data)
+	if err != nil {
+		return
+	}
+
+	payload := data[HEADER_SIZE:]
+	checksum := sha256.Sum256(payload)
+	if checksum != header.Checksum {
+		err = fmt.Errorf("lookup table checksum mismatch")
+		return
+	}
+
+	err = gob.NewDecoder(bytes.NewReader(payload)).Decode(&table)
+	if err != nil {
+		return
+	}
+
+	if len(table) != int(header.Count) {
+		err = fmt.Errorf("lookup table count mismatch")
+		return
+	}
+
+	for _, t := range table {
+		if len(t) != int(header.Size) {
+			err = fmt.Errorf("lookup table size mismatch")
+			return
+		}
+	}
+
+	return
+}
+
+// same as walletapi.Initialize_LookupTable but with progress and without assigning the global table
+func Generate(count int, size int, onProgress func(value float64)) (walletapi.LookupTable, error) {
+	err := ValidateSize(size)
+	if err != nil {
+		return nil, err
+	}
+
+	table := make(walletapi.LookupTable, count)
+
+	var acc bn256.G1
+	acc.ScalarMult(crypto.G, new(big.Int).SetUint64(0))
+
+	smallTable := make([]*bn256.G1, 256)
+	for k := range smallTable {
+		smallTable[k] = new(bn256.G1)
+	}
+
+	var compressed [33]byte
+	total := count * size
+
+	for i := range table {
+		table[i] = make(walletapi.PreComputeTable, size)
+
+		for j := 0; j < size; j += 256 {
+			for k := range smallTable {
+				smallTable[k].Set(&acc)
+				acc.Add(smallTable[k], crypto.G)
+			}
+			bn256.G1Array(smallTable).MakeAffine()
+
+			for k := range smallTable {
+				smallTable[k].EncodeCompressedToBuf(compressed[:])
+
+				compressed[32] = byte(uint64(j+k) & 0xff)
+				compressed[31] = byte((uint64(j+k) >> 8) & 0xff)
+				compressed[30] = byte((uint64(j+k) >> 16) & 0xff)
+
+				table[i][j+k] = binary.BigEndian.Uint64(compressed[25:])
+			}
+
+			if onProgress != nil && j%(1<<16) == 0 {
+				onProgress(float64(i*size+j) / float64(total))
+			}
+		}
+
+		sort.Sort(table[i])
+	}
+
+	if onProgress != nil {
+		onProgress(1)
+	}
+
+	return table, nil
+}
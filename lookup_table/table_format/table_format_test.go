@@ -0,0 +1,91 @@
+package table_format
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/deroproject/derohe/walletapi"
+)
+
+func encodeTestTable(t *testing.T) (walletapi.LookupTable, []byte) {
+	t.Helper()
+
+	table, err := Generate(1, MIN_TABLE_SIZE, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buffer bytes.Buffer
+	err = Encode(&buffer, table)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return table, buffer.Bytes()
+}
+
+func TestRoundTrip(t *testing.T) {
+	table, data := encodeTestTable(t)
+
+	decoded, header, err := Decode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if header.Version != VERSION || header.Count != 1 || header.Size != MIN_TABLE_SIZE {
+		t.Fatalf("unexpected header %+v", header)
+	}
+
+	if len(decoded) != 1 || len(decoded[0]) != len(table[0]) {
+		t.Fatalf("unexpected table size %d", len(decoded))
+	}
+
+	for i := range table[0] {
+		if decoded[0][i] != table[0][i] {
+			t.Fatalf("unexpected value at %d", i)
+		}
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	_, data := encodeTestTable(t)
+
+	corrupt := func(change func(data []byte)) []byte {
+		corrupted := bytes.Clone(data)
+		change(corrupted)
+		return corrupted
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		err  string
+	}{
+		{"payload", corrupt(func(data []byte) { data[len(data)-1] ^= 0xff }), "checksum mismatch"},
+		{"checksum", corrupt(func(data []byte) { data[HEADER_SIZE-1] ^= 0xff }), "checksum mismatch"},
+		{"magic", corrupt(func(data []byte) { data[0] = 'X' }), "invalid header"},
+		{"version", corrupt(func(data []byte) { data[7] = VERSION + 1 }), "is not supported"},
+		{"count", corrupt(func(data []byte) { data[11] = 2 }), "count mismatch"},
+		{"truncated", data[:HEADER_SIZE-1], "too small"},
+	}
+
+	for _, test := range tests {
+		_, _, err := Decode(test.data)
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Fatalf("%s: expected [%s] got %v", test.name, test.err, err)
+		}
+	}
+}
+
+func TestValidateSize(t *testing.T) {
+	for _, size := range []int{MIN_TABLE_SIZE - 256, MAX_TABLE_SIZE + 256, DEFAULT_TABLE_SIZE + 1} {
+		if ValidateSize(size) == nil {
+			t.Fatalf("size %d is valid", size)
+		}
+	}
+
+	if ValidateSize(DEFAULT_TABLE_SIZE) != nil {
+		t.Fatal("default size is not valid")
+	}
+}
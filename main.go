@@ -2,6 +2,7 @@ package main
 
 import (
 	// first we are going to import some boilerplate
+	"flag"
 	"log"
 	"os"
 
@@ -32,6 +33,7 @@ import (
 	"github.com/secretsystems/secret-wallet/app_db"
 	"github.com/secretsystems/secret-wallet/app_instance"
 	"github.com/secretsystems/secret-wallet/assets"
+	"github.com/secretsystems/secret-wallet/caching"
 	"github.com/secretsystems/secret-wallet/containers"
 	"github.com/secretsystems/secret-wallet/lang"
 	"github.com/secretsystems/secret-wallet/lookup_table"
@@ -93,17 +95,17 @@ func loadPages(router *router.Router) {
 	router.SetCurrent(pages.PAGE_WALLET_SELECT)
 }
 
+// replaces the network selected in the settings
+var networkFlag string
+
 func runApp() error {
 	//  fundamentally, we are are using derohe's api
-	globals.Arguments["--testnet"] = false
 	globals.Arguments["--debug"] = false
 	globals.Arguments["--flog-level"] = nil
 	globals.Arguments["--log-dir"] = nil
 	globals.Arguments["--help"] = false
 	globals.Arguments["--version"] = false
-
-	// so let's turn on the DERO mainnet network
-	globals.InitNetwork() // this func assign mainnet/testnet config depending on globals.Arguments["--testnet"] value
+	// the network (mainnet/testnet/simulator) is initialized by settings.Load
 
 	var ops op.Ops
 
@@ -148,12 +150,38 @@ func runApp() error {
 			return
 		}
 
+		if networkFlag != "" {
+			err = settings.SetNetwork(networkFlag)
+			if err == nil {
+				err = settings.Save()
+			}
+
+			if err != nil {
+				loadState.SetStatus("", err)
+				return
+			}
+		}
+
+		// discard cache files written with an older format
+		err = caching.Load()
+		if err != nil {
+			loadState.SetStatus("", err)
+			return
+		}
+
 		// set status
 		loadState.SetStatus(lang.Translate("Loading lookup table"), nil)
 		//walletapi.Initialize_LookupTable(1, 1<<21)
 
 		// and load the precompiled lookup table into memory
-		err = lookup_table.Load()
+		// if the table needs to be generated it's done in the background and we show the progress while loading
+		err = lookup_table.Load(func(status string, err error) {
+			if !loadState.loaded {
+				loadState.SetStatus(status, err)
+			} else if err != nil {
+				log.Println(err)
+			}
+		})
 		if err != nil {
 			loadState.SetStatus("", err)
 			return
@@ -178,6 +206,9 @@ func runApp() error {
 		// load all of the divisions/containers of the applicaiton
 		containers.Load()
 
+		// health check the node list and switch away from a failing node
+		node_manager.StartSupervisor()
+
 		// load the router into the page loader
 		loadPages(router)
 
@@ -247,6 +278,9 @@ func runApp() error {
 }
 
 func main() {
+	flag.StringVar(&networkFlag, "network", "", "mainnet, testnet or simulator - saved as the selected network")
+	flag.Parse()
+
 	// start a go routine
 	go func() {
 
@@ -0,0 +1,420 @@
+package mock_daemon
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/creachadair/jrpc2"
+	"github.com/creachadair/jrpc2/channel"
+	"github.com/creachadair/jrpc2/handler"
+	"github.com/deroproject/derohe/block"
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/deroproject/derohe/errormsg"
+	"github.com/deroproject/derohe/globals"
+	"github.com/deroproject/derohe/glue/rwc"
+	"github.com/deroproject/derohe/rpc"
+	"github.com/deroproject/derohe/transaction"
+	"github.com/deroproject/derohe/walletapi"
+	"github.com/gorilla/websocket"
+)
+
+// Local stand-in for a DERO node used by tests.
+// It serves the same websocket json rpc api on /ws so walletapi.Connect and
+// wallet_manager.ConnectRPCClient can both point to it.
+// Balances are stored in clear and encrypted on the fly with the account public key.
+
+type account struct {
+	registration int64
+	bits         int
+	balances     map[crypto.Hash]uint64
+}
+
+type Tx struct {
+	Hex         string
+	BlockHeight int64
+	ValidBlock  string
+	InPool      bool
+}
+
+type Daemon struct {
+	Endpoint string // host:port without scheme
+
+	Height     int64
+	Testnet    bool
+	GasStorage uint64
+	GasCompute uint64
+	TreeHash   string
+
+	server          *httptest.Server
+	lock            sync.Mutex
+	accounts        map[string]*account
+	randomAddresses map[crypto.Hash][]string
+	txs             map[string]Tx
+	blocks          map[uint64]string
+	scs             map[string]rpc.GetSC_Result
+	calls           map[string]int
+	conns           map[*websocket.Conn]bool
+}
+
+var upgrader = websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }}
+
+func New() *Daemon {
+	d := &Daemon{
+		Height:          100,
+		Testnet:         !globals.IsMainnet(),
+		TreeHash:        hex.EncodeToString(make([]byte, 32)),
+		accounts:        make(map[string]*account),
+		randomAddresses: make(map[crypto.Hash][]string),
+		txs:             make(map[string]Tx),
+		blocks:          make(map[uint64]string),
+		scs:             make(map[string]rpc.GetSC_Result),
+		calls:           make(map[string]int),
+		conns:           make(map[*websocket.Conn]bool),
+	}
+
+	methods := handler.Map{
+		"DERO.Echo":                handler.New(d.echo),
+		"DERO.Ping":                handler.New(d.ping),
+		"DERO.GetInfo":             handler.New(d.getInfo),
+		"DERO.GetSC":               handler.New(d.getSC),
+		"DERO.GetRandomAddress":    handler.New(d.getRandomAddress),
+		"DERO.GetEncryptedBalance": handler.New(d.getEncryptedBalance),
+		"DERO.GetTransaction":      handler.New(d.getTransaction),
+		"DERO.GetBlock":            handler.New(d.getBlock),
+		"DERO.GetGasEstimate":      handler.New(d.getGasEstimate),
+		"DERO.SendRawTransaction":  handler.New(d.sendRawTransaction),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		d.lock.Lock()
+		d.conns[conn] = true
+		d.lock.Unlock()
+
+		defer func() {
+			d.lock.Lock()
+			delete(d.conns, conn)
+			d.lock.Unlock()
+			conn.Close()
+		}()
+
+		inputOutput := rwc.New(conn)
+		server := jrpc2.NewServer(methods, nil).Start(channel.RawJSON(inputOutput, inputOutput))
+		server.Wait()
+	})
+
+	d.server = httptest.NewServer(mux)
+	d.Endpoint = strings.TrimPrefix(d.server.URL, "http://")
+	return d
+}
+
+func (d *Daemon) Close() {
+	// websocket connections are hijacked and not closed by the http server
+	d.lock.Lock()
+	for conn := range d.conns {
+		conn.Close()
+	}
+	d.lock.Unlock()
+
+	d.server.Close()
+}
+
+// number of times a method was called ex: "DERO.GetRandomAddress"
+func (d *Daemon) Calls(method string) int {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.calls[method]
+}
+
+func (d *Daemon) called(method string) {
+	d.lock.Lock()
+	d.calls[method]++
+	d.lock.Unlock()
+}
+
+func (d *Daemon) getAccount(addr string) *account {
+	acc, ok := d.accounts[addr]
+	if !ok {
+		acc = &account{registration: 1, bits: 8, balances: make(map[crypto.Hash]uint64)}
+		d.accounts[addr] = acc
+	}
+
+	return acc
+}
+
+// register the address and set its balance for the asset
+func (d *Daemon) SetBalance(scId crypto.Hash, addr string, amount uint64) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.getAccount(addr).balances[scId] = amount
+}
+
+func (d *Daemon) SetRegistration(addr string, topoHeight int64) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.getAccount(addr).registration = topoHeight
+}
+
+// bits needed to find the public key in the tree - used to compute the ring max bits
+func (d *Daemon) SetBits(addr string, bits int) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.getAccount(addr).bits = bits
+}
+
+// addresses returned by DERO.GetRandomAddress - they are registered with a zero balance
+func (d *Daemon) SetRandomAddresses(scId crypto.Hash, addrs []string) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.randomAddresses[scId] = addrs
+	for _, addr := range addrs {
+		d.getAccount(addr)
+	}
+}
+
+func (d *Daemon) SetTx(txId string, tx Tx) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.txs[txId] = tx
+}
+
+func (d *Daemon) GetTx(txId string) (Tx, bool) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	tx, ok := d.txs[txId]
+	return tx, ok
+}
+
+// store a block at height including the txs
+func (d *Daemon) SetBlock(height uint64, txIds ...crypto.Hash) {
+	var bl block.Block
+	bl.Major_Version = 1
+	bl.Height = height
+	bl.Miner_TX.Version = 1
+	bl.Miner_TX.TransactionType = transaction.COINBASE
+	bl.Tx_hashes = txIds
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.blocks[height] = hex.EncodeToString(bl.Serialize())
+}
+
+func (d *Daemon) SetSC(scId string, result rpc.GetSC_Result) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.scs[scId] = result
+}
+
+func (d *Daemon) echo(ctx context.Context, args []string) string {
+	d.called("DERO.Echo")
+	return "DERO " + strings.Join(args, " ")
+}
+
+func (d *Daemon) ping(ctx context.Context) string {
+	d.called("DERO.Ping")
+	return "Pong "
+}
+
+func (d *Daemon) getInfo(ctx context.Context) (rpc.GetInfo_Result, error) {
+	d.called("DERO.GetInfo")
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	network := "Mainnet"
+	if d.Testnet {
+		network = "Testnet"
+	}
+
+	return rpc.GetInfo_Result{
+		Height:                  d.Height,
+		StableHeight:            d.Height - 8,
+		TopoHeight:              d.Height,
+		Merkle_Balance_TreeHash: d.TreeHash,
+		Testnet:                 d.Testnet,
+		Network:                 network,
+		Status:                  "OK",
+	}, nil
+}
+
+func (d *Daemon) getSC(ctx context.Context, params rpc.GetSC_Params) (rpc.GetSC_Result, error) {
+	d.called("DERO.GetSC")
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	result, ok := d.scs[params.SCID]
+	if !ok {
+		return result, fmt.Errorf("sc [%s] not found", params.SCID)
+	}
+
+	if !params.Code {
+		result.Code = ""
+	}
+
+	if !params.Variables {
+		result.VariableStringKeys = nil
+		result.VariableUint64Keys = nil
+	}
+
+	result.Status = "OK"
+	return result, nil
+}
+
+func (d *Daemon) getRandomAddress(ctx context.Context, params rpc.GetRandomAddress_Params) (rpc.GetRandomAddress_Result, error) {
+	d.called("DERO.GetRandomAddress")
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	return rpc.GetRandomAddress_Result{
+		Address: d.randomAddresses[params.SCID],
+		Status:  "OK",
+	}, nil
+}
+
+func (d *Daemon) getEncryptedBalance(ctx context.Context, params rpc.GetEncryptedBalance_Params) (result rpc.GetEncryptedBalance_Result, err error) {
+	d.called("DERO.GetEncryptedBalance")
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	addr, err := rpc.NewAddress(params.Address)
+	if err != nil {
+		return
+	}
+
+	acc, ok := d.accounts[addr.String()]
+	if !ok {
+		err = errormsg.ErrAccountUnregistered
+		return
+	}
+
+	// an untouched balance is stored as the registration zero balance like the blockchain does
+	balance := crypto.ConstructElGamal(addr.PublicKey.G1(), crypto.ElGamal_BASE_G)
+	amount := acc.balances[params.SCID]
+	if amount > 0 {
+		balance = crypto.CommitElGamal(addr.PublicKey.G1(), new(big.Int).SetUint64(amount))
+	}
+	nonceBalance := crypto.NonceBalance{NonceHeight: uint64(acc.registration), Balance: balance}
+
+	topoHeight := params.TopoHeight
+	if topoHeight == -1 {
+		topoHeight = d.Height
+	}
+
+	result = rpc.GetEncryptedBalance_Result{
+		SCID:                     params.SCID,
+		Data:                     hex.EncodeToString(nonceBalance.Serialize()),
+		Registration:             acc.registration,
+		Bits:                     acc.bits,
+		Height:                   topoHeight,
+		Topoheight:               topoHeight,
+		Merkle_Balance_TreeHash:  d.TreeHash,
+		DHeight:                  d.Height,
+		DTopoheight:              d.Height,
+		DMerkle_Balance_TreeHash: d.TreeHash,
+		Status:                   "OK",
+	}
+
+	return
+}
+
+func (d *Daemon) getTransaction(ctx context.Context, params rpc.GetTransaction_Params) (rpc.GetTransaction_Result, error) {
+	d.called("DERO.GetTransaction")
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	var result rpc.GetTransaction_Result
+	for _, txId := range params.Tx_Hashes {
+		tx, ok := d.txs[txId]
+		if !ok {
+			// same as the real daemon - a not found tx returns empty data
+			result.Txs_as_hex = append(result.Txs_as_hex, "")
+			result.Txs = append(result.Txs, rpc.Tx_Related_Info{})
+			continue
+		}
+
+		result.Txs_as_hex = append(result.Txs_as_hex, tx.Hex)
+		result.Txs = append(result.Txs, rpc.Tx_Related_Info{
+			Block_Height: tx.BlockHeight,
+			ValidBlock:   tx.ValidBlock,
+			In_pool:      tx.InPool,
+		})
+	}
+
+	result.Status = "OK"
+	return result, nil
+}
+
+func (d *Daemon) getBlock(ctx context.Context, params rpc.GetBlock_Params) (rpc.GetBlock_Result, error) {
+	d.called("DERO.GetBlock")
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	blob, ok := d.blocks[params.Height]
+	if !ok {
+		return rpc.GetBlock_Result{}, fmt.Errorf("block [%d] not found", params.Height)
+	}
+
+	return rpc.GetBlock_Result{Blob: blob, Status: "OK"}, nil
+}
+
+func (d *Daemon) getGasEstimate(ctx context.Context, params rpc.GasEstimate_Params) (rpc.GasEstimate_Result, error) {
+	d.called("DERO.GetGasEstimate")
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	return rpc.GasEstimate_Result{
+		GasCompute: d.GasCompute,
+		GasStorage: d.GasStorage,
+		Status:     "OK",
+	}, nil
+}
+
+// the tx goes to the pool - use SetTx to mine it
+func (d *Daemon) sendRawTransaction(ctx context.Context, params rpc.SendRawTransaction_Params) (result rpc.SendRawTransaction_Result, err error) {
+	d.called("DERO.SendRawTransaction")
+
+	data, err := hex.DecodeString(params.Tx_as_hex)
+	if err != nil {
+		return
+	}
+
+	var tx transaction.Transaction
+	err = tx.Deserialize(data)
+	if err != nil {
+		return
+	}
+
+	txId := tx.GetHash().String()
+	d.SetTx(txId, Tx{Hex: params.Tx_as_hex, BlockHeight: -1, InPool: true})
+
+	result.TXID = txId
+	result.Status = "OK"
+	return
+}
+
+// random registered address for the current network
+func RandomAddress() string {
+	acc, _ := walletapi.Generate_Keys_From_Random()
+	addr := rpc.NewAddressFromKeys(acc.Keys.Public)
+	addr.Mainnet = globals.IsMainnet()
+	return addr.String()
+}
+
+func RandomAddresses(count int) []string {
+	var addrs []string
+	for i := 0; i < count; i++ {
+		addrs = append(addrs, RandomAddress())
+	}
+
+	return addrs
+}
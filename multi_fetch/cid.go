@@ -0,0 +1,184 @@
+package multi_fetch
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const (
+	CODEC_RAW    = 0x55
+	CODEC_DAG_PB = 0x70
+
+	HASH_IDENTITY = 0x00
+	HASH_SHA2_256 = 0x12
+)
+
+type CID struct {
+	Version int
+	Codec   uint64
+	Hash    uint64
+	Digest  []byte
+}
+
+var base32Lower = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+func decodeBase58(value string) ([]byte, error) {
+	number := new(big.Int)
+	for _, c := range value {
+		idx := strings.IndexRune(base58Alphabet, c)
+		if idx == -1 {
+			return nil, fmt.Errorf("invalid base58 character [%c]", c)
+		}
+
+		number.Mul(number, big.NewInt(58))
+		number.Add(number, big.NewInt(int64(idx)))
+	}
+
+	// leading ones are leading zero bytes
+	zeros := 0
+	for zeros < len(value) && value[zeros] == '1' {
+		zeros++
+	}
+
+	return append(make([]byte, zeros), number.Bytes()...), nil
+}
+
+// v0 is a base58 sha2-256 multihash (Qm...) and v1 is multibase encoded - only base32, base58 and base16 are supported
+func ParseCID(value string) (cid CID, err error) {
+	var data []byte
+	if len(value) == 46 && strings.HasPrefix(value, "Qm") {
+		data, err = decodeBase58(value)
+		if err != nil {
+			return
+		}
+
+		cid.Version = 0
+		cid.Codec = CODEC_DAG_PB
+		_, err = cid.readMultihash(data)
+		return
+	}
+
+	if len(value) < 2 {
+		err = fmt.Errorf("invalid cid [%s]", value)
+		return
+	}
+
+	switch value[0] {
+	case 'b':
+		data, err = base32Lower.DecodeString(value[1:])
+	case 'B':
+		data, err = base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(value[1:])
+	case 'z':
+		data, err = decodeBase58(value[1:])
+	case 'f':
+		data, err = hex.DecodeString(value[1:])
+	default:
+		err = fmt.Errorf("unsupported multibase [%c]", value[0])
+	}
+
+	if err != nil {
+		return
+	}
+
+	cid, err = DecodeCID(data)
+	return
+}
+
+// binary cid from dag-pb links
+func DecodeCID(data []byte) (cid CID, err error) {
+	// v0 binary is the multihash itself
+	if len(data) == 34 && data[0] == HASH_SHA2_256 && data[1] == 32 {
+		cid.Version = 0
+		cid.Codec = CODEC_DAG_PB
+		_, err = cid.readMultihash(data)
+		return
+	}
+
+	reader := bytes.NewReader(data)
+	version, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return
+	}
+
+	if version != 1 {
+		err = fmt.Errorf("unsupported cid version %d", version)
+		return
+	}
+
+	cid.Version = 1
+	cid.Codec, err = binary.ReadUvarint(reader)
+	if err != nil {
+		return
+	}
+
+	_, err = cid.readMultihash(data[len(data)-reader.Len():])
+	return
+}
+
+func (cid *CID) readMultihash(data []byte) (int, error) {
+	reader := bytes.NewReader(data)
+	hash, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return 0, err
+	}
+
+	size, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return 0, err
+	}
+
+	if uint64(reader.Len()) != size {
+		return 0, fmt.Errorf("invalid multihash length")
+	}
+
+	switch hash {
+	case HASH_SHA2_256:
+		if size != sha256.Size {
+			return 0, fmt.Errorf("invalid sha2-256 digest length")
+		}
+	case HASH_IDENTITY:
+	default:
+		return 0, fmt.Errorf("unsupported multihash 0x%x", hash)
+	}
+
+	cid.Hash = hash
+	cid.Digest = data[len(data)-reader.Len():]
+	return len(data), nil
+}
+
+// gateways accept any cid version so blocks are always requested as v1 base32
+func (cid CID) String() string {
+	var data []byte
+	data = binary.AppendUvarint(data, 1)
+	data = binary.AppendUvarint(data, cid.Codec)
+	data = binary.AppendUvarint(data, cid.Hash)
+	data = binary.AppendUvarint(data, uint64(len(cid.Digest)))
+	data = append(data, cid.Digest...)
+	return "b" + base32Lower.EncodeToString(data)
+}
+
+func (cid CID) Verify(block []byte) error {
+	switch cid.Hash {
+	case HASH_SHA2_256:
+		hash := sha256.Sum256(block)
+		if !bytes.Equal(hash[:], cid.Digest) {
+			return fmt.Errorf("content does not match cid [%s]", cid)
+		}
+	case HASH_IDENTITY:
+		if !bytes.Equal(block, cid.Digest) {
+			return fmt.Errorf("content does not match cid [%s]", cid)
+		}
+	default:
+		return fmt.Errorf("unsupported multihash 0x%x", cid.Hash)
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package multi_fetch
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// unixfs data types
+const (
+	UNIXFS_RAW       = 0
+	UNIXFS_DIRECTORY = 1
+	UNIXFS_FILE      = 2
+	UNIXFS_HAMT      = 5
+)
+
+type PBLink struct {
+	Hash []byte
+	Name string
+}
+
+// dag-pb node with its unixfs data already decoded
+type PBNode struct {
+	Links    []PBLink
+	DataType uint64
+	Data     []byte
+}
+
+// calls fn for each field of a protobuf message - only varint and length-delimited wire types are used by dag-pb and unixfs
+func readProtobuf(data []byte, fn func(field uint64, value uint64, bytes []byte) error) error {
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return fmt.Errorf("invalid protobuf key")
+		}
+		data = data[n:]
+
+		field := key >> 3
+		switch key & 7 {
+		case 0:
+			value, n := binary.Uvarint(data)
+			if n <= 0 {
+				return fmt.Errorf("invalid protobuf varint")
+			}
+			data = data[n:]
+
+			err := fn(field, value, nil)
+			if err != nil {
+				return err
+			}
+		case 2:
+			size, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < size {
+				return fmt.Errorf("invalid protobuf length")
+			}
+
+			err := fn(field, 0, data[n:n+int(size)])
+			if err != nil {
+				return err
+			}
+			data = data[n+int(size):]
+		default:
+			return fmt.Errorf("unsupported protobuf wire type %d", key&7)
+		}
+	}
+
+	return nil
+}
+
+func DecodePBNode(block []byte) (node PBNode, err error) {
+	err = readProtobuf(block, func(field uint64, value uint64, bytes []byte) error {
+		switch field {
+		case 1: // Data
+			return readProtobuf(bytes, func(field uint64, value uint64, bytes []byte) error {
+				switch field {
+				case 1:
+					node.DataType = value
+				case 2:
+					node.Data = bytes
+				}
+				return nil
+			})
+		case 2: // Links
+			link := PBLink{}
+			err := readProtobuf(bytes, func(field uint64, value uint64, bytes []byte) error {
+				switch field {
+				case 1:
+					link.Hash = bytes
+				case 2:
+					link.Name = string(bytes)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			node.Links = append(node.Links, link)
+		}
+		return nil
+	})
+
+	return
+}
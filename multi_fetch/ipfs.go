@@ -0,0 +1,377 @@
+package multi_fetch
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/secretsystems/secret-wallet/settings"
+)
+
+// same as the bitswap limit - a gateway sending more is not following the spec
+const MAX_BLOCK_SIZE = 2 << 20
+
+// a deeper dag is most likely a loop built to waste requests
+const MAX_DAG_DEPTH = 32
+
+const DEFAULT_GATEWAY_TIMEOUT = 15 * time.Second
+
+// sibling blocks requested at the same time - also bounds the blocks held in memory per dag level
+const MAX_PARALLEL_BLOCKS = 8
+
+func gatewayTimeout(gateway settings.IPFSGateway) time.Duration {
+	if gateway.Timeout <= 0 {
+		return DEFAULT_GATEWAY_TIMEOUT
+	}
+
+	return time.Duration(gateway.Timeout) * time.Second
+}
+
+func gatewayUrl(gateway settings.IPFSGateway) string {
+	return strings.TrimRight(gateway.Url, "/")
+}
+
+// returns {name} and path segments of ipfs://{name}/{path}
+func splitUrl(value string, scheme string) (string, []string, error) {
+	value = strings.TrimPrefix(value, scheme)
+	value = strings.Split(value, "?")[0]
+
+	var segments []string
+	for _, segment := range strings.Split(value, "/") {
+		if segment == "" {
+			continue
+		}
+
+		segment, err := url.PathUnescape(segment)
+		if err != nil {
+			return "", nil, err
+		}
+
+		segments = append(segments, segment)
+	}
+
+	if len(segments) == 0 {
+		return "", nil, fmt.Errorf("missing cid")
+	}
+
+	return segments[0], segments[1:], nil
+}
+
+// https url of the first gateway - used to open ipfs content outside of the app
+func GatewayURL(value string) string {
+	gateways := settings.App.IPFSGateways
+	if len(gateways) == 0 {
+		return value
+	}
+
+	if strings.HasPrefix(value, "ipfs://") {
+		return fmt.Sprintf("%s/ipfs/%s", gatewayUrl(gateways[0]), strings.TrimPrefix(value, "ipfs://"))
+	}
+
+	if strings.HasPrefix(value, "ipns://") {
+		return fmt.Sprintf("%s/ipns/%s", gatewayUrl(gateways[0]), strings.TrimPrefix(value, "ipns://"))
+	}
+
+	return value
+}
+
+// Blocks are requested raw and checked against their cid so a gateway can't serve altered content.
+// The next gateway is tried if one fails, times out or sends invalid blocks.
+func IPFSFetch(value string) (*http.Response, error) {
+	name, path, err := splitUrl(value, "ipfs://")
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := ParseCID(name)
+	if err != nil {
+		return nil, err
+	}
+
+	gateways := settings.App.IPFSGateways
+	if len(gateways) == 0 {
+		return nil, fmt.Errorf("no ipfs gateways")
+	}
+
+	var errs []string
+	for _, gateway := range gateways {
+		data, err := fetchVerified(gateway, root, path)
+		if err == nil {
+			return &http.Response{
+				Status:        "200 OK",
+				StatusCode:    200,
+				Header:        make(http.Header),
+				Body:          io.NopCloser(bytes.NewReader(data)),
+				ContentLength: int64(len(data)),
+			}, nil
+		}
+
+		// other gateways would serve the same content
+		if err == ErrMaxSize {
+			return nil, err
+		}
+
+		errs = append(errs, fmt.Sprintf("%s: %s", gateway.Url, err))
+	}
+
+	return nil, fmt.Errorf("ipfs fetch failed - %s", strings.Join(errs, ", "))
+}
+
+// IPNS names are mutable and can't be checked against a cid so the content is trusted from the gateway.
+func IPNSFetch(value string) (*http.Response, error) {
+	_, _, err := splitUrl(value, "ipns://")
+	if err != nil {
+		return nil, err
+	}
+
+	gateways := settings.App.IPFSGateways
+	if len(gateways) == 0 {
+		return nil, fmt.Errorf("no ipfs gateways")
+	}
+
+	var errs []string
+	for _, gateway := range gateways {
+		ctx, cancel := context.WithTimeout(context.Background(), gatewayTimeout(gateway))
+		nameUrl := fmt.Sprintf("%s/ipns/%s", gatewayUrl(gateway), strings.TrimPrefix(value, "ipns://"))
+		res, err := httpGet(ctx, nameUrl, nil)
+		if err != nil {
+			cancel()
+			errs = append(errs, fmt.Sprintf("%s: %s", gateway.Url, err))
+			continue
+		}
+
+		if res.StatusCode != 200 {
+			res.Body.Close()
+			cancel()
+			errs = append(errs, fmt.Sprintf("%s: %s", gateway.Url, res.Status))
+			continue
+		}
+
+		res.Body = &cancelBody{ReadCloser: res.Body, cancel: cancel}
+		return res, nil
+	}
+
+	return nil, fmt.Errorf("ipns fetch failed - %s", strings.Join(errs, ", "))
+}
+
+type blockFetcher struct {
+	timeout time.Duration
+	gateway string
+	data    []byte
+	maxSize int64
+}
+
+func fetchVerified(gateway settings.IPFSGateway, root CID, path []string) ([]byte, error) {
+	fetcher := &blockFetcher{
+		timeout: gatewayTimeout(gateway),
+		gateway: gatewayUrl(gateway),
+		maxSize: settings.App.FetchMaxSize,
+	}
+
+	cid, err := fetcher.resolvePath(root, path)
+	if err != nil {
+		return nil, err
+	}
+
+	err = fetcher.readFile(cid, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return fetcher.data, nil
+}
+
+func (f *blockFetcher) getBlock(cid CID) ([]byte, error) {
+	if cid.Hash == HASH_IDENTITY {
+		return cid.Digest, nil
+	}
+
+	header := make(http.Header)
+	header.Set("Accept", "application/vnd.ipld.raw")
+
+	// the timeout is per block so big files are not cut short
+	ctx, cancel := context.WithTimeout(context.Background(), f.timeout)
+	defer cancel()
+
+	blockUrl := fmt.Sprintf("%s/ipfs/%s?format=raw", f.gateway, cid)
+	res, err := httpGet(ctx, blockUrl, header)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		return nil, fmt.Errorf("%s", res.Status)
+	}
+
+	block, err := io.ReadAll(io.LimitReader(res.Body, MAX_BLOCK_SIZE+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(block) > MAX_BLOCK_SIZE {
+		return nil, fmt.Errorf("block [%s] is too big", cid)
+	}
+
+	err = cid.Verify(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return block, nil
+}
+
+// sharded (HAMT) directories are not supported
+func (f *blockFetcher) resolvePath(cid CID, path []string) (CID, error) {
+	for _, segment := range path {
+		if cid.Codec != CODEC_DAG_PB {
+			return cid, fmt.Errorf("[%s] is not a directory", segment)
+		}
+
+		block, err := f.getBlock(cid)
+		if err != nil {
+			return cid, err
+		}
+
+		node, err := DecodePBNode(block)
+		if err != nil {
+			return cid, err
+		}
+
+		switch node.DataType {
+		case UNIXFS_DIRECTORY:
+		case UNIXFS_HAMT:
+			return cid, fmt.Errorf("sharded directories are not supported")
+		default:
+			return cid, fmt.Errorf("not a directory")
+		}
+
+		found := false
+		for _, link := range node.Links {
+			if link.Name == segment {
+				cid, err = DecodeCID(link.Hash)
+				if err != nil {
+					return cid, err
+				}
+
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return cid, fmt.Errorf("[%s] not found", segment)
+		}
+	}
+
+	return cid, nil
+}
+
+func (f *blockFetcher) write(data []byte) error {
+	if f.maxSize > 0 && int64(len(f.data)+len(data)) > f.maxSize {
+		return ErrMaxSize
+	}
+
+	f.data = append(f.data, data...)
+	return nil
+}
+
+// getBlocks requests the blocks in parallel and returns them in the same order
+func (f *blockFetcher) getBlocks(cids []CID) ([][]byte, error) {
+	blocks := make([][]byte, len(cids))
+	errs := make([]error, len(cids))
+
+	var wg sync.WaitGroup
+	for i := range cids {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			blocks[i], errs[i] = f.getBlock(cids[i])
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return blocks, nil
+}
+
+func (f *blockFetcher) readFile(cid CID, depth int) error {
+	block, err := f.getBlock(cid)
+	if err != nil {
+		return err
+	}
+
+	return f.readBlock(cid, block, depth)
+}
+
+// file chunks are the leaves of the dag read from left to right
+// the links of a node are fetched a few at a time and written in order
+func (f *blockFetcher) readBlock(cid CID, block []byte, depth int) error {
+	if depth > MAX_DAG_DEPTH {
+		return fmt.Errorf("dag is too deep")
+	}
+
+	switch cid.Codec {
+	case CODEC_RAW:
+		return f.write(block)
+	case CODEC_DAG_PB:
+	default:
+		return fmt.Errorf("unsupported codec 0x%x", cid.Codec)
+	}
+
+	node, err := DecodePBNode(block)
+	if err != nil {
+		return err
+	}
+
+	if node.DataType != UNIXFS_FILE && node.DataType != UNIXFS_RAW {
+		return fmt.Errorf("not a file")
+	}
+
+	err = f.write(node.Data)
+	if err != nil {
+		return err
+	}
+
+	var linkCids []CID
+	for _, link := range node.Links {
+		linkCid, err := DecodeCID(link.Hash)
+		if err != nil {
+			return err
+		}
+
+		linkCids = append(linkCids, linkCid)
+	}
+
+	for start := 0; start < len(linkCids); start += MAX_PARALLEL_BLOCKS {
+		end := start + MAX_PARALLEL_BLOCKS
+		if end > len(linkCids) {
+			end = len(linkCids)
+		}
+
+		blocks, err := f.getBlocks(linkCids[start:end])
+		if err != nil {
+			return err
+		}
+
+		for i, block := range blocks {
+			err = f.readBlock(linkCids[start+i], block, depth+1)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
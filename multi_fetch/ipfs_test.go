@@ -0,0 +1,266 @@
+package multi_fetch
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/secretsystems/secret-wallet/settings"
+)
+
+func protobufField(field uint64, value []byte) []byte {
+	data := binary.AppendUvarint(nil, field<<3|2)
+	data = binary.AppendUvarint(data, uint64(len(value)))
+	return append(data, value...)
+}
+
+func testDagPB(unixfsType uint64, links []PBLink) []byte {
+	var node []byte
+	for _, l := range links {
+		link := protobufField(1, l.Hash)
+		link = append(link, protobufField(2, []byte(l.Name))...)
+		node = append(node, protobufField(2, link)...)
+	}
+
+	unixfs := binary.AppendUvarint([]byte{1 << 3}, unixfsType)
+	return append(node, protobufField(1, unixfs)...)
+}
+
+func testCID(codec uint64, block []byte) []byte {
+	hash := sha256.Sum256(block)
+	cid := binary.AppendUvarint([]byte{1}, codec)
+	cid = append(cid, HASH_SHA2_256, 32)
+	return append(cid, hash[:]...)
+}
+
+func encodeTestBase58(data []byte) string {
+	number := new(big.Int).SetBytes(data)
+	result := ""
+	for number.Sign() > 0 {
+		mod := new(big.Int)
+		number.DivMod(number, big.NewInt(58), mod)
+		result = string(base58Alphabet[mod.Int64()]) + result
+	}
+	return result
+}
+
+func TestParseCID(t *testing.T) {
+	block := []byte("dero seal")
+	hash := sha256.Sum256(block)
+
+	v0 := encodeTestBase58(append([]byte{HASH_SHA2_256, 32}, hash[:]...))
+	cid, err := ParseCID(v0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cid.Version != 0 || cid.Codec != CODEC_DAG_PB || cid.Hash != HASH_SHA2_256 {
+		t.Fatalf("unexpected cid %+v", cid)
+	}
+
+	// v1 string of the same content parses back to the same cid
+	v1, err := ParseCID(cid.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v1.Version != 1 || v1.String() != cid.String() || v1.Verify(block) != nil {
+		t.Fatalf("unexpected cid %+v", v1)
+	}
+
+	raw, err := DecodeCID(testCID(CODEC_RAW, block))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, value := range []string{raw.String(), fmt.Sprintf("f%x", testCID(CODEC_RAW, block))} {
+		cid, err := ParseCID(value)
+		if err != nil || cid.Codec != CODEC_RAW || cid.Verify(block) != nil {
+			t.Fatalf("unexpected cid [%s] %+v %v", value, cid, err)
+		}
+	}
+
+	if raw.Verify([]byte("not the content")) == nil {
+		t.Fatal("altered content was verified")
+	}
+
+	invalid := []string{
+		"",
+		"Qm0OIl",
+		"xabc",
+		"f" + fmt.Sprintf("%x", testCID(CODEC_RAW, block)[:10]),
+		"f" + fmt.Sprintf("%x", append([]byte{2}, testCID(CODEC_RAW, block)[1:]...)),
+	}
+
+	for _, value := range invalid {
+		_, err := ParseCID(value)
+		if err == nil {
+			t.Fatalf("expected an error for [%s]", value)
+		}
+	}
+}
+
+func TestDecodePBNode(t *testing.T) {
+	fileCid := testCID(CODEC_RAW, []byte("dero"))
+	block := testDagPB(UNIXFS_DIRECTORY, []PBLink{{Hash: fileCid, Name: "seal.png"}})
+
+	node, err := DecodePBNode(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if node.DataType != UNIXFS_DIRECTORY || len(node.Links) != 1 ||
+		node.Links[0].Name != "seal.png" || string(node.Links[0].Hash) != string(fileCid) {
+		t.Fatalf("unexpected node %+v", node)
+	}
+
+	_, err = DecodePBNode(block[:len(block)-1])
+	if err == nil {
+		t.Fatal("expected a truncated node error")
+	}
+}
+
+type testGateway struct {
+	lock     sync.Mutex
+	blocks   map[string][]byte
+	requests int
+}
+
+func (g *testGateway) addBlock(t *testing.T, codec uint64, block []byte) []byte {
+	cid := testCID(codec, block)
+	decoded, err := DecodeCID(cid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g.blocks[decoded.String()] = block
+	return cid
+}
+
+func (g *testGateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.lock.Lock()
+	g.requests++
+	block, ok := g.blocks[strings.TrimPrefix(r.URL.Path, "/ipfs/")]
+	g.lock.Unlock()
+
+	if !ok || r.URL.Query().Get("format") != "raw" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Write(block)
+}
+
+func fetchTestContent(url string) ([]byte, error) {
+	res, err := Fetch(url)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	return io.ReadAll(res.Body)
+}
+
+func TestIPFSFetch(t *testing.T) {
+	blocks := &testGateway{blocks: make(map[string][]byte)}
+
+	// a two chunks file in a directory referenced by a v0 cid
+	fileCid := blocks.addBlock(t, CODEC_DAG_PB, testDagPB(UNIXFS_FILE, []PBLink{
+		{Hash: blocks.addBlock(t, CODEC_RAW, []byte("dero "))},
+		{Hash: blocks.addBlock(t, CODEC_RAW, []byte("seal"))},
+	}))
+	dir := testDagPB(UNIXFS_DIRECTORY, []PBLink{{Hash: fileCid, Name: "seal.png"}})
+	dirHash := sha256.Sum256(dir)
+	dirCid := append([]byte{HASH_SHA2_256, 32}, dirHash[:]...)
+	blocks.blocks[CID{Codec: CODEC_DAG_PB, Hash: HASH_SHA2_256, Digest: dirHash[:]}.String()] = dir
+
+	gateway := httptest.NewServer(blocks)
+	defer gateway.Close()
+
+	// serves altered content and must be skipped
+	badGateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("not the content"))
+	}))
+	defer badGateway.Close()
+
+	appSettings := settings.App
+	defer func() { settings.App = appSettings }()
+	settings.App.IPFSGateways = []settings.IPFSGateway{{Url: badGateway.URL, Timeout: 5}, {Url: gateway.URL + "/", Timeout: 5}}
+	settings.App.FetchMaxSize = 1 << 20
+
+	url := fmt.Sprintf("ipfs://%s/seal.png", encodeTestBase58(dirCid))
+	data, err := fetchTestContent(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != "dero seal" || blocks.requests != 4 {
+		t.Fatalf("unexpected content [%s] after %d requests", data, blocks.requests)
+	}
+
+	_, err = fetchTestContent(fmt.Sprintf("ipfs://%s/missing.png", encodeTestBase58(dirCid)))
+	if err == nil {
+		t.Fatal("expected a not found error")
+	}
+
+	settings.App.FetchMaxSize = 4
+	_, err = fetchTestContent(url)
+	if err != ErrMaxSize {
+		t.Fatalf("expected max size error got %v", err)
+	}
+
+	if GatewayURL(url) != fmt.Sprintf("%s/ipfs/%s/seal.png", badGateway.URL, encodeTestBase58(dirCid)) {
+		t.Fatalf("unexpected gateway url %s", GatewayURL(url))
+	}
+}
+
+// the chunks are fetched in parallel but must be written in the file order
+func TestIPFSFetchChunksOrder(t *testing.T) {
+	blocks := &testGateway{blocks: make(map[string][]byte)}
+
+	var expected string
+	var links []PBLink
+	for i := 0; i < 3*MAX_PARALLEL_BLOCKS+1; i++ {
+		chunk := fmt.Sprintf("chunk %d,", i)
+		expected += chunk
+
+		// a nested node in the middle of the file
+		if i == MAX_PARALLEL_BLOCKS {
+			nested := blocks.addBlock(t, CODEC_DAG_PB, testDagPB(UNIXFS_FILE, []PBLink{
+				{Hash: blocks.addBlock(t, CODEC_RAW, []byte(chunk))},
+			}))
+			links = append(links, PBLink{Hash: nested})
+			continue
+		}
+
+		links = append(links, PBLink{Hash: blocks.addBlock(t, CODEC_RAW, []byte(chunk))})
+	}
+
+	root, err := DecodeCID(blocks.addBlock(t, CODEC_DAG_PB, testDagPB(UNIXFS_FILE, links)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gateway := httptest.NewServer(blocks)
+	defer gateway.Close()
+
+	appSettings := settings.App
+	defer func() { settings.App = appSettings }()
+	settings.App.IPFSGateways = []settings.IPFSGateway{{Url: gateway.URL, Timeout: 5}}
+	settings.App.FetchMaxSize = 1 << 20
+
+	data, err := fetchTestContent("ipfs://" + root.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != expected || blocks.requests != len(blocks.blocks) {
+		t.Fatalf("unexpected content [%s] after %d requests", data, blocks.requests)
+	}
+}
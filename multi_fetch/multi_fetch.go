@@ -3,32 +3,98 @@ package multi_fetch
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/secretsystems/secret-wallet/settings"
 )
 
+var ErrMaxSize = fmt.Errorf("file is bigger than the max download size")
+
 func Fetch(url string) (*http.Response, error) {
 	if strings.HasPrefix(url, "http") {
 		return HttpFetch(url, 5*time.Second)
+	} else if strings.HasPrefix(url, "ipfs://") {
+		return IPFSFetch(url)
+	} else if strings.HasPrefix(url, "ipns://") {
+		return IPNSFetch(url)
 	} else {
 		return nil, fmt.Errorf("url scheme not supported")
 	}
 }
 
 func HttpFetch(url string, timeout time.Duration) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	res, err := httpGet(ctx, url, nil)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// the context must live until the body is read
+	res.Body = &cancelBody{ReadCloser: res.Body, cancel: cancel}
+	return res, nil
+}
+
+func httpGet(ctx context.Context, url string, header http.Header) (*http.Response, error) {
 	client := new(http.Client)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	ctx, _ := context.WithTimeout(context.Background(), timeout)
-	res, err := client.Do(req.WithContext(ctx))
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	res, err := client.Do(req)
 	if err != nil {
 		return nil, err
 	}
 
+	maxSize := settings.App.FetchMaxSize
+	if maxSize > 0 {
+		if res.ContentLength > maxSize {
+			res.Body.Close()
+			return nil, ErrMaxSize
+		}
+
+		res.Body = &limitBody{ReadCloser: res.Body, remaining: maxSize}
+	}
+
 	return res, nil
 }
+
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// errors instead of truncating so a partial file is never mistaken for the whole one
+type limitBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (b *limitBody) Read(p []byte) (int, error) {
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	if b.remaining < 0 {
+		return n, ErrMaxSize
+	}
+
+	return n, err
+}
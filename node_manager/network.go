@@ -0,0 +1,80 @@
+package node_manager
+
+import (
+	"github.com/deroproject/derohe/walletapi"
+	"github.com/secretsystems/secret-wallet/app_db"
+	"github.com/secretsystems/secret-wallet/caching"
+	"github.com/secretsystems/secret-wallet/settings"
+	"github.com/secretsystems/secret-wallet/wallet_manager"
+)
+
+// closes the wallet and the node of the current network and loads the app.db, cache and node of the other one
+// the returned error can be the node connection failing after the network was switched
+func SwitchNetwork(network string) error {
+	if network == settings.App.Network {
+		return nil
+	}
+
+	wallet_manager.CloseOpenedWallet()
+	Disconnect()
+
+	err := settings.SetNetwork(network)
+	if err != nil {
+		return err
+	}
+
+	err = settings.Save()
+	if err != nil {
+		return err
+	}
+
+	if app_db.DB != nil {
+		app_db.DB.Close()
+	}
+
+	err = app_db.Load()
+	if err != nil {
+		return err
+	}
+
+	err = caching.Load()
+	if err != nil {
+		return err
+	}
+
+	return Load()
+}
+
+// the restored settings can be of another network so everything is loaded again like a network switch
+// the returned error can be the node connection failing after the backup was restored
+func RestoreBackup(backup *app_db.Backup, mode app_db.BackupMode) error {
+	// the wallet saves its file when closing - it would overwrite the restored one
+	wallet_manager.CloseOpenedWalletAndWait()
+	Disconnect()
+
+	err := backup.Restore(mode)
+	if err != nil {
+		return err
+	}
+
+	err = caching.Load()
+	if err != nil {
+		return err
+	}
+
+	return Load()
+}
+
+func Disconnect() {
+	connectLock.Lock()
+	defer connectLock.Unlock()
+
+	CurrentNode = nil
+	userDisconnected = false
+	walletapi.Connected = false
+	wallet_manager.CloseRPCClient()
+
+	healthLock.Lock()
+	healths = make(map[string]*NodeHealth)
+	healthLock.Unlock()
+}
@@ -1,26 +1,34 @@
 package node_manager
 
 import (
+	"sync"
+
 	"github.com/deroproject/derohe/walletapi"
 	"github.com/secretsystems/secret-wallet/app_db"
+	"github.com/secretsystems/secret-wallet/wallet_manager"
 
 	"github.com/secretsystems/secret-wallet/settings"
 )
 
 var CurrentNode *app_db.NodeConnection
 
+// the supervisor and the node pages can connect at the same time
+var connectLock sync.Mutex
+
+// the user disconnected the current node - the supervisor leaves it alone until a node is connected again
+var userDisconnected bool
+
+// the saved node stays current if it's down so the supervisor can reconnect or replace it
 func Load() error {
 	endpoint := settings.App.NodeEndpoint
 	if endpoint != "" {
-		var nodeConn *app_db.NodeConnection
-
 		conn, err := app_db.GetNodeConnectionByEndpoint(endpoint)
 		if err != nil {
 			return err
 		}
 
-		nodeConn = &conn
-		if nodeConn == nil {
+		nodeConn := &conn
+		if conn.Endpoint == "" {
 			nodeConn = &app_db.NodeConnection{
 				Name:     "",
 				Endpoint: endpoint,
@@ -29,16 +37,18 @@ func Load() error {
 
 		err = Connect(*nodeConn, false)
 		if err != nil {
+			CurrentNode = nodeConn
 			return err
 		}
-
-		CurrentNode = nodeConn
 	}
 
 	return nil
 }
 
 func Connect(nodeConn app_db.NodeConnection, save bool) error {
+	connectLock.Lock()
+	defer connectLock.Unlock()
+
 	endpoint := nodeConn.Endpoint
 
 	err := walletapi.Connect(endpoint)
@@ -46,7 +56,13 @@ func Connect(nodeConn app_db.NodeConnection, save bool) error {
 		return err
 	}
 
+	err = wallet_manager.ConnectRPCClient(endpoint)
+	if err != nil {
+		return err
+	}
+
 	CurrentNode = &nodeConn
+	userDisconnected = false
 	settings.App.NodeEndpoint = nodeConn.Endpoint
 
 	if save {
@@ -58,3 +74,19 @@ func Connect(nodeConn app_db.NodeConnection, save bool) error {
 
 	return nil
 }
+
+// the node stays current so it's shown and can be reconnected
+func UserDisconnect() {
+	connectLock.Lock()
+	defer connectLock.Unlock()
+
+	userDisconnected = true
+	wallet_manager.CloseRPCClient()
+}
+
+func UserDisconnected() bool {
+	connectLock.Lock()
+	defer connectLock.Unlock()
+
+	return userDisconnected
+}
@@ -0,0 +1,294 @@
+package node_manager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/deroproject/derohe/globals"
+	"github.com/deroproject/derohe/rpc"
+	"github.com/secretsystems/secret-wallet/app_db"
+	"github.com/secretsystems/secret-wallet/settings"
+	"github.com/secretsystems/secret-wallet/wallet_manager"
+)
+
+const (
+	CHECK_INTERVAL = 30 * time.Second
+	CHECK_TIMEOUT  = 5 * time.Second
+	// a node further behind the highest healthy node is lagging
+	MAX_HEIGHT_LAG = 5
+	// failed checks in a row before leaving the current node
+	MAX_FAILURES = 2
+	// no new block for this long while another node moved forward
+	STALL_TIMEOUT = 3 * time.Minute
+)
+
+type NodeHealth struct {
+	Node         app_db.NodeConnection
+	Height       int64
+	StableHeight int64
+	Latency      time.Duration
+	Err          error
+	// failed checks in a row
+	Failures  int
+	CheckedAt time.Time
+	// last time the height moved forward
+	ProgressAt time.Time
+	// blocks behind the highest healthy node
+	Lag int64
+}
+
+func (h NodeHealth) Healthy() bool {
+	return h.Err == nil && !h.CheckedAt.IsZero()
+}
+
+func (h NodeHealth) Lagging() bool {
+	return h.Lag > MAX_HEIGHT_LAG
+}
+
+var (
+	healthLock sync.Mutex
+	healths    = make(map[string]*NodeHealth)
+
+	// called after the supervisor switched or reconnected the current node
+	OnSwitch func(node app_db.NodeConnection, reason string)
+)
+
+// ranked copy of the last checks
+func GetNodeHealth() []NodeHealth {
+	healthLock.Lock()
+	defer healthLock.Unlock()
+
+	var list []NodeHealth
+	for _, health := range healths {
+		list = append(list, *health)
+	}
+
+	return RankNodes(list)
+}
+
+// healthy nodes in sync sorted by latency, then lagging nodes by height and failing nodes last
+func RankNodes(list []NodeHealth) []NodeHealth {
+	rank := func(h NodeHealth) int {
+		switch {
+		case !h.Healthy():
+			return 2
+		case h.Lagging():
+			return 1
+		}
+
+		return 0
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		rankA, rankB := rank(a), rank(b)
+		if rankA != rankB {
+			return rankA < rankB
+		}
+
+		switch rankA {
+		case 0:
+			return a.Latency < b.Latency
+		case 1:
+			return a.Height > b.Height
+		}
+
+		return a.Node.OrderNumber < b.Node.OrderNumber
+	})
+
+	return list
+}
+
+// probes every node of the list and the current node if it was added by endpoint only
+func CheckNodes() error {
+	nodes, err := app_db.GetNodeConnections()
+	if err != nil {
+		return err
+	}
+
+	currentNode := CurrentNode
+	if currentNode != nil {
+		found := false
+		for _, node := range nodes {
+			if node.Endpoint == currentNode.Endpoint {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			nodes = append(nodes, *currentNode)
+		}
+	}
+
+	type probe struct {
+		node    app_db.NodeConnection
+		info    rpc.GetInfo_Result
+		latency time.Duration
+		err     error
+	}
+
+	probes := make([]probe, len(nodes))
+	var wg sync.WaitGroup
+	for i := range nodes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			info, latency, err := wallet_manager.ProbeNode(nodes[i].Endpoint, CHECK_TIMEOUT)
+			if err == nil && info.Testnet == globals.IsMainnet() {
+				err = fmt.Errorf("node is not on %s", settings.App.Network)
+			}
+			probes[i] = probe{node: nodes[i], info: info, latency: latency, err: err}
+		}(i)
+	}
+	wg.Wait()
+
+	now := time.Now()
+
+	healthLock.Lock()
+	defer healthLock.Unlock()
+
+	checked := make(map[string]*NodeHealth)
+	var bestHeight int64
+	for _, probe := range probes {
+		health, ok := healths[probe.node.Endpoint]
+		if !ok {
+			health = &NodeHealth{ProgressAt: now}
+		}
+
+		health.Node = probe.node
+		health.CheckedAt = now
+		health.Latency = probe.latency
+		health.Err = probe.err
+
+		if probe.err != nil {
+			health.Failures++
+		} else {
+			health.Failures = 0
+			if probe.info.Height > health.Height {
+				health.ProgressAt = now
+			}
+
+			health.Height = probe.info.Height
+			health.StableHeight = probe.info.StableHeight
+			if health.Height > bestHeight {
+				bestHeight = health.Height
+			}
+		}
+
+		checked[probe.node.Endpoint] = health
+	}
+
+	for _, health := range checked {
+		health.Lag = 0
+		if health.Err == nil {
+			health.Lag = bestHeight - health.Height
+		}
+	}
+
+	// removed nodes are forgotten
+	healths = checked
+	return nil
+}
+
+// empty if the current node is fine
+func failoverReason(current NodeHealth, best NodeHealth, now time.Time) string {
+	switch {
+	case current.Failures >= MAX_FAILURES:
+		return fmt.Sprintf("not responding: %s", current.Err)
+	case current.Healthy() && current.Lagging():
+		return fmt.Sprintf("%d blocks behind", current.Lag)
+	case current.Healthy() && now.Sub(current.ProgressAt) > STALL_TIMEOUT && best.ProgressAt.After(current.ProgressAt):
+		return "stalled"
+	}
+
+	return ""
+}
+
+// the live connection can drop while the node itself still answers
+func clientConnected() bool {
+	client := wallet_manager.RPC_Client.RPC
+	if client == nil {
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), CHECK_TIMEOUT)
+	defer cancel()
+
+	var info rpc.GetInfo_Result
+	return client.CallResult(ctx, "DERO.GetInfo", nil, &info) == nil
+}
+
+// one supervisor pass - a pinned node is only reconnected and a node disconnected by the user is left alone
+func Supervise() error {
+	err := CheckNodes()
+	if err != nil {
+		return err
+	}
+
+	currentNode := CurrentNode
+	if currentNode == nil || UserDisconnected() {
+		return nil
+	}
+
+	ranked := GetNodeHealth()
+	var current, best *NodeHealth
+	for i := range ranked {
+		if ranked[i].Node.Endpoint == currentNode.Endpoint {
+			current = &ranked[i]
+		} else if best == nil && ranked[i].Healthy() && !ranked[i].Lagging() {
+			best = &ranked[i]
+		}
+	}
+
+	if current == nil {
+		return nil
+	}
+
+	if current.Healthy() && !clientConnected() {
+		err = Connect(*currentNode, false)
+		if err == nil && OnSwitch != nil {
+			OnSwitch(*currentNode, "connection lost")
+		}
+
+		return err
+	}
+
+	if settings.App.NodePinned || best == nil {
+		return nil
+	}
+
+	reason := failoverReason(*current, *best, time.Now())
+	if reason == "" {
+		return nil
+	}
+
+	err = Connect(best.Node, true)
+	if err != nil {
+		return err
+	}
+
+	if OnSwitch != nil {
+		OnSwitch(best.Node, reason)
+	}
+
+	return nil
+}
+
+func StartSupervisor() {
+	ticker := time.NewTicker(CHECK_INTERVAL)
+
+	go func() {
+		for {
+			err := Supervise()
+			if err != nil {
+				fmt.Println(err)
+			}
+
+			<-ticker.C
+		}
+	}()
+}
@@ -0,0 +1,112 @@
+package node_manager
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/secretsystems/secret-wallet/app_db"
+)
+
+func testNodeHealth(endpoint string, order int, height int64, lag int64, latency time.Duration, err error) NodeHealth {
+	return NodeHealth{
+		Node:      app_db.NodeConnection{Endpoint: endpoint, OrderNumber: order},
+		Height:    height,
+		Lag:       lag,
+		Latency:   latency,
+		Err:       err,
+		CheckedAt: time.Unix(1, 0),
+	}
+}
+
+func TestRankNodes(t *testing.T) {
+	down := errors.New("down")
+	unchecked := testNodeHealth("unchecked", 0, 0, 0, 0, nil)
+	unchecked.CheckedAt = time.Time{}
+
+	tests := []struct {
+		name     string
+		list     []NodeHealth
+		expected string
+	}{
+		{
+			"healthy by latency",
+			[]NodeHealth{
+				testNodeHealth("slow", 0, 100, 0, 300*time.Millisecond, nil),
+				testNodeHealth("fast", 1, 100, 0, 50*time.Millisecond, nil),
+			},
+			"fast,slow",
+		},
+		{
+			"lagging after healthy by height",
+			[]NodeHealth{
+				testNodeHealth("far", 0, 80, 20, time.Millisecond, nil),
+				testNodeHealth("near", 1, 90, 10, time.Millisecond, nil),
+				testNodeHealth("synced", 2, 100, 0, time.Second, nil),
+			},
+			"synced,near,far",
+		},
+		{
+			"failing last by order",
+			[]NodeHealth{
+				testNodeHealth("down2", 2, 0, 0, 0, down),
+				unchecked,
+				testNodeHealth("down1", 1, 0, 0, 0, down),
+				testNodeHealth("lagging", 3, 90, 10, 0, nil),
+			},
+			"lagging,unchecked,down1,down2",
+		},
+	}
+
+	for _, test := range tests {
+		var endpoints []string
+		for _, health := range RankNodes(test.list) {
+			endpoints = append(endpoints, health.Node.Endpoint)
+		}
+
+		if strings.Join(endpoints, ",") != test.expected {
+			t.Fatalf("%s: expected %s got %v", test.name, test.expected, endpoints)
+		}
+	}
+}
+
+func TestFailoverReason(t *testing.T) {
+	now := time.Unix(10000, 0)
+	stalledAt := now.Add(-STALL_TIMEOUT - time.Second)
+	recentAt := now.Add(-time.Second)
+
+	healthy := func(lag int64, progressAt time.Time) NodeHealth {
+		health := testNodeHealth("node", 0, 100, lag, 0, nil)
+		health.ProgressAt = progressAt
+		return health
+	}
+
+	failing := testNodeHealth("node", 0, 0, 0, 0, errors.New("timeout"))
+	failing.Failures = MAX_FAILURES
+
+	failedOnce := failing
+	failedOnce.Failures = MAX_FAILURES - 1
+
+	tests := []struct {
+		name     string
+		current  NodeHealth
+		best     NodeHealth
+		expected string
+	}{
+		{"fine", healthy(0, recentAt), healthy(0, recentAt), ""},
+		{"not responding", failing, healthy(0, recentAt), "not responding: timeout"},
+		{"one failure", failedOnce, healthy(0, recentAt), ""},
+		{"lagging", healthy(MAX_HEIGHT_LAG+1, recentAt), healthy(0, recentAt), "6 blocks behind"},
+		{"small lag", healthy(MAX_HEIGHT_LAG, recentAt), healthy(0, recentAt), ""},
+		{"stalled", healthy(0, stalledAt), healthy(0, recentAt), "stalled"},
+		{"every node stalled", healthy(0, stalledAt), healthy(0, stalledAt), ""},
+	}
+
+	for _, test := range tests {
+		reason := failoverReason(test.current, test.best, now)
+		if reason != test.expected {
+			t.Fatalf("%s: expected [%s] got [%s]", test.name, test.expected, reason)
+		}
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/secretsystems/secret-wallet/lang"
 	"github.com/secretsystems/secret-wallet/node_manager"
 	"github.com/secretsystems/secret-wallet/router"
+	"github.com/secretsystems/secret-wallet/settings"
 	"github.com/secretsystems/secret-wallet/theme"
 	"github.com/secretsystems/secret-wallet/utils"
 	"github.com/secretsystems/secret-wallet/wallet_manager"
@@ -41,6 +42,7 @@ type PageRemoteNode struct {
 	buttonDisconnect *components.Button
 	nodeInfo         *RemoteNodeInfo
 	connecting       bool
+	pinned           *widget.Bool
 
 	list *widget.List
 }
@@ -91,6 +93,7 @@ func NewPageRemoteNode() *PageRemoteNode {
 		nodeInfo:         nodeInfo,
 		buttonReconnect:  buttonReconnect,
 		buttonDisconnect: buttonDisconnect,
+		pinned:           new(widget.Bool),
 		list:             list,
 	}
 }
@@ -102,6 +105,7 @@ func (p *PageRemoteNode) IsActive() bool {
 func (p *PageRemoteNode) Enter() {
 	p.isActive = true
 	page_instance.header.Title = func() string { return lang.Translate("Remote Node") }
+	p.pinned.Value = settings.App.NodePinned
 
 	if !page_instance.header.IsHistory(PAGE_REMOTE_NODE) {
 		p.animationLeave.Reset()
@@ -146,10 +150,16 @@ func (p *PageRemoteNode) Layout(gtx layout.Context, th *material.Theme) layout.D
 	}
 
 	if p.buttonDisconnect.Clicked() {
-		go func() {
-			wallet_manager.RPC_Client.RPC.Close()
-			wallet_manager.RPC_Client.WS.Close()
-		}()
+		go node_manager.UserDisconnect()
+	}
+
+	if p.pinned.Changed() {
+		settings.App.NodePinned = p.pinned.Value
+		err := settings.Save()
+		if err != nil {
+			notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+			notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		}
 	}
 
 	var widgets []layout.Widget
@@ -265,6 +275,48 @@ func (p *PageRemoteNode) Layout(gtx layout.Context, th *material.Theme) layout.D
 		})
 	}
 
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						s := material.Switch(th, p.pinned, "")
+						s.Color = theme.Current.SwitchColors
+						return s.Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						lbl := material.Label(th, unit.Sp(18), lang.Translate("Pin this node"))
+						return lbl.Layout(gtx)
+					}),
+				)
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(5)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				lbl := material.Label(th, unit.Sp(14), lang.Translate("Otherwise the wallet switches to the best node of your list when this one stops responding or falls behind."))
+				lbl.Color = theme.Current.TextMuteColor
+				return lbl.Layout(gtx)
+			}),
+		)
+	})
+
+	ranked := node_manager.GetNodeHealth()
+	if len(ranked) > 0 {
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(18), lang.Translate("Node Ranking"))
+			lbl.Font.Weight = font.Bold
+			return lbl.Layout(gtx)
+		})
+
+		for i := range ranked {
+			health := ranked[i]
+			current := health.Node.Endpoint == currentNode.Endpoint
+			widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+				return layoutNodeHealth(gtx, th, health, current)
+			})
+		}
+	}
+
 	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
 		return layout.Spacer{Height: unit.Dp(30)}.Layout(gtx)
 	})
@@ -280,6 +332,66 @@ func (p *PageRemoteNode) Layout(gtx layout.Context, th *material.Theme) layout.D
 	})
 }
 
+func nodeHealthStatus(health node_manager.NodeHealth) string {
+	switch {
+	case !health.Healthy():
+		return lang.Translate("Offline")
+	case health.Lagging():
+		return fmt.Sprintf(lang.Translate("%d blocks behind"), health.Lag)
+	}
+
+	return fmt.Sprintf("%d ms", health.Latency.Milliseconds())
+}
+
+func layoutNodeHealth(gtx layout.Context, th *material.Theme, health node_manager.NodeHealth, current bool) layout.Dimensions {
+	name := health.Node.Name
+	if name == "" {
+		name = health.Node.Endpoint
+	}
+
+	r := op.Record(gtx.Ops)
+	dims := layout.UniformInset(unit.Dp(10)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						lbl := material.Label(th, unit.Sp(16), name)
+						if current {
+							lbl.Font.Weight = font.Bold
+						}
+						return lbl.Layout(gtx)
+					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						height := "--"
+						if health.Healthy() {
+							height = fmt.Sprint(health.Height)
+						}
+
+						lbl := material.Label(th, unit.Sp(14), fmt.Sprintf("%s %s", lang.Translate("Height"), height))
+						lbl.Color = theme.Current.TextMuteColor
+						return lbl.Layout(gtx)
+					}),
+				)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				lbl := material.Label(th, unit.Sp(14), nodeHealthStatus(health))
+				lbl.Color = theme.Current.TextMuteColor
+				return lbl.Layout(gtx)
+			}),
+		)
+	})
+	c := r.Stop()
+
+	paint.FillShape(gtx.Ops, theme.Current.ListBgColor,
+		clip.UniformRRect(
+			image.Rectangle{Max: dims.Size},
+			gtx.Dp(10),
+		).Op(gtx.Ops))
+
+	c.Add(gtx.Ops)
+	return dims
+}
+
 func (p *PageRemoteNode) reconnect() {
 	if p.connecting {
 		return
@@ -50,24 +50,7 @@ func NewPageAppInfo() *PageAppInfo {
 	list := new(widget.List)
 	list.Axis = layout.Vertical
 
-	unix, _ := strconv.ParseUint(settings.BuildTime, 10, 64)
-	buildTimeUnix := time.Unix(int64(unix), 0)
-	buildTime := fmt.Sprintf("%s (%d)", buildTimeUnix.Local().String(), unix)
-
-	// do not remove @lang.Translate comment
-	// it's used by the python script to generate language json dictionary
-	// we don't use lang.Translate directly here because it needs to be inside the Layout func or the value won't be updated after language change
-	infoItems := []*InfoListItem{
-		NewInfoListItem("App Directory", settings.AppDir, text.WrapGraphemes),         //@lang.Translate("App Directory")
-		NewInfoListItem("Wallets Directory", settings.WalletsDir, text.WrapGraphemes), //@lang.Translate("Wallets Directory")
-		NewInfoListItem("Cache Directory", settings.CacheDir, text.WrapGraphemes),     //@lang.Translate("Cache Directory")
-		NewInfoListItem("Version", settings.Version, text.WrapGraphemes),              //@lang.Translate("Version")
-		NewInfoListItem("Git Version", settings.GitVersion, text.WrapGraphemes),       //@lang.Translate("Git Version")
-		NewInfoListItem("Build Time", buildTime, text.WrapGraphemes),                  //@lang.Translate("Build Time")
-	}
-
 	return &PageAppInfo{
-		infoItems:      infoItems,
 		list:           list,
 		animationEnter: animationEnter,
 		animationLeave: animationLeave,
@@ -80,6 +63,7 @@ func (p *PageAppInfo) IsActive() bool {
 
 func (p *PageAppInfo) Enter() {
 	p.isActive = true
+	p.load()
 	page_instance.header.Title = func() string { return lang.Translate("App Information") }
 
 	if !page_instance.header.IsHistory(PAGE_APP_INFO) {
@@ -88,6 +72,26 @@ func (p *PageAppInfo) Enter() {
 	}
 }
 
+// the directories change with the network
+func (p *PageAppInfo) load() {
+	unix, _ := strconv.ParseUint(settings.BuildTime, 10, 64)
+	buildTimeUnix := time.Unix(int64(unix), 0)
+	buildTime := fmt.Sprintf("%s (%d)", buildTimeUnix.Local().String(), unix)
+
+	// do not remove @lang.Translate comment
+	// it's used by the python script to generate language json dictionary
+	// we don't use lang.Translate directly here because it needs to be inside the Layout func or the value won't be updated after language change
+	p.infoItems = []*InfoListItem{
+		NewInfoListItem("App Directory", settings.AppDir, text.WrapGraphemes),         //@lang.Translate("App Directory")
+		NewInfoListItem("Network", settings.App.Network, text.WrapGraphemes),          //@lang.Translate("Network")
+		NewInfoListItem("Wallets Directory", settings.WalletsDir, text.WrapGraphemes), //@lang.Translate("Wallets Directory")
+		NewInfoListItem("Cache Directory", settings.CacheDir, text.WrapGraphemes),     //@lang.Translate("Cache Directory")
+		NewInfoListItem("Version", settings.Version, text.WrapGraphemes),              //@lang.Translate("Version")
+		NewInfoListItem("Git Version", settings.GitVersion, text.WrapGraphemes),       //@lang.Translate("Git Version")
+		NewInfoListItem("Build Time", buildTime, text.WrapGraphemes),                  //@lang.Translate("Build Time")
+	}
+}
+
 func (p *PageAppInfo) Leave() {
 	p.animationEnter.Reset()
 	p.animationLeave.Start()
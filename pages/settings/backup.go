@@ -0,0 +1,438 @@
+package page_settings
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"time"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/secretsystems/secret-wallet/animation"
+	"github.com/secretsystems/secret-wallet/app_db"
+	"github.com/secretsystems/secret-wallet/app_instance"
+	"github.com/secretsystems/secret-wallet/components"
+	"github.com/secretsystems/secret-wallet/containers/confirm_modal"
+	"github.com/secretsystems/secret-wallet/containers/notification_modals"
+	"github.com/secretsystems/secret-wallet/lang"
+	"github.com/secretsystems/secret-wallet/node_manager"
+	"github.com/secretsystems/secret-wallet/pages"
+	"github.com/secretsystems/secret-wallet/prefabs"
+	"github.com/secretsystems/secret-wallet/router"
+	"github.com/secretsystems/secret-wallet/settings"
+	"github.com/secretsystems/secret-wallet/theme"
+	"github.com/secretsystems/secret-wallet/utils"
+	"github.com/tanema/gween"
+	"github.com/tanema/gween/ease"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+type PageBackup struct {
+	isActive bool
+
+	animationEnter *animation.Animation
+	animationLeave *animation.Animation
+
+	txtBackupPassword  *prefabs.TextField
+	txtBackupConfirm   *prefabs.TextField
+	buttonCreateBackup *components.Button
+
+	txtRestorePassword *prefabs.TextField
+	buttonOpenBackup   *components.Button
+	replace            *widget.Bool
+	buttonRestore      *components.Button
+
+	backupLock sync.Mutex
+	backup     *app_db.Backup
+	changes    []app_db.BackupChange
+
+	list *widget.List
+}
+
+var _ router.Page = &PageBackup{}
+
+func NewPageBackup() *PageBackup {
+	animationEnter := animation.NewAnimation(false, gween.NewSequence(
+		gween.New(1, 0, .25, ease.Linear),
+	))
+
+	animationLeave := animation.NewAnimation(false, gween.NewSequence(
+		gween.New(0, 1, .25, ease.Linear),
+	))
+
+	saveIcon, _ := widget.NewIcon(icons.ContentSave)
+	openIcon, _ := widget.NewIcon(icons.FileFolderOpen)
+	restoreIcon, _ := widget.NewIcon(icons.ActionRestore)
+
+	list := new(widget.List)
+	list.Axis = layout.Vertical
+
+	return &PageBackup{
+		animationEnter: animationEnter,
+		animationLeave: animationLeave,
+
+		txtBackupPassword:  prefabs.NewPasswordTextField(),
+		txtBackupConfirm:   prefabs.NewPasswordTextField(),
+		buttonCreateBackup: newRPCPolicyButton(saveIcon),
+
+		txtRestorePassword: prefabs.NewPasswordTextField(),
+		buttonOpenBackup:   newRPCPolicyButton(openIcon),
+		replace:            new(widget.Bool),
+		buttonRestore:      newRPCPolicyButton(restoreIcon),
+
+		list: list,
+	}
+}
+
+func (p *PageBackup) IsActive() bool {
+	return p.isActive
+}
+
+func (p *PageBackup) Enter() {
+	p.isActive = true
+	page_instance.header.Title = func() string { return lang.Translate("Backup & Restore") }
+
+	if !page_instance.header.IsHistory(PAGE_BACKUP) {
+		p.animationEnter.Start()
+		p.animationLeave.Reset()
+	}
+}
+
+func (p *PageBackup) Leave() {
+	p.animationEnter.Reset()
+	p.animationLeave.Start()
+}
+
+func (p *PageBackup) mode() app_db.BackupMode {
+	if p.replace.Value {
+		return app_db.BACKUP_MODE_REPLACE
+	}
+
+	return app_db.BACKUP_MODE_MERGE
+}
+
+func (p *PageBackup) createBackup() {
+	p.buttonCreateBackup.SetLoading(true)
+
+	go func() {
+		err := func() error {
+			password := p.txtBackupPassword.Value()
+			if password == "" {
+				return fmt.Errorf("enter a password")
+			}
+
+			if password != p.txtBackupConfirm.Value() {
+				return fmt.Errorf("the passwords don't match")
+			}
+
+			name := fmt.Sprintf("secret-wallet-%s%s", time.Now().Format("2006-01-02"), app_db.BACKUP_EXT)
+			file, err := app_instance.Explorer.CreateFile(name)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+
+			_, err = app_db.CreateBackup(file, password)
+			return err
+		}()
+
+		p.buttonCreateBackup.SetLoading(false)
+		if err != nil {
+			notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+			notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		} else {
+			p.txtBackupPassword.SetValue("")
+			p.txtBackupConfirm.SetValue("")
+			notification_modals.SuccessInstance.SetText(lang.Translate("Success"), lang.Translate("Backup saved. Keep its password, it can't be restored without it."))
+			notification_modals.SuccessInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		}
+
+		app_instance.Window.Invalidate()
+	}()
+}
+
+func (p *PageBackup) openBackup() {
+	p.buttonOpenBackup.SetLoading(true)
+
+	go func() {
+		err := func() error {
+			password := p.txtRestorePassword.Value()
+			if password == "" {
+				return fmt.Errorf("enter the password of the backup")
+			}
+
+			file, err := app_instance.Explorer.ChooseFile()
+			if err != nil {
+				return err
+			}
+
+			reader := utils.ReadCloser{ReadCloser: file}
+			data, err := reader.ReadAll()
+			if err != nil {
+				return err
+			}
+
+			backup, err := app_db.OpenBackup(bytes.NewReader(data), password)
+			if err != nil {
+				return err
+			}
+
+			p.backupLock.Lock()
+			p.backup = backup
+			p.changes = nil
+			p.backupLock.Unlock()
+
+			return p.preview()
+		}()
+
+		p.buttonOpenBackup.SetLoading(false)
+		if err != nil {
+			notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+			notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		}
+
+		app_instance.Window.Invalidate()
+	}()
+}
+
+// changes of the selected mode - nothing is written
+func (p *PageBackup) preview() error {
+	p.backupLock.Lock()
+	backup := p.backup
+	p.backupLock.Unlock()
+
+	if backup == nil {
+		return nil
+	}
+
+	changes, err := backup.Preview(p.mode())
+	if err != nil {
+		return err
+	}
+
+	p.backupLock.Lock()
+	p.changes = changes
+	p.backupLock.Unlock()
+
+	app_instance.Window.Invalidate()
+	return nil
+}
+
+func (p *PageBackup) restore() {
+	p.backupLock.Lock()
+	backup := p.backup
+	p.backupLock.Unlock()
+
+	if backup == nil {
+		return
+	}
+
+	prompt := lang.Translate("Merge the backup into this device? The opened wallet will be closed.")
+	if p.replace.Value {
+		prompt = lang.Translate("Replace the data of this device with the backup? The opened wallet will be closed and the changes listed can't be undone.")
+	}
+
+	yesChan := confirm_modal.Instance.Open(confirm_modal.ConfirmText{Prompt: prompt})
+
+	for yes := range yesChan {
+		if !yes {
+			continue
+		}
+
+		app_instance.Router.SetCurrent(pages.PAGE_WALLET_SELECT)
+		err := node_manager.RestoreBackup(backup, p.mode())
+		if err != nil {
+			notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+			notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		} else {
+			p.backupLock.Lock()
+			p.backup = nil
+			p.changes = nil
+			p.backupLock.Unlock()
+
+			p.txtRestorePassword.SetValue("")
+			// the restored settings can differ
+			page_instance.pageMain.networkSelector.Key = settings.App.Network
+			page_instance.pageXSWD.enabled.Value = settings.App.XSWD
+			notification_modals.SuccessInstance.SetText(lang.Translate("Success"), lang.Translate("Backup restored."))
+			notification_modals.SuccessInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		}
+
+		app_instance.Window.Invalidate()
+	}
+}
+
+func backupFileTitle(file app_db.BackupFile) string {
+	var title string
+	switch file.File {
+	case "settings.json":
+		return lang.Translate("Settings")
+	case "app.db":
+		title = lang.Translate("Nodes, wallet list and dApps")
+	case "wallet.db":
+		title = fmt.Sprintf(lang.Translate("Wallet %s"), utils.ReduceAddr(file.Wallet))
+	case "data.db":
+		title = fmt.Sprintf(lang.Translate("Contacts, tokens and history of %s"), utils.ReduceAddr(file.Wallet))
+	}
+
+	return fmt.Sprintf("%s · %s", title, prefabs.NetworkName(file.Network))
+}
+
+func backupActionText(change app_db.BackupChange) string {
+	switch change.Action {
+	case app_db.BACKUP_ACTION_ADD:
+		return lang.Translate("Added")
+	case app_db.BACKUP_ACTION_REPLACE:
+		return lang.Translate("Replaced")
+	case app_db.BACKUP_ACTION_MERGE:
+		return fmt.Sprintf(lang.Translate("Merged · %d new rows"), change.Rows)
+	case app_db.BACKUP_ACTION_REMOVE:
+		return lang.Translate("Removed")
+	}
+
+	return lang.Translate("Unchanged")
+}
+
+func layoutBackupChange(gtx layout.Context, th *material.Theme, change app_db.BackupChange) layout.Dimensions {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(14), backupFileTitle(change.BackupFile))
+			lbl.Font.Weight = font.Bold
+			return lbl.Layout(gtx)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(14), backupActionText(change))
+			lbl.Color = theme.Current.TextMuteColor
+			if change.Action == app_db.BACKUP_ACTION_REMOVE {
+				lbl.Color = theme.Current.TextColor
+			}
+
+			return lbl.Layout(gtx)
+		}),
+	)
+}
+
+func (p *PageBackup) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	{
+		state := p.animationEnter.Update(gtx)
+		if state.Active {
+			defer animation.TransformX(gtx, state.Value).Push(gtx.Ops).Pop()
+		}
+	}
+
+	{
+		state := p.animationLeave.Update(gtx)
+		if state.Finished {
+			p.isActive = false
+			op.InvalidateOp{}.Add(gtx.Ops)
+		}
+
+		if state.Active {
+			defer animation.TransformX(gtx, state.Value).Push(gtx.Ops).Pop()
+		}
+	}
+
+	if p.buttonCreateBackup.Clicked() {
+		p.createBackup()
+	}
+
+	if p.buttonOpenBackup.Clicked() {
+		p.openBackup()
+	}
+
+	if p.replace.Changed() {
+		go func() {
+			err := p.preview()
+			if err != nil {
+				notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+				notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+			}
+		}()
+	}
+
+	if p.buttonRestore.Clicked() {
+		go p.restore()
+	}
+
+	p.backupLock.Lock()
+	backup := p.backup
+	changes := p.changes
+	p.backupLock.Unlock()
+
+	var widgets []layout.Widget
+
+	widgets = append(widgets,
+		func(gtx layout.Context) layout.Dimensions {
+			return layoutSectionTitle(gtx, th, lang.Translate("Backup"),
+				lang.Translate("Saves the settings, nodes, dApps and every wallet with its contacts, tokens and history in one file encrypted with a password. Wallets stay encrypted with their own password."))
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.txtBackupPassword.Layout(gtx, th, lang.Translate("Backup password"), "")
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.txtBackupConfirm.Layout(gtx, th, lang.Translate("Confirm password"), "")
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonCreateBackup.Text = lang.Translate("CREATE BACKUP")
+			p.buttonCreateBackup.Style.Colors = theme.Current.ButtonPrimaryColors
+			return p.buttonCreateBackup.Layout(gtx, th)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return layoutSectionTitle(gtx, th, lang.Translate("Restore"),
+				lang.Translate("Open a backup to see what it will change before anything is written."))
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.txtRestorePassword.Layout(gtx, th, lang.Translate("Backup password"), "")
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonOpenBackup.Text = lang.Translate("OPEN BACKUP")
+			p.buttonOpenBackup.Style.Colors = theme.Current.ButtonSecondaryColors
+			return p.buttonOpenBackup.Layout(gtx, th)
+		},
+	)
+
+	if backup != nil {
+		widgets = append(widgets,
+			func(gtx layout.Context) layout.Dimensions {
+				created := time.Unix(backup.Info.Timestamp, 0).Format("2006-01-02 15:04")
+				lbl := material.Label(th, unit.Sp(16), fmt.Sprintf(lang.Translate("Backup of %s · %s"), created, backup.Info.AppVersion))
+				return lbl.Layout(gtx)
+			},
+			func(gtx layout.Context) layout.Dimensions {
+				return layoutSwitchRow(gtx, th, p.replace, lang.Translate("Replace current data"),
+					lang.Translate("Off merges the backup: missing wallets, contacts and other rows are added and what is on this device is kept. On makes this device match the backup and removes the wallets it doesn't have."))
+			},
+		)
+
+		for i := range changes {
+			change := changes[i]
+			widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+				return layoutBackupChange(gtx, th, change)
+			})
+		}
+
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			p.buttonRestore.Text = lang.Translate("RESTORE")
+			p.buttonRestore.Style.Colors = theme.Current.ButtonPrimaryColors
+			if p.replace.Value {
+				p.buttonRestore.Style.Colors = theme.Current.ButtonDangerColors
+			}
+
+			return p.buttonRestore.Layout(gtx, th)
+		})
+	}
+
+	listStyle := material.List(th, p.list)
+	listStyle.AnchorStrategy = material.Overlay
+
+	return listStyle.Layout(gtx, len(widgets), func(gtx layout.Context, index int) layout.Dimensions {
+		return layout.Inset{
+			Top: unit.Dp(0), Bottom: unit.Dp(20),
+			Left: unit.Dp(30), Right: unit.Dp(30),
+		}.Layout(gtx, widgets[index])
+	})
+}
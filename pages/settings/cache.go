@@ -0,0 +1,321 @@
+package page_settings
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/secretsystems/secret-wallet/animation"
+	"github.com/secretsystems/secret-wallet/app_instance"
+	"github.com/secretsystems/secret-wallet/caching"
+	"github.com/secretsystems/secret-wallet/components"
+	"github.com/secretsystems/secret-wallet/containers/confirm_modal"
+	"github.com/secretsystems/secret-wallet/containers/notification_modals"
+	"github.com/secretsystems/secret-wallet/lang"
+	"github.com/secretsystems/secret-wallet/prefabs"
+	"github.com/secretsystems/secret-wallet/router"
+	"github.com/secretsystems/secret-wallet/settings"
+	"github.com/secretsystems/secret-wallet/theme"
+	"github.com/secretsystems/secret-wallet/utils"
+	"github.com/tanema/gween"
+	"github.com/tanema/gween/ease"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+type CacheCategoryItem struct {
+	category    caching.Category
+	infoRows    []*prefabs.InfoRow
+	buttonClear *components.Button
+}
+
+type PageCache struct {
+	isActive bool
+
+	animationEnter *animation.Animation
+	animationLeave *animation.Animation
+
+	list           *widget.List
+	items          []*CacheCategoryItem
+	usages         map[string]caching.Usage
+	usagesMutex    sync.Mutex
+	totalInfoRow   *prefabs.InfoRow
+	txtMaxSize     *prefabs.TextField
+	buttonSaveSize *components.Button
+	buttonClearAll *components.Button
+}
+
+var _ router.Page = &PageCache{}
+
+func newClearButton() *components.Button {
+	deleteIcon, _ := widget.NewIcon(icons.ActionDelete)
+	loadingIcon, _ := widget.NewIcon(icons.NavigationRefresh)
+	button := components.NewButton(components.ButtonStyle{
+		Rounded:     components.UniformRounded(unit.Dp(5)),
+		Icon:        deleteIcon,
+		TextSize:    unit.Sp(14),
+		IconGap:     unit.Dp(10),
+		Inset:       layout.UniformInset(unit.Dp(10)),
+		Animation:   components.NewButtonAnimationDefault(),
+		LoadingIcon: loadingIcon,
+	})
+	button.Label.Alignment = text.Middle
+	button.Style.Font.Weight = font.Bold
+	return button
+}
+
+func NewPageCache() *PageCache {
+	animationEnter := animation.NewAnimation(false, gween.NewSequence(
+		gween.New(1, 0, .25, ease.Linear),
+	))
+
+	animationLeave := animation.NewAnimation(false, gween.NewSequence(
+		gween.New(0, 1, .25, ease.Linear),
+	))
+
+	list := new(widget.List)
+	list.Axis = layout.Vertical
+
+	var items []*CacheCategoryItem
+	for _, category := range caching.Categories {
+		items = append(items, &CacheCategoryItem{
+			category:    category,
+			infoRows:    prefabs.NewInfoRows(3),
+			buttonClear: newClearButton(),
+		})
+	}
+
+	saveIcon, _ := widget.NewIcon(icons.ContentSave)
+	buttonSaveSize := components.NewButton(components.ButtonStyle{
+		Rounded:   components.UniformRounded(unit.Dp(5)),
+		Icon:      saveIcon,
+		TextSize:  unit.Sp(14),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+	})
+	buttonSaveSize.Label.Alignment = text.Middle
+	buttonSaveSize.Style.Font.Weight = font.Bold
+
+	return &PageCache{
+		animationEnter: animationEnter,
+		animationLeave: animationLeave,
+
+		list:           list,
+		items:          items,
+		usages:         make(map[string]caching.Usage),
+		totalInfoRow:   prefabs.NewInfoRow(),
+		txtMaxSize:     prefabs.NewNumberTextField(),
+		buttonSaveSize: buttonSaveSize,
+		buttonClearAll: newClearButton(),
+	}
+}
+
+func (p *PageCache) IsActive() bool {
+	return p.isActive
+}
+
+func (p *PageCache) Enter() {
+	p.isActive = true
+	page_instance.header.Title = func() string { return lang.Translate("Cache") }
+
+	p.txtMaxSize.SetValue(fmt.Sprint(settings.App.CacheMaxSize >> 20))
+	go p.loadUsage()
+
+	if !page_instance.header.IsHistory(PAGE_CACHE) {
+		p.animationEnter.Start()
+		p.animationLeave.Reset()
+	}
+}
+
+func (p *PageCache) Leave() {
+	p.animationEnter.Reset()
+	p.animationLeave.Start()
+}
+
+func (p *PageCache) loadUsage() {
+	usages, err := caching.GetUsage()
+	if err != nil {
+		notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+		notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		return
+	}
+
+	p.usagesMutex.Lock()
+	for _, usage := range usages {
+		p.usages[usage.Category.Key] = usage
+	}
+	p.usagesMutex.Unlock()
+	app_instance.Window.Invalidate()
+}
+
+func (p *PageCache) clear(button *components.Button, prompt string, clear func() error) {
+	go func() {
+		yesChan := confirm_modal.Instance.Open(confirm_modal.ConfirmText{
+			Prompt: prompt,
+		})
+
+		for yes := range yesChan {
+			if !yes {
+				continue
+			}
+
+			button.SetLoading(true)
+			err := clear()
+			button.SetLoading(false)
+			if err != nil {
+				notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+				notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+			} else {
+				notification_modals.SuccessInstance.SetText(lang.Translate("Success"), lang.Translate("Cache cleared."))
+				notification_modals.SuccessInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+			}
+
+			p.loadUsage()
+		}
+	}()
+}
+
+func (p *PageCache) saveMaxSize() error {
+	size, err := strconv.ParseInt(p.txtMaxSize.Value(), 10, 64)
+	if err != nil || size < 0 {
+		return fmt.Errorf("invalid max size")
+	}
+
+	settings.App.CacheMaxSize = size << 20
+	return settings.Save()
+}
+
+func formatTTL(category caching.Category) string {
+	if category.TTL == 0 {
+		return lang.Translate("Never")
+	}
+
+	return category.TTL.String()
+}
+
+func (p *PageCache) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	{
+		state := p.animationEnter.Update(gtx)
+		if state.Active {
+			defer animation.TransformX(gtx, state.Value).Push(gtx.Ops).Pop()
+		}
+	}
+
+	{
+		state := p.animationLeave.Update(gtx)
+		if state.Finished {
+			p.isActive = false
+			op.InvalidateOp{}.Add(gtx.Ops)
+		}
+
+		if state.Active {
+			defer animation.TransformX(gtx, state.Value).Push(gtx.Ops).Pop()
+		}
+	}
+
+	if p.buttonSaveSize.Clicked() {
+		err := p.saveMaxSize()
+		if err != nil {
+			notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+			notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		} else {
+			notification_modals.SuccessInstance.SetText(lang.Translate("Success"), lang.Translate("Max cache size saved."))
+			notification_modals.SuccessInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		}
+	}
+
+	if p.buttonClearAll.Clicked() {
+		p.clear(p.buttonClearAll, lang.Translate("Clear the whole cache?"), caching.ClearAll)
+	}
+
+	p.usagesMutex.Lock()
+	usages := make(map[string]caching.Usage)
+	var totalSize int64
+	for key, usage := range p.usages {
+		usages[key] = usage
+		totalSize += usage.Size
+	}
+	p.usagesMutex.Unlock()
+
+	var widgets []layout.Widget
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		lbl := material.Label(th, unit.Sp(16), lang.Translate("Smart contract data and images are cached to avoid fetching them again. Entries are refreshed once expired and the least recently used are removed when the cache is full."))
+		return lbl.Layout(gtx)
+	})
+
+	for i := range p.items {
+		item := p.items[i]
+		usage := usages[item.category.Key]
+
+		if item.buttonClear.Clicked() {
+			prompt := fmt.Sprintf(lang.Translate("Clear %s cache?"), lang.Translate(item.category.Name))
+			category := item.category
+			p.clear(item.buttonClear, prompt, func() error {
+				return caching.ClearCategory(category)
+			})
+		}
+
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					lbl := material.Label(th, unit.Sp(18), lang.Translate(item.category.Name))
+					lbl.Font.Weight = font.Bold
+					return lbl.Layout(gtx)
+				}),
+				layout.Rigid(layout.Spacer{Height: unit.Dp(5)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return item.infoRows[0].Layout(gtx, th, lang.Translate("Files"), fmt.Sprint(usage.Files))
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return item.infoRows[1].Layout(gtx, th, lang.Translate("Size"), utils.FormatBytes(usage.Size))
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return item.infoRows[2].Layout(gtx, th, lang.Translate("Expires after"), formatTTL(item.category))
+				}),
+				layout.Rigid(layout.Spacer{Height: unit.Dp(10)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					item.buttonClear.Text = lang.Translate("CLEAR")
+					item.buttonClear.Style.Colors = theme.Current.ButtonSecondaryColors
+					return item.buttonClear.Layout(gtx, th)
+				}),
+			)
+		})
+	}
+
+	widgets = append(widgets,
+		func(gtx layout.Context) layout.Dimensions {
+			return p.totalInfoRow.Layout(gtx, th, lang.Translate("Total"), utils.FormatBytes(totalSize))
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.txtMaxSize.Layout(gtx, th, lang.Translate("Max cache size (MB)"), lang.Translate("0 is unlimited"))
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonSaveSize.Text = lang.Translate("SAVE")
+			p.buttonSaveSize.Style.Colors = theme.Current.ButtonPrimaryColors
+			return p.buttonSaveSize.Layout(gtx, th)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonClearAll.Text = lang.Translate("CLEAR ALL")
+			p.buttonClearAll.Style.Colors = theme.Current.ButtonDangerColors
+			return p.buttonClearAll.Layout(gtx, th)
+		},
+	)
+
+	listStyle := material.List(th, p.list)
+	listStyle.AnchorStrategy = material.Overlay
+
+	return listStyle.Layout(gtx, len(widgets), func(gtx layout.Context, index int) layout.Dimensions {
+		return layout.Inset{
+			Top: unit.Dp(0), Bottom: unit.Dp(20),
+			Left: unit.Dp(30), Right: unit.Dp(30),
+		}.Layout(gtx, widgets[index])
+	})
+}
@@ -0,0 +1,366 @@
+package page_settings
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/secretsystems/secret-wallet/animation"
+	"github.com/secretsystems/secret-wallet/app_instance"
+	"github.com/secretsystems/secret-wallet/components"
+	"github.com/secretsystems/secret-wallet/containers/listselect_modal"
+	"github.com/secretsystems/secret-wallet/containers/notification_modals"
+	"github.com/secretsystems/secret-wallet/lang"
+	"github.com/secretsystems/secret-wallet/prefabs"
+	"github.com/secretsystems/secret-wallet/router"
+	"github.com/secretsystems/secret-wallet/settings"
+	"github.com/secretsystems/secret-wallet/theme"
+	"github.com/tanema/gween"
+	"github.com/tanema/gween/ease"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+type PageIPFS struct {
+	isActive bool
+
+	animationEnter *animation.Animation
+	animationLeave *animation.Animation
+
+	list           *widget.List
+	gatewayButtons []*components.Button
+	txtUrl         *prefabs.TextField
+	txtTimeout     *prefabs.TextField
+	buttonAdd      *components.Button
+	txtMaxSize     *prefabs.TextField
+	buttonSaveSize *components.Button
+	buttonReset    *components.Button
+}
+
+var _ router.Page = &PageIPFS{}
+
+func NewPageIPFS() *PageIPFS {
+	animationEnter := animation.NewAnimation(false, gween.NewSequence(
+		gween.New(1, 0, .25, ease.Linear),
+	))
+
+	animationLeave := animation.NewAnimation(false, gween.NewSequence(
+		gween.New(0, 1, .25, ease.Linear),
+	))
+
+	list := new(widget.List)
+	list.Axis = layout.Vertical
+
+	addIcon, _ := widget.NewIcon(icons.ContentAdd)
+	buttonAdd := components.NewButton(components.ButtonStyle{
+		Rounded:   components.UniformRounded(unit.Dp(5)),
+		Icon:      addIcon,
+		TextSize:  unit.Sp(14),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+	})
+	buttonAdd.Label.Alignment = text.Middle
+	buttonAdd.Style.Font.Weight = font.Bold
+
+	saveIcon, _ := widget.NewIcon(icons.ContentSave)
+	buttonSaveSize := components.NewButton(components.ButtonStyle{
+		Rounded:   components.UniformRounded(unit.Dp(5)),
+		Icon:      saveIcon,
+		TextSize:  unit.Sp(14),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+	})
+	buttonSaveSize.Label.Alignment = text.Middle
+	buttonSaveSize.Style.Font.Weight = font.Bold
+
+	resetIcon, _ := widget.NewIcon(icons.ActionRestore)
+	buttonReset := components.NewButton(components.ButtonStyle{
+		Rounded:   components.UniformRounded(unit.Dp(5)),
+		Icon:      resetIcon,
+		TextSize:  unit.Sp(14),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+	})
+	buttonReset.Label.Alignment = text.Middle
+	buttonReset.Style.Font.Weight = font.Bold
+
+	return &PageIPFS{
+		animationEnter: animationEnter,
+		animationLeave: animationLeave,
+
+		list:           list,
+		txtUrl:         prefabs.NewTextField(),
+		txtTimeout:     prefabs.NewNumberTextField(),
+		buttonAdd:      buttonAdd,
+		txtMaxSize:     prefabs.NewNumberTextField(),
+		buttonSaveSize: buttonSaveSize,
+		buttonReset:    buttonReset,
+	}
+}
+
+func (p *PageIPFS) IsActive() bool {
+	return p.isActive
+}
+
+func (p *PageIPFS) Enter() {
+	p.isActive = true
+	page_instance.header.Title = func() string { return lang.Translate("IPFS Settings") }
+
+	p.txtTimeout.SetValue("15")
+	p.txtMaxSize.SetValue(fmt.Sprint(settings.App.FetchMaxSize >> 20))
+	p.loadGateways()
+
+	if !page_instance.header.IsHistory(PAGE_IPFS) {
+		p.animationEnter.Start()
+		p.animationLeave.Reset()
+	}
+}
+
+func (p *PageIPFS) Leave() {
+	p.animationEnter.Reset()
+	p.animationLeave.Start()
+}
+
+func (p *PageIPFS) loadGateways() {
+	p.gatewayButtons = nil
+	for range settings.App.IPFSGateways {
+		button := components.NewButton(components.ButtonStyle{
+			Rounded:   components.UniformRounded(unit.Dp(5)),
+			TextSize:  unit.Sp(14),
+			Inset:     layout.UniformInset(unit.Dp(10)),
+			Animation: components.NewButtonAnimationDefault(),
+		})
+		button.Label.Alignment = text.Start
+		p.gatewayButtons = append(p.gatewayButtons, button)
+	}
+}
+
+// the default list is copied so editing the settings never changes it
+func (p *PageIPFS) saveGateways(gateways []settings.IPFSGateway) error {
+	settings.App.IPFSGateways = append([]settings.IPFSGateway{}, gateways...)
+	err := settings.Save()
+	if err != nil {
+		return err
+	}
+
+	p.loadGateways()
+	return nil
+}
+
+func (p *PageIPFS) addGateway() error {
+	gatewayUrl, err := url.Parse(p.txtUrl.Value())
+	if err != nil {
+		return err
+	}
+
+	if gatewayUrl.Scheme != "https" && gatewayUrl.Scheme != "http" || gatewayUrl.Host == "" {
+		return fmt.Errorf("invalid gateway url")
+	}
+
+	timeout, err := strconv.Atoi(p.txtTimeout.Value())
+	if err != nil || timeout <= 0 {
+		return fmt.Errorf("invalid timeout")
+	}
+
+	gateways := settings.App.IPFSGateways
+	for _, gateway := range gateways {
+		if gateway.Url == gatewayUrl.String() {
+			return fmt.Errorf("gateway already exists")
+		}
+	}
+
+	gateways = append(gateways, settings.IPFSGateway{Url: gatewayUrl.String(), Timeout: timeout})
+	return p.saveGateways(gateways)
+}
+
+func (p *PageIPFS) saveMaxSize() error {
+	size, err := strconv.ParseInt(p.txtMaxSize.Value(), 10, 64)
+	if err != nil || size <= 0 {
+		return fmt.Errorf("invalid max size")
+	}
+
+	settings.App.FetchMaxSize = size << 20
+	return settings.Save()
+}
+
+func (p *PageIPFS) openGatewayMenu(index int) {
+	go func() {
+		upIcon, _ := widget.NewIcon(icons.NavigationArrowUpward)
+		downIcon, _ := widget.NewIcon(icons.NavigationArrowDownward)
+		deleteIcon, _ := widget.NewIcon(icons.ActionDelete)
+
+		gateways := append([]settings.IPFSGateway{}, settings.App.IPFSGateways...)
+		var items []*listselect_modal.SelectListItem
+
+		if index > 0 {
+			items = append(items, listselect_modal.NewSelectListItem("move_up",
+				listselect_modal.NewItemText(upIcon, lang.Translate("Move up")).Layout,
+			))
+		}
+
+		if index < len(gateways)-1 {
+			items = append(items, listselect_modal.NewSelectListItem("move_down",
+				listselect_modal.NewItemText(downIcon, lang.Translate("Move down")).Layout,
+			))
+		}
+
+		items = append(items, listselect_modal.NewSelectListItem("remove_gateway",
+			listselect_modal.NewItemText(deleteIcon, lang.Translate("Remove gateway")).Layout,
+		))
+
+		keyChan := listselect_modal.Instance.Open(items)
+
+		for sKey := range keyChan {
+			switch sKey {
+			case "move_up":
+				gateways[index-1], gateways[index] = gateways[index], gateways[index-1]
+			case "move_down":
+				gateways[index+1], gateways[index] = gateways[index], gateways[index+1]
+			case "remove_gateway":
+				gateways = append(gateways[:index], gateways[index+1:]...)
+			}
+
+			err := p.saveGateways(gateways)
+			if err != nil {
+				notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+				notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+			}
+
+			app_instance.Window.Invalidate()
+		}
+	}()
+}
+
+func (p *PageIPFS) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	{
+		state := p.animationEnter.Update(gtx)
+		if state.Active {
+			defer animation.TransformX(gtx, state.Value).Push(gtx.Ops).Pop()
+		}
+	}
+
+	{
+		state := p.animationLeave.Update(gtx)
+		if state.Finished {
+			p.isActive = false
+			op.InvalidateOp{}.Add(gtx.Ops)
+		}
+
+		if state.Active {
+			defer animation.TransformX(gtx, state.Value).Push(gtx.Ops).Pop()
+		}
+	}
+
+	if p.buttonAdd.Clicked() {
+		err := p.addGateway()
+		if err != nil {
+			notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+			notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		} else {
+			p.txtUrl.SetValue("")
+			notification_modals.SuccessInstance.SetText(lang.Translate("Success"), lang.Translate("Gateway added."))
+			notification_modals.SuccessInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		}
+	}
+
+	if p.buttonSaveSize.Clicked() {
+		err := p.saveMaxSize()
+		if err != nil {
+			notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+			notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		} else {
+			notification_modals.SuccessInstance.SetText(lang.Translate("Success"), lang.Translate("Max download size saved."))
+			notification_modals.SuccessInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		}
+	}
+
+	if p.buttonReset.Clicked() {
+		err := p.saveGateways(settings.DefaultIPFSGateways)
+		if err != nil {
+			notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+			notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		}
+	}
+
+	var widgets []layout.Widget
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		lbl := material.Label(th, unit.Sp(16), lang.Translate("ipfs:// and ipns:// links are fetched from these gateways in order. The next gateway is used if one fails or times out. IPFS content is checked against its CID."))
+		return lbl.Layout(gtx)
+	})
+
+	gateways := settings.App.IPFSGateways
+	for i := range p.gatewayButtons {
+		if i >= len(gateways) {
+			break
+		}
+
+		index := i
+		button := p.gatewayButtons[i]
+		gateway := gateways[i]
+
+		if button.Clicked() {
+			p.openGatewayMenu(index)
+		}
+
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			button.Text = fmt.Sprintf("%d. %s (%ds)", index+1, gateway.Url, gateway.Timeout)
+			button.Style.Colors = theme.Current.ButtonSecondaryColors
+			return button.Layout(gtx, th)
+		})
+	}
+
+	if len(gateways) == 0 {
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(16), lang.Translate("No gateways. IPFS links can't be loaded."))
+			lbl.Color = theme.Current.TextMuteColor
+			return lbl.Layout(gtx)
+		})
+	}
+
+	widgets = append(widgets,
+		func(gtx layout.Context) layout.Dimensions {
+			return p.txtUrl.Layout(gtx, th, lang.Translate("Gateway"), "https://ipfs.io")
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.txtTimeout.Layout(gtx, th, lang.Translate("Timeout (seconds)"), "15")
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonAdd.Text = lang.Translate("ADD GATEWAY")
+			p.buttonAdd.Style.Colors = theme.Current.ButtonPrimaryColors
+			return p.buttonAdd.Layout(gtx, th)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonReset.Text = lang.Translate("RESET GATEWAYS")
+			p.buttonReset.Style.Colors = theme.Current.ButtonSecondaryColors
+			return p.buttonReset.Layout(gtx, th)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.txtMaxSize.Layout(gtx, th, lang.Translate("Max download size (MB)"), "20")
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonSaveSize.Text = lang.Translate("SAVE")
+			p.buttonSaveSize.Style.Colors = theme.Current.ButtonPrimaryColors
+			return p.buttonSaveSize.Layout(gtx, th)
+		},
+	)
+
+	listStyle := material.List(th, p.list)
+	listStyle.AnchorStrategy = material.Overlay
+
+	return listStyle.Layout(gtx, len(widgets), func(gtx layout.Context, index int) layout.Dimensions {
+		return layout.Inset{
+			Top: unit.Dp(0), Bottom: unit.Dp(20),
+			Left: unit.Dp(30), Right: unit.Dp(30),
+		}.Layout(gtx, widgets[index])
+	})
+}
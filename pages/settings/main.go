@@ -1,6 +1,7 @@
 package page_settings
 
 import (
+	"fmt"
 	"image/color"
 
 	"gioui.org/font"
@@ -11,9 +12,13 @@ import (
 	"gioui.org/widget"
 	"gioui.org/widget/material"
 	"github.com/secretsystems/secret-wallet/animation"
+	"github.com/secretsystems/secret-wallet/app_instance"
 	"github.com/secretsystems/secret-wallet/components"
+	"github.com/secretsystems/secret-wallet/containers/confirm_modal"
 	"github.com/secretsystems/secret-wallet/containers/notification_modals"
 	"github.com/secretsystems/secret-wallet/lang"
+	"github.com/secretsystems/secret-wallet/node_manager"
+	"github.com/secretsystems/secret-wallet/pages"
 	"github.com/secretsystems/secret-wallet/prefabs"
 	"github.com/secretsystems/secret-wallet/router"
 	"github.com/secretsystems/secret-wallet/settings"
@@ -29,11 +34,16 @@ type PageMain struct {
 	animationEnter *animation.Animation
 	animationLeave *animation.Animation
 
-	langSelector  *prefabs.LangSelector
-	themeSelector *prefabs.ThemeSelector
-	buttonInfo    *components.Button
-	buttonDERO    *components.Button
-	buttonRPC     *components.Button
+	langSelector    *prefabs.LangSelector
+	themeSelector   *prefabs.ThemeSelector
+	networkSelector *prefabs.NetworkSelector
+	buttonInfo      *components.Button
+	buttonDERO      *components.Button
+	buttonRPC       *components.Button
+	buttonXSWD      *components.Button
+	buttonBackup    *components.Button
+	buttonIPFS      *components.Button
+	buttonCache     *components.Button
 }
 
 var _ router.Page = &PageMain{}
@@ -43,6 +53,7 @@ func NewPageFront() *PageMain {
 	defaultThemeKey := settings.App.Theme
 	langSelector := prefabs.NewLangSelector(defaultLangKey)
 	themeSelector := prefabs.NewThemeSelector(defaultThemeKey)
+	networkSelector := prefabs.NewNetworkSelector(settings.App.Network)
 
 	animationEnter := animation.NewAnimation(false, gween.NewSequence(
 		gween.New(-1, 0, .25, ease.Linear),
@@ -101,6 +112,70 @@ func NewPageFront() *PageMain {
 	})
 	buttonRPC.Label.Alignment = text.Middle
 	buttonRPC.Style.Font.Weight = font.Bold
+	infoIcon, _ = widget.NewIcon(icons.ActionExtension)
+
+	buttonXSWD := components.NewButton(components.ButtonStyle{
+		Icon:      infoIcon,
+		TextSize:  unit.Sp(16),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+		Border: widget.Border{
+			Color:        color.NRGBA{R: 0, G: 0, B: 0, A: 255},
+			Width:        unit.Dp(2),
+			CornerRadius: unit.Dp(5),
+		},
+	})
+	buttonXSWD.Label.Alignment = text.Middle
+	buttonXSWD.Style.Font.Weight = font.Bold
+	infoIcon, _ = widget.NewIcon(icons.ActionBackup)
+
+	buttonBackup := components.NewButton(components.ButtonStyle{
+		Icon:      infoIcon,
+		TextSize:  unit.Sp(16),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+		Border: widget.Border{
+			Color:        color.NRGBA{R: 0, G: 0, B: 0, A: 255},
+			Width:        unit.Dp(2),
+			CornerRadius: unit.Dp(5),
+		},
+	})
+	buttonBackup.Label.Alignment = text.Middle
+	buttonBackup.Style.Font.Weight = font.Bold
+	infoIcon, _ = widget.NewIcon(icons.FileCloudDownload)
+
+	buttonIPFS := components.NewButton(components.ButtonStyle{
+		Icon:      infoIcon,
+		TextSize:  unit.Sp(16),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+		Border: widget.Border{
+			Color:        color.NRGBA{R: 0, G: 0, B: 0, A: 255},
+			Width:        unit.Dp(2),
+			CornerRadius: unit.Dp(5),
+		},
+	})
+	buttonIPFS.Label.Alignment = text.Middle
+	buttonIPFS.Style.Font.Weight = font.Bold
+	infoIcon, _ = widget.NewIcon(icons.DeviceStorage)
+
+	buttonCache := components.NewButton(components.ButtonStyle{
+		Icon:      infoIcon,
+		TextSize:  unit.Sp(16),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+		Border: widget.Border{
+			Color:        color.NRGBA{R: 0, G: 0, B: 0, A: 255},
+			Width:        unit.Dp(2),
+			CornerRadius: unit.Dp(5),
+		},
+	})
+	buttonCache.Label.Alignment = text.Middle
+	buttonCache.Style.Font.Weight = font.Bold
 
 	list := new(widget.List)
 	list.Axis = layout.Vertical
@@ -110,11 +185,16 @@ func NewPageFront() *PageMain {
 		animationEnter: animationEnter,
 		animationLeave: animationLeave,
 
-		langSelector:  langSelector,
-		themeSelector: themeSelector,
-		buttonInfo:    buttonInfo,
-		buttonDERO:    buttonDERO,
-		buttonRPC:     buttonRPC,
+		langSelector:    langSelector,
+		themeSelector:   themeSelector,
+		networkSelector: networkSelector,
+		buttonInfo:      buttonInfo,
+		buttonDERO:      buttonDERO,
+		buttonRPC:       buttonRPC,
+		buttonXSWD:      buttonXSWD,
+		buttonBackup:    buttonBackup,
+		buttonIPFS:      buttonIPFS,
+		buttonCache:     buttonCache,
 	}
 }
 
@@ -174,6 +254,26 @@ func (p *PageMain) Layout(gtx layout.Context, th *material.Theme) layout.Dimensi
 		page_instance.header.AddHistory(PAGE_RPC)
 	}
 
+	if p.buttonXSWD.Clicked() {
+		page_instance.pageRouter.SetCurrent(PAGE_XSWD)
+		page_instance.header.AddHistory(PAGE_XSWD)
+	}
+
+	if p.buttonBackup.Clicked() {
+		page_instance.pageRouter.SetCurrent(PAGE_BACKUP)
+		page_instance.header.AddHistory(PAGE_BACKUP)
+	}
+
+	if p.buttonIPFS.Clicked() {
+		page_instance.pageRouter.SetCurrent(PAGE_IPFS)
+		page_instance.header.AddHistory(PAGE_IPFS)
+	}
+
+	if p.buttonCache.Clicked() {
+		page_instance.pageRouter.SetCurrent(PAGE_CACHE)
+		page_instance.header.AddHistory(PAGE_CACHE)
+	}
+
 	if p.langSelector.Changed {
 		settings.App.Language = p.langSelector.Key
 		err := settings.Save()
@@ -200,6 +300,10 @@ func (p *PageMain) Layout(gtx layout.Context, th *material.Theme) layout.Dimensi
 		}
 	}
 
+	if p.networkSelector.Changed {
+		go p.switchNetwork(p.networkSelector.Key)
+	}
+
 	widgets := []layout.Widget{
 		func(gtx layout.Context) layout.Dimensions {
 			return p.langSelector.Layout(gtx, th)
@@ -207,11 +311,34 @@ func (p *PageMain) Layout(gtx layout.Context, th *material.Theme) layout.Dimensi
 		func(gtx layout.Context) layout.Dimensions {
 			return p.themeSelector.Layout(gtx, th)
 		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.networkSelector.Layout(gtx, th)
+		},
 		func(gtx layout.Context) layout.Dimensions {
 			p.buttonRPC.Text = lang.Translate("RPC Settings")
 			p.buttonRPC.Style.Colors = theme.Current.ButtonSecondaryColors
 			return p.buttonRPC.Layout(gtx, th)
 		},
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonXSWD.Text = lang.Translate("dApp Connections")
+			p.buttonXSWD.Style.Colors = theme.Current.ButtonSecondaryColors
+			return p.buttonXSWD.Layout(gtx, th)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonIPFS.Text = lang.Translate("IPFS Settings")
+			p.buttonIPFS.Style.Colors = theme.Current.ButtonSecondaryColors
+			return p.buttonIPFS.Layout(gtx, th)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonCache.Text = lang.Translate("Cache")
+			p.buttonCache.Style.Colors = theme.Current.ButtonSecondaryColors
+			return p.buttonCache.Layout(gtx, th)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonBackup.Text = lang.Translate("Backup & Restore")
+			p.buttonBackup.Style.Colors = theme.Current.ButtonSecondaryColors
+			return p.buttonBackup.Layout(gtx, th)
+		},
 		func(gtx layout.Context) layout.Dimensions {
 			p.buttonDERO.Text = lang.Translate("About DERO")
 			p.buttonDERO.Style.Colors = theme.Current.ButtonSecondaryColors
@@ -234,3 +361,29 @@ func (p *PageMain) Layout(gtx layout.Context, th *material.Theme) layout.Dimensi
 		}.Layout(gtx, widgets[index])
 	})
 }
+
+func (p *PageMain) switchNetwork(network string) {
+	yesChan := confirm_modal.Instance.Open(confirm_modal.ConfirmText{
+		Prompt: lang.Translate("Switch network? The opened wallet will be closed."),
+	})
+
+	for yes := range yesChan {
+		if !yes {
+			p.networkSelector.Key = settings.App.Network
+			continue
+		}
+
+		app_instance.Router.SetCurrent(pages.PAGE_WALLET_SELECT)
+		err := node_manager.SwitchNetwork(network)
+		p.networkSelector.Key = settings.App.Network
+		if err != nil {
+			notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+			notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		} else {
+			notification_modals.SuccessInstance.SetText(lang.Translate("Success"), fmt.Sprintf(lang.Translate("Switched to %s."), prefabs.NetworkName(network)))
+			notification_modals.SuccessInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		}
+
+		app_instance.Window.Invalidate()
+	}
+}
@@ -24,17 +24,29 @@ type Page struct {
 	header         *prefabs.Header
 	pageRouter     *router.Router
 
-	pageMain    *PageMain
-	pageAppInfo *PageAppInfo
-	pageDero    *PageDero
-	pageRpc     *PageRpc
+	pageMain      *PageMain
+	pageAppInfo   *PageAppInfo
+	pageDero      *PageDero
+	pageRpc       *PageRpc
+	pageRpcPolicy *PageRpcPolicy
+	pageXSWD      *PageXSWD
+	pageXSWDApp   *PageXSWDApp
+	pageIPFS      *PageIPFS
+	pageCache     *PageCache
+	pageBackup    *PageBackup
 }
 
 var (
-	PAGE_MAIN     = "page_main"
-	PAGE_APP_INFO = "page_app_info"
-	PAGE_DERO     = "page_dero"
-	PAGE_RPC      = "page_rpc"
+	PAGE_MAIN       = "page_main"
+	PAGE_APP_INFO   = "page_app_info"
+	PAGE_DERO       = "page_dero"
+	PAGE_RPC        = "page_rpc"
+	PAGE_RPC_POLICY = "page_rpc_policy"
+	PAGE_XSWD       = "page_xswd"
+	PAGE_XSWD_APP   = "page_xswd_app"
+	PAGE_IPFS       = "page_ipfs"
+	PAGE_CACHE      = "page_cache"
+	PAGE_BACKUP     = "page_backup"
 )
 
 var page_instance *Page
@@ -64,6 +76,24 @@ func New() *Page {
 	pageRpc := NewPageRpc()
 	pageRouter.Add(PAGE_RPC, pageRpc)
 
+	pageRpcPolicy := NewPageRpcPolicy()
+	pageRouter.Add(PAGE_RPC_POLICY, pageRpcPolicy)
+
+	pageXSWD := NewPageXSWD()
+	pageRouter.Add(PAGE_XSWD, pageXSWD)
+
+	pageXSWDApp := NewPageXSWDApp()
+	pageRouter.Add(PAGE_XSWD_APP, pageXSWDApp)
+
+	pageIPFS := NewPageIPFS()
+	pageRouter.Add(PAGE_IPFS, pageIPFS)
+
+	pageCache := NewPageCache()
+	pageRouter.Add(PAGE_CACHE, pageCache)
+
+	pageBackup := NewPageBackup()
+	pageRouter.Add(PAGE_BACKUP, pageBackup)
+
 	header := prefabs.NewHeader(pageRouter)
 
 	page := &Page{
@@ -74,6 +104,12 @@ func New() *Page {
 		pageMain:       pageMain,
 		pageDero:       pageDero,
 		pageRpc:        pageRpc,
+		pageRpcPolicy:  pageRpcPolicy,
+		pageXSWD:       pageXSWD,
+		pageXSWDApp:    pageXSWDApp,
+		pageIPFS:       pageIPFS,
+		pageCache:      pageCache,
+		pageBackup:     pageBackup,
 	}
 
 	page_instance = page
@@ -3,6 +3,7 @@ package page_settings
 import (
 	"fmt"
 	"net"
+	"time"
 
 	"gioui.org/font"
 	"gioui.org/layout"
@@ -11,16 +12,15 @@ import (
 	"gioui.org/unit"
 	"gioui.org/widget"
 	"gioui.org/widget/material"
-	"github.com/deroproject/derohe/globals"
-	"github.com/deroproject/derohe/walletapi/rpcserver"
 	"github.com/secretsystems/secret-wallet/animation"
+	"github.com/secretsystems/secret-wallet/app_instance"
 	"github.com/secretsystems/secret-wallet/components"
 	"github.com/secretsystems/secret-wallet/containers/notification_modals"
 	"github.com/secretsystems/secret-wallet/lang"
 	"github.com/secretsystems/secret-wallet/prefabs"
 	"github.com/secretsystems/secret-wallet/router"
+	"github.com/secretsystems/secret-wallet/settings"
 	"github.com/secretsystems/secret-wallet/theme"
-	"github.com/secretsystems/secret-wallet/utils"
 	"github.com/secretsystems/secret-wallet/wallet_manager"
 	"github.com/tanema/gween"
 	"github.com/tanema/gween/ease"
@@ -38,13 +38,18 @@ type PageRpc struct {
 }
 
 type RpcServer struct {
-	user      string
-	pass      string
-	buttonOn  *components.Button
-	buttonOff *components.Button
-	txtUser   *prefabs.TextField
-	txtPass   *prefabs.TextField
-	server    *rpcserver.RPCServer
+	buttonOn     *components.Button
+	buttonOff    *components.Button
+	buttonPolicy *components.Button
+	txtBind      *prefabs.TextField
+	txtUser      *prefabs.TextField
+	txtPass      *prefabs.TextField
+	tls          *widget.Bool
+	remember     *widget.Bool
+	autoStart    *widget.Bool
+	statusRows   []*prefabs.InfoRow
+	requestRows  map[string]*prefabs.InfoRow
+	localAddress string
 }
 
 func NewRPCServer() *RpcServer {
@@ -76,17 +81,33 @@ func NewRPCServer() *RpcServer {
 	buttonOff.Label.Alignment = text.Middle
 	buttonOff.Style.Font.Weight = font.Bold
 
-	txtUser := prefabs.NewTextField()
-	txtPass := prefabs.NewTextField()
+	addIcon, _ = widget.NewIcon(icons.ActionVerifiedUser)
+	buttonPolicy := components.NewButton(components.ButtonStyle{
+		Rounded:   components.UniformRounded(unit.Dp(5)),
+		Icon:      addIcon,
+		TextSize:  unit.Sp(14),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+	})
+	buttonPolicy.Label.Alignment = text.Middle
+	buttonPolicy.Style.Font.Weight = font.Bold
+
+	localAddress, _ := getLocalIP()
 
 	item := &RpcServer{
-		user:      "",
-		pass:      "",
-		buttonOn:  buttonOn,
-		buttonOff: buttonOff,
-		txtUser:   txtUser,
-		txtPass:   txtPass,
-		server:    nil,
+		buttonOn:     buttonOn,
+		buttonOff:    buttonOff,
+		buttonPolicy: buttonPolicy,
+		txtBind:      prefabs.NewTextField(),
+		txtUser:      prefabs.NewTextField(),
+		txtPass:      prefabs.NewPasswordTextField(),
+		tls:          new(widget.Bool),
+		remember:     new(widget.Bool),
+		autoStart:    new(widget.Bool),
+		statusRows:   prefabs.NewInfoRows(6),
+		requestRows:  make(map[string]*prefabs.InfoRow),
+		localAddress: localAddress,
 	}
 	return item
 }
@@ -105,6 +126,9 @@ func NewPageRpc() *PageRpc {
 	list := new(widget.List)
 	list.Axis = layout.Vertical
 
+	// transfers and contract calls of the rpc server are confirmed in the app
+	wallet_manager.OnRPCApproval = approveRPCRequest
+
 	return &PageRpc{
 		animationEnter: animationEnter,
 		animationLeave: animationLeave,
@@ -123,17 +147,158 @@ func (p *PageRpc) Enter() {
 	p.isActive = true
 	page_instance.header.Title = func() string { return lang.Translate("RPC Settings") }
 
-	if !page_instance.header.IsHistory(PAGE_APP_INFO) {
+	p.load()
+
+	if !page_instance.header.IsHistory(PAGE_RPC) {
 		p.animationEnter.Start()
 		p.animationLeave.Reset()
 	}
 }
 
+// fills the form with the app settings and the saved credentials of the opened wallet
+func (p *PageRpc) load() {
+	s := p.rpcServer
+	s.txtBind.SetValue(settings.App.RPCBind)
+	s.tls.Value = settings.App.RPCTLS
+	s.txtUser.SetValue("")
+	s.txtPass.SetValue("")
+	s.remember.Value = false
+	s.autoStart.Value = false
+
+	wallet := wallet_manager.OpenedWallet
+	if wallet == nil || wallet.IsWatchOnly() {
+		return
+	}
+
+	credentials, found, err := wallet.GetRPCCredentials()
+	if err != nil {
+		notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+		notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		return
+	}
+
+	if found {
+		s.txtUser.SetValue(credentials.User)
+		s.txtPass.SetValue(credentials.Password)
+		s.remember.Value = true
+		s.autoStart.Value = credentials.AutoStart
+	}
+}
+
 func (p *PageRpc) Leave() {
 	p.animationEnter.Reset()
 	p.animationLeave.Start()
 }
 
+func layoutSwitchRow(gtx layout.Context, th *material.Theme, value *widget.Bool, title string, description string) layout.Dimensions {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					s := material.Switch(th, value, "")
+					s.Color = theme.Current.SwitchColors
+					return s.Layout(gtx)
+				}),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					lbl := material.Label(th, unit.Sp(18), title)
+					return lbl.Layout(gtx)
+				}),
+			)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(5)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(14), description)
+			lbl.Color = theme.Current.TextMuteColor
+			return lbl.Layout(gtx)
+		}),
+	)
+}
+
+func formatRequestTime(t time.Time) string {
+	if t.IsZero() {
+		return "--"
+	}
+
+	return t.Local().Format("2006-01-02 15:04:05")
+}
+
+func (p *PageRpc) layoutStatus(gtx layout.Context, th *material.Theme, server *wallet_manager.RPCServer) layout.Dimensions {
+	rows := p.rpcServer.statusRows
+	var status wallet_manager.RPCServerStatus
+	state := lang.Translate("Stopped")
+	if server != nil {
+		status = server.Status()
+		switch {
+		case status.Running:
+			scheme := "http"
+			if status.TLS {
+				scheme = "https"
+			}
+
+			state = fmt.Sprintf(lang.Translate("Listening on %s"), fmt.Sprintf("%s://%s", scheme, status.Addr))
+		case status.Err != nil:
+			state = fmt.Sprintf("%s: %s", lang.Translate("Error"), status.Err)
+		}
+	}
+
+	children := []layout.FlexChild{
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(18), lang.Translate("Status"))
+			lbl.Font.Weight = font.Bold
+			return lbl.Layout(gtx)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(5)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return rows[0].Layout(gtx, th, lang.Translate("Server"), state)
+		}),
+	}
+
+	if server == nil {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+	}
+
+	children = append(children,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return rows[1].Layout(gtx, th, lang.Translate("Started"), formatRequestTime(status.StartedAt))
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			value := fmt.Sprintf(lang.Translate("%d (%d in the last %s)"), status.Total, status.Recent, wallet_manager.RPC_RECENT_WINDOW)
+			return rows[2].Layout(gtx, th, lang.Translate("Requests"), value)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return rows[3].Layout(gtx, th, lang.Translate("Last request"), formatRequestTime(status.LastRequest))
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return rows[4].Layout(gtx, th, lang.Translate("Failed logins"), fmt.Sprint(status.AuthFailed))
+		}),
+	)
+
+	if status.TLS {
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return rows[5].Layout(gtx, th, lang.Translate("Certificate SHA-256"), status.Fingerprint)
+		}))
+	}
+
+	for _, method := range status.Methods() {
+		row, ok := p.rpcServer.requestRows[method]
+		if !ok {
+			row = prefabs.NewInfoRow()
+			p.rpcServer.requestRows[method] = row
+		}
+
+		count := status.Requests[method]
+		name := method
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return row.Layout(gtx, th, name, fmt.Sprint(count))
+		}))
+	}
+
+	// the counters change without any user input
+	op.InvalidateOp{At: gtx.Now.Add(time.Second)}.Add(gtx.Ops)
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+}
+
 func (p *PageRpc) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
 	{
 		state := p.animationEnter.Update(gtx)
@@ -155,30 +320,55 @@ func (p *PageRpc) Layout(gtx layout.Context, th *material.Theme) layout.Dimensio
 	}
 
 	if p.rpcServer.buttonOn.Clicked() {
-		p.turnOn(gtx)
+		p.turnOn()
 	}
+
 	if p.rpcServer.buttonOff.Clicked() {
-		p.turnOff(gtx)
+		p.turnOff()
+	}
+
+	if p.rpcServer.buttonPolicy.Clicked() {
+		page_instance.pageRouter.SetCurrent(PAGE_RPC_POLICY)
+		page_instance.header.AddHistory(PAGE_RPC_POLICY)
+	}
+
+	// auto start needs the credentials to be saved
+	if p.rpcServer.autoStart.Changed() && p.rpcServer.autoStart.Value {
+		p.rpcServer.remember.Value = true
+	}
+
+	if p.rpcServer.remember.Changed() && !p.rpcServer.remember.Value {
+		p.rpcServer.autoStart.Value = false
+	}
+
+	var server *wallet_manager.RPCServer
+	wallet := wallet_manager.OpenedWallet
+	if wallet != nil {
+		server = wallet.Server
 	}
 
 	var widgets []layout.Widget
 
-	widgets = append(
-		widgets,
+	widgets = append(widgets,
+		func(gtx layout.Context) layout.Dimensions {
+			return p.layoutStatus(gtx, th, server)
+		},
 		func(gtx layout.Context) layout.Dimensions {
-			message := "To use an RPC bridge, you will need IP:Port\n"
-			message += "\nOn Mobile, you will have to find your IP address manually.\n"
-			message += "\nLook under Settings >> Network/Internet >> Wifi >> Find your local IP address:\n ex. 192.168.12.109\n"
-			message += "\nPort is set to be 10107 and so with the RPC bridge, you would use IP:Port like this:\n192.168.12.109:10107\n"
-			message += "\nSet RPC Username Password\n"
-			if utils.IsMobile() == false {
-				address, _ := getLocalIP()
-				message = "This Wallet's default IP:Port for RPC:\n " + address + ":10107\n"
-				message += "\nPlease set RPC Username Password\n"
+			message := lang.Translate("The wallet RPC server lets other apps read your wallet and send transactions. It only listens on this device (127.0.0.1) unless you change the address.")
+			if p.rpcServer.localAddress != "" {
+				message += "\n\n" + fmt.Sprintf(lang.Translate("To reach it from another device on your network use %s. Turn on TLS if you do."), p.rpcServer.localAddress+":10107")
 			}
-			lbl := material.Label(th, unit.Sp(16), lang.Translate(message))
+
+			lbl := material.Label(th, unit.Sp(16), message)
 			return lbl.Layout(gtx)
 		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.rpcServer.txtBind.Layout(gtx, th, lang.Translate("Address"), "127.0.0.1:10107")
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return layoutSwitchRow(gtx, th, p.rpcServer.tls, lang.Translate("Use TLS"),
+				lang.Translate("Serve https with a self-signed certificate generated in the app directory."))
+		},
 		func(gtx layout.Context) layout.Dimensions {
 			return p.rpcServer.txtUser.Layout(gtx, th, lang.Translate("Username"), "RPC username")
 		},
@@ -186,14 +376,34 @@ func (p *PageRpc) Layout(gtx layout.Context, th *material.Theme) layout.Dimensio
 			return p.rpcServer.txtPass.Layout(gtx, th, lang.Translate("Password"), "RPC password")
 		},
 		func(gtx layout.Context) layout.Dimensions {
+			return layoutSwitchRow(gtx, th, p.rpcServer.remember, lang.Translate("Remember credentials"),
+				lang.Translate("Saved encrypted in this wallet and only readable once it's opened."))
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return layoutSwitchRow(gtx, th, p.rpcServer.autoStart, lang.Translate("Start with the wallet"),
+				lang.Translate("Turn the RPC server on every time this wallet is opened."))
+		},
+	)
+
+	if server == nil {
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
 			p.rpcServer.buttonOn.Text = lang.Translate("Turn RPC on")
 			p.rpcServer.buttonOn.Style.Colors = theme.Current.ButtonPrimaryColors
 			return p.rpcServer.buttonOn.Layout(gtx, th)
-		}, func(gtx layout.Context) layout.Dimensions {
+		})
+	} else {
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
 			p.rpcServer.buttonOff.Text = lang.Translate("Turn RPC off")
 			p.rpcServer.buttonOff.Style.Colors = theme.Current.ButtonPrimaryColors
 			return p.rpcServer.buttonOff.Layout(gtx, th)
 		})
+	}
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		p.rpcServer.buttonPolicy.Text = lang.Translate("Approvals and limits")
+		p.rpcServer.buttonPolicy.Style.Colors = theme.Current.ButtonSecondaryColors
+		return p.rpcServer.buttonPolicy.Layout(gtx, th)
+	})
 
 	listStyle := material.List(th, p.list)
 	listStyle.AnchorStrategy = material.Overlay
@@ -214,78 +424,119 @@ func (p *PageRpc) Layout(gtx layout.Context, th *material.Theme) layout.Dimensio
 	})
 }
 
-func (p *PageRpc) turnOn(gtx layout.Context) {
-	var err error
+func (p *PageRpc) saveSettings(wallet *wallet_manager.Wallet, credentials wallet_manager.RPCCredentials) error {
+	settings.App.RPCBind = p.rpcServer.txtBind.Value()
+	settings.App.RPCTLS = p.rpcServer.tls.Value
+	err := settings.Save()
+	if err != nil {
+		return err
+	}
+
+	if p.rpcServer.remember.Value {
+		return wallet.StoreRPCCredentials(credentials)
+	}
+
+	return wallet.DelRPCCredentials()
+}
 
-	go func() {
+func (p *PageRpc) turnOn() {
+	p.rpcServer.buttonOn.SetLoading(true)
 
+	go func() {
 		setError := func(err error) {
 			p.rpcServer.buttonOn.SetLoading(false)
 			notification_modals.ErrorInstance.SetText("Error", err.Error())
 			notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
 		}
 
-		if wallet_manager.OpenedWallet == nil {
+		wallet := wallet_manager.OpenedWallet
+		if wallet == nil {
 			setError(fmt.Errorf("No opened wallet"))
 			return
 		}
 
-		txtUser := p.rpcServer.txtUser.Editor()
-		txtPass := p.rpcServer.txtPass.Editor()
+		// the rpc server signs transfers and decrypts the balance
+		if wallet.IsWatchOnly() {
+			setError(wallet_manager.ErrWatchOnly)
+			return
+		}
+
+		_, _, err := net.SplitHostPort(p.rpcServer.txtBind.Value())
+		if err != nil {
+			setError(fmt.Errorf("invalid address: %s", err))
+			return
+		}
+
+		credentials := wallet_manager.RPCCredentials{
+			User:      p.rpcServer.txtUser.Value(),
+			Password:  p.rpcServer.txtPass.Value(),
+			AutoStart: p.rpcServer.autoStart.Value,
+		}
 
-		if txtUser.Text() == "" {
+		if credentials.User == "" {
 			setError(fmt.Errorf("enter user"))
 			return
 		}
 
-		if txtPass.Text() == "" {
+		if credentials.Password == "" {
 			setError(fmt.Errorf("enter pass"))
 			return
 		}
-		globals.Arguments["--rpc-server"] = true
-		globals.Arguments["--rpc-login"] = p.rpcServer.txtUser.Value() + ":" + p.rpcServer.txtPass.Value()
-		globals.Arguments["--rpc-bind"] = "0.0.0.0:10107"
 
-		p.rpcServer.server, err = rpcserver.RPCServer_Start(wallet_manager.OpenedWallet.Memory, "secret-wallet")
-		wallet_manager.OpenedWallet.Server = p.rpcServer.server
+		err = p.saveSettings(wallet, credentials)
 		if err != nil {
-			p.rpcServer.server = nil
+			setError(err)
+			return
 		}
-		//todo
-		//please do a quick check to see if you are connected :(
-		// _, err := rpcserver.GetAddress()
-		// if err != nil {
-		// 	setError(err)
-		// 	return
-		// }
+
+		config, err := wallet_manager.NewRPCServerConfig(credentials)
+		if err != nil {
+			setError(err)
+			return
+		}
+
+		server, err := wallet.StartRPCServer(config)
+		if err != nil {
+			setError(err)
+			return
+		}
+
+		wallet.Server = server
 
 		p.rpcServer.buttonOn.SetLoading(false)
-		notification_modals.SuccessInstance.SetText(lang.Translate("Success"), "RPC turned on")
+		notification_modals.SuccessInstance.SetText(lang.Translate("Success"), fmt.Sprintf(lang.Translate("RPC listening on %s"), server.Addr()))
 		notification_modals.SuccessInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
-		page_instance.header.GoBack()
+		app_instance.Window.Invalidate()
 	}()
 }
 
-func (p *PageRpc) turnOff(gtx layout.Context) {
+func (p *PageRpc) turnOff() {
 	setError := func(err error) {
 		p.rpcServer.buttonOff.SetLoading(false)
 		notification_modals.ErrorInstance.SetText("Error", err.Error())
 		notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
 	}
 
-	if wallet_manager.OpenedWallet == nil {
-		setError(fmt.Errorf("No opened wallet"))
+	wallet := wallet_manager.OpenedWallet
+	if wallet == nil || wallet.Server == nil {
+		setError(fmt.Errorf("RPC server is not running"))
 		return
 	}
+
 	p.rpcServer.buttonOff.SetLoading(true)
 	go func() {
-		globals.Arguments["--rpc-server"] = false
-		p.rpcServer.server.RPCServer_Stop()
+		server := wallet.Server
+		wallet.Server = nil
+		err := server.Stop()
+		if err != nil {
+			setError(err)
+			return
+		}
 
 		p.rpcServer.buttonOff.SetLoading(false)
-		notification_modals.SuccessInstance.SetText(lang.Translate("Success"), "RPC turned off")
+		notification_modals.SuccessInstance.SetText(lang.Translate("Success"), lang.Translate("RPC turned off"))
 		notification_modals.SuccessInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
-		page_instance.header.GoBack()
+		app_instance.Window.Invalidate()
 	}()
 }
 
@@ -303,10 +554,8 @@ func getLocalIP() (string, error) {
 
 		for _, addr := range addrs {
 			ipNet, ok := addr.(*net.IPNet)
-			if ok && !ipNet.IP.IsLoopback() && ipNet.IP.To4() != nil {
-				if ipNet.IP.String()[:3] == "192" && ipNet.IP.String()[4:7] == "168" {
-					return ipNet.IP.String(), nil
-				}
+			if ok && ipNet.IP.IsPrivate() && ipNet.IP.To4() != nil {
+				return ipNet.IP.String(), nil
 			}
 		}
 	}
@@ -0,0 +1,768 @@
+package page_settings
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"strings"
+	"sync"
+	"time"
+
+	"gioui.org/font"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/deroproject/derohe/globals"
+	"github.com/deroproject/derohe/rpc"
+	"github.com/secretsystems/secret-wallet/animation"
+	"github.com/secretsystems/secret-wallet/app_db"
+	"github.com/secretsystems/secret-wallet/app_instance"
+	"github.com/secretsystems/secret-wallet/components"
+	"github.com/secretsystems/secret-wallet/containers/confirm_modal"
+	"github.com/secretsystems/secret-wallet/containers/listselect_modal"
+	"github.com/secretsystems/secret-wallet/containers/notification_modals"
+	"github.com/secretsystems/secret-wallet/lang"
+	"github.com/secretsystems/secret-wallet/prefabs"
+	"github.com/secretsystems/secret-wallet/router"
+	"github.com/secretsystems/secret-wallet/theme"
+	"github.com/secretsystems/secret-wallet/utils"
+	"github.com/secretsystems/secret-wallet/wallet_manager"
+	"github.com/tanema/gween"
+	"github.com/tanema/gween/ease"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+// log entries shown in the page
+const RPC_LOG_SHOWN = 100
+
+type PageRpcPolicy struct {
+	isActive bool
+
+	animationEnter *animation.Animation
+	animationLeave *animation.Animation
+
+	txtClient      *prefabs.TextField
+	txtMaxTransfer *prefabs.TextField
+	txtMaxDaily    *prefabs.TextField
+	buttonSetLimit *components.Button
+
+	txtAddress       *prefabs.TextField
+	txtAddressName   *prefabs.TextField
+	buttonAddAddress *components.Button
+
+	buttonClearLog *components.Button
+
+	itemsLock   sync.Mutex
+	limitItems  []*RPCPolicyItem
+	allowItems  []*RPCPolicyItem
+	xswdClients []string
+	logEntries  []wallet_manager.RPCLogEntry
+	loadedAt    time.Time
+
+	list *widget.List
+}
+
+var _ router.Page = &PageRpcPolicy{}
+
+func newRPCPolicyButton(icon *widget.Icon) *components.Button {
+	button := components.NewButton(components.ButtonStyle{
+		Rounded:   components.UniformRounded(unit.Dp(5)),
+		Icon:      icon,
+		TextSize:  unit.Sp(14),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+	})
+	button.Label.Alignment = text.Middle
+	button.Style.Font.Weight = font.Bold
+	return button
+}
+
+func NewPageRpcPolicy() *PageRpcPolicy {
+	animationEnter := animation.NewAnimation(false, gween.NewSequence(
+		gween.New(1, 0, .25, ease.Linear),
+	))
+
+	animationLeave := animation.NewAnimation(false, gween.NewSequence(
+		gween.New(0, 1, .25, ease.Linear),
+	))
+
+	saveIcon, _ := widget.NewIcon(icons.ContentSave)
+	addIcon, _ := widget.NewIcon(icons.ContentAdd)
+	clearIcon, _ := widget.NewIcon(icons.ActionDelete)
+
+	list := new(widget.List)
+	list.Axis = layout.Vertical
+
+	return &PageRpcPolicy{
+		animationEnter: animationEnter,
+		animationLeave: animationLeave,
+
+		txtClient:      prefabs.NewTextField(),
+		txtMaxTransfer: prefabs.NewNumberTextField(),
+		txtMaxDaily:    prefabs.NewNumberTextField(),
+		buttonSetLimit: newRPCPolicyButton(saveIcon),
+
+		txtAddress:       prefabs.NewTextField(),
+		txtAddressName:   prefabs.NewTextField(),
+		buttonAddAddress: newRPCPolicyButton(addIcon),
+
+		buttonClearLog: newRPCPolicyButton(clearIcon),
+
+		list: list,
+	}
+}
+
+func (p *PageRpcPolicy) IsActive() bool {
+	return p.isActive
+}
+
+func (p *PageRpcPolicy) Enter() {
+	p.isActive = true
+	page_instance.header.Title = func() string { return lang.Translate("RPC Approvals") }
+
+	if !page_instance.header.IsHistory(PAGE_RPC_POLICY) {
+		p.animationEnter.Start()
+		p.animationLeave.Reset()
+	}
+
+	go p.load()
+}
+
+func (p *PageRpcPolicy) Leave() {
+	p.animationEnter.Reset()
+	p.animationLeave.Start()
+}
+
+func formatRPCLimit(amount uint64) string {
+	if amount == 0 {
+		return lang.Translate("No limit")
+	}
+
+	return fmt.Sprintf("%s DERO", globals.FormatMoney(amount))
+}
+
+func (p *PageRpcPolicy) load() error {
+	wallet := wallet_manager.OpenedWallet
+	if wallet == nil {
+		p.itemsLock.Lock()
+		p.limitItems = nil
+		p.allowItems = nil
+		p.xswdClients = nil
+		p.logEntries = nil
+		p.itemsLock.Unlock()
+		return nil
+	}
+
+	limits, err := wallet.GetRPCLimits()
+	if err != nil {
+		return err
+	}
+
+	allowlist, err := wallet.GetRPCAllowlist()
+	if err != nil {
+		return err
+	}
+
+	logEntries, err := wallet.GetRPCLog(RPC_LOG_SHOWN)
+	if err != nil {
+		return err
+	}
+
+	apps, err := app_db.GetXSWDApps()
+	if err != nil {
+		return err
+	}
+
+	// the dApps are limited by the client of their page - not by the name they give
+	appNames := make(map[string][]string)
+	var xswdClients []string
+	for _, app := range apps {
+		client := wallet_manager.XSWDPolicyClient(app)
+		if len(appNames[client]) == 0 {
+			xswdClients = append(xswdClients, client)
+		}
+
+		appNames[client] = append(appNames[client], app.Name)
+	}
+
+	for i, client := range xswdClients {
+		xswdClients[i] = fmt.Sprintf("%s · %s", client, strings.Join(appNames[client], ", "))
+	}
+
+	var limitItems []*RPCPolicyItem
+	for _, limit := range limits {
+		client := limit.Client
+		if client == wallet_manager.RPC_ANY_CLIENT {
+			client = lang.Translate("Every client")
+		}
+
+		if names, ok := appNames[limit.Client]; ok {
+			client = fmt.Sprintf("%s (%s)", client, strings.Join(names, ", "))
+		}
+
+		limitItems = append(limitItems, NewRPCPolicyItem(limit.Client, client,
+			fmt.Sprintf(lang.Translate("Per transfer: %s · Per day: %s"), formatRPCLimit(limit.MaxTransfer), formatRPCLimit(limit.MaxDaily)),
+		))
+	}
+
+	var allowItems []*RPCPolicyItem
+	for _, allowed := range allowlist {
+		title := allowed.Name
+		if title == "" {
+			title = utils.ReduceAddr(allowed.Address)
+		}
+
+		allowItems = append(allowItems, NewRPCPolicyItem(allowed.Address, title, allowed.Address))
+	}
+
+	p.itemsLock.Lock()
+	p.limitItems = limitItems
+	p.allowItems = allowItems
+	p.xswdClients = xswdClients
+	p.logEntries = logEntries
+	p.loadedAt = time.Now()
+	p.itemsLock.Unlock()
+
+	app_instance.Window.Invalidate()
+	return nil
+}
+
+func (p *PageRpcPolicy) setLimit() error {
+	wallet := wallet_manager.OpenedWallet
+	if wallet == nil {
+		return fmt.Errorf("no opened wallet")
+	}
+
+	limit := wallet_manager.RPCLimit{Client: strings.TrimSpace(p.txtClient.Value())}
+	for _, field := range []struct {
+		txt   *prefabs.TextField
+		value *uint64
+	}{
+		{p.txtMaxTransfer, &limit.MaxTransfer},
+		{p.txtMaxDaily, &limit.MaxDaily},
+	} {
+		if field.txt.Value() == "" {
+			continue
+		}
+
+		amount := utils.ShiftNumber{Decimals: 5}
+		err := amount.Parse(field.txt.Value())
+		if err != nil {
+			return err
+		}
+
+		*field.value = amount.Number
+	}
+
+	err := wallet.SetRPCLimit(limit)
+	if err != nil {
+		return err
+	}
+
+	p.txtClient.SetValue("")
+	p.txtMaxTransfer.SetValue("")
+	p.txtMaxDaily.SetValue("")
+	return p.load()
+}
+
+func (p *PageRpcPolicy) addAddress() error {
+	wallet := wallet_manager.OpenedWallet
+	if wallet == nil {
+		return fmt.Errorf("no opened wallet")
+	}
+
+	err := wallet.AddRPCAllowedAddress(strings.TrimSpace(p.txtAddress.Value()), p.txtAddressName.Value())
+	if err != nil {
+		return err
+	}
+
+	p.txtAddress.SetValue("")
+	p.txtAddressName.SetValue("")
+	return p.load()
+}
+
+func (p *PageRpcPolicy) openItemMenu(item *RPCPolicyItem, remove func(key string) error) {
+	deleteIcon, _ := widget.NewIcon(icons.ActionDelete)
+
+	items := []*listselect_modal.SelectListItem{
+		listselect_modal.NewSelectListItem("remove",
+			listselect_modal.NewItemText(deleteIcon, lang.Translate("Remove")).Layout,
+		),
+	}
+
+	keyChan := listselect_modal.Instance.Open(items)
+	for key := range keyChan {
+		if key != "remove" {
+			continue
+		}
+
+		err := remove(item.key)
+		if err == nil {
+			err = p.load()
+		}
+
+		if err != nil {
+			notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+			notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		}
+
+		app_instance.Window.Invalidate()
+	}
+}
+
+func (p *PageRpcPolicy) clearLog() {
+	yesChan := confirm_modal.Instance.Open(confirm_modal.ConfirmText{
+		Prompt: lang.Translate("Clear the request log? The daily limits are counted from it."),
+	})
+
+	for yes := range yesChan {
+		if !yes {
+			continue
+		}
+
+		wallet := wallet_manager.OpenedWallet
+		err := wallet.ClearRPCLog()
+		if err == nil {
+			err = p.load()
+		}
+
+		if err != nil {
+			notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+			notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		}
+
+		app_instance.Window.Invalidate()
+	}
+}
+
+func layoutSectionTitle(gtx layout.Context, th *material.Theme, title string, description string) layout.Dimensions {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(18), title)
+			lbl.Font.Weight = font.Bold
+			return lbl.Layout(gtx)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(5)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(14), description)
+			lbl.Color = theme.Current.TextMuteColor
+			return lbl.Layout(gtx)
+		}),
+	)
+}
+
+func layoutRPCLogEntry(gtx layout.Context, th *material.Theme, entry wallet_manager.RPCLogEntry) layout.Dimensions {
+	status := lang.Translate("Approved")
+	if !entry.Approved {
+		status = lang.Translate("Denied")
+	}
+
+	title := fmt.Sprintf("%s  %s  %s", time.UnixMilli(entry.Timestamp).Format("2006-01-02 15:04:05"), entry.Method, status)
+
+	var details []string
+	if entry.Client != "" {
+		details = append(details, entry.Client)
+	}
+
+	if entry.Amount > 0 {
+		details = append(details, fmt.Sprintf("%s DERO", globals.FormatMoney(entry.Amount)))
+	}
+
+	if entry.Destinations != "" {
+		var destinations []string
+		for _, destination := range strings.Split(entry.Destinations, ",") {
+			destinations = append(destinations, utils.ReduceAddr(destination))
+		}
+
+		details = append(details, strings.Join(destinations, ", "))
+	}
+
+	if entry.TxId != "" {
+		details = append(details, utils.ReduceTxId(entry.TxId))
+	}
+
+	if entry.Reason != "" {
+		details = append(details, entry.Reason)
+	}
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(14), title)
+			lbl.Font.Weight = font.Bold
+			return lbl.Layout(gtx)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(14), strings.Join(details, " · "))
+			lbl.Color = theme.Current.TextMuteColor
+			return lbl.Layout(gtx)
+		}),
+	)
+}
+
+func (p *PageRpcPolicy) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	{
+		state := p.animationEnter.Update(gtx)
+		if state.Active {
+			defer animation.TransformX(gtx, state.Value).Push(gtx.Ops).Pop()
+		}
+	}
+
+	{
+		state := p.animationLeave.Update(gtx)
+		if state.Finished {
+			p.isActive = false
+			op.InvalidateOp{}.Add(gtx.Ops)
+		}
+
+		if state.Active {
+			defer animation.TransformX(gtx, state.Value).Push(gtx.Ops).Pop()
+		}
+	}
+
+	setError := func(err error) {
+		if err != nil {
+			notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+			notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		}
+	}
+
+	if p.buttonSetLimit.Clicked() {
+		setError(p.setLimit())
+	}
+
+	if p.buttonAddAddress.Clicked() {
+		setError(p.addAddress())
+	}
+
+	if p.buttonClearLog.Clicked() {
+		go p.clearLog()
+	}
+
+	wallet := wallet_manager.OpenedWallet
+
+	p.itemsLock.Lock()
+	limitItems := p.limitItems
+	allowItems := p.allowItems
+	xswdClients := p.xswdClients
+	logEntries := p.logEntries
+	loadedAt := p.loadedAt
+	p.itemsLock.Unlock()
+
+	for _, item := range limitItems {
+		if item.clickable.Clicked() {
+			go p.openItemMenu(item, wallet.DelRPCLimit)
+		}
+	}
+
+	for _, item := range allowItems {
+		if item.clickable.Clicked() {
+			go p.openItemMenu(item, wallet.DelRPCAllowedAddress)
+		}
+	}
+
+	// requests are logged by the server in the background
+	if wallet != nil && time.Since(loadedAt) > 5*time.Second {
+		p.itemsLock.Lock()
+		p.loadedAt = time.Now()
+		p.itemsLock.Unlock()
+		go p.load()
+	}
+	op.InvalidateOp{At: gtx.Now.Add(5 * time.Second)}.Add(gtx.Ops)
+
+	var widgets []layout.Widget
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		lbl := material.Label(th, unit.Sp(16), lang.Translate("Balance and history reads are answered right away. Transfers, contract calls and seed requests wait for your approval in the app and are denied after two minutes."))
+		return lbl.Layout(gtx)
+	})
+
+	if wallet == nil {
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(16), lang.Translate("Open a wallet to manage its approvals."))
+			lbl.Color = theme.Current.TextMuteColor
+			return lbl.Layout(gtx)
+		})
+	} else {
+		widgets = append(widgets,
+			func(gtx layout.Context) layout.Dimensions {
+				return layoutSectionTitle(gtx, th, lang.Translate("Spending limits"),
+					lang.Translate("DERO sent or burned by a client. Requests over a limit are denied without asking and so are its token transfers. Leave the client empty to set the limit of every client.")+"\n\n"+
+						lang.Translate("The RPC server has a single login so its clients are only told apart by their IP address: every app on this device is 127.0.0.1 and shares the same limit."))
+			},
+		)
+
+		for i := range limitItems {
+			item := limitItems[i]
+			widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+				return item.Layout(gtx, th)
+			})
+		}
+
+		if len(xswdClients) > 0 {
+			widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+				lbl := material.Label(th, unit.Sp(14), lang.Translate("dApps are limited by the page they run on, whatever name they give. Their clients are:")+"\n"+strings.Join(xswdClients, "\n"))
+				lbl.Color = theme.Current.TextMuteColor
+				return lbl.Layout(gtx)
+			})
+		}
+
+		widgets = append(widgets,
+			func(gtx layout.Context) layout.Dimensions {
+				return p.txtClient.Layout(gtx, th, lang.Translate("Client IP or dApp client"), "127.0.0.1")
+			},
+			func(gtx layout.Context) layout.Dimensions {
+				return p.txtMaxTransfer.Layout(gtx, th, lang.Translate("Max per transfer"), "0.00000")
+			},
+			func(gtx layout.Context) layout.Dimensions {
+				return p.txtMaxDaily.Layout(gtx, th, lang.Translate("Max per day"), "0.00000")
+			},
+			func(gtx layout.Context) layout.Dimensions {
+				p.buttonSetLimit.Text = lang.Translate("SET LIMIT")
+				p.buttonSetLimit.Style.Colors = theme.Current.ButtonPrimaryColors
+				return p.buttonSetLimit.Layout(gtx, th)
+			},
+			func(gtx layout.Context) layout.Dimensions {
+				return layoutSectionTitle(gtx, th, lang.Translate("Allowed destinations"),
+					lang.Translate("Once an address is added, transfers to any other address are denied without asking."))
+			},
+		)
+
+		for i := range allowItems {
+			item := allowItems[i]
+			widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+				return item.Layout(gtx, th)
+			})
+		}
+
+		widgets = append(widgets,
+			func(gtx layout.Context) layout.Dimensions {
+				return p.txtAddress.Layout(gtx, th, lang.Translate("Address"), "dero1...")
+			},
+			func(gtx layout.Context) layout.Dimensions {
+				return p.txtAddressName.Layout(gtx, th, lang.Translate("Name"), "")
+			},
+			func(gtx layout.Context) layout.Dimensions {
+				p.buttonAddAddress.Text = lang.Translate("ADD ADDRESS")
+				p.buttonAddAddress.Style.Colors = theme.Current.ButtonPrimaryColors
+				return p.buttonAddAddress.Layout(gtx, th)
+			},
+			func(gtx layout.Context) layout.Dimensions {
+				return layoutSectionTitle(gtx, th, lang.Translate("Request log"),
+					fmt.Sprintf(lang.Translate("The last %d requests, approved or denied. Reads are answered without asking."), RPC_LOG_SHOWN))
+			},
+		)
+
+		if len(logEntries) == 0 {
+			widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+				lbl := material.Label(th, unit.Sp(14), lang.Translate("No requests yet."))
+				lbl.Color = theme.Current.TextMuteColor
+				return lbl.Layout(gtx)
+			})
+		}
+
+		for i := range logEntries {
+			entry := logEntries[i]
+			widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+				return layoutRPCLogEntry(gtx, th, entry)
+			})
+		}
+
+		if len(logEntries) > 0 {
+			widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+				p.buttonClearLog.Text = lang.Translate("CLEAR LOG")
+				p.buttonClearLog.Style.Colors = theme.Current.ButtonDangerColors
+				return p.buttonClearLog.Layout(gtx, th)
+			})
+		}
+	}
+
+	listStyle := material.List(th, p.list)
+	listStyle.AnchorStrategy = material.Overlay
+
+	return listStyle.Layout(gtx, len(widgets), func(gtx layout.Context, index int) layout.Dimensions {
+		return layout.Inset{
+			Top: unit.Dp(0), Bottom: unit.Dp(20),
+			Left: unit.Dp(30), Right: unit.Dp(30),
+		}.Layout(gtx, widgets[index])
+	})
+}
+
+type RPCPolicyItem struct {
+	key       string
+	title     string
+	subtitle  string
+	clickable *widget.Clickable
+}
+
+func NewRPCPolicyItem(key string, title string, subtitle string) *RPCPolicyItem {
+	return &RPCPolicyItem{
+		key:       key,
+		title:     title,
+		subtitle:  subtitle,
+		clickable: new(widget.Clickable),
+	}
+}
+
+func (item *RPCPolicyItem) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	r := op.Record(gtx.Ops)
+	dims := item.clickable.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		r := op.Record(gtx.Ops)
+		dims := layout.UniformInset(unit.Dp(15)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					lbl := material.Label(th, unit.Sp(16), item.title)
+					lbl.Font.Weight = font.Bold
+					return lbl.Layout(gtx)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					lbl := material.Label(th, unit.Sp(14), item.subtitle)
+					lbl.Color = theme.Current.TextMuteColor
+					return lbl.Layout(gtx)
+				}),
+			)
+		})
+		c := r.Stop()
+
+		if item.clickable.Hovered() {
+			pointer.CursorPointer.Add(gtx.Ops)
+			paint.FillShape(gtx.Ops, theme.Current.ListItemHoverBgColor,
+				clip.UniformRRect(
+					image.Rectangle{Max: image.Pt(dims.Size.X, dims.Size.Y)},
+					gtx.Dp(10),
+				).Op(gtx.Ops),
+			)
+		}
+
+		c.Add(gtx.Ops)
+		return dims
+	})
+	c := r.Stop()
+
+	paint.FillShape(gtx.Ops, theme.Current.ListBgColor,
+		clip.UniformRRect(
+			image.Rectangle{Max: dims.Size},
+			gtx.Dp(10),
+		).Op(gtx.Ops))
+
+	c.Add(gtx.Ops)
+	return dims
+}
+
+// sc args added by the wallet itself are not shown
+var rpcInternalArgs = map[string]bool{
+	rpc.SCACTION: true,
+	rpc.SCID:     true,
+	rpc.SCCODE:   true,
+}
+
+// readable lines of what the request will do - the addresses and values are never shortened because
+// this is what the user checks before approving, a destination that is allowlisted also shows its name
+func rpcRequestDetail(request wallet_manager.RPCRequest, allowedNames map[string]string) string {
+	var lines []string
+	for _, transfer := range request.Transfers {
+		asset := "DERO"
+		amount := globals.FormatMoney(transfer.Amount)
+		burn := globals.FormatMoney(transfer.Burn)
+		if !transfer.SCID.IsZero() {
+			asset = transfer.SCID.String()
+			amount = fmt.Sprint(transfer.Amount)
+			burn = fmt.Sprint(transfer.Burn)
+		}
+
+		if transfer.Amount > 0 {
+			destination := transfer.Destination
+			name := allowedNames[destination]
+			if name != "" {
+				destination = fmt.Sprintf("%s (%s)", name, destination)
+			}
+
+			lines = append(lines, fmt.Sprintf(lang.Translate("Send %s %s to %s"), amount, asset, destination))
+		}
+
+		if transfer.Burn > 0 {
+			lines = append(lines, fmt.Sprintf(lang.Translate("Burn %s %s"), burn, asset))
+		}
+	}
+
+	if request.Install {
+		lines = append(lines, lang.Translate("Install a new smart contract"))
+	}
+
+	if request.SCID != "" {
+		lines = append(lines, fmt.Sprintf(lang.Translate("Contract %s"), request.SCID))
+	}
+
+	for _, arg := range request.SCArgs {
+		if rpcInternalArgs[arg.Name] {
+			continue
+		}
+
+		lines = append(lines, fmt.Sprintf("%s = %v", arg.Name, arg.Value))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// names of the allowlisted addresses - a missing list only loses the names
+func rpcAllowedNames() map[string]string {
+	names := make(map[string]string)
+	wallet := wallet_manager.OpenedWallet
+	if wallet == nil {
+		return names
+	}
+
+	allowlist, err := wallet.GetRPCAllowlist()
+	if err != nil {
+		return names
+	}
+
+	for _, allowed := range allowlist {
+		names[allowed.Address] = allowed.Name
+	}
+
+	return names
+}
+
+// set as wallet_manager.OnRPCApproval - the modal is closed if the request times out
+func approveRPCRequest(ctx context.Context, request wallet_manager.RPCRequest) bool {
+	client := request.Client
+	if client == "" {
+		client = lang.Translate("An app")
+	}
+
+	var prompt string
+	switch request.Method {
+	case "Transfer":
+		prompt = fmt.Sprintf(lang.Translate("%s wants to send a transaction."), client)
+	case "SC_Invoke":
+		prompt = fmt.Sprintf(lang.Translate("%s wants to call a smart contract."), client)
+	case "QueryKey":
+		prompt = fmt.Sprintf(lang.Translate("%s asks for your seed words."), client)
+	default:
+		prompt = fmt.Sprintf(lang.Translate("%s wants to use %s."), client, request.Method)
+	}
+
+	yesChan := confirm_modal.Instance.Open(confirm_modal.ConfirmText{
+		Prompt: prompt,
+		Detail: rpcRequestDetail(request, rpcAllowedNames()),
+		Yes:    lang.Translate("Approve"),
+		No:     lang.Translate("Deny"),
+	})
+	app_instance.Window.Invalidate()
+
+	select {
+	case yes := <-yesChan:
+		return yes
+	case <-ctx.Done():
+		confirm_modal.Instance.Modal.SetVisible(false)
+		app_instance.Window.Invalidate()
+		return false
+	}
+}
@@ -0,0 +1,391 @@
+package page_settings
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/secretsystems/secret-wallet/animation"
+	"github.com/secretsystems/secret-wallet/app_db"
+	"github.com/secretsystems/secret-wallet/app_instance"
+	"github.com/secretsystems/secret-wallet/containers/confirm_modal"
+	"github.com/secretsystems/secret-wallet/containers/listselect_modal"
+	"github.com/secretsystems/secret-wallet/containers/notification_modals"
+	"github.com/secretsystems/secret-wallet/lang"
+	"github.com/secretsystems/secret-wallet/router"
+	"github.com/secretsystems/secret-wallet/settings"
+	"github.com/secretsystems/secret-wallet/theme"
+	"github.com/secretsystems/secret-wallet/wallet_manager"
+	"github.com/tanema/gween"
+	"github.com/tanema/gween/ease"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+type PageXSWD struct {
+	isActive bool
+
+	animationEnter *animation.Animation
+	animationLeave *animation.Animation
+
+	enabled *widget.Bool
+
+	itemsLock sync.Mutex
+	apps      map[string]app_db.XSWDApp
+	appItems  []*RPCPolicyItem
+	loadedAt  time.Time
+
+	list *widget.List
+}
+
+var _ router.Page = &PageXSWD{}
+
+func NewPageXSWD() *PageXSWD {
+	animationEnter := animation.NewAnimation(false, gween.NewSequence(
+		gween.New(1, 0, .25, ease.Linear),
+	))
+
+	animationLeave := animation.NewAnimation(false, gween.NewSequence(
+		gween.New(0, 1, .25, ease.Linear),
+	))
+
+	list := new(widget.List)
+	list.Axis = layout.Vertical
+
+	wallet_manager.OnXSWDRegistration = approveXSWDApp
+	wallet_manager.OnXSWDApproval = func(ctx context.Context, app app_db.XSWDApp, request wallet_manager.RPCRequest) bool {
+		return approveRPCRequest(ctx, request)
+	}
+
+	return &PageXSWD{
+		animationEnter: animationEnter,
+		animationLeave: animationLeave,
+		enabled:        &widget.Bool{Value: settings.App.XSWD},
+		list:           list,
+	}
+}
+
+func (p *PageXSWD) IsActive() bool {
+	return p.isActive
+}
+
+func (p *PageXSWD) Enter() {
+	p.isActive = true
+	page_instance.header.Title = func() string { return lang.Translate("dApp Connections") }
+
+	if !page_instance.header.IsHistory(PAGE_XSWD) {
+		p.animationEnter.Start()
+		p.animationLeave.Reset()
+	}
+
+	go p.load()
+}
+
+func (p *PageXSWD) Leave() {
+	p.animationEnter.Reset()
+	p.animationLeave.Start()
+}
+
+// connected apps first then the remembered ones by last seen
+func (p *PageXSWD) load() error {
+	apps, err := app_db.GetXSWDApps()
+	if err != nil {
+		return err
+	}
+
+	var server *wallet_manager.XSWDServer
+	wallet := wallet_manager.OpenedWallet
+	if wallet != nil {
+		server = wallet.XSWD
+	}
+
+	connected := make(map[string]bool)
+	if server != nil {
+		for _, connection := range server.Connections() {
+			connected[connection.App.ID] = true
+		}
+	}
+
+	sort.SliceStable(apps, func(i, j int) bool {
+		return connected[apps[i].ID] && !connected[apps[j].ID]
+	})
+
+	appMap := make(map[string]app_db.XSWDApp)
+	var appItems []*RPCPolicyItem
+	for _, app := range apps {
+		appMap[app.ID] = app
+
+		status := fmt.Sprintf(lang.Translate("Last seen %s"), time.Unix(app.LastSeenTimestamp, 0).Format("2006-01-02 15:04"))
+		if connected[app.ID] {
+			status = lang.Translate("Connected")
+		}
+
+		subtitle := status
+		if app.Url != "" {
+			subtitle = fmt.Sprintf("%s · %s", status, app.Url)
+		}
+
+		appItems = append(appItems, NewRPCPolicyItem(app.ID, app.Name, subtitle))
+	}
+
+	p.itemsLock.Lock()
+	p.apps = appMap
+	p.appItems = appItems
+	p.loadedAt = time.Now()
+	p.itemsLock.Unlock()
+
+	app_instance.Window.Invalidate()
+	return nil
+}
+
+func (p *PageXSWD) setEnabled(enabled bool) error {
+	settings.App.XSWD = enabled
+	err := settings.Save()
+	if err != nil {
+		return err
+	}
+
+	wallet := wallet_manager.OpenedWallet
+	if wallet == nil {
+		return nil
+	}
+
+	if !enabled {
+		server := wallet.XSWD
+		wallet.XSWD = nil
+		if server != nil {
+			return server.Stop()
+		}
+
+		return nil
+	}
+
+	return wallet.AutoStartXSWDServer()
+}
+
+// revoked apps are disconnected and have to register again
+func revokeXSWDApp(app app_db.XSWDApp) error {
+	yesChan := confirm_modal.Instance.Open(confirm_modal.ConfirmText{
+		Prompt: fmt.Sprintf(lang.Translate("Revoke %s? It will be disconnected and its permissions forgotten."), app.Name),
+	})
+
+	for yes := range yesChan {
+		if !yes {
+			return nil
+		}
+
+		wallet := wallet_manager.OpenedWallet
+		if wallet != nil && wallet.XSWD != nil {
+			return wallet.XSWD.Revoke(app.ID)
+		}
+
+		return app_db.DelXSWDApp(app.ID)
+	}
+
+	return nil
+}
+
+func (p *PageXSWD) openAppMenu(app app_db.XSWDApp) {
+	permissionsIcon, _ := widget.NewIcon(icons.ActionLock)
+	deleteIcon, _ := widget.NewIcon(icons.ActionDelete)
+
+	items := []*listselect_modal.SelectListItem{
+		listselect_modal.NewSelectListItem("permissions",
+			listselect_modal.NewItemText(permissionsIcon, lang.Translate("Permissions")).Layout,
+		),
+		listselect_modal.NewSelectListItem("revoke",
+			listselect_modal.NewItemText(deleteIcon, lang.Translate("Revoke")).Layout,
+		),
+	}
+
+	keyChan := listselect_modal.Instance.Open(items)
+	for key := range keyChan {
+		switch key {
+		case "permissions":
+			page_instance.pageXSWDApp.SetApp(app)
+			page_instance.pageRouter.SetCurrent(PAGE_XSWD_APP)
+			page_instance.header.AddHistory(PAGE_XSWD_APP)
+		case "revoke":
+			err := revokeXSWDApp(app)
+			if err == nil {
+				err = p.load()
+			}
+
+			if err != nil {
+				notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+				notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+			}
+		}
+
+		app_instance.Window.Invalidate()
+	}
+}
+
+func (p *PageXSWD) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	{
+		state := p.animationEnter.Update(gtx)
+		if state.Active {
+			defer animation.TransformX(gtx, state.Value).Push(gtx.Ops).Pop()
+		}
+	}
+
+	{
+		state := p.animationLeave.Update(gtx)
+		if state.Finished {
+			p.isActive = false
+			op.InvalidateOp{}.Add(gtx.Ops)
+		}
+
+		if state.Active {
+			defer animation.TransformX(gtx, state.Value).Push(gtx.Ops).Pop()
+		}
+	}
+
+	if p.enabled.Changed() {
+		err := p.setEnabled(p.enabled.Value)
+		if err != nil {
+			notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+			notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		}
+
+		go p.load()
+	}
+
+	p.itemsLock.Lock()
+	apps := p.apps
+	appItems := p.appItems
+	loadedAt := p.loadedAt
+	p.itemsLock.Unlock()
+
+	for _, item := range appItems {
+		if item.clickable.Clicked() {
+			go p.openAppMenu(apps[item.key])
+		}
+	}
+
+	// apps connect and leave in the background
+	if time.Since(loadedAt) > 2*time.Second {
+		p.itemsLock.Lock()
+		p.loadedAt = time.Now()
+		p.itemsLock.Unlock()
+		go p.load()
+	}
+	op.InvalidateOp{At: gtx.Now.Add(2 * time.Second)}.Add(gtx.Ops)
+
+	var server *wallet_manager.XSWDServer
+	wallet := wallet_manager.OpenedWallet
+	if wallet != nil {
+		server = wallet.XSWD
+	}
+
+	status := lang.Translate("Stopped")
+	switch {
+	case server != nil:
+		status = fmt.Sprintf(lang.Translate("Listening on ws://%s%s"), server.Addr(), wallet_manager.XSWD_PATH)
+	case p.enabled.Value && wallet == nil:
+		status = lang.Translate("Starts when a wallet is opened")
+	case p.enabled.Value && wallet.IsWatchOnly():
+		status = wallet_manager.ErrWatchOnly.Error()
+	}
+
+	var widgets []layout.Widget
+
+	widgets = append(widgets,
+		func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(16), lang.Translate("XSWD lets dApps in your browser or on your computer connect to the opened wallet. Every app asks to be accepted first and its transfers still go through the RPC limits and allowlist."))
+			return lbl.Layout(gtx)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return layoutSwitchRow(gtx, th, p.enabled, lang.Translate("Enable XSWD"), status)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return layoutSectionTitle(gtx, th, lang.Translate("Apps"),
+				lang.Translate("Accepted apps reconnect without asking. Revoke an app to disconnect it and forget its permissions."))
+		},
+	)
+
+	if len(appItems) == 0 {
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(14), lang.Translate("No apps yet."))
+			lbl.Color = theme.Current.TextMuteColor
+			return lbl.Layout(gtx)
+		})
+	}
+
+	for i := range appItems {
+		item := appItems[i]
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			return item.Layout(gtx, th)
+		})
+	}
+
+	listStyle := material.List(th, p.list)
+	listStyle.AnchorStrategy = material.Overlay
+
+	return listStyle.Layout(gtx, len(widgets), func(gtx layout.Context, index int) layout.Dimensions {
+		return layout.Inset{
+			Top: unit.Dp(0), Bottom: unit.Dp(20),
+			Left: unit.Dp(30), Right: unit.Dp(30),
+		}.Layout(gtx, widgets[index])
+	})
+}
+
+func xswdPermissionText(permission app_db.XSWDPermission) string {
+	switch permission {
+	case app_db.XSWD_PERMISSION_ALWAYS:
+		return lang.Translate("Always allow")
+	case app_db.XSWD_PERMISSION_DENY:
+		return lang.Translate("Deny")
+	}
+
+	return lang.Translate("Ask every time")
+}
+
+// set as wallet_manager.OnXSWDRegistration - the modal is closed if the app is not answered in time
+func approveXSWDApp(ctx context.Context, app app_db.XSWDApp) bool {
+	lines := []string{app.Description}
+	if app.Url != "" {
+		lines = append(lines, app.Url)
+	}
+
+	// the name and url are chosen by the app - the origin is the page it really runs in
+	if app.Origin != "" {
+		lines = append(lines, fmt.Sprintf(lang.Translate("Connecting from %s"), app.Origin))
+	} else {
+		lines = append(lines, lang.Translate("Not connecting from a web page"))
+	}
+
+	var methods []string
+	for method := range app.Permissions {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+
+	for _, method := range methods {
+		lines = append(lines, fmt.Sprintf("%s: %s", method, xswdPermissionText(app.Permissions[method])))
+	}
+
+	yesChan := confirm_modal.Instance.Open(confirm_modal.ConfirmText{
+		Prompt: fmt.Sprintf(lang.Translate("%s wants to connect to your wallet."), app.Name),
+		Detail: strings.Join(lines, "\n"),
+		Yes:    lang.Translate("Accept"),
+		No:     lang.Translate("Reject"),
+	})
+	app_instance.Window.Invalidate()
+
+	select {
+	case yes := <-yesChan:
+		return yes
+	case <-ctx.Done():
+		confirm_modal.Instance.Modal.SetVisible(false)
+		app_instance.Window.Invalidate()
+		return false
+	}
+}
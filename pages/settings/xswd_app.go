@@ -0,0 +1,267 @@
+package page_settings
+
+import (
+	"fmt"
+	"sync"
+
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/secretsystems/secret-wallet/animation"
+	"github.com/secretsystems/secret-wallet/app_db"
+	"github.com/secretsystems/secret-wallet/app_instance"
+	"github.com/secretsystems/secret-wallet/components"
+	"github.com/secretsystems/secret-wallet/containers/listselect_modal"
+	"github.com/secretsystems/secret-wallet/containers/notification_modals"
+	"github.com/secretsystems/secret-wallet/lang"
+	"github.com/secretsystems/secret-wallet/prefabs"
+	"github.com/secretsystems/secret-wallet/router"
+	"github.com/secretsystems/secret-wallet/theme"
+	"github.com/secretsystems/secret-wallet/wallet_manager"
+	"github.com/tanema/gween"
+	"github.com/tanema/gween/ease"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+type PageXSWDApp struct {
+	isActive bool
+
+	animationEnter *animation.Animation
+	animationLeave *animation.Animation
+
+	buttonRevoke *components.Button
+	infoRows     []*prefabs.InfoRow
+
+	itemsLock   sync.Mutex
+	app         app_db.XSWDApp
+	methodItems []*RPCPolicyItem
+
+	list *widget.List
+}
+
+var _ router.Page = &PageXSWDApp{}
+
+func NewPageXSWDApp() *PageXSWDApp {
+	animationEnter := animation.NewAnimation(false, gween.NewSequence(
+		gween.New(1, 0, .25, ease.Linear),
+	))
+
+	animationLeave := animation.NewAnimation(false, gween.NewSequence(
+		gween.New(0, 1, .25, ease.Linear),
+	))
+
+	deleteIcon, _ := widget.NewIcon(icons.ActionDelete)
+
+	list := new(widget.List)
+	list.Axis = layout.Vertical
+
+	return &PageXSWDApp{
+		animationEnter: animationEnter,
+		animationLeave: animationLeave,
+		buttonRevoke:   newRPCPolicyButton(deleteIcon),
+		infoRows:       prefabs.NewInfoRows(3),
+		list:           list,
+	}
+}
+
+func (p *PageXSWDApp) IsActive() bool {
+	return p.isActive
+}
+
+func (p *PageXSWDApp) Enter() {
+	p.isActive = true
+	page_instance.header.Title = func() string { return p.app.Name }
+
+	if !page_instance.header.IsHistory(PAGE_XSWD_APP) {
+		p.animationEnter.Start()
+		p.animationLeave.Reset()
+	}
+}
+
+func (p *PageXSWDApp) Leave() {
+	p.animationEnter.Reset()
+	p.animationLeave.Start()
+}
+
+func (p *PageXSWDApp) SetApp(app app_db.XSWDApp) {
+	var methodItems []*RPCPolicyItem
+	for _, method := range wallet_manager.XSWDMethods() {
+		methodItems = append(methodItems, NewRPCPolicyItem(method, method, xswdPermissionText(app.Permission(method))))
+	}
+
+	p.itemsLock.Lock()
+	p.app = app
+	p.methodItems = methodItems
+	p.itemsLock.Unlock()
+}
+
+// the server updates the permissions of a connected app right away
+func (p *PageXSWDApp) setPermission(method string, permission app_db.XSWDPermission) error {
+	p.itemsLock.Lock()
+	app := p.app
+	p.itemsLock.Unlock()
+
+	var err error
+	wallet := wallet_manager.OpenedWallet
+	if wallet != nil && wallet.XSWD != nil {
+		err = wallet.XSWD.SetPermission(app.ID, method, permission)
+	} else {
+		err = app_db.SetXSWDPermission(app.ID, method, permission)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	app, found, err := app_db.GetXSWDApp(app.ID)
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		return fmt.Errorf("application was revoked")
+	}
+
+	p.SetApp(app)
+	return nil
+}
+
+func (p *PageXSWDApp) openPermissionMenu(method string) {
+	permissions := []app_db.XSWDPermission{app_db.XSWD_PERMISSION_ASK, app_db.XSWD_PERMISSION_DENY}
+	if wallet_manager.XSWDCanAlwaysAllow(method) {
+		permissions = append([]app_db.XSWDPermission{app_db.XSWD_PERMISSION_ALWAYS}, permissions...)
+	}
+
+	items := []*listselect_modal.SelectListItem{}
+	for _, permission := range permissions {
+		items = append(items, listselect_modal.NewSelectListItem(permission.String(),
+			listselect_modal.NewItemText(nil, xswdPermissionText(permission)).Layout,
+		))
+	}
+
+	keyChan := listselect_modal.Instance.Open(items)
+	for key := range keyChan {
+		permission := app_db.XSWD_PERMISSION_ASK
+		switch key {
+		case app_db.XSWD_PERMISSION_ALWAYS.String():
+			permission = app_db.XSWD_PERMISSION_ALWAYS
+		case app_db.XSWD_PERMISSION_DENY.String():
+			permission = app_db.XSWD_PERMISSION_DENY
+		}
+
+		err := p.setPermission(method, permission)
+		if err != nil {
+			notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+			notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		}
+
+		app_instance.Window.Invalidate()
+	}
+}
+
+func (p *PageXSWDApp) revoke(app app_db.XSWDApp) {
+	err := revokeXSWDApp(app)
+	if err != nil {
+		notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+		notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		app_instance.Window.Invalidate()
+		return
+	}
+
+	_, found, _ := app_db.GetXSWDApp(app.ID)
+	if !found {
+		page_instance.header.GoBack()
+	}
+
+	app_instance.Window.Invalidate()
+}
+
+func (p *PageXSWDApp) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	{
+		state := p.animationEnter.Update(gtx)
+		if state.Active {
+			defer animation.TransformX(gtx, state.Value).Push(gtx.Ops).Pop()
+		}
+	}
+
+	{
+		state := p.animationLeave.Update(gtx)
+		if state.Finished {
+			p.isActive = false
+			op.InvalidateOp{}.Add(gtx.Ops)
+		}
+
+		if state.Active {
+			defer animation.TransformX(gtx, state.Value).Push(gtx.Ops).Pop()
+		}
+	}
+
+	p.itemsLock.Lock()
+	app := p.app
+	methodItems := p.methodItems
+	p.itemsLock.Unlock()
+
+	if p.buttonRevoke.Clicked() {
+		go p.revoke(app)
+	}
+
+	for _, item := range methodItems {
+		if item.clickable.Clicked() {
+			go p.openPermissionMenu(item.key)
+		}
+	}
+
+	connected := false
+	wallet := wallet_manager.OpenedWallet
+	if wallet != nil && wallet.XSWD != nil {
+		connected = wallet.XSWD.IsConnected(app.ID)
+	}
+
+	var widgets []layout.Widget
+
+	widgets = append(widgets,
+		func(gtx layout.Context) layout.Dimensions {
+			return p.infoRows[0].Layout(gtx, th, lang.Translate("Description"), app.Description)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.infoRows[1].Layout(gtx, th, lang.Translate("Url"), app.Url)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			status := lang.Translate("Not connected")
+			if connected {
+				status = lang.Translate("Connected")
+			}
+
+			return p.infoRows[2].Layout(gtx, th, lang.Translate("Status"), status)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return layoutSectionTitle(gtx, th, lang.Translate("Permissions"),
+				lang.Translate("What the app can do without asking. Denied methods fail right away."))
+		},
+	)
+
+	for i := range methodItems {
+		item := methodItems[i]
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			return item.Layout(gtx, th)
+		})
+	}
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		p.buttonRevoke.Text = lang.Translate("REVOKE")
+		p.buttonRevoke.Style.Colors = theme.Current.ButtonDangerColors
+		return p.buttonRevoke.Layout(gtx, th)
+	})
+
+	listStyle := material.List(th, p.list)
+	listStyle.AnchorStrategy = material.Overlay
+
+	return listStyle.Layout(gtx, len(widgets), func(gtx layout.Context, index int) layout.Dimensions {
+		return layout.Inset{
+			Top: unit.Dp(0), Bottom: unit.Dp(20),
+			Left: unit.Dp(30), Right: unit.Dp(30),
+		}.Layout(gtx, widgets[index])
+	})
+}
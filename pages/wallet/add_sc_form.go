@@ -17,6 +17,7 @@ import (
 	"gioui.org/widget"
 	"gioui.org/widget/material"
 	"github.com/secretsystems/secret-wallet/animation"
+	"github.com/secretsystems/secret-wallet/app_instance"
 	"github.com/secretsystems/secret-wallet/components"
 	"github.com/secretsystems/secret-wallet/containers/notification_modals"
 	"github.com/secretsystems/secret-wallet/lang"
@@ -106,6 +107,26 @@ func (p *PageAddSCForm) Leave() {
 	p.animationEnter.Reset()
 }
 
+// opens the form with the contract already fetched
+func (p *PageAddSCForm) SetSCID(scId string) {
+	p.txtSCID.SetValue(scId)
+	go p.fetchData()
+}
+
+func (p *PageAddSCForm) fetchData() {
+	p.scDetailsContainer.token = nil
+	p.buttonFetchData.SetLoading(true)
+	token, err := p.submitForm()
+	if err != nil {
+		notification_modals.ErrorInstance.SetText("Error", err.Error())
+		notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+	}
+
+	p.buttonFetchData.SetLoading(false)
+	p.scDetailsContainer.Set(token)
+	app_instance.Window.Invalidate()
+}
+
 func (p *PageAddSCForm) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
 	{
 		state := p.animationEnter.Update(gtx)
@@ -127,19 +148,7 @@ func (p *PageAddSCForm) Layout(gtx layout.Context, th *material.Theme) layout.Di
 	}
 
 	if p.buttonFetchData.Clicked() {
-		go func() {
-			p.scDetailsContainer.token = nil
-			p.buttonFetchData.SetLoading(true)
-			token, err := p.submitForm()
-			if err != nil {
-				notification_modals.ErrorInstance.SetText("Error", err.Error())
-				notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
-
-			}
-
-			p.buttonFetchData.SetLoading(false)
-			p.scDetailsContainer.Set(token)
-		}()
+		go p.fetchData()
 	}
 
 	widgets := []layout.Widget{
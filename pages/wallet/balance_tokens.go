@@ -23,10 +23,12 @@ import (
 	"github.com/deroproject/derohe/walletapi"
 	"github.com/secretsystems/secret-wallet/animation"
 	"github.com/secretsystems/secret-wallet/app_icons"
+	"github.com/secretsystems/secret-wallet/app_instance"
 	"github.com/secretsystems/secret-wallet/components"
 	"github.com/secretsystems/secret-wallet/containers/image_modal"
 	"github.com/secretsystems/secret-wallet/containers/node_status_bar"
 	"github.com/secretsystems/secret-wallet/containers/notification_modals"
+	"github.com/secretsystems/secret-wallet/containers/tx_filter_modal"
 	"github.com/secretsystems/secret-wallet/lang"
 	"github.com/secretsystems/secret-wallet/node_manager"
 	"github.com/secretsystems/secret-wallet/prefabs"
@@ -46,20 +48,19 @@ type PageBalanceTokens struct {
 	animationEnter *animation.Animation
 	animationLeave *animation.Animation
 
-	alertBox         *AlertBox
-	displayBalance   *DisplayBalance
-	tokenBar         *TokenBar
-	tokenItems       []*TokenListItem
-	buttonSettings   *components.Button
-	buttonRegister   *components.Button
-	buttonCopyAddr   *components.Button
-	buttonDexSwap    *components.Button
-	tabBars          *components.TabBars
-	txBar            *TxBar
-	txItems          []*TxListItem
-	getEntriesParams wallet_manager.GetEntriesParams
-	tokenDragItems   *components.DragItems
-	tokenList        *widget.List
+	alertBox       *AlertBox
+	displayBalance *DisplayBalance
+	tokenBar       *TokenBar
+	tokenItems     []*TokenListItem
+	buttonSettings *components.Button
+	buttonRegister *components.Button
+	buttonCopyAddr *components.Button
+	buttonDexSwap  *components.Button
+	tabBars        *components.TabBars
+	txBar          *TxBar
+	txItems        []*TxListItem
+	tokenDragItems *components.DragItems
+	tokenList      *widget.List
 
 	list *widget.List
 }
@@ -165,9 +166,12 @@ func (p *PageBalanceTokens) Enter() {
 }
 
 func (p *PageBalanceTokens) Load() error {
-	p.LoadTxs()
+	err := p.LoadTxs()
+	if err != nil {
+		return err
+	}
 
-	err := p.LoadTokens()
+	err = p.LoadTokens()
 	if err != nil {
 		return err
 	}
@@ -194,9 +198,17 @@ func (p *PageBalanceTokens) LoadTokens() error {
 	return nil
 }
 
-func (p *PageBalanceTokens) LoadTxs() {
+func (p *PageBalanceTokens) LoadTxs() error {
 	wallet := wallet_manager.OpenedWallet
-	entries := wallet.GetEntries(&crypto.ZEROHASH, p.getEntriesParams)
+	params, err := p.txBar.EntriesParams()
+	if err != nil {
+		return err
+	}
+
+	entries, err := wallet.GetEntries(&crypto.ZEROHASH, params)
+	if err != nil {
+		return err
+	}
 
 	txItems := []*TxListItem{}
 
@@ -206,6 +218,7 @@ func (p *PageBalanceTokens) LoadTxs() {
 
 	p.txItems = txItems
 	p.txBar.txCount = len(entries)
+	return nil
 }
 
 func (p *PageBalanceTokens) ResetWalletHeader() {
@@ -314,7 +327,10 @@ func (p *PageBalanceTokens) Layout(gtx layout.Context, th *material.Theme) layou
 					widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
 						return p.alertBox.Layout(gtx, th, lang.Translate("This wallet is not registered on the blockchain."))
 					})
+				}
 
+				// the registration tx must be signed by the owner of the address
+				if !isRegistered && !wallet.IsWatchOnly() {
 					widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
 						return layout.Inset{
 							Top: unit.Dp(0), Bottom: unit.Dp(20),
@@ -344,6 +360,12 @@ func (p *PageBalanceTokens) Layout(gtx layout.Context, th *material.Theme) layou
 		}
 	}
 
+	if wallet != nil && wallet.IsWatchOnly() {
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			return p.alertBox.Layout(gtx, th, lang.Translate("This is a watch-only wallet. Balances can't be decrypted without the secret key and transactions are exported for offline signing."))
+		})
+	}
+
 	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
 		return layout.Inset{
 			Left: unit.Dp(30), Right: unit.Dp(30),
@@ -353,16 +375,16 @@ func (p *PageBalanceTokens) Layout(gtx layout.Context, th *material.Theme) layou
 		})
 	})
 
-	// widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
-	// 	return layout.Inset{
-	// 		Left: unit.Dp(30), Right: unit.Dp(30),
-	// 		Top: unit.Dp(0), Bottom: unit.Dp(30),
-	// 	}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
-	// 		p.buttonDexSwap.Style.Colors = theme.Current.ButtonSecondaryColors
-	// 		// p.buttonDexSwap.Text = lang.Translate("DEX Swap")
-	// 		return p.buttonDexSwap.Layout(gtx, th)
-	// 	})
-	// })
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		return layout.Inset{
+			Left: unit.Dp(30), Right: unit.Dp(30),
+			Top: unit.Dp(0), Bottom: unit.Dp(30),
+		}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			p.buttonDexSwap.Style.Colors = theme.Current.ButtonSecondaryColors
+			p.buttonDexSwap.Text = lang.Translate("DEX Swap")
+			return p.buttonDexSwap.Layout(gtx, th)
+		})
+	})
 
 	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
 		return prefabs.Divider(gtx, 3)
@@ -382,26 +404,13 @@ func (p *PageBalanceTokens) Layout(gtx layout.Context, th *material.Theme) layou
 	})
 
 	{
-		changed, tab := p.txBar.Changed()
+		changed, _ := p.txBar.Changed()
 		if changed {
-			switch tab {
-			case "all":
-				p.getEntriesParams = wallet_manager.GetEntriesParams{}
-			case "in":
-				p.getEntriesParams = wallet_manager.GetEntriesParams{
-					In: sql.NullBool{Bool: true, Valid: true},
-				}
-			case "out":
-				p.getEntriesParams = wallet_manager.GetEntriesParams{
-					Out: sql.NullBool{Bool: true, Valid: true},
-				}
-			case "coinbase":
-				p.getEntriesParams = wallet_manager.GetEntriesParams{
-					Coinbase: sql.NullBool{Bool: true, Valid: true},
-				}
+			err := p.LoadTxs()
+			if err != nil {
+				notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+				notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
 			}
-
-			p.LoadTxs()
 		}
 	}
 
@@ -709,8 +718,11 @@ func (d *DisplayBalance) Layout(gtx layout.Context, th *material.Theme) layout.D
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 			return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
 				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
-					balance, _ := wallet.Memory.Get_Balance()
+					balance, known := wallet.GetBalance(crypto.ZEROHASH)
 					amount := utils.ShiftNumber{Number: balance, Decimals: 5}.Format()
+					if !known {
+						amount = lang.Translate("Encrypted")
+					}
 
 					if d.balanceEditor.Text() != amount {
 						d.balanceEditor.SetText(amount)
@@ -890,9 +902,13 @@ func (item *TokenListItem) Layout(gtx layout.Context, th *material.Theme) layout
 				Bottom: unit.Dp(5), Top: unit.Dp(5),
 			}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 				wallet := wallet_manager.OpenedWallet
-				balance, _ := wallet.Memory.Get_Balance_scid(item.token.GetHash())
-				amount := utils.ShiftNumber{Number: uint64(balance), Decimals: int(item.token.Decimals)}
-				lbl := material.Label(th, unit.Sp(18), amount.Format())
+				balance, known := wallet.GetBalance(item.token.GetHash())
+				amount := utils.ShiftNumber{Number: uint64(balance), Decimals: int(item.token.Decimals)}.Format()
+				if !known {
+					amount = lang.Translate("Encrypted")
+				}
+
+				lbl := material.Label(th, unit.Sp(18), amount)
 				lbl.Font.Weight = font.Bold
 				return lbl.Layout(gtx)
 			})
@@ -926,6 +942,10 @@ type TxBar struct {
 	buttonFilter   *components.Button
 	txCount        int
 
+	filter        tx_filter_modal.TxFilter
+	filterApplied bool
+	decimals      int // used to parse the amounts of the filter
+
 	textColorOn  color.NRGBA
 	textColorOff color.NRGBA
 	bgColorOn    color.NRGBA
@@ -1006,6 +1026,7 @@ func NewTxBar() *TxBar {
 		buttonCoinbase: buttonCoinbase,
 		buttonFilter:   buttonFilter,
 		tab:            "all",
+		decimals:       5,
 
 		textColorOn:  textColorOn,
 		textColorOff: textColorOff,
@@ -1018,6 +1039,29 @@ func (t *TxBar) Changed() (bool, string) {
 	return t.changed, t.tab
 }
 
+func (t *TxBar) ResetFilter() {
+	t.filter = tx_filter_modal.TxFilter{}
+}
+
+// the selected tab combined with the filter
+func (t *TxBar) EntriesParams() (wallet_manager.GetEntriesParams, error) {
+	params, err := t.filter.Params(t.decimals)
+	if err != nil {
+		return params, err
+	}
+
+	switch t.tab {
+	case "in":
+		params.In = sql.NullBool{Bool: true, Valid: true}
+	case "out":
+		params.Out = sql.NullBool{Bool: true, Valid: true}
+	case "coinbase":
+		params.Coinbase = sql.NullBool{Bool: true, Valid: true}
+	}
+
+	return params, nil
+}
+
 func (t *TxBar) setActiveButton(button *components.Button, tab string) {
 	if t.tab == tab {
 		button.Style.Colors = theme.Current.ButtonPrimaryColors
@@ -1051,6 +1095,22 @@ func (t *TxBar) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions
 		t.tab = "coinbase"
 	}
 
+	if t.buttonFilter.Clicked() {
+		go func() {
+			filterChan := tx_filter_modal.Instance.Open(t.filter, t.decimals)
+			for filter := range filterChan {
+				t.filter = filter
+				t.filterApplied = true
+				app_instance.Window.Invalidate()
+			}
+		}()
+	}
+
+	if t.filterApplied {
+		t.filterApplied = false
+		t.changed = true
+	}
+
 	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 			return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
@@ -1084,7 +1144,11 @@ func (t *TxBar) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions
 				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 					//t.buttonFilter.Text = lang.Translate("Filter")
 					gtx.Constraints.Max = image.Pt(gtx.Dp(30), gtx.Dp(30))
-					t.buttonFilter.Style.Colors = theme.Current.ButtonSecondaryColors
+					if t.filter.IsEmpty() {
+						t.buttonFilter.Style.Colors = theme.Current.ButtonSecondaryColors
+					} else {
+						t.buttonFilter.Style.Colors = theme.Current.ButtonPrimaryColors
+					}
 					return t.buttonFilter.Layout(gtx, th)
 				}),
 			)
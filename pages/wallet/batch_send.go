@@ -0,0 +1,693 @@
+package page_wallet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"sort"
+	"strings"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/deroproject/derohe/globals"
+	"github.com/deroproject/derohe/rpc"
+	"github.com/secretsystems/secret-wallet/animation"
+	"github.com/secretsystems/secret-wallet/app_instance"
+	"github.com/secretsystems/secret-wallet/components"
+	"github.com/secretsystems/secret-wallet/containers/build_tx_modal"
+	"github.com/secretsystems/secret-wallet/containers/listselect_modal"
+	"github.com/secretsystems/secret-wallet/containers/notification_modals"
+	"github.com/secretsystems/secret-wallet/lang"
+	"github.com/secretsystems/secret-wallet/prefabs"
+	"github.com/secretsystems/secret-wallet/router"
+	"github.com/secretsystems/secret-wallet/settings"
+	"github.com/secretsystems/secret-wallet/theme"
+	"github.com/secretsystems/secret-wallet/utils"
+	"github.com/secretsystems/secret-wallet/wallet_manager"
+	"github.com/tanema/gween"
+	"github.com/tanema/gween/ease"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+type PageBatchSend struct {
+	isActive bool
+
+	animationEnter *animation.Animation
+	animationLeave *animation.Animation
+
+	rows             []*BatchRow
+	ringSizeSelector *prefabs.RingSizeSelector
+	buttonAddRow     *components.Button
+	buttonPreview    *components.Button
+	buttonSend       *components.Button
+	buttonMenu       *components.Button
+
+	batchTxs      []wallet_manager.BatchTx
+	batchTokens   []*wallet_manager.Token
+	batchValues   string
+	batchRingsize int
+	sentCount     int
+	sentTxIds     []string
+
+	list *widget.List
+}
+
+var _ router.Page = &PageBatchSend{}
+
+func NewPageBatchSend() *PageBatchSend {
+	animationEnter := animation.NewAnimation(false, gween.NewSequence(
+		gween.New(1, 0, .25, ease.Linear),
+	))
+
+	animationLeave := animation.NewAnimation(false, gween.NewSequence(
+		gween.New(0, 1, .25, ease.Linear),
+	))
+
+	list := new(widget.List)
+	list.Axis = layout.Vertical
+
+	addIcon, _ := widget.NewIcon(icons.ContentAdd)
+	buttonAddRow := components.NewButton(components.ButtonStyle{
+		Rounded:   components.UniformRounded(unit.Dp(5)),
+		Icon:      addIcon,
+		TextSize:  unit.Sp(14),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+	})
+	buttonAddRow.Label.Alignment = text.Middle
+	buttonAddRow.Style.Font.Weight = font.Bold
+
+	loadingIcon, _ := widget.NewIcon(icons.NavigationRefresh)
+
+	previewIcon, _ := widget.NewIcon(icons.ActionAssignment)
+	buttonPreview := components.NewButton(components.ButtonStyle{
+		Rounded:     components.UniformRounded(unit.Dp(5)),
+		Icon:        previewIcon,
+		LoadingIcon: loadingIcon,
+		TextSize:    unit.Sp(14),
+		IconGap:     unit.Dp(10),
+		Inset:       layout.UniformInset(unit.Dp(10)),
+		Animation:   components.NewButtonAnimationDefault(),
+	})
+	buttonPreview.Label.Alignment = text.Middle
+	buttonPreview.Style.Font.Weight = font.Bold
+
+	buildIcon, _ := widget.NewIcon(icons.HardwareMemory)
+	buttonSend := components.NewButton(components.ButtonStyle{
+		Rounded:     components.UniformRounded(unit.Dp(5)),
+		Icon:        buildIcon,
+		LoadingIcon: loadingIcon,
+		TextSize:    unit.Sp(14),
+		IconGap:     unit.Dp(10),
+		Inset:       layout.UniformInset(unit.Dp(10)),
+		Animation:   components.NewButtonAnimationDefault(),
+	})
+	buttonSend.Label.Alignment = text.Middle
+	buttonSend.Style.Font.Weight = font.Bold
+
+	menuIcon, _ := widget.NewIcon(icons.NavigationMenu)
+	buttonMenu := components.NewButton(components.ButtonStyle{
+		Icon:      menuIcon,
+		Animation: components.NewButtonAnimationScale(.98),
+	})
+
+	return &PageBatchSend{
+		animationEnter:   animationEnter,
+		animationLeave:   animationLeave,
+		ringSizeSelector: prefabs.NewRingSizeSelector(settings.App.SendRingSize),
+		buttonAddRow:     buttonAddRow,
+		buttonPreview:    buttonPreview,
+		buttonSend:       buttonSend,
+		buttonMenu:       buttonMenu,
+		list:             list,
+	}
+}
+
+func (p *PageBatchSend) IsActive() bool {
+	return p.isActive
+}
+
+func (p *PageBatchSend) Enter() {
+	p.isActive = true
+	page_instance.header.Title = func() string { return lang.Translate("Batch Send") }
+	page_instance.header.Subtitle = nil
+	page_instance.header.ButtonRight = p.buttonMenu
+
+	if !page_instance.header.IsHistory(PAGE_BATCH_SEND) {
+		p.animationEnter.Start()
+		p.animationLeave.Reset()
+	}
+
+	if len(p.rows) == 0 {
+		p.AddRow(wallet_manager.BatchTransfer{})
+	}
+}
+
+func (p *PageBatchSend) Leave() {
+	p.animationLeave.Start()
+	p.animationEnter.Reset()
+}
+
+func (p *PageBatchSend) AddRow(batchTransfer wallet_manager.BatchTransfer) {
+	row := NewBatchRow()
+	row.SetValues(batchTransfer)
+	p.rows = append(p.rows, row)
+}
+
+func (p *PageBatchSend) ClearForm() {
+	p.rows = nil
+	p.batchTxs = nil
+	p.sentCount = 0
+	p.sentTxIds = nil
+	p.AddRow(wallet_manager.BatchTransfer{})
+	p.list.ScrollTo(0)
+}
+
+// the preview is only valid if the rows didn't change since
+func (p *PageBatchSend) rowsValues() string {
+	var values []string
+	for _, row := range p.rows {
+		values = append(values, fmt.Sprintf("%+v", row.Values()))
+	}
+
+	return strings.Join(values, "\n")
+}
+
+func (p *PageBatchSend) preview() error {
+	wallet := wallet_manager.OpenedWallet
+
+	p.batchTxs = nil
+	p.batchTokens = nil
+	p.sentCount = 0
+	p.sentTxIds = nil
+
+	var transfers []rpc.Transfer
+	tokens := make(map[string]*wallet_manager.Token)
+	rowErrors := 0
+
+	for _, row := range p.rows {
+		transfer, token, err := wallet.ValidateBatchTransfer(row.Values())
+		row.err = err
+		if err != nil {
+			rowErrors++
+			continue
+		}
+
+		transfers = append(transfers, transfer)
+		tokens[token.SCID] = token
+	}
+
+	app_instance.Window.Invalidate()
+
+	if rowErrors > 0 {
+		return fmt.Errorf(lang.Translate("%d row(s) have errors."), rowErrors)
+	}
+
+	ringsize := p.ringSizeSelector.Size
+	batchTxs, err := wallet.SplitBatchTransfers(transfers, uint64(ringsize))
+	if err != nil {
+		return err
+	}
+
+	for _, token := range tokens {
+		p.batchTokens = append(p.batchTokens, token)
+	}
+
+	p.batchTxs = batchTxs
+	p.batchValues = p.rowsValues()
+	p.batchRingsize = ringsize
+	return nil
+}
+
+// a tx is built against the last balance - the previous tx of the batch must be mined and synced first
+func (p *PageBatchSend) checkPreviousTx() error {
+	if len(p.sentTxIds) == 0 {
+		return nil
+	}
+
+	wallet := wallet_manager.OpenedWallet
+	txId := p.sentTxIds[len(p.sentTxIds)-1]
+
+	outgoingTxs, err := wallet.GetOutgoingTxs(wallet_manager.GetOutgoingTxsParams{
+		TxIds: []string{txId},
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, outgoingTx := range outgoingTxs {
+		switch outgoingTx.Status.String {
+		case "pending":
+			return errors.New(lang.Translate("Wait for the previous transaction to be confirmed before sending the next one."))
+		case "invalid":
+			// the previous batch tx didn't go through - send it again
+			p.sentTxIds = p.sentTxIds[:len(p.sentTxIds)-1]
+			if p.sentCount > 0 {
+				p.sentCount--
+			}
+		case "valid":
+			if wallet.Memory.Get_Height() < uint64(outgoingTx.BlockHeight.Int64) {
+				return errors.New(lang.Translate("Wait for the wallet to sync the previous transaction."))
+			}
+		}
+	}
+
+	return nil
+}
+
+func (p *PageBatchSend) sendNextTx() error {
+	err := p.checkPreviousTx()
+	if err != nil {
+		return err
+	}
+
+	batchTx := p.batchTxs[p.sentCount]
+	build_tx_modal.Instance.Open(build_tx_modal.TxPayload{
+		Transfers:  batchTx.Transfers,
+		Ringsize:   uint64(p.batchRingsize),
+		TokensInfo: p.batchTokens,
+	})
+
+	return nil
+}
+
+func (p *PageBatchSend) importCSV() error {
+	file, err := app_instance.Explorer.ChooseFile(".csv")
+	if err != nil {
+		return err
+	}
+
+	reader := utils.ReadCloser{ReadCloser: file}
+	data, err := reader.ReadAll()
+	if err != nil {
+		return err
+	}
+
+	batchTransfers, err := wallet_manager.ParseBatchCSV(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	// replace the empty row of a new form
+	if len(p.rows) == 1 && p.rows[0].IsEmpty() {
+		p.rows = nil
+	}
+
+	for _, batchTransfer := range batchTransfers {
+		p.AddRow(batchTransfer)
+	}
+
+	return nil
+}
+
+func (p *PageBatchSend) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	{
+		state := p.animationEnter.Update(gtx)
+		if state.Active {
+			defer animation.TransformX(gtx, state.Value).Push(gtx.Ops).Pop()
+		}
+	}
+
+	{
+		state := p.animationLeave.Update(gtx)
+		if state.Active {
+			defer animation.TransformX(gtx, state.Value).Push(gtx.Ops).Pop()
+		}
+
+		if state.Finished {
+			p.isActive = false
+			op.InvalidateOp{}.Add(gtx.Ops)
+		}
+	}
+
+	if p.buttonMenu.Clicked() {
+		go func() {
+			contactIcon, _ := widget.NewIcon(icons.SocialGroup)
+			downIcon, _ := widget.NewIcon(icons.FileFileDownload)
+			clearIcon, _ := widget.NewIcon(icons.ContentClear)
+
+			keyChan := listselect_modal.Instance.Open([]*listselect_modal.SelectListItem{
+				listselect_modal.NewSelectListItem("add_contact",
+					listselect_modal.NewItemText(contactIcon, lang.Translate("Add from contacts")).Layout,
+				),
+				listselect_modal.NewSelectListItem("import_csv",
+					listselect_modal.NewItemText(downIcon, lang.Translate("Import CSV")).Layout,
+				),
+				listselect_modal.NewSelectListItem("clear_rows",
+					listselect_modal.NewItemText(clearIcon, lang.Translate("Clear rows")).Layout,
+				),
+			})
+
+			for key := range keyChan {
+				switch key {
+				case "add_contact":
+					page_instance.pageContacts.onSelect = func(contact wallet_manager.Contact) {
+						if len(p.rows) == 1 && p.rows[0].IsEmpty() {
+							p.rows = nil
+						}
+
+						p.AddRow(wallet_manager.BatchTransfer{Addr: contact.Addr})
+					}
+					page_instance.pageRouter.SetCurrent(PAGE_CONTACTS)
+					page_instance.header.AddHistory(PAGE_CONTACTS)
+				case "import_csv":
+					err := p.importCSV()
+					if err != nil {
+						notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+						notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+					} else {
+						notification_modals.SuccessInstance.SetText(lang.Translate("Success"), lang.Translate("CSV imported."))
+						notification_modals.SuccessInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+					}
+				case "clear_rows":
+					p.ClearForm()
+				}
+			}
+		}()
+	}
+
+	if p.buttonAddRow.Clicked() {
+		p.AddRow(wallet_manager.BatchTransfer{})
+		p.list.ScrollTo(len(p.rows))
+	}
+
+	for i := 0; i < len(p.rows); i++ {
+		if p.rows[i].buttonRemove.Clicked() {
+			p.rows = append(p.rows[:i], p.rows[i+1:]...)
+			i--
+		}
+	}
+
+	if p.ringSizeSelector.Changed {
+		settings.App.SendRingSize = p.ringSizeSelector.Size
+		settings.Save()
+	}
+
+	// rows or ring size changed after the preview or while sending - the preview must be done again
+	if len(p.batchTxs) > 0 && p.sentCount == 0 &&
+		(p.batchValues != p.rowsValues() || p.batchRingsize != p.ringSizeSelector.Size) {
+		p.batchTxs = nil
+	}
+
+	if p.buttonPreview.Clicked() {
+		go func() {
+			p.buttonPreview.SetLoading(true)
+			err := p.preview()
+			p.buttonPreview.SetLoading(false)
+
+			if err != nil {
+				notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+				notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+			}
+
+			app_instance.Window.Invalidate()
+		}()
+	}
+
+	if p.buttonSend.Clicked() {
+		go func() {
+			p.buttonSend.SetLoading(true)
+			err := p.sendNextTx()
+			p.buttonSend.SetLoading(false)
+
+			if err != nil {
+				notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+				notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+			}
+		}()
+	}
+
+	if build_tx_modal.Instance.TxSent() {
+		sentTx := build_tx_modal.Instance.SentTx()
+		if sentTx != nil {
+			p.sentTxIds = append(p.sentTxIds, sentTx.GetHash().String())
+		}
+
+		p.sentCount++
+		if p.sentCount >= len(p.batchTxs) {
+			p.ClearForm()
+			notification_modals.SuccessInstance.SetText(lang.Translate("Success"), lang.Translate("Batch sent."))
+			notification_modals.SuccessInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		}
+	}
+
+	widgets := []layout.Widget{
+		func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(14), lang.Translate("Add rows manually, from your contacts or import a CSV file with the columns: address, amount, token scid, message, dst port."))
+			lbl.Color = theme.Current.TextMuteColor
+			return lbl.Layout(gtx)
+		},
+	}
+
+	for i := range p.rows {
+		idx := i
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			return p.rows[idx].Layout(gtx, th, idx)
+		})
+	}
+
+	widgets = append(widgets,
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonAddRow.Text = lang.Translate("ADD ROW")
+			p.buttonAddRow.Style.Colors = theme.Current.ButtonSecondaryColors
+			return p.buttonAddRow.Layout(gtx, th)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.ringSizeSelector.Layout(gtx, th)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonPreview.Text = lang.Translate("PREVIEW BATCH")
+			p.buttonPreview.Style.Colors = theme.Current.ButtonPrimaryColors
+			return p.buttonPreview.Layout(gtx, th)
+		},
+	)
+
+	if len(p.batchTxs) > 0 {
+		widgets = append(widgets,
+			func(gtx layout.Context) layout.Dimensions {
+				return p.layoutPreview(gtx, th)
+			},
+			func(gtx layout.Context) layout.Dimensions {
+				p.buttonSend.Text = fmt.Sprintf("%s %d/%d", lang.Translate("SEND TRANSACTION"), p.sentCount+1, len(p.batchTxs))
+				p.buttonSend.Style.Colors = theme.Current.ButtonPrimaryColors
+				return p.buttonSend.Layout(gtx, th)
+			},
+		)
+	}
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		return layout.Spacer{Height: unit.Dp(30)}.Layout(gtx)
+	})
+
+	listStyle := material.List(th, p.list)
+	listStyle.AnchorStrategy = material.Overlay
+
+	return listStyle.Layout(gtx, len(widgets), func(gtx layout.Context, index int) layout.Dimensions {
+		return layout.Inset{
+			Top: unit.Dp(0), Bottom: unit.Dp(20),
+			Left: unit.Dp(30), Right: unit.Dp(30),
+		}.Layout(gtx, widgets[index])
+	})
+}
+
+func (p *PageBatchSend) layoutPreview(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	var childs []layout.FlexChild
+
+	addLine := func(title string, value string) {
+		childs = append(childs, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					lbl := material.Label(th, unit.Sp(16), title)
+					lbl.Color = theme.Current.TextMuteColor
+					return lbl.Layout(gtx)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					lbl := material.Label(th, unit.Sp(16), value)
+					lbl.Alignment = text.End
+					return lbl.Layout(gtx)
+				}),
+			)
+		}))
+	}
+
+	recipients := 0
+	fees := uint64(0)
+	assetsAmount := make(map[crypto.Hash]uint64)
+	for _, batchTx := range p.batchTxs {
+		fees += batchTx.TxFees
+		for _, transfer := range batchTx.Transfers {
+			recipients++
+			assetsAmount[transfer.SCID] += transfer.Amount
+		}
+	}
+
+	addLine(lang.Translate("Recipients"), fmt.Sprint(recipients))
+	addLine(lang.Translate("Transactions"), fmt.Sprintf("%d (%d %s)", len(p.batchTxs), p.sentCount, lang.Translate("sent")))
+
+	var lines []string
+	for _, token := range p.batchTokens {
+		amount := assetsAmount[token.GetHash()]
+		value := utils.ShiftNumber{Number: amount, Decimals: int(token.Decimals)}.Format()
+		if token.Symbol.Valid {
+			value = fmt.Sprintf("%s %s", value, token.Symbol.String)
+		} else {
+			value = fmt.Sprintf("%s %s", value, utils.ReduceTxId(token.SCID))
+		}
+
+		lines = append(lines, value)
+	}
+
+	sort.Strings(lines)
+	for _, line := range lines {
+		addLine(lang.Translate("Total"), line)
+	}
+
+	addLine(lang.Translate("Estimated fees"), fmt.Sprintf("%s DERO", globals.FormatMoney(fees)))
+
+	r := op.Record(gtx.Ops)
+	dims := layout.UniformInset(unit.Dp(15)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx, childs...)
+	})
+	c := r.Stop()
+
+	paint.FillShape(gtx.Ops, theme.Current.ListBgColor,
+		clip.UniformRRect(image.Rectangle{Max: dims.Size}, gtx.Dp(10)).Op(gtx.Ops),
+	)
+
+	c.Add(gtx.Ops)
+	return dims
+}
+
+type BatchRow struct {
+	txtAddr      *prefabs.TextField
+	txtAmount    *prefabs.TextField
+	txtSCID      *prefabs.TextField
+	txtComment   *prefabs.TextField
+	txtDstPort   *prefabs.TextField
+	buttonRemove *components.Button
+
+	err error
+}
+
+func NewBatchRow() *BatchRow {
+	removeIcon, _ := widget.NewIcon(icons.ActionDelete)
+	buttonRemove := components.NewButton(components.ButtonStyle{
+		Icon:      removeIcon,
+		Animation: components.NewButtonAnimationScale(.98),
+	})
+
+	return &BatchRow{
+		txtAddr:      prefabs.NewTextField(),
+		txtAmount:    prefabs.NewNumberTextField(),
+		txtSCID:      prefabs.NewTextField(),
+		txtComment:   prefabs.NewTextField(),
+		txtDstPort:   prefabs.NewNumberTextField(),
+		buttonRemove: buttonRemove,
+	}
+}
+
+func (r *BatchRow) SetValues(batchTransfer wallet_manager.BatchTransfer) {
+	r.txtAddr.SetValue(batchTransfer.Addr)
+	r.txtAmount.SetValue(batchTransfer.Amount)
+	r.txtSCID.SetValue(batchTransfer.SCID)
+	r.txtComment.SetValue(batchTransfer.Comment)
+	r.txtDstPort.SetValue(batchTransfer.DstPort)
+}
+
+func (r *BatchRow) Values() wallet_manager.BatchTransfer {
+	return wallet_manager.BatchTransfer{
+		Addr:    strings.TrimSpace(r.txtAddr.Value()),
+		Amount:  strings.TrimSpace(r.txtAmount.Value()),
+		SCID:    strings.TrimSpace(r.txtSCID.Value()),
+		Comment: r.txtComment.Value(),
+		DstPort: strings.TrimSpace(r.txtDstPort.Value()),
+	}
+}
+
+func (r *BatchRow) IsEmpty() bool {
+	return r.Values() == wallet_manager.BatchTransfer{}
+}
+
+func (r *BatchRow) Layout(gtx layout.Context, th *material.Theme, index int) layout.Dimensions {
+	rec := op.Record(gtx.Ops)
+	dims := layout.UniformInset(unit.Dp(15)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		var childs []layout.FlexChild
+
+		childs = append(childs,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						lbl := material.Label(th, unit.Sp(18), fmt.Sprintf("#%d", index+1))
+						lbl.Font.Weight = font.Bold
+						return lbl.Layout(gtx)
+					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						gtx.Constraints.Min.X = gtx.Dp(25)
+						gtx.Constraints.Min.Y = gtx.Dp(25)
+						r.buttonRemove.Style.Colors = theme.Current.ButtonIconPrimaryColors
+						return r.buttonRemove.Layout(gtx, th)
+					}),
+				)
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(5)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return r.txtAddr.Layout(gtx, th, lang.Translate("DERO Address / Name"), "")
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(10)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						return r.txtAmount.Layout(gtx, th, lang.Translate("Amount"), "0")
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+					layout.Flexed(2, func(gtx layout.Context) layout.Dimensions {
+						return r.txtSCID.Layout(gtx, th, lang.Translate("Token SCID"), "DERO")
+					}),
+				)
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(10)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+					layout.Flexed(2, func(gtx layout.Context) layout.Dimensions {
+						return r.txtComment.Layout(gtx, th, lang.Translate("Message"), "")
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						return r.txtDstPort.Layout(gtx, th, lang.Translate("DST Port"), "")
+					}),
+				)
+			}),
+		)
+
+		if r.err != nil {
+			childs = append(childs,
+				layout.Rigid(layout.Spacer{Height: unit.Dp(10)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					lbl := material.Label(th, unit.Sp(14), r.err.Error())
+					lbl.Color = theme.Current.NodeStatusDotRedColor
+					return lbl.Layout(gtx)
+				}),
+			)
+		}
+
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx, childs...)
+	})
+	c := rec.Stop()
+
+	paint.FillShape(gtx.Ops, theme.Current.ListBgColor,
+		clip.UniformRRect(image.Rectangle{Max: dims.Size}, gtx.Dp(10)).Op(gtx.Ops),
+	)
+
+	c.Add(gtx.Ops)
+	return dims
+}
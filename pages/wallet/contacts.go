@@ -40,6 +40,9 @@ type PageContacts struct {
 
 	list              *widget.List
 	buttonMenuContact *components.Button
+
+	// replaces setting the send form address when a contact is selected
+	onSelect func(contact wallet_manager.Contact)
 }
 
 var _ router.Page = &PageContacts{}
@@ -318,8 +321,13 @@ func (item *ContactListItem) Layout(gtx layout.Context, th *material.Theme) layo
 	}
 
 	if item.buttonSelect.Clicked() {
-		txtWalletAddr := page_instance.pageSendForm.walletAddrInput.txtWalletAddr
-		txtWalletAddr.SetValue(item.contact.Addr)
+		onSelect := page_instance.pageContacts.onSelect
+		if onSelect != nil {
+			onSelect(item.contact)
+		} else {
+			txtWalletAddr := page_instance.pageSendForm.walletAddrInput.txtWalletAddr
+			txtWalletAddr.SetValue(item.contact.Addr)
+		}
 		page_instance.header.GoBack()
 	}
 
@@ -0,0 +1,341 @@
+package page_wallet
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/secretsystems/secret-wallet/animation"
+	"github.com/secretsystems/secret-wallet/app_instance"
+	"github.com/secretsystems/secret-wallet/components"
+	"github.com/secretsystems/secret-wallet/containers/build_tx_modal"
+	"github.com/secretsystems/secret-wallet/containers/notification_modals"
+	"github.com/secretsystems/secret-wallet/lang"
+	"github.com/secretsystems/secret-wallet/prefabs"
+	"github.com/secretsystems/secret-wallet/router"
+	"github.com/secretsystems/secret-wallet/theme"
+	"github.com/secretsystems/secret-wallet/utils"
+	"github.com/secretsystems/secret-wallet/wallet_manager"
+	"github.com/tanema/gween"
+	"github.com/tanema/gween/ease"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+type PageDEXAddLiquidity struct {
+	isActive bool
+
+	animationEnter *animation.Animation
+	animationLeave *animation.Animation
+	txtAmount1     *prefabs.TextField
+	txtAmount2     *prefabs.TextField
+	buttonAdd      *components.Button
+
+	// used to fill the other amount at the pool ratio when one of them is edited
+	lastAmount1 string
+	lastAmount2 string
+
+	pair DEXPair
+
+	list *widget.List
+}
+
+var _ router.Page = &PageDEXAddLiquidity{}
+
+func NewPageDEXAddLiquidity() *PageDEXAddLiquidity {
+	animationEnter := animation.NewAnimation(false, gween.NewSequence(
+		gween.New(1, 0, .25, ease.Linear),
+	))
+
+	animationLeave := animation.NewAnimation(false, gween.NewSequence(
+		gween.New(0, 1, .25, ease.Linear),
+	))
+
+	addIcon, _ := widget.NewIcon(icons.ContentAddCircleOutline)
+	loadingIcon, _ := widget.NewIcon(icons.NavigationRefresh)
+	buttonAdd := components.NewButton(components.ButtonStyle{
+		Rounded:     components.UniformRounded(unit.Dp(5)),
+		Icon:        addIcon,
+		TextSize:    unit.Sp(14),
+		IconGap:     unit.Dp(10),
+		Inset:       layout.UniformInset(unit.Dp(10)),
+		Animation:   components.NewButtonAnimationDefault(),
+		LoadingIcon: loadingIcon,
+	})
+	buttonAdd.Label.Alignment = text.Middle
+	buttonAdd.Style.Font.Weight = font.Bold
+
+	list := new(widget.List)
+	list.Axis = layout.Vertical
+
+	return &PageDEXAddLiquidity{
+		animationEnter: animationEnter,
+		animationLeave: animationLeave,
+		txtAmount1:     prefabs.NewNumberTextField(),
+		txtAmount2:     prefabs.NewNumberTextField(),
+		buttonAdd:      buttonAdd,
+		list:           list,
+	}
+}
+
+func (p *PageDEXAddLiquidity) IsActive() bool {
+	return p.isActive
+}
+
+func (p *PageDEXAddLiquidity) SetPair(pair DEXPair) {
+	p.pair = pair
+	p.setAmounts("", "")
+}
+
+func (p *PageDEXAddLiquidity) setAmounts(amount1 string, amount2 string) {
+	p.txtAmount1.SetValue(amount1)
+	p.txtAmount2.SetValue(amount2)
+	p.lastAmount1 = amount1
+	p.lastAmount2 = amount2
+}
+
+func (p *PageDEXAddLiquidity) Enter() {
+	p.isActive = true
+
+	if !page_instance.header.IsHistory(PAGE_DEX_ADD_LIQUIDITY) {
+		p.animationEnter.Start()
+		p.animationLeave.Reset()
+	}
+
+	page_instance.header.Title = func() string {
+		return lang.Translate("Add liquidity")
+	}
+
+	page_instance.header.Subtitle = func(gtx layout.Context, th *material.Theme) layout.Dimensions {
+		lbl := material.Label(th, unit.Sp(16), p.pair.Pair.Symbol)
+		lbl.Color = theme.Current.TextMuteColor
+		return lbl.Layout(gtx)
+	}
+
+	// the shares are the pair token
+	wallet := wallet_manager.OpenedWallet
+	wallet.Memory.TokenAdd(crypto.HashHexToHash(p.pair.Pair.Asset1))
+	wallet.Memory.TokenAdd(crypto.HashHexToHash(p.pair.Pair.Asset2))
+	wallet.Memory.TokenAdd(crypto.HashHexToHash(p.pair.SCID))
+}
+
+func (p *PageDEXAddLiquidity) Leave() {
+	p.animationLeave.Start()
+	p.animationEnter.Reset()
+}
+
+func parseDEXAmount(token *wallet_manager.Token, value string) (uint64, error) {
+	amount := utils.ShiftNumber{Decimals: int(token.Decimals)}
+	err := amount.Parse(strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s amount", dexTokenSymbol(token))
+	}
+
+	return amount.Number, nil
+}
+
+func (p *PageDEXAddLiquidity) fillAmounts() {
+	pair := p.pair.Pair
+	value1 := p.txtAmount1.Value()
+	value2 := p.txtAmount2.Value()
+
+	if value1 != p.lastAmount1 {
+		amount1, err := parseDEXAmount(p.pair.Token1, value1)
+		if err == nil {
+			amount2, _ := pair.CalcAddLiquidity(amount1, false)
+			if amount2 > 0 {
+				value2 = utils.ShiftNumber{Number: amount2, Decimals: int(p.pair.Token2.Decimals)}.Format()
+			}
+		}
+	} else if value2 != p.lastAmount2 {
+		amount2, err := parseDEXAmount(p.pair.Token2, value2)
+		if err == nil {
+			amount1, _ := pair.CalcAddLiquidity(amount2, true)
+			if amount1 > 0 {
+				value1 = utils.ShiftNumber{Number: amount1, Decimals: int(p.pair.Token1.Decimals)}.Format()
+			}
+		}
+	}
+
+	if value1 != p.txtAmount1.Value() || value2 != p.txtAmount2.Value() {
+		p.setAmounts(value1, value2)
+	}
+
+	p.lastAmount1 = value1
+	p.lastAmount2 = value2
+}
+
+func (p *PageDEXAddLiquidity) submitForm() error {
+	amount1, err := parseDEXAmount(p.pair.Token1, p.txtAmount1.Value())
+	if err != nil {
+		return err
+	}
+
+	amount2, err := parseDEXAmount(p.pair.Token2, p.txtAmount2.Value())
+	if err != nil {
+		return err
+	}
+
+	pair := LoadDEXPair(p.pair.SCID)
+	if pair.Err != nil {
+		return pair.Err
+	}
+
+	// the ratio moved since the amounts were filled - half a percent covers the rounding of the inputs
+	expected, _ := pair.Pair.CalcAddLiquidity(amount1, false)
+	if expected > 0 && math.Abs(float64(expected)-float64(amount2)) > float64(expected)*0.005 {
+		p.pair = pair
+		app_instance.Window.Invalidate()
+		return fmt.Errorf("the amounts don't match the pool ratio")
+	}
+
+	wallet := wallet_manager.OpenedWallet
+	randomAddr, err := wallet.GetRandomAddress(crypto.ZEROHASH)
+	if err != nil {
+		return err
+	}
+
+	transfers, scArgs, err := wallet_manager.DEXAddLiquidityPayload(pair.Pair, amount1, amount2, randomAddr)
+	if err != nil {
+		return err
+	}
+
+	build_tx_modal.Instance.Open(build_tx_modal.TxPayload{
+		Transfers:  transfers,
+		Ringsize:   2,
+		SCArgs:     scArgs,
+		TokensInfo: []*wallet_manager.Token{pair.Token1, pair.Token2},
+	})
+
+	return nil
+}
+
+func (p *PageDEXAddLiquidity) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	{
+		state := p.animationEnter.Update(gtx)
+		if state.Active {
+			defer animation.TransformX(gtx, state.Value).Push(gtx.Ops).Pop()
+		}
+	}
+
+	{
+		state := p.animationLeave.Update(gtx)
+		if state.Active {
+			defer animation.TransformX(gtx, state.Value).Push(gtx.Ops).Pop()
+		}
+
+		if state.Finished {
+			p.isActive = false
+			op.InvalidateOp{}.Add(gtx.Ops)
+		}
+	}
+
+	if p.buttonAdd.Clicked() {
+		go func() {
+			p.buttonAdd.SetLoading(true)
+			err := p.submitForm()
+			p.buttonAdd.SetLoading(false)
+			if err != nil {
+				notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+				notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+			}
+		}()
+	}
+
+	if build_tx_modal.Instance.TxSent() {
+		p.setAmounts("", "")
+	}
+
+	p.fillAmounts()
+
+	wallet := wallet_manager.OpenedWallet
+	pair := p.pair.Pair
+	amount1, _ := parseDEXAmount(p.pair.Token1, p.txtAmount1.Value())
+	_, shares := pair.CalcAddLiquidity(amount1, false)
+
+	widgets := []layout.Widget{}
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		text := lang.Translate("Both assets are added at the pool ratio and you receive pair shares in return. Shares can be removed at any time for your part of the pool.")
+		if pair.Liquidity1 == 0 || pair.Liquidity2 == 0 {
+			text = lang.Translate("The pool is empty. The amounts you add set the price of the pair.")
+		}
+
+		lbl := material.Label(th, unit.Sp(16), text)
+		lbl.Color = theme.Current.TextMuteColor
+		return lbl.Layout(gtx)
+	})
+
+	tokens := []struct {
+		token *wallet_manager.Token
+		txt   *prefabs.TextField
+	}{
+		{p.pair.Token1, p.txtAmount1},
+		{p.pair.Token2, p.txtAmount2},
+	}
+
+	for i := range tokens {
+		item := tokens[i]
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return item.txt.Layout(gtx, th, dexTokenSymbol(item.token), "")
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					text := lang.Translate("Balance: ?")
+					balance, known := wallet.GetBalance(item.token.GetHash())
+					if known {
+						text = fmt.Sprintf("%s %s", lang.Translate("Balance:"), dexFormatAmount(item.token, balance))
+					}
+
+					lbl := material.Label(th, unit.Sp(14), text)
+					lbl.Color = theme.Current.TextMuteColor
+					return lbl.Layout(gtx)
+				}),
+			)
+		})
+	}
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				lbl := material.Label(th, unit.Sp(16), lang.Translate("Shares"))
+				lbl.Color = theme.Current.TextMuteColor
+				return lbl.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				value := fmt.Sprintf("%d (%.2f%%)", shares, pair.CalcOwnership(shares))
+				lbl := material.Label(th, unit.Sp(16), value)
+				lbl.Font.Weight = font.Bold
+				return lbl.Layout(gtx)
+			}),
+		)
+	})
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		p.buttonAdd.Style.Colors = theme.Current.ButtonPrimaryColors
+		p.buttonAdd.Text = lang.Translate("ADD LIQUIDITY")
+		return p.buttonAdd.Layout(gtx, th)
+	})
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		return layout.Spacer{Height: unit.Dp(30)}.Layout(gtx)
+	})
+
+	listStyle := material.List(th, p.list)
+	listStyle.AnchorStrategy = material.Overlay
+
+	return listStyle.Layout(gtx, len(widgets), func(gtx layout.Context, index int) layout.Dimensions {
+		return layout.Inset{
+			Top: unit.Dp(0), Bottom: unit.Dp(20),
+			Left: unit.Dp(30), Right: unit.Dp(30),
+		}.Layout(gtx, widgets[index])
+	})
+}
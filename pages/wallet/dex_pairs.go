@@ -0,0 +1,511 @@
+package page_wallet
+
+import (
+	"fmt"
+	"image"
+	"strings"
+	"sync"
+
+	"gioui.org/font"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/secretsystems/secret-wallet/animation"
+	"github.com/secretsystems/secret-wallet/app_icons"
+	"github.com/secretsystems/secret-wallet/app_instance"
+	"github.com/secretsystems/secret-wallet/components"
+	"github.com/secretsystems/secret-wallet/containers/confirm_modal"
+	"github.com/secretsystems/secret-wallet/containers/listselect_modal"
+	"github.com/secretsystems/secret-wallet/containers/notification_modals"
+	"github.com/secretsystems/secret-wallet/lang"
+	"github.com/secretsystems/secret-wallet/prefabs"
+	"github.com/secretsystems/secret-wallet/router"
+	"github.com/secretsystems/secret-wallet/sc/dex_sc"
+	"github.com/secretsystems/secret-wallet/theme"
+	"github.com/secretsystems/secret-wallet/utils"
+	"github.com/secretsystems/secret-wallet/wallet_manager"
+	"github.com/tanema/gween"
+	"github.com/tanema/gween/ease"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+type PageDEXPairs struct {
+	isActive bool
+
+	animationEnter *animation.Animation
+	animationLeave *animation.Animation
+	txtSCID        *prefabs.TextField
+	buttonAdd      *components.Button
+	buttonRefresh  *components.Button
+
+	pairItems []*DEXPairItem
+	pairsLock sync.Mutex
+	loading   bool
+
+	list *widget.List
+}
+
+var _ router.Page = &PageDEXPairs{}
+
+func NewPageDEXPairs() *PageDEXPairs {
+	animationEnter := animation.NewAnimation(false, gween.NewSequence(
+		gween.New(-1, 0, .25, ease.Linear),
+	))
+
+	animationLeave := animation.NewAnimation(false, gween.NewSequence(
+		gween.New(0, -1, .25, ease.Linear),
+	))
+
+	addIcon, _ := widget.NewIcon(icons.ContentAdd)
+	loadingIcon, _ := widget.NewIcon(icons.NavigationRefresh)
+	buttonAdd := components.NewButton(components.ButtonStyle{
+		Rounded:     components.UniformRounded(unit.Dp(5)),
+		Icon:        addIcon,
+		TextSize:    unit.Sp(14),
+		IconGap:     unit.Dp(10),
+		Inset:       layout.UniformInset(unit.Dp(10)),
+		Animation:   components.NewButtonAnimationDefault(),
+		LoadingIcon: loadingIcon,
+	})
+	buttonAdd.Label.Alignment = text.Middle
+	buttonAdd.Style.Font.Weight = font.Bold
+
+	buttonRefresh := components.NewButton(components.ButtonStyle{
+		Icon:      loadingIcon,
+		Animation: components.NewButtonAnimationScale(.98),
+	})
+
+	list := new(widget.List)
+	list.Axis = layout.Vertical
+
+	return &PageDEXPairs{
+		animationEnter: animationEnter,
+		animationLeave: animationLeave,
+		txtSCID:        prefabs.NewTextField(),
+		buttonAdd:      buttonAdd,
+		buttonRefresh:  buttonRefresh,
+		list:           list,
+	}
+}
+
+func (p *PageDEXPairs) IsActive() bool {
+	return p.isActive
+}
+
+func (p *PageDEXPairs) Enter() {
+	p.isActive = true
+
+	if !page_instance.header.IsHistory(PAGE_DEX_PAIRS) {
+		p.animationEnter.Start()
+		p.animationLeave.Reset()
+	}
+
+	page_instance.header.Title = func() string {
+		return lang.Translate("DEX Pairs")
+	}
+
+	page_instance.header.Subtitle = nil
+	go p.Load()
+}
+
+func (p *PageDEXPairs) Leave() {
+	p.animationLeave.Start()
+	p.animationEnter.Reset()
+}
+
+// the liquidity is always loaded from the daemon - asset tokens come from the cache
+func (p *PageDEXPairs) Load() {
+	wallet := wallet_manager.OpenedWallet
+	p.loading = true
+	defer func() {
+		p.loading = false
+		app_instance.Window.Invalidate()
+	}()
+
+	scIds, err := wallet.GetDEXPairs()
+	if err != nil {
+		notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+		notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		return
+	}
+
+	var pairItems []*DEXPairItem
+	for _, scId := range scIds {
+		pairItems = append(pairItems, NewDEXPairItem(LoadDEXPair(scId)))
+	}
+
+	p.pairsLock.Lock()
+	p.pairItems = pairItems
+	p.pairsLock.Unlock()
+}
+
+func (p *PageDEXPairs) addPair() error {
+	scId := strings.TrimSpace(p.txtSCID.Value())
+	if len(scId) != 64 || crypto.HashHexToHash(scId).String() != scId {
+		return fmt.Errorf("invalid smart contract id")
+	}
+
+	pair, err := wallet_manager.GetDEXPair(scId)
+	if err != nil {
+		return err
+	}
+
+	wallet := wallet_manager.OpenedWallet
+	err = wallet.StoreDEXPair(pair.SCID)
+	if err != nil {
+		return err
+	}
+
+	p.txtSCID.SetValue("")
+	p.Load()
+	return nil
+}
+
+func (p *PageDEXPairs) removePair(scId string) {
+	yesChan := confirm_modal.Instance.Open(confirm_modal.ConfirmText{})
+	for yes := range yesChan {
+		if !yes {
+			continue
+		}
+
+		wallet := wallet_manager.OpenedWallet
+		err := wallet.DelDEXPair(scId)
+		if err != nil {
+			notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+			notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+			return
+		}
+
+		notification_modals.SuccessInstance.SetText(lang.Translate("Success"), lang.Translate("Pair removed."))
+		notification_modals.SuccessInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		p.Load()
+	}
+}
+
+func (p *PageDEXPairs) openMenu(pair DEXPair) {
+	swapIcon, _ := widget.NewIcon(app_icons.Swap)
+	addIcon, _ := widget.NewIcon(icons.ContentAddCircleOutline)
+	remIcon, _ := widget.NewIcon(icons.ContentRemoveCircleOutline)
+	deleteIcon, _ := widget.NewIcon(icons.ActionDelete)
+
+	var items []*listselect_modal.SelectListItem
+	if pair.Err == nil {
+		items = append(items, listselect_modal.NewSelectListItem("swap",
+			listselect_modal.NewItemText(swapIcon, lang.Translate("Swap")).Layout,
+		))
+
+		items = append(items, listselect_modal.NewSelectListItem("add_liquidity",
+			listselect_modal.NewItemText(addIcon, lang.Translate("Add liquidity")).Layout,
+		))
+
+		items = append(items, listselect_modal.NewSelectListItem("rem_liquidity",
+			listselect_modal.NewItemText(remIcon, lang.Translate("Remove liquidity")).Layout,
+		))
+	}
+
+	items = append(items, listselect_modal.NewSelectListItem("remove_pair",
+		listselect_modal.NewItemText(deleteIcon, lang.Translate("Remove pair")).Layout,
+	))
+
+	keyChan := listselect_modal.Instance.Open(items)
+	for key := range keyChan {
+		switch key {
+		case "swap":
+			page_instance.pageDEXSwap.SetPair(pair)
+			page_instance.pageRouter.SetCurrent(PAGE_DEX_SWAP)
+			page_instance.header.AddHistory(PAGE_DEX_SWAP)
+		case "add_liquidity":
+			page_instance.pageDEXAddLiquidity.SetPair(pair)
+			page_instance.pageRouter.SetCurrent(PAGE_DEX_ADD_LIQUIDITY)
+			page_instance.header.AddHistory(PAGE_DEX_ADD_LIQUIDITY)
+		case "rem_liquidity":
+			page_instance.pageDEXRemLiquidity.SetPair(pair)
+			page_instance.pageRouter.SetCurrent(PAGE_DEX_REM_LIQUIDITY)
+			page_instance.header.AddHistory(PAGE_DEX_REM_LIQUIDITY)
+		case "remove_pair":
+			p.removePair(pair.SCID)
+		}
+	}
+}
+
+func (p *PageDEXPairs) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	{
+		state := p.animationEnter.Update(gtx)
+		if state.Active {
+			defer animation.TransformX(gtx, state.Value).Push(gtx.Ops).Pop()
+		}
+	}
+
+	{
+		state := p.animationLeave.Update(gtx)
+		if state.Active {
+			defer animation.TransformX(gtx, state.Value).Push(gtx.Ops).Pop()
+		}
+
+		if state.Finished {
+			p.isActive = false
+			op.InvalidateOp{}.Add(gtx.Ops)
+		}
+	}
+
+	if p.buttonAdd.Clicked() {
+		go func() {
+			p.buttonAdd.SetLoading(true)
+			err := p.addPair()
+			p.buttonAdd.SetLoading(false)
+			if err != nil {
+				notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+				notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+			} else {
+				notification_modals.SuccessInstance.SetText(lang.Translate("Success"), lang.Translate("Pair added."))
+				notification_modals.SuccessInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+			}
+		}()
+	}
+
+	if p.buttonRefresh.Clicked() && !p.loading {
+		go p.Load()
+	}
+
+	p.pairsLock.Lock()
+	pairItems := p.pairItems
+	p.pairsLock.Unlock()
+
+	for _, item := range pairItems {
+		if item.clickable.Clicked() {
+			go p.openMenu(item.pair)
+		}
+	}
+
+	widgets := []layout.Widget{}
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		lbl := material.Label(th, unit.Sp(16), lang.Translate("Pairs you traded with are listed automatically. Add another pair with its smart contract id."))
+		lbl.Color = theme.Current.TextMuteColor
+		return lbl.Layout(gtx)
+	})
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		return p.txtSCID.Layout(gtx, th, lang.Translate("Pair SCID"), "")
+	})
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		p.buttonAdd.Style.Colors = theme.Current.ButtonPrimaryColors
+		p.buttonAdd.Text = lang.Translate("ADD PAIR")
+		return p.buttonAdd.Layout(gtx, th)
+	})
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		return prefabs.Divider(gtx, unit.Dp(5))
+	})
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				lbl := material.Label(th, unit.Sp(18), lang.Translate("Pairs"))
+				lbl.Font.Weight = font.Bold
+				return lbl.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				gtx.Constraints.Max.X = gtx.Dp(25)
+				gtx.Constraints.Max.Y = gtx.Dp(25)
+				p.buttonRefresh.Style.Colors = theme.Current.ButtonIconPrimaryColors
+				return p.buttonRefresh.Layout(gtx, th)
+			}),
+		)
+	})
+
+	if len(pairItems) > 0 {
+		for i := range pairItems {
+			item := pairItems[i]
+			widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+				return item.Layout(gtx, th)
+			})
+		}
+	} else if !p.loading {
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(16), lang.Translate("You don't have any pairs. Add a pair to swap or provide liquidity."))
+			lbl.Color = theme.Current.TextMuteColor
+			return lbl.Layout(gtx)
+		})
+	}
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		return layout.Spacer{Height: unit.Dp(30)}.Layout(gtx)
+	})
+
+	listStyle := material.List(th, p.list)
+	listStyle.AnchorStrategy = material.Overlay
+
+	return listStyle.Layout(gtx, len(widgets), func(gtx layout.Context, index int) layout.Dimensions {
+		return layout.Inset{
+			Top: unit.Dp(0), Bottom: unit.Dp(20),
+			Left: unit.Dp(30), Right: unit.Dp(30),
+		}.Layout(gtx, widgets[index])
+	})
+}
+
+// pair with the token info of both assets
+type DEXPair struct {
+	SCID   string
+	Pair   *dex_sc.Pair
+	Token1 *wallet_manager.Token
+	Token2 *wallet_manager.Token
+	Err    error
+}
+
+func LoadDEXPair(scId string) (pair DEXPair) {
+	pair.SCID = scId
+	pair.Pair, pair.Err = wallet_manager.GetDEXPair(scId)
+	if pair.Err != nil {
+		return
+	}
+
+	pair.Token1, pair.Err = wallet_manager.GetTokenBySCID(pair.Pair.Asset1)
+	if pair.Err != nil {
+		return
+	}
+
+	pair.Token2, pair.Err = wallet_manager.GetTokenBySCID(pair.Pair.Asset2)
+	return
+}
+
+func dexTokenSymbol(token *wallet_manager.Token) string {
+	if token.Symbol.String != "" {
+		return token.Symbol.String
+	}
+
+	return token.Name
+}
+
+func dexFormatAmount(token *wallet_manager.Token, amount uint64) string {
+	value := utils.ShiftNumber{Number: amount, Decimals: int(token.Decimals)}
+	return fmt.Sprintf("%s %s", value.Format(), dexTokenSymbol(token))
+}
+
+// the pair fee is in basis points
+func dexFormatFee(fee uint64) string {
+	return fmt.Sprintf("%.2f%%", float64(fee)/100)
+}
+
+type dexInfoRow struct {
+	title string
+	value string
+}
+
+func layoutDEXInfoRows(gtx layout.Context, th *material.Theme, rows []dexInfoRow) layout.Dimensions {
+	var children []layout.FlexChild
+	for i := range rows {
+		row := rows[i]
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					lbl := material.Label(th, unit.Sp(16), row.title)
+					lbl.Color = theme.Current.TextMuteColor
+					return lbl.Layout(gtx)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					lbl := material.Label(th, unit.Sp(16), row.value)
+					lbl.Font.Weight = font.Bold
+					return lbl.Layout(gtx)
+				}),
+			)
+		}))
+	}
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+}
+
+type DEXPairItem struct {
+	pair      DEXPair
+	clickable *widget.Clickable
+}
+
+func NewDEXPairItem(pair DEXPair) *DEXPairItem {
+	return &DEXPairItem{
+		pair:      pair,
+		clickable: new(widget.Clickable),
+	}
+}
+
+func (item *DEXPairItem) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	r := op.Record(gtx.Ops)
+	dims := item.clickable.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		r := op.Record(gtx.Ops)
+		dims := layout.UniformInset(unit.Dp(15)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			pair := item.pair
+			if pair.Err != nil {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						lbl := material.Label(th, unit.Sp(16), utils.ReduceTxId(pair.SCID))
+						lbl.Font.Weight = font.Bold
+						return lbl.Layout(gtx)
+					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						lbl := material.Label(th, unit.Sp(14), pair.Err.Error())
+						lbl.Color = theme.Current.TextMuteColor
+						return lbl.Layout(gtx)
+					}),
+				)
+			}
+
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+						layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+							name := fmt.Sprintf("%s / %s", dexTokenSymbol(pair.Token1), dexTokenSymbol(pair.Token2))
+							lbl := material.Label(th, unit.Sp(18), name)
+							lbl.Font.Weight = font.Bold
+							return lbl.Layout(gtx)
+						}),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							lbl := material.Label(th, unit.Sp(14), dexFormatFee(pair.Pair.Fee))
+							lbl.Color = theme.Current.TextMuteColor
+							return lbl.Layout(gtx)
+						}),
+					)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					liquidity := fmt.Sprintf("%s - %s",
+						dexFormatAmount(pair.Token1, pair.Pair.Liquidity1),
+						dexFormatAmount(pair.Token2, pair.Pair.Liquidity2),
+					)
+					lbl := material.Label(th, unit.Sp(14), liquidity)
+					lbl.Color = theme.Current.TextMuteColor
+					return lbl.Layout(gtx)
+				}),
+			)
+		})
+		c := r.Stop()
+
+		if item.clickable.Hovered() {
+			pointer.CursorPointer.Add(gtx.Ops)
+			paint.FillShape(gtx.Ops, theme.Current.ListItemHoverBgColor,
+				clip.UniformRRect(
+					image.Rectangle{Max: image.Pt(dims.Size.X, dims.Size.Y)},
+					gtx.Dp(10),
+				).Op(gtx.Ops),
+			)
+		}
+
+		c.Add(gtx.Ops)
+		return dims
+	})
+	c := r.Stop()
+
+	paint.FillShape(gtx.Ops, theme.Current.ListBgColor,
+		clip.UniformRRect(
+			image.Rectangle{Max: dims.Size},
+			gtx.Dp(10),
+		).Op(gtx.Ops))
+
+	c.Add(gtx.Ops)
+	return dims
+}
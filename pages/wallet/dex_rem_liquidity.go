@@ -0,0 +1,274 @@
+package page_wallet
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/secretsystems/secret-wallet/animation"
+	"github.com/secretsystems/secret-wallet/components"
+	"github.com/secretsystems/secret-wallet/containers/build_tx_modal"
+	"github.com/secretsystems/secret-wallet/containers/notification_modals"
+	"github.com/secretsystems/secret-wallet/lang"
+	"github.com/secretsystems/secret-wallet/prefabs"
+	"github.com/secretsystems/secret-wallet/router"
+	"github.com/secretsystems/secret-wallet/theme"
+	"github.com/secretsystems/secret-wallet/wallet_manager"
+	"github.com/tanema/gween"
+	"github.com/tanema/gween/ease"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+type PageDEXRemLiquidity struct {
+	isActive bool
+
+	animationEnter *animation.Animation
+	animationLeave *animation.Animation
+	txtShares      *prefabs.TextField
+	buttonMax      *components.Button
+	buttonRemove   *components.Button
+
+	pair DEXPair
+
+	list *widget.List
+}
+
+var _ router.Page = &PageDEXRemLiquidity{}
+
+func NewPageDEXRemLiquidity() *PageDEXRemLiquidity {
+	animationEnter := animation.NewAnimation(false, gween.NewSequence(
+		gween.New(1, 0, .25, ease.Linear),
+	))
+
+	animationLeave := animation.NewAnimation(false, gween.NewSequence(
+		gween.New(0, 1, .25, ease.Linear),
+	))
+
+	remIcon, _ := widget.NewIcon(icons.ContentRemoveCircleOutline)
+	loadingIcon, _ := widget.NewIcon(icons.NavigationRefresh)
+	buttonRemove := components.NewButton(components.ButtonStyle{
+		Rounded:     components.UniformRounded(unit.Dp(5)),
+		Icon:        remIcon,
+		TextSize:    unit.Sp(14),
+		IconGap:     unit.Dp(10),
+		Inset:       layout.UniformInset(unit.Dp(10)),
+		Animation:   components.NewButtonAnimationDefault(),
+		LoadingIcon: loadingIcon,
+	})
+	buttonRemove.Label.Alignment = text.Middle
+	buttonRemove.Style.Font.Weight = font.Bold
+
+	buttonMax := components.NewButton(components.ButtonStyle{
+		Rounded:   components.UniformRounded(unit.Dp(5)),
+		TextSize:  unit.Sp(14),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+	})
+	buttonMax.Label.Alignment = text.Middle
+	buttonMax.Style.Font.Weight = font.Bold
+
+	list := new(widget.List)
+	list.Axis = layout.Vertical
+
+	return &PageDEXRemLiquidity{
+		animationEnter: animationEnter,
+		animationLeave: animationLeave,
+		txtShares:      prefabs.NewNumberTextField(),
+		buttonMax:      buttonMax,
+		buttonRemove:   buttonRemove,
+		list:           list,
+	}
+}
+
+func (p *PageDEXRemLiquidity) IsActive() bool {
+	return p.isActive
+}
+
+func (p *PageDEXRemLiquidity) SetPair(pair DEXPair) {
+	p.pair = pair
+	p.txtShares.SetValue("")
+}
+
+func (p *PageDEXRemLiquidity) Enter() {
+	p.isActive = true
+
+	if !page_instance.header.IsHistory(PAGE_DEX_REM_LIQUIDITY) {
+		p.animationEnter.Start()
+		p.animationLeave.Reset()
+	}
+
+	page_instance.header.Title = func() string {
+		return lang.Translate("Remove liquidity")
+	}
+
+	page_instance.header.Subtitle = func(gtx layout.Context, th *material.Theme) layout.Dimensions {
+		lbl := material.Label(th, unit.Sp(16), p.pair.Pair.Symbol)
+		lbl.Color = theme.Current.TextMuteColor
+		return lbl.Layout(gtx)
+	}
+
+	// the shares are the pair token
+	wallet := wallet_manager.OpenedWallet
+	wallet.Memory.TokenAdd(crypto.HashHexToHash(p.pair.SCID))
+}
+
+func (p *PageDEXRemLiquidity) Leave() {
+	p.animationLeave.Start()
+	p.animationEnter.Reset()
+}
+
+func (p *PageDEXRemLiquidity) shares() (uint64, error) {
+	shares, err := strconv.ParseUint(strings.TrimSpace(p.txtShares.Value()), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid shares")
+	}
+
+	return shares, nil
+}
+
+func (p *PageDEXRemLiquidity) submitForm() error {
+	shares, err := p.shares()
+	if err != nil {
+		return err
+	}
+
+	wallet := wallet_manager.OpenedWallet
+	balance, known := wallet.GetBalance(crypto.HashHexToHash(p.pair.SCID))
+	if known && shares > balance {
+		return fmt.Errorf("you only have %d shares", balance)
+	}
+
+	pair := LoadDEXPair(p.pair.SCID)
+	if pair.Err != nil {
+		return pair.Err
+	}
+
+	p.pair = pair
+	randomAddr, err := wallet.GetRandomAddress(crypto.ZEROHASH)
+	if err != nil {
+		return err
+	}
+
+	transfers, scArgs, err := wallet_manager.DEXRemoveLiquidityPayload(pair.Pair, shares, randomAddr)
+	if err != nil {
+		return err
+	}
+
+	build_tx_modal.Instance.Open(build_tx_modal.TxPayload{
+		Transfers:  transfers,
+		Ringsize:   2,
+		SCArgs:     scArgs,
+		TokensInfo: []*wallet_manager.Token{pair.Token1, pair.Token2},
+	})
+
+	return nil
+}
+
+func (p *PageDEXRemLiquidity) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	{
+		state := p.animationEnter.Update(gtx)
+		if state.Active {
+			defer animation.TransformX(gtx, state.Value).Push(gtx.Ops).Pop()
+		}
+	}
+
+	{
+		state := p.animationLeave.Update(gtx)
+		if state.Active {
+			defer animation.TransformX(gtx, state.Value).Push(gtx.Ops).Pop()
+		}
+
+		if state.Finished {
+			p.isActive = false
+			op.InvalidateOp{}.Add(gtx.Ops)
+		}
+	}
+
+	wallet := wallet_manager.OpenedWallet
+	balance, known := wallet.GetBalance(crypto.HashHexToHash(p.pair.SCID))
+
+	if p.buttonMax.Clicked() && known {
+		p.txtShares.SetValue(fmt.Sprint(balance))
+	}
+
+	if p.buttonRemove.Clicked() {
+		go func() {
+			p.buttonRemove.SetLoading(true)
+			err := p.submitForm()
+			p.buttonRemove.SetLoading(false)
+			if err != nil {
+				notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+				notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+			}
+		}()
+	}
+
+	if build_tx_modal.Instance.TxSent() {
+		p.txtShares.SetValue("")
+	}
+
+	pair := p.pair.Pair
+	shares, _ := p.shares()
+
+	widgets := []layout.Widget{}
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		text := lang.Translate("Shares: ?")
+		if known {
+			text = fmt.Sprintf("%s %d (%.2f%%)", lang.Translate("Shares:"), balance, pair.CalcOwnership(balance))
+		}
+
+		lbl := material.Label(th, unit.Sp(16), text)
+		lbl.Color = theme.Current.TextMuteColor
+		return lbl.Layout(gtx)
+	})
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Horizontal, Alignment: layout.End}.Layout(gtx,
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				return p.txtShares.Layout(gtx, th, lang.Translate("Shares"), "")
+			}),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				p.buttonMax.Style.Colors = theme.Current.ButtonSecondaryColors
+				p.buttonMax.Text = lang.Translate("MAX")
+				return p.buttonMax.Layout(gtx, th)
+			}),
+		)
+	})
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		return layoutDEXInfoRows(gtx, th, []dexInfoRow{
+			{dexTokenSymbol(p.pair.Token1), dexFormatAmount(p.pair.Token1, pair.CalcShare(shares, false))},
+			{dexTokenSymbol(p.pair.Token2), dexFormatAmount(p.pair.Token2, pair.CalcShare(shares, true))},
+		})
+	})
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		p.buttonRemove.Style.Colors = theme.Current.ButtonPrimaryColors
+		p.buttonRemove.Text = lang.Translate("REMOVE LIQUIDITY")
+		return p.buttonRemove.Layout(gtx, th)
+	})
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		return layout.Spacer{Height: unit.Dp(30)}.Layout(gtx)
+	})
+
+	listStyle := material.List(th, p.list)
+	listStyle.AnchorStrategy = material.Overlay
+
+	return listStyle.Layout(gtx, len(widgets), func(gtx layout.Context, index int) layout.Dimensions {
+		return layout.Inset{
+			Top: unit.Dp(0), Bottom: unit.Dp(20),
+			Left: unit.Dp(30), Right: unit.Dp(30),
+		}.Layout(gtx, widgets[index])
+	})
+}
@@ -0,0 +1,227 @@
+package page_wallet
+
+import (
+	"fmt"
+	"strings"
+
+	"gioui.org/font"
+	"gioui.org/io/clipboard"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/secretsystems/secret-wallet/animation"
+	"github.com/secretsystems/secret-wallet/app_instance"
+	"github.com/secretsystems/secret-wallet/components"
+	"github.com/secretsystems/secret-wallet/containers/notification_modals"
+	"github.com/secretsystems/secret-wallet/lang"
+	"github.com/secretsystems/secret-wallet/router"
+	"github.com/secretsystems/secret-wallet/sc/dex_sc"
+	"github.com/secretsystems/secret-wallet/theme"
+	"github.com/secretsystems/secret-wallet/wallet_manager"
+	"github.com/tanema/gween"
+	"github.com/tanema/gween/ease"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+// Bridging in happens on the Ethereum side. The trustees mint the wrapped
+// token to the Dero address given with the deposit so there is no Dero tx to build here.
+type PageDEXSCBridgeIn struct {
+	isActive bool
+
+	animationEnter *animation.Animation
+	animationLeave *animation.Animation
+	addrEditor     *widget.Editor
+	buttonCopyAddr *components.Button
+	alertBox       *AlertBox
+
+	token    *wallet_manager.Token
+	dexToken *dex_sc.Token
+	loadErr  error
+
+	list *widget.List
+}
+
+var _ router.Page = &PageDEXSCBridgeIn{}
+
+func NewPageDEXSCBridgeIn() *PageDEXSCBridgeIn {
+	animationEnter := animation.NewAnimation(false, gween.NewSequence(
+		gween.New(1, 0, .25, ease.Linear),
+	))
+
+	animationLeave := animation.NewAnimation(false, gween.NewSequence(
+		gween.New(0, 1, .25, ease.Linear),
+	))
+
+	addrEditor := new(widget.Editor)
+	addrEditor.WrapPolicy = text.WrapGraphemes
+	addrEditor.ReadOnly = true
+
+	copyIcon, _ := widget.NewIcon(icons.ContentContentCopy)
+	buttonCopyAddr := components.NewButton(components.ButtonStyle{
+		Rounded:   components.UniformRounded(unit.Dp(5)),
+		Icon:      copyIcon,
+		TextSize:  unit.Sp(14),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+	})
+	buttonCopyAddr.Label.Alignment = text.Middle
+	buttonCopyAddr.Style.Font.Weight = font.Bold
+
+	list := new(widget.List)
+	list.Axis = layout.Vertical
+
+	return &PageDEXSCBridgeIn{
+		animationEnter: animationEnter,
+		animationLeave: animationLeave,
+		addrEditor:     addrEditor,
+		buttonCopyAddr: buttonCopyAddr,
+		alertBox:       NewAlertBox(),
+		list:           list,
+	}
+}
+
+func (p *PageDEXSCBridgeIn) IsActive() bool {
+	return p.isActive
+}
+
+func (p *PageDEXSCBridgeIn) SetToken(token *wallet_manager.Token) {
+	p.token = token
+	p.dexToken = nil
+	p.loadErr = nil
+}
+
+func (p *PageDEXSCBridgeIn) Enter() {
+	p.isActive = true
+
+	if !page_instance.header.IsHistory(PAGE_DEX_SC_BRIDGE_IN) {
+		p.animationEnter.Start()
+		p.animationLeave.Reset()
+	}
+
+	page_instance.header.Title = func() string {
+		return lang.Translate("Bridge in")
+	}
+
+	page_instance.header.Subtitle = func(gtx layout.Context, th *material.Theme) layout.Dimensions {
+		lbl := material.Label(th, unit.Sp(16), dexTokenSymbol(p.token))
+		lbl.Color = theme.Current.TextMuteColor
+		return lbl.Layout(gtx)
+	}
+
+	wallet := wallet_manager.OpenedWallet
+	p.addrEditor.SetText(wallet.Memory.GetAddress().String())
+
+	go func() {
+		p.dexToken, p.loadErr = wallet_manager.GetDEXToken(p.token.SCID)
+		app_instance.Window.Invalidate()
+	}()
+}
+
+func (p *PageDEXSCBridgeIn) Leave() {
+	p.animationLeave.Start()
+	p.animationEnter.Reset()
+}
+
+func (p *PageDEXSCBridgeIn) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	{
+		state := p.animationEnter.Update(gtx)
+		if state.Active {
+			defer animation.TransformX(gtx, state.Value).Push(gtx.Ops).Pop()
+		}
+	}
+
+	{
+		state := p.animationLeave.Update(gtx)
+		if state.Active {
+			defer animation.TransformX(gtx, state.Value).Push(gtx.Ops).Pop()
+		}
+
+		if state.Finished {
+			p.isActive = false
+			op.InvalidateOp{}.Add(gtx.Ops)
+		}
+	}
+
+	if p.buttonCopyAddr.Clicked() {
+		clipboard.WriteOp{
+			Text: p.addrEditor.Text(),
+		}.Add(gtx.Ops)
+		notification_modals.InfoInstance.SetText(lang.Translate("Clipboard"), lang.Translate("Addr copied to clipboard"))
+		notification_modals.InfoInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+	}
+
+	dexToken := p.dexToken
+	widgets := []layout.Widget{}
+
+	if p.loadErr != nil {
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			return p.alertBox.Layout(gtx, th, p.loadErr.Error())
+		})
+	}
+
+	if dexToken != nil {
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			text := lang.Translate("Deposit {0} on Ethereum through the bridge and use this wallet address as the Dero destination. The trustees mint {1} to your address once {2} of {3} have confirmed the deposit.")
+			text = strings.NewReplacer(
+				"{0}", dexToken.NativeSymbol,
+				"{1}", dexToken.Symbol,
+				"{2}", fmt.Sprint(dexToken.Quorum),
+				"{3}", fmt.Sprint(dexToken.NumTrustees),
+			).Replace(text)
+
+			lbl := material.Label(th, unit.Sp(16), text)
+			lbl.Color = theme.Current.TextMuteColor
+			return lbl.Layout(gtx)
+		})
+
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			status := lang.Translate("Open")
+			if !dexToken.BridgeOpen {
+				status = lang.Translate("Closed")
+			}
+
+			return layoutDEXInfoRows(gtx, th, []dexInfoRow{
+				{lang.Translate("Bridge"), status},
+				{lang.Translate("Native asset"), dexToken.NativeSymbol},
+				{lang.Translate("Native decimals"), fmt.Sprint(dexToken.NativeDecimals)},
+			})
+		})
+
+		if !dexToken.BridgeOpen {
+			widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+				return p.alertBox.Layout(gtx, th, lang.Translate("The bridge is closed. Do not deposit until it opens again."))
+			})
+		}
+	}
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		editor := material.Editor(th, p.addrEditor, "")
+		editor.TextSize = unit.Sp(16)
+		editor.Font.Weight = font.Bold
+		return editor.Layout(gtx)
+	})
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		p.buttonCopyAddr.Text = lang.Translate("COPY")
+		p.buttonCopyAddr.Style.Colors = theme.Current.ButtonPrimaryColors
+		return p.buttonCopyAddr.Layout(gtx, th)
+	})
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		return layout.Spacer{Height: unit.Dp(30)}.Layout(gtx)
+	})
+
+	listStyle := material.List(th, p.list)
+	listStyle.AnchorStrategy = material.Overlay
+
+	return listStyle.Layout(gtx, len(widgets), func(gtx layout.Context, index int) layout.Dimensions {
+		return layout.Inset{
+			Top: unit.Dp(0), Bottom: unit.Dp(20),
+			Left: unit.Dp(30), Right: unit.Dp(30),
+		}.Layout(gtx, widgets[index])
+	})
+}
@@ -0,0 +1,294 @@
+package page_wallet
+
+import (
+	"fmt"
+	"strings"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/secretsystems/secret-wallet/animation"
+	"github.com/secretsystems/secret-wallet/app_icons"
+	"github.com/secretsystems/secret-wallet/app_instance"
+	"github.com/secretsystems/secret-wallet/components"
+	"github.com/secretsystems/secret-wallet/containers/build_tx_modal"
+	"github.com/secretsystems/secret-wallet/containers/notification_modals"
+	"github.com/secretsystems/secret-wallet/lang"
+	"github.com/secretsystems/secret-wallet/prefabs"
+	"github.com/secretsystems/secret-wallet/router"
+	"github.com/secretsystems/secret-wallet/sc/dex_sc"
+	"github.com/secretsystems/secret-wallet/theme"
+	"github.com/secretsystems/secret-wallet/wallet_manager"
+	"github.com/tanema/gween"
+	"github.com/tanema/gween/ease"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+type PageDEXSCBridgeOut struct {
+	isActive bool
+
+	animationEnter *animation.Animation
+	animationLeave *animation.Animation
+	txtAmount      *prefabs.TextField
+	txtEthAddr     *prefabs.TextField
+	buttonBridge   *components.Button
+	alertBox       *AlertBox
+
+	token    *wallet_manager.Token
+	dexToken *dex_sc.Token
+	loadErr  error
+
+	list *widget.List
+}
+
+var _ router.Page = &PageDEXSCBridgeOut{}
+
+func NewPageDEXSCBridgeOut() *PageDEXSCBridgeOut {
+	animationEnter := animation.NewAnimation(false, gween.NewSequence(
+		gween.New(1, 0, .25, ease.Linear),
+	))
+
+	animationLeave := animation.NewAnimation(false, gween.NewSequence(
+		gween.New(0, 1, .25, ease.Linear),
+	))
+
+	ethereumIcon, _ := widget.NewIcon(app_icons.Ethereum)
+	loadingIcon, _ := widget.NewIcon(icons.NavigationRefresh)
+	buttonBridge := components.NewButton(components.ButtonStyle{
+		Rounded:     components.UniformRounded(unit.Dp(5)),
+		Icon:        ethereumIcon,
+		TextSize:    unit.Sp(14),
+		IconGap:     unit.Dp(10),
+		Inset:       layout.UniformInset(unit.Dp(10)),
+		Animation:   components.NewButtonAnimationDefault(),
+		LoadingIcon: loadingIcon,
+	})
+	buttonBridge.Label.Alignment = text.Middle
+	buttonBridge.Style.Font.Weight = font.Bold
+
+	list := new(widget.List)
+	list.Axis = layout.Vertical
+
+	return &PageDEXSCBridgeOut{
+		animationEnter: animationEnter,
+		animationLeave: animationLeave,
+		txtAmount:      prefabs.NewNumberTextField(),
+		txtEthAddr:     prefabs.NewTextField(),
+		buttonBridge:   buttonBridge,
+		alertBox:       NewAlertBox(),
+		list:           list,
+	}
+}
+
+func (p *PageDEXSCBridgeOut) IsActive() bool {
+	return p.isActive
+}
+
+func (p *PageDEXSCBridgeOut) SetToken(token *wallet_manager.Token) {
+	p.token = token
+	p.dexToken = nil
+	p.loadErr = nil
+	p.txtAmount.SetValue("")
+	p.txtEthAddr.SetValue("")
+}
+
+func (p *PageDEXSCBridgeOut) Enter() {
+	p.isActive = true
+
+	if !page_instance.header.IsHistory(PAGE_DEX_SC_BRIDGE_OUT) {
+		p.animationEnter.Start()
+		p.animationLeave.Reset()
+	}
+
+	page_instance.header.Title = func() string {
+		return lang.Translate("Bridge out")
+	}
+
+	page_instance.header.Subtitle = func(gtx layout.Context, th *material.Theme) layout.Dimensions {
+		lbl := material.Label(th, unit.Sp(16), dexTokenSymbol(p.token))
+		lbl.Color = theme.Current.TextMuteColor
+		return lbl.Layout(gtx)
+	}
+
+	go p.loadToken()
+}
+
+func (p *PageDEXSCBridgeOut) Leave() {
+	p.animationLeave.Start()
+	p.animationEnter.Reset()
+}
+
+func (p *PageDEXSCBridgeOut) loadToken() error {
+	dexToken, err := wallet_manager.GetDEXToken(p.token.SCID)
+	p.dexToken = dexToken
+	p.loadErr = err
+	app_instance.Window.Invalidate()
+	return err
+}
+
+// the token is loaded again so a bridge closed in the meantime is refused
+func (p *PageDEXSCBridgeOut) submitForm() error {
+	amount, err := parseDEXAmount(p.token, p.txtAmount.Value())
+	if err != nil {
+		return err
+	}
+
+	ethAddr := strings.TrimSpace(p.txtEthAddr.Value())
+	err = dex_sc.ValidateEthAddress(ethAddr)
+	if err != nil {
+		return err
+	}
+
+	err = p.loadToken()
+	if err != nil {
+		return err
+	}
+
+	wallet := wallet_manager.OpenedWallet
+	randomAddr, err := wallet.GetRandomAddress(p.token.GetHash())
+	if err != nil {
+		return err
+	}
+
+	transfers, scArgs, err := wallet_manager.DEXBridgeOutPayload(p.dexToken, amount, ethAddr, randomAddr)
+	if err != nil {
+		return err
+	}
+
+	txType := wallet_manager.OUTGOING_TX_DEX_BRIDGE_OUT
+	build_tx_modal.Instance.Open(build_tx_modal.TxPayload{
+		Transfers:  transfers,
+		Ringsize:   2,
+		SCArgs:     scArgs,
+		TokensInfo: []*wallet_manager.Token{p.token},
+		TxType:     &txType,
+	})
+
+	return nil
+}
+
+func (p *PageDEXSCBridgeOut) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	{
+		state := p.animationEnter.Update(gtx)
+		if state.Active {
+			defer animation.TransformX(gtx, state.Value).Push(gtx.Ops).Pop()
+		}
+	}
+
+	{
+		state := p.animationLeave.Update(gtx)
+		if state.Active {
+			defer animation.TransformX(gtx, state.Value).Push(gtx.Ops).Pop()
+		}
+
+		if state.Finished {
+			p.isActive = false
+			op.InvalidateOp{}.Add(gtx.Ops)
+		}
+	}
+
+	if p.buttonBridge.Clicked() {
+		go func() {
+			p.buttonBridge.SetLoading(true)
+			err := p.submitForm()
+			p.buttonBridge.SetLoading(false)
+			if err != nil {
+				notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+				notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+			}
+		}()
+	}
+
+	if build_tx_modal.Instance.TxSent() {
+		p.txtAmount.SetValue("")
+		p.txtEthAddr.SetValue("")
+	}
+
+	wallet := wallet_manager.OpenedWallet
+	dexToken := p.dexToken
+	widgets := []layout.Widget{}
+
+	if p.loadErr != nil {
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			return p.alertBox.Layout(gtx, th, p.loadErr.Error())
+		})
+	}
+
+	if dexToken != nil {
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			text := strings.Replace(lang.Translate("Burn your {} on Dero and receive the native asset to your Ethereum address once the bridge trustees approve it."), "{}", dexToken.Symbol, -1)
+			lbl := material.Label(th, unit.Sp(16), text)
+			lbl.Color = theme.Current.TextMuteColor
+			return lbl.Layout(gtx)
+		})
+
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			status := lang.Translate("Open")
+			if !dexToken.BridgeOpen {
+				status = lang.Translate("Closed")
+			}
+
+			return layoutDEXInfoRows(gtx, th, []dexInfoRow{
+				{lang.Translate("Bridge"), status},
+				{lang.Translate("Native asset"), dexToken.NativeSymbol},
+				{lang.Translate("Bridge fee"), dexFormatAmount(wallet_manager.DeroToken(), dexToken.BridgeFee)},
+				{lang.Translate("Trustees"), fmt.Sprintf("%d / %d", dexToken.Quorum, dexToken.NumTrustees)},
+			})
+		})
+
+		if !dexToken.BridgeOpen {
+			widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+				return p.alertBox.Layout(gtx, th, lang.Translate("The bridge is closed. Try again later."))
+			})
+		}
+	}
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return p.txtAmount.Layout(gtx, th, lang.Translate("Amount"), "")
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				text := lang.Translate("Balance: ?")
+				balance, known := wallet.GetBalance(p.token.GetHash())
+				if known {
+					text = fmt.Sprintf("%s %s", lang.Translate("Balance:"), dexFormatAmount(p.token, balance))
+				}
+
+				lbl := material.Label(th, unit.Sp(14), text)
+				lbl.Color = theme.Current.TextMuteColor
+				return lbl.Layout(gtx)
+			}),
+		)
+	})
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		return p.txtEthAddr.Layout(gtx, th, lang.Translate("Ethereum address"), "0x...")
+	})
+
+	if dexToken != nil && dexToken.BridgeOpen {
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			p.buttonBridge.Style.Colors = theme.Current.ButtonPrimaryColors
+			p.buttonBridge.Text = lang.Translate("BRIDGE OUT")
+			return p.buttonBridge.Layout(gtx, th)
+		})
+	}
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		return layout.Spacer{Height: unit.Dp(30)}.Layout(gtx)
+	})
+
+	listStyle := material.List(th, p.list)
+	listStyle.AnchorStrategy = material.Overlay
+
+	return listStyle.Layout(gtx, len(widgets), func(gtx layout.Context, index int) layout.Dimensions {
+		return layout.Inset{
+			Top: unit.Dp(0), Bottom: unit.Dp(20),
+			Left: unit.Dp(30), Right: unit.Dp(30),
+		}.Layout(gtx, widgets[index])
+	})
+}
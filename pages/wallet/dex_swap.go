@@ -0,0 +1,382 @@
+package page_wallet
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/secretsystems/secret-wallet/animation"
+	"github.com/secretsystems/secret-wallet/app_icons"
+	"github.com/secretsystems/secret-wallet/app_instance"
+	"github.com/secretsystems/secret-wallet/components"
+	"github.com/secretsystems/secret-wallet/containers/build_tx_modal"
+	"github.com/secretsystems/secret-wallet/containers/notification_modals"
+	"github.com/secretsystems/secret-wallet/lang"
+	"github.com/secretsystems/secret-wallet/prefabs"
+	"github.com/secretsystems/secret-wallet/router"
+	"github.com/secretsystems/secret-wallet/theme"
+	"github.com/secretsystems/secret-wallet/utils"
+	"github.com/secretsystems/secret-wallet/wallet_manager"
+	"github.com/tanema/gween"
+	"github.com/tanema/gween/ease"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+const DEX_DEFAULT_MAX_SLIPPAGE = "1"
+
+type PageDEXSwap struct {
+	isActive bool
+
+	animationEnter  *animation.Animation
+	animationLeave  *animation.Animation
+	txtAmount       *prefabs.TextField
+	txtMaxSlippage  *prefabs.TextField
+	buttonDirection *components.Button
+	buttonRefresh   *components.Button
+	buttonSwap      *components.Button
+	alertBox        *AlertBox
+
+	pair    DEXPair
+	reverse bool // sell asset2 for asset1
+
+	list *widget.List
+}
+
+var _ router.Page = &PageDEXSwap{}
+
+func NewPageDEXSwap() *PageDEXSwap {
+	animationEnter := animation.NewAnimation(false, gween.NewSequence(
+		gween.New(1, 0, .25, ease.Linear),
+	))
+
+	animationLeave := animation.NewAnimation(false, gween.NewSequence(
+		gween.New(0, 1, .25, ease.Linear),
+	))
+
+	swapIcon, _ := widget.NewIcon(app_icons.Swap)
+	loadingIcon, _ := widget.NewIcon(icons.NavigationRefresh)
+	buttonSwap := components.NewButton(components.ButtonStyle{
+		Rounded:     components.UniformRounded(unit.Dp(5)),
+		Icon:        swapIcon,
+		TextSize:    unit.Sp(14),
+		IconGap:     unit.Dp(10),
+		Inset:       layout.UniformInset(unit.Dp(10)),
+		Animation:   components.NewButtonAnimationDefault(),
+		LoadingIcon: loadingIcon,
+	})
+	buttonSwap.Label.Alignment = text.Middle
+	buttonSwap.Style.Font.Weight = font.Bold
+
+	directionIcon, _ := widget.NewIcon(icons.ActionSwapVert)
+	buttonDirection := components.NewButton(components.ButtonStyle{
+		Icon:      directionIcon,
+		Animation: components.NewButtonAnimationScale(.98),
+	})
+
+	buttonRefresh := components.NewButton(components.ButtonStyle{
+		Icon:      loadingIcon,
+		Animation: components.NewButtonAnimationScale(.98),
+	})
+
+	txtMaxSlippage := prefabs.NewNumberTextField()
+	txtMaxSlippage.SetValue(DEX_DEFAULT_MAX_SLIPPAGE)
+
+	list := new(widget.List)
+	list.Axis = layout.Vertical
+
+	return &PageDEXSwap{
+		animationEnter:  animationEnter,
+		animationLeave:  animationLeave,
+		txtAmount:       prefabs.NewNumberTextField(),
+		txtMaxSlippage:  txtMaxSlippage,
+		buttonDirection: buttonDirection,
+		buttonRefresh:   buttonRefresh,
+		buttonSwap:      buttonSwap,
+		alertBox:        NewAlertBox(),
+		list:            list,
+	}
+}
+
+func (p *PageDEXSwap) IsActive() bool {
+	return p.isActive
+}
+
+func (p *PageDEXSwap) SetPair(pair DEXPair) {
+	p.pair = pair
+	p.reverse = false
+	p.txtAmount.SetValue("")
+}
+
+func (p *PageDEXSwap) Enter() {
+	p.isActive = true
+
+	if !page_instance.header.IsHistory(PAGE_DEX_SWAP) {
+		p.animationEnter.Start()
+		p.animationLeave.Reset()
+	}
+
+	page_instance.header.Title = func() string {
+		return lang.Translate("Swap")
+	}
+
+	page_instance.header.Subtitle = func(gtx layout.Context, th *material.Theme) layout.Dimensions {
+		lbl := material.Label(th, unit.Sp(16), p.pair.Pair.Symbol)
+		lbl.Color = theme.Current.TextMuteColor
+		return lbl.Layout(gtx)
+	}
+
+	// track both assets so the balances are synced
+	wallet := wallet_manager.OpenedWallet
+	wallet.Memory.TokenAdd(crypto.HashHexToHash(p.pair.Pair.Asset1))
+	wallet.Memory.TokenAdd(crypto.HashHexToHash(p.pair.Pair.Asset2))
+}
+
+func (p *PageDEXSwap) Leave() {
+	p.animationLeave.Start()
+	p.animationEnter.Reset()
+}
+
+func (p *PageDEXSwap) refreshPair() error {
+	pair := LoadDEXPair(p.pair.SCID)
+	if pair.Err != nil {
+		return pair.Err
+	}
+
+	p.pair = pair
+	app_instance.Window.Invalidate()
+	return nil
+}
+
+// token sold and token received
+func (p *PageDEXSwap) tokens() (*wallet_manager.Token, *wallet_manager.Token) {
+	if p.reverse {
+		return p.pair.Token2, p.pair.Token1
+	}
+
+	return p.pair.Token1, p.pair.Token2
+}
+
+func (p *PageDEXSwap) amount() (uint64, error) {
+	tokenIn, _ := p.tokens()
+	amount := utils.ShiftNumber{Decimals: int(tokenIn.Decimals)}
+	err := amount.Parse(strings.TrimSpace(p.txtAmount.Value()))
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount")
+	}
+
+	return amount.Number, nil
+}
+
+func parseMaxSlippage(value string) (float64, error) {
+	maxSlippage, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || maxSlippage < 0 || maxSlippage > 100 {
+		return 0, fmt.Errorf("invalid max slippage")
+	}
+
+	return maxSlippage, nil
+}
+
+// the pair is loaded again so the guard uses the latest liquidity
+func (p *PageDEXSwap) submitForm() error {
+	amount, err := p.amount()
+	if err != nil {
+		return err
+	}
+
+	maxSlippage, err := parseMaxSlippage(p.txtMaxSlippage.Value())
+	if err != nil {
+		return err
+	}
+
+	err = p.refreshPair()
+	if err != nil {
+		return err
+	}
+
+	tokenIn, tokenOut := p.tokens()
+	wallet := wallet_manager.OpenedWallet
+	randomAddr, err := wallet.GetRandomAddress(tokenIn.GetHash())
+	if err != nil {
+		return err
+	}
+
+	transfers, scArgs, err := wallet_manager.DEXSwapPayload(p.pair.Pair, amount, p.reverse, maxSlippage, randomAddr)
+	if err != nil {
+		return err
+	}
+
+	build_tx_modal.Instance.Open(build_tx_modal.TxPayload{
+		Transfers:  transfers,
+		Ringsize:   2,
+		SCArgs:     scArgs,
+		TokensInfo: []*wallet_manager.Token{tokenIn, tokenOut},
+	})
+
+	return nil
+}
+
+func (p *PageDEXSwap) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	{
+		state := p.animationEnter.Update(gtx)
+		if state.Active {
+			defer animation.TransformX(gtx, state.Value).Push(gtx.Ops).Pop()
+		}
+	}
+
+	{
+		state := p.animationLeave.Update(gtx)
+		if state.Active {
+			defer animation.TransformX(gtx, state.Value).Push(gtx.Ops).Pop()
+		}
+
+		if state.Finished {
+			p.isActive = false
+			op.InvalidateOp{}.Add(gtx.Ops)
+		}
+	}
+
+	if p.buttonSwap.Clicked() {
+		go func() {
+			p.buttonSwap.SetLoading(true)
+			err := p.submitForm()
+			p.buttonSwap.SetLoading(false)
+			if err != nil {
+				notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+				notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+			}
+		}()
+	}
+
+	if p.buttonDirection.Clicked() {
+		p.reverse = !p.reverse
+		p.txtAmount.SetValue("")
+	}
+
+	if p.buttonRefresh.Clicked() {
+		go func() {
+			err := p.refreshPair()
+			if err != nil {
+				notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+				notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+			}
+		}()
+	}
+
+	if build_tx_modal.Instance.TxSent() {
+		p.txtAmount.SetValue("")
+		go p.refreshPair()
+	}
+
+	wallet := wallet_manager.OpenedWallet
+	pair := p.pair.Pair
+	tokenIn, tokenOut := p.tokens()
+
+	// live quote of the amount typed
+	amount, _ := p.amount()
+	receive, fee, slip := pair.CalcSwap(amount, p.reverse)
+	maxSlippage, maxSlippageErr := parseMaxSlippage(p.txtMaxSlippage.Value())
+
+	widgets := []layout.Widget{}
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				name := fmt.Sprintf("%s > %s", dexTokenSymbol(tokenIn), dexTokenSymbol(tokenOut))
+				lbl := material.Label(th, unit.Sp(20), name)
+				lbl.Font.Weight = font.Bold
+				return lbl.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				gtx.Constraints.Max.X = gtx.Dp(30)
+				gtx.Constraints.Max.Y = gtx.Dp(30)
+				p.buttonDirection.Style.Colors = theme.Current.ButtonIconPrimaryColors
+				return p.buttonDirection.Layout(gtx, th)
+			}),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				gtx.Constraints.Max.X = gtx.Dp(30)
+				gtx.Constraints.Max.Y = gtx.Dp(30)
+				p.buttonRefresh.Style.Colors = theme.Current.ButtonIconPrimaryColors
+				return p.buttonRefresh.Layout(gtx, th)
+			}),
+		)
+	})
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		liquidity := fmt.Sprintf("%s %s - %s | %s %s",
+			lang.Translate("Liquidity"),
+			dexFormatAmount(p.pair.Token1, pair.Liquidity1),
+			dexFormatAmount(p.pair.Token2, pair.Liquidity2),
+			lang.Translate("Fee"),
+			dexFormatFee(pair.Fee),
+		)
+		lbl := material.Label(th, unit.Sp(14), liquidity)
+		lbl.Color = theme.Current.TextMuteColor
+		return lbl.Layout(gtx)
+	})
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return p.txtAmount.Layout(gtx, th, lang.Translate("Amount"), "")
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				text := lang.Translate("Balance: ?")
+				balance, known := wallet.GetBalance(tokenIn.GetHash())
+				if known {
+					text = fmt.Sprintf("%s %s", lang.Translate("Balance:"), dexFormatAmount(tokenIn, balance))
+				}
+
+				lbl := material.Label(th, unit.Sp(14), text)
+				lbl.Color = theme.Current.TextMuteColor
+				return lbl.Layout(gtx)
+			}),
+		)
+	})
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		return p.txtMaxSlippage.Layout(gtx, th, lang.Translate("Max slippage (%)"), DEX_DEFAULT_MAX_SLIPPAGE)
+	})
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		return layoutDEXInfoRows(gtx, th, []dexInfoRow{
+			{lang.Translate("You receive"), dexFormatAmount(tokenOut, receive)},
+			{lang.Translate("Fee"), dexFormatAmount(tokenOut, fee)},
+			{lang.Translate("Slippage"), fmt.Sprintf("%.2f%%", slip)},
+		})
+	})
+
+	if maxSlippageErr == nil && slip > maxSlippage {
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			return p.alertBox.Layout(gtx, th, lang.Translate("The slippage is higher than your max slippage. Lower the amount or raise the max slippage."))
+		})
+	}
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		p.buttonSwap.Style.Colors = theme.Current.ButtonPrimaryColors
+		p.buttonSwap.Text = lang.Translate("SWAP")
+		return p.buttonSwap.Layout(gtx, th)
+	})
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		return layout.Spacer{Height: unit.Dp(30)}.Layout(gtx)
+	})
+
+	listStyle := material.List(th, p.list)
+	listStyle.AnchorStrategy = material.Overlay
+
+	return listStyle.Layout(gtx, len(widgets), func(gtx layout.Context, index int) layout.Dimensions {
+		return layout.Inset{
+			Top: unit.Dp(0), Bottom: unit.Dp(20),
+			Left: unit.Dp(30), Right: unit.Dp(30),
+		}.Layout(gtx, widgets[index])
+	})
+}
@@ -0,0 +1,590 @@
+package page_wallet
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gioui.org/font"
+	"gioui.org/io/clipboard"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/deroproject/derohe/dvm"
+	"github.com/secretsystems/secret-wallet/animation"
+	"github.com/secretsystems/secret-wallet/app_instance"
+	"github.com/secretsystems/secret-wallet/components"
+	"github.com/secretsystems/secret-wallet/containers/build_tx_modal"
+	"github.com/secretsystems/secret-wallet/containers/listselect_modal"
+	"github.com/secretsystems/secret-wallet/containers/notification_modals"
+	"github.com/secretsystems/secret-wallet/lang"
+	"github.com/secretsystems/secret-wallet/prefabs"
+	"github.com/secretsystems/secret-wallet/router"
+	"github.com/secretsystems/secret-wallet/sc"
+	"github.com/secretsystems/secret-wallet/theme"
+	"github.com/secretsystems/secret-wallet/utils"
+	"github.com/secretsystems/secret-wallet/wallet_manager"
+	"github.com/tanema/gween"
+	"github.com/tanema/gween/ease"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+type G45MintExtraField struct {
+	param dvm.Variable
+	txt   *prefabs.TextField
+}
+
+type PageG45Mint struct {
+	isActive bool
+
+	animationEnter *animation.Animation
+	animationLeave *animation.Animation
+
+	buttonType     *components.Button
+	buttonLoadCode *components.Button
+	buttonMint     *components.Button
+	buttonRegister *components.Button
+	buttonCopySCID *components.Button
+
+	txtName        *prefabs.TextField
+	txtSymbol      *prefabs.TextField
+	txtImage       *prefabs.TextField
+	txtDescription *prefabs.TextField
+	txtAttributes  *prefabs.TextField
+	txtDecimals    *prefabs.TextField
+	txtMaxSupply   *prefabs.TextField
+	txtCollection  *prefabs.TextField
+	extraFields    []*G45MintExtraField
+
+	scType sc.SCType
+	code   string
+
+	pendingMint *wallet_manager.G45Mint
+	registering bool
+	minted      *wallet_manager.G45Mint
+	mintedId    string
+
+	list *widget.List
+}
+
+var _ router.Page = &PageG45Mint{}
+
+func NewPageG45Mint() *PageG45Mint {
+	animationEnter := animation.NewAnimation(false, gween.NewSequence(
+		gween.New(1, 0, .25, ease.Linear),
+	))
+
+	animationLeave := animation.NewAnimation(false, gween.NewSequence(
+		gween.New(0, 1, .25, ease.Linear),
+	))
+
+	newButton := func(icon *widget.Icon, loading bool) *components.Button {
+		style := components.ButtonStyle{
+			Rounded:   components.UniformRounded(unit.Dp(5)),
+			Icon:      icon,
+			TextSize:  unit.Sp(14),
+			IconGap:   unit.Dp(10),
+			Inset:     layout.UniformInset(unit.Dp(10)),
+			Animation: components.NewButtonAnimationDefault(),
+		}
+
+		if loading {
+			style.LoadingIcon, _ = widget.NewIcon(icons.NavigationRefresh)
+		}
+
+		button := components.NewButton(style)
+		button.Label.Alignment = text.Middle
+		button.Style.Font.Weight = font.Bold
+		return button
+	}
+
+	typeIcon, _ := widget.NewIcon(icons.NavigationArrowDropDown)
+	codeIcon, _ := widget.NewIcon(icons.FileFileUpload)
+	mintIcon, _ := widget.NewIcon(icons.ImagePalette)
+	registerIcon, _ := widget.NewIcon(icons.ImageCollections)
+	copyIcon, _ := widget.NewIcon(icons.ContentContentCopy)
+
+	txtDescription := prefabs.NewTextField()
+	txtDescription.Editor().SingleLine = false
+	txtAttributes := prefabs.NewTextField()
+	txtAttributes.Editor().SingleLine = false
+
+	list := new(widget.List)
+	list.Axis = layout.Vertical
+
+	return &PageG45Mint{
+		animationEnter: animationEnter,
+		animationLeave: animationLeave,
+
+		buttonType:     newButton(typeIcon, false),
+		buttonLoadCode: newButton(codeIcon, false),
+		buttonMint:     newButton(mintIcon, true),
+		buttonRegister: newButton(registerIcon, true),
+		buttonCopySCID: newButton(copyIcon, false),
+
+		txtName:        prefabs.NewTextField(),
+		txtSymbol:      prefabs.NewTextField(),
+		txtImage:       prefabs.NewTextField(),
+		txtDescription: txtDescription,
+		txtAttributes:  txtAttributes,
+		txtDecimals:    prefabs.NewNumberTextField(),
+		txtMaxSupply:   prefabs.NewNumberTextField(),
+		txtCollection:  prefabs.NewTextField(),
+
+		scType: sc.G45_NFT_TYPE,
+		list:   list,
+	}
+}
+
+func (p *PageG45Mint) IsActive() bool {
+	return p.isActive
+}
+
+func (p *PageG45Mint) Enter() {
+	p.isActive = true
+
+	if !page_instance.header.IsHistory(PAGE_G45_MINT) {
+		p.animationEnter.Start()
+		p.animationLeave.Reset()
+	}
+
+	page_instance.header.Title = func() string {
+		return lang.Translate("Mint token")
+	}
+
+	page_instance.header.Subtitle = func(gtx layout.Context, th *material.Theme) layout.Dimensions {
+		lbl := material.Label(th, unit.Sp(16), g45MintTypeName(p.scType))
+		lbl.Color = theme.Current.TextMuteColor
+		return lbl.Layout(gtx)
+	}
+}
+
+func (p *PageG45Mint) Leave() {
+	p.animationLeave.Start()
+	p.animationEnter.Reset()
+}
+
+func g45MintTypeName(scType sc.SCType) string {
+	switch scType {
+	case sc.G45_NFT_TYPE:
+		return lang.Translate("NFT (G45-NFT)")
+	case sc.G45_AT_TYPE:
+		return lang.Translate("Asset token (G45-AT)")
+	case sc.G45_FAT_TYPE:
+		return lang.Translate("Fixed asset token (G45-FAT)")
+	case sc.G45_C_TYPE:
+		return lang.Translate("Collection (G45-C)")
+	}
+
+	return string(scType)
+}
+
+// the template must be loaded again for another type since the code hash is checked
+func (p *PageG45Mint) setType(scType sc.SCType) {
+	p.scType = scType
+	p.code = ""
+	p.extraFields = nil
+}
+
+func (p *PageG45Mint) loadCode() error {
+	file, err := app_instance.Explorer.ChooseFile(".bas")
+	if err != nil {
+		return err
+	}
+
+	reader := utils.ReadCloser{ReadCloser: file}
+	data, err := reader.ReadAll()
+	if err != nil {
+		return err
+	}
+
+	mint := wallet_manager.G45Mint{SCType: p.scType, Code: string(data)}
+	if sc.CheckType(mint.Code) != p.scType {
+		return fmt.Errorf("the code is not a %s template", p.scType)
+	}
+
+	params, err := mint.ExtraParams()
+	if err != nil {
+		return err
+	}
+
+	var extraFields []*G45MintExtraField
+	for _, param := range params {
+		txt := prefabs.NewTextField()
+		if param.Type == dvm.Uint64 {
+			txt = prefabs.NewNumberTextField()
+		}
+
+		extraFields = append(extraFields, &G45MintExtraField{param: param, txt: txt})
+	}
+
+	p.code = mint.Code
+	p.extraFields = extraFields
+	return nil
+}
+
+func (p *PageG45Mint) mintParams() (mint wallet_manager.G45Mint, err error) {
+	mint = wallet_manager.G45Mint{
+		SCType:      p.scType,
+		Code:        p.code,
+		Name:        strings.TrimSpace(p.txtName.Value()),
+		Image:       strings.TrimSpace(p.txtImage.Value()),
+		Description: strings.TrimSpace(p.txtDescription.Value()),
+		Collection:  strings.TrimSpace(p.txtCollection.Value()),
+		Extra:       make(map[string]string),
+	}
+
+	if p.code == "" {
+		err = fmt.Errorf("load the contract template first")
+		return
+	}
+
+	switch p.scType {
+	case sc.G45_NFT_TYPE:
+		attributes := strings.TrimSpace(p.txtAttributes.Value())
+		if attributes != "" {
+			err = json.Unmarshal([]byte(attributes), &mint.Attributes)
+			if err != nil {
+				err = fmt.Errorf("attributes must be a json object")
+				return
+			}
+		}
+	case sc.G45_AT_TYPE, sc.G45_FAT_TYPE:
+		mint.Symbol = strings.TrimSpace(p.txtSymbol.Value())
+
+		mint.Decimals, err = strconv.ParseUint(p.txtDecimals.Value(), 10, 64)
+		if err != nil {
+			err = fmt.Errorf("invalid decimals")
+			return
+		}
+
+		// the max supply is entered with the decimals
+		maxSupply := utils.ShiftNumber{Decimals: int(mint.Decimals)}
+		err = maxSupply.Parse(p.txtMaxSupply.Value())
+		if err != nil {
+			err = fmt.Errorf("invalid max supply")
+			return
+		}
+
+		mint.MaxSupply = maxSupply.Number
+	case sc.G45_C_TYPE:
+		mint.Collection = ""
+	}
+
+	for _, field := range p.extraFields {
+		mint.Extra[field.param.Name] = field.txt.Value()
+	}
+
+	return
+}
+
+func (p *PageG45Mint) submitMint() error {
+	mint, err := p.mintParams()
+	if err != nil {
+		return err
+	}
+
+	scArgs, err := mint.InstallArgs()
+	if err != nil {
+		return err
+	}
+
+	p.pendingMint = &mint
+	p.registering = false
+	build_tx_modal.Instance.Open(build_tx_modal.TxPayload{
+		Ringsize: 2,
+		SCArgs:   scArgs,
+	})
+
+	return nil
+}
+
+func (p *PageG45Mint) submitRegister() error {
+	wallet := wallet_manager.OpenedWallet
+	scArgs, err := wallet.G45RegisterAssetArgs(p.minted.Collection, p.mintedId)
+	if err != nil {
+		return err
+	}
+
+	p.pendingMint = nil
+	p.registering = true
+	build_tx_modal.Instance.Open(build_tx_modal.TxPayload{
+		Ringsize: 2,
+		SCArgs:   scArgs,
+	})
+
+	return nil
+}
+
+// collections are not tokens so only the assets are added to the tokens table
+func (p *PageG45Mint) onTxSent() error {
+	if p.registering {
+		p.registering = false
+		notification_modals.SuccessInstance.SetText(lang.Translate("Success"), lang.Translate("The asset will be added to the collection once the transaction is confirmed."))
+		notification_modals.SuccessInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		return nil
+	}
+
+	mint := p.pendingMint
+	tx := build_tx_modal.Instance.SentTx()
+	if mint == nil || tx == nil {
+		return nil
+	}
+
+	p.pendingMint = nil
+	p.minted = mint
+	p.mintedId = tx.GetHash().String()
+
+	if mint.SCType != sc.G45_C_TYPE {
+		token := mint.Token(p.mintedId)
+		currentFolder := page_instance.pageSCFolders.currentFolder
+		if currentFolder != nil {
+			token.FolderId = sql.NullInt64{Int64: currentFolder.ID, Valid: true}
+		}
+
+		wallet := wallet_manager.OpenedWallet
+		err := wallet.InsertToken(token)
+		if err != nil {
+			return err
+		}
+
+		page_instance.pageSCFolders.Load()
+	}
+
+	notification_modals.SuccessInstance.SetText(lang.Translate("Success"), lang.Translate("The contract will be available once the transaction is confirmed."))
+	notification_modals.SuccessInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+	return nil
+}
+
+func (p *PageG45Mint) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	{
+		state := p.animationEnter.Update(gtx)
+		if state.Active {
+			defer animation.TransformX(gtx, state.Value).Push(gtx.Ops).Pop()
+		}
+	}
+
+	{
+		state := p.animationLeave.Update(gtx)
+		if state.Active {
+			defer animation.TransformX(gtx, state.Value).Push(gtx.Ops).Pop()
+		}
+
+		if state.Finished {
+			p.isActive = false
+			op.InvalidateOp{}.Add(gtx.Ops)
+		}
+	}
+
+	if p.buttonType.Clicked() {
+		go func() {
+			var items []*listselect_modal.SelectListItem
+			for _, scType := range []sc.SCType{sc.G45_NFT_TYPE, sc.G45_AT_TYPE, sc.G45_FAT_TYPE, sc.G45_C_TYPE} {
+				items = append(items, listselect_modal.NewSelectListItem(string(scType),
+					listselect_modal.NewItemText(nil, g45MintTypeName(scType)).Layout,
+				))
+			}
+
+			keyChan := listselect_modal.Instance.Open(items)
+			for sKey := range keyChan {
+				p.setType(sc.SCType(sKey))
+				app_instance.Window.Invalidate()
+			}
+		}()
+	}
+
+	if p.buttonLoadCode.Clicked() {
+		go func() {
+			err := p.loadCode()
+			if err != nil {
+				notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+				notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+			}
+			app_instance.Window.Invalidate()
+		}()
+	}
+
+	if p.buttonMint.Clicked() {
+		go func() {
+			p.buttonMint.SetLoading(true)
+			err := p.submitMint()
+			p.buttonMint.SetLoading(false)
+			if err != nil {
+				notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+				notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+			}
+		}()
+	}
+
+	if p.buttonRegister.Clicked() {
+		go func() {
+			p.buttonRegister.SetLoading(true)
+			err := p.submitRegister()
+			p.buttonRegister.SetLoading(false)
+			if err != nil {
+				notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+				notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+			}
+		}()
+	}
+
+	if p.buttonCopySCID.Clicked() {
+		clipboard.WriteOp{
+			Text: p.mintedId,
+		}.Add(gtx.Ops)
+		notification_modals.InfoInstance.SetText(lang.Translate("Clipboard"), lang.Translate("SCID copied to clipboard"))
+		notification_modals.InfoInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+	}
+
+	if build_tx_modal.Instance.TxSent() {
+		err := p.onTxSent()
+		if err != nil {
+			notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+			notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		}
+	}
+
+	widgets := []layout.Widget{}
+
+	if p.mintedId != "" {
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			return layoutDEXInfoRows(gtx, th, []dexInfoRow{
+				{lang.Translate("Minted"), p.minted.Name},
+				{lang.Translate("SCID"), utils.ReduceTxId(p.mintedId)},
+			})
+		})
+
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			var childs []layout.FlexChild
+			childs = append(childs, layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				p.buttonCopySCID.Text = lang.Translate("COPY SCID")
+				p.buttonCopySCID.Style.Colors = theme.Current.ButtonSecondaryColors
+				return p.buttonCopySCID.Layout(gtx, th)
+			}))
+
+			if p.minted.Collection != "" {
+				childs = append(childs,
+					layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						p.buttonRegister.Text = lang.Translate("ADD TO COLLECTION")
+						p.buttonRegister.Style.Colors = theme.Current.ButtonPrimaryColors
+						return p.buttonRegister.Layout(gtx, th)
+					}),
+				)
+			}
+
+			return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, childs...)
+		})
+
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			return prefabs.Divider(gtx, 5)
+		})
+	}
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		p.buttonType.Text = g45MintTypeName(p.scType)
+		p.buttonType.Style.Colors = theme.Current.ButtonSecondaryColors
+		return p.buttonType.Layout(gtx, th)
+	})
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				p.buttonLoadCode.Text = lang.Translate("LOAD TEMPLATE")
+				p.buttonLoadCode.Style.Colors = theme.Current.ButtonSecondaryColors
+				return p.buttonLoadCode.Layout(gtx, th)
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(5)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				text := lang.Translate("Load the standard .bas file of this type. Modified templates are not recognized by the wallet.")
+				if p.code != "" {
+					text = lang.Translate("Template loaded and verified.")
+				}
+
+				lbl := material.Label(th, unit.Sp(14), text)
+				lbl.Color = theme.Current.TextMuteColor
+				return lbl.Layout(gtx)
+			}),
+		)
+	})
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		return p.txtName.Layout(gtx, th, lang.Translate("Name"), "")
+	})
+
+	switch p.scType {
+	case sc.G45_AT_TYPE, sc.G45_FAT_TYPE:
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			return p.txtSymbol.Layout(gtx, th, lang.Translate("Symbol"), "")
+		})
+
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					return p.txtDecimals.Layout(gtx, th, lang.Translate("Decimals"), "")
+				}),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					return p.txtMaxSupply.Layout(gtx, th, lang.Translate("Max supply"), "")
+				}),
+			)
+		})
+	case sc.G45_NFT_TYPE, sc.G45_C_TYPE:
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			return p.txtDescription.Layout(gtx, th, lang.Translate("Description"), "")
+		})
+	}
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		title := lang.Translate("Image")
+		if p.scType == sc.G45_C_TYPE {
+			title = lang.Translate("Backdrop image")
+		}
+
+		return p.txtImage.Layout(gtx, th, title, "ipfs://...")
+	})
+
+	if p.scType == sc.G45_NFT_TYPE {
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			return p.txtAttributes.Layout(gtx, th, lang.Translate("Attributes"), `{"trait": "value"}`)
+		})
+	}
+
+	if p.scType != sc.G45_C_TYPE {
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			return p.txtCollection.Layout(gtx, th, lang.Translate("Collection"), lang.Translate("Optional collection SCID"))
+		})
+	}
+
+	for i := range p.extraFields {
+		field := p.extraFields[i]
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			return field.txt.Layout(gtx, th, field.param.Name, "")
+		})
+	}
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		p.buttonMint.Text = lang.Translate("MINT")
+		p.buttonMint.Style.Colors = theme.Current.ButtonPrimaryColors
+		return p.buttonMint.Layout(gtx, th)
+	})
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		return layout.Spacer{Height: unit.Dp(30)}.Layout(gtx)
+	})
+
+	listStyle := material.List(th, p.list)
+	listStyle.AnchorStrategy = material.Overlay
+
+	return listStyle.Layout(gtx, len(widgets), func(gtx layout.Context, index int) layout.Dimensions {
+		return layout.Inset{
+			Top: unit.Dp(0), Bottom: unit.Dp(20),
+			Left: unit.Dp(30), Right: unit.Dp(30),
+		}.Layout(gtx, widgets[index])
+	})
+}
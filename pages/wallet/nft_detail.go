@@ -0,0 +1,533 @@
+package page_wallet
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"net/url"
+	"sort"
+	"sync"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"gioui.org/x/browser"
+	"github.com/secretsystems/secret-wallet/animation"
+	"github.com/secretsystems/secret-wallet/app_instance"
+	"github.com/secretsystems/secret-wallet/components"
+	"github.com/secretsystems/secret-wallet/containers/notification_modals"
+	"github.com/secretsystems/secret-wallet/lang"
+	"github.com/secretsystems/secret-wallet/multi_fetch"
+	"github.com/secretsystems/secret-wallet/prefabs"
+	"github.com/secretsystems/secret-wallet/router"
+	"github.com/secretsystems/secret-wallet/sc/g45_sc"
+	"github.com/secretsystems/secret-wallet/theme"
+	"github.com/secretsystems/secret-wallet/wallet_manager"
+	"github.com/tanema/gween"
+	"github.com/tanema/gween/ease"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+type NFTMediaImage struct {
+	image   *components.Image
+	loading bool
+	err     error
+}
+
+type NFTCollectionLink struct {
+	name   string
+	url    string
+	button *components.Button
+}
+
+type PageNFTDetail struct {
+	isActive bool
+
+	animationEnter *animation.Animation
+	animationLeave *animation.Animation
+
+	buttonPrev *components.Button
+	buttonNext *components.Button
+	buttonOpen *components.Button
+	buttonSave *components.Button
+
+	token      *wallet_manager.Token
+	metadata   g45_sc.NFTMetadata
+	loadErr    error
+	media      []wallet_manager.NFTMedia
+	mediaIndex int
+	images     map[string]*NFTMediaImage
+	imagesLock sync.Mutex
+
+	attributes []wallet_manager.NFTAttribute
+	infoRows   []*prefabs.InfoRow
+
+	collectionId       string
+	collectionMetadata *g45_sc.CollectionMetadata
+	collectionLinks    []*NFTCollectionLink
+
+	list *widget.List
+}
+
+var _ router.Page = &PageNFTDetail{}
+
+func NewPageNFTDetail() *PageNFTDetail {
+	animationEnter := animation.NewAnimation(false, gween.NewSequence(
+		gween.New(1, 0, .25, ease.Linear),
+	))
+
+	animationLeave := animation.NewAnimation(false, gween.NewSequence(
+		gween.New(0, 1, .25, ease.Linear),
+	))
+
+	prevIcon, _ := widget.NewIcon(icons.NavigationChevronLeft)
+	nextIcon, _ := widget.NewIcon(icons.NavigationChevronRight)
+	openIcon, _ := widget.NewIcon(icons.ActionOpenInNew)
+	saveIcon, _ := widget.NewIcon(icons.FileFileDownload)
+	loadingIcon, _ := widget.NewIcon(icons.NavigationRefresh)
+
+	newButton := func(icon *widget.Icon) *components.Button {
+		button := components.NewButton(components.ButtonStyle{
+			Rounded:   components.UniformRounded(unit.Dp(5)),
+			Icon:      icon,
+			TextSize:  unit.Sp(14),
+			IconGap:   unit.Dp(10),
+			Inset:     layout.UniformInset(unit.Dp(10)),
+			Animation: components.NewButtonAnimationDefault(),
+		})
+		button.Label.Alignment = text.Middle
+		button.Style.Font.Weight = font.Bold
+		return button
+	}
+
+	buttonSave := newButton(saveIcon)
+	buttonSave.Style.LoadingIcon = loadingIcon
+
+	list := new(widget.List)
+	list.Axis = layout.Vertical
+
+	return &PageNFTDetail{
+		animationEnter: animationEnter,
+		animationLeave: animationLeave,
+		buttonPrev:     newButton(prevIcon),
+		buttonNext:     newButton(nextIcon),
+		buttonOpen:     newButton(openIcon),
+		buttonSave:     buttonSave,
+		images:         make(map[string]*NFTMediaImage),
+		list:           list,
+	}
+}
+
+func (p *PageNFTDetail) IsActive() bool {
+	return p.isActive
+}
+
+func (p *PageNFTDetail) SetToken(token *wallet_manager.Token) {
+	p.token = token
+	p.metadata = g45_sc.NFTMetadata{}
+	p.loadErr = nil
+	p.media = nil
+	p.mediaIndex = 0
+	p.attributes = nil
+	p.collectionId = ""
+	p.collectionMetadata = nil
+	p.collectionLinks = nil
+
+	p.imagesLock.Lock()
+	p.images = make(map[string]*NFTMediaImage)
+	p.imagesLock.Unlock()
+}
+
+func (p *PageNFTDetail) Enter() {
+	p.isActive = true
+
+	if !page_instance.header.IsHistory(PAGE_NFT_DETAIL) {
+		p.animationEnter.Start()
+		p.animationLeave.Reset()
+	}
+
+	page_instance.header.Title = func() string {
+		return p.token.Name
+	}
+
+	page_instance.header.Subtitle = func(gtx layout.Context, th *material.Theme) layout.Dimensions {
+		lbl := material.Label(th, unit.Sp(16), lang.Translate("NFT details"))
+		lbl.Color = theme.Current.TextMuteColor
+		return lbl.Layout(gtx)
+	}
+
+	p.load()
+}
+
+func (p *PageNFTDetail) Leave() {
+	p.animationLeave.Start()
+	p.animationEnter.Reset()
+}
+
+func (p *PageNFTDetail) load() {
+	metadata, err := p.token.NFTMetadata()
+	if err != nil {
+		p.loadErr = err
+		return
+	}
+
+	p.metadata = metadata
+	p.media = wallet_manager.NFTMediaList(metadata)
+	p.attributes = wallet_manager.NFTAttributes(metadata)
+	p.infoRows = prefabs.NewInfoRows(len(p.attributes))
+
+	go func() {
+		collectionId, err := p.token.G45Collection()
+		if err != nil || collectionId == "" {
+			return
+		}
+
+		collectionMetadata, err := wallet_manager.GetG45CollectionMetadata(collectionId)
+		if err != nil {
+			return
+		}
+
+		var names []string
+		for name := range collectionMetadata.Links {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		openIcon, _ := widget.NewIcon(icons.ActionOpenInNew)
+		var links []*NFTCollectionLink
+		for _, name := range names {
+			button := components.NewButton(components.ButtonStyle{
+				Rounded:   components.UniformRounded(unit.Dp(5)),
+				Icon:      openIcon,
+				TextSize:  unit.Sp(14),
+				IconGap:   unit.Dp(10),
+				Inset:     layout.UniformInset(unit.Dp(10)),
+				Animation: components.NewButtonAnimationDefault(),
+			})
+			button.Label.Alignment = text.Middle
+			links = append(links, &NFTCollectionLink{name: name, url: collectionMetadata.Links[name], button: button})
+		}
+
+		p.collectionId = collectionId
+		p.collectionMetadata = &collectionMetadata
+		p.collectionLinks = links
+		app_instance.Window.Invalidate()
+	}()
+}
+
+// images are loaded once they are displayed in the carousel
+func (p *PageNFTDetail) getImage(url string) *NFTMediaImage {
+	p.imagesLock.Lock()
+	defer p.imagesLock.Unlock()
+
+	mediaImage, ok := p.images[url]
+	if ok {
+		return mediaImage
+	}
+
+	mediaImage = &NFTMediaImage{loading: true}
+	p.images[url] = mediaImage
+	token := p.token
+
+	go func() {
+		var img image.Image
+		data, err := token.GetMediaImage(url)
+		if err == nil {
+			img, _, err = image.Decode(bytes.NewBuffer(data))
+		}
+
+		p.imagesLock.Lock()
+		mediaImage.loading = false
+		mediaImage.err = err
+		if err == nil {
+			mediaImage.image = &components.Image{
+				Src: paint.NewImageOp(img),
+				Fit: components.Contain,
+			}
+		}
+		p.imagesLock.Unlock()
+		app_instance.Window.Invalidate()
+	}()
+
+	return mediaImage
+}
+
+func (p *PageNFTDetail) saveMedia(media wallet_manager.NFTMedia) error {
+	data, err := wallet_manager.FetchMedia(media.Url)
+	if err != nil {
+		return err
+	}
+
+	file, err := app_instance.Explorer.CreateFile(media.FileName())
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(data)
+	return err
+}
+
+func (p *PageNFTDetail) layoutMedia(gtx layout.Context, th *material.Theme, media wallet_manager.NFTMedia) layout.Dimensions {
+	gtx.Constraints.Min.X = gtx.Constraints.Max.X
+	gtx.Constraints.Max.Y = gtx.Dp(300)
+
+	if media.Type == wallet_manager.NFTMediaImage {
+		mediaImage := p.getImage(media.Url)
+
+		p.imagesLock.Lock()
+		img, loading, err := mediaImage.image, mediaImage.loading, mediaImage.err
+		p.imagesLock.Unlock()
+
+		if img != nil {
+			return layout.Center.Layout(gtx, img.Layout)
+		}
+
+		status := lang.Translate("Loading...")
+		if !loading && err != nil {
+			status = err.Error()
+		}
+
+		return layout.Center.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(16), status)
+			lbl.Color = theme.Current.TextMuteColor
+			return lbl.Layout(gtx)
+		})
+	}
+
+	// the wallet has no audio or video player
+	return layout.Center.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		status := lang.Translate("Audio can't be played in the wallet. Open it externally or save it.")
+		if media.Type == wallet_manager.NFTMediaVideo {
+			status = lang.Translate("Video can't be played in the wallet. Open it externally or save it.")
+		}
+
+		lbl := material.Label(th, unit.Sp(16), status)
+		lbl.Color = theme.Current.TextMuteColor
+		lbl.Alignment = text.Middle
+		return lbl.Layout(gtx)
+	})
+}
+
+// the urls come from the token metadata - only web pages are given to the browser, not files or app schemes
+// audio and video are only played by the browser
+func openNFTUrl(value string) {
+	link := multi_fetch.GatewayURL(value)
+	u, err := url.Parse(link)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		err = fmt.Errorf(lang.Translate("Can't open [%s] - only http and https links are opened."), link)
+	} else {
+		err = browser.OpenUrl(u.String())
+	}
+
+	if err != nil {
+		notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+		notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		app_instance.Window.Invalidate()
+	}
+}
+
+func (p *PageNFTDetail) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	{
+		state := p.animationEnter.Update(gtx)
+		if state.Active {
+			defer animation.TransformX(gtx, state.Value).Push(gtx.Ops).Pop()
+		}
+	}
+
+	{
+		state := p.animationLeave.Update(gtx)
+		if state.Active {
+			defer animation.TransformX(gtx, state.Value).Push(gtx.Ops).Pop()
+		}
+
+		if state.Finished {
+			p.isActive = false
+			op.InvalidateOp{}.Add(gtx.Ops)
+		}
+	}
+
+	var currentMedia *wallet_manager.NFTMedia
+	if len(p.media) > 0 {
+		if p.buttonPrev.Clicked() {
+			p.mediaIndex = (p.mediaIndex - 1 + len(p.media)) % len(p.media)
+		}
+
+		if p.buttonNext.Clicked() {
+			p.mediaIndex = (p.mediaIndex + 1) % len(p.media)
+		}
+
+		currentMedia = &p.media[p.mediaIndex]
+	}
+
+	if p.buttonOpen.Clicked() && currentMedia != nil {
+		go openNFTUrl(currentMedia.Url)
+	}
+
+	if p.buttonSave.Clicked() && currentMedia != nil {
+		media := *currentMedia
+		go func() {
+			p.buttonSave.SetLoading(true)
+			err := p.saveMedia(media)
+			p.buttonSave.SetLoading(false)
+			if err != nil {
+				notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+				notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+			} else {
+				notification_modals.SuccessInstance.SetText(lang.Translate("Success"), lang.Translate("Media saved."))
+				notification_modals.SuccessInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+			}
+		}()
+	}
+
+	for _, link := range p.collectionLinks {
+		if link.button.Clicked() {
+			go openNFTUrl(link.url)
+		}
+	}
+
+	widgets := []layout.Widget{}
+
+	if p.loadErr != nil {
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(16), p.loadErr.Error())
+			lbl.Color = theme.Current.TextMuteColor
+			return lbl.Layout(gtx)
+		})
+	}
+
+	if currentMedia != nil {
+		media := *currentMedia
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			return p.layoutMedia(gtx, th, media)
+		})
+
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					p.buttonPrev.Style.Colors = theme.Current.ButtonSecondaryColors
+					return p.buttonPrev.Layout(gtx, th)
+				}),
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					return layout.Center.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						status := fmt.Sprintf("%s - %s (%d/%d)", lang.Translate(string(media.Type)), media.Name, p.mediaIndex+1, len(p.media))
+						lbl := material.Label(th, unit.Sp(14), status)
+						lbl.Color = theme.Current.TextMuteColor
+						return lbl.Layout(gtx)
+					})
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					p.buttonNext.Style.Colors = theme.Current.ButtonSecondaryColors
+					return p.buttonNext.Layout(gtx, th)
+				}),
+			)
+		})
+
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					p.buttonOpen.Text = lang.Translate("OPEN")
+					p.buttonOpen.Style.Colors = theme.Current.ButtonSecondaryColors
+					return p.buttonOpen.Layout(gtx, th)
+				}),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					p.buttonSave.Text = lang.Translate("SAVE")
+					p.buttonSave.Style.Colors = theme.Current.ButtonPrimaryColors
+					return p.buttonSave.Layout(gtx, th)
+				}),
+			)
+		})
+	}
+
+	if p.metadata.Description != "" {
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(16), p.metadata.Description)
+			return lbl.Layout(gtx)
+		})
+	}
+
+	if len(p.attributes) > 0 {
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(18), lang.Translate("Attributes"))
+			lbl.Font.Weight = font.Bold
+			return lbl.Layout(gtx)
+		})
+
+		for i := range p.attributes {
+			idx := i
+			widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+				attribute := p.attributes[idx]
+				return p.infoRows[idx].Layout(gtx, th, attribute.Key, attribute.Value)
+			})
+		}
+	}
+
+	if p.collectionMetadata != nil {
+		collectionMetadata := p.collectionMetadata
+
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			return prefabs.Divider(gtx, 5)
+		})
+
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					lbl := material.Label(th, unit.Sp(14), lang.Translate("Collection"))
+					lbl.Color = theme.Current.TextMuteColor
+					return lbl.Layout(gtx)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					lbl := material.Label(th, unit.Sp(18), collectionMetadata.Name)
+					lbl.Font.Weight = font.Bold
+					return lbl.Layout(gtx)
+				}),
+			)
+		})
+
+		if collectionMetadata.BackdropImage != "" {
+			widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+				return p.layoutMedia(gtx, th, wallet_manager.NFTMedia{
+					Type: wallet_manager.NFTMediaImage,
+					Url:  collectionMetadata.BackdropImage,
+				})
+			})
+		}
+
+		if collectionMetadata.Description != "" {
+			widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+				lbl := material.Label(th, unit.Sp(16), collectionMetadata.Description)
+				lbl.Color = theme.Current.TextMuteColor
+				return lbl.Layout(gtx)
+			})
+		}
+
+		for i := range p.collectionLinks {
+			link := p.collectionLinks[i]
+			widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+				link.button.Text = link.name
+				link.button.Style.Colors = theme.Current.ButtonSecondaryColors
+				return link.button.Layout(gtx, th)
+			})
+		}
+	}
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		return layout.Spacer{Height: unit.Dp(30)}.Layout(gtx)
+	})
+
+	listStyle := material.List(th, p.list)
+	listStyle.AnchorStrategy = material.Overlay
+
+	return listStyle.Layout(gtx, len(widgets), func(gtx layout.Context, index int) layout.Dimensions {
+		return layout.Inset{
+			Top: unit.Dp(0), Bottom: unit.Dp(20),
+			Left: unit.Dp(30), Right: unit.Dp(30),
+		}.Layout(gtx, widgets[index])
+	})
+}
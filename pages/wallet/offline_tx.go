@@ -0,0 +1,709 @@
+package page_wallet
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+	"time"
+
+	"gioui.org/font"
+	"gioui.org/io/clipboard"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/deroproject/derohe/globals"
+	"github.com/deroproject/derohe/rpc"
+	"github.com/deroproject/derohe/transaction"
+	"github.com/secretsystems/secret-wallet/animation"
+	"github.com/secretsystems/secret-wallet/app_icons"
+	"github.com/secretsystems/secret-wallet/app_instance"
+	"github.com/secretsystems/secret-wallet/components"
+	"github.com/secretsystems/secret-wallet/containers/notification_modals"
+	"github.com/secretsystems/secret-wallet/containers/password_modal"
+	"github.com/secretsystems/secret-wallet/containers/qrcode_scan_modal"
+	"github.com/secretsystems/secret-wallet/containers/recent_txs_modal"
+	"github.com/secretsystems/secret-wallet/lang"
+	"github.com/secretsystems/secret-wallet/prefabs"
+	"github.com/secretsystems/secret-wallet/router"
+	"github.com/secretsystems/secret-wallet/theme"
+	"github.com/secretsystems/secret-wallet/utils"
+	"github.com/secretsystems/secret-wallet/wallet_manager"
+	qrcode "github.com/skip2/go-qrcode"
+	"github.com/tanema/gween"
+	"github.com/tanema/gween/ease"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+// chars per QR code and delay between frames of the sequence
+const QR_FRAME_SIZE = 300
+const QR_FRAME_DELAY = 800 * time.Millisecond
+
+type PageOfflineTx struct {
+	isActive bool
+
+	animationEnter *animation.Animation
+	animationLeave *animation.Animation
+
+	txtData          *prefabs.TextField
+	buttonLoad       *components.Button
+	buttonImportFile *components.Button
+	buttonScanQR     *components.Button
+	buttonSign       *components.Button
+	buttonBroadcast  *components.Button
+	buttonSaveFile   *components.Button
+	buttonCopy       *components.Button
+	buttonShowQR     *components.Button
+	buttonClear      *components.Button
+	alertBox         *AlertBox
+	infoRows         []*prefabs.InfoRow
+
+	data       string
+	unsignedTx *wallet_manager.UnsignedTx
+	signedTx   *transaction.Transaction
+	scanFrames *wallet_manager.QRFrames
+	qrImages   []*components.Image
+	qrStart    time.Time
+
+	list *widget.List
+}
+
+var _ router.Page = &PageOfflineTx{}
+
+func newOfflineTxButton(icon *widget.Icon, loadingIcon *widget.Icon) *components.Button {
+	button := components.NewButton(components.ButtonStyle{
+		Rounded:     components.UniformRounded(unit.Dp(5)),
+		Icon:        icon,
+		TextSize:    unit.Sp(14),
+		IconGap:     unit.Dp(10),
+		Inset:       layout.UniformInset(unit.Dp(10)),
+		Animation:   components.NewButtonAnimationDefault(),
+		LoadingIcon: loadingIcon,
+	})
+	button.Label.Alignment = text.Middle
+	button.Style.Font.Weight = font.Bold
+	return button
+}
+
+func NewPageOfflineTx() *PageOfflineTx {
+	animationEnter := animation.NewAnimation(false, gween.NewSequence(
+		gween.New(-1, 0, .25, ease.Linear),
+	))
+
+	animationLeave := animation.NewAnimation(false, gween.NewSequence(
+		gween.New(0, -1, .25, ease.Linear),
+	))
+
+	list := new(widget.List)
+	list.Axis = layout.Vertical
+
+	txtData := prefabs.NewTextField()
+	txtData.Editor().SingleLine = false
+	txtData.Editor().Submit = false
+
+	loadingIcon, _ := widget.NewIcon(icons.NavigationRefresh)
+	loadIcon, _ := widget.NewIcon(icons.ContentContentPaste)
+	fileIcon, _ := widget.NewIcon(icons.FileFolderOpen)
+	scanIcon, _ := widget.NewIcon(app_icons.QRCodeScanner)
+	signIcon, _ := widget.NewIcon(icons.ContentCreate)
+	sendIcon, _ := widget.NewIcon(icons.ContentSend)
+	saveIcon, _ := widget.NewIcon(icons.ContentSave)
+	copyIcon, _ := widget.NewIcon(icons.ContentContentCopy)
+	qrIcon, _ := widget.NewIcon(icons.ImageCropFree)
+	clearIcon, _ := widget.NewIcon(icons.ContentClear)
+
+	return &PageOfflineTx{
+		animationEnter: animationEnter,
+		animationLeave: animationLeave,
+		list:           list,
+
+		txtData:          txtData,
+		buttonLoad:       newOfflineTxButton(loadIcon, nil),
+		buttonImportFile: newOfflineTxButton(fileIcon, nil),
+		buttonScanQR:     newOfflineTxButton(scanIcon, nil),
+		buttonSign:       newOfflineTxButton(signIcon, loadingIcon),
+		buttonBroadcast:  newOfflineTxButton(sendIcon, loadingIcon),
+		buttonSaveFile:   newOfflineTxButton(saveIcon, nil),
+		buttonCopy:       newOfflineTxButton(copyIcon, nil),
+		buttonShowQR:     newOfflineTxButton(qrIcon, nil),
+		buttonClear:      newOfflineTxButton(clearIcon, nil),
+		alertBox:         NewAlertBox(),
+		infoRows:         prefabs.NewInfoRows(4),
+	}
+}
+
+func (p *PageOfflineTx) IsActive() bool {
+	return p.isActive
+}
+
+func (p *PageOfflineTx) Enter() {
+	p.isActive = true
+
+	if !page_instance.header.IsHistory(PAGE_OFFLINE_TX) {
+		p.animationEnter.Start()
+		p.animationLeave.Reset()
+	}
+
+	page_instance.header.Title = func() string {
+		return lang.Translate("Offline Signing")
+	}
+
+	page_instance.header.Subtitle = nil
+}
+
+func (p *PageOfflineTx) Leave() {
+	p.animationLeave.Start()
+	p.animationEnter.Reset()
+}
+
+// accepts a signed tx hex or an unsigned tx
+func (p *PageOfflineTx) load(value string) error {
+	signedTx, err := wallet_manager.DecodeSignedTx(value)
+	if err == nil {
+		p.clear()
+		p.signedTx = signedTx
+		p.data = hex.EncodeToString(signedTx.Serialize())
+		return nil
+	}
+
+	unsignedTx, err := wallet_manager.DecodeUnsignedTx(value)
+	if err != nil {
+		return fmt.Errorf("invalid unsigned tx or signed tx hex")
+	}
+
+	data, err := unsignedTx.Encode()
+	if err != nil {
+		return err
+	}
+
+	p.clear()
+	p.unsignedTx = &unsignedTx
+	p.data = data
+	return nil
+}
+
+func (p *PageOfflineTx) clear() {
+	p.data = ""
+	p.unsignedTx = nil
+	p.signedTx = nil
+	p.qrImages = nil
+	p.txtData.SetValue("")
+}
+
+func (p *PageOfflineTx) importFile() error {
+	file, err := app_instance.Explorer.ChooseFile()
+	if err != nil {
+		return err
+	}
+
+	reader := utils.ReadCloser{ReadCloser: file}
+	data, err := reader.ReadAll()
+	if err != nil {
+		return err
+	}
+
+	return p.load(string(data))
+}
+
+func (p *PageOfflineTx) saveFile() error {
+	name := fmt.Sprintf("unsigned_tx_%d.json", time.Now().Unix())
+	if p.signedTx != nil {
+		name = fmt.Sprintf("signed_tx_%s.txt", p.signedTx.GetHash().String()[:8])
+	}
+
+	file, err := app_instance.Explorer.CreateFile(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write([]byte(p.data))
+	return err
+}
+
+// scanned frames are added until the sequence is complete
+func (p *PageOfflineTx) addScannedFrame(frame string) error {
+	if p.scanFrames == nil {
+		p.scanFrames = new(wallet_manager.QRFrames)
+	}
+
+	done, err := p.scanFrames.Add(frame)
+	if err != nil {
+		p.scanFrames = nil
+		return err
+	}
+
+	if !done {
+		added, count := p.scanFrames.Progress()
+		notification_modals.InfoInstance.SetText(lang.Translate("QR Code"), fmt.Sprintf(lang.Translate("Scanned %d of %d. Scan the next QR code."), added, count))
+		notification_modals.InfoInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		return nil
+	}
+
+	data := p.scanFrames.Data()
+	p.scanFrames = nil
+	return p.load(data)
+}
+
+func (p *PageOfflineTx) showQR() error {
+	var qrImages []*components.Image
+	for _, frame := range wallet_manager.SplitQRFrames(p.data, QR_FRAME_SIZE) {
+		imgBytes, err := qrcode.Encode(frame, qrcode.Medium, 256)
+		if err != nil {
+			return err
+		}
+
+		img, _, err := image.Decode(bytes.NewBuffer(imgBytes))
+		if err != nil {
+			return err
+		}
+
+		qrImages = append(qrImages, &components.Image{
+			Src: paint.NewImageOp(img),
+			Fit: components.Contain,
+		})
+	}
+
+	p.qrImages = qrImages
+	p.qrStart = time.Now()
+	return nil
+}
+
+func (p *PageOfflineTx) sign() error {
+	p.buttonSign.SetLoading(true)
+	defer p.buttonSign.SetLoading(false)
+
+	wallet := wallet_manager.OpenedWallet
+	tx, _, err := wallet.SignTransaction(*p.unsignedTx, false)
+	if err != nil {
+		return err
+	}
+
+	return p.load(hex.EncodeToString(tx.Serialize()))
+}
+
+func (p *PageOfflineTx) broadcast() error {
+	p.buttonBroadcast.SetLoading(true)
+	defer p.buttonBroadcast.SetLoading(false)
+
+	wallet := wallet_manager.OpenedWallet
+	err := wallet.BroadcastSignedTx(p.signedTx)
+	if err != nil {
+		return err
+	}
+
+	p.clear()
+	recent_txs_modal.Instance.SetVisible(true)
+	return nil
+}
+
+func showOfflineTxError(err error) {
+	notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+	notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+}
+
+// readable lines of the contract call - the values are not shortened so nothing is hidden from the signer
+func offlineSCDetail(scArgs rpc.Arguments) string {
+	var lines []string
+	if scArgs.Has(rpc.SCACTION, rpc.DataUint64) {
+		action, _ := scArgs.Value(rpc.SCACTION, rpc.DataUint64).(uint64)
+		switch rpc.SC_ACTION(action) {
+		case rpc.SC_INSTALL:
+			lines = append(lines, lang.Translate("Install a new smart contract"))
+		case rpc.SC_CALL:
+			lines = append(lines, lang.Translate("Call a smart contract"))
+		default:
+			lines = append(lines, fmt.Sprintf(lang.Translate("Unknown action %d"), action))
+		}
+	}
+
+	if scArgs.Has(rpc.SCID, rpc.DataHash) {
+		scId, _ := scArgs.Value(rpc.SCID, rpc.DataHash).(crypto.Hash)
+		lines = append(lines, fmt.Sprintf(lang.Translate("Contract %s"), scId))
+	}
+
+	for _, arg := range scArgs {
+		switch arg.Name {
+		case rpc.SCACTION, rpc.SCID:
+			continue
+		case rpc.SCCODE:
+			code := fmt.Sprint(arg.Value)
+			lines = append(lines, fmt.Sprintf(lang.Translate("Code of %d bytes"), len(code)))
+			continue
+		}
+
+		lines = append(lines, fmt.Sprintf("%s = %v", arg.Name, arg.Value))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (p *PageOfflineTx) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	{
+		state := p.animationEnter.Update(gtx)
+		if state.Active {
+			defer animation.TransformX(gtx, state.Value).Push(gtx.Ops).Pop()
+		}
+	}
+
+	{
+		state := p.animationLeave.Update(gtx)
+		if state.Active {
+			defer animation.TransformX(gtx, state.Value).Push(gtx.Ops).Pop()
+		}
+
+		if state.Finished {
+			p.isActive = false
+			op.InvalidateOp{}.Add(gtx.Ops)
+		}
+	}
+
+	wallet := wallet_manager.OpenedWallet
+
+	if p.buttonLoad.Clicked() {
+		err := p.load(p.txtData.Value())
+		if err != nil {
+			showOfflineTxError(err)
+		}
+	}
+
+	if p.buttonImportFile.Clicked() {
+		go func() {
+			err := p.importFile()
+			if err != nil {
+				showOfflineTxError(err)
+			}
+
+			app_instance.Window.Invalidate()
+		}()
+	}
+
+	if p.buttonScanQR.Clicked() {
+		qrcode_scan_modal.Instance.Open()
+	}
+
+	{
+		sent, value := qrcode_scan_modal.Instance.Value()
+		if sent {
+			err := p.addScannedFrame(value)
+			if err != nil {
+				showOfflineTxError(err)
+			}
+		}
+	}
+
+	if p.buttonSign.Clicked() {
+		password_modal.Instance.SetVisible(true)
+	}
+
+	submitted, password := password_modal.Instance.Input.Submitted()
+	if submitted {
+		if !wallet.Memory.Check_Password(password) {
+			password_modal.Instance.StartWrongPassAnimation()
+		} else {
+			password_modal.Instance.SetVisible(false)
+
+			// decrypting the balances can take a while
+			go func() {
+				err := p.sign()
+				if err != nil {
+					showOfflineTxError(err)
+				} else {
+					notification_modals.SuccessInstance.SetText(lang.Translate("Success"), lang.Translate("Transaction signed. Broadcast it from an online wallet."))
+					notification_modals.SuccessInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+				}
+
+				app_instance.Window.Invalidate()
+			}()
+		}
+	}
+
+	if p.buttonBroadcast.Clicked() {
+		go func() {
+			err := p.broadcast()
+			if err != nil {
+				showOfflineTxError(err)
+			}
+
+			app_instance.Window.Invalidate()
+		}()
+	}
+
+	if p.buttonSaveFile.Clicked() {
+		go func() {
+			err := p.saveFile()
+			if err != nil {
+				showOfflineTxError(err)
+			}
+		}()
+	}
+
+	if p.buttonCopy.Clicked() {
+		clipboard.WriteOp{
+			Text: p.data,
+		}.Add(gtx.Ops)
+		notification_modals.InfoInstance.SetText(lang.Translate("Clipboard"), lang.Translate("Transaction copied to clipboard"))
+		notification_modals.InfoInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+	}
+
+	if p.buttonShowQR.Clicked() {
+		if p.qrImages != nil {
+			p.qrImages = nil
+		} else {
+			err := p.showQR()
+			if err != nil {
+				showOfflineTxError(err)
+			}
+		}
+	}
+
+	if p.buttonClear.Clicked() {
+		p.clear()
+	}
+
+	widgets := []layout.Widget{}
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		lbl := material.Label(th, unit.Sp(16), lang.Translate("Prepare a transaction with a watch-only wallet, sign it here with the wallet holding the secret key on an offline device and broadcast the signed transaction from an online wallet."))
+		lbl.Color = theme.Current.TextMuteColor
+		return lbl.Layout(gtx)
+	})
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		return p.txtData.Layout(gtx, th, lang.Translate("Transaction"), lang.Translate("Paste an unsigned tx or a signed tx hex"))
+	})
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				p.buttonLoad.Text = lang.Translate("LOAD")
+				p.buttonLoad.Style.Colors = theme.Current.ButtonPrimaryColors
+				return p.buttonLoad.Layout(gtx, th)
+			}),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				p.buttonImportFile.Text = lang.Translate("FILE")
+				p.buttonImportFile.Style.Colors = theme.Current.ButtonPrimaryColors
+				return p.buttonImportFile.Layout(gtx, th)
+			}),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				p.buttonScanQR.Text = lang.Translate("SCAN")
+				p.buttonScanQR.Style.Colors = theme.Current.ButtonPrimaryColors
+				return p.buttonScanQR.Layout(gtx, th)
+			}),
+		)
+	})
+
+	if p.unsignedTx != nil {
+		unsignedTx := p.unsignedTx
+
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			return prefabs.Divider(gtx, 5)
+		})
+
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(18), lang.Translate("Unsigned transaction"))
+			lbl.Font.Weight = font.Bold
+			return lbl.Layout(gtx)
+		})
+
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					lbl := material.Label(th, unit.Sp(16), lang.Translate("Signer"))
+					lbl.Font.Weight = font.Bold
+					lbl.Color = theme.Current.TextMuteColor
+					return lbl.Layout(gtx)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					lbl := material.Label(th, unit.Sp(16), unsignedTx.Signer)
+					return lbl.Layout(gtx)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return p.infoRows[1].Layout(gtx, th, lang.Translate("Height"), fmt.Sprint(unsignedTx.Height))
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					created := time.Unix(unsignedTx.Timestamp, 0).Format("2006-01-02 15:04")
+					return p.infoRows[2].Layout(gtx, th, lang.Translate("Created"), created)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					gasFees := fmt.Sprintf("%s DERO", globals.FormatMoney(unsignedTx.GasFees))
+					return p.infoRows[3].Layout(gtx, th, lang.Translate("Gas fees"), gasFees)
+				}),
+			)
+		})
+
+		for i := range unsignedTx.Transfers {
+			transfer := unsignedTx.Transfers[i]
+			widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+				amount := fmt.Sprintf("%s DERO", globals.FormatMoney(transfer.Amount+transfer.Burn))
+				if !transfer.SCID.IsZero() {
+					amount = fmt.Sprintf("%d %s", transfer.Amount+transfer.Burn, transfer.SCID.String())
+				}
+
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						lbl := material.Label(th, unit.Sp(16), transfer.Destination)
+						lbl.Color = theme.Current.TextMuteColor
+						return lbl.Layout(gtx)
+					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						lbl := material.Label(th, unit.Sp(16), amount)
+						return lbl.Layout(gtx)
+					}),
+				)
+			})
+		}
+
+		// a contract call is part of what gets signed - it's shown in full like the rpc approvals
+		if len(unsignedTx.SCArgs) > 0 {
+			scDetail := offlineSCDetail(unsignedTx.SCArgs)
+
+			widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+				lbl := material.Label(th, unit.Sp(16), lang.Translate("Smart contract"))
+				lbl.Font.Weight = font.Bold
+				return lbl.Layout(gtx)
+			})
+
+			widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+				lbl := material.Label(th, unit.Sp(16), scDetail)
+				lbl.Color = theme.Current.TextMuteColor
+				return lbl.Layout(gtx)
+			})
+		}
+
+		if wallet.IsWatchOnly() {
+			widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+				return p.alertBox.Layout(gtx, th, lang.Translate("Transfer this unsigned transaction to the offline device to sign it."))
+			})
+		} else {
+			widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+				p.buttonSign.Text = lang.Translate("SIGN TRANSACTION")
+				p.buttonSign.Style.Colors = theme.Current.ButtonPrimaryColors
+				return p.buttonSign.Layout(gtx, th)
+			})
+		}
+	}
+
+	if p.signedTx != nil {
+		signedTx := p.signedTx
+
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			return prefabs.Divider(gtx, 5)
+		})
+
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(18), lang.Translate("Signed transaction"))
+			lbl.Font.Weight = font.Bold
+			return lbl.Layout(gtx)
+		})
+
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					txId := utils.ReduceTxId(signedTx.GetHash().String())
+					return p.infoRows[0].Layout(gtx, th, lang.Translate("TXID"), txId)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return p.infoRows[1].Layout(gtx, th, lang.Translate("Type"), signedTx.TransactionType.String())
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return p.infoRows[2].Layout(gtx, th, lang.Translate("Height"), fmt.Sprint(signedTx.Height))
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					fees := fmt.Sprintf("%s DERO", globals.FormatMoney(signedTx.Fees()))
+					return p.infoRows[3].Layout(gtx, th, lang.Translate("TX fees"), fees)
+				}),
+			)
+		})
+
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			p.buttonBroadcast.Text = lang.Translate("BROADCAST")
+			p.buttonBroadcast.Style.Colors = theme.Current.ButtonPrimaryColors
+			return p.buttonBroadcast.Layout(gtx, th)
+		})
+	}
+
+	if p.data != "" {
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					p.buttonSaveFile.Text = lang.Translate("SAVE")
+					p.buttonSaveFile.Style.Colors = theme.Current.ButtonSecondaryColors
+					return p.buttonSaveFile.Layout(gtx, th)
+				}),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					p.buttonCopy.Text = lang.Translate("COPY")
+					p.buttonCopy.Style.Colors = theme.Current.ButtonSecondaryColors
+					return p.buttonCopy.Layout(gtx, th)
+				}),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					p.buttonShowQR.Text = lang.Translate("QR")
+					p.buttonShowQR.Style.Colors = theme.Current.ButtonSecondaryColors
+					return p.buttonShowQR.Layout(gtx, th)
+				}),
+			)
+		})
+
+		if len(p.qrImages) > 0 {
+			widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+				// loop through the frames so the other device can scan all of them
+				elapsed := time.Since(p.qrStart)
+				index := int(elapsed/QR_FRAME_DELAY) % len(p.qrImages)
+				next := p.qrStart.Add((elapsed/QR_FRAME_DELAY + 1) * QR_FRAME_DELAY)
+				op.InvalidateOp{At: next}.Add(gtx.Ops)
+
+				return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return layout.Center.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+							gtx.Constraints.Max.Y = gtx.Dp(250)
+							return p.qrImages[index].Layout(gtx)
+						})
+					}),
+					layout.Rigid(layout.Spacer{Height: unit.Dp(5)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						txt := fmt.Sprintf("%d / %d", index+1, len(p.qrImages))
+						lbl := material.Label(th, unit.Sp(14), txt)
+						lbl.Color = theme.Current.TextMuteColor
+						return lbl.Layout(gtx)
+					}),
+				)
+			})
+		}
+
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			p.buttonClear.Text = lang.Translate("CLEAR")
+			p.buttonClear.Style.Colors = theme.Current.ButtonSecondaryColors
+			p.buttonClear.Style.Border = widget.Border{
+				Color:        color.NRGBA{A: 100},
+				Width:        unit.Dp(1),
+				CornerRadius: unit.Dp(5),
+			}
+			return p.buttonClear.Layout(gtx, th)
+		})
+	}
+
+	listStyle := material.List(th, p.list)
+	listStyle.AnchorStrategy = material.Overlay
+
+	if p.txtData.Input.Clickable.Clicked() {
+		p.list.ScrollTo(1)
+	}
+
+	return listStyle.Layout(gtx, len(widgets), func(gtx layout.Context, index int) layout.Dimensions {
+		return layout.Inset{
+			Top: unit.Dp(0), Bottom: unit.Dp(20),
+			Left: unit.Dp(30), Right: unit.Dp(30),
+		}.Layout(gtx, widgets[index])
+	})
+}
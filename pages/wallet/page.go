@@ -41,6 +41,22 @@ type Page struct {
 	pageSCFolders       *PageSCFolders
 	pageContacts        *PageContacts
 	pageTransaction     *PageTransaction
+	pageBatchSend       *PageBatchSend
+	pageDEXSwap         *PageDEXSwap
+	pageDEXAddLiquidity *PageDEXAddLiquidity
+	pageDEXRemLiquidity *PageDEXRemLiquidity
+	pageDEXSCBridgeOut  *PageDEXSCBridgeOut
+	pageDEXSCBridgeIn   *PageDEXSCBridgeIn
+	pageNFTDetail       *PageNFTDetail
+	pageSCConsole       *PageSCConsole
+	pageSCInstall       *PageSCInstall
+	pageAddSCForm       *PageAddSCForm
+	pageSCState         *PageSCState
+	pageSCWatchlist     *PageSCWatchlist
+	pageReceiveForm     *PageReceiveForm
+
+	// wallet the background checks were started for - they stop when it's closed
+	checkedWallet *wallet_manager.Wallet
 
 	pageRouter *router.Router
 }
@@ -66,12 +82,20 @@ var (
 	PAGE_TRANSACTION       = "page_transaction"
 	PAGE_SCAN_COLLECTION   = "page_scan_collection"
 	PAGE_SERVICE_NAMES     = "page_service_names"
+	PAGE_BATCH_SEND        = "page_batch_send"
+	PAGE_OFFLINE_TX        = "page_offline_tx"
 	PAGE_DEX_PAIRS         = "page_dex_pairs"
 	PAGE_DEX_SWAP          = "page_dex_swap"
 	PAGE_DEX_ADD_LIQUIDITY = "page_dex_add_liquidity"
 	PAGE_DEX_REM_LIQUIDITY = "page_dex_rem_liquidity"
 	PAGE_DEX_SC_BRIDGE_OUT = "page_dex_sc_bridge_out"
 	PAGE_DEX_SC_BRIDGE_IN  = "page_dex_sc_bridge_in"
+	PAGE_G45_MINT          = "page_g45_mint"
+	PAGE_NFT_DETAIL        = "page_nft_detail"
+	PAGE_SC_CONSOLE        = "page_sc_console"
+	PAGE_SC_INSTALL        = "page_sc_install"
+	PAGE_SC_STATE          = "page_sc_state"
+	PAGE_SC_WATCHLIST      = "page_sc_watchlist"
 )
 
 func New() *Page {
@@ -129,23 +153,47 @@ func New() *Page {
 	pageServiceNames := NewPageServiceNames()
 	pageRouter.Add(PAGE_SERVICE_NAMES, pageServiceNames)
 
-	// pageDEXPairs := NewPageDEXPairs()
-	// pageRouter.Add(PAGE_DEX_PAIRS, pageDEXPairs)
+	pageBatchSend := NewPageBatchSend()
+	pageRouter.Add(PAGE_BATCH_SEND, pageBatchSend)
 
-	// pageDEXSwap := NewPageDEXSwap()
-	// pageRouter.Add(PAGE_DEX_SWAP, pageDEXSwap)
+	pageOfflineTx := NewPageOfflineTx()
+	pageRouter.Add(PAGE_OFFLINE_TX, pageOfflineTx)
 
-	// pageDEXAddLiquidity := NewPageDEXAddLiquidity()
-	// pageRouter.Add(PAGE_DEX_ADD_LIQUIDITY, pageDEXAddLiquidity)
+	pageDEXPairs := NewPageDEXPairs()
+	pageRouter.Add(PAGE_DEX_PAIRS, pageDEXPairs)
 
-	// pageDEXRemLiquidity := NewPageDEXRemLiquidity()
-	// pageRouter.Add(PAGE_DEX_REM_LIQUIDITY, pageDEXRemLiquidity)
+	pageDEXSwap := NewPageDEXSwap()
+	pageRouter.Add(PAGE_DEX_SWAP, pageDEXSwap)
 
-	// pageDEXSCBridgeOut := NewPageDEXSCBridgeOut()
-	// pageRouter.Add(PAGE_DEX_SC_BRIDGE_OUT, pageDEXSCBridgeOut)
+	pageDEXAddLiquidity := NewPageDEXAddLiquidity()
+	pageRouter.Add(PAGE_DEX_ADD_LIQUIDITY, pageDEXAddLiquidity)
 
-	// pageDEXSCBridgeIn := NewPageDEXSCBridgeIn()
-	// pageRouter.Add(PAGE_DEX_SC_BRIDGE_IN, pageDEXSCBridgeIn)
+	pageDEXRemLiquidity := NewPageDEXRemLiquidity()
+	pageRouter.Add(PAGE_DEX_REM_LIQUIDITY, pageDEXRemLiquidity)
+
+	pageDEXSCBridgeOut := NewPageDEXSCBridgeOut()
+	pageRouter.Add(PAGE_DEX_SC_BRIDGE_OUT, pageDEXSCBridgeOut)
+
+	pageDEXSCBridgeIn := NewPageDEXSCBridgeIn()
+	pageRouter.Add(PAGE_DEX_SC_BRIDGE_IN, pageDEXSCBridgeIn)
+
+	pageG45Mint := NewPageG45Mint()
+	pageRouter.Add(PAGE_G45_MINT, pageG45Mint)
+
+	pageNFTDetail := NewPageNFTDetail()
+	pageRouter.Add(PAGE_NFT_DETAIL, pageNFTDetail)
+
+	pageSCConsole := NewPageSCConsole()
+	pageRouter.Add(PAGE_SC_CONSOLE, pageSCConsole)
+
+	pageSCInstall := NewPageSCInstall()
+	pageRouter.Add(PAGE_SC_INSTALL, pageSCInstall)
+
+	pageSCState := NewPageSCState()
+	pageRouter.Add(PAGE_SC_STATE, pageSCState)
+
+	pageSCWatchlist := NewPageSCWatchlist()
+	pageRouter.Add(PAGE_SC_WATCHLIST, pageSCWatchlist)
 
 	header := prefabs.NewHeader(pageRouter)
 
@@ -163,11 +211,19 @@ func New() *Page {
 		pageSCFolders:       pageSCFolders,
 		pageContacts:        pageContacts,
 		pageTransaction:     pageTransaction,
-		// pageDexSwap:         pageDEXSwap,
-		// pageDEXAddLiquidity: pageDEXAddLiquidity,
-		// pageDEXRemLiquidity: pageDEXRemLiquidity,
-		// pageDEXSCBridgeOut:  pageDEXSCBridgeOut,
-		// pageDEXSCBridgeIn:   pageDEXSCBridgeIn,
+		pageBatchSend:       pageBatchSend,
+		pageDEXSwap:         pageDEXSwap,
+		pageDEXAddLiquidity: pageDEXAddLiquidity,
+		pageDEXRemLiquidity: pageDEXRemLiquidity,
+		pageDEXSCBridgeOut:  pageDEXSCBridgeOut,
+		pageDEXSCBridgeIn:   pageDEXSCBridgeIn,
+		pageNFTDetail:       pageNFTDetail,
+		pageSCConsole:       pageSCConsole,
+		pageSCInstall:       pageSCInstall,
+		pageAddSCForm:       pageAddSCForm,
+		pageSCState:         pageSCState,
+		pageSCWatchlist:     pageSCWatchlist,
+		pageReceiveForm:     pageReceiveForm,
 
 		pageRouter: pageRouter,
 	}
@@ -190,6 +246,12 @@ func (p *Page) Enter() {
 		p.animationLeave.Reset()
 		p.animationEnter.Start()
 
+		if p.checkedWallet != openedWallet {
+			p.checkedWallet = openedWallet
+			p.pageSCWatchlist.startPolling(openedWallet)
+			p.pageReceiveForm.startCheckingInvoices(openedWallet)
+		}
+
 		//node_status_bar.Instance.Update()
 		lastHistory := p.header.GetLastHistory()
 		if lastHistory != nil {
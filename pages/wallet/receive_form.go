@@ -2,23 +2,39 @@ package page_wallet
 
 import (
 	"bytes"
+	"fmt"
 	"image"
+	"strconv"
+	"sync"
+	"time"
 
 	"gioui.org/font"
+	"gioui.org/io/clipboard"
+	"gioui.org/io/pointer"
 	"gioui.org/layout"
 	"gioui.org/op"
+	"gioui.org/op/clip"
 	"gioui.org/op/paint"
 	"gioui.org/text"
 	"gioui.org/unit"
 	"gioui.org/widget"
 	"gioui.org/widget/material"
+	"github.com/deroproject/derohe/globals"
 	"github.com/secretsystems/secret-wallet/animation"
+	"github.com/secretsystems/secret-wallet/app_instance"
 	"github.com/secretsystems/secret-wallet/components"
+	"github.com/secretsystems/secret-wallet/containers/listselect_modal"
+	"github.com/secretsystems/secret-wallet/containers/notification_modals"
+	"github.com/secretsystems/secret-wallet/lang"
+	"github.com/secretsystems/secret-wallet/prefabs"
 	"github.com/secretsystems/secret-wallet/router"
+	"github.com/secretsystems/secret-wallet/theme"
+	"github.com/secretsystems/secret-wallet/utils"
 	"github.com/secretsystems/secret-wallet/wallet_manager"
 	qrcode "github.com/skip2/go-qrcode"
 	"github.com/tanema/gween"
 	"github.com/tanema/gween/ease"
+	"golang.org/x/exp/shiny/materialdesign/icons"
 )
 
 type PageReceiveForm struct {
@@ -30,6 +46,23 @@ type PageReceiveForm struct {
 	list       *widget.List
 	addrEditor *widget.Editor
 	addrImage  *components.Image
+	addr       string
+	invoice    *wallet_manager.Invoice
+
+	txtAmount        *prefabs.TextField
+	txtComment       *prefabs.TextField
+	txtDstPort       *prefabs.TextField
+	buttonExpiry     *components.Button
+	buttonCreate     *components.Button
+	buttonCopyAddr   *components.Button
+	buttonWalletAddr *components.Button
+	expiry           time.Duration
+
+	invoiceItems []*InvoiceListItem
+
+	// set by the invoice checks - the list is loaded again by the next layout
+	checkLock       sync.Mutex
+	invoicesChanged bool
 }
 
 var _ router.Page = &PageReceiveForm{}
@@ -51,12 +84,73 @@ func NewPageReceiveForm() *PageReceiveForm {
 	addrEditor.Alignment = text.Middle
 	addrEditor.ReadOnly = true
 
-	return &PageReceiveForm{
-		animationEnter: animationEnter,
-		animationLeave: animationLeave,
-		list:           list,
-		addrEditor:     addrEditor,
+	txtComment := prefabs.NewTextField()
+	txtComment.Editor().SingleLine = false
+	txtComment.Editor().Submit = false
+
+	timerIcon, _ := widget.NewIcon(icons.ImageTimer)
+	buttonExpiry := components.NewButton(components.ButtonStyle{
+		Rounded:   components.UniformRounded(unit.Dp(5)),
+		TextSize:  unit.Sp(16),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Icon:      timerIcon,
+		IconGap:   unit.Dp(10),
+		Animation: components.NewButtonAnimationDefault(),
+	})
+	buttonExpiry.Label.Alignment = text.Middle
+	buttonExpiry.Style.Font.Weight = font.Bold
+
+	receiptIcon, _ := widget.NewIcon(icons.ActionReceipt)
+	buttonCreate := components.NewButton(components.ButtonStyle{
+		Rounded:   components.UniformRounded(unit.Dp(5)),
+		Icon:      receiptIcon,
+		TextSize:  unit.Sp(14),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+	})
+	buttonCreate.Label.Alignment = text.Middle
+	buttonCreate.Style.Font.Weight = font.Bold
+
+	copyIcon, _ := widget.NewIcon(icons.ContentContentCopy)
+	buttonCopyAddr := components.NewButton(components.ButtonStyle{
+		Rounded:   components.UniformRounded(unit.Dp(5)),
+		Icon:      copyIcon,
+		TextSize:  unit.Sp(14),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+	})
+	buttonCopyAddr.Label.Alignment = text.Middle
+	buttonCopyAddr.Style.Font.Weight = font.Bold
+
+	walletIcon, _ := widget.NewIcon(icons.ActionAccountBalanceWallet)
+	buttonWalletAddr := components.NewButton(components.ButtonStyle{
+		Rounded:   components.UniformRounded(unit.Dp(5)),
+		Icon:      walletIcon,
+		TextSize:  unit.Sp(14),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+	})
+	buttonWalletAddr.Label.Alignment = text.Middle
+	buttonWalletAddr.Style.Font.Weight = font.Bold
+
+	page := &PageReceiveForm{
+		animationEnter:   animationEnter,
+		animationLeave:   animationLeave,
+		list:             list,
+		addrEditor:       addrEditor,
+		txtAmount:        prefabs.NewNumberTextField(),
+		txtComment:       txtComment,
+		txtDstPort:       prefabs.NewNumberTextField(),
+		buttonExpiry:     buttonExpiry,
+		buttonCreate:     buttonCreate,
+		buttonCopyAddr:   buttonCopyAddr,
+		buttonWalletAddr: buttonWalletAddr,
 	}
+
+	return page
 }
 
 func (p *PageReceiveForm) IsActive() bool {
@@ -72,7 +166,22 @@ func (p *PageReceiveForm) Enter() {
 	}
 	page_instance.pageBalanceTokens.ResetWalletHeader()
 
+	p.setAddr(nil)
+	p.Load()
+}
+
+func (p *PageReceiveForm) Leave() {
+	p.animationLeave.Start()
+	p.animationEnter.Reset()
+}
+
+// shows the invoice integrated address or the wallet address if nil
+func (p *PageReceiveForm) setAddr(invoice *wallet_manager.Invoice) {
 	addr := wallet_manager.OpenedWallet.Memory.GetAddress().String()
+	if invoice != nil {
+		addr = invoice.Addr
+	}
+
 	imgBytes, _ := qrcode.Encode(addr, qrcode.Medium, 256)
 	img, _, _ := image.Decode(bytes.NewBuffer(imgBytes))
 
@@ -81,12 +190,133 @@ func (p *PageReceiveForm) Enter() {
 		Fit: components.Contain,
 	}
 
+	p.addr = addr
+	p.invoice = invoice
 	p.addrEditor.SetText(addr)
 }
 
-func (p *PageReceiveForm) Leave() {
-	p.animationLeave.Start()
-	p.animationEnter.Reset()
+func (p *PageReceiveForm) Load() error {
+	wallet := wallet_manager.OpenedWallet
+	invoices, err := wallet.GetInvoices(wallet_manager.GetInvoicesParams{})
+	if err != nil {
+		return err
+	}
+
+	p.invoiceItems = make([]*InvoiceListItem, 0)
+	for _, invoice := range invoices {
+		p.invoiceItems = append(p.invoiceItems, NewInvoiceListItem(invoice))
+	}
+
+	return nil
+}
+
+// the integrated addresses are checked against incoming entries until the wallet is closed
+func (p *PageReceiveForm) startCheckingInvoices(wallet *wallet_manager.Wallet) {
+	lastErr := ""
+	wallet.StartInvoiceChecks(func(paidInvoices []wallet_manager.Invoice, err error) {
+		if err != nil {
+			// the same error is not shown every check
+			if err.Error() != lastErr {
+				lastErr = err.Error()
+				notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+				notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+				app_instance.Window.Invalidate()
+			}
+
+			return
+		}
+
+		lastErr = ""
+
+		p.checkLock.Lock()
+		p.invoicesChanged = true
+		p.checkLock.Unlock()
+
+		notification_modals.SuccessInstance.SetText(lang.Translate("Success"), fmt.Sprintf(lang.Translate("%d invoice(s) paid."), len(paidInvoices)))
+		notification_modals.SuccessInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		app_instance.Window.Invalidate()
+	})
+}
+
+// the shown invoice is replaced by its paid version
+func (p *PageReceiveForm) reloadPaidInvoices() {
+	p.checkLock.Lock()
+	changed := p.invoicesChanged
+	p.invoicesChanged = false
+	p.checkLock.Unlock()
+
+	if !changed {
+		return
+	}
+
+	err := p.Load()
+	if err != nil {
+		notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+		notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		return
+	}
+
+	if p.invoice != nil {
+		for _, item := range p.invoiceItems {
+			if item.invoice.ID == p.invoice.ID {
+				invoice := item.invoice
+				p.invoice = &invoice
+				break
+			}
+		}
+	}
+}
+
+func (p *PageReceiveForm) createInvoice() error {
+	wallet := wallet_manager.OpenedWallet
+
+	var amount utils.ShiftNumber
+	amount.Decimals = 5
+	if p.txtAmount.Value() != "" {
+		err := amount.Parse(p.txtAmount.Value())
+		if err != nil {
+			return err
+		}
+	}
+
+	var dstPort uint64
+	if p.txtDstPort.Value() != "" {
+		var err error
+		dstPort, err = strconv.ParseUint(p.txtDstPort.Value(), 10, 64)
+		if err != nil {
+			return err
+		}
+	}
+
+	invoice, err := wallet.CreateInvoice(wallet_manager.CreateInvoiceParams{
+		Amount:  amount.Number,
+		Comment: p.txtComment.Value(),
+		Expiry:  p.expiry,
+		DstPort: dstPort,
+	})
+	if err != nil {
+		return err
+	}
+
+	p.txtAmount.SetValue("")
+	p.txtComment.SetValue("")
+	p.txtDstPort.SetValue("")
+	p.setAddr(invoice)
+	p.list.ScrollTo(0)
+	return p.Load()
+}
+
+func expiryText(expiry time.Duration) string {
+	switch expiry {
+	case time.Hour:
+		return lang.Translate("Expires in 1 hour")
+	case 24 * time.Hour:
+		return lang.Translate("Expires in 24 hours")
+	case 7 * 24 * time.Hour:
+		return lang.Translate("Expires in 7 days")
+	}
+
+	return lang.Translate("No expiry")
 }
 
 func (p *PageReceiveForm) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
@@ -109,7 +339,89 @@ func (p *PageReceiveForm) Layout(gtx layout.Context, th *material.Theme) layout.
 		}
 	}
 
-	widgets := []layout.Widget{
+	p.reloadPaidInvoices()
+
+	if p.buttonCopyAddr.Clicked() {
+		clipboard.WriteOp{
+			Text: p.addr,
+		}.Add(gtx.Ops)
+		notification_modals.InfoInstance.SetText(lang.Translate("Clipboard"), lang.Translate("Addr copied to clipboard"))
+		notification_modals.InfoInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+	}
+
+	if p.buttonWalletAddr.Clicked() {
+		p.setAddr(nil)
+	}
+
+	if p.buttonExpiry.Clicked() {
+		go func() {
+			items := []*listselect_modal.SelectListItem{}
+			expiries := []time.Duration{0, time.Hour, 24 * time.Hour, 7 * 24 * time.Hour}
+			for _, expiry := range expiries {
+				text := expiryText(expiry)
+				items = append(items, listselect_modal.NewSelectListItem(fmt.Sprint(int64(expiry)), func(gtx layout.Context, th *material.Theme) layout.Dimensions {
+					lbl := material.Label(th, unit.Sp(18), text)
+					return lbl.Layout(gtx)
+				}))
+			}
+
+			keyChan := listselect_modal.Instance.Open(items)
+			for key := range keyChan {
+				value, _ := strconv.ParseInt(key, 10, 64)
+				p.expiry = time.Duration(value)
+			}
+		}()
+	}
+
+	if p.buttonCreate.Clicked() {
+		err := p.createInvoice()
+		if err != nil {
+			notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+			notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		} else {
+			notification_modals.SuccessInstance.SetText(lang.Translate("Success"), lang.Translate("Payment request created."))
+			notification_modals.SuccessInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		}
+	}
+
+	for _, item := range p.invoiceItems {
+		if item.clickable.Clicked() {
+			invoice := item.invoice
+			p.setAddr(&invoice)
+			p.list.ScrollTo(0)
+		}
+
+		if item.buttonDelete.Clicked() {
+			wallet := wallet_manager.OpenedWallet
+			err := wallet.DelInvoice(item.invoice.ID)
+			if err != nil {
+				notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+				notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+			} else {
+				if p.invoice != nil && p.invoice.ID == item.invoice.ID {
+					p.setAddr(nil)
+				}
+
+				p.Load()
+			}
+			break
+		}
+	}
+
+	widgets := []layout.Widget{}
+
+	if p.invoice != nil {
+		invoice := *p.invoice
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			return layout.Center.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				lbl := material.Label(th, unit.Sp(18), invoiceTitle(invoice))
+				lbl.Font.Weight = font.Bold
+				return lbl.Layout(gtx)
+			})
+		})
+	}
+
+	widgets = append(widgets,
 		func(gtx layout.Context) layout.Dimensions {
 			gtx.Constraints.Max.X = gtx.Dp(250)
 			return layout.Center.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
@@ -125,8 +437,86 @@ func (p *PageReceiveForm) Layout(gtx layout.Context, th *material.Theme) layout.
 				return p.addrImage.Layout(gtx)
 			})
 		},
+		func(gtx layout.Context) layout.Dimensions {
+			var childs []layout.FlexChild
+
+			childs = append(childs, layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				p.buttonCopyAddr.Text = lang.Translate("COPY")
+				p.buttonCopyAddr.Style.Colors = theme.Current.ButtonPrimaryColors
+				return p.buttonCopyAddr.Layout(gtx, th)
+			}))
+
+			if p.invoice != nil {
+				childs = append(childs,
+					layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						p.buttonWalletAddr.Text = lang.Translate("WALLET ADDR")
+						p.buttonWalletAddr.Style.Colors = theme.Current.ButtonSecondaryColors
+						return p.buttonWalletAddr.Layout(gtx, th)
+					}),
+				)
+			}
+
+			return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, childs...)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return prefabs.Divider(gtx, unit.Dp(5))
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					lbl := material.Label(th, unit.Sp(20), lang.Translate("Payment Request"))
+					lbl.Font.Weight = font.Bold
+					return lbl.Layout(gtx)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					lbl := material.Label(th, unit.Sp(14), lang.Translate("Creates an integrated address with the amount, message and a destination port. The invoice is marked as paid once a matching transfer is received."))
+					lbl.Color = theme.Current.TextMuteColor
+					return lbl.Layout(gtx)
+				}),
+			)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.txtAmount.Layout(gtx, th, lang.Translate("Amount"), "0.00000")
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			p.txtComment.Input.EditorMinY = gtx.Dp(50)
+			return p.txtComment.Layout(gtx, th, lang.Translate("Message"), "")
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.txtDstPort.Layout(gtx, th, lang.Translate("DST Port"), lang.Translate("Random if empty"))
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonExpiry.Text = expiryText(p.expiry)
+			p.buttonExpiry.Style.Colors = theme.Current.ButtonPrimaryColors
+			return p.buttonExpiry.Layout(gtx, th)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonCreate.Text = lang.Translate("CREATE PAYMENT REQUEST")
+			p.buttonCreate.Style.Colors = theme.Current.ButtonPrimaryColors
+			return p.buttonCreate.Layout(gtx, th)
+		},
+	)
+
+	if len(p.invoiceItems) > 0 {
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(20), lang.Translate("Invoices"))
+			lbl.Font.Weight = font.Bold
+			return lbl.Layout(gtx)
+		})
+	}
+
+	for i := range p.invoiceItems {
+		item := p.invoiceItems[i]
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			return item.Layout(gtx, th)
+		})
 	}
 
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		return layout.Spacer{Height: unit.Dp(30)}.Layout(gtx)
+	})
+
 	listStyle := material.List(th, p.list)
 	listStyle.AnchorStrategy = material.Overlay
 
@@ -137,3 +527,102 @@ func (p *PageReceiveForm) Layout(gtx layout.Context, th *material.Theme) layout.
 		}.Layout(gtx, widgets[index])
 	})
 }
+
+func invoiceTitle(invoice wallet_manager.Invoice) string {
+	title := lang.Translate("Any amount")
+	if invoice.Amount > 0 {
+		title = fmt.Sprintf("%s DERO", globals.FormatMoney(invoice.Amount))
+	}
+
+	if invoice.Comment != "" {
+		title = fmt.Sprintf("%s - %s", title, invoice.Comment)
+	}
+
+	return title
+}
+
+type InvoiceListItem struct {
+	invoice      wallet_manager.Invoice
+	clickable    *widget.Clickable
+	buttonDelete *components.Button
+}
+
+func NewInvoiceListItem(invoice wallet_manager.Invoice) *InvoiceListItem {
+	deleteIcon, _ := widget.NewIcon(icons.ActionDelete)
+	buttonDelete := components.NewButton(components.ButtonStyle{
+		Icon:      deleteIcon,
+		Animation: components.NewButtonAnimationScale(.98),
+	})
+
+	return &InvoiceListItem{
+		invoice:      invoice,
+		clickable:    new(widget.Clickable),
+		buttonDelete: buttonDelete,
+	}
+}
+
+func (item *InvoiceListItem) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	status := lang.Translate("Pending")
+	statusColor := theme.Current.TextMuteColor
+	if item.invoice.IsPaid() {
+		status = lang.Translate("Paid")
+		statusColor = theme.Current.NodeStatusDotGreenColor
+	} else if item.invoice.IsExpired() {
+		status = lang.Translate("Expired")
+		statusColor = theme.Current.NodeStatusDotRedColor
+	}
+
+	r := op.Record(gtx.Ops)
+	dims := item.clickable.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Inset{
+			Top: unit.Dp(10), Bottom: unit.Dp(10),
+			Left: unit.Dp(15), Right: unit.Dp(15),
+		}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							lbl := material.Label(th, unit.Sp(18), invoiceTitle(item.invoice))
+							lbl.Font.Weight = font.Bold
+							lbl.MaxLines = 1
+							return lbl.Layout(gtx)
+						}),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							created := time.Unix(item.invoice.Timestamp, 0).Format("2006-01-02 15:04")
+							lbl := material.Label(th, unit.Sp(14), fmt.Sprintf("%s - %s %d", created, lang.Translate("Port"), item.invoice.DstPort))
+							lbl.Color = theme.Current.TextMuteColor
+							return lbl.Layout(gtx)
+						}),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							lbl := material.Label(th, unit.Sp(14), status)
+							lbl.Color = statusColor
+							lbl.Font.Weight = font.Bold
+							return lbl.Layout(gtx)
+						}),
+					)
+				}),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					gtx.Constraints.Min.X = gtx.Dp(25)
+					gtx.Constraints.Min.Y = gtx.Dp(25)
+					item.buttonDelete.Style.Colors = theme.Current.ButtonIconPrimaryColors
+					return item.buttonDelete.Layout(gtx, th)
+				}),
+			)
+		})
+	})
+	c := r.Stop()
+
+	bgColor := theme.Current.ListBgColor
+	if item.clickable.Hovered() {
+		pointer.CursorPointer.Add(gtx.Ops)
+		bgColor = theme.Current.ListItemHoverBgColor
+	}
+
+	paint.FillShape(gtx.Ops, bgColor,
+		clip.UniformRRect(image.Rectangle{Max: dims.Size}, gtx.Dp(10)).Op(gtx.Ops),
+	)
+
+	c.Add(gtx.Ops)
+	return dims
+}
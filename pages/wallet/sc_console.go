@@ -0,0 +1,511 @@
+package page_wallet
+
+import (
+	"fmt"
+	"strings"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/deroproject/derohe/dvm"
+	"github.com/deroproject/derohe/rpc"
+	"github.com/secretsystems/secret-wallet/animation"
+	"github.com/secretsystems/secret-wallet/app_instance"
+	"github.com/secretsystems/secret-wallet/components"
+	"github.com/secretsystems/secret-wallet/containers/build_tx_modal"
+	"github.com/secretsystems/secret-wallet/containers/listselect_modal"
+	"github.com/secretsystems/secret-wallet/containers/notification_modals"
+	"github.com/secretsystems/secret-wallet/lang"
+	"github.com/secretsystems/secret-wallet/prefabs"
+	"github.com/secretsystems/secret-wallet/router"
+	"github.com/secretsystems/secret-wallet/sc"
+	"github.com/secretsystems/secret-wallet/theme"
+	"github.com/secretsystems/secret-wallet/utils"
+	"github.com/secretsystems/secret-wallet/wallet_manager"
+	"github.com/tanema/gween"
+	"github.com/tanema/gween/ease"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+type SCConsoleField struct {
+	param dvm.Variable
+	txt   *prefabs.TextField
+}
+
+type PageSCConsole struct {
+	isActive bool
+
+	animationEnter *animation.Animation
+	animationLeave *animation.Animation
+
+	txtSCID        *prefabs.TextField
+	buttonLoad     *components.Button
+	buttonFunction *components.Button
+	buttonEstimate *components.Button
+	buttonSend     *components.Button
+
+	txtDeroBurn  *prefabs.TextField
+	txtTokenSCID *prefabs.TextField
+	txtTokenBurn *prefabs.TextField
+	paramFields  []*SCConsoleField
+	infoRows     []*prefabs.InfoRow
+
+	scId      string
+	scType    sc.SCType
+	functions []dvm.Function
+	function  *dvm.Function
+	estimate  *rpc.GasEstimate_Result
+
+	list *widget.List
+}
+
+var _ router.Page = &PageSCConsole{}
+
+func NewPageSCConsole() *PageSCConsole {
+	animationEnter := animation.NewAnimation(false, gween.NewSequence(
+		gween.New(1, 0, .25, ease.Linear),
+	))
+
+	animationLeave := animation.NewAnimation(false, gween.NewSequence(
+		gween.New(0, 1, .25, ease.Linear),
+	))
+
+	newButton := func(icon *widget.Icon, loading bool) *components.Button {
+		style := components.ButtonStyle{
+			Rounded:   components.UniformRounded(unit.Dp(5)),
+			Icon:      icon,
+			TextSize:  unit.Sp(14),
+			IconGap:   unit.Dp(10),
+			Inset:     layout.UniformInset(unit.Dp(10)),
+			Animation: components.NewButtonAnimationDefault(),
+		}
+
+		if loading {
+			style.LoadingIcon, _ = widget.NewIcon(icons.NavigationRefresh)
+		}
+
+		button := components.NewButton(style)
+		button.Label.Alignment = text.Middle
+		button.Style.Font.Weight = font.Bold
+		return button
+	}
+
+	loadIcon, _ := widget.NewIcon(icons.ActionSearch)
+	functionIcon, _ := widget.NewIcon(icons.NavigationArrowDropDown)
+	estimateIcon, _ := widget.NewIcon(icons.ActionAssessment)
+	sendIcon, _ := widget.NewIcon(icons.ContentSend)
+
+	list := new(widget.List)
+	list.Axis = layout.Vertical
+
+	return &PageSCConsole{
+		animationEnter: animationEnter,
+		animationLeave: animationLeave,
+
+		txtSCID:        prefabs.NewTextField(),
+		buttonLoad:     newButton(loadIcon, true),
+		buttonFunction: newButton(functionIcon, false),
+		buttonEstimate: newButton(estimateIcon, true),
+		buttonSend:     newButton(sendIcon, true),
+
+		txtDeroBurn:  prefabs.NewNumberTextField(),
+		txtTokenSCID: prefabs.NewTextField(),
+		txtTokenBurn: prefabs.NewNumberTextField(),
+		infoRows:     prefabs.NewInfoRows(3),
+
+		list: list,
+	}
+}
+
+func (p *PageSCConsole) IsActive() bool {
+	return p.isActive
+}
+
+func (p *PageSCConsole) Enter() {
+	p.isActive = true
+
+	if !page_instance.header.IsHistory(PAGE_SC_CONSOLE) {
+		p.animationEnter.Start()
+		p.animationLeave.Reset()
+	}
+
+	page_instance.header.Title = func() string {
+		return lang.Translate("Smart Contract Console")
+	}
+
+	page_instance.header.Subtitle = func(gtx layout.Context, th *material.Theme) layout.Dimensions {
+		if p.scId == "" {
+			return layout.Dimensions{}
+		}
+
+		lbl := material.Label(th, unit.Sp(16), utils.ReduceTxId(p.scId))
+		lbl.Color = theme.Current.TextMuteColor
+		return lbl.Layout(gtx)
+	}
+}
+
+func (p *PageSCConsole) Leave() {
+	p.animationLeave.Start()
+	p.animationEnter.Reset()
+}
+
+// opens the console with a contract already loaded
+func (p *PageSCConsole) SetSCID(scId string) {
+	p.txtSCID.SetValue(scId)
+	go func() {
+		err := p.load()
+		if err != nil {
+			notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+			notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		}
+		app_instance.Window.Invalidate()
+	}()
+}
+
+func (p *PageSCConsole) load() error {
+	scId := strings.TrimSpace(p.txtSCID.Value())
+	if crypto.HashHexToHash(scId).String() != scId {
+		return fmt.Errorf("invalid SCID")
+	}
+
+	result, _, err := wallet_manager.GetSC(scId)
+	if err != nil {
+		return err
+	}
+
+	functions, err := wallet_manager.SCEntrypoints(result.Code)
+	if err != nil {
+		return err
+	}
+
+	if len(functions) == 0 {
+		return fmt.Errorf("the contract has no exported functions")
+	}
+
+	p.scId = scId
+	p.scType = sc.CheckType(result.Code)
+	p.functions = functions
+	p.setFunction(functions[0])
+	return nil
+}
+
+// "value" is filled by the dvm with the DERO burned so it has no field
+func (p *PageSCConsole) setFunction(function dvm.Function) {
+	var fields []*SCConsoleField
+	for _, param := range function.Params {
+		if param.Name == "value" && param.Type == dvm.Uint64 {
+			continue
+		}
+
+		txt := prefabs.NewTextField()
+		if param.Type == dvm.Uint64 {
+			txt = prefabs.NewNumberTextField()
+		}
+
+		fields = append(fields, &SCConsoleField{param: param, txt: txt})
+	}
+
+	p.function = &function
+	p.paramFields = fields
+	p.estimate = nil
+}
+
+func scParamTypeName(paramType dvm.Vtype) string {
+	switch paramType {
+	case dvm.Uint64:
+		return "Uint64"
+	case dvm.String:
+		return "String"
+	}
+
+	return "?"
+}
+
+func (p *PageSCConsole) hasValueParam() bool {
+	if p.function == nil {
+		return false
+	}
+
+	for _, param := range p.function.Params {
+		if param.Name == "value" && param.Type == dvm.Uint64 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *PageSCConsole) payload() (transfers []rpc.Transfer, scArgs rpc.Arguments, tokens []*wallet_manager.Token, err error) {
+	if p.function == nil {
+		err = fmt.Errorf("no function selected")
+		return
+	}
+
+	var burns []wallet_manager.SCBurn
+	deroBurn := strings.TrimSpace(p.txtDeroBurn.Value())
+	if deroBurn != "" {
+		amount := utils.ShiftNumber{Decimals: 5}
+		err = amount.Parse(deroBurn)
+		if err != nil {
+			err = fmt.Errorf("invalid DERO amount")
+			return
+		}
+
+		burns = append(burns, wallet_manager.SCBurn{SCID: crypto.ZEROHASH.String(), Amount: amount.Number})
+		tokens = append(tokens, wallet_manager.DeroToken())
+	}
+
+	tokenId := strings.TrimSpace(p.txtTokenSCID.Value())
+	if tokenId != "" {
+		var token *wallet_manager.Token
+		token, err = wallet_manager.GetTokenBySCID(tokenId)
+		if err != nil {
+			return
+		}
+
+		amount := utils.ShiftNumber{Decimals: int(token.Decimals)}
+		err = amount.Parse(strings.TrimSpace(p.txtTokenBurn.Value()))
+		if err != nil {
+			err = fmt.Errorf("invalid token amount")
+			return
+		}
+
+		burns = append(burns, wallet_manager.SCBurn{SCID: tokenId, Amount: amount.Number})
+		tokens = append(tokens, token)
+	}
+
+	// the ring members must exist for the burned asset
+	ringAsset := crypto.ZEROHASH
+	if tokenId != "" && len(burns) > 0 {
+		ringAsset = crypto.HashHexToHash(tokenId)
+	}
+
+	wallet := wallet_manager.OpenedWallet
+	randomAddr, err := wallet.GetRandomAddress(ringAsset)
+	if err != nil {
+		return
+	}
+
+	values := make(map[string]interface{})
+	for _, field := range p.paramFields {
+		values[field.param.Name] = field.txt.Value()
+	}
+
+	transfers, scArgs, err = wallet_manager.SCCallPayload(p.scId, *p.function, values, burns, randomAddr)
+	return
+}
+
+func (p *PageSCConsole) dryRun() (transfers []rpc.Transfer, scArgs rpc.Arguments, tokens []*wallet_manager.Token, err error) {
+	transfers, scArgs, tokens, err = p.payload()
+	if err != nil {
+		return
+	}
+
+	wallet := wallet_manager.OpenedWallet
+	estimate, err := wallet.GasEstimate(transfers, 2, scArgs)
+	if err != nil {
+		p.estimate = nil
+		err = fmt.Errorf("dry run failed: %s", err)
+		return
+	}
+
+	p.estimate = &estimate
+	return
+}
+
+func (p *PageSCConsole) submit() error {
+	transfers, scArgs, tokens, err := p.dryRun()
+	if err != nil {
+		return err
+	}
+
+	build_tx_modal.Instance.Open(build_tx_modal.TxPayload{
+		Transfers:  transfers,
+		Ringsize:   2,
+		SCArgs:     scArgs,
+		TokensInfo: tokens,
+	})
+
+	return nil
+}
+
+func (p *PageSCConsole) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	{
+		state := p.animationEnter.Update(gtx)
+		if state.Active {
+			defer animation.TransformX(gtx, state.Value).Push(gtx.Ops).Pop()
+		}
+	}
+
+	{
+		state := p.animationLeave.Update(gtx)
+		if state.Active {
+			defer animation.TransformX(gtx, state.Value).Push(gtx.Ops).Pop()
+		}
+
+		if state.Finished {
+			p.isActive = false
+			op.InvalidateOp{}.Add(gtx.Ops)
+		}
+	}
+
+	if p.buttonLoad.Clicked() {
+		go func() {
+			p.buttonLoad.SetLoading(true)
+			err := p.load()
+			p.buttonLoad.SetLoading(false)
+			if err != nil {
+				notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+				notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+			}
+			app_instance.Window.Invalidate()
+		}()
+	}
+
+	if p.buttonFunction.Clicked() {
+		go func() {
+			var items []*listselect_modal.SelectListItem
+			for _, function := range p.functions {
+				items = append(items, listselect_modal.NewSelectListItem(function.Name,
+					listselect_modal.NewItemText(nil, function.Name).Layout,
+				))
+			}
+
+			keyChan := listselect_modal.Instance.Open(items)
+			for sKey := range keyChan {
+				for _, function := range p.functions {
+					if function.Name == sKey {
+						p.setFunction(function)
+					}
+				}
+				app_instance.Window.Invalidate()
+			}
+		}()
+	}
+
+	if p.buttonEstimate.Clicked() {
+		go func() {
+			p.buttonEstimate.SetLoading(true)
+			_, _, _, err := p.dryRun()
+			p.buttonEstimate.SetLoading(false)
+			if err != nil {
+				notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+				notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+			}
+			app_instance.Window.Invalidate()
+		}()
+	}
+
+	if p.buttonSend.Clicked() {
+		go func() {
+			p.buttonSend.SetLoading(true)
+			err := p.submit()
+			p.buttonSend.SetLoading(false)
+			if err != nil {
+				notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+				notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+			}
+			app_instance.Window.Invalidate()
+		}()
+	}
+
+	var widgets []layout.Widget
+
+	widgets = append(widgets,
+		func(gtx layout.Context) layout.Dimensions {
+			return p.txtSCID.Layout(gtx, th, lang.Translate("Smart Contract ID"), "")
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonLoad.Text = lang.Translate("LOAD CONTRACT")
+			p.buttonLoad.Style.Colors = theme.Current.ButtonPrimaryColors
+			return p.buttonLoad.Layout(gtx, th)
+		},
+	)
+
+	if p.function != nil {
+		widgets = append(widgets,
+			func(gtx layout.Context) layout.Dimensions {
+				return prefabs.Divider(gtx, 5)
+			},
+			func(gtx layout.Context) layout.Dimensions {
+				return p.infoRows[0].Layout(gtx, th, lang.Translate("Contract type"), string(p.scType))
+			},
+			func(gtx layout.Context) layout.Dimensions {
+				p.buttonFunction.Text = p.function.Name
+				p.buttonFunction.Style.Colors = theme.Current.ButtonSecondaryColors
+				return p.buttonFunction.Layout(gtx, th)
+			},
+		)
+
+		for i := range p.paramFields {
+			field := p.paramFields[i]
+			widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+				return field.txt.Layout(gtx, th, fmt.Sprintf("%s (%s)", field.param.Name, scParamTypeName(field.param.Type)), "")
+			})
+		}
+
+		if p.hasValueParam() {
+			widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+				lbl := material.Label(th, unit.Sp(14), lang.Translate("The value parameter is filled with the DERO burned."))
+				lbl.Color = theme.Current.TextMuteColor
+				return lbl.Layout(gtx)
+			})
+		}
+
+		widgets = append(widgets,
+			func(gtx layout.Context) layout.Dimensions {
+				return p.txtDeroBurn.Layout(gtx, th, lang.Translate("DERO to burn"), lang.Translate("Optional"))
+			},
+			func(gtx layout.Context) layout.Dimensions {
+				return p.txtTokenSCID.Layout(gtx, th, lang.Translate("Token to burn"), lang.Translate("Optional token SCID"))
+			},
+			func(gtx layout.Context) layout.Dimensions {
+				return p.txtTokenBurn.Layout(gtx, th, lang.Translate("Token amount"), "")
+			},
+			func(gtx layout.Context) layout.Dimensions {
+				p.buttonEstimate.Text = lang.Translate("ESTIMATE GAS")
+				p.buttonEstimate.Style.Colors = theme.Current.ButtonSecondaryColors
+				return p.buttonEstimate.Layout(gtx, th)
+			},
+		)
+
+		if p.estimate != nil {
+			estimate := *p.estimate
+			widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return p.infoRows[1].Layout(gtx, th, lang.Translate("Gas compute"), fmt.Sprint(estimate.GasCompute))
+					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return p.infoRows[2].Layout(gtx, th, lang.Translate("Gas storage"), fmt.Sprint(estimate.GasStorage))
+					}),
+				)
+			})
+		}
+
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			p.buttonSend.Text = lang.Translate("SEND")
+			p.buttonSend.Style.Colors = theme.Current.ButtonPrimaryColors
+			return p.buttonSend.Layout(gtx, th)
+		})
+	}
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		return layout.Spacer{Height: unit.Dp(30)}.Layout(gtx)
+	})
+
+	listStyle := material.List(th, p.list)
+	listStyle.AnchorStrategy = material.Overlay
+
+	return listStyle.Layout(gtx, len(widgets), func(gtx layout.Context, index int) layout.Dimensions {
+		return layout.Inset{
+			Top: unit.Dp(0), Bottom: unit.Dp(20),
+			Left: unit.Dp(30), Right: unit.Dp(30),
+		}.Layout(gtx, widgets[index])
+	})
+}
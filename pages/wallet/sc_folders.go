@@ -211,6 +211,7 @@ func (p *PageSCFolders) Layout(gtx layout.Context, th *material.Theme) layout.Di
 		go func() {
 			addIcon, _ := widget.NewIcon(icons.ActionNoteAdd)
 			scanIcon, _ := widget.NewIcon(icons.ActionSearch)
+			mintIcon, _ := widget.NewIcon(icons.ImagePalette)
 			folderIcon, _ := widget.NewIcon(icons.FileCreateNewFolder)
 			editIcon, _ := widget.NewIcon(icons.EditorBorderColor)
 			listIcon, _ := widget.NewIcon(icons.ActionList)
@@ -228,6 +229,12 @@ func (p *PageSCFolders) Layout(gtx layout.Context, th *material.Theme) layout.Di
 				listselect_modal.NewItemText(scanIcon, lang.Translate("Scan collection")).Layout,
 			))
 
+			if !wallet_manager.OpenedWallet.IsWatchOnly() {
+				items = append(items, listselect_modal.NewSelectListItem("mint_token",
+					listselect_modal.NewItemText(mintIcon, lang.Translate("Mint token")).Layout,
+				))
+			}
+
 			items = append(items, listselect_modal.NewSelectListItem("new_folder",
 				listselect_modal.NewItemText(folderIcon, lang.Translate("New folder")).Layout,
 			))
@@ -273,6 +280,9 @@ func (p *PageSCFolders) Layout(gtx layout.Context, th *material.Theme) layout.Di
 				case "scan_collection":
 					page_instance.pageRouter.SetCurrent(PAGE_SCAN_COLLECTION)
 					page_instance.header.AddHistory(PAGE_SCAN_COLLECTION)
+				case "mint_token":
+					page_instance.pageRouter.SetCurrent(PAGE_G45_MINT)
+					page_instance.header.AddHistory(PAGE_G45_MINT)
 				case "new_folder":
 					wallet := wallet_manager.OpenedWallet
 					currentFolder := page_instance.pageSCFolders.currentFolder
@@ -0,0 +1,604 @@
+package page_wallet
+
+import (
+	"fmt"
+	"image"
+	"strings"
+	"sync"
+	"time"
+
+	"gioui.org/font"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/deroproject/derohe/dvm"
+	"github.com/deroproject/derohe/rpc"
+	"github.com/secretsystems/secret-wallet/animation"
+	"github.com/secretsystems/secret-wallet/app_instance"
+	"github.com/secretsystems/secret-wallet/components"
+	"github.com/secretsystems/secret-wallet/containers/build_tx_modal"
+	"github.com/secretsystems/secret-wallet/containers/confirm_modal"
+	"github.com/secretsystems/secret-wallet/containers/listselect_modal"
+	"github.com/secretsystems/secret-wallet/containers/notification_modals"
+	"github.com/secretsystems/secret-wallet/lang"
+	"github.com/secretsystems/secret-wallet/prefabs"
+	"github.com/secretsystems/secret-wallet/router"
+	"github.com/secretsystems/secret-wallet/sc"
+	"github.com/secretsystems/secret-wallet/theme"
+	"github.com/secretsystems/secret-wallet/utils"
+	"github.com/secretsystems/secret-wallet/wallet_manager"
+	"github.com/tanema/gween"
+	"github.com/tanema/gween/ease"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+type PageSCInstall struct {
+	isActive bool
+
+	animationEnter *animation.Animation
+	animationLeave *animation.Animation
+
+	buttonLoadFile *components.Button
+	buttonEstimate *components.Button
+	buttonInstall  *components.Button
+
+	txtName     *prefabs.TextField
+	paramFields []*SCConsoleField
+	infoRows    []*prefabs.InfoRow
+
+	code       string
+	scType     sc.SCType
+	function   *dvm.Function
+	estimate   *rpc.GasEstimate_Result
+	installing bool
+
+	contractItems []*MyContractItem
+	contractsLock sync.Mutex
+
+	list *widget.List
+}
+
+var _ router.Page = &PageSCInstall{}
+
+func NewPageSCInstall() *PageSCInstall {
+	animationEnter := animation.NewAnimation(false, gween.NewSequence(
+		gween.New(1, 0, .25, ease.Linear),
+	))
+
+	animationLeave := animation.NewAnimation(false, gween.NewSequence(
+		gween.New(0, 1, .25, ease.Linear),
+	))
+
+	newButton := func(icon *widget.Icon, loading bool) *components.Button {
+		style := components.ButtonStyle{
+			Rounded:   components.UniformRounded(unit.Dp(5)),
+			Icon:      icon,
+			TextSize:  unit.Sp(14),
+			IconGap:   unit.Dp(10),
+			Inset:     layout.UniformInset(unit.Dp(10)),
+			Animation: components.NewButtonAnimationDefault(),
+		}
+
+		if loading {
+			style.LoadingIcon, _ = widget.NewIcon(icons.NavigationRefresh)
+		}
+
+		button := components.NewButton(style)
+		button.Label.Alignment = text.Middle
+		button.Style.Font.Weight = font.Bold
+		return button
+	}
+
+	fileIcon, _ := widget.NewIcon(icons.FileFolderOpen)
+	estimateIcon, _ := widget.NewIcon(icons.ActionAssessment)
+	installIcon, _ := widget.NewIcon(icons.FileCloudUpload)
+
+	list := new(widget.List)
+	list.Axis = layout.Vertical
+
+	return &PageSCInstall{
+		animationEnter: animationEnter,
+		animationLeave: animationLeave,
+
+		buttonLoadFile: newButton(fileIcon, false),
+		buttonEstimate: newButton(estimateIcon, true),
+		buttonInstall:  newButton(installIcon, true),
+
+		txtName:  prefabs.NewTextField(),
+		infoRows: prefabs.NewInfoRows(5),
+
+		list: list,
+	}
+}
+
+func (p *PageSCInstall) IsActive() bool {
+	return p.isActive
+}
+
+func (p *PageSCInstall) Enter() {
+	p.isActive = true
+
+	if !page_instance.header.IsHistory(PAGE_SC_INSTALL) {
+		p.animationEnter.Start()
+		p.animationLeave.Reset()
+	}
+
+	page_instance.header.Title = func() string {
+		return lang.Translate("My Contracts")
+	}
+
+	page_instance.header.Subtitle = nil
+	go p.loadContracts()
+}
+
+func (p *PageSCInstall) Leave() {
+	p.animationLeave.Start()
+	p.animationEnter.Reset()
+}
+
+func (p *PageSCInstall) loadContracts() {
+	wallet := wallet_manager.OpenedWallet
+	contracts, err := wallet.GetMyContracts()
+	if err != nil {
+		notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+		notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		return
+	}
+
+	var items []*MyContractItem
+	for _, contract := range contracts {
+		items = append(items, NewMyContractItem(contract))
+	}
+
+	p.contractsLock.Lock()
+	p.contractItems = items
+	p.contractsLock.Unlock()
+	app_instance.Window.Invalidate()
+}
+
+func (p *PageSCInstall) loadFile() error {
+	file, err := app_instance.Explorer.ChooseFile(".bas")
+	if err != nil {
+		return err
+	}
+
+	reader := utils.ReadCloser{ReadCloser: file}
+	data, err := reader.ReadAll()
+	if err != nil {
+		return err
+	}
+
+	code := string(data)
+	function, err := wallet_manager.SCInstallFunction(code)
+	if err != nil {
+		return err
+	}
+
+	var fields []*SCConsoleField
+	if function != nil {
+		for _, param := range function.Params {
+			if param.Name == "value" && param.Type == dvm.Uint64 {
+				continue
+			}
+
+			txt := prefabs.NewTextField()
+			if param.Type == dvm.Uint64 {
+				txt = prefabs.NewNumberTextField()
+			}
+
+			fields = append(fields, &SCConsoleField{param: param, txt: txt})
+		}
+	}
+
+	p.code = code
+	p.scType = sc.CheckType(code)
+	p.function = function
+	p.paramFields = fields
+	p.estimate = nil
+	return nil
+}
+
+func (p *PageSCInstall) dryRun() (scArgs rpc.Arguments, err error) {
+	if p.code == "" {
+		err = fmt.Errorf("no contract loaded")
+		return
+	}
+
+	values := make(map[string]interface{})
+	for _, field := range p.paramFields {
+		values[field.param.Name] = field.txt.Value()
+	}
+
+	scArgs, err = wallet_manager.SCInstallArgs(p.code, values)
+	if err != nil {
+		return
+	}
+
+	wallet := wallet_manager.OpenedWallet
+	estimate, err := wallet.GasEstimate(nil, 2, scArgs)
+	if err != nil {
+		p.estimate = nil
+		err = fmt.Errorf("dry run failed: %s", err)
+		return
+	}
+
+	p.estimate = &estimate
+	return
+}
+
+func (p *PageSCInstall) submit() error {
+	scArgs, err := p.dryRun()
+	if err != nil {
+		return err
+	}
+
+	p.installing = true
+	build_tx_modal.Instance.Open(build_tx_modal.TxPayload{
+		Ringsize: 2,
+		SCArgs:   scArgs,
+	})
+
+	return nil
+}
+
+// the contract id is the install txid - it can be opened as soon as the transaction is mined
+func (p *PageSCInstall) onTxSent() error {
+	tx := build_tx_modal.Instance.SentTx()
+	if !p.installing || tx == nil {
+		return nil
+	}
+
+	p.installing = false
+	scId := tx.GetHash().String()
+	name := strings.TrimSpace(p.txtName.Value())
+
+	wallet := wallet_manager.OpenedWallet
+	err := wallet.StoreMyContract(wallet_manager.NewMyContract(scId, name))
+	if err != nil {
+		return err
+	}
+
+	p.code = ""
+	p.function = nil
+	p.paramFields = nil
+	p.estimate = nil
+	p.txtName.SetValue("")
+	go p.loadContracts()
+
+	notification_modals.SuccessInstance.SetText(lang.Translate("Success"), lang.Translate("The contract will be available once the transaction is confirmed."))
+	notification_modals.SuccessInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+	return nil
+}
+
+func (p *PageSCInstall) openMenu(contract wallet_manager.MyContract) {
+	consoleIcon, _ := widget.NewIcon(icons.ActionCode)
+	addIcon, _ := widget.NewIcon(icons.ContentAddBox)
+	stateIcon, _ := widget.NewIcon(icons.ActionList)
+	deleteIcon, _ := widget.NewIcon(icons.ActionDelete)
+
+	items := []*listselect_modal.SelectListItem{
+		listselect_modal.NewSelectListItem("sc_console",
+			listselect_modal.NewItemText(consoleIcon, lang.Translate("Interact")).Layout,
+		),
+		listselect_modal.NewSelectListItem("add_token",
+			listselect_modal.NewItemText(addIcon, lang.Translate("Add token")).Layout,
+		),
+		listselect_modal.NewSelectListItem("sc_state",
+			listselect_modal.NewItemText(stateIcon, lang.Translate("Browse variables")).Layout,
+		),
+		listselect_modal.NewSelectListItem("remove",
+			listselect_modal.NewItemText(deleteIcon, lang.Translate("Remove from list")).Layout,
+		),
+	}
+
+	keyChan := listselect_modal.Instance.Open(items)
+	for key := range keyChan {
+		switch key {
+		case "sc_console":
+			page_instance.pageSCConsole.SetSCID(contract.SCID)
+			page_instance.pageRouter.SetCurrent(PAGE_SC_CONSOLE)
+			page_instance.header.AddHistory(PAGE_SC_CONSOLE)
+		case "add_token":
+			page_instance.pageAddSCForm.SetSCID(contract.SCID)
+			page_instance.pageRouter.SetCurrent(PAGE_ADD_SC_FORM)
+			page_instance.header.AddHistory(PAGE_ADD_SC_FORM)
+		case "sc_state":
+			page_instance.pageSCState.SetSCID(contract.SCID)
+			page_instance.pageRouter.SetCurrent(PAGE_SC_STATE)
+			page_instance.header.AddHistory(PAGE_SC_STATE)
+		case "remove":
+			go p.removeContract(contract.SCID)
+		}
+	}
+}
+
+func (p *PageSCInstall) removeContract(scId string) {
+	yesChan := confirm_modal.Instance.Open(confirm_modal.ConfirmText{
+		Prompt: lang.Translate("The contract stays on the blockchain. Remove it from the list?"),
+	})
+
+	for yes := range yesChan {
+		if !yes {
+			continue
+		}
+
+		wallet := wallet_manager.OpenedWallet
+		err := wallet.DelMyContract(scId)
+		if err != nil {
+			notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+			notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+			continue
+		}
+
+		p.loadContracts()
+	}
+}
+
+func (p *PageSCInstall) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	{
+		state := p.animationEnter.Update(gtx)
+		if state.Active {
+			defer animation.TransformX(gtx, state.Value).Push(gtx.Ops).Pop()
+		}
+	}
+
+	{
+		state := p.animationLeave.Update(gtx)
+		if state.Active {
+			defer animation.TransformX(gtx, state.Value).Push(gtx.Ops).Pop()
+		}
+
+		if state.Finished {
+			p.isActive = false
+			op.InvalidateOp{}.Add(gtx.Ops)
+		}
+	}
+
+	if build_tx_modal.Instance.TxSent() {
+		err := p.onTxSent()
+		if err != nil {
+			notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+			notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		}
+	}
+
+	if p.buttonLoadFile.Clicked() {
+		go func() {
+			err := p.loadFile()
+			if err != nil {
+				notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+				notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+			}
+			app_instance.Window.Invalidate()
+		}()
+	}
+
+	if p.buttonEstimate.Clicked() {
+		go func() {
+			p.buttonEstimate.SetLoading(true)
+			_, err := p.dryRun()
+			p.buttonEstimate.SetLoading(false)
+			if err != nil {
+				notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+				notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+			}
+			app_instance.Window.Invalidate()
+		}()
+	}
+
+	if p.buttonInstall.Clicked() {
+		go func() {
+			p.buttonInstall.SetLoading(true)
+			err := p.submit()
+			p.buttonInstall.SetLoading(false)
+			if err != nil {
+				notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+				notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+			}
+			app_instance.Window.Invalidate()
+		}()
+	}
+
+	p.contractsLock.Lock()
+	contractItems := p.contractItems
+	p.contractsLock.Unlock()
+
+	for _, item := range contractItems {
+		if item.clickable.Clicked() {
+			go p.openMenu(item.contract)
+		}
+	}
+
+	var widgets []layout.Widget
+
+	widgets = append(widgets,
+		func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(16), lang.Translate("Install a smart contract from a .bas file. Contracts installed by this wallet are listed below."))
+			lbl.Color = theme.Current.TextMuteColor
+			return lbl.Layout(gtx)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonLoadFile.Text = lang.Translate("LOAD FILE")
+			p.buttonLoadFile.Style.Colors = theme.Current.ButtonSecondaryColors
+			return p.buttonLoadFile.Layout(gtx, th)
+		},
+	)
+
+	if p.code != "" {
+		initName := lang.Translate("None")
+		if p.function != nil {
+			initName = p.function.Name
+		}
+
+		widgets = append(widgets,
+			func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return p.infoRows[0].Layout(gtx, th, lang.Translate("Code size"), utils.FormatBytes(int64(len(p.code))))
+					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return p.infoRows[1].Layout(gtx, th, lang.Translate("Contract type"), string(p.scType))
+					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return p.infoRows[2].Layout(gtx, th, lang.Translate("Initialize function"), initName)
+					}),
+				)
+			},
+			func(gtx layout.Context) layout.Dimensions {
+				return p.txtName.Layout(gtx, th, lang.Translate("Name"), lang.Translate("Only saved in this wallet"))
+			},
+		)
+
+		for i := range p.paramFields {
+			field := p.paramFields[i]
+			widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+				return field.txt.Layout(gtx, th, fmt.Sprintf("%s (%s)", field.param.Name, scParamTypeName(field.param.Type)), "")
+			})
+		}
+
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			p.buttonEstimate.Text = lang.Translate("ESTIMATE GAS")
+			p.buttonEstimate.Style.Colors = theme.Current.ButtonSecondaryColors
+			return p.buttonEstimate.Layout(gtx, th)
+		})
+
+		if p.estimate != nil {
+			estimate := *p.estimate
+			widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return p.infoRows[3].Layout(gtx, th, lang.Translate("Gas compute"), fmt.Sprint(estimate.GasCompute))
+					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return p.infoRows[4].Layout(gtx, th, lang.Translate("Gas storage"), fmt.Sprint(estimate.GasStorage))
+					}),
+				)
+			})
+		}
+
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			p.buttonInstall.Text = lang.Translate("INSTALL")
+			p.buttonInstall.Style.Colors = theme.Current.ButtonPrimaryColors
+			return p.buttonInstall.Layout(gtx, th)
+		})
+	}
+
+	widgets = append(widgets,
+		func(gtx layout.Context) layout.Dimensions {
+			return prefabs.Divider(gtx, unit.Dp(5))
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(18), lang.Translate("Installed"))
+			lbl.Font.Weight = font.Bold
+			return lbl.Layout(gtx)
+		},
+	)
+
+	if len(contractItems) == 0 {
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(16), lang.Translate("You didn't install any contract yet."))
+			lbl.Color = theme.Current.TextMuteColor
+			return lbl.Layout(gtx)
+		})
+	}
+
+	for i := range contractItems {
+		item := contractItems[i]
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			return item.Layout(gtx, th)
+		})
+	}
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		return layout.Spacer{Height: unit.Dp(30)}.Layout(gtx)
+	})
+
+	listStyle := material.List(th, p.list)
+	listStyle.AnchorStrategy = material.Overlay
+
+	return listStyle.Layout(gtx, len(widgets), func(gtx layout.Context, index int) layout.Dimensions {
+		return layout.Inset{
+			Top: unit.Dp(0), Bottom: unit.Dp(20),
+			Left: unit.Dp(30), Right: unit.Dp(30),
+		}.Layout(gtx, widgets[index])
+	})
+}
+
+type MyContractItem struct {
+	contract  wallet_manager.MyContract
+	clickable *widget.Clickable
+}
+
+func NewMyContractItem(contract wallet_manager.MyContract) *MyContractItem {
+	return &MyContractItem{
+		contract:  contract,
+		clickable: new(widget.Clickable),
+	}
+}
+
+func (item *MyContractItem) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	r := op.Record(gtx.Ops)
+	dims := item.clickable.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		r := op.Record(gtx.Ops)
+		dims := layout.UniformInset(unit.Dp(15)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			contract := item.contract
+			name := contract.Name
+			if name == "" {
+				name = lang.Translate("Unnamed contract")
+			}
+
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+						layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+							lbl := material.Label(th, unit.Sp(18), name)
+							lbl.Font.Weight = font.Bold
+							return lbl.Layout(gtx)
+						}),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							date := time.UnixMilli(contract.Timestamp).Format("2006-01-02")
+							lbl := material.Label(th, unit.Sp(14), date)
+							lbl.Color = theme.Current.TextMuteColor
+							return lbl.Layout(gtx)
+						}),
+					)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					lbl := material.Label(th, unit.Sp(14), utils.ReduceTxId(contract.SCID))
+					lbl.Color = theme.Current.TextMuteColor
+					return lbl.Layout(gtx)
+				}),
+			)
+		})
+		c := r.Stop()
+
+		if item.clickable.Hovered() {
+			pointer.CursorPointer.Add(gtx.Ops)
+			paint.FillShape(gtx.Ops, theme.Current.ListItemHoverBgColor,
+				clip.UniformRRect(
+					image.Rectangle{Max: image.Pt(dims.Size.X, dims.Size.Y)},
+					gtx.Dp(10),
+				).Op(gtx.Ops),
+			)
+		}
+
+		c.Add(gtx.Ops)
+		return dims
+	})
+	c := r.Stop()
+
+	paint.FillShape(gtx.Ops, theme.Current.ListBgColor,
+		clip.UniformRRect(
+			image.Rectangle{Max: dims.Size},
+			gtx.Dp(10),
+		).Op(gtx.Ops))
+
+	c.Add(gtx.Ops)
+	return dims
+}
@@ -0,0 +1,463 @@
+package page_wallet
+
+import (
+	"fmt"
+	"image"
+	"strings"
+	"sync"
+
+	"gioui.org/font"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/secretsystems/secret-wallet/animation"
+	"github.com/secretsystems/secret-wallet/app_instance"
+	"github.com/secretsystems/secret-wallet/components"
+	"github.com/secretsystems/secret-wallet/containers/listselect_modal"
+	"github.com/secretsystems/secret-wallet/containers/notification_modals"
+	"github.com/secretsystems/secret-wallet/lang"
+	"github.com/secretsystems/secret-wallet/prefabs"
+	"github.com/secretsystems/secret-wallet/router"
+	"github.com/secretsystems/secret-wallet/theme"
+	"github.com/secretsystems/secret-wallet/utils"
+	"github.com/secretsystems/secret-wallet/wallet_manager"
+	"github.com/tanema/gween"
+	"github.com/tanema/gween/ease"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+const SC_STATE_PAGE_SIZE = 25
+
+type PageSCState struct {
+	isActive bool
+
+	animationEnter *animation.Animation
+	animationLeave *animation.Animation
+
+	txtSCID         *prefabs.TextField
+	txtSearch       *prefabs.TextField
+	buttonLoad      *components.Button
+	buttonWatchlist *components.Button
+	buttonPrev      *components.Button
+	buttonNext      *components.Button
+	infoRows        []*prefabs.InfoRow
+
+	scId      string
+	variables []wallet_manager.SCVariable
+	lock      sync.Mutex
+
+	search    string
+	page      int
+	pageCount int
+	items     []*SCVariableItem
+
+	list *widget.List
+}
+
+var _ router.Page = &PageSCState{}
+
+func NewPageSCState() *PageSCState {
+	animationEnter := animation.NewAnimation(false, gween.NewSequence(
+		gween.New(1, 0, .25, ease.Linear),
+	))
+
+	animationLeave := animation.NewAnimation(false, gween.NewSequence(
+		gween.New(0, 1, .25, ease.Linear),
+	))
+
+	newButton := func(icon *widget.Icon, loading bool) *components.Button {
+		style := components.ButtonStyle{
+			Rounded:   components.UniformRounded(unit.Dp(5)),
+			Icon:      icon,
+			TextSize:  unit.Sp(14),
+			IconGap:   unit.Dp(10),
+			Inset:     layout.UniformInset(unit.Dp(10)),
+			Animation: components.NewButtonAnimationDefault(),
+		}
+
+		if loading {
+			style.LoadingIcon, _ = widget.NewIcon(icons.NavigationRefresh)
+		}
+
+		button := components.NewButton(style)
+		button.Label.Alignment = text.Middle
+		button.Style.Font.Weight = font.Bold
+		return button
+	}
+
+	loadIcon, _ := widget.NewIcon(icons.ActionSearch)
+	watchlistIcon, _ := widget.NewIcon(icons.ActionVisibility)
+	prevIcon, _ := widget.NewIcon(icons.NavigationChevronLeft)
+	nextIcon, _ := widget.NewIcon(icons.NavigationChevronRight)
+
+	list := new(widget.List)
+	list.Axis = layout.Vertical
+
+	return &PageSCState{
+		animationEnter: animationEnter,
+		animationLeave: animationLeave,
+
+		txtSCID:         prefabs.NewTextField(),
+		txtSearch:       prefabs.NewTextField(),
+		buttonLoad:      newButton(loadIcon, true),
+		buttonWatchlist: newButton(watchlistIcon, false),
+		buttonPrev:      newButton(prevIcon, false),
+		buttonNext:      newButton(nextIcon, false),
+		infoRows:        prefabs.NewInfoRows(1),
+
+		list: list,
+	}
+}
+
+func (p *PageSCState) IsActive() bool {
+	return p.isActive
+}
+
+func (p *PageSCState) Enter() {
+	p.isActive = true
+
+	if !page_instance.header.IsHistory(PAGE_SC_STATE) {
+		p.animationEnter.Start()
+		p.animationLeave.Reset()
+	}
+
+	page_instance.header.Title = func() string {
+		return lang.Translate("Contract State")
+	}
+
+	page_instance.header.Subtitle = func(gtx layout.Context, th *material.Theme) layout.Dimensions {
+		if p.scId == "" {
+			return layout.Dimensions{}
+		}
+
+		lbl := material.Label(th, unit.Sp(16), utils.ReduceTxId(p.scId))
+		lbl.Color = theme.Current.TextMuteColor
+		return lbl.Layout(gtx)
+	}
+}
+
+func (p *PageSCState) Leave() {
+	p.animationLeave.Start()
+	p.animationEnter.Reset()
+}
+
+// opens the browser with the variables of a contract already loaded
+func (p *PageSCState) SetSCID(scId string) {
+	p.txtSCID.SetValue(scId)
+	go func() {
+		err := p.load()
+		if err != nil {
+			notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+			notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		}
+		app_instance.Window.Invalidate()
+	}()
+}
+
+func (p *PageSCState) load() error {
+	scId := strings.TrimSpace(p.txtSCID.Value())
+	if crypto.HashHexToHash(scId).String() != scId {
+		return fmt.Errorf("invalid SCID")
+	}
+
+	variables, err := wallet_manager.GetSCVariables(scId)
+	if err != nil {
+		return err
+	}
+
+	p.lock.Lock()
+	p.scId = scId
+	p.variables = variables
+	p.lock.Unlock()
+	p.setPage(0)
+	return nil
+}
+
+// decoding addresses is not free so the page is filtered only when the search or the page changes
+func (p *PageSCState) setPage(page int) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	search := p.txtSearch.Value()
+	variables, page, pageCount := wallet_manager.FilterSCVariables(p.variables, search, page, SC_STATE_PAGE_SIZE)
+
+	var items []*SCVariableItem
+	for _, variable := range variables {
+		items = append(items, NewSCVariableItem(variable))
+	}
+
+	p.search = search
+	p.page = page
+	p.pageCount = pageCount
+	p.items = items
+}
+
+func (p *PageSCState) openMenu(variable wallet_manager.SCVariable) {
+	watchIcon, _ := widget.NewIcon(icons.ActionVisibility)
+
+	items := []*listselect_modal.SelectListItem{
+		listselect_modal.NewSelectListItem("watch",
+			listselect_modal.NewItemText(watchIcon, lang.Translate("Add to watchlist")).Layout,
+		),
+	}
+
+	keyChan := listselect_modal.Instance.Open(items)
+	for key := range keyChan {
+		switch key {
+		case "watch":
+			wallet := wallet_manager.OpenedWallet
+			err := wallet.AddSCWatch(p.scId, variable.Key, variable.Uint64Key)
+			if err == nil {
+				_, err = wallet.PollSCWatchlist()
+			}
+
+			if err != nil {
+				notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+				notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+				continue
+			}
+
+			notification_modals.SuccessInstance.SetText(lang.Translate("Success"), lang.Translate("Variable added to the watchlist."))
+			notification_modals.SuccessInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		}
+	}
+}
+
+func (p *PageSCState) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	{
+		state := p.animationEnter.Update(gtx)
+		if state.Active {
+			defer animation.TransformX(gtx, state.Value).Push(gtx.Ops).Pop()
+		}
+	}
+
+	{
+		state := p.animationLeave.Update(gtx)
+		if state.Active {
+			defer animation.TransformX(gtx, state.Value).Push(gtx.Ops).Pop()
+		}
+
+		if state.Finished {
+			p.isActive = false
+			op.InvalidateOp{}.Add(gtx.Ops)
+		}
+	}
+
+	if p.buttonLoad.Clicked() {
+		go func() {
+			p.buttonLoad.SetLoading(true)
+			err := p.load()
+			p.buttonLoad.SetLoading(false)
+			if err != nil {
+				notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+				notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+			}
+			app_instance.Window.Invalidate()
+		}()
+	}
+
+	if p.buttonWatchlist.Clicked() {
+		page_instance.pageRouter.SetCurrent(PAGE_SC_WATCHLIST)
+		page_instance.header.AddHistory(PAGE_SC_WATCHLIST)
+	}
+
+	if p.txtSearch.Value() != p.search {
+		p.setPage(0)
+	}
+
+	if p.buttonPrev.Clicked() && p.page > 0 {
+		p.setPage(p.page - 1)
+	}
+
+	if p.buttonNext.Clicked() && p.page < p.pageCount-1 {
+		p.setPage(p.page + 1)
+	}
+
+	p.lock.Lock()
+	items := p.items
+	variableCount := len(p.variables)
+	p.lock.Unlock()
+
+	for _, item := range items {
+		if item.clickable.Clicked() {
+			go p.openMenu(item.variable)
+		}
+	}
+
+	var widgets []layout.Widget
+
+	widgets = append(widgets,
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonWatchlist.Text = lang.Translate("WATCHLIST")
+			p.buttonWatchlist.Style.Colors = theme.Current.ButtonSecondaryColors
+			return p.buttonWatchlist.Layout(gtx, th)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			return p.txtSCID.Layout(gtx, th, lang.Translate("Smart Contract ID"), "")
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonLoad.Text = lang.Translate("LOAD VARIABLES")
+			p.buttonLoad.Style.Colors = theme.Current.ButtonPrimaryColors
+			return p.buttonLoad.Layout(gtx, th)
+		},
+	)
+
+	if p.scId != "" {
+		widgets = append(widgets,
+			func(gtx layout.Context) layout.Dimensions {
+				return prefabs.Divider(gtx, 5)
+			},
+			func(gtx layout.Context) layout.Dimensions {
+				return p.infoRows[0].Layout(gtx, th, lang.Translate("Variables"), fmt.Sprint(variableCount))
+			},
+			func(gtx layout.Context) layout.Dimensions {
+				return p.txtSearch.Layout(gtx, th, lang.Translate("Search"), lang.Translate("Key or value"))
+			},
+		)
+
+		if len(items) == 0 {
+			widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+				lbl := material.Label(th, unit.Sp(16), lang.Translate("No variables found."))
+				lbl.Color = theme.Current.TextMuteColor
+				return lbl.Layout(gtx)
+			})
+		}
+
+		for i := range items {
+			item := items[i]
+			widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+				return item.Layout(gtx, th)
+			})
+		}
+
+		if p.pageCount > 1 {
+			widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						p.buttonPrev.Text = lang.Translate("PREV")
+						p.buttonPrev.Style.Colors = theme.Current.ButtonSecondaryColors
+						return p.buttonPrev.Layout(gtx, th)
+					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return layout.Inset{Left: unit.Dp(10), Right: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+							lbl := material.Label(th, unit.Sp(16), fmt.Sprintf("%d / %d", p.page+1, p.pageCount))
+							return lbl.Layout(gtx)
+						})
+					}),
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						p.buttonNext.Text = lang.Translate("NEXT")
+						p.buttonNext.Style.Colors = theme.Current.ButtonSecondaryColors
+						return p.buttonNext.Layout(gtx, th)
+					}),
+				)
+			})
+		}
+	}
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		return layout.Spacer{Height: unit.Dp(30)}.Layout(gtx)
+	})
+
+	listStyle := material.List(th, p.list)
+	listStyle.AnchorStrategy = material.Overlay
+
+	return listStyle.Layout(gtx, len(widgets), func(gtx layout.Context, index int) layout.Dimensions {
+		return layout.Inset{
+			Top: unit.Dp(0), Bottom: unit.Dp(20),
+			Left: unit.Dp(30), Right: unit.Dp(30),
+		}.Layout(gtx, widgets[index])
+	})
+}
+
+func scVariableTypeName(uint64Type bool) string {
+	if uint64Type {
+		return "Uint64"
+	}
+
+	return "String"
+}
+
+type SCVariableItem struct {
+	variable  wallet_manager.SCVariable
+	decoded   string
+	clickable *widget.Clickable
+}
+
+func NewSCVariableItem(variable wallet_manager.SCVariable) *SCVariableItem {
+	return &SCVariableItem{
+		variable:  variable,
+		decoded:   variable.Decoded(),
+		clickable: new(widget.Clickable),
+	}
+}
+
+func (item *SCVariableItem) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	r := op.Record(gtx.Ops)
+	dims := item.clickable.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		r := op.Record(gtx.Ops)
+		dims := layout.UniformInset(unit.Dp(15)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			variable := item.variable
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+						layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+							lbl := material.Label(th, unit.Sp(16), variable.Key)
+							lbl.Font.Weight = font.Bold
+							return lbl.Layout(gtx)
+						}),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							types := fmt.Sprintf("%s → %s", scVariableTypeName(variable.Uint64Key), scVariableTypeName(variable.Uint64Value))
+							lbl := material.Label(th, unit.Sp(14), types)
+							lbl.Color = theme.Current.TextMuteColor
+							return lbl.Layout(gtx)
+						}),
+					)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					lbl := material.Label(th, unit.Sp(14), item.decoded)
+					return lbl.Layout(gtx)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					if variable.Uint64Value || item.decoded == variable.Value {
+						return layout.Dimensions{}
+					}
+
+					lbl := material.Label(th, unit.Sp(12), variable.Value)
+					lbl.Color = theme.Current.TextMuteColor
+					return lbl.Layout(gtx)
+				}),
+			)
+		})
+		c := r.Stop()
+
+		if item.clickable.Hovered() {
+			pointer.CursorPointer.Add(gtx.Ops)
+			paint.FillShape(gtx.Ops, theme.Current.ListItemHoverBgColor,
+				clip.UniformRRect(
+					image.Rectangle{Max: image.Pt(dims.Size.X, dims.Size.Y)},
+					gtx.Dp(10),
+				).Op(gtx.Ops),
+			)
+		}
+
+		c.Add(gtx.Ops)
+		return dims
+	})
+	c := r.Stop()
+
+	paint.FillShape(gtx.Ops, theme.Current.ListBgColor,
+		clip.UniformRRect(
+			image.Rectangle{Max: dims.Size},
+			gtx.Dp(10),
+		).Op(gtx.Ops))
+
+	c.Add(gtx.Ops)
+	return dims
+}
@@ -51,7 +51,6 @@ type PageSCToken struct {
 	sendReceiveButtons  *SendReceiveButtons
 	tabBars             *components.TabBars
 	txBar               *TxBar
-	getEntriesParams    wallet_manager.GetEntriesParams
 	txItems             []*TxListItem
 	tokenInfo           *TokenInfoList
 	balanceContainer    *BalanceContainer
@@ -179,10 +178,18 @@ func (p *PageSCToken) Leave() {
 	p.animationEnter.Reset()
 }
 
-func (p *PageSCToken) LoadTxs() {
+func (p *PageSCToken) LoadTxs() error {
 	wallet := wallet_manager.OpenedWallet
 	hash := p.token.GetHash()
-	entries := wallet.GetEntries(&hash, p.getEntriesParams)
+	params, err := p.txBar.EntriesParams()
+	if err != nil {
+		return err
+	}
+
+	entries, err := wallet.GetEntries(&hash, params)
+	if err != nil {
+		return err
+	}
 
 	txItems := []*TxListItem{}
 
@@ -192,15 +199,23 @@ func (p *PageSCToken) LoadTxs() {
 
 	p.txItems = txItems
 	p.txBar.txCount = len(entries)
+	return nil
 }
 
 func (p *PageSCToken) SetToken(token *wallet_manager.Token) {
 	p.token = token
 	p.token.RefreshImageOp()
+	p.txBar.decimals = int(token.Decimals)
+	p.txBar.ResetFilter()
 	p.balanceContainer.SetToken(p.token)
 	p.g45DisplayContainer.SetToken(p.token)
 	p.g45DisplayContainer.Load()
-	p.LoadTxs()
+
+	err := p.LoadTxs()
+	if err != nil {
+		notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+		notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+	}
 }
 
 func (p *PageSCToken) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
@@ -233,6 +248,9 @@ func (p *PageSCToken) Layout(gtx layout.Context, th *material.Theme) layout.Dime
 			//editIcon, _ := widget.NewIcon(icons.ActionInput)
 			deleteIcon, _ := widget.NewIcon(icons.ActionDelete)
 			ethereumIcon, _ := widget.NewIcon(app_icons.Ethereum)
+			imageIcon, _ := widget.NewIcon(icons.ImagePhotoLibrary)
+			consoleIcon, _ := widget.NewIcon(icons.ActionCode)
+			stateIcon, _ := widget.NewIcon(icons.ActionList)
 
 			var items []*listselect_modal.SelectListItem
 			token := page_instance.pageSCToken.token
@@ -248,6 +266,10 @@ func (p *PageSCToken) Layout(gtx layout.Context, th *material.Theme) layout.Dime
 			}
 
 			if standardType == sc.G45_NFT_TYPE {
+				items = append(items, listselect_modal.NewSelectListItem("nft_details",
+					listselect_modal.NewItemText(imageIcon, lang.Translate("NFT details")).Layout,
+				))
+
 				items = append(items, listselect_modal.NewSelectListItem("g45_display_nft",
 					listselect_modal.NewItemText(showIcon, lang.Translate("Display NFT")).Layout,
 				))
@@ -277,6 +299,14 @@ func (p *PageSCToken) Layout(gtx layout.Context, th *material.Theme) layout.Dime
 				))
 			}
 
+			items = append(items, listselect_modal.NewSelectListItem("sc_console",
+				listselect_modal.NewItemText(consoleIcon, lang.Translate("Interact")).Layout,
+			))
+
+			items = append(items, listselect_modal.NewSelectListItem("sc_state",
+				listselect_modal.NewItemText(stateIcon, lang.Translate("Browse variables")).Layout,
+			))
+
 			items = append(items, listselect_modal.NewSelectListItem("refresh_cache",
 				listselect_modal.NewItemText(refreshIcon, lang.Translate("Refresh cache")).Layout,
 			))
@@ -313,6 +343,8 @@ func (p *PageSCToken) Layout(gtx layout.Context, th *material.Theme) layout.Dime
 				switch sKey {
 				case "refresh_cache":
 					wallet.ResetBalanceResult(p.token.SCID)
+					err = wallet_manager.ClearSCCache(p.token.SCID)
+					p.token.RefreshImageOp()
 					successMsg = lang.Translate("Cache refreshed.")
 				case "add_favorite":
 					p.token.IsFavorite = sql.NullBool{Bool: true, Valid: true}
@@ -413,14 +445,26 @@ func (p *PageSCToken) Layout(gtx layout.Context, th *material.Theme) layout.Dime
 							})
 						})
 					}
-					// case "dex_sc_bridge_in":
-					// 	page_instance.pageDEXSCBridgeIn.SetToken(p.token)
-					// 	page_instance.pageRouter.SetCurrent(PAGE_DEX_SC_BRIDGE_IN)
-					// 	page_instance.header.AddHistory(PAGE_DEX_SC_BRIDGE_IN)
-					// case "dex_sc_bridge_out":
-					// 	page_instance.pageDEXSCBridgeOut.SetToken(p.token)
-					// 	page_instance.pageRouter.SetCurrent(PAGE_DEX_SC_BRIDGE_OUT)
-					// 	page_instance.header.AddHistory(PAGE_DEX_SC_BRIDGE_OUT)
+				case "sc_console":
+					page_instance.pageSCConsole.SetSCID(p.token.SCID)
+					page_instance.pageRouter.SetCurrent(PAGE_SC_CONSOLE)
+					page_instance.header.AddHistory(PAGE_SC_CONSOLE)
+				case "sc_state":
+					page_instance.pageSCState.SetSCID(p.token.SCID)
+					page_instance.pageRouter.SetCurrent(PAGE_SC_STATE)
+					page_instance.header.AddHistory(PAGE_SC_STATE)
+				case "nft_details":
+					page_instance.pageNFTDetail.SetToken(p.token)
+					page_instance.pageRouter.SetCurrent(PAGE_NFT_DETAIL)
+					page_instance.header.AddHistory(PAGE_NFT_DETAIL)
+				case "dex_sc_bridge_in":
+					page_instance.pageDEXSCBridgeIn.SetToken(p.token)
+					page_instance.pageRouter.SetCurrent(PAGE_DEX_SC_BRIDGE_IN)
+					page_instance.header.AddHistory(PAGE_DEX_SC_BRIDGE_IN)
+				case "dex_sc_bridge_out":
+					page_instance.pageDEXSCBridgeOut.SetToken(p.token)
+					page_instance.pageRouter.SetCurrent(PAGE_DEX_SC_BRIDGE_OUT)
+					page_instance.header.AddHistory(PAGE_DEX_SC_BRIDGE_OUT)
 				}
 
 				if err != nil {
@@ -435,26 +479,13 @@ func (p *PageSCToken) Layout(gtx layout.Context, th *material.Theme) layout.Dime
 	}
 
 	{
-		changed, tab := p.txBar.Changed()
+		changed, _ := p.txBar.Changed()
 		if changed {
-			switch tab {
-			case "all":
-				p.getEntriesParams = wallet_manager.GetEntriesParams{}
-			case "in":
-				p.getEntriesParams = wallet_manager.GetEntriesParams{
-					In: sql.NullBool{Bool: true, Valid: true},
-				}
-			case "out":
-				p.getEntriesParams = wallet_manager.GetEntriesParams{
-					Out: sql.NullBool{Bool: true, Valid: true},
-				}
-			case "coinbase":
-				p.getEntriesParams = wallet_manager.GetEntriesParams{
-					Coinbase: sql.NullBool{Bool: true, Valid: true},
-				}
+			err := p.LoadTxs()
+			if err != nil {
+				notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+				notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
 			}
-
-			p.LoadTxs()
 		}
 	}
 
@@ -642,8 +673,11 @@ func (b *BalanceContainer) Layout(gtx layout.Context, th *material.Theme) layout
 									layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
 									layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
 										wallet := wallet_manager.OpenedWallet
-										balance, _ := wallet.Memory.Get_Balance_scid(b.token.GetHash())
+										balance, known := wallet.GetBalance(b.token.GetHash())
 										amount := utils.ShiftNumber{Number: balance, Decimals: int(b.token.Decimals)}.Format()
+										if !known {
+											amount = lang.Translate("Encrypted")
+										}
 
 										if b.balanceEditor.Text() != amount {
 											b.balanceEditor.SetText(amount)
@@ -0,0 +1,443 @@
+package page_wallet
+
+import (
+	"fmt"
+	"image"
+	"sync"
+	"time"
+
+	"gioui.org/font"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/secretsystems/secret-wallet/animation"
+	"github.com/secretsystems/secret-wallet/app_instance"
+	"github.com/secretsystems/secret-wallet/components"
+	"github.com/secretsystems/secret-wallet/containers/listselect_modal"
+	"github.com/secretsystems/secret-wallet/containers/notification_modals"
+	"github.com/secretsystems/secret-wallet/lang"
+	"github.com/secretsystems/secret-wallet/router"
+	"github.com/secretsystems/secret-wallet/theme"
+	"github.com/secretsystems/secret-wallet/utils"
+	"github.com/secretsystems/secret-wallet/wallet_manager"
+	"github.com/tanema/gween"
+	"github.com/tanema/gween/ease"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+type PageSCWatchlist struct {
+	isActive bool
+
+	animationEnter *animation.Animation
+	animationLeave *animation.Animation
+
+	buttonPoll *components.Button
+
+	// items are replaced by Load and not changed after - expanded is the id of the items showing their changes
+	items     []*SCWatchItem
+	expanded  map[int64]bool
+	itemsLock sync.Mutex
+
+	list *widget.List
+}
+
+var _ router.Page = &PageSCWatchlist{}
+
+func NewPageSCWatchlist() *PageSCWatchlist {
+	animationEnter := animation.NewAnimation(false, gween.NewSequence(
+		gween.New(1, 0, .25, ease.Linear),
+	))
+
+	animationLeave := animation.NewAnimation(false, gween.NewSequence(
+		gween.New(0, 1, .25, ease.Linear),
+	))
+
+	refreshIcon, _ := widget.NewIcon(icons.NavigationRefresh)
+	buttonPoll := components.NewButton(components.ButtonStyle{
+		Rounded:     components.UniformRounded(unit.Dp(5)),
+		Icon:        refreshIcon,
+		TextSize:    unit.Sp(14),
+		IconGap:     unit.Dp(10),
+		Inset:       layout.UniformInset(unit.Dp(10)),
+		Animation:   components.NewButtonAnimationDefault(),
+		LoadingIcon: refreshIcon,
+	})
+	buttonPoll.Label.Alignment = text.Middle
+	buttonPoll.Style.Font.Weight = font.Bold
+
+	list := new(widget.List)
+	list.Axis = layout.Vertical
+
+	page := &PageSCWatchlist{
+		animationEnter: animationEnter,
+		animationLeave: animationLeave,
+
+		buttonPoll: buttonPoll,
+		expanded:   make(map[int64]bool),
+
+		list: list,
+	}
+
+	return page
+}
+
+func (p *PageSCWatchlist) IsActive() bool {
+	return p.isActive
+}
+
+func (p *PageSCWatchlist) Enter() {
+	p.isActive = true
+
+	if !page_instance.header.IsHistory(PAGE_SC_WATCHLIST) {
+		p.animationEnter.Start()
+		p.animationLeave.Reset()
+	}
+
+	page_instance.header.Title = func() string {
+		return lang.Translate("Watchlist")
+	}
+
+	page_instance.header.Subtitle = nil
+	go p.Load()
+}
+
+func (p *PageSCWatchlist) Leave() {
+	p.animationLeave.Start()
+	p.animationEnter.Reset()
+}
+
+func (p *PageSCWatchlist) Load() error {
+	wallet := wallet_manager.OpenedWallet
+	if wallet == nil {
+		return nil
+	}
+
+	watches, err := wallet.GetSCWatchlist()
+	if err != nil {
+		return err
+	}
+
+	p.itemsLock.Lock()
+	expanded := make(map[int64]bool)
+	for id := range p.expanded {
+		expanded[id] = true
+	}
+	p.itemsLock.Unlock()
+
+	var items []*SCWatchItem
+	for _, watch := range watches {
+		item := NewSCWatchItem(watch)
+		if expanded[watch.ID] {
+			changes, err := wallet.GetSCWatchChanges(watch.ID)
+			if err != nil {
+				return err
+			}
+
+			// not nil once loaded to show the item expanded
+			item.changes = append([]wallet_manager.SCWatchChange{}, changes...)
+		}
+
+		items = append(items, item)
+	}
+
+	p.itemsLock.Lock()
+	p.items = items
+	p.itemsLock.Unlock()
+	app_instance.Window.Invalidate()
+	return nil
+}
+
+// the watched contracts are polled until the wallet is closed
+func (p *PageSCWatchlist) startPolling(wallet *wallet_manager.Wallet) {
+	wallet.StartSCWatchlistPolling(func(changed int) {
+		err := p.Load()
+		if err != nil {
+			fmt.Println(err)
+		}
+
+		notification_modals.SuccessInstance.SetText(lang.Translate("Watchlist"), fmt.Sprintf(lang.Translate("%d watched variable(s) changed."), changed))
+		notification_modals.SuccessInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		app_instance.Window.Invalidate()
+	})
+}
+
+func (p *PageSCWatchlist) poll() error {
+	wallet := wallet_manager.OpenedWallet
+	_, err := wallet.PollSCWatchlist()
+	if err != nil {
+		return err
+	}
+
+	return p.Load()
+}
+
+func (p *PageSCWatchlist) openMenu(item *SCWatchItem) {
+	historyIcon, _ := widget.NewIcon(icons.ActionHistory)
+	browseIcon, _ := widget.NewIcon(icons.ActionSearch)
+	deleteIcon, _ := widget.NewIcon(icons.ActionDelete)
+
+	historyText := lang.Translate("Show changes")
+	if item.changes != nil {
+		historyText = lang.Translate("Hide changes")
+	}
+
+	items := []*listselect_modal.SelectListItem{
+		listselect_modal.NewSelectListItem("history",
+			listselect_modal.NewItemText(historyIcon, historyText).Layout,
+		),
+		listselect_modal.NewSelectListItem("browse",
+			listselect_modal.NewItemText(browseIcon, lang.Translate("Browse contract")).Layout,
+		),
+		listselect_modal.NewSelectListItem("remove",
+			listselect_modal.NewItemText(deleteIcon, lang.Translate("Remove from watchlist")).Layout,
+		),
+	}
+
+	keyChan := listselect_modal.Instance.Open(items)
+	for key := range keyChan {
+		var err error
+		wallet := wallet_manager.OpenedWallet
+
+		switch key {
+		case "history":
+			p.itemsLock.Lock()
+			if p.expanded[item.watch.ID] {
+				delete(p.expanded, item.watch.ID)
+			} else {
+				p.expanded[item.watch.ID] = true
+			}
+			p.itemsLock.Unlock()
+
+			err = p.Load()
+		case "browse":
+			page_instance.pageSCState.SetSCID(item.watch.SCID)
+			page_instance.pageRouter.SetCurrent(PAGE_SC_STATE)
+			page_instance.header.AddHistory(PAGE_SC_STATE)
+		case "remove":
+			err = wallet.DelSCWatch(item.watch.ID)
+			if err == nil {
+				err = p.Load()
+			}
+		}
+
+		if err != nil {
+			notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+			notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		}
+
+		app_instance.Window.Invalidate()
+	}
+}
+
+func (p *PageSCWatchlist) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	{
+		state := p.animationEnter.Update(gtx)
+		if state.Active {
+			defer animation.TransformX(gtx, state.Value).Push(gtx.Ops).Pop()
+		}
+	}
+
+	{
+		state := p.animationLeave.Update(gtx)
+		if state.Active {
+			defer animation.TransformX(gtx, state.Value).Push(gtx.Ops).Pop()
+		}
+
+		if state.Finished {
+			p.isActive = false
+			op.InvalidateOp{}.Add(gtx.Ops)
+		}
+	}
+
+	if p.buttonPoll.Clicked() {
+		go func() {
+			p.buttonPoll.SetLoading(true)
+			err := p.poll()
+			p.buttonPoll.SetLoading(false)
+			if err != nil {
+				notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+				notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+			}
+			app_instance.Window.Invalidate()
+		}()
+	}
+
+	p.itemsLock.Lock()
+	items := p.items
+	p.itemsLock.Unlock()
+
+	for _, item := range items {
+		if item.clickable.Clicked() {
+			go p.openMenu(item)
+		}
+	}
+
+	var widgets []layout.Widget
+
+	widgets = append(widgets,
+		func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(16), lang.Translate("Watched variables are checked every minute while the wallet is opened. Add variables from the contract state browser."))
+			lbl.Color = theme.Current.TextMuteColor
+			return lbl.Layout(gtx)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonPoll.Text = lang.Translate("CHECK NOW")
+			p.buttonPoll.Style.Colors = theme.Current.ButtonPrimaryColors
+			return p.buttonPoll.Layout(gtx, th)
+		},
+	)
+
+	if len(items) == 0 {
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(16), lang.Translate("The watchlist is empty."))
+			lbl.Color = theme.Current.TextMuteColor
+			return lbl.Layout(gtx)
+		})
+	}
+
+	for i := range items {
+		item := items[i]
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			return item.Layout(gtx, th)
+		})
+	}
+
+	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+		return layout.Spacer{Height: unit.Dp(30)}.Layout(gtx)
+	})
+
+	listStyle := material.List(th, p.list)
+	listStyle.AnchorStrategy = material.Overlay
+
+	return listStyle.Layout(gtx, len(widgets), func(gtx layout.Context, index int) layout.Dimensions {
+		return layout.Inset{
+			Top: unit.Dp(0), Bottom: unit.Dp(20),
+			Left: unit.Dp(30), Right: unit.Dp(30),
+		}.Layout(gtx, widgets[index])
+	})
+}
+
+func formatWatchValue(value string, valid bool) string {
+	if !valid {
+		return lang.Translate("Not set")
+	}
+
+	return value
+}
+
+type SCWatchItem struct {
+	watch     wallet_manager.SCWatch
+	changes   []wallet_manager.SCWatchChange
+	clickable *widget.Clickable
+}
+
+func NewSCWatchItem(watch wallet_manager.SCWatch) *SCWatchItem {
+	return &SCWatchItem{
+		watch:     watch,
+		clickable: new(widget.Clickable),
+	}
+}
+
+func (item *SCWatchItem) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	r := op.Record(gtx.Ops)
+	dims := item.clickable.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		r := op.Record(gtx.Ops)
+		dims := layout.UniformInset(unit.Dp(15)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			watch := item.watch
+
+			value := lang.Translate("Not set")
+			if variable := watch.Variable(); variable != nil {
+				value = variable.Decoded()
+			}
+
+			checked := lang.Translate("Never checked")
+			if watch.CheckedTimestamp > 0 {
+				checked = time.UnixMilli(watch.CheckedTimestamp).Format("2006-01-02 15:04")
+			}
+
+			children := []layout.FlexChild{
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+						layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+							lbl := material.Label(th, unit.Sp(16), watch.Key)
+							lbl.Font.Weight = font.Bold
+							return lbl.Layout(gtx)
+						}),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							lbl := material.Label(th, unit.Sp(14), utils.ReduceTxId(watch.SCID))
+							lbl.Color = theme.Current.TextMuteColor
+							return lbl.Layout(gtx)
+						}),
+					)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					lbl := material.Label(th, unit.Sp(14), value)
+					return lbl.Layout(gtx)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					lbl := material.Label(th, unit.Sp(12), checked)
+					lbl.Color = theme.Current.TextMuteColor
+					return lbl.Layout(gtx)
+				}),
+			}
+
+			if item.changes != nil {
+				children = append(children, layout.Rigid(layout.Spacer{Height: unit.Dp(10)}.Layout))
+
+				if len(item.changes) == 0 {
+					children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						lbl := material.Label(th, unit.Sp(14), lang.Translate("No changes recorded."))
+						lbl.Color = theme.Current.TextMuteColor
+						return lbl.Layout(gtx)
+					}))
+				}
+
+				for i := range item.changes {
+					change := item.changes[i]
+					children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						date := time.UnixMilli(change.Timestamp).Format("2006-01-02 15:04")
+						line := fmt.Sprintf("%s  %s → %s", date,
+							formatWatchValue(change.OldValue.String, change.OldValue.Valid),
+							formatWatchValue(change.NewValue.String, change.NewValue.Valid),
+						)
+						lbl := material.Label(th, unit.Sp(14), line)
+						lbl.Color = theme.Current.TextMuteColor
+						return lbl.Layout(gtx)
+					}))
+				}
+			}
+
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+		})
+		c := r.Stop()
+
+		if item.clickable.Hovered() {
+			pointer.CursorPointer.Add(gtx.Ops)
+			paint.FillShape(gtx.Ops, theme.Current.ListItemHoverBgColor,
+				clip.UniformRRect(
+					image.Rectangle{Max: image.Pt(dims.Size.X, dims.Size.Y)},
+					gtx.Dp(10),
+				).Op(gtx.Ops),
+			)
+		}
+
+		c.Add(gtx.Ops)
+		return dims
+	})
+	c := r.Stop()
+
+	paint.FillShape(gtx.Ops, theme.Current.ListBgColor,
+		clip.UniformRRect(
+			image.Rectangle{Max: dims.Size},
+			gtx.Dp(10),
+		).Op(gtx.Ops))
+
+	c.Add(gtx.Ops)
+	return dims
+}
@@ -46,6 +46,7 @@ type PageSendForm struct {
 
 	buttonBuildTx *components.Button
 	buttonOptions *components.Button
+	buttonBatch   *components.Button
 	buttonSetMax  *components.Button
 
 	balanceContainer *BalanceContainer
@@ -128,6 +129,27 @@ func NewPageSendForm() *PageSendForm {
 
 	buttonOptions.Style.Font.Weight = font.Bold
 
+	batchIcon, _ := widget.NewIcon(icons.ActionList)
+
+	buttonBatch := components.NewButton(
+		components.ButtonStyle{
+			Rounded:   components.UniformRounded(unit.Dp(5)),
+			TextSize:  unit.Sp(14),
+			Icon:      batchIcon,
+			IconGap:   unit.Dp(10),
+			Inset:     layout.UniformInset(unit.Dp(10)),
+			Animation: components.NewButtonAnimationDefault(),
+			Border: widget.Border{
+				Color:        color.NRGBA{R: 0, G: 0, B: 0, A: 255},
+				Width:        unit.Dp(2),
+				CornerRadius: unit.Dp(5),
+			},
+		})
+
+	buttonBatch.Label.Alignment = text.Middle
+
+	buttonBatch.Style.Font.Weight = font.Bold
+
 	buttonSetMax := components.NewButton(
 		components.ButtonStyle{
 			TextSize: unit.Sp(16),
@@ -147,6 +169,7 @@ func NewPageSendForm() *PageSendForm {
 		animationLeave:   animationLeave,
 		list:             list,
 		buttonOptions:    buttonOptions,
+		buttonBatch:      buttonBatch,
 		buttonSetMax:     buttonSetMax,
 		balanceContainer: balanceContainer,
 		walletAddrInput:  walletAddrInput,
@@ -243,6 +266,11 @@ func (p *PageSendForm) Layout(gtx layout.Context, th *material.Theme) layout.Dim
 		page_instance.header.AddHistory(PAGE_SEND_OPTIONS_FORM)
 	}
 
+	if p.buttonBatch.Clicked() {
+		page_instance.pageRouter.SetCurrent(PAGE_BATCH_SEND)
+		page_instance.header.AddHistory(PAGE_BATCH_SEND)
+	}
+
 	if p.buttonSetMax.Clicked() {
 		wallet := wallet_manager.OpenedWallet
 		balance, _ := wallet.Memory.Get_Balance_scid(p.token.GetHash())
@@ -342,6 +370,12 @@ func (p *PageSendForm) Layout(gtx layout.Context, th *material.Theme) layout.Dim
 
 		},
 
+		func(gtx layout.Context) layout.Dimensions { // This is batch payments
+			p.buttonBatch.Text = lang.Translate("BATCH PAYMENTS")
+			p.buttonBatch.Style.Colors = theme.Current.ButtonSecondaryColors
+			return p.buttonBatch.Layout(gtx, th)
+		},
+
 		func(gtx layout.Context) layout.Dimensions { // This is the build
 			p.buttonBuildTx.Text = lang.Translate("SEND TRANSACTION")
 			p.buttonBuildTx.Style.Colors = theme.Current.ButtonPrimaryColors
@@ -810,6 +844,7 @@ func (p *WalletAddrInput) Layout(gtx layout.Context, th *material.Theme) layout.
 				switch key {
 
 				case "contact_list":
+					page_instance.pageContacts.onSelect = nil
 					page_instance.pageRouter.SetCurrent(
 						PAGE_CONTACTS,
 					)
@@ -3,8 +3,14 @@ package page_wallet
 import (
 	"database/sql"
 	"fmt"
+	"image/color"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"gioui.org/font"
+	"gioui.org/io/key"
 	"gioui.org/layout"
 	"gioui.org/op"
 	"gioui.org/text"
@@ -14,9 +20,12 @@ import (
 	"github.com/deroproject/derohe/cryptography/crypto"
 	"github.com/deroproject/derohe/rpc"
 	"github.com/secretsystems/secret-wallet/animation"
+	"github.com/secretsystems/secret-wallet/app_instance"
 	"github.com/secretsystems/secret-wallet/components"
 	"github.com/secretsystems/secret-wallet/containers/build_tx_modal"
+	"github.com/secretsystems/secret-wallet/containers/confirm_modal"
 	"github.com/secretsystems/secret-wallet/containers/notification_modals"
+	"github.com/secretsystems/secret-wallet/containers/prompt_modal"
 	"github.com/secretsystems/secret-wallet/lang"
 	"github.com/secretsystems/secret-wallet/prefabs"
 	"github.com/secretsystems/secret-wallet/router"
@@ -34,9 +43,17 @@ type PageServiceNames struct {
 	animationEnter *animation.Animation
 	animationLeave *animation.Animation
 	buttonRegister *components.Button
+	buttonCheck    *components.Button
 	txtName        *prefabs.TextField
 
-	entries []wallet_manager.Entry
+	// set by the availability check goroutine for the name that was checked
+	availability     string
+	availabilityName string
+	availabilityLock sync.Mutex
+	// the items are not changed once shown - the owner checks replace them with checked copies
+	nameItems []*ServiceNameItem
+	namesLoad int
+	namesLock sync.Mutex
 
 	list *widget.List
 }
@@ -68,6 +85,24 @@ func NewPageServiceNames() *PageServiceNames {
 	buttonRegister.Label.Alignment = text.Middle
 	buttonRegister.Style.Font.Weight = font.Bold
 
+	checkIcon, _ := widget.NewIcon(icons.ActionSearch)
+	buttonCheck := components.NewButton(components.ButtonStyle{
+		Rounded:     components.UniformRounded(unit.Dp(5)),
+		Icon:        checkIcon,
+		TextSize:    unit.Sp(14),
+		IconGap:     unit.Dp(10),
+		Inset:       layout.UniformInset(unit.Dp(10)),
+		Animation:   components.NewButtonAnimationDefault(),
+		LoadingIcon: loadingIcon,
+		Border: widget.Border{
+			Color:        color.NRGBA{R: 0, G: 0, B: 0, A: 255},
+			Width:        unit.Dp(2),
+			CornerRadius: unit.Dp(5),
+		},
+	})
+	buttonCheck.Label.Alignment = text.Middle
+	buttonCheck.Style.Font.Weight = font.Bold
+
 	txtName := prefabs.NewTextField()
 
 	list := new(widget.List)
@@ -78,8 +113,8 @@ func NewPageServiceNames() *PageServiceNames {
 		animationLeave: animationLeave,
 		list:           list,
 		buttonRegister: buttonRegister,
+		buttonCheck:    buttonCheck,
 		txtName:        txtName,
-		entries:        make([]wallet_manager.Entry, 0),
 	}
 }
 
@@ -104,15 +139,156 @@ func (p *PageServiceNames) Enter() {
 }
 
 func (p *PageServiceNames) Load() {
-	p.entries = make([]wallet_manager.Entry, 0)
 	wallet := wallet_manager.OpenedWallet
+	items := make(map[string]*ServiceNameItem)
 
-	p.entries = wallet.GetEntries(&crypto.ZEROHASH, wallet_manager.GetEntriesParams{
+	// outgoing txs are available right away even if the wallet is not synced
+	scCalls, err := wallet.GetOutgoingSCCalls(SERVICE_NAME_SCID, "Register", "TransferOwnership")
+	if err != nil {
+		notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+		notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+	}
+
+	// oldest first so a transfer always comes after the registration
+	for i := len(scCalls) - 1; i >= 0; i-- {
+		scCall := scCalls[i]
+		if scCall.Status.String == "invalid" || !scCall.Args.Has("name", rpc.DataString) {
+			continue
+		}
+
+		name := scCall.Args.Value("name", rpc.DataString).(string)
+		switch scCall.Entrypoint {
+		case "Register":
+			items[name] = NewServiceNameItem(name, time.Unix(scCall.Timestamp.Int64, 0), scCall.Status.String)
+		case "TransferOwnership":
+			item, ok := items[name]
+			if ok {
+				item.transferred = true
+			}
+		}
+	}
+
+	// names registered before outgoing txs were stored or from another device
+	entries, err := wallet.GetEntries(&crypto.ZEROHASH, wallet_manager.GetEntriesParams{
 		SC_CALL: &wallet_manager.SCCallParams{
 			SCID:       sql.NullString{String: SERVICE_NAME_SCID.String(), Valid: true},
 			Entrypoint: sql.NullString{String: "Register", Valid: true},
 		},
 	})
+	if err != nil {
+		notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+		notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+	}
+
+	for _, entry := range entries {
+		for _, arg := range entry.Payload_RPC {
+			if arg.Name == "name" {
+				name, ok := arg.Value.(string)
+				_, exists := items[name]
+				if ok && !exists {
+					items[name] = NewServiceNameItem(name, entry.Time, "valid")
+				}
+			}
+		}
+	}
+
+	var nameItems []*ServiceNameItem
+	for _, item := range items {
+		nameItems = append(nameItems, item)
+	}
+
+	sort.Slice(nameItems, func(i, j int) bool {
+		return nameItems[i].date.After(nameItems[j].date)
+	})
+
+	p.namesLock.Lock()
+	p.nameItems = nameItems
+	p.namesLoad++
+	load := p.namesLoad
+	p.namesLock.Unlock()
+
+	if wallet.IsWatchOnly() {
+		go p.loadRegisteredNames(load, nameItems)
+	} else {
+		go p.checkOwners(load, nameItems)
+	}
+}
+
+// replaced only if the names were not loaded again in the meantime
+func (p *PageServiceNames) setCheckedItems(load int, nameItems []*ServiceNameItem, owners map[string]string, walletAddr string) {
+	var checkedItems []*ServiceNameItem
+	for _, item := range nameItems {
+		checked := *item
+		owner, ok := owners[item.name]
+		if ok {
+			checked.setOwner(owner)
+			checked.owned = owner == walletAddr
+		}
+
+		checkedItems = append(checkedItems, &checked)
+	}
+
+	p.namesLock.Lock()
+	if p.namesLoad == load {
+		p.nameItems = checkedItems
+	}
+	p.namesLock.Unlock()
+
+	app_instance.Window.Invalidate()
+}
+
+// a watch-only wallet has no history - the names are read from the smart contract variables
+func (p *PageServiceNames) loadRegisteredNames(load int, nameItems []*ServiceNameItem) {
+	wallet := wallet_manager.OpenedWallet
+	names, err := wallet.GetRegisteredNames(SERVICE_NAME_SCID)
+	if err != nil {
+		notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+		notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		return
+	}
+
+	walletAddr := wallet.Memory.GetAddress().String()
+	shown := make(map[string]bool)
+	for _, item := range nameItems {
+		shown[item.name] = true
+	}
+
+	owners := make(map[string]string)
+	allItems := append([]*ServiceNameItem{}, nameItems...)
+	for _, name := range names {
+		owners[name] = walletAddr
+		if !shown[name] {
+			allItems = append(allItems, NewServiceNameItem(name, time.Time{}, "valid"))
+		}
+	}
+
+	sort.Slice(allItems, func(i, j int) bool {
+		return allItems[i].name < allItems[j].name
+	})
+
+	p.setCheckedItems(load, allItems, owners, walletAddr)
+}
+
+// the blockchain is the source of truth - a name can be transferred from another wallet
+func (p *PageServiceNames) checkOwners(load int, nameItems []*ServiceNameItem) {
+	wallet := wallet_manager.OpenedWallet
+	walletAddr := wallet.Memory.GetAddress().String()
+
+	owners := make(map[string]string)
+	for _, item := range nameItems {
+		addr, err := wallet.Memory.NameToAddress(item.name)
+		if err != nil {
+			if utils.IsErrLeafNotFound(err) {
+				owners[item.name] = ""
+			}
+
+			continue
+		}
+
+		owners[item.name] = addr
+	}
+
+	p.setCheckedItems(load, nameItems, owners, walletAddr)
 }
 
 func (p *PageServiceNames) Leave() {
@@ -150,6 +326,48 @@ func (p *PageServiceNames) Layout(gtx layout.Context, th *material.Theme) layout
 		}()
 	}
 
+	if p.buttonCheck.Clicked() {
+		name := strings.TrimSpace(p.txtName.Value())
+		go func() {
+			p.buttonCheck.SetLoading(true)
+			err := p.checkAvailability(name)
+			p.buttonCheck.SetLoading(false)
+			if err != nil {
+				notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+				notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+			}
+		}()
+	}
+
+	if build_tx_modal.Instance.TxSent() {
+		p.txtName.SetValue("")
+		p.Load()
+	}
+
+	p.availabilityLock.Lock()
+	if p.availabilityName != strings.TrimSpace(p.txtName.Value()) {
+		p.availability = ""
+	}
+	availability := p.availability
+	p.availabilityLock.Unlock()
+
+	p.namesLock.Lock()
+	nameItems := p.nameItems
+	p.namesLock.Unlock()
+
+	for _, item := range nameItems {
+		if item.buttonTransfer.Clicked() {
+			go func(name string) {
+				err := p.transferName(name)
+				if err != nil {
+					notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+					notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+				}
+			}(item.name)
+		}
+	}
+
+	wallet := wallet_manager.OpenedWallet
 	widgets := []layout.Widget{}
 
 	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
@@ -162,50 +380,51 @@ func (p *PageServiceNames) Layout(gtx layout.Context, th *material.Theme) layout
 		return p.txtName.Layout(gtx, th, lang.Translate("Name"), "")
 	})
 
+	if availability != "" {
+		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(16), availability)
+			lbl.Color = theme.Current.TextMuteColor
+			return lbl.Layout(gtx)
+		})
+	}
+
 	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
-		p.buttonRegister.Style.Colors = theme.Current.ButtonPrimaryColors
-		p.buttonRegister.Text = lang.Translate("REGISTER")
-		return p.buttonRegister.Layout(gtx, th)
+		return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				p.buttonCheck.Style.Colors = theme.Current.ButtonSecondaryColors
+				p.buttonCheck.Text = lang.Translate("CHECK")
+				return p.buttonCheck.Layout(gtx, th)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if wallet.IsWatchOnly() {
+					return layout.Dimensions{}
+				}
+
+				return layout.Spacer{Width: unit.Dp(10)}.Layout(gtx)
+			}),
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				if wallet.IsWatchOnly() {
+					return layout.Dimensions{}
+				}
+
+				p.buttonRegister.Style.Colors = theme.Current.ButtonPrimaryColors
+				p.buttonRegister.Text = lang.Translate("REGISTER")
+				return p.buttonRegister.Layout(gtx, th)
+			}),
+		)
 	})
 
 	widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
 		return prefabs.Divider(gtx, unit.Dp(5))
 	})
 
-	if len(p.entries) > 0 {
-		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
-			var childs []layout.FlexChild
-
-			for i := range p.entries {
-				idx := i
-				childs = append(childs, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-					entry := p.entries[idx]
-					return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
-						layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
-							name := ""
-							for _, arg := range entry.Payload_RPC {
-								if arg.Name == "name" {
-									value, ok := arg.Value.(string)
-									if ok {
-										name = value
-									}
-								}
-							}
-
-							lbl := material.Label(th, unit.Sp(16), name)
-							return lbl.Layout(gtx)
-						}),
-						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-							lbl := material.Label(th, unit.Sp(16), entry.Time.Format("2006-01-02"))
-							lbl.Color = theme.Current.TextMuteColor
-							return lbl.Layout(gtx)
-						}),
-					)
-				}))
-			}
-
-			return layout.Flex{Axis: layout.Vertical}.Layout(gtx, childs...)
-		})
+	if len(nameItems) > 0 {
+		for i := range nameItems {
+			item := nameItems[i]
+			widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
+				return item.Layout(gtx, th)
+			})
+		}
 	} else {
 		widgets = append(widgets, func(gtx layout.Context) layout.Dimensions {
 			lbl := material.Label(th, unit.Sp(16), lang.Translate("No registered names or the wallet is not synced properly. Try cleaning the wallet in settings page."))
@@ -229,10 +448,7 @@ func (p *PageServiceNames) Layout(gtx layout.Context, th *material.Theme) layout
 	})
 }
 
-func (p *PageServiceNames) submitForm() error {
-	wallet := wallet_manager.OpenedWallet
-	name := p.txtName.Value()
-
+func validateServiceName(name string) error {
 	if name == "" {
 		return fmt.Errorf("name cannot be empty")
 	}
@@ -241,13 +457,62 @@ func (p *PageServiceNames) submitForm() error {
 		return fmt.Errorf("name must be at least 6 characters")
 	}
 
+	if len(name) > 64 {
+		return fmt.Errorf("name must be at most 64 characters")
+	}
+
+	return nil
+}
+
+// returns the owner addr or an empty string if the name is available
+func nameOwner(name string) (string, error) {
+	wallet := wallet_manager.OpenedWallet
 	addr, err := wallet.Memory.NameToAddress(name)
 	if err != nil {
 		if !utils.IsErrLeafNotFound(err) {
-			return err
+			return "", err
 		}
 	}
 
+	return addr, nil
+}
+
+func (p *PageServiceNames) checkAvailability(name string) error {
+	err := validateServiceName(name)
+	if err != nil {
+		return err
+	}
+
+	addr, err := nameOwner(name)
+	if err != nil {
+		return err
+	}
+
+	availability := lang.Translate("The name is available.")
+	if addr != "" {
+		availability = strings.Replace(lang.Translate("The name is already taken by {}."), "{}", utils.ReduceAddr(addr), -1)
+	}
+
+	p.availabilityLock.Lock()
+	p.availability = availability
+	p.availabilityName = name
+	p.availabilityLock.Unlock()
+	app_instance.Window.Invalidate()
+	return nil
+}
+
+func (p *PageServiceNames) submitForm() error {
+	name := strings.TrimSpace(p.txtName.Value())
+	err := validateServiceName(name)
+	if err != nil {
+		return err
+	}
+
+	addr, err := nameOwner(name)
+	if err != nil {
+		return err
+	}
+
 	if addr != "" {
 		return fmt.Errorf("name already taken by [%s]", utils.ReduceAddr(addr))
 	}
@@ -264,3 +529,138 @@ func (p *PageServiceNames) submitForm() error {
 
 	return nil
 }
+
+func (p *PageServiceNames) transferName(name string) error {
+	wallet := wallet_manager.OpenedWallet
+	walletAddr := wallet.Memory.GetAddress().String()
+
+	addr, err := nameOwner(name)
+	if err != nil {
+		return err
+	}
+
+	if addr != walletAddr {
+		return fmt.Errorf("you are not the owner of [%s]", name)
+	}
+
+	txtChan := prompt_modal.Instance.Open("", lang.Translate("Enter new owner address"), key.HintText)
+	for txt := range txtChan {
+		newOwner, err := rpc.NewAddress(strings.TrimSpace(txt))
+		if err != nil {
+			return err
+		}
+
+		if newOwner.IsIntegratedAddress() {
+			return fmt.Errorf("integrated address is not allowed")
+		}
+
+		if newOwner.String() == walletAddr {
+			return fmt.Errorf("you already own this name")
+		}
+
+		prompt := strings.Replace(lang.Translate("Transfer [{}] to this address?"), "{}", name, -1)
+		yesChan := confirm_modal.Instance.Open(confirm_modal.ConfirmText{Prompt: prompt})
+		for yes := range yesChan {
+			if yes {
+				build_tx_modal.Instance.Open(build_tx_modal.TxPayload{
+					Ringsize: 2,
+					SCArgs: rpc.Arguments{
+						{Name: rpc.SCACTION, DataType: rpc.DataUint64, Value: uint64(rpc.SC_CALL)},
+						{Name: rpc.SCID, DataType: rpc.DataHash, Value: SERVICE_NAME_SCID},
+						{Name: "entrypoint", DataType: rpc.DataString, Value: "TransferOwnership"},
+						{Name: "name", DataType: rpc.DataString, Value: name},
+						{Name: "newowner", DataType: rpc.DataString, Value: newOwner.String()},
+					},
+				})
+			}
+		}
+	}
+
+	return nil
+}
+
+type ServiceNameItem struct {
+	name        string
+	date        time.Time
+	status      string
+	owner       string
+	ownerLoaded bool
+	owned       bool
+	transferred bool
+
+	buttonTransfer *components.Button
+}
+
+func NewServiceNameItem(name string, date time.Time, status string) *ServiceNameItem {
+	transferIcon, _ := widget.NewIcon(icons.ContentSend)
+	buttonTransfer := components.NewButton(components.ButtonStyle{
+		Icon:      transferIcon,
+		Animation: components.NewButtonAnimationScale(.98),
+	})
+
+	return &ServiceNameItem{
+		name:           name,
+		date:           date,
+		status:         status,
+		buttonTransfer: buttonTransfer,
+	}
+}
+
+func (item *ServiceNameItem) setOwner(addr string) {
+	item.owner = addr
+	item.ownerLoaded = true
+}
+
+func (item *ServiceNameItem) statusText() string {
+	if item.ownerLoaded {
+		if item.owned {
+			return lang.Translate("Owned")
+		}
+
+		if item.owner == "" {
+			return lang.Translate("Not registered")
+		}
+
+		return strings.Replace(lang.Translate("Owned by {}"), "{}", utils.ReduceAddr(item.owner), -1)
+	}
+
+	if item.transferred {
+		return lang.Translate("Transferred")
+	}
+
+	if item.status == "pending" {
+		return lang.Translate("Pending")
+	}
+
+	return item.date.Format("2006-01-02")
+}
+
+func (item *ServiceNameItem) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					lbl := material.Label(th, unit.Sp(16), item.name)
+					lbl.Font.Weight = font.Bold
+					return lbl.Layout(gtx)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					lbl := material.Label(th, unit.Sp(14), item.statusText())
+					lbl.Color = theme.Current.TextMuteColor
+					return lbl.Layout(gtx)
+				}),
+			)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			wallet := wallet_manager.OpenedWallet
+			if !item.owned || wallet.IsWatchOnly() {
+				return layout.Dimensions{}
+			}
+
+			gtx.Constraints.Max.X = gtx.Dp(25)
+			gtx.Constraints.Max.Y = gtx.Dp(25)
+			item.buttonTransfer.Style.Colors = theme.Current.ButtonIconPrimaryColors
+			return item.buttonTransfer.Layout(gtx, th)
+		}),
+	)
+}
@@ -35,6 +35,10 @@ type PageSettings struct {
 	buttonDeleteWallet      *components.Button
 	buttonInfo              *components.Button
 	buttonServiceNames      *components.Button
+	buttonOfflineTx         *components.Button
+	buttonSCConsole         *components.Button
+	buttonSCInstall         *components.Button
+	buttonSCState           *components.Button
 	txtWalletName           *prefabs.TextField
 	txtWalletChangePassword *prefabs.TextField
 	buttonSave              *components.Button
@@ -120,6 +124,70 @@ func NewPageSettings() *PageSettings {
 	buttonServiceNames.Label.Alignment = text.Middle
 	buttonServiceNames.Style.Font.Weight = font.Bold
 
+	offlineIcon, _ := widget.NewIcon(icons.ContentCreate)
+	buttonOfflineTx := components.NewButton(components.ButtonStyle{
+		Icon:      offlineIcon,
+		TextSize:  unit.Sp(16),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+		Border: widget.Border{
+			Color:        color.NRGBA{R: 0, G: 0, B: 0, A: 255},
+			Width:        unit.Dp(2),
+			CornerRadius: unit.Dp(5),
+		},
+	})
+	buttonOfflineTx.Label.Alignment = text.Middle
+	buttonOfflineTx.Style.Font.Weight = font.Bold
+
+	consoleIcon, _ := widget.NewIcon(icons.ActionCode)
+	buttonSCConsole := components.NewButton(components.ButtonStyle{
+		Icon:      consoleIcon,
+		TextSize:  unit.Sp(16),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+		Border: widget.Border{
+			Color:        color.NRGBA{R: 0, G: 0, B: 0, A: 255},
+			Width:        unit.Dp(2),
+			CornerRadius: unit.Dp(5),
+		},
+	})
+	buttonSCConsole.Label.Alignment = text.Middle
+	buttonSCConsole.Style.Font.Weight = font.Bold
+
+	installIcon, _ := widget.NewIcon(icons.FileCloudUpload)
+	buttonSCInstall := components.NewButton(components.ButtonStyle{
+		Icon:      installIcon,
+		TextSize:  unit.Sp(16),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+		Border: widget.Border{
+			Color:        color.NRGBA{R: 0, G: 0, B: 0, A: 255},
+			Width:        unit.Dp(2),
+			CornerRadius: unit.Dp(5),
+		},
+	})
+	buttonSCInstall.Label.Alignment = text.Middle
+	buttonSCInstall.Style.Font.Weight = font.Bold
+
+	stateIcon, _ := widget.NewIcon(icons.ActionList)
+	buttonSCState := components.NewButton(components.ButtonStyle{
+		Icon:      stateIcon,
+		TextSize:  unit.Sp(16),
+		IconGap:   unit.Dp(10),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Animation: components.NewButtonAnimationDefault(),
+		Border: widget.Border{
+			Color:        color.NRGBA{R: 0, G: 0, B: 0, A: 255},
+			Width:        unit.Dp(2),
+			CornerRadius: unit.Dp(5),
+		},
+	})
+	buttonSCState.Label.Alignment = text.Middle
+	buttonSCState.Style.Font.Weight = font.Bold
+
 	loadingIcon, _ := widget.NewIcon(icons.NavigationRefresh)
 	exportIcon, _ := widget.NewIcon(icons.EditorPublish)
 	buttonExportTxs := components.NewButton(components.ButtonStyle{
@@ -164,6 +232,10 @@ func NewPageSettings() *PageSettings {
 		buttonCleanWallet:       buttonCleanWallet,
 		buttonExportTxs:         buttonExportTxs,
 		buttonServiceNames:      buttonServiceNames,
+		buttonOfflineTx:         buttonOfflineTx,
+		buttonSCConsole:         buttonSCConsole,
+		buttonSCInstall:         buttonSCInstall,
+		buttonSCState:           buttonSCState,
 	}
 }
 
@@ -210,6 +282,26 @@ func (p *PageSettings) Layout(gtx layout.Context, th *material.Theme) layout.Dim
 		page_instance.header.AddHistory(PAGE_SERVICE_NAMES)
 	}
 
+	if p.buttonOfflineTx.Clicked() {
+		page_instance.pageRouter.SetCurrent(PAGE_OFFLINE_TX)
+		page_instance.header.AddHistory(PAGE_OFFLINE_TX)
+	}
+
+	if p.buttonSCConsole.Clicked() {
+		page_instance.pageRouter.SetCurrent(PAGE_SC_CONSOLE)
+		page_instance.header.AddHistory(PAGE_SC_CONSOLE)
+	}
+
+	if p.buttonSCInstall.Clicked() {
+		page_instance.pageRouter.SetCurrent(PAGE_SC_INSTALL)
+		page_instance.header.AddHistory(PAGE_SC_INSTALL)
+	}
+
+	if p.buttonSCState.Clicked() {
+		page_instance.pageRouter.SetCurrent(PAGE_SC_STATE)
+		page_instance.header.AddHistory(PAGE_SC_STATE)
+	}
+
 	if p.buttonInfo.Clicked() {
 		p.action = "wallet_info"
 		password_modal.Instance.SetVisible(true)
@@ -279,6 +371,70 @@ func (p *PageSettings) Layout(gtx layout.Context, th *material.Theme) layout.Dim
 				}),
 			)
 		},
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonOfflineTx.Text = lang.Translate("Offline Signing")
+
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					p.buttonOfflineTx.Style.Colors = theme.Current.ButtonSecondaryColors
+					return p.buttonOfflineTx.Layout(gtx, th)
+				}),
+				layout.Rigid(layout.Spacer{Height: unit.Dp(3)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					lbl := material.Label(th, unit.Sp(14), lang.Translate("Sign or broadcast transactions prepared on another device"))
+					lbl.Color = theme.Current.TextMuteColor
+					return lbl.Layout(gtx)
+				}),
+			)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonSCConsole.Text = lang.Translate("Smart Contracts")
+
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					p.buttonSCConsole.Style.Colors = theme.Current.ButtonSecondaryColors
+					return p.buttonSCConsole.Layout(gtx, th)
+				}),
+				layout.Rigid(layout.Spacer{Height: unit.Dp(3)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					lbl := material.Label(th, unit.Sp(14), lang.Translate("Call any smart contract function"))
+					lbl.Color = theme.Current.TextMuteColor
+					return lbl.Layout(gtx)
+				}),
+			)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonSCInstall.Text = lang.Translate("Install SC")
+
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					p.buttonSCInstall.Style.Colors = theme.Current.ButtonSecondaryColors
+					return p.buttonSCInstall.Layout(gtx, th)
+				}),
+				layout.Rigid(layout.Spacer{Height: unit.Dp(3)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					lbl := material.Label(th, unit.Sp(14), lang.Translate("Deploy a contract from a .bas file and list the ones you installed"))
+					lbl.Color = theme.Current.TextMuteColor
+					return lbl.Layout(gtx)
+				}),
+			)
+		},
+		func(gtx layout.Context) layout.Dimensions {
+			p.buttonSCState.Text = lang.Translate("Contract State")
+
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					p.buttonSCState.Style.Colors = theme.Current.ButtonSecondaryColors
+					return p.buttonSCState.Layout(gtx, th)
+				}),
+				layout.Rigid(layout.Spacer{Height: unit.Dp(3)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					lbl := material.Label(th, unit.Sp(14), lang.Translate("Browse contract variables and watch them for changes"))
+					lbl.Color = theme.Current.TextMuteColor
+					return lbl.Layout(gtx)
+				}),
+			)
+		},
 		func(gtx layout.Context) layout.Dimensions {
 			p.buttonInfo.Text = lang.Translate("Wallet Information")
 
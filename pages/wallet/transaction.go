@@ -521,9 +521,13 @@ func (t *TxTransfers) Load(entry wallet_manager.Entry) {
 
 	t.items = make([]*TxTransferItem, 0)
 	if entry.TXID != "" {
-		entries := wallet.GetEntries(nil, wallet_manager.GetEntriesParams{
+		entries, err := wallet.GetEntries(nil, wallet_manager.GetEntriesParams{
 			TXID: sql.NullString{String: entry.TXID, Valid: true},
 		})
+		if err != nil {
+			notification_modals.ErrorInstance.SetText(lang.Translate("Error"), err.Error())
+			notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+		}
 
 		for _, entry := range entries {
 			t.items = append(t.items, NewTxTransferItem(entry))
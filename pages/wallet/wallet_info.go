@@ -56,13 +56,24 @@ func (p *PageWalletInfo) Enter() {
 	wallet := wallet_manager.OpenedWallet
 
 	addr := wallet.Memory.GetAddress().String()
-	seed := wallet.Memory.GetSeed()
-	hexSeed := wallet.Memory.Get_Keys().Secret.Text(16)
 
 	infoItems := []*page_settings.InfoListItem{
-		page_settings.NewInfoListItem("Address", addr, text.WrapGraphemes),     //@lang.Translate("Address")
-		page_settings.NewInfoListItem("Seed", seed, text.WrapWords),            //@lang.Translate("Seed")
-		page_settings.NewInfoListItem("Hex Seed", hexSeed, text.WrapGraphemes), //@lang.Translate("Hex Seed")
+		page_settings.NewInfoListItem("Address", addr, text.WrapGraphemes), //@lang.Translate("Address")
+	}
+
+	// the secret of a watch-only wallet is random and does not belong to the address
+	if wallet.IsWatchOnly() {
+		infoItems = append(infoItems,
+			page_settings.NewInfoListItem("Type", lang.Translate("Watch-only"), text.WrapWords), //@lang.Translate("Type")
+		)
+	} else {
+		seed := wallet.Memory.GetSeed()
+		hexSeed := wallet.Memory.Get_Keys().Secret.Text(16)
+
+		infoItems = append(infoItems,
+			page_settings.NewInfoListItem("Seed", seed, text.WrapWords),            //@lang.Translate("Seed")
+			page_settings.NewInfoListItem("Hex Seed", hexSeed, text.WrapGraphemes), //@lang.Translate("Hex Seed")
+		)
 	}
 
 	p.infoItems = infoItems
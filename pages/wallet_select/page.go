@@ -36,12 +36,13 @@ var _ router.Page = &Page{}
 var page_instance *Page
 
 const (
-	PAGE_CREATE_WALLET_SEED_FORM    = "page_create_wallet_seed_form"
-	PAGE_CREATE_WALLET_HEXSEED_FORM = "page_create_wallet_hexseed_form"
-	PAGE_CREATE_WALLET_FORM         = "page_create_wallet_form"
-	PAGE_CREATE_WALLET_FASTREG_FORM = "page_create_Wallet_fastreg_form"
-	PAGE_CREATE_WALLET_DISK_FORM    = "page_create_wallet_disk_form"
-	PAGE_SELECT_WALLET              = "page_select_wallet"
+	PAGE_CREATE_WALLET_SEED_FORM       = "page_create_wallet_seed_form"
+	PAGE_CREATE_WALLET_HEXSEED_FORM    = "page_create_wallet_hexseed_form"
+	PAGE_CREATE_WALLET_FORM            = "page_create_wallet_form"
+	PAGE_CREATE_WALLET_FASTREG_FORM    = "page_create_Wallet_fastreg_form"
+	PAGE_CREATE_WALLET_DISK_FORM       = "page_create_wallet_disk_form"
+	PAGE_CREATE_WALLET_WATCH_ONLY_FORM = "page_create_wallet_watch_only_form"
+	PAGE_SELECT_WALLET                 = "page_select_wallet"
 )
 
 func New() *Page {
@@ -73,6 +74,9 @@ func New() *Page {
 	pageCreateWalletDiskForm := NewPageCreateWalletDiskForm()
 	pageRouter.Add(PAGE_CREATE_WALLET_DISK_FORM, pageCreateWalletDiskForm)
 
+	pageCreateWalletWatchOnlyForm := NewPageCreateWalletWatchOnlyForm()
+	pageRouter.Add(PAGE_CREATE_WALLET_WATCH_ONLY_FORM, pageCreateWalletWatchOnlyForm)
+
 	header := prefabs.NewHeader(pageRouter)
 
 	page := &Page{
@@ -232,6 +232,7 @@ func (p *PageSelectWallet) Layout(gtx layout.Context, th *material.Theme) layout
 								newIcon, _ := widget.NewIcon(icons.ContentAddCircle)
 								diskIcon, _ := widget.NewIcon(icons.FileFolder)
 								seedIcon, _ := widget.NewIcon(icons.EditorShortText)
+								watchIcon, _ := widget.NewIcon(icons.ActionVisibility)
 
 								keyChan := listselect_modal.Instance.Open([]*listselect_modal.SelectListItem{
 									// listselect_modal.NewSelectListItem(PAGE_CREATE_WALLET_FASTREG_FORM,
@@ -249,6 +250,9 @@ func (p *PageSelectWallet) Layout(gtx layout.Context, th *material.Theme) layout
 									listselect_modal.NewSelectListItem(PAGE_CREATE_WALLET_HEXSEED_FORM,
 										listselect_modal.NewItemText(seedIcon, lang.Translate("Recover from hex seed")).Layout,
 									),
+									listselect_modal.NewSelectListItem(PAGE_CREATE_WALLET_WATCH_ONLY_FORM,
+										listselect_modal.NewItemText(watchIcon, lang.Translate("Watch-only wallet")).Layout,
+									),
 								})
 
 								for key := range keyChan {
@@ -276,7 +280,23 @@ func (p *PageSelectWallet) Layout(gtx layout.Context, th *material.Theme) layout
 				password_modal.Instance.SetLoading(false)
 				if err == nil {
 					wallet := wallet_manager.OpenedWallet
-					wallet.Memory.SetOnlineMode()
+					if wallet.IsWatchOnly() {
+						wallet.StartWatchOnlySync()
+					} else {
+						wallet.Memory.SetOnlineMode()
+
+						err := wallet.AutoStartRPCServer()
+						if err != nil {
+							notification_modals.ErrorInstance.SetText(lang.Translate("RPC server"), err.Error())
+							notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+						}
+
+						err = wallet.AutoStartXSWDServer()
+						if err != nil {
+							notification_modals.ErrorInstance.SetText(lang.Translate("XSWD server"), err.Error())
+							notification_modals.ErrorInstance.SetVisible(true, notification_modals.CLOSE_AFTER_DEFAULT)
+						}
+					}
 					password_modal.Instance.SetVisible(false)
 					// important reset wallet pages to initial state
 					app_instance.Router.Pages[pages.PAGE_WALLET] = page_wallet.New()
@@ -310,6 +330,10 @@ func (item *walletItem) Layout(gtx layout.Context, th *material.Theme, fill bool
 				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
 					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 						name := fmt.Sprintf("%s [%s]", lang.Translate("Wallet"), item.walletInfo.Name)
+						if item.walletInfo.WatchOnly {
+							name = fmt.Sprintf("%s (%s)", name, lang.Translate("Watch-only"))
+						}
+
 						lbl := material.Label(th, unit.Sp(18), name)
 						lbl.Font.Weight = font.Bold
 						return lbl.Layout(gtx)
@@ -0,0 +1,86 @@
+package prefabs
+
+import (
+	"fmt"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/secretsystems/secret-wallet/components"
+	"github.com/secretsystems/secret-wallet/containers/listselect_modal"
+	"github.com/secretsystems/secret-wallet/lang"
+	"github.com/secretsystems/secret-wallet/settings"
+	"github.com/secretsystems/secret-wallet/theme"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+)
+
+type NetworkSelector struct {
+	buttonSelect *components.Button
+	items        []*listselect_modal.SelectListItem
+
+	Changed bool
+	Key     string
+}
+
+func NetworkName(network string) string {
+	switch network {
+	case settings.NetworkTestnet:
+		return lang.Translate("Testnet")
+	case settings.NetworkSimulator:
+		return lang.Translate("Simulator")
+	}
+
+	return lang.Translate("Mainnet")
+}
+
+func NewNetworkSelector(defaultNetwork string) *NetworkSelector {
+	networkIcon, _ := widget.NewIcon(icons.HardwareDeviceHub)
+	buttonSelect := components.NewButton(components.ButtonStyle{
+		Rounded:   components.UniformRounded(unit.Dp(5)),
+		TextSize:  unit.Sp(16),
+		Inset:     layout.UniformInset(unit.Dp(10)),
+		Icon:      networkIcon,
+		IconGap:   unit.Dp(10),
+		Animation: components.NewButtonAnimationDefault(),
+	})
+	buttonSelect.Label.Alignment = text.Middle
+	buttonSelect.Style.Font.Weight = font.Bold
+
+	items := []*listselect_modal.SelectListItem{}
+
+	for _, network := range settings.Networks {
+		key := network
+		items = append(items, listselect_modal.NewSelectListItem(key, func(gtx layout.Context, th *material.Theme) layout.Dimensions {
+			lbl := material.Label(th, unit.Sp(20), NetworkName(key))
+			return lbl.Layout(gtx)
+		}))
+	}
+
+	return &NetworkSelector{
+		buttonSelect: buttonSelect,
+		items:        items,
+		Key:          defaultNetwork,
+	}
+}
+
+func (n *NetworkSelector) Layout(gtx layout.Context, th *material.Theme) layout.Dimensions {
+	n.Changed = false
+
+	if n.buttonSelect.Clicked() {
+		go func() {
+			keyChan := listselect_modal.Instance.Open(n.items)
+
+			for key := range keyChan {
+				n.Changed = key != n.Key
+				n.Key = key
+			}
+		}()
+	}
+
+	n.buttonSelect.Text = fmt.Sprintf("%s: %s", lang.Translate("Network"), NetworkName(n.Key))
+	n.buttonSelect.Style.Colors = theme.Current.ButtonPrimaryColors
+	return n.buttonSelect.Layout(gtx, th)
+}
@@ -1,9 +1,20 @@
 package dex_sc
 
 import (
+	"fmt"
+
 	"github.com/secretsystems/secret-wallet/utils"
 )
 
+// pair entrypoints - assets are sent to the contract as burns
+// and the other side or the shares are sent back to the signer
+// pairs have no published hash so the functions are checked in the pair code by the wallet
+var (
+	SWAP_ENTRYPOINT             = "Swap"
+	ADD_LIQUIDITY_ENTRYPOINT    = "AddLiquidity"
+	REMOVE_LIQUIDITY_ENTRYPOINT = "RemoveLiquidity"
+)
+
 type Pair struct {
 	SCID              string
 	NumTrustees       uint64
@@ -37,6 +48,24 @@ func (pair *Pair) CalcOwnership(share uint64) float32 {
 	return float32(share) / float32(pair.SharesOutstanding) * 100.0
 }
 
+// amount of the other asset needed to keep the pool ratio and the shares minted for it
+// an empty pool has no ratio so the first provider sets it and both values are zero
+func (pair *Pair) CalcAddLiquidity(amt uint64, reverse bool) (other uint64, shares uint64) {
+	if pair.Liquidity1 == 0 || pair.Liquidity2 == 0 {
+		return
+	}
+
+	if reverse {
+		other = utils.MultDiv(amt, pair.Liquidity1, pair.Liquidity2)
+		shares = utils.MultDiv(amt, pair.SharesOutstanding, pair.Liquidity2)
+	} else {
+		other = utils.MultDiv(amt, pair.Liquidity2, pair.Liquidity1)
+		shares = utils.MultDiv(amt, pair.SharesOutstanding, pair.Liquidity1)
+	}
+
+	return
+}
+
 func (pair *Pair) CalcSwap(amt uint64, reverse bool) (receive uint64, fee uint64, slip float64) {
 	if amt == 0 {
 		return
@@ -63,26 +92,73 @@ func (pair *Pair) CalcSwap(amt uint64, reverse bool) (receive uint64, fee uint64
 	return
 }
 
+func parseUint(values map[string]interface{}, key string) (uint64, error) {
+	value, ok := values[key].(float64)
+	if !ok {
+		return 0, fmt.Errorf("invalid pair variable [%s]", key)
+	}
+
+	return uint64(value), nil
+}
+
+func parseString(values map[string]interface{}, key string) (string, error) {
+	value, ok := values[key].(string)
+	if !ok {
+		return "", fmt.Errorf("invalid pair variable [%s]", key)
+	}
+
+	return value, nil
+}
+
+// returns an error instead of panicking if the contract is not a pair
 func (pair *Pair) Parse(scId string, values map[string]interface{}) (err error) {
 	pair.SCID = scId
-	pair.NumTrustees = uint64(values["numTrustees"].(float64))
 
-	pair.Asset1 = values["asset1"].(string)
-	pair.Asset2 = values["asset2"].(string)
+	pair.NumTrustees, err = parseUint(values, "numTrustees")
+	if err != nil {
+		return
+	}
+
+	pair.Asset1, err = parseString(values, "asset1")
+	if err != nil {
+		return
+	}
+
+	pair.Asset2, err = parseString(values, "asset2")
+	if err != nil {
+		return
+	}
+
+	symbol, err := parseString(values, "symbol")
+	if err != nil {
+		return
+	}
 
-	pair.Symbol, err = utils.DecodeString(values["symbol"].(string))
+	pair.Symbol, err = utils.DecodeString(symbol)
 	if err != nil {
 		return
 	}
 
-	pair.Quorum = uint64(values["quorum"].(float64))
-	pair.Fee = uint64(values["fee"].(float64))
-	pair.Liquidity1 = uint64(values["val1"].(float64))
-	pair.Liquidity2 = uint64(values["val2"].(float64))
-	pair.SharesOutstanding = uint64(values["sharesOutstanding"].(float64))
-	pair.AddCount = uint64(values["adds"].(float64))
-	pair.RemoveCount = uint64(values["rems"].(float64))
-	pair.SwapCount = uint64(values["swaps"].(float64))
+	uints := []struct {
+		key   string
+		value *uint64
+	}{
+		{"quorum", &pair.Quorum},
+		{"fee", &pair.Fee},
+		{"val1", &pair.Liquidity1},
+		{"val2", &pair.Liquidity2},
+		{"sharesOutstanding", &pair.SharesOutstanding},
+		{"adds", &pair.AddCount},
+		{"rems", &pair.RemoveCount},
+		{"swaps", &pair.SwapCount},
+	}
+
+	for _, u := range uints {
+		*u.value, err = parseUint(values, u.key)
+		if err != nil {
+			return
+		}
+	}
 
 	return
 }
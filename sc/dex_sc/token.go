@@ -1,9 +1,23 @@
 package dex_sc
 
-import "github.com/secretsystems/secret-wallet/utils"
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/secretsystems/secret-wallet/utils"
+	"golang.org/x/crypto/sha3"
+)
 
 var DEX_SC_SHA256 = "51f330aeb991da9c845b77daf45304acf6e07a0e87125b8c0b8884dadbbd9dba"
 
+// burns the wrapped tokens and the fee in DERO - the trustees release the native asset to the eth address
+// the function and its argument are checked in the token code by the wallet before building the call
+var (
+	BRIDGE_OUT_ENTRYPOINT   = "Bridge"
+	BRIDGE_OUT_ETH_ADDR_ARG = "eth_addr"
+)
+
 // DEX Tokens
 // DFRAX: f42fd725bc3659a7e6502ce416363afea0951e7f21af4f8f71b42090206e29d4
 // DLINK: ab8ee3627b212a0b3803c127f3de7c44465fac21ec30692cb7988b14059990bb
@@ -71,3 +85,38 @@ func (token *Token) Parse(scId string, values map[string]interface{}) (err error
 
 	return
 }
+
+// checks the format and the EIP-55 checksum if the address is mixed case
+func ValidateEthAddress(addr string) error {
+	if len(addr) != 42 || !strings.HasPrefix(addr, "0x") {
+		return fmt.Errorf("invalid eth address")
+	}
+
+	hexAddr := addr[2:]
+	_, err := hex.DecodeString(hexAddr)
+	if err != nil {
+		return fmt.Errorf("invalid eth address")
+	}
+
+	if hexAddr == strings.ToLower(hexAddr) || hexAddr == strings.ToUpper(hexAddr) {
+		return nil
+	}
+
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write([]byte(strings.ToLower(hexAddr)))
+	hashHex := hex.EncodeToString(hash.Sum(nil))
+
+	// a letter is uppercase when the same nibble of the hash is 8 or more
+	for i, c := range hexAddr {
+		if c >= '0' && c <= '9' {
+			continue
+		}
+
+		upper := hashHex[i] >= '8'
+		if upper != (c >= 'A' && c <= 'F') {
+			return fmt.Errorf("invalid eth address checksum")
+		}
+	}
+
+	return nil
+}
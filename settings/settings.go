@@ -2,11 +2,13 @@ package settings
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 
 	"gioui.org/app"
 	sysTheme "gioui.org/x/pref/theme"
+	"github.com/deroproject/derohe/globals"
 	"github.com/secretsystems/secret-wallet/lang"
 	"github.com/secretsystems/secret-wallet/theme"
 )
@@ -16,20 +18,60 @@ var (
 	MainTabBarsTxs   = "txs"
 	FolderLayoutGrid = "grid"
 	FolderLayoutList = "list"
+	NetworkMainnet   = "mainnet"
+	NetworkTestnet   = "testnet"
+	NetworkSimulator = "simulator"
 )
 
+var Networks = []string{NetworkMainnet, NetworkTestnet, NetworkSimulator}
+
+type IPFSGateway struct {
+	// https://ipfs.io - /ipfs/{cid} is appended to the url
+	Url string `json:"url"`
+	// seconds before trying the next gateway
+	Timeout int `json:"timeout"`
+}
+
+var DefaultIPFSGateways = []IPFSGateway{
+	{Url: "https://ipfs.io", Timeout: 15},
+	{Url: "https://dweb.link", Timeout: 15},
+	{Url: "https://trustless-gateway.link", Timeout: 15},
+}
+
 type AppSettings struct {
 	Language     string `json:"language"`
 	HideBalance  bool   `json:"hide_balance"`
 	SendRingSize int    `json:"send_ring_size"`
+	// mainnet, testnet or simulator - each network has its own wallets, app.db and cache
+	Network      string `json:"network"`
 	NodeEndpoint string `json:"node_endpoint"`
+	// selected node of the other networks
+	NetworkNodeEndpoints map[string]string `json:"network_node_endpoints"`
+	// the node supervisor never switches away from a pinned node
+	NodePinned   bool   `json:"node_pinned"`
 	MainTabBars  string `json:"main_tab_bars"`
 	Theme        string `json:"theme"`
 	FolderLayout string `json:"folder_layout"`
+	// balance decoding lookup table - bigger is faster but takes more memory and time to generate
+	LookupTableSize int `json:"lookup_table_size"`
+	// gateways are tried in order when fetching ipfs:// and ipns:// urls
+	IPFSGateways []IPFSGateway `json:"ipfs_gateways"`
+	// max size in bytes of a fetched file (nft media, token images...)
+	FetchMaxSize int64 `json:"fetch_max_size"`
+	// least recently used cache files are removed above this size in bytes - 0 is unlimited
+	CacheMaxSize int64 `json:"cache_max_size"`
+	// wallet rpc server host:port - localhost only by default
+	RPCBind string `json:"rpc_bind"`
+	// serve the wallet rpc over https with a self-signed certificate
+	RPCTLS bool `json:"rpc_tls"`
+	// start the xswd server of dApps when a wallet is opened
+	XSWD bool `json:"xswd"`
 }
 
 var (
-	AppDir     string
+	AppDir string
+	// app.db, wallets and cache of the current network
+	NetworkDir string
 	WalletsDir string
 	CacheDir   string
 )
@@ -61,26 +103,31 @@ func Load() error {
 		}
 	}
 
-	walletsDir := filepath.Join(appDir, "wallets")
-	cacheDir := filepath.Join(appDir, "cache")
-
 	AppDir = appDir
-	WalletsDir = walletsDir
-	CacheDir = cacheDir
+	return Reload()
+}
 
+// reads settings.json of the app dir again - used after it's restored from a backup
+func Reload() error {
 	settingsPath := filepath.Join(AppDir, "settings.json")
 
 	// settings with default values
 	appSettings := AppSettings{
-		Language:     "en",
-		HideBalance:  false,
-		SendRingSize: 16,
-		NodeEndpoint: "",
-		MainTabBars:  MainTabBarsTxs,
-		FolderLayout: FolderLayoutGrid,
+		Language:        "en",
+		HideBalance:     false,
+		SendRingSize:    16,
+		Network:         NetworkMainnet,
+		NodeEndpoint:    "",
+		MainTabBars:     MainTabBarsTxs,
+		FolderLayout:    FolderLayoutGrid,
+		LookupTableSize: 1 << 21, // same as lookup_table/create default
+		IPFSGateways:    DefaultIPFSGateways,
+		FetchMaxSize:    20 << 20,
+		CacheMaxSize:    500 << 20,
+		RPCBind:         "127.0.0.1:10107",
 	}
 
-	_, err = os.Stat(settingsPath)
+	_, err := os.Stat(settingsPath)
 	if err == nil {
 		data, err := os.ReadFile(settingsPath)
 		if err != nil {
@@ -116,8 +163,70 @@ func Load() error {
 		theme.Current = theme.Get(appSettings.Theme)
 	}
 
+	if !IsNetwork(appSettings.Network) {
+		appSettings.Network = NetworkMainnet
+	}
+
 	App = appSettings
-	return nil
+	return InitNetwork()
+}
+
+func IsNetwork(network string) bool {
+	for _, n := range Networks {
+		if n == network {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mainnet keeps the app dir layout it had before networks were added
+func networkDir(appDir string, network string) string {
+	if network == NetworkMainnet {
+		return appDir
+	}
+
+	return filepath.Join(appDir, network)
+}
+
+// data dir of any network - the current one is NetworkDir
+func GetNetworkDir(network string) string {
+	return networkDir(AppDir, network)
+}
+
+// sets the derohe globals and the data dirs of the current network
+func InitNetwork() error {
+	// the simulator runs with the testnet config
+	globals.Arguments["--testnet"] = App.Network != NetworkMainnet
+	globals.Arguments["--simulator"] = App.Network == NetworkSimulator
+	globals.InitNetwork()
+
+	NetworkDir = networkDir(AppDir, App.Network)
+	WalletsDir = filepath.Join(NetworkDir, "wallets")
+	CacheDir = filepath.Join(NetworkDir, "cache")
+
+	return os.MkdirAll(NetworkDir, os.ModePerm)
+}
+
+// the selected node is kept per network - doesn't save the settings
+func SetNetwork(network string) error {
+	if !IsNetwork(network) {
+		return fmt.Errorf("unknown network %q", network)
+	}
+
+	if network == App.Network {
+		return nil
+	}
+
+	if App.NetworkNodeEndpoints == nil {
+		App.NetworkNodeEndpoints = make(map[string]string)
+	}
+
+	App.NetworkNodeEndpoints[App.Network] = App.NodeEndpoint
+	App.NodeEndpoint = App.NetworkNodeEndpoints[network]
+	App.Network = network
+	return InitNetwork()
 }
 
 func Save() error {
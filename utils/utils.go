@@ -167,7 +167,12 @@ func (s *ShiftNumber) Parse(value string) error {
 		return err
 	}
 
-	s.Number = uint64(floatValue * math.Pow(10, float64(s.Decimals)))
+	if floatValue < 0 {
+		return fmt.Errorf("negative number [%s]", value)
+	}
+
+	// round to avoid float errors like 0.29 becoming 28999
+	s.Number = uint64(math.Round(floatValue * math.Pow(10, float64(s.Decimals))))
 	return nil
 }
 
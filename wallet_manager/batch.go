@@ -0,0 +1,326 @@
+package wallet_manager
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deroproject/derohe/config"
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/deroproject/derohe/rpc"
+	"github.com/deroproject/derohe/transaction"
+	"github.com/secretsystems/secret-wallet/utils"
+)
+
+// a batch row as entered by the user or imported from a csv file
+type BatchTransfer struct {
+	Addr    string
+	Amount  string
+	SCID    string
+	Comment string
+	DstPort string
+}
+
+type BatchTx struct {
+	Transfers []rpc.Transfer
+	TxFees    uint64
+}
+
+// keep some room under the daemon limit because the final tx is built with different fees
+var batchMaxTxSize = uint64(config.STARGATE_HE_MAX_TX_SIZE * 9 / 10)
+
+// csv columns are address, amount, token scid, comment and destination port - only the first two are required
+func ParseBatchCSV(reader io.Reader) ([]BatchTransfer, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var batchTransfers []BatchTransfer
+	for i, record := range records {
+		// skip the header if there is one
+		if i == 0 && strings.EqualFold(strings.TrimSpace(record[0]), "address") {
+			continue
+		}
+
+		if len(record) < 2 || len(record) > 5 {
+			return nil, fmt.Errorf("line %d: expected 2 to 5 columns but got %d", i+1, len(record))
+		}
+
+		values := make([]string, 5)
+		for j, value := range record {
+			values[j] = strings.TrimSpace(value)
+		}
+
+		batchTransfers = append(batchTransfers, BatchTransfer{
+			Addr:    values[0],
+			Amount:  values[1],
+			SCID:    values[2],
+			Comment: values[3],
+			DstPort: values[4],
+		})
+	}
+
+	return batchTransfers, nil
+}
+
+// validates a batch row and returns the transfer with the token used for the amount decimals
+func (w *Wallet) ValidateBatchTransfer(batchTransfer BatchTransfer) (transfer rpc.Transfer, token *Token, err error) {
+	if batchTransfer.Addr == "" {
+		err = fmt.Errorf("destination address is empty")
+		return
+	}
+
+	address, err := rpc.NewAddress(batchTransfer.Addr)
+	if err != nil {
+		var addrString string
+		addrString, err = w.Memory.NameToAddress(batchTransfer.Addr)
+		if err != nil {
+			if utils.IsErrLeafNotFound(err) {
+				err = fmt.Errorf("address not found for [%s]", batchTransfer.Addr)
+			}
+
+			return
+		}
+
+		address, err = rpc.NewAddress(addrString)
+		if err != nil {
+			return
+		}
+	}
+
+	walletAddr := w.Memory.GetAddress()
+	if bytes.Equal(address.Compressed(), walletAddr.Compressed()) {
+		err = fmt.Errorf("can't send to self")
+		return
+	}
+
+	token = DeroToken()
+	scId := crypto.ZEROHASH
+	if batchTransfer.SCID != "" {
+		scId = crypto.HashHexToHash(batchTransfer.SCID)
+		if scId.IsZero() || scId.String() != strings.ToLower(batchTransfer.SCID) {
+			err = fmt.Errorf("invalid token scid [%s]", batchTransfer.SCID)
+			return
+		}
+
+		token, err = GetTokenBySCID(scId.String())
+		if err != nil {
+			return
+		}
+	}
+
+	var arguments rpc.Arguments
+	amount := utils.ShiftNumber{Decimals: int(token.Decimals)}
+
+	if address.IsIntegratedAddress() {
+		// the integrated address arguments replace the comment and port of the row
+		err = address.Arguments.Validate_Arguments()
+		if err != nil {
+			return
+		}
+
+		if address.Arguments.Has(rpc.RPC_EXPIRY, rpc.DataTime) {
+			expireTime := address.Arguments.Value(rpc.RPC_EXPIRY, rpc.DataTime).(time.Time)
+			if expireTime.Before(time.Now().UTC()) {
+				err = fmt.Errorf("the integrated address has expired")
+				return
+			}
+		}
+
+		if !address.Arguments.Has(rpc.RPC_DESTINATION_PORT, rpc.DataUint64) {
+			err = fmt.Errorf("the integrated address does not contain a destination port")
+			return
+		}
+
+		if address.Arguments.Has(rpc.RPC_NEEDS_REPLYBACK_ADDRESS, rpc.DataUint64) {
+			arguments = append(arguments, rpc.Argument{
+				Name:     rpc.RPC_REPLYBACK_ADDRESS,
+				DataType: rpc.DataAddress,
+				Value:    w.Memory.GetAddress(),
+			})
+		}
+
+		if address.Arguments.Has(rpc.RPC_VALUE_TRANSFER, rpc.DataUint64) {
+			amount.Number = address.Arguments.Value(rpc.RPC_VALUE_TRANSFER, rpc.DataUint64).(uint64)
+			arguments = append(arguments, rpc.Argument{
+				Name:     rpc.RPC_VALUE_TRANSFER,
+				DataType: rpc.DataUint64,
+				Value:    amount.Number,
+			})
+		}
+
+		arguments = append(arguments, rpc.Argument{
+			Name:     rpc.RPC_DESTINATION_PORT,
+			DataType: rpc.DataUint64,
+			Value:    address.Arguments.Value(rpc.RPC_DESTINATION_PORT, rpc.DataUint64).(uint64),
+		})
+
+		if address.Arguments.Has(rpc.RPC_COMMENT, rpc.DataString) {
+			arguments = append(arguments, rpc.Argument{
+				Name:     rpc.RPC_COMMENT,
+				DataType: rpc.DataString,
+				Value:    address.Arguments.Value(rpc.RPC_COMMENT, rpc.DataString).(string),
+			})
+		}
+	} else {
+		if batchTransfer.Comment != "" {
+			arguments = append(arguments, rpc.Argument{
+				Name:     rpc.RPC_COMMENT,
+				DataType: rpc.DataString,
+				Value:    batchTransfer.Comment,
+			})
+		}
+
+		if batchTransfer.DstPort != "" {
+			var dstPort uint64
+			dstPort, err = strconv.ParseUint(batchTransfer.DstPort, 10, 64)
+			if err != nil {
+				err = fmt.Errorf("invalid destination port [%s]", batchTransfer.DstPort)
+				return
+			}
+
+			arguments = append(arguments, rpc.Argument{
+				Name:     rpc.RPC_DESTINATION_PORT,
+				DataType: rpc.DataUint64,
+				Value:    dstPort,
+			})
+		}
+	}
+
+	if amount.Number == 0 {
+		if batchTransfer.Amount == "" {
+			err = fmt.Errorf("amount cannot be empty")
+			return
+		}
+
+		err = amount.Parse(batchTransfer.Amount)
+		if err != nil {
+			err = fmt.Errorf("invalid amount [%s]", batchTransfer.Amount)
+			return
+		}
+
+		if amount.Number == 0 {
+			err = fmt.Errorf("amount must be greater than 0")
+			return
+		}
+	}
+
+	_, err = arguments.CheckPack(transaction.PAYLOAD0_LIMIT)
+	if err != nil {
+		return
+	}
+
+	transfer = rpc.Transfer{
+		SCID:        scId,
+		Destination: address.String(),
+		Amount:      amount.Number,
+		Payload_RPC: arguments,
+	}
+
+	return
+}
+
+// splits the transfers in multiple txs when the batch is too big for a single one
+// the txs must be sent one after the other - a tx is only valid once the previous one is mined
+func (w *Wallet) SplitBatchTransfers(transfers []rpc.Transfer, ringsize uint64) ([]BatchTx, error) {
+	if len(transfers) == 0 {
+		return nil, fmt.Errorf("no transfers")
+	}
+
+	// the groups are capped subslices - BuildTransaction can append a Dero transfer and must not overwrite the next one
+	// estimate how many transfers fit in a tx with the size of one and two transfers
+	tx, _, _, err := w.BuildTransaction(transfers[:1:1], ringsize, nil, true)
+	if err != nil {
+		return nil, err
+	}
+
+	txSize := uint64(len(tx.Serialize()))
+	transferSize := txSize
+	overhead := uint64(0)
+
+	if len(transfers) > 1 {
+		tx, _, _, err = w.BuildTransaction(transfers[:2:2], ringsize, nil, true)
+		if err != nil {
+			return nil, err
+		}
+
+		size := uint64(len(tx.Serialize()))
+		if size > txSize && size-txSize < txSize {
+			transferSize = size - txSize
+			overhead = txSize - transferSize
+		}
+	}
+
+	transfersPerTx := 1
+	if batchMaxTxSize > overhead+transferSize {
+		transfersPerTx = int((batchMaxTxSize - overhead) / transferSize)
+	}
+
+	var groups [][]rpc.Transfer
+	for start := 0; start < len(transfers); start += transfersPerTx {
+		end := start + transfersPerTx
+		if end > len(transfers) {
+			end = len(transfers)
+		}
+
+		groups = append(groups, transfers[start:end:end])
+	}
+
+	var batchTxs []BatchTx
+	for len(groups) > 0 {
+		group := groups[0]
+		groups = groups[1:]
+
+		tx, txFees, _, err := w.BuildTransaction(group, ringsize, nil, true)
+		if err != nil {
+			return nil, err
+		}
+
+		// the estimation was wrong - split the group in two and try again
+		if uint64(len(tx.Serialize())) > batchMaxTxSize {
+			if len(group) == 1 {
+				return nil, fmt.Errorf("transaction is too big - lower the ring size")
+			}
+
+			half := len(group) / 2
+			groups = append([][]rpc.Transfer{group[:half:half], group[half:]}, groups...)
+			continue
+		}
+
+		batchTxs = append(batchTxs, BatchTx{
+			Transfers: group,
+			TxFees:    txFees,
+		})
+	}
+
+	// each tx only checks its own amounts so make sure the wallet can pay for the whole batch
+	assetsAmount := make(map[crypto.Hash]uint64)
+	for _, batchTx := range batchTxs {
+		assetsAmount[crypto.ZEROHASH] += batchTx.TxFees
+		for _, transfer := range batchTx.Transfers {
+			assetsAmount[transfer.SCID] += transfer.Amount + transfer.Burn
+		}
+	}
+
+	for asset, amount := range assetsAmount {
+		balance, _ := w.Memory.Get_Balance_scid(asset)
+		if amount > balance {
+			if asset.IsZero() {
+				return nil, fmt.Errorf("you don't have enough Dero for the batch and fees")
+			}
+
+			return nil, fmt.Errorf("you don't have enough asset funds of [%s] for the batch", utils.ReduceTxId(asset.String()))
+		}
+	}
+
+	return batchTxs, nil
+}
@@ -0,0 +1,183 @@
+package wallet_manager
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/deroproject/derohe/rpc"
+	"github.com/secretsystems/secret-wallet/mock_daemon"
+)
+
+func TestParseBatchCSV(t *testing.T) {
+	addr := mock_daemon.RandomAddress()
+	scId := strings.Repeat("ab", 32)
+
+	data := "address,amount,scid,comment,port\n" +
+		addr + ",1.5\n" +
+		addr + ", 2 ," + scId + ",\"salary, june\",1337\n"
+
+	batchTransfers, err := ParseBatchCSV(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []BatchTransfer{
+		{Addr: addr, Amount: "1.5"},
+		{Addr: addr, Amount: "2", SCID: scId, Comment: "salary, june", DstPort: "1337"},
+	}
+
+	if len(batchTransfers) != len(expected) {
+		t.Fatalf("%d rows instead of %d", len(batchTransfers), len(expected))
+	}
+
+	for i := range expected {
+		if batchTransfers[i] != expected[i] {
+			t.Fatalf("row %d is %+v instead of %+v", i, batchTransfers[i], expected[i])
+		}
+	}
+
+	_, err = ParseBatchCSV(strings.NewReader(addr + "\n"))
+	if err == nil || !strings.HasPrefix(err.Error(), "line 1") {
+		t.Fatalf("expected a line 1 error, got %v", err)
+	}
+}
+
+func TestValidateBatchTransfer(t *testing.T) {
+	wallet := openTestWallet(t, 100000)
+	dest := newTestDestination(crypto.ZEROHASH)
+
+	transfer, token, err := wallet.ValidateBatchTransfer(BatchTransfer{Addr: dest, Amount: "0.29", Comment: "hello", DstPort: "1337"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !token.GetHash().IsZero() || !transfer.SCID.IsZero() {
+		t.Fatal("transfer is not in Dero")
+	}
+
+	if transfer.Amount != 29000 || transfer.Destination != dest {
+		t.Fatalf("unexpected transfer %+v", transfer)
+	}
+
+	if !transfer.Payload_RPC.Has(rpc.RPC_COMMENT, rpc.DataString) ||
+		transfer.Payload_RPC.Value(rpc.RPC_DESTINATION_PORT, rpc.DataUint64).(uint64) != 1337 {
+		t.Fatalf("unexpected payload %+v", transfer.Payload_RPC)
+	}
+
+	tests := []struct {
+		name          string
+		batchTransfer BatchTransfer
+	}{
+		{name: "empty address", batchTransfer: BatchTransfer{Amount: "1"}},
+		{name: "self", batchTransfer: BatchTransfer{Addr: wallet.Info.Addr, Amount: "1"}},
+		{name: "empty amount", batchTransfer: BatchTransfer{Addr: dest}},
+		{name: "zero amount", batchTransfer: BatchTransfer{Addr: dest, Amount: "0"}},
+		{name: "negative amount", batchTransfer: BatchTransfer{Addr: dest, Amount: "-1"}},
+		{name: "invalid port", batchTransfer: BatchTransfer{Addr: dest, Amount: "1", DstPort: "port"}},
+		{name: "invalid scid", batchTransfer: BatchTransfer{Addr: dest, Amount: "1", SCID: "abcd"}},
+		{name: "unknown scid", batchTransfer: BatchTransfer{Addr: dest, Amount: "1", SCID: strings.Repeat("99", 32)}},
+		{name: "comment too long", batchTransfer: BatchTransfer{Addr: dest, Amount: "1", Comment: strings.Repeat("a", 200)}},
+	}
+
+	for _, test := range tests {
+		_, _, err := wallet.ValidateBatchTransfer(test.batchTransfer)
+		if err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+	}
+}
+
+func TestSplitBatchTransfers(t *testing.T) {
+	wallet := openTestWallet(t, 100000)
+
+	var transfers []rpc.Transfer
+	for i := 0; i < 5; i++ {
+		transfers = append(transfers, rpc.Transfer{Destination: newTestDestination(crypto.ZEROHASH), Amount: 1000})
+	}
+
+	batchTxs, err := wallet.SplitBatchTransfers(transfers, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(batchTxs) != 1 || len(batchTxs[0].Transfers) != 5 {
+		t.Fatalf("expected a single tx with all the transfers")
+	}
+
+	// only two transfers fit in a tx
+	tx, _, _, err := wallet.BuildTransaction(transfers[:1], 2, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	size1 := uint64(len(tx.Serialize()))
+	tx, _, _, err = wallet.BuildTransaction(transfers[:2], 2, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	size2 := uint64(len(tx.Serialize()))
+	maxTxSize := batchMaxTxSize
+	batchMaxTxSize = size2 + (size2-size1)/2
+	defer func() { batchMaxTxSize = maxTxSize }()
+
+	batchTxs, err = wallet.SplitBatchTransfers(transfers, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	count := 0
+	fees := uint64(0)
+	for _, batchTx := range batchTxs {
+		if len(batchTx.Transfers) > 2 {
+			t.Fatalf("tx has %d transfers", len(batchTx.Transfers))
+		}
+
+		for _, transfer := range batchTx.Transfers {
+			if transfer.Destination != transfers[count].Destination {
+				t.Fatalf("transfer %d is out of order", count)
+			}
+			count++
+		}
+
+		fees += batchTx.TxFees
+	}
+
+	if count != len(transfers) || len(batchTxs) != 3 {
+		t.Fatalf("%d transfers in %d txs", count, len(batchTxs))
+	}
+
+	// enough for the transfers but not the fees of the three txs
+	setTestBalance(t, wallet, crypto.ZEROHASH, 5000+fees-1)
+	_, err = wallet.SplitBatchTransfers(transfers, 2)
+	if err == nil || !strings.HasPrefix(err.Error(), "you don't have enough Dero for the batch") {
+		t.Fatalf("expected not enough Dero error, got %v", err)
+	}
+}
+
+func TestSplitBatchTokenTransfers(t *testing.T) {
+	wallet := openTestWallet(t, 100000)
+	scId := crypto.HashHexToHash(strings.Repeat("ef", 32))
+	setTestBalance(t, wallet, scId, 500)
+	daemon.SetRandomAddresses(scId, mock_daemon.RandomAddresses(20))
+
+	transfers := []rpc.Transfer{
+		{SCID: scId, Destination: newTestDestination(scId), Amount: 10},
+		{SCID: scId, Destination: newTestDestination(scId), Amount: 20},
+	}
+
+	batchTxs, err := wallet.SplitBatchTransfers(transfers, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the Dero transfer added by the dry run for the fees must not overwrite the second transfer
+	if transfers[1].SCID != scId || transfers[1].Amount != 20 {
+		t.Fatalf("second transfer was modified %+v", transfers[1])
+	}
+
+	if len(batchTxs) != 1 || len(batchTxs[0].Transfers) != 2 {
+		t.Fatal("expected a single tx with the two token transfers")
+	}
+}
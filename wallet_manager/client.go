@@ -1,5 +1,91 @@
 package wallet_manager
 
-import "github.com/deroproject/derohe/walletapi"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/creachadair/jrpc2"
+	"github.com/creachadair/jrpc2/channel"
+	"github.com/deroproject/derohe/glue/rwc"
+	"github.com/deroproject/derohe/rpc"
+	"github.com/deroproject/derohe/walletapi"
+	"github.com/gorilla/websocket"
+)
 
 var RPC_Client = &walletapi.Client{}
+
+var ErrNoNode = errors.New("wallet is not connected to a node")
+
+// walletapi keeps its daemon client private so we open our own websocket to the same endpoint
+func ConnectRPCClient(endpoint string) error {
+	wsUrl := DaemonWSUrl(endpoint)
+
+	ws, _, err := websocket.DefaultDialer.Dial(wsUrl, nil)
+	if err != nil {
+		return err
+	}
+
+	CloseRPCClient()
+
+	inputOutput := rwc.New(ws)
+	RPC_Client.WS = ws
+	RPC_Client.RPC = jrpc2.NewClient(channel.RawJSON(inputOutput, inputOutput), nil)
+	return nil
+}
+
+func CloseRPCClient() {
+	// close the websocket first - the jrpc2 client waits for its reader to stop
+	if RPC_Client.WS != nil {
+		RPC_Client.WS.Close()
+	}
+
+	if RPC_Client.RPC != nil {
+		RPC_Client.RPC.Close()
+	}
+}
+
+// health check on a separate connection so the connected client is not touched
+// the latency is the time of the GetInfo call without the websocket handshake
+func ProbeNode(endpoint string, timeout time.Duration) (info rpc.GetInfo_Result, latency time.Duration, err error) {
+	dialer := websocket.Dialer{HandshakeTimeout: timeout}
+	ws, _, err := dialer.Dial(DaemonWSUrl(endpoint), nil)
+	if err != nil {
+		return
+	}
+
+	inputOutput := rwc.New(ws)
+	client := jrpc2.NewClient(channel.RawJSON(inputOutput, inputOutput), nil)
+	defer func() {
+		ws.Close()
+		client.Close()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	err = client.CallResult(ctx, "DERO.GetInfo", nil, &info)
+	latency = time.Since(start)
+	return
+}
+
+// same endpoint rules as walletapi.Connect
+func DaemonWSUrl(endpoint string) string {
+	lower := strings.ToLower(endpoint)
+
+	switch {
+	case strings.HasPrefix(lower, "https://"):
+		return fmt.Sprintf("wss://%s/ws", strings.TrimPrefix(lower, "https://"))
+	case strings.HasPrefix(lower, "http://"):
+		return fmt.Sprintf("ws://%s/ws", strings.TrimPrefix(lower, "http://"))
+	case strings.HasPrefix(lower, "wss://"):
+		return fmt.Sprintf("wss://%s/ws", strings.TrimPrefix(lower, "wss://"))
+	case strings.HasPrefix(lower, "ws://"):
+		return fmt.Sprintf("ws://%s/ws", strings.TrimPrefix(lower, "ws://"))
+	}
+
+	return fmt.Sprintf("ws://%s/ws", endpoint)
+}
@@ -0,0 +1,23 @@
+package wallet_manager
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProbeNode(t *testing.T) {
+	info, latency, err := ProbeNode(daemon.Endpoint, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Height != daemon.Height || latency <= 0 {
+		t.Fatalf("unexpected probe %+v %s", info, latency)
+	}
+
+	// nothing listens on the port
+	_, _, err = ProbeNode("127.0.0.1:1", time.Second)
+	if err == nil {
+		t.Fatal("expected a connection error")
+	}
+}
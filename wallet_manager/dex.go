@@ -0,0 +1,284 @@
+package wallet_manager
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/deroproject/derohe/dvm"
+	"github.com/deroproject/derohe/rpc"
+	"github.com/secretsystems/secret-wallet/sc"
+	"github.com/secretsystems/secret-wallet/sc/dex_sc"
+)
+
+// pairs known by the wallet - added manually or found in the sc calls of the wallet history
+// removed pairs are hidden instead of deleted so they are not discovered again
+func initDatabaseDEXPairs(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS dex_pairs (
+			sc_id VARCHAR PRIMARY KEY,
+			timestamp BIGINT,
+			hidden BOOLEAN
+		);
+	`)
+	return err
+}
+
+// the pair variables are not cached because the liquidity changes with every swap
+func GetDEXPair(scId string) (*dex_sc.Pair, error) {
+	var result rpc.GetSC_Result
+	err := RPC_Client.RPC.CallResult(context.Background(), "DERO.GetSC", rpc.GetSC_Params{
+		SCID:      scId,
+		Code:      true,
+		Variables: true,
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	pair := &dex_sc.Pair{}
+	err = pair.Parse(scId, result.VariableStringKeys)
+	if err != nil {
+		return nil, fmt.Errorf("[%s] is not a dex pair: %s", scId, err.Error())
+	}
+
+	for _, entrypoint := range []string{dex_sc.SWAP_ENTRYPOINT, dex_sc.ADD_LIQUIDITY_ENTRYPOINT, dex_sc.REMOVE_LIQUIDITY_ENTRYPOINT} {
+		err = checkDEXFunction(result.Code, entrypoint)
+		if err != nil {
+			return nil, fmt.Errorf("[%s] is not a dex pair: %s", scId, err.Error())
+		}
+	}
+
+	return pair, nil
+}
+
+// the dex entrypoints are checked in the contract code before building a call
+// a call to a missing function or with a missing argument would still be mined and pay the fees
+func checkDEXFunction(code string, entrypoint string, args ...string) error {
+	contract, _, err := dvm.ParseSmartContract(code)
+	if err != nil {
+		return err
+	}
+
+	function, ok := contract.Functions[entrypoint]
+	if !ok {
+		return fmt.Errorf("the contract has no %s function", entrypoint)
+	}
+
+	for _, arg := range args {
+		found := false
+		for _, param := range function.Params {
+			if param.Name == arg && param.Type == dvm.String {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("the %s function has no [%s] argument", entrypoint, arg)
+		}
+	}
+
+	return nil
+}
+
+func (w *Wallet) discoverDEXPairs() error {
+	err := w.SyncEntries()
+	if err != nil {
+		return err
+	}
+
+	_, err = w.DB.Exec(`
+		INSERT OR IGNORE INTO dex_pairs (sc_id,timestamp,hidden)
+		SELECT DISTINCT sc_call_id, ?, false FROM entries
+		WHERE sc_call_id IS NOT NULL
+		AND sc_call_entrypoint IN (?,?,?);
+	`, time.Now().UnixMilli(), dex_sc.SWAP_ENTRYPOINT, dex_sc.ADD_LIQUIDITY_ENTRYPOINT, dex_sc.REMOVE_LIQUIDITY_ENTRYPOINT)
+	return err
+}
+
+func (w *Wallet) GetDEXPairs() ([]string, error) {
+	err := w.discoverDEXPairs()
+	if err != nil {
+		return nil, err
+	}
+
+	query := sq.Select("sc_id").From("dex_pairs").
+		Where(sq.Eq{"hidden": false}).
+		OrderBy("timestamp ASC")
+
+	rows, err := query.RunWith(w.DB).Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var scIds []string
+	for rows.Next() {
+		var scId string
+		err = rows.Scan(&scId)
+		if err != nil {
+			return nil, err
+		}
+
+		scIds = append(scIds, scId)
+	}
+
+	return scIds, nil
+}
+
+func (w *Wallet) StoreDEXPair(scId string) error {
+	_, err := w.DB.Exec(`
+		INSERT INTO dex_pairs (sc_id,timestamp,hidden)
+		VALUES (?,?,false)
+		ON CONFLICT (sc_id) DO UPDATE SET
+		hidden = false;
+	`, scId, time.Now().UnixMilli())
+	return err
+}
+
+func (w *Wallet) DelDEXPair(scId string) error {
+	_, err := w.DB.Exec(`
+		INSERT INTO dex_pairs (sc_id,timestamp,hidden)
+		VALUES (?,?,true)
+		ON CONFLICT (sc_id) DO UPDATE SET
+		hidden = true;
+	`, scId, time.Now().UnixMilli())
+	return err
+}
+
+// sells asset1 for asset2 or the reverse - maxSlippage is a percentage of the pool price
+// the quote is checked again with the latest pair before sending the transaction
+func DEXSwapPayload(pair *dex_sc.Pair, amount uint64, reverse bool, maxSlippage float64, destination string) (transfers []rpc.Transfer, scArgs rpc.Arguments, err error) {
+	if amount == 0 {
+		err = fmt.Errorf("amount is zero")
+		return
+	}
+
+	if pair.Liquidity1 == 0 || pair.Liquidity2 == 0 {
+		err = fmt.Errorf("the pair has no liquidity")
+		return
+	}
+
+	receive, _, slip := pair.CalcSwap(amount, reverse)
+	if receive == 0 {
+		err = fmt.Errorf("amount is too small")
+		return
+	}
+
+	if slip > maxSlippage {
+		err = fmt.Errorf("slippage of %.2f%% is higher than the max of %.2f%%", slip, maxSlippage)
+		return
+	}
+
+	asset := pair.Asset1
+	if reverse {
+		asset = pair.Asset2
+	}
+
+	transfers = []rpc.Transfer{
+		{SCID: crypto.HashHexToHash(asset), Destination: destination, Burn: amount},
+	}
+
+	scArgs = scCallArgs(pair.SCID, dex_sc.SWAP_ENTRYPOINT)
+	return
+}
+
+// amounts should follow the pool ratio - use CalcAddLiquidity to get the other side
+func DEXAddLiquidityPayload(pair *dex_sc.Pair, amount1 uint64, amount2 uint64, destination string) (transfers []rpc.Transfer, scArgs rpc.Arguments, err error) {
+	if amount1 == 0 || amount2 == 0 {
+		err = fmt.Errorf("amount is zero")
+		return
+	}
+
+	transfers = []rpc.Transfer{
+		{SCID: crypto.HashHexToHash(pair.Asset1), Destination: destination, Burn: amount1},
+		{SCID: crypto.HashHexToHash(pair.Asset2), Destination: destination, Burn: amount2},
+	}
+
+	scArgs = scCallArgs(pair.SCID, dex_sc.ADD_LIQUIDITY_ENTRYPOINT)
+	return
+}
+
+// shares are the pair token so they are burned to withdraw both assets
+func DEXRemoveLiquidityPayload(pair *dex_sc.Pair, shares uint64, destination string) (transfers []rpc.Transfer, scArgs rpc.Arguments, err error) {
+	if shares == 0 {
+		err = fmt.Errorf("amount is zero")
+		return
+	}
+
+	if shares > pair.SharesOutstanding {
+		err = fmt.Errorf("shares are higher than the pair outstanding shares")
+		return
+	}
+
+	transfers = []rpc.Transfer{
+		{SCID: crypto.HashHexToHash(pair.SCID), Destination: destination, Burn: shares},
+	}
+
+	scArgs = scCallArgs(pair.SCID, dex_sc.REMOVE_LIQUIDITY_ENTRYPOINT)
+	return
+}
+
+// the bridge status and fee can change so the token variables are not cached
+func GetDEXToken(scId string) (*dex_sc.Token, error) {
+	var result rpc.GetSC_Result
+	err := RPC_Client.RPC.CallResult(context.Background(), "DERO.GetSC", rpc.GetSC_Params{
+		SCID:      scId,
+		Code:      true,
+		Variables: true,
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if sc.CheckType(result.Code) != sc.DEX_SC_TYPE {
+		return nil, fmt.Errorf("[%s] is not a dex token", scId)
+	}
+
+	token := &dex_sc.Token{}
+	err = token.Parse(scId, result.VariableStringKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkDEXFunction(result.Code, dex_sc.BRIDGE_OUT_ENTRYPOINT, dex_sc.BRIDGE_OUT_ETH_ADDR_ARG)
+	if err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// burns the wrapped tokens and the bridge fee in DERO
+func DEXBridgeOutPayload(token *dex_sc.Token, amount uint64, ethAddr string, destination string) (transfers []rpc.Transfer, scArgs rpc.Arguments, err error) {
+	if !token.BridgeOpen {
+		err = fmt.Errorf("the bridge is closed")
+		return
+	}
+
+	if amount == 0 {
+		err = fmt.Errorf("amount is zero")
+		return
+	}
+
+	err = dex_sc.ValidateEthAddress(ethAddr)
+	if err != nil {
+		return
+	}
+
+	transfers = []rpc.Transfer{
+		{SCID: crypto.HashHexToHash(token.SCID), Destination: destination, Burn: amount},
+	}
+
+	if token.BridgeFee > 0 {
+		transfers = append(transfers, rpc.Transfer{SCID: crypto.ZEROHASH, Destination: destination, Burn: token.BridgeFee})
+	}
+
+	scArgs = scCallArgs(token.SCID, dex_sc.BRIDGE_OUT_ENTRYPOINT)
+	scArgs = append(scArgs, rpc.Argument{Name: dex_sc.BRIDGE_OUT_ETH_ADDR_ARG, DataType: rpc.DataString, Value: ethAddr})
+	return
+}
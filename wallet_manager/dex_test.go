@@ -0,0 +1,331 @@
+package wallet_manager
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/deroproject/derohe/rpc"
+	"github.com/secretsystems/secret-wallet/sc/dex_sc"
+)
+
+// only the functions called by the wallet
+const testDEXPairCode = `Function Swap() Uint64
+10 RETURN 0
+End Function
+
+Function AddLiquidity() Uint64
+10 RETURN 0
+End Function
+
+Function RemoveLiquidity() Uint64
+10 RETURN 0
+End Function`
+
+const testDEXTokenCode = `Function Bridge(eth_addr String) Uint64
+10 RETURN 0
+End Function`
+
+func newTestPairVariables(val1 float64, val2 float64) map[string]interface{} {
+	return map[string]interface{}{
+		"numTrustees":       float64(3),
+		"asset1":            crypto.ZEROHASH.String(),
+		"asset2":            strings.Repeat("aa", 32),
+		"symbol":            encodeTestString("DERO-DUSDT"),
+		"quorum":            float64(2),
+		"fee":               float64(30),
+		"val1":              val1,
+		"val2":              val2,
+		"sharesOutstanding": float64(1000),
+		"adds":              float64(1),
+		"rems":              float64(0),
+		"swaps":             float64(4),
+	}
+}
+
+func TestGetDEXPair(t *testing.T) {
+	scId := strings.Repeat("d1", 32)
+	daemon.SetSC(scId, rpc.GetSC_Result{Code: testDEXPairCode, VariableStringKeys: newTestPairVariables(100000, 200000)})
+
+	pair, err := GetDEXPair(scId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pair.SCID != scId || pair.Symbol != "DERO-DUSDT" || pair.Fee != 30 ||
+		pair.Liquidity1 != 100000 || pair.Liquidity2 != 200000 || pair.SharesOutstanding != 1000 {
+		t.Fatalf("unexpected pair %+v", pair)
+	}
+
+	// a token is not a pair
+	notPair := strings.Repeat("d2", 32)
+	daemon.SetSC(notPair, rpc.GetSC_Result{VariableStringKeys: map[string]interface{}{
+		"name": encodeTestString("Token"),
+	}})
+
+	_, err = GetDEXPair(notPair)
+	if err == nil {
+		t.Fatal("expected an error for a contract that is not a pair")
+	}
+
+	// the pair variables without the pair functions
+	noFunctions := strings.Repeat("d3", 32)
+	daemon.SetSC(noFunctions, rpc.GetSC_Result{
+		Code:               strings.Split(testDEXPairCode, "\n\nFunction RemoveLiquidity")[0],
+		VariableStringKeys: newTestPairVariables(100000, 200000),
+	})
+
+	_, err = GetDEXPair(noFunctions)
+	if err == nil || !strings.Contains(err.Error(), dex_sc.REMOVE_LIQUIDITY_ENTRYPOINT) {
+		t.Fatalf("expected a missing function error instead of %v", err)
+	}
+}
+
+func TestDEXPairs(t *testing.T) {
+	wallet := openTestWallet(t, 0)
+
+	stored := strings.Repeat("e1", 32)
+	err := wallet.StoreDEXPair(stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// pairs of the wallet history are discovered
+	called := strings.Repeat("e2", 32)
+	entry := newTestEntry(1, false, 100)
+	entry.Payload_RPC = scCallArgs(called, dex_sc.SWAP_ENTRYPOINT)
+	addTestEntry(wallet, entry)
+
+	other := newTestEntry(2, false, 100)
+	other.Payload_RPC = scCallArgs(strings.Repeat("e3", 32), "Register")
+	addTestEntry(wallet, other)
+
+	scIds, err := wallet.GetDEXPairs()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprint(scIds) != fmt.Sprint([]string{stored, called}) {
+		t.Fatalf("unexpected pairs %v", scIds)
+	}
+
+	// a removed pair is not discovered again
+	err = wallet.DelDEXPair(called)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scIds, err = wallet.GetDEXPairs()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprint(scIds) != fmt.Sprint([]string{stored}) {
+		t.Fatalf("unexpected pairs after removal %v", scIds)
+	}
+
+	err = wallet.StoreDEXPair(called)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scIds, err = wallet.GetDEXPairs()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(scIds) != 2 {
+		t.Fatalf("pair was not added back %v", scIds)
+	}
+}
+
+func TestDEXSwapPayload(t *testing.T) {
+	pair := &dex_sc.Pair{}
+	err := pair.Parse(strings.Repeat("f1", 32), newTestPairVariables(100000, 200000))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	destination := strings.Repeat("a", 10)
+	transfers, scArgs, err := DEXSwapPayload(pair, 1000, true, 1, destination)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(transfers) != 1 || transfers[0].SCID.String() != pair.Asset2 || transfers[0].Burn != 1000 || transfers[0].Amount != 0 {
+		t.Fatalf("unexpected transfers %+v", transfers)
+	}
+
+	if !scArgs.Has("entrypoint", rpc.DataString) || scArgs.Value("entrypoint", rpc.DataString) != dex_sc.SWAP_ENTRYPOINT {
+		t.Fatalf("unexpected sc args %+v", scArgs)
+	}
+
+	// 10% of the pool moves the price more than the max slippage
+	_, _, err = DEXSwapPayload(pair, 10000, false, 1, destination)
+	if err == nil {
+		t.Fatal("expected a slippage error")
+	}
+
+	_, _, err = DEXSwapPayload(pair, 10000, false, 10, destination)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	empty := &dex_sc.Pair{SCID: pair.SCID}
+	_, _, err = DEXSwapPayload(empty, 1000, false, 100, destination)
+	if err == nil {
+		t.Fatal("expected a liquidity error")
+	}
+}
+
+func TestDEXLiquidityPayload(t *testing.T) {
+	pair := &dex_sc.Pair{}
+	err := pair.Parse(strings.Repeat("f2", 32), newTestPairVariables(100000, 200000))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	amount2, shares := pair.CalcAddLiquidity(1000, false)
+	if amount2 != 2000 || shares != 10 {
+		t.Fatalf("unexpected add liquidity %d %d", amount2, shares)
+	}
+
+	transfers, _, err := DEXAddLiquidityPayload(pair, 1000, amount2, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(transfers) != 2 || transfers[0].Burn != 1000 || transfers[1].Burn != 2000 ||
+		transfers[1].SCID.String() != pair.Asset2 {
+		t.Fatalf("unexpected transfers %+v", transfers)
+	}
+
+	transfers, _, err = DEXRemoveLiquidityPayload(pair, 10, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(transfers) != 1 || transfers[0].SCID.String() != pair.SCID || transfers[0].Burn != 10 {
+		t.Fatalf("unexpected transfers %+v", transfers)
+	}
+
+	_, _, err = DEXRemoveLiquidityPayload(pair, 1001, "")
+	if err == nil {
+		t.Fatal("expected an outstanding shares error")
+	}
+}
+
+func TestDEXBridgeOutPayload(t *testing.T) {
+	code := useTestCode(t, &dex_sc.DEX_SC_SHA256, testDEXTokenCode)
+	scId := strings.Repeat("b1", 32)
+	variables := map[string]interface{}{
+		"name":            encodeTestString("Dero Wrapped Ether"),
+		"decimals":        float64(9),
+		"image_url":       encodeTestString(""),
+		"symbol":          encodeTestString("DWETH"),
+		"totalsupply":     float64(1000000),
+		"native_symbol":   encodeTestString("WETH"),
+		"native_decimals": float64(18),
+		"quorum":          float64(2),
+		"numTrustees":     float64(3),
+		"version":         encodeTestString("1.0.0"),
+		"bridgeOpen":      float64(1),
+		"bridgeFee":       float64(500),
+	}
+	daemon.SetSC(scId, rpc.GetSC_Result{Code: code, VariableStringKeys: variables})
+
+	token, err := GetDEXToken(scId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// checksum address from EIP-55
+	ethAddr := "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+	transfers, scArgs, err := DEXBridgeOutPayload(token, 1000, ethAddr, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(transfers) != 2 || transfers[0].Burn != 1000 || transfers[0].SCID.String() != scId ||
+		!transfers[1].SCID.IsZero() || transfers[1].Burn != 500 {
+		t.Fatalf("unexpected transfers %+v", transfers)
+	}
+
+	if scArgs.Value("entrypoint", rpc.DataString) != dex_sc.BRIDGE_OUT_ENTRYPOINT || scArgs.Value("eth_addr", rpc.DataString) != ethAddr {
+		t.Fatalf("unexpected sc args %+v", scArgs)
+	}
+
+	invalidAddrs := []string{
+		"5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAe",
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeZ",
+		"0x5AAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", // wrong checksum
+	}
+
+	for _, addr := range invalidAddrs {
+		_, _, err = DEXBridgeOutPayload(token, 1000, addr, "")
+		if err == nil {
+			t.Errorf("expected an error for [%s]", addr)
+		}
+	}
+
+	_, _, err = DEXBridgeOutPayload(token, 1000, strings.ToLower(ethAddr), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	variables["bridgeOpen"] = float64(0)
+	daemon.SetSC(scId, rpc.GetSC_Result{Code: code, VariableStringKeys: variables})
+
+	token, err = GetDEXToken(scId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err = DEXBridgeOutPayload(token, 1000, ethAddr, "")
+	if err == nil {
+		t.Fatal("expected a closed bridge error")
+	}
+
+	// pairs are not tokens
+	pairId := strings.Repeat("b2", 32)
+	daemon.SetSC(pairId, rpc.GetSC_Result{VariableStringKeys: newTestPairVariables(1, 1)})
+	_, err = GetDEXToken(pairId)
+	if err == nil {
+		t.Fatal("expected a dex token error")
+	}
+
+	// a token code with another bridge argument
+	code = useTestCode(t, &dex_sc.DEX_SC_SHA256, strings.Replace(testDEXTokenCode, "eth_addr", "addr", 1))
+	daemon.SetSC(scId, rpc.GetSC_Result{Code: code, VariableStringKeys: variables})
+	_, err = GetDEXToken(scId)
+	if err == nil || !strings.Contains(err.Error(), "eth_addr") {
+		t.Fatalf("expected a missing argument error instead of %v", err)
+	}
+}
+
+func TestInsertOutgoingTxWithType(t *testing.T) {
+	wallet := openTestWallet(t, 100000)
+
+	dest := newTestDestination(crypto.ZEROHASH)
+	tx, _, _, err := wallet.BuildTransaction([]rpc.Transfer{{Destination: dest, Amount: 1}}, 2, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = wallet.InsertOutgoingTxWithType(tx, OUTGOING_TX_DEX_BRIDGE_OUT)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	txType := OUTGOING_TX_DEX_BRIDGE_OUT
+	outgoingTxs, err := wallet.GetOutgoingTxs(GetOutgoingTxsParams{TxType: &txType})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(outgoingTxs) != 1 || outgoingTxs[0].TxId != tx.GetHash().String() {
+		t.Fatalf("unexpected outgoing txs %+v", outgoingTxs)
+	}
+}
@@ -0,0 +1,331 @@
+package wallet_manager
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/deroproject/derohe/dvm"
+	"github.com/deroproject/derohe/rpc"
+	"github.com/secretsystems/secret-wallet/sc"
+	"github.com/secretsystems/secret-wallet/sc/g45_sc"
+)
+
+// G45 contracts are recognized by the hash of their code so the templates are installed untouched
+// and the metadata is passed to the initialize function matching its parameters by name
+var G45_C_SET_ASSETS_ENTRYPOINT = "SetAssets"
+var G45_METADATA_FORMAT = "json"
+
+type G45Mint struct {
+	SCType      sc.SCType
+	Code        string
+	Name        string
+	Symbol      string
+	Image       string
+	Description string
+	Attributes  map[string]interface{}
+	Decimals    uint64
+	MaxSupply   uint64
+	Collection  string
+	Extra       map[string]string // other parameters of the template entered by the user
+}
+
+func (m G45Mint) Metadata() (string, error) {
+	var metadata interface{}
+	switch m.SCType {
+	case sc.G45_NFT_TYPE:
+		metadata = g45_sc.NFTMetadata{
+			Name:        m.Name,
+			Description: m.Description,
+			Attributes:  m.Attributes,
+			Image:       m.Image,
+		}
+	case sc.G45_AT_TYPE, sc.G45_FAT_TYPE:
+		metadata = g45_sc.TokenMetadata{
+			Name:   m.Name,
+			Symbol: m.Symbol,
+			Image:  m.Image,
+		}
+	case sc.G45_C_TYPE:
+		metadata = g45_sc.CollectionMetadata{
+			Name:          m.Name,
+			Description:   m.Description,
+			BackdropImage: m.Image,
+		}
+	default:
+		return "", fmt.Errorf("[%s] is not a G45 type", m.SCType)
+	}
+
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+func (m G45Mint) Validate() error {
+	if sc.CheckType(m.Code) != m.SCType {
+		return fmt.Errorf("the code is not a %s template", m.SCType)
+	}
+
+	if strings.TrimSpace(m.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	switch m.SCType {
+	case sc.G45_AT_TYPE, sc.G45_FAT_TYPE:
+		if m.MaxSupply == 0 {
+			return fmt.Errorf("max supply must be greater than zero")
+		}
+
+		// the amounts are uint64 with the decimals included
+		if m.Decimals > 18 {
+			return fmt.Errorf("decimals can't be more than 18")
+		}
+	}
+
+	if m.Collection != "" && crypto.HashHexToHash(m.Collection).String() != m.Collection {
+		return fmt.Errorf("invalid collection [%s]", m.Collection)
+	}
+
+	return nil
+}
+
+// params of the initialize function that are not filled by the wizard
+func (m G45Mint) ExtraParams() ([]dvm.Variable, error) {
+	function, err := g45InitializeFunction(m.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := m.values()
+	if err != nil {
+		return nil, err
+	}
+
+	var params []dvm.Variable
+	for _, param := range function.Params {
+		_, ok := values[param.Name]
+		if !ok && param.Name != "value" {
+			params = append(params, param)
+		}
+	}
+
+	return params, nil
+}
+
+func (m G45Mint) values() (map[string]interface{}, error) {
+	metadata, err := m.Metadata()
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]interface{}{
+		"metadataFormat": G45_METADATA_FORMAT,
+		"metadata":       metadata,
+	}
+
+	if m.SCType != sc.G45_C_TYPE {
+		values["collection"] = m.Collection
+	}
+
+	switch m.SCType {
+	case sc.G45_AT_TYPE, sc.G45_FAT_TYPE:
+		values["decimals"] = m.Decimals
+		values["maxSupply"] = m.MaxSupply
+	}
+
+	return values, nil
+}
+
+func (m G45Mint) InstallArgs() (rpc.Arguments, error) {
+	err := m.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	function, err := g45InitializeFunction(m.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := m.values()
+	if err != nil {
+		return nil, err
+	}
+
+	for name, value := range m.Extra {
+		values[name] = value
+	}
+
+	scArgs := rpc.Arguments{
+		{Name: rpc.SCACTION, DataType: rpc.DataUint64, Value: uint64(rpc.SC_INSTALL)},
+		{Name: rpc.SCCODE, DataType: rpc.DataString, Value: m.Code},
+	}
+
+	args, err := scFunctionArgs(function, values)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(scArgs, args...), nil
+}
+
+// the installed contract id is the install txid
+func (m G45Mint) Token(scId string) Token {
+	token := Token{
+		SCID:           scId,
+		Name:           m.Name,
+		StandardType:   m.SCType,
+		ImageUrl:       sql.NullString{String: m.Image, Valid: m.Image != ""},
+		AddedTimestamp: sql.NullInt64{Int64: time.Now().Unix(), Valid: true},
+	}
+
+	metadata, err := m.Metadata()
+	if err == nil {
+		token.Metadata = sql.NullString{String: metadata, Valid: true}
+	}
+
+	switch m.SCType {
+	case sc.G45_NFT_TYPE:
+		token.MaxSupply = sql.NullInt64{Int64: 1, Valid: true}
+	case sc.G45_AT_TYPE, sc.G45_FAT_TYPE:
+		token.Decimals = int64(m.Decimals)
+		token.MaxSupply = sql.NullInt64{Int64: int64(m.MaxSupply), Valid: true}
+		token.Symbol = sql.NullString{String: m.Symbol, Valid: m.Symbol != ""}
+	}
+
+	return token
+}
+
+// private templates run InitializePrivate and public ones Initialize
+func g45InitializeFunction(code string) (dvm.Function, error) {
+	function, err := SCInstallFunction(code)
+	if err != nil {
+		return dvm.Function{}, err
+	}
+
+	if function == nil {
+		return dvm.Function{}, fmt.Errorf("the template has no initialize function")
+	}
+
+	return *function, nil
+}
+
+// values are matched by param name - the dvm fills "value" with the Dero sent
+func scFunctionArgs(function dvm.Function, values map[string]interface{}) (rpc.Arguments, error) {
+	var args rpc.Arguments
+	for _, param := range function.Params {
+		if param.Name == "value" && param.Type == dvm.Uint64 {
+			continue
+		}
+
+		value, ok := values[param.Name]
+		if !ok {
+			return nil, fmt.Errorf("missing value for [%s]", param.Name)
+		}
+
+		switch param.Type {
+		case dvm.Uint64:
+			switch v := value.(type) {
+			case uint64:
+				args = append(args, rpc.Argument{Name: param.Name, DataType: rpc.DataUint64, Value: v})
+			case string:
+				number, err := strconv.ParseUint(strings.TrimSpace(v), 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("[%s] must be a number", param.Name)
+				}
+
+				args = append(args, rpc.Argument{Name: param.Name, DataType: rpc.DataUint64, Value: number})
+			default:
+				return nil, fmt.Errorf("[%s] must be a number", param.Name)
+			}
+		case dvm.String:
+			args = append(args, rpc.Argument{Name: param.Name, DataType: rpc.DataString, Value: fmt.Sprint(value)})
+		default:
+			return nil, fmt.Errorf("unsupported type for [%s]", param.Name)
+		}
+	}
+
+	return args, nil
+}
+
+// the collection is loaded without cache since the owner and the assets can change
+func GetG45Collection(scId string) (*g45_sc.G45_C, dvm.SmartContract, error) {
+	var result rpc.GetSC_Result
+	err := RPC_Client.RPC.CallResult(context.Background(), "DERO.GetSC", rpc.GetSC_Params{
+		SCID:      scId,
+		Code:      true,
+		Variables: true,
+	}, &result)
+	if err != nil {
+		return nil, dvm.SmartContract{}, err
+	}
+
+	if sc.CheckType(result.Code) != sc.G45_C_TYPE {
+		return nil, dvm.SmartContract{}, fmt.Errorf("[%s] is not a G45 collection", scId)
+	}
+
+	collection := &g45_sc.G45_C{}
+	err = collection.Parse(scId, result.VariableStringKeys)
+	if err != nil {
+		return nil, dvm.SmartContract{}, err
+	}
+
+	contract, _, err := dvm.ParseSmartContract(result.Code)
+	if err != nil {
+		return nil, dvm.SmartContract{}, err
+	}
+
+	return collection, contract, nil
+}
+
+// sc call adding the minted asset to a collection owned by the wallet
+func (w *Wallet) G45RegisterAssetArgs(collectionId string, assetId string) (rpc.Arguments, error) {
+	collection, contract, err := GetG45Collection(collectionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if collection.Owner != w.Memory.GetAddress().String() {
+		return nil, fmt.Errorf("you are not the owner of this collection")
+	}
+
+	if collection.FrozenAssets {
+		return nil, fmt.Errorf("the collection assets are frozen")
+	}
+
+	_, exists := collection.Assets[assetId]
+	if exists {
+		return nil, fmt.Errorf("the asset is already in the collection")
+	}
+
+	function, ok := contract.Functions[G45_C_SET_ASSETS_ENTRYPOINT]
+	if !ok {
+		return nil, fmt.Errorf("the collection has no %s function", G45_C_SET_ASSETS_ENTRYPOINT)
+	}
+
+	// the asset gets the next index of the collection
+	index := collection.AssetCount
+	assets, err := json.Marshal(map[string]uint64{assetId: index})
+	if err != nil {
+		return nil, err
+	}
+
+	args, err := scFunctionArgs(function, map[string]interface{}{
+		"assets": string(assets),
+		"asset":  assetId,
+		"index":  index,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return append(scCallArgs(collectionId, G45_C_SET_ASSETS_ENTRYPOINT), args...), nil
+}
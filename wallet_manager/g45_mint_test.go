@@ -0,0 +1,180 @@
+package wallet_manager
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/deroproject/derohe/rpc"
+	"github.com/secretsystems/secret-wallet/mock_daemon"
+	"github.com/secretsystems/secret-wallet/sc"
+	"github.com/secretsystems/secret-wallet/sc/g45_sc"
+)
+
+func TestG45MintInstallArgs(t *testing.T) {
+	code := useTestCode(t, &g45_sc.G45_AT_PRIVATE_SHA256, `Function InitializePrivate(collection String, metadataFormat String, metadata String, maxSupply Uint64, decimals Uint64, freezeMint Uint64) Uint64
+10 RETURN 0
+End Function`)
+
+	mint := G45Mint{
+		SCType:    sc.G45_AT_TYPE,
+		Code:      code,
+		Name:      "Asset",
+		Symbol:    "AST",
+		Image:     "https://img",
+		Decimals:  2,
+		MaxSupply: 100000,
+	}
+
+	params, err := mint.ExtraParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(params) != 1 || params[0].Name != "freezeMint" {
+		t.Fatalf("unexpected extra params %+v", params)
+	}
+
+	_, err = mint.InstallArgs()
+	if err == nil {
+		t.Fatal("expected a missing value error")
+	}
+
+	mint.Extra = map[string]string{"freezeMint": "1"}
+	scArgs, err := mint.InstallArgs()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if scArgs.Value(rpc.SCACTION, rpc.DataUint64) != uint64(rpc.SC_INSTALL) ||
+		scArgs.Value(rpc.SCCODE, rpc.DataString) != code ||
+		scArgs.Value("maxSupply", rpc.DataUint64) != uint64(100000) ||
+		scArgs.Value("freezeMint", rpc.DataUint64) != uint64(1) {
+		t.Fatalf("unexpected sc args %+v", scArgs)
+	}
+
+	metadata := g45_sc.TokenMetadata{}
+	err = metadata.Parse(scArgs.Value("metadata", rpc.DataString).(string))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if metadata.Name != "Asset" || metadata.Symbol != "AST" || metadata.Image != "https://img" {
+		t.Fatalf("unexpected metadata %+v", metadata)
+	}
+
+	token := mint.Token(strings.Repeat("a1", 32))
+	if token.StandardType != sc.G45_AT_TYPE || token.Decimals != 2 || token.MaxSupply.Int64 != 100000 || token.Symbol.String != "AST" {
+		t.Fatalf("unexpected token %+v", token)
+	}
+
+	mint.Extra = map[string]string{"freezeMint": "yes"}
+	_, err = mint.InstallArgs()
+	if err == nil {
+		t.Fatal("expected a number error")
+	}
+
+	// only the standard code is accepted
+	mint.Code = code + "\n"
+	_, err = mint.InstallArgs()
+	if err == nil {
+		t.Fatal("expected a template error")
+	}
+
+	mint.Code = code
+	mint.MaxSupply = 0
+	_, err = mint.InstallArgs()
+	if err == nil {
+		t.Fatal("expected a max supply error")
+	}
+}
+
+// public templates are initialized by Initialize
+func TestG45MintPublicInstallArgs(t *testing.T) {
+	code := useTestCode(t, &g45_sc.G45_NFT_PUBLIC_SHA256, `Function Initialize(collection String, metadataFormat String, metadata String) Uint64
+10 RETURN 0
+End Function`)
+
+	mint := G45Mint{
+		SCType: sc.G45_NFT_TYPE,
+		Code:   code,
+		Name:   "Public NFT",
+		Image:  "https://img",
+	}
+
+	params, err := mint.ExtraParams()
+	if err != nil || len(params) != 0 {
+		t.Fatalf("unexpected extra params %+v %v", params, err)
+	}
+
+	scArgs, err := mint.InstallArgs()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if scArgs.Value(rpc.SCCODE, rpc.DataString) != code ||
+		scArgs.Value("metadataFormat", rpc.DataString) != G45_METADATA_FORMAT {
+		t.Fatalf("unexpected sc args %+v", scArgs)
+	}
+}
+
+func TestG45RegisterAssetArgs(t *testing.T) {
+	wallet := openTestWallet(t, 0)
+	addr := wallet.Memory.GetAddress().String()
+
+	code := useTestCode(t, &g45_sc.G45_C_SHA256, `Function InitializePrivate(metadataFormat String, metadata String) Uint64
+10 RETURN 0
+End Function
+
+Function SetAssets(assets String) Uint64
+10 RETURN 0
+End Function`)
+
+	existing := strings.Repeat("c2", 32)
+	assets, _ := json.Marshal(map[string]uint64{existing: 0})
+
+	collectionId := strings.Repeat("c1", 32)
+	variables := map[string]interface{}{
+		"frozenAssets":   float64(0),
+		"frozenMetadata": float64(0),
+		"metadataFormat": encodeTestString("json"),
+		"metadata":       encodeTestString(`{"name":"Collection"}`),
+		"timestamp":      float64(1690000000),
+		"owner":          encodeTestAddress(t, addr),
+		"originalOwner":  encodeTestAddress(t, addr),
+		"assets_0":       hex.EncodeToString(assets),
+	}
+	daemon.SetSC(collectionId, rpc.GetSC_Result{Code: code, VariableStringKeys: variables})
+
+	assetId := strings.Repeat("c3", 32)
+	scArgs, err := wallet.G45RegisterAssetArgs(collectionId, assetId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if scArgs.Value("entrypoint", rpc.DataString) != G45_C_SET_ASSETS_ENTRYPOINT ||
+		scArgs.Value("assets", rpc.DataString) != `{"`+assetId+`":1}` {
+		t.Fatalf("unexpected sc args %+v", scArgs)
+	}
+
+	_, err = wallet.G45RegisterAssetArgs(collectionId, existing)
+	if err == nil {
+		t.Fatal("expected an existing asset error")
+	}
+
+	variables["frozenAssets"] = float64(1)
+	daemon.SetSC(collectionId, rpc.GetSC_Result{Code: code, VariableStringKeys: variables})
+	_, err = wallet.G45RegisterAssetArgs(collectionId, assetId)
+	if err == nil {
+		t.Fatal("expected a frozen assets error")
+	}
+
+	variables["frozenAssets"] = float64(0)
+	variables["owner"] = encodeTestAddress(t, mock_daemon.RandomAddress())
+	daemon.SetSC(collectionId, rpc.GetSC_Result{Code: code, VariableStringKeys: variables})
+	_, err = wallet.G45RegisterAssetArgs(collectionId, assetId)
+	if err == nil {
+		t.Fatal("expected an owner error")
+	}
+}
@@ -0,0 +1,265 @@
+package wallet_manager
+
+import (
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/deroproject/derohe/rpc"
+	"github.com/deroproject/derohe/transaction"
+)
+
+type Invoice struct {
+	ID            int64
+	Addr          string
+	DstPort       uint64
+	Amount        uint64
+	Comment       string
+	Expiry        sql.NullInt64
+	Timestamp     int64
+	PaidTxId      sql.NullString
+	PaidTimestamp sql.NullInt64
+}
+
+func (i Invoice) IsPaid() bool {
+	return i.PaidTxId.Valid
+}
+
+func (i Invoice) IsExpired() bool {
+	return i.Expiry.Valid && time.Now().Unix() > i.Expiry.Int64
+}
+
+func initDatabaseInvoices(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS invoices (
+			id INTEGER PRIMARY KEY,
+			addr VARCHAR,
+			dst_port VARCHAR UNIQUE,
+			amount BIGINT,
+			comment VARCHAR,
+			expiry BIGINT,
+			timestamp BIGINT,
+			paid_tx_id VARCHAR,
+			paid_timestamp BIGINT
+		);
+	`)
+	return err
+}
+
+func rowsScanInvoices(rows *sql.Rows) ([]Invoice, error) {
+	defer rows.Close()
+
+	var invoices []Invoice
+	for rows.Next() {
+		var invoice Invoice
+		var dstPort string
+		err := rows.Scan(
+			&invoice.ID,
+			&invoice.Addr,
+			&dstPort,
+			&invoice.Amount,
+			&invoice.Comment,
+			&invoice.Expiry,
+			&invoice.Timestamp,
+			&invoice.PaidTxId,
+			&invoice.PaidTimestamp,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		// stored as text because sqlite integers are signed
+		_, err = fmt.Sscan(dstPort, &invoice.DstPort)
+		if err != nil {
+			return nil, err
+		}
+
+		invoices = append(invoices, invoice)
+	}
+
+	err := rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return invoices, nil
+}
+
+type CreateInvoiceParams struct {
+	Amount  uint64
+	Comment string
+	Expiry  time.Duration
+	DstPort uint64 // a random port is used if empty
+}
+
+// creates an integrated address with the payment request arguments and saves it as an invoice
+func (w *Wallet) CreateInvoice(params CreateInvoiceParams) (*Invoice, error) {
+	dstPort := params.DstPort
+	if dstPort == 0 {
+		dstPort = rand.Uint64()
+	}
+
+	arguments := rpc.Arguments{
+		{Name: rpc.RPC_DESTINATION_PORT, DataType: rpc.DataUint64, Value: dstPort},
+	}
+
+	if params.Amount > 0 {
+		arguments = append(arguments, rpc.Argument{Name: rpc.RPC_VALUE_TRANSFER, DataType: rpc.DataUint64, Value: params.Amount})
+	}
+
+	if params.Comment != "" {
+		arguments = append(arguments, rpc.Argument{Name: rpc.RPC_COMMENT, DataType: rpc.DataString, Value: params.Comment})
+	}
+
+	now := time.Now()
+	var expiry sql.NullInt64
+	if params.Expiry > 0 {
+		expireTime := now.Add(params.Expiry).UTC()
+		expiry = sql.NullInt64{Int64: expireTime.Unix(), Valid: true}
+		arguments = append(arguments, rpc.Argument{Name: rpc.RPC_EXPIRY, DataType: rpc.DataTime, Value: expireTime})
+	}
+
+	err := arguments.Validate_Arguments()
+	if err != nil {
+		return nil, err
+	}
+
+	// the sender has to fit the arguments in the tx payload
+	_, err = arguments.CheckPack(transaction.PAYLOAD0_LIMIT)
+	if err != nil {
+		return nil, err
+	}
+
+	addr := w.Memory.GetAddress()
+	addr.Arguments = arguments
+
+	invoice := &Invoice{
+		Addr:      addr.String(),
+		DstPort:   dstPort,
+		Amount:    params.Amount,
+		Comment:   params.Comment,
+		Expiry:    expiry,
+		Timestamp: now.Unix(),
+	}
+
+	result, err := w.DB.Exec(`
+		INSERT INTO invoices (addr,dst_port,amount,comment,expiry,timestamp)
+		VALUES (?,?,?,?,?,?);
+	`, invoice.Addr, fmt.Sprint(invoice.DstPort), invoice.Amount, invoice.Comment, invoice.Expiry, invoice.Timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	invoice.ID, err = result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return invoice, nil
+}
+
+type GetInvoicesParams struct {
+	Paid sql.NullBool
+}
+
+func (w *Wallet) GetInvoices(params GetInvoicesParams) ([]Invoice, error) {
+	query := sq.Select("*").From("invoices").OrderBy("timestamp DESC")
+
+	if params.Paid.Valid {
+		if params.Paid.Bool {
+			query = query.Where(sq.NotEq{"paid_tx_id": nil})
+		} else {
+			query = query.Where(sq.Eq{"paid_tx_id": nil})
+		}
+	}
+
+	rows, err := query.RunWith(w.DB).Query()
+	if err != nil {
+		return nil, err
+	}
+
+	return rowsScanInvoices(rows)
+}
+
+func (w *Wallet) DelInvoice(id int64) error {
+	_, err := w.DB.Exec(`
+		DELETE FROM invoices
+		WHERE id = ?;
+	`, id)
+	return err
+}
+
+const INVOICE_CHECK_INTERVAL = 15 * time.Second
+
+// checks the unpaid invoices until the wallet is closed - onCheck is called from the checking goroutine
+// when invoices were paid or the check failed
+func (w *Wallet) StartInvoiceChecks(onCheck func(paidInvoices []Invoice, err error)) {
+	go func() {
+		for {
+			select {
+			case <-w.Memory.Quit:
+				return
+			case <-time.After(INVOICE_CHECK_INTERVAL):
+			}
+
+			paidInvoices, err := w.UpdatePaidInvoices()
+			if (len(paidInvoices) > 0 || err != nil) && onCheck != nil {
+				onCheck(paidInvoices, err)
+			}
+		}
+	}()
+}
+
+// marks unpaid invoices as paid if an incoming entry matches the destination port and amount
+// returns the invoices that were paid
+func (w *Wallet) UpdatePaidInvoices() ([]Invoice, error) {
+	invoices, err := w.GetInvoices(GetInvoicesParams{Paid: sql.NullBool{Bool: false, Valid: true}})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(invoices) == 0 {
+		return nil, nil
+	}
+
+	scId := crypto.ZEROHASH
+	var paidInvoices []Invoice
+	for _, invoice := range invoices {
+		// the dst_port index finds the payments - paying more than requested is fine
+		// the random port is the invoice so the block time is not compared to the local clock
+		dstPort := invoice.DstPort
+		entries, err := w.GetEntries(&scId, GetEntriesParams{
+			In:                       sql.NullBool{Bool: true, Valid: true},
+			DstPort:                  &dstPort,
+			AmountGreaterOrEqualThan: sql.NullInt64{Int64: int64(invoice.Amount), Valid: true},
+			Limit:                    sql.NullInt64{Int64: 1, Valid: true},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if len(entries) == 0 {
+			continue
+		}
+
+		entry := entries[0]
+		invoice.PaidTxId = sql.NullString{String: entry.TXID, Valid: true}
+		invoice.PaidTimestamp = sql.NullInt64{Int64: entry.Time.Unix(), Valid: true}
+
+		_, err = w.DB.Exec(`
+			UPDATE invoices
+			SET paid_tx_id = ?, paid_timestamp = ?
+			WHERE id = ?;
+		`, invoice.PaidTxId, invoice.PaidTimestamp, invoice.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		paidInvoices = append(paidInvoices, invoice)
+	}
+
+	return paidInvoices, nil
+}
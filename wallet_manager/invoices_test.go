@@ -0,0 +1,150 @@
+package wallet_manager
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/deroproject/derohe/rpc"
+)
+
+func addTestEntry(wallet *Wallet, entry rpc.Entry) {
+	account := wallet.Memory.GetAccount()
+	account.EntriesNative[crypto.ZEROHASH] = append(account.EntriesNative[crypto.ZEROHASH], entry)
+}
+
+func TestCreateInvoice(t *testing.T) {
+	wallet := openTestWallet(t, 0)
+
+	invoice, err := wallet.CreateInvoice(CreateInvoiceParams{
+		Amount:  150000,
+		Comment: "order #42",
+		Expiry:  time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addr, err := rpc.NewAddress(invoice.Addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !addr.IsIntegratedAddress() || addr.BaseAddress().String() != wallet.Info.Addr {
+		t.Fatalf("invalid integrated address %s", invoice.Addr)
+	}
+
+	// the same arguments the send form reads
+	args := addr.Arguments
+	if args.Value(rpc.RPC_DESTINATION_PORT, rpc.DataUint64).(uint64) != invoice.DstPort ||
+		args.Value(rpc.RPC_VALUE_TRANSFER, rpc.DataUint64).(uint64) != 150000 ||
+		args.Value(rpc.RPC_COMMENT, rpc.DataString).(string) != "order #42" ||
+		args.Value(rpc.RPC_EXPIRY, rpc.DataTime).(time.Time).Unix() != invoice.Expiry.Int64 {
+		t.Fatalf("unexpected arguments %s", args)
+	}
+
+	invoices, err := wallet.GetInvoices(GetInvoicesParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(invoices) != 1 || invoices[0] != *invoice {
+		t.Fatalf("stored invoice %+v does not match %+v", invoices, invoice)
+	}
+
+	err = wallet.DelInvoice(invoice.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	invoices, err = wallet.GetInvoices(GetInvoicesParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(invoices) != 0 {
+		t.Fatal("invoice was not deleted")
+	}
+}
+
+func TestUpdatePaidInvoices(t *testing.T) {
+	wallet := openTestWallet(t, 0)
+
+	invoice, err := wallet.CreateInvoice(CreateInvoiceParams{Amount: 1000, DstPort: 1337})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, err := wallet.CreateInvoice(CreateInvoiceParams{Amount: 2000})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paymentTime := time.Unix(invoice.Timestamp+10, 0)
+
+	// wrong amount, outgoing and wrong port entries don't pay the invoice
+	addTestEntry(wallet, rpc.Entry{TXID: "a", Incoming: true, DestinationPort: 1337, Amount: 999, Time: paymentTime})
+	addTestEntry(wallet, rpc.Entry{TXID: "b", Incoming: false, DestinationPort: 1337, Amount: 1000, Time: paymentTime})
+	addTestEntry(wallet, rpc.Entry{TXID: "c", Incoming: true, DestinationPort: 1338, Amount: 1000, Time: paymentTime})
+
+	paid, err := wallet.UpdatePaidInvoices()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(paid) != 0 {
+		t.Fatalf("%d invoices paid instead of 0", len(paid))
+	}
+
+	addTestEntry(wallet, rpc.Entry{TXID: "d", Incoming: true, DestinationPort: 1337, Amount: 1000, Time: paymentTime})
+
+	paid, err = wallet.UpdatePaidInvoices()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(paid) != 1 || paid[0].ID != invoice.ID || paid[0].PaidTxId.String != "d" {
+		t.Fatalf("unexpected paid invoices %+v", paid)
+	}
+
+	invoices, err := wallet.GetInvoices(GetInvoicesParams{Paid: sql.NullBool{Bool: false, Valid: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(invoices) != 1 || invoices[0].ID != other.ID {
+		t.Fatalf("unexpected unpaid invoices %+v", invoices)
+	}
+
+	// already paid invoices are not checked again
+	paid, err = wallet.UpdatePaidInvoices()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(paid) != 0 {
+		t.Fatalf("%d invoices paid instead of 0", len(paid))
+	}
+}
+
+// the local clock can be ahead of the block time of the payment
+func TestUpdatePaidInvoicesClockAhead(t *testing.T) {
+	wallet := openTestWallet(t, 0)
+
+	invoice, err := wallet.CreateInvoice(CreateInvoiceParams{Amount: 1000, DstPort: 4242})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addTestEntry(wallet, rpc.Entry{TXID: "e", Incoming: true, DestinationPort: 4242, Amount: 1000, Time: time.Unix(invoice.Timestamp-3600, 0)})
+
+	paid, err := wallet.UpdatePaidInvoices()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(paid) != 1 || paid[0].ID != invoice.ID || paid[0].PaidTxId.String != "e" {
+		t.Fatalf("unexpected paid invoices %+v", paid)
+	}
+}
@@ -0,0 +1,120 @@
+package wallet_manager
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/deroproject/derohe/dvm"
+	"github.com/deroproject/derohe/rpc"
+)
+
+// contracts installed from this wallet - the id is the install txid
+type MyContract struct {
+	SCID      string
+	Name      string
+	Timestamp int64
+}
+
+func initDatabaseMyContracts(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS my_contracts (
+			sc_id VARCHAR PRIMARY KEY,
+			name VARCHAR,
+			timestamp BIGINT
+		);
+	`)
+	return err
+}
+
+func (w *Wallet) GetMyContracts() ([]MyContract, error) {
+	query := sq.Select("sc_id", "name", "timestamp").From("my_contracts").
+		OrderBy("timestamp DESC")
+
+	rows, err := query.RunWith(w.DB).Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var contracts []MyContract
+	for rows.Next() {
+		var contract MyContract
+		err = rows.Scan(&contract.SCID, &contract.Name, &contract.Timestamp)
+		if err != nil {
+			return nil, err
+		}
+
+		contracts = append(contracts, contract)
+	}
+
+	return contracts, nil
+}
+
+func (w *Wallet) StoreMyContract(contract MyContract) error {
+	_, err := w.DB.Exec(`
+		INSERT INTO my_contracts (sc_id,name,timestamp)
+		VALUES (?,?,?)
+		ON CONFLICT (sc_id) DO UPDATE SET
+		name = excluded.name;
+	`, contract.SCID, contract.Name, contract.Timestamp)
+	return err
+}
+
+func (w *Wallet) DelMyContract(scId string) error {
+	_, err := w.DB.Exec(`
+		DELETE FROM my_contracts
+		WHERE sc_id = ?;
+	`, scId)
+	return err
+}
+
+// the dvm runs InitializePrivate on install or Initialize if there is none
+// a contract without any of them installs without running code
+func SCInstallFunction(code string) (*dvm.Function, error) {
+	contract, _, err := dvm.ParseSmartContract(code)
+	if err != nil {
+		return nil, fmt.Errorf("invalid smart contract: %s", err.Error())
+	}
+
+	if len(contract.Functions) == 0 {
+		return nil, fmt.Errorf("the contract has no functions")
+	}
+
+	for _, name := range []string{"InitializePrivate", "Initialize"} {
+		function, ok := contract.Functions[name]
+		if ok {
+			return &function, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func SCInstallArgs(code string, values map[string]interface{}) (rpc.Arguments, error) {
+	function, err := SCInstallFunction(code)
+	if err != nil {
+		return nil, err
+	}
+
+	scArgs := rpc.Arguments{
+		{Name: rpc.SCACTION, DataType: rpc.DataUint64, Value: uint64(rpc.SC_INSTALL)},
+		{Name: rpc.SCCODE, DataType: rpc.DataString, Value: code},
+	}
+
+	if function == nil {
+		return scArgs, nil
+	}
+
+	args, err := scFunctionArgs(*function, values)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(scArgs, args...), nil
+}
+
+func NewMyContract(scId string, name string) MyContract {
+	return MyContract{SCID: scId, Name: name, Timestamp: time.Now().UnixMilli()}
+}
@@ -0,0 +1,92 @@
+package wallet_manager
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/deroproject/derohe/rpc"
+)
+
+func TestSCInstallArgs(t *testing.T) {
+	_, err := SCInstallArgs("not a contract", nil)
+	if err == nil {
+		t.Fatal("expected a parse error")
+	}
+
+	code := `Function InitializePrivate(name String, supply Uint64, value Uint64) Uint64
+10 RETURN 0
+End Function`
+
+	_, err = SCInstallArgs(code, map[string]interface{}{"name": "test"})
+	if err == nil {
+		t.Fatal("expected a missing value error")
+	}
+
+	scArgs, err := SCInstallArgs(code, map[string]interface{}{"name": "test", "supply": "100"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if scArgs.Value(rpc.SCACTION, rpc.DataUint64) != uint64(rpc.SC_INSTALL) ||
+		scArgs.Value(rpc.SCCODE, rpc.DataString) != code ||
+		scArgs.Value("name", rpc.DataString) != "test" ||
+		scArgs.Value("supply", rpc.DataUint64) != uint64(100) ||
+		scArgs.Has("value", rpc.DataUint64) {
+		t.Fatalf("unexpected sc args %+v", scArgs)
+	}
+
+	// no initialize function
+	scArgs, err = SCInstallArgs(testConsoleCode[strings.Index(testConsoleCode, "Function Deposit"):], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(scArgs) != 2 {
+		t.Fatalf("unexpected sc args %+v", scArgs)
+	}
+}
+
+func TestMyContracts(t *testing.T) {
+	wallet := openTestWallet(t, 0)
+
+	first := strings.Repeat("a1", 32)
+	second := strings.Repeat("a2", 32)
+
+	err := wallet.StoreMyContract(MyContract{SCID: first, Name: "first", Timestamp: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = wallet.StoreMyContract(MyContract{SCID: second, Name: "second", Timestamp: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = wallet.StoreMyContract(MyContract{SCID: first, Name: "renamed", Timestamp: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contracts, err := wallet.GetMyContracts()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(contracts) != 2 || contracts[0].SCID != second || contracts[1].Name != "renamed" || contracts[1].Timestamp != 1 {
+		t.Fatalf("unexpected contracts %+v", contracts)
+	}
+
+	err = wallet.DelMyContract(second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contracts, err = wallet.GetMyContracts()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(contracts) != 1 || contracts[0].SCID != first {
+		t.Fatalf("unexpected contracts %+v", contracts)
+	}
+}
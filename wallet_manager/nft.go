@@ -0,0 +1,188 @@
+package wallet_manager
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/secretsystems/secret-wallet/caching"
+	"github.com/secretsystems/secret-wallet/multi_fetch"
+	"github.com/secretsystems/secret-wallet/sc"
+	"github.com/secretsystems/secret-wallet/sc/g45_sc"
+)
+
+type NFTMediaType string
+
+var (
+	NFTMediaImage NFTMediaType = "image"
+	NFTMediaVideo NFTMediaType = "video"
+	NFTMediaAudio NFTMediaType = "audio"
+)
+
+type NFTMedia struct {
+	Type NFTMediaType
+	Name string
+	Url  string
+}
+
+// file name used when saving the media to disk
+func (m NFTMedia) FileName() string {
+	name := path.Base(m.Url)
+	if name == "." || name == "/" || strings.Contains(name, ":") {
+		return fmt.Sprintf("%s_%s", m.Type, m.Name)
+	}
+
+	return name
+}
+
+type NFTAttribute struct {
+	Key   string
+	Value string
+}
+
+// the main media comes first then the named ones sorted by name - duplicated urls are skipped
+func NFTMediaList(metadata g45_sc.NFTMetadata) []NFTMedia {
+	var list []NFTMedia
+	urls := make(map[string]bool)
+
+	add := func(mediaType NFTMediaType, name string, url string) {
+		url = strings.TrimSpace(url)
+		if url == "" || urls[url] {
+			return
+		}
+
+		urls[url] = true
+		list = append(list, NFTMedia{Type: mediaType, Name: name, Url: url})
+	}
+
+	addMap := func(mediaType NFTMediaType, values map[string]interface{}) {
+		var names []string
+		for name := range values {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			url, ok := values[name].(string)
+			if ok {
+				add(mediaType, name, url)
+			}
+		}
+	}
+
+	add(NFTMediaImage, "image", metadata.Image)
+	addMap(NFTMediaImage, metadata.Images)
+	add(NFTMediaVideo, "video", metadata.Video)
+	addMap(NFTMediaVideo, metadata.Videos)
+	add(NFTMediaAudio, "audio", metadata.Audio)
+	addMap(NFTMediaAudio, metadata.Audios)
+	return list
+}
+
+func NFTAttributes(metadata g45_sc.NFTMetadata) []NFTAttribute {
+	var attributes []NFTAttribute
+	for key, value := range metadata.Attributes {
+		attributes = append(attributes, NFTAttribute{Key: key, Value: fmt.Sprint(value)})
+	}
+
+	sort.Slice(attributes, func(i, j int) bool {
+		return attributes[i].Key < attributes[j].Key
+	})
+
+	return attributes
+}
+
+func (token *Token) NFTMetadata() (metadata g45_sc.NFTMetadata, err error) {
+	if token.StandardType != sc.G45_NFT_TYPE || !token.Metadata.Valid {
+		err = fmt.Errorf("not a G45 NFT")
+		return
+	}
+
+	err = metadata.Parse(token.Metadata.String)
+	return
+}
+
+// the collection of a G45 asset is read from the contract since it's not stored in the tokens table
+func (token *Token) G45Collection() (string, error) {
+	result, _, err := GetSC(token.SCID)
+	if err != nil {
+		return "", err
+	}
+
+	switch token.StandardType {
+	case sc.G45_NFT_TYPE:
+		nft := g45_sc.G45_NFT{}
+		err = nft.Parse(token.SCID, result.VariableStringKeys)
+		return nft.Collection, err
+	case sc.G45_AT_TYPE:
+		at := g45_sc.G45_AT{}
+		err = at.Parse(token.SCID, result.VariableStringKeys)
+		return at.Collection, err
+	case sc.G45_FAT_TYPE:
+		fat := g45_sc.G45_FAT{}
+		err = fat.Parse(token.SCID, result.VariableStringKeys)
+		return fat.Collection, err
+	}
+
+	return "", fmt.Errorf("not a G45 asset")
+}
+
+func GetG45CollectionMetadata(scId string) (metadata g45_sc.CollectionMetadata, err error) {
+	result, _, err := GetSC(scId)
+	if err != nil {
+		return
+	}
+
+	if sc.CheckType(result.Code) != sc.G45_C_TYPE {
+		err = fmt.Errorf("[%s] is not a G45 collection", scId)
+		return
+	}
+
+	collection := g45_sc.G45_C{}
+	err = collection.Parse(scId, result.VariableStringKeys)
+	if err != nil {
+		return
+	}
+
+	err = metadata.Parse(collection.Metadata)
+	return
+}
+
+// only images are cached - audio and video are opened externally or saved to disk
+func (token *Token) GetMediaImage(url string) (data []byte, err error) {
+	hash := sha256.Sum256([]byte(url))
+	relCachePath := filepath.Join("tokens", token.SCID)
+	cacheFileName := fmt.Sprintf("media_%s", hex.EncodeToString(hash[:8]))
+
+	exists, err := caching.Get(caching.IMAGES, relCachePath, cacheFileName, &data)
+	if err != nil || exists {
+		return
+	}
+
+	data, err = FetchMedia(url)
+	if err != nil {
+		return
+	}
+
+	err = caching.Store(caching.IMAGES, relCachePath, cacheFileName, data)
+	return
+}
+
+func FetchMedia(url string) ([]byte, error) {
+	res, err := multi_fetch.Fetch(url)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		return nil, fmt.Errorf("unable to fetch media: %s", res.Status)
+	}
+
+	return io.ReadAll(res.Body)
+}
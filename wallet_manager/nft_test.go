@@ -0,0 +1,45 @@
+package wallet_manager
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/secretsystems/secret-wallet/sc/g45_sc"
+)
+
+func TestNFTMediaList(t *testing.T) {
+	metadata := g45_sc.NFTMetadata{}
+	err := metadata.Parse(`{
+		"name": "Dero Seal #1",
+		"image": "ipfs://seal/front.png",
+		"images": {"side": "https://img/side.png", "back": "https://img/back.png", "front": "ipfs://seal/front.png"},
+		"video": "https://video/seal.mp4",
+		"audios": {"theme": "https://audio/theme.mp3", "broken": 1},
+		"attributes": {"rarity": "rare", "level": 3}
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list := NFTMediaList(metadata)
+	expected := []NFTMedia{
+		{Type: NFTMediaImage, Name: "image", Url: "ipfs://seal/front.png"},
+		{Type: NFTMediaImage, Name: "back", Url: "https://img/back.png"},
+		{Type: NFTMediaImage, Name: "side", Url: "https://img/side.png"},
+		{Type: NFTMediaVideo, Name: "video", Url: "https://video/seal.mp4"},
+		{Type: NFTMediaAudio, Name: "theme", Url: "https://audio/theme.mp3"},
+	}
+
+	if fmt.Sprint(list) != fmt.Sprint(expected) {
+		t.Fatalf("unexpected media %v", list)
+	}
+
+	if list[0].FileName() != "front.png" || list[4].FileName() != "theme.mp3" {
+		t.Fatalf("unexpected file names %s %s", list[0].FileName(), list[4].FileName())
+	}
+
+	attributes := NFTAttributes(metadata)
+	if fmt.Sprint(attributes) != fmt.Sprint([]NFTAttribute{{"level", "3"}, {"rarity", "rare"}}) {
+		t.Fatalf("unexpected attributes %v", attributes)
+	}
+}
@@ -0,0 +1,496 @@
+package wallet_manager
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deroproject/derohe/cryptography/bn256"
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/deroproject/derohe/globals"
+	"github.com/deroproject/derohe/rpc"
+	"github.com/deroproject/derohe/transaction"
+	"github.com/deroproject/graviton"
+	"github.com/secretsystems/secret-wallet/utils"
+)
+
+// Offline (air-gapped) signing is split in three steps:
+// - an online wallet (usually watch-only) prepares an unsigned tx with everything fetched from the node
+// - the offline wallet holding the secret key signs it without any network access
+// - the signed tx hex is imported back online and broadcasted
+
+const UNSIGNED_TX_VERSION = 1
+
+type UnsignedTx struct {
+	Version   int            `json:"version"`
+	Mainnet   bool           `json:"mainnet"`
+	Signer    string         `json:"signer"`
+	Transfers []rpc.Transfer `json:"transfers"`
+	SCArgs    rpc.Arguments  `json:"sc_args"`
+	// compressed public keys and encrypted balances in hex - the sender is first and the receiver second
+	Rings         [][]string  `json:"rings"`
+	RingsBalances [][]string  `json:"rings_balances"`
+	MaxBits       int         `json:"max_bits"`
+	BlockHash     crypto.Hash `json:"block_hash"`
+	Height        uint64      `json:"height"`
+	TreeHash      string      `json:"tree_hash"`
+	GasFees       uint64      `json:"gas_fees"`
+	Timestamp     int64       `json:"timestamp"`
+}
+
+// online step - does not need the secret key and works for watch-only wallets
+func (w *Wallet) PrepareTransaction(transfers []rpc.Transfer, ringsize uint64, scArgs rpc.Arguments) (unsignedTx UnsignedTx, err error) {
+	var gasFees uint64
+	if len(scArgs) > 0 {
+		// smart contract call to test if it can succeed and return gas fees for the dry run
+		gasFees, err = w.GetGasEstimate(transfers, ringsize, scArgs)
+		if err != nil {
+			return
+		}
+	}
+
+	// need at least one Dero transfers
+	hasBase := false
+	for _, t := range transfers {
+		if t.SCID.IsZero() {
+			hasBase = true
+			break
+		}
+	}
+
+	if !hasBase {
+		var randomAddr string
+		randomAddr, err = w.GetRandomAddress(crypto.ZEROHASH)
+		if err != nil {
+			return
+		}
+
+		transfers = append(transfers, rpc.Transfer{
+			SCID:        crypto.ZEROHASH,
+			Destination: randomAddr,
+			Amount:      0,
+		})
+	}
+
+	var ringMembers RingMembers
+	ringMembers, err = w.BuildRingMembers(transfers, ringsize)
+	if err != nil {
+		return
+	}
+
+	topoHeight := w.Memory.Get_TopoHeight()
+
+	var encryptedBalance rpc.GetEncryptedBalance_Result
+	encryptedBalance, err = w.Memory.GetSelfEncryptedBalanceAtTopoHeight(crypto.ZEROHASH, topoHeight)
+	if err != nil {
+		return
+	}
+
+	unsignedTx = UnsignedTx{
+		Version:   UNSIGNED_TX_VERSION,
+		Mainnet:   globals.IsMainnet(),
+		Signer:    w.Memory.GetAddress().String(),
+		Transfers: transfers,
+		SCArgs:    scArgs,
+		MaxBits:   ringMembers.MaxBits,
+		BlockHash: encryptedBalance.BlockHash,
+		Height:    uint64(encryptedBalance.Height),
+		TreeHash:  encryptedBalance.Merkle_Balance_TreeHash,
+		GasFees:   gasFees,
+		Timestamp: time.Now().Unix(),
+	}
+
+	for i, ring := range ringMembers.Rings {
+		var keys []string
+		var balances []string
+		for j, key := range ring {
+			keys = append(keys, hex.EncodeToString(key.EncodeCompressed()))
+			balances = append(balances, hex.EncodeToString(ringMembers.RingsBalances[i][j]))
+		}
+
+		unsignedTx.Rings = append(unsignedTx.Rings, keys)
+		unsignedTx.RingsBalances = append(unsignedTx.RingsBalances, balances)
+	}
+
+	return
+}
+
+func decodePublicKey(value string) (*bn256.G1, error) {
+	data, err := hex.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	key := new(bn256.G1)
+	err = key.DecodeCompressed(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// makes sure the offline wallet is the sender and the rings match the transfers
+func (w *Wallet) decodeRings(unsignedTx UnsignedTx) (rings [][]*bn256.G1, ringsBalances [][][]byte, err error) {
+	if unsignedTx.Version != UNSIGNED_TX_VERSION {
+		err = fmt.Errorf("unsupported unsigned tx version %d", unsignedTx.Version)
+		return
+	}
+
+	if unsignedTx.Mainnet != globals.IsMainnet() {
+		err = fmt.Errorf("the transaction is not from the current network")
+		return
+	}
+
+	walletAddr := w.Memory.GetAddress().String()
+	if unsignedTx.Signer != walletAddr {
+		err = fmt.Errorf("the transaction must be signed by [%s]", utils.ReduceAddr(unsignedTx.Signer))
+		return
+	}
+
+	if len(unsignedTx.Transfers) == 0 ||
+		len(unsignedTx.Rings) != len(unsignedTx.Transfers) ||
+		len(unsignedTx.RingsBalances) != len(unsignedTx.Transfers) {
+		err = fmt.Errorf("invalid ring members")
+		return
+	}
+
+	// the fees are split between the Dero transfers - PrepareTransaction always adds one
+	hasBase := false
+	for _, transfer := range unsignedTx.Transfers {
+		if transfer.SCID.IsZero() {
+			hasBase = true
+			break
+		}
+	}
+
+	if !hasBase {
+		err = fmt.Errorf("the transaction has no Dero transfer to pay the fees")
+		return
+	}
+
+	// BuildTransaction panics over 240 bits
+	if unsignedTx.MaxBits <= 0 || unsignedTx.MaxBits > 200 {
+		err = fmt.Errorf("invalid max bits %d", unsignedTx.MaxBits)
+		return
+	}
+
+	publicKey := w.Memory.GetAccount().Keys.Public.G1().EncodeCompressed()
+
+	for i, transfer := range unsignedTx.Transfers {
+		size := len(unsignedTx.Rings[i])
+		if size < 2 || size&(size-1) != 0 || size != len(unsignedTx.RingsBalances[i]) {
+			err = fmt.Errorf("invalid ring size %d", size)
+			return
+		}
+
+		var destAddr *rpc.Address
+		destAddr, err = rpc.NewAddress(transfer.Destination)
+		if err != nil {
+			return
+		}
+
+		var ring []*bn256.G1
+		var ringBalances [][]byte
+		for j := range unsignedTx.Rings[i] {
+			var key *bn256.G1
+			key, err = decodePublicKey(unsignedTx.Rings[i][j])
+			if err != nil {
+				return
+			}
+
+			var balance []byte
+			balance, err = hex.DecodeString(unsignedTx.RingsBalances[i][j])
+			if err != nil {
+				return
+			}
+
+			ring = append(ring, key)
+			ringBalances = append(ringBalances, balance)
+		}
+
+		if !bytes.Equal(ring[0].EncodeCompressed(), publicKey) {
+			err = fmt.Errorf("the wallet is not the sender of transfer %d", i+1)
+			return
+		}
+
+		if !bytes.Equal(ring[1].EncodeCompressed(), destAddr.PublicKey.EncodeCompressed()) {
+			err = fmt.Errorf("the receiver of transfer %d does not match the ring", i+1)
+			return
+		}
+
+		rings = append(rings, ring)
+		ringsBalances = append(ringsBalances, ringBalances)
+	}
+
+	return
+}
+
+// offline step - the balances are decrypted from the rings since the wallet is not synced
+func (w *Wallet) SignTransaction(unsignedTx UnsignedTx, dryRun bool) (tx *transaction.Transaction, txFees uint64, err error) {
+	if w.IsWatchOnly() {
+		err = ErrWatchOnly
+		return
+	}
+
+	rings, ringsBalances, err := w.decodeRings(unsignedTx)
+	if err != nil {
+		return
+	}
+
+	treeHashRaw, err := hex.DecodeString(unsignedTx.TreeHash)
+	if err != nil {
+		return
+	}
+
+	transfers := unsignedTx.Transfers
+
+	// get len of for Dero transfers - the fees are only applied to Dero asset statements and not other tokens
+	deroTransfers := 0
+	assetsAmount := make(map[crypto.Hash]uint64, 0)
+	senderBalances := make(map[crypto.Hash][]byte, 0)
+	for i, transfer := range transfers {
+		_, ok := assetsAmount[transfer.SCID]
+		if !ok {
+			assetsAmount[transfer.SCID] = 0
+			senderBalances[transfer.SCID] = ringsBalances[i][0]
+		}
+
+		assetsAmount[transfer.SCID] += transfer.Amount + transfer.Burn
+		if transfer.SCID.IsZero() {
+			deroTransfers++
+		}
+	}
+
+	for asset, amount := range assetsAmount {
+		encryptedBalance := new(crypto.ElGamal).Deserialize(senderBalances[asset])
+		balance := w.Memory.DecodeEncryptedBalanceNow(encryptedBalance)
+		if amount > balance {
+			if asset.IsZero() {
+				err = fmt.Errorf("you don't have enough Dero")
+			} else {
+				err = fmt.Errorf("you don't have enough asset funds of [%s]", utils.ReduceTxId(asset.String()))
+			}
+
+			return
+		}
+	}
+
+	// build a dry transaction to get transaction size and calculate fees
+	// set fees to 1 to avoid automatic fees in statement
+	// fee value is store in tx but its too small for making any adjustments
+	tx = w.Memory.BuildTransaction(
+		transfers,
+		ringsBalances,
+		rings,
+		unsignedTx.BlockHash,
+		unsignedTx.Height,
+		unsignedTx.SCArgs,
+		treeHashRaw,
+		unsignedTx.MaxBits,
+		1,
+	)
+	if tx == nil {
+		err = fmt.Errorf("can't build transaction")
+		return
+	}
+
+	txSize := uint64(len(tx.Serialize()))
+	txFees = w.CalculateTxFees(txSize)
+	totalFees := txFees + unsignedTx.GasFees
+
+	if dryRun {
+		return
+	}
+
+	// set fees in BuildTransaction applies it to all Dero transfers -_-
+	// split the fees amongst all Dero transfers
+	feesPerTransfer := uint64(math.Ceil(float64(totalFees) / float64(deroTransfers)))
+
+	tx = w.Memory.BuildTransaction(
+		transfers,
+		ringsBalances,
+		rings,
+		unsignedTx.BlockHash,
+		unsignedTx.Height,
+		unsignedTx.SCArgs,
+		treeHashRaw,
+		unsignedTx.MaxBits,
+		feesPerTransfer,
+	)
+
+	if tx == nil {
+		err = fmt.Errorf("can't build transaction")
+		return
+	}
+
+	return
+}
+
+func (u UnsignedTx) Encode() (string, error) {
+	data, err := json.Marshal(u)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+func DecodeUnsignedTx(value string) (unsignedTx UnsignedTx, err error) {
+	err = json.Unmarshal([]byte(strings.TrimSpace(value)), &unsignedTx)
+	if err != nil {
+		err = fmt.Errorf("invalid unsigned tx: %s", err.Error())
+	}
+
+	return
+}
+
+func DecodeSignedTx(value string) (tx *transaction.Transaction, err error) {
+	data, err := hex.DecodeString(strings.TrimSpace(value))
+	if err != nil {
+		return
+	}
+
+	tx = new(transaction.Transaction)
+	err = tx.Deserialize(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+// the wallet public key must be in the rings - otherwise the tx could not be tracked in outgoing txs
+// serialized statements only have pointers with the first bytes of the hashed public keys
+// it's not proof the wallet signed the tx - any tx using the key as a decoy is also in the rings
+func (w *Wallet) isInTxRings(tx *transaction.Transaction) bool {
+	hashedKey := graviton.Sum(w.Memory.GetAccount().Keys.Public.G1().EncodeCompressed())
+
+	for _, payload := range tx.Payloads {
+		size := int(payload.Statement.Bytes_per_publickey)
+		pointers := payload.Statement.Publickeylist_pointers
+		for i := 0; size > 0 && i+size <= len(pointers); i += size {
+			if bytes.Equal(pointers[i:i+size], hashedKey[:size]) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// online step - stores the signed tx like any other outgoing tx and sends it to the node
+func (w *Wallet) BroadcastSignedTx(tx *transaction.Transaction) error {
+	if tx.TransactionType != transaction.NORMAL && tx.TransactionType != transaction.SC_TX {
+		return fmt.Errorf("invalid transaction type %s", tx.TransactionType)
+	}
+
+	if !w.isInTxRings(tx) {
+		return fmt.Errorf("the wallet address is not in the transaction rings")
+	}
+
+	err := w.InsertOutgoingTx(tx)
+	if err != nil {
+		return err
+	}
+
+	return w.Memory.SendTransaction(tx)
+}
+
+// a tx does not fit in a single QR code - the data is split in numbered frames [index/count/hash:data]
+// the hash is a short checksum of the full data so frames of another tx are not mixed in
+func SplitQRFrames(data string, frameSize int) []string {
+	hash := qrFramesHash(data)
+	count := int(math.Ceil(float64(len(data)) / float64(frameSize)))
+	var frames []string
+	for i := 0; i < count; i++ {
+		end := (i + 1) * frameSize
+		if end > len(data) {
+			end = len(data)
+		}
+
+		frames = append(frames, fmt.Sprintf("%d/%d/%s:%s", i+1, count, hash, data[i*frameSize:end]))
+	}
+
+	return frames
+}
+
+func qrFramesHash(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:4])
+}
+
+// collects scanned frames in any order
+type QRFrames struct {
+	frames []string
+	hash   string
+	added  int
+}
+
+func (q *QRFrames) Add(frame string) (done bool, err error) {
+	header, data, ok := strings.Cut(frame, ":")
+	if !ok {
+		err = fmt.Errorf("invalid QR frame")
+		return
+	}
+
+	values := strings.Split(header, "/")
+	if len(values) != 3 {
+		err = fmt.Errorf("invalid QR frame")
+		return
+	}
+
+	index, err := strconv.Atoi(values[0])
+	if err != nil {
+		return
+	}
+
+	count, err := strconv.Atoi(values[1])
+	if err != nil {
+		return
+	}
+
+	hash := values[2]
+	if count < 1 || index < 1 || index > count || hash == "" {
+		err = fmt.Errorf("invalid QR frame %s", header)
+		return
+	}
+
+	if q.frames == nil {
+		q.frames = make([]string, count)
+		q.hash = hash
+	} else if len(q.frames) != count || q.hash != hash {
+		err = fmt.Errorf("the QR frame is from another sequence")
+		return
+	}
+
+	if q.frames[index-1] == "" {
+		q.frames[index-1] = data
+		q.added++
+	}
+
+	if q.Done() && qrFramesHash(q.Data()) != q.hash {
+		err = fmt.Errorf("the QR frames don't match their checksum")
+		return
+	}
+
+	return q.Done(), nil
+}
+
+func (q *QRFrames) Done() bool {
+	return q.frames != nil && q.added == len(q.frames)
+}
+
+func (q *QRFrames) Progress() (added int, count int) {
+	return q.added, len(q.frames)
+}
+
+func (q *QRFrames) Data() string {
+	return strings.Join(q.frames, "")
+}
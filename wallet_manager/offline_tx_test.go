@@ -0,0 +1,194 @@
+package wallet_manager
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/deroproject/derohe/rpc"
+	"github.com/secretsystems/secret-wallet/mock_daemon"
+)
+
+func TestOfflineTransaction(t *testing.T) {
+	wallet := openTestWallet(t, 100000)
+	dest := newTestDestination(crypto.ZEROHASH)
+
+	unsignedTx, err := wallet.PrepareTransaction([]rpc.Transfer{{Destination: dest, Amount: 1000}}, 4, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(unsignedTx.Rings) != 1 || len(unsignedTx.Rings[0]) != 4 {
+		t.Fatalf("unexpected rings %v", unsignedTx.Rings)
+	}
+
+	// the bundle goes through a file or QR codes
+	value, err := unsignedTx.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unsignedTx, err = DecodeUnsignedTx(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx, txFees, err := wallet.SignTransaction(unsignedTx, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tx.Fees() < txFees {
+		t.Fatalf("tx fees %d are lower than needed %d", tx.Fees(), txFees)
+	}
+
+	signedTx, err := DecodeSignedTx(hex.EncodeToString(tx.Serialize()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls := daemon.Calls("DERO.SendRawTransaction")
+	err = wallet.BroadcastSignedTx(signedTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if daemon.Calls("DERO.SendRawTransaction") != calls+1 {
+		t.Fatal("tx was not sent")
+	}
+
+	outgoingTx := getTestOutgoingTx(t, wallet, tx.GetHash().String())
+	if outgoingTx.Status.String != "pending" {
+		t.Fatalf("outgoing tx status is %s", outgoingTx.Status.String)
+	}
+
+	// signed by another wallet
+	otherWallet := openTestWallet(t, 100000)
+	err = otherWallet.BroadcastSignedTx(signedTx)
+	if err == nil {
+		t.Fatal("expected an error for a tx of another wallet")
+	}
+}
+
+func TestSignTransactionErrors(t *testing.T) {
+	wallet := openTestWallet(t, 1000)
+	dest := newTestDestination(crypto.ZEROHASH)
+
+	unsignedTx, err := wallet.PrepareTransaction([]rpc.Transfer{{Destination: dest, Amount: 1001}}, 2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		update func(u *UnsignedTx)
+		err    string
+	}{
+		{name: "not enough funds", update: func(u *UnsignedTx) {}, err: "you don't have enough Dero"},
+		{name: "other signer", update: func(u *UnsignedTx) { u.Signer = mock_daemon.RandomAddress() }, err: "the transaction must be signed by"},
+		{name: "other network", update: func(u *UnsignedTx) { u.Mainnet = !u.Mainnet }, err: "the transaction is not from the current network"},
+		{name: "version", update: func(u *UnsignedTx) { u.Version = 0 }, err: "unsupported unsigned tx version"},
+		{name: "ring size", update: func(u *UnsignedTx) { u.Rings[0] = u.Rings[0][:1] }, err: "invalid ring size"},
+		{name: "receiver", update: func(u *UnsignedTx) { u.Transfers[0].Destination = mock_daemon.RandomAddress() }, err: "does not match the ring"},
+		{name: "no dero transfer", update: func(u *UnsignedTx) { u.Transfers[0].SCID = crypto.HashHexToHash(strings.Repeat("a1", 32)) }, err: "no Dero transfer"},
+		{name: "sender", update: func(u *UnsignedTx) { u.Rings[0][0], u.Rings[0][1] = u.Rings[0][1], u.Rings[0][0] }, err: "the wallet is not the sender"},
+	}
+
+	for _, test := range tests {
+		value, err := unsignedTx.Encode()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// use a copy for each test
+		testTx, err := DecodeUnsignedTx(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		test.update(&testTx)
+		_, _, err = wallet.SignTransaction(testTx, true)
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: expected [%s] error, got %v", test.name, test.err, err)
+		}
+	}
+}
+
+func TestPrepareTransactionWatchOnly(t *testing.T) {
+	addr := mock_daemon.RandomAddress()
+	daemon.SetRegistration(addr, daemon.Height)
+	daemon.SetBalance(crypto.ZEROHASH, addr, 5000)
+	daemon.SetRandomAddresses(crypto.ZEROHASH, mock_daemon.RandomAddresses(20))
+	wallet := openTestWatchOnlyWallet(t, addr)
+
+	err := wallet.SyncWatchOnly()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unsignedTx, err := wallet.PrepareTransaction([]rpc.Transfer{{Destination: newTestDestination(crypto.ZEROHASH), Amount: 1}}, 2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if unsignedTx.Signer != addr || unsignedTx.Height != uint64(daemon.Height) {
+		t.Fatalf("unexpected unsigned tx %+v", unsignedTx)
+	}
+
+	_, _, err = wallet.SignTransaction(unsignedTx, true)
+	if err != ErrWatchOnly {
+		t.Fatalf("expected watch-only error, got %v", err)
+	}
+}
+
+func TestQRFrames(t *testing.T) {
+	data := strings.Repeat("0123456789", 25)
+	frames := SplitQRFrames(data, 100)
+	if len(frames) != 3 || !strings.HasPrefix(frames[2], "3/3/"+qrFramesHash(data)+":") {
+		t.Fatalf("unexpected frames %v", frames)
+	}
+
+	// scanned in any order with duplicates
+	var qrFrames QRFrames
+	for _, frame := range []string{frames[1], frames[1], frames[2]} {
+		done, err := qrFrames.Add(frame)
+		if err != nil || done {
+			t.Fatalf("unexpected state %t %v", done, err)
+		}
+	}
+
+	done, err := qrFrames.Add(frames[0])
+	if err != nil || !done || qrFrames.Data() != data {
+		t.Fatalf("frames not complete %t %v", done, err)
+	}
+
+	for _, frame := range []string{"invalid", "1/3:data", "a/3/00:data", "4/3/00:data", "1/3/:data", "1/2/00:data", "1/3/00:data"} {
+		_, err := qrFrames.Add(frame)
+		if err == nil {
+			t.Errorf("expected an error for frame [%s]", frame)
+		}
+	}
+
+	// a frame of another tx with the same count
+	otherFrames := SplitQRFrames(strings.Repeat("9876543210", 25), 100)
+	qrFrames = QRFrames{}
+	for _, frame := range []string{frames[0], otherFrames[1]} {
+		_, err = qrFrames.Add(frame)
+	}
+
+	if err == nil {
+		t.Fatal("expected an error for a frame of another sequence")
+	}
+
+	// a corrupted frame with the right header
+	qrFrames = QRFrames{}
+	corrupted := frames[2][:len(frames[2])-1] + "x"
+	for _, frame := range []string{frames[0], frames[1], corrupted} {
+		done, err = qrFrames.Add(frame)
+	}
+
+	if err == nil || done {
+		t.Fatalf("expected a checksum error %t %v", done, err)
+	}
+}
@@ -8,11 +8,18 @@ import (
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/deroproject/derohe/block"
+	"github.com/deroproject/derohe/cryptography/crypto"
 	"github.com/deroproject/derohe/rpc"
 	"github.com/deroproject/derohe/transaction"
 	"github.com/deroproject/derohe/walletapi"
 )
 
+// wallet types stored in tx_type instead of the transaction type
+// the tx is still a regular sc call on chain
+const (
+	OUTGOING_TX_DEX_BRIDGE_OUT transaction.TransactionType = 100
+)
+
 type OutgoingTx struct {
 	TxId        string
 	HeightBuilt sql.NullInt64
@@ -73,6 +80,7 @@ type GetOutgoingTxsParams struct {
 	OrderBy    string
 	Limit      *uint64
 	TxType     *transaction.TransactionType
+	TxIds      []string
 }
 
 func (w *Wallet) GetOutgoingTxs(params GetOutgoingTxsParams) ([]OutgoingTx, error) {
@@ -82,6 +90,10 @@ func (w *Wallet) GetOutgoingTxs(params GetOutgoingTxsParams) ([]OutgoingTx, erro
 		query = query.Where(sq.Eq{"tx_type": params.TxType})
 	}
 
+	if len(params.TxIds) > 0 {
+		query = query.Where(sq.Eq{"tx_id": params.TxIds})
+	}
+
 	if len(params.OrderBy) > 0 {
 		direction := "ASC"
 		if params.Descending {
@@ -103,6 +115,77 @@ func (w *Wallet) GetOutgoingTxs(params GetOutgoingTxsParams) ([]OutgoingTx, erro
 	return rowsScanOutgoingTxs(rows)
 }
 
+func (o OutgoingTx) Transaction() (tx transaction.Transaction, err error) {
+	if !o.HexData.Valid {
+		err = fmt.Errorf("tx [%s] has no data", o.TxId)
+		return
+	}
+
+	data, err := hex.DecodeString(o.HexData.String)
+	if err != nil {
+		return
+	}
+
+	err = tx.Deserialize(data)
+	return
+}
+
+type OutgoingSCCall struct {
+	OutgoingTx
+	Entrypoint string
+	Args       rpc.Arguments
+}
+
+// returns outgoing sc calls of a specific smart contract - filter by entrypoints if any
+func (w *Wallet) GetOutgoingSCCalls(scId crypto.Hash, entrypoints ...string) ([]OutgoingSCCall, error) {
+	txType := transaction.SC_TX
+	outgoingTxs, err := w.GetOutgoingTxs(GetOutgoingTxsParams{
+		TxType:     &txType,
+		OrderBy:    "timestamp",
+		Descending: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var scCalls []OutgoingSCCall
+	for _, outgoingTx := range outgoingTxs {
+		tx, err := outgoingTx.Transaction()
+		if err != nil {
+			continue
+		}
+
+		if !tx.SCDATA.Has(rpc.SCID, rpc.DataHash) ||
+			!tx.SCDATA.Has("entrypoint", rpc.DataString) {
+			continue
+		}
+
+		txSCID := tx.SCDATA.Value(rpc.SCID, rpc.DataHash).(crypto.Hash)
+		if txSCID != scId {
+			continue
+		}
+
+		entrypoint := tx.SCDATA.Value("entrypoint", rpc.DataString).(string)
+		add := len(entrypoints) == 0
+		for _, e := range entrypoints {
+			if e == entrypoint {
+				add = true
+				break
+			}
+		}
+
+		if add {
+			scCalls = append(scCalls, OutgoingSCCall{
+				OutgoingTx: outgoingTx,
+				Entrypoint: entrypoint,
+				Args:       tx.SCDATA,
+			})
+		}
+	}
+
+	return scCalls, nil
+}
+
 var pendingTries map[string]int
 
 func (w *Wallet) CheckRegistrationTx(tx transaction.Transaction) (rpc.GetEncryptedBalance_Result, bool, error) {
@@ -182,16 +265,14 @@ func (w *Wallet) UpdatePendingOutgoingTxs() (int, error) {
 	updated := 0
 
 	for i, info := range txResult.Txs {
-		txHex := txResult.Txs_as_hex[i]
-
-		var tx transaction.Transaction
-		data, _ := hex.DecodeString(txHex)
-		err := tx.Deserialize(data)
+		// use the tx we stored - the daemon returns empty data if it does not know the tx
+		outgoingTx := outgoingTxs[i]
+		tx, err := outgoingTx.Transaction()
 		if err != nil {
 			return updated, err
 		}
 
-		txId := tx.GetHash().String()
+		txId := outgoingTx.TxId
 		valid := false
 		var blockHeight int64
 
@@ -251,9 +332,12 @@ func (w *Wallet) UpdateOugoingTx(txId string, status string, blockHeight int64)
 }
 
 func (w *Wallet) InsertOutgoingTx(tx *transaction.Transaction) error {
+	return w.InsertOutgoingTxWithType(tx, tx.TransactionType)
+}
+
+func (w *Wallet) InsertOutgoingTxWithType(tx *transaction.Transaction, txType transaction.TransactionType) error {
 	txId := tx.GetHash().String()
 	height := tx.Height
-	txType := tx.TransactionType
 	hexData := hex.EncodeToString(tx.Serialize())
 
 	_, err := w.DB.Exec(`
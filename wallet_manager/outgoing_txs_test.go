@@ -0,0 +1,159 @@
+package wallet_manager
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/deroproject/derohe/rpc"
+	"github.com/deroproject/derohe/transaction"
+	"github.com/deroproject/derohe/walletapi"
+	"github.com/secretsystems/secret-wallet/mock_daemon"
+)
+
+func insertTestTx(t *testing.T, wallet *Wallet) *transaction.Transaction {
+	t.Helper()
+
+	dest := newTestDestination(crypto.ZEROHASH)
+	tx, _, _, err := wallet.BuildTransaction([]rpc.Transfer{{Destination: dest, Amount: 1}}, 2, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = wallet.InsertOutgoingTx(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return tx
+}
+
+func getTestOutgoingTx(t *testing.T, wallet *Wallet, txId string) OutgoingTx {
+	t.Helper()
+
+	outgoingTxs, err := wallet.GetOutgoingTxs(GetOutgoingTxsParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, outgoingTx := range outgoingTxs {
+		if outgoingTx.TxId == txId {
+			return outgoingTx
+		}
+	}
+
+	t.Fatalf("outgoing tx [%s] not found", txId)
+	return OutgoingTx{}
+}
+
+func updateTestPendingTxs(t *testing.T, wallet *Wallet, expected int) {
+	t.Helper()
+
+	updated, err := wallet.UpdatePendingOutgoingTxs()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if updated != expected {
+		t.Fatalf("%d txs updated instead of %d", updated, expected)
+	}
+}
+
+func TestUpdatePendingOutgoingTxs(t *testing.T) {
+	wallet := openTestWallet(t, 100000)
+	pendingTries = nil
+
+	unknownTx := insertTestTx(t, wallet)
+	poolTx := insertTestTx(t, wallet)
+	minedTx := insertTestTx(t, wallet)
+
+	poolTxId := poolTx.GetHash().String()
+	minedTxId := minedTx.GetHash().String()
+	daemon.SetTx(poolTxId, mock_daemon.Tx{Hex: hex.EncodeToString(poolTx.Serialize()), BlockHeight: -1, InPool: true})
+	daemon.SetTx(minedTxId, mock_daemon.Tx{Hex: hex.EncodeToString(minedTx.Serialize()), BlockHeight: 105, ValidBlock: "blid"})
+
+	// a tx the daemon doesn't know must not stop the other updates
+	updateTestPendingTxs(t, wallet, 1)
+
+	outgoingTx := getTestOutgoingTx(t, wallet, minedTxId)
+	if outgoingTx.Status.String != "valid" || outgoingTx.BlockHeight.Int64 != 105 {
+		t.Fatalf("mined tx is %s at height %d", outgoingTx.Status.String, outgoingTx.BlockHeight.Int64)
+	}
+
+	for _, txId := range []string{unknownTx.GetHash().String(), poolTxId} {
+		outgoingTx := getTestOutgoingTx(t, wallet, txId)
+		if outgoingTx.Status.String != "pending" {
+			t.Fatalf("tx [%s] is %s instead of pending", txId, outgoingTx.Status.String)
+		}
+	}
+
+	// pending txs are set invalid after 30 tries
+	for i := 1; i < 30; i++ {
+		updateTestPendingTxs(t, wallet, 0)
+	}
+
+	updateTestPendingTxs(t, wallet, 2)
+
+	for _, txId := range []string{unknownTx.GetHash().String(), poolTxId} {
+		outgoingTx := getTestOutgoingTx(t, wallet, txId)
+		if outgoingTx.Status.String != "invalid" {
+			t.Fatalf("tx [%s] is %s instead of invalid", txId, outgoingTx.Status.String)
+		}
+	}
+
+	if len(pendingTries) != 0 {
+		t.Fatalf("%d pending tries left", len(pendingTries))
+	}
+
+	// nothing left to check
+	calls := daemon.Calls("DERO.GetTransaction")
+	updateTestPendingTxs(t, wallet, 0)
+	if daemon.Calls("DERO.GetTransaction") != calls {
+		t.Fatal("no pending txs but the daemon was called")
+	}
+}
+
+func TestUpdatePendingOutgoingTxsRegistration(t *testing.T) {
+	wallet := openTestWallet(t, 0)
+	pendingTries = nil
+
+	tx := wallet.Memory.GetRegistrationTX()
+	err := wallet.InsertOutgoingTx(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	txId := tx.GetHash().String()
+
+	// not in the registration block yet
+	daemon.SetBlock(uint64(daemon.Height))
+	updateTestPendingTxs(t, wallet, 0)
+
+	daemon.SetRegistration(wallet.Info.Addr, 50)
+	daemon.SetBlock(50, tx.GetHash())
+	updateTestPendingTxs(t, wallet, 1)
+
+	outgoingTx := getTestOutgoingTx(t, wallet, txId)
+	if outgoingTx.Status.String != "valid" || outgoingTx.BlockHeight.Int64 != 50 {
+		t.Fatalf("registration tx is %s at height %d", outgoingTx.Status.String, outgoingTx.BlockHeight.Int64)
+	}
+
+	if outgoingTx.TxType.Int32 != int32(transaction.REGISTRATION) {
+		t.Fatalf("tx type is %d instead of registration", outgoingTx.TxType.Int32)
+	}
+}
+
+func TestUpdatePendingOutgoingTxsOffline(t *testing.T) {
+	wallet := openTestWallet(t, 100000)
+	pendingTries = nil
+	insertTestTx(t, wallet)
+
+	walletapi.Connected = false
+	defer func() { walletapi.Connected = true }()
+
+	calls := daemon.Calls("DERO.GetTransaction")
+	updateTestPendingTxs(t, wallet, 0)
+	if daemon.Calls("DERO.GetTransaction") != calls {
+		t.Fatal("the daemon was called while offline")
+	}
+}
@@ -0,0 +1,229 @@
+package wallet_manager
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// rpc server login of a wallet - stored encrypted with a key derived from the wallet secret key
+type RPCCredentials struct {
+	User     string `json:"user"`
+	Password string `json:"password"`
+	// start the rpc server when the wallet is opened
+	AutoStart bool `json:"-"`
+}
+
+func initDatabaseRPCCredentials(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS rpc_credentials (
+			id INTEGER PRIMARY KEY CHECK (id = 1),
+			data VARCHAR NOT NULL,
+			auto_start BOOLEAN NOT NULL
+		);
+	`)
+	return err
+}
+
+// only the opened wallet can decrypt its credentials
+func (w *Wallet) rpcCredentialsCipher() (cipher.AEAD, error) {
+	if w.IsWatchOnly() {
+		return nil, ErrWatchOnly
+	}
+
+	secret := (*big.Int)(w.Memory.GetAccount().Keys.Secret).Bytes()
+	key := sha256.Sum256(append([]byte("secret-wallet rpc credentials"), secret...))
+
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func (w *Wallet) StoreRPCCredentials(credentials RPCCredentials) error {
+	aead, err := w.rpcCredentialsCipher()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(credentials)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return err
+	}
+
+	encrypted := aead.Seal(nonce, nonce, data, nil)
+
+	_, err = w.DB.Exec(`
+		INSERT INTO rpc_credentials (id,data,auto_start)
+		VALUES (1,?,?)
+		ON CONFLICT (id) DO UPDATE SET
+		data = excluded.data,
+		auto_start = excluded.auto_start;
+	`, hex.EncodeToString(encrypted), credentials.AutoStart)
+	return err
+}
+
+// found is false if the credentials were never saved
+func (w *Wallet) GetRPCCredentials() (credentials RPCCredentials, found bool, err error) {
+	row := w.DB.QueryRow(`
+		SELECT data, auto_start FROM rpc_credentials
+		WHERE id = 1;
+	`)
+
+	var data string
+	err = row.Scan(&data, &credentials.AutoStart)
+	if err == sql.ErrNoRows {
+		err = nil
+		return
+	}
+
+	if err != nil {
+		return
+	}
+
+	aead, err := w.rpcCredentialsCipher()
+	if err != nil {
+		return
+	}
+
+	encrypted, err := hex.DecodeString(data)
+	if err != nil {
+		return
+	}
+
+	if len(encrypted) < aead.NonceSize() {
+		err = fmt.Errorf("invalid rpc credentials")
+		return
+	}
+
+	nonce, encrypted := encrypted[:aead.NonceSize()], encrypted[aead.NonceSize():]
+	decrypted, err := aead.Open(nil, nonce, encrypted, nil)
+	if err != nil {
+		return
+	}
+
+	err = json.Unmarshal(decrypted, &credentials)
+	found = err == nil
+	return
+}
+
+func (w *Wallet) DelRPCCredentials() error {
+	_, err := w.DB.Exec(`DELETE FROM rpc_credentials;`)
+	return err
+}
+
+// self-signed certificate kept in dir - a new one is generated if it's missing, expired or doesn't cover the host
+// the fingerprint is the sha256 of the certificate for clients pinning it
+func LoadRPCCertificate(dir string, host string) (cert tls.Certificate, fingerprint string, err error) {
+	certPath := filepath.Join(dir, "rpc_cert.pem")
+	keyPath := filepath.Join(dir, "rpc_key.pem")
+
+	cert, err = tls.LoadX509KeyPair(certPath, keyPath)
+	if err == nil {
+		cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0])
+	}
+
+	if err != nil || time.Now().After(cert.Leaf.NotAfter) || rpcCertificateHostErr(cert.Leaf, host) != nil {
+		err = createRPCCertificate(certPath, keyPath, host)
+		if err != nil {
+			return
+		}
+
+		cert, err = tls.LoadX509KeyPair(certPath, keyPath)
+		if err != nil {
+			return
+		}
+	}
+
+	sum := sha256.Sum256(cert.Certificate[0])
+	fingerprint = hex.EncodeToString(sum[:])
+	return
+}
+
+// unspecified addresses (0.0.0.0) listen on every interface and can't be verified
+func rpcCertificateHostErr(cert *x509.Certificate, host string) error {
+	ip := net.ParseIP(host)
+	if host == "" || (ip != nil && ip.IsUnspecified()) {
+		return nil
+	}
+
+	return cert.VerifyHostname(host)
+}
+
+func createRPCCertificate(certPath string, keyPath string, host string) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return err
+	}
+
+	template := x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{Organization: []string{"secret-wallet"}, CommonName: "secret-wallet rpc"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().AddDate(5, 0, 0),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("::1")},
+	}
+
+	ip := net.ParseIP(host)
+	if ip != nil {
+		if !ip.IsUnspecified() && !ip.IsLoopback() {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		}
+	} else if host != "" && host != "localhost" {
+		template.DNSNames = append(template.DNSNames, host)
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return err
+	}
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(certPath), os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644)
+}
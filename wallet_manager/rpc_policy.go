@@ -0,0 +1,590 @@
+package wallet_manager
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/creachadair/jrpc2"
+	"github.com/creachadair/jrpc2/jhttp"
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/deroproject/derohe/rpc"
+)
+
+// how long a request waits for the user before it's denied
+const RPC_APPROVAL_TIMEOUT = 2 * time.Minute
+
+// log rows kept past the daily limit window
+const RPC_LOG_SIZE = 1000
+
+// reads are logged apart from the other rows so an app polling them doesn't push the approvals out
+const RPC_READ_LOG_SIZE = 1000
+
+// limit row used by clients without their own
+const RPC_ANY_CLIENT = "*"
+
+var ErrRPCDenied = errors.New("request denied")
+
+// answered without asking the user - everything else waits for an approval
+var RPCReadMethods = map[string]bool{
+	"Echo":                   true,
+	"Ping":                   true,
+	"GetAddress":             true,
+	"GetBalance":             true,
+	"GetHeight":              true,
+	"GetTransferbyTXID":      true,
+	"GetTransfers":           true,
+	"MakeIntegratedAddress":  true,
+	"SplitIntegratedAddress": true,
+}
+
+// decoded call shown to the user before it's approved
+type RPCRequest struct {
+	// remote host of the caller
+	Client    string
+	Method    string
+	SCID      string
+	Install   bool
+	Transfers []rpc.Transfer
+	SCArgs    rpc.Arguments
+}
+
+// dero sent and burned - tokens are denied to the clients with a limit instead of being counted
+func (r RPCRequest) Amount() (amount uint64) {
+	for _, transfer := range r.Transfers {
+		if transfer.SCID.IsZero() {
+			amount += transfer.Amount + transfer.Burn
+		}
+	}
+
+	return
+}
+
+// tokens sent or burned - the limits are in DERO so a client with a limit can't move them
+func (r RPCRequest) HasTokens() bool {
+	for _, transfer := range r.Transfers {
+		if !transfer.SCID.IsZero() && transfer.Amount+transfer.Burn > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// receivers of the transfers - burns and sc deposits go to random ring members
+func (r RPCRequest) Destinations() []string {
+	destinations := []string{}
+	for _, transfer := range r.Transfers {
+		if transfer.Amount > 0 && transfer.Destination != "" {
+			destinations = append(destinations, transfer.Destination)
+		}
+	}
+
+	return destinations
+}
+
+// asks the user to approve a request - returns false when denied or ctx is done
+// left nil every request but the reads is denied
+var OnRPCApproval func(ctx context.Context, request RPCRequest) bool
+
+// spending limits in atomic DERO - a limit of 0 is not checked
+type RPCLimit struct {
+	Client      string
+	MaxTransfer uint64
+	MaxDaily    uint64
+}
+
+type RPCAllowedAddress struct {
+	Address string
+	Name    string
+}
+
+type RPCLogEntry struct {
+	ID           int64
+	Timestamp    int64
+	Client       string
+	Method       string
+	Amount       uint64
+	Destinations string
+	Approved     bool
+	// answered without asking the user
+	Read bool
+	// why it was denied or the error of an approved call
+	Reason string
+	TxId   string
+}
+
+func initDatabaseRPCPolicy(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS rpc_limits (
+			client VARCHAR PRIMARY KEY,
+			max_transfer BIGINT,
+			max_daily BIGINT
+		);
+
+		CREATE TABLE IF NOT EXISTS rpc_allowlist (
+			address VARCHAR PRIMARY KEY,
+			name VARCHAR
+		);
+
+		CREATE TABLE IF NOT EXISTS rpc_log (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			timestamp BIGINT,
+			client VARCHAR,
+			method VARCHAR,
+			amount BIGINT,
+			destinations VARCHAR,
+			approved BOOLEAN,
+			read BOOLEAN,
+			reason VARCHAR,
+			tx_id VARCHAR
+		);
+	`)
+	return err
+}
+
+func (w *Wallet) SetRPCLimit(limit RPCLimit) error {
+	if limit.Client == "" {
+		limit.Client = RPC_ANY_CLIENT
+	}
+
+	_, err := w.DB.Exec(`
+		INSERT INTO rpc_limits (client,max_transfer,max_daily)
+		VALUES (?,?,?)
+		ON CONFLICT (client) DO UPDATE SET
+		max_transfer = excluded.max_transfer,
+		max_daily = excluded.max_daily;
+	`, limit.Client, limit.MaxTransfer, limit.MaxDaily)
+	return err
+}
+
+func (w *Wallet) DelRPCLimit(client string) error {
+	_, err := w.DB.Exec(`
+		DELETE FROM rpc_limits
+		WHERE client = ?;
+	`, client)
+	return err
+}
+
+func (w *Wallet) GetRPCLimits() ([]RPCLimit, error) {
+	query := sq.Select("client", "max_transfer", "max_daily").
+		From("rpc_limits").
+		OrderBy("client ASC")
+
+	rows, err := query.RunWith(w.DB).Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var limits []RPCLimit
+	for rows.Next() {
+		var limit RPCLimit
+		err = rows.Scan(&limit.Client, &limit.MaxTransfer, &limit.MaxDaily)
+		if err != nil {
+			return nil, err
+		}
+
+		limits = append(limits, limit)
+	}
+
+	return limits, nil
+}
+
+// the client's own limit or the one of every client - found is false if neither is set
+func (w *Wallet) GetRPCLimit(client string) (limit RPCLimit, found bool, err error) {
+	row := w.DB.QueryRow(`
+		SELECT client, max_transfer, max_daily FROM rpc_limits
+		WHERE client IN (?,?)
+		ORDER BY client = ? ASC
+		LIMIT 1;
+	`, client, RPC_ANY_CLIENT, RPC_ANY_CLIENT)
+
+	err = row.Scan(&limit.Client, &limit.MaxTransfer, &limit.MaxDaily)
+	if err == sql.ErrNoRows {
+		err = nil
+		return
+	}
+
+	found = err == nil
+	return
+}
+
+// integrated addresses are stored as their base address
+func (w *Wallet) AddRPCAllowedAddress(address string, name string) error {
+	addr, err := rpc.NewAddress(address)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.DB.Exec(`
+		INSERT INTO rpc_allowlist (address,name)
+		VALUES (?,?)
+		ON CONFLICT (address) DO UPDATE SET
+		name = excluded.name;
+	`, addr.BaseAddress().String(), name)
+	return err
+}
+
+func (w *Wallet) DelRPCAllowedAddress(address string) error {
+	_, err := w.DB.Exec(`
+		DELETE FROM rpc_allowlist
+		WHERE address = ?;
+	`, address)
+	return err
+}
+
+func (w *Wallet) GetRPCAllowlist() ([]RPCAllowedAddress, error) {
+	query := sq.Select("address", "name").
+		From("rpc_allowlist").
+		OrderBy("name ASC", "address ASC")
+
+	rows, err := query.RunWith(w.DB).Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var allowlist []RPCAllowedAddress
+	for rows.Next() {
+		var allowed RPCAllowedAddress
+		err = rows.Scan(&allowed.Address, &allowed.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		allowlist = append(allowlist, allowed)
+	}
+
+	return allowlist, nil
+}
+
+// rows past the log size are removed once they are out of the daily limit window
+// reads are capped to their own size right away
+func (w *Wallet) InsertRPCLog(entry RPCLogEntry) error {
+	if entry.Timestamp == 0 {
+		entry.Timestamp = time.Now().UnixMilli()
+	}
+
+	_, err := w.DB.Exec(`
+		INSERT INTO rpc_log (timestamp,client,method,amount,destinations,approved,read,reason,tx_id)
+		VALUES (?,?,?,?,?,?,?,?,?);
+	`, entry.Timestamp, entry.Client, entry.Method, entry.Amount, entry.Destinations, entry.Approved, entry.Read, entry.Reason, entry.TxId)
+	if err != nil {
+		return err
+	}
+
+	if entry.Read {
+		_, err = w.DB.Exec(`
+			DELETE FROM rpc_log
+			WHERE read AND id NOT IN (
+				SELECT id FROM rpc_log WHERE read ORDER BY id DESC LIMIT ?
+			);
+		`, RPC_READ_LOG_SIZE)
+		return err
+	}
+
+	_, err = w.DB.Exec(`
+		DELETE FROM rpc_log
+		WHERE NOT read AND timestamp < ? AND id NOT IN (
+			SELECT id FROM rpc_log WHERE NOT read ORDER BY id DESC LIMIT ?
+		);
+	`, time.Now().Add(-24*time.Hour).UnixMilli(), RPC_LOG_SIZE)
+	return err
+}
+
+// latest first
+func (w *Wallet) GetRPCLog(limit uint64) ([]RPCLogEntry, error) {
+	query := sq.Select("id", "timestamp", "client", "method", "amount", "destinations", "approved", "read", "reason", "tx_id").
+		From("rpc_log").
+		OrderBy("id DESC").
+		Limit(limit)
+
+	rows, err := query.RunWith(w.DB).Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []RPCLogEntry
+	for rows.Next() {
+		var entry RPCLogEntry
+		err = rows.Scan(
+			&entry.ID, &entry.Timestamp, &entry.Client, &entry.Method, &entry.Amount,
+			&entry.Destinations, &entry.Approved, &entry.Read, &entry.Reason, &entry.TxId,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func (w *Wallet) ClearRPCLog() error {
+	_, err := w.DB.Exec(`DELETE FROM rpc_log;`)
+	return err
+}
+
+// dero sent by txs of the client in the last 24 hours
+func (w *Wallet) GetRPCDailySpent(client string) (spent uint64, err error) {
+	row := w.DB.QueryRow(`
+		SELECT COALESCE(SUM(amount), 0) FROM rpc_log
+		WHERE client = ? AND approved AND tx_id <> '' AND timestamp >= ?;
+	`, client, time.Now().Add(-24*time.Hour).UnixMilli())
+
+	err = row.Scan(&spent)
+	return
+}
+
+// checks the limits and the allowlist - the user is only asked if it passes
+func (w *Wallet) CheckRPCPolicy(request RPCRequest) error {
+	limit, found, err := w.GetRPCLimit(request.Client)
+	if err != nil {
+		return err
+	}
+
+	amount := request.Amount()
+	if found && (limit.MaxTransfer > 0 || limit.MaxDaily > 0) {
+		if request.HasTokens() {
+			return fmt.Errorf("%w: tokens can't be sent by a client with a spending limit", ErrRPCDenied)
+		}
+
+		if limit.MaxTransfer > 0 && amount > limit.MaxTransfer {
+			return fmt.Errorf("%w: amount is over the transfer limit", ErrRPCDenied)
+		}
+
+		if limit.MaxDaily > 0 {
+			spent, err := w.GetRPCDailySpent(request.Client)
+			if err != nil {
+				return err
+			}
+
+			if spent+amount > limit.MaxDaily {
+				return fmt.Errorf("%w: amount is over the daily limit", ErrRPCDenied)
+			}
+		}
+	}
+
+	allowlist, err := w.GetRPCAllowlist()
+	if err != nil {
+		return err
+	}
+
+	// an empty allowlist doesn't restrict the destinations
+	if len(allowlist) == 0 {
+		return nil
+	}
+
+	allowed := make(map[string]bool)
+	for _, address := range allowlist {
+		allowed[address.Address] = true
+	}
+
+	for _, destination := range request.Destinations() {
+		addr, err := rpc.NewAddress(destination)
+		if err != nil || !allowed[addr.BaseAddress().String()] {
+			return fmt.Errorf("%w: %s is not an allowed destination", ErrRPCDenied, destination)
+		}
+	}
+
+	return nil
+}
+
+type rpcClientKey struct{}
+
+// the server has a single login so the host is all that tells the callers apart - the local apps share 127.0.0.1
+func rpcClientFromRequest(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+func rpcClient(ctx context.Context) string {
+	client, _ := ctx.Value(rpcClientKey{}).(string)
+	return client
+}
+
+// the http bridge calls its own server so the caller is passed along with the params
+type rpcClientParams struct {
+	Client string          `json:"client"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+func encodeRPCClient(ctx context.Context, method string, params json.RawMessage) (json.RawMessage, error) {
+	var client string
+	r := jhttp.HTTPRequest(ctx)
+	if r != nil {
+		client = rpcClientFromRequest(r)
+	}
+
+	return json.Marshal(rpcClientParams{Client: client, Params: params})
+}
+
+func decodeRPCClient(ctx context.Context, method string, params json.RawMessage) (context.Context, json.RawMessage, error) {
+	var p rpcClientParams
+	err := json.Unmarshal(params, &p)
+	if err != nil {
+		return ctx, nil, err
+	}
+
+	// methods without params refuse an explicit null
+	if string(p.Params) == "null" {
+		p.Params = nil
+	}
+
+	return context.WithValue(ctx, rpcClientKey{}, p.Client), p.Params, nil
+}
+
+// decodes the params of the calls that need an approval
+func parseRPCRequest(client string, method string, req *jrpc2.Request) (request RPCRequest, err error) {
+	request = RPCRequest{Client: client, Method: method}
+
+	switch method {
+	case "Transfer":
+		var p rpc.Transfer_Params
+		err = req.UnmarshalParams(&p)
+		if err != nil {
+			return
+		}
+
+		request.Transfers = p.Transfers
+		request.SCID = p.SC_ID
+		request.SCArgs = p.SC_RPC
+		request.Install = p.SC_ID == "" && p.SC_Code != ""
+	case "SC_Invoke":
+		var p rpc.SC_Invoke_Params
+		err = req.UnmarshalParams(&p)
+		if err != nil {
+			return
+		}
+
+		request.SCID = p.SC_ID
+		request.SCArgs = p.SC_RPC
+		if p.SC_DERO_Deposit > 0 {
+			request.Transfers = append(request.Transfers, rpc.Transfer{Burn: p.SC_DERO_Deposit})
+		}
+
+		if p.SC_TOKEN_Deposit > 0 {
+			request.Transfers = append(request.Transfers, rpc.Transfer{SCID: crypto.HashHexToHash(p.SC_ID), Burn: p.SC_TOKEN_Deposit})
+		}
+	}
+
+	return
+}
+
+var (
+	// held by the calls that are not reads so a limit can't be passed by concurrent calls
+	rpcPolicyLock sync.Mutex
+	// one prompt at a time - the app has a single confirm modal
+	rpcApprovalLock sync.Mutex
+)
+
+// waits for the user - ask returns false when denied or ctx is done
+func askRPCApproval(ctx context.Context, ask func(ctx context.Context) bool) error {
+	if ask == nil {
+		return fmt.Errorf("%w: approvals are not available", ErrRPCDenied)
+	}
+
+	rpcApprovalLock.Lock()
+	defer rpcApprovalLock.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, RPC_APPROVAL_TIMEOUT)
+	defer cancel()
+
+	if !ask(ctx) {
+		if ctx.Err() != nil {
+			return fmt.Errorf("%w: no answer", ErrRPCDenied)
+		}
+
+		return fmt.Errorf("%w: rejected by the user", ErrRPCDenied)
+	}
+
+	return nil
+}
+
+// a call of the rpc or xswd server - calls that are not reads are checked against the limits and the allowlist
+// approve is called after the policy passed and the call is logged either way
+func (w *Wallet) serveRPCCall(ctx context.Context, client string, method string, req *jrpc2.Request, h jrpc2.Handler, approve func(ctx context.Context, request RPCRequest) error) (interface{}, error) {
+	entry := RPCLogEntry{Client: client, Method: method, Read: RPCReadMethods[method]}
+
+	deny := func(err error) (interface{}, error) {
+		entry.Reason = err.Error()
+		w.logRPCCall(entry)
+		return nil, err
+	}
+
+	request, err := parseRPCRequest(client, method, req)
+	if err != nil {
+		return deny(err)
+	}
+
+	if !RPCReadMethods[method] {
+		rpcPolicyLock.Lock()
+		defer rpcPolicyLock.Unlock()
+
+		entry.Amount = request.Amount()
+		entry.Destinations = strings.Join(request.Destinations(), ",")
+
+		err = w.CheckRPCPolicy(request)
+		if err != nil {
+			return deny(err)
+		}
+	}
+
+	err = approve(ctx, request)
+	if err != nil {
+		return deny(err)
+	}
+
+	result, err := h.Handle(ctx, req)
+	entry.Approved = true
+	if err != nil {
+		entry.Reason = err.Error()
+	}
+
+	transferResult, ok := result.(rpc.Transfer_Result)
+	if ok {
+		entry.TxId = transferResult.TXID
+	}
+
+	w.logRPCCall(entry)
+	return result, err
+}
+
+// a failing log doesn't fail the call
+func (w *Wallet) logRPCCall(entry RPCLogEntry) {
+	err := w.InsertRPCLog(entry)
+	if err != nil {
+		fmt.Println(err)
+	}
+}
+
+// reads go through and the other calls wait for the user
+func (s *RPCServer) serve(ctx context.Context, method string, req *jrpc2.Request, h jrpc2.Handler) (interface{}, error) {
+	return s.wallet.serveRPCCall(ctx, rpcClient(ctx), method, req, h, func(ctx context.Context, request RPCRequest) error {
+		if RPCReadMethods[method] {
+			return nil
+		}
+
+		var ask func(ctx context.Context) bool
+		if OnRPCApproval != nil {
+			ask = func(ctx context.Context) bool { return OnRPCApproval(ctx, request) }
+		}
+
+		return askRPCApproval(ctx, ask)
+	})
+}
@@ -0,0 +1,228 @@
+package wallet_manager
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/deroproject/derohe/rpc"
+)
+
+func TestRPCPolicy(t *testing.T) {
+	wallet := openTestWallet(t, 100000)
+	server := startTestRPCServer(t, wallet, RPCServerConfig{})
+	url := "http://" + server.Addr()
+
+	transfer := func(dest string, amount uint64) (rpc.Transfer_Result, error) {
+		var result rpc.Transfer_Result
+		_, err := callTestRPC(t, http.DefaultClient, url, "pass", "Transfer", rpc.Transfer_Params{
+			Transfers: []rpc.Transfer{{Destination: dest, Amount: amount}},
+			Ringsize:  2,
+		}, &result)
+		return result, err
+	}
+
+	expectDenied := func(err error, reason string) {
+		t.Helper()
+		if err == nil || !strings.Contains(err.Error(), ErrRPCDenied.Error()) || !strings.Contains(err.Error(), reason) {
+			t.Fatalf("expected denied [%s] got %v", reason, err)
+		}
+	}
+
+	dest := newTestDestination(crypto.ZEROHASH)
+
+	// reads don't need an approval
+	var heightResult rpc.GetHeight_Result
+	_, err := callTestRPC(t, http.DefaultClient, url, "pass", "GetHeight", nil, &heightResult)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// no one to ask
+	_, err = transfer(dest, 1)
+	expectDenied(err, "approvals are not available")
+
+	approvals := setTestRPCApproval(t, false)
+	_, err = transfer(dest, 1)
+	expectDenied(err, "rejected")
+
+	var keyResult rpc.Query_Key_Result
+	_, err = callTestRPC(t, http.DefaultClient, url, "pass", "QueryKey", rpc.Query_Key_Params{Key_type: "mnemonic"}, &keyResult)
+	expectDenied(err, "rejected")
+
+	if *approvals != 2 {
+		t.Fatalf("%d approvals asked instead of 2", *approvals)
+	}
+
+	approvals = setTestRPCApproval(t, true)
+
+	// the own limit of the client is used over the one of every client
+	err = wallet.SetRPCLimit(RPCLimit{MaxTransfer: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = wallet.SetRPCLimit(RPCLimit{Client: "127.0.0.1", MaxTransfer: 500, MaxDaily: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = transfer(dest, 501)
+	expectDenied(err, "transfer limit")
+
+	// the limits are in DERO so the tokens are denied
+	var tokenResult rpc.Transfer_Result
+	_, err = callTestRPC(t, http.DefaultClient, url, "pass", "Transfer", rpc.Transfer_Params{
+		Transfers: []rpc.Transfer{{SCID: crypto.HashHexToHash(strings.Repeat("ab", 32)), Destination: dest, Amount: 1}},
+		Ringsize:  2,
+	}, &tokenResult)
+	expectDenied(err, "tokens can't be sent")
+
+	_, err = callTestRPC(t, http.DefaultClient, url, "pass", "SC_Invoke", rpc.SC_Invoke_Params{
+		SC_ID:            strings.Repeat("ab", 32),
+		SC_TOKEN_Deposit: 1,
+		Ringsize:         2,
+	}, &tokenResult)
+	expectDenied(err, "tokens can't be sent")
+
+	result, err := transfer(dest, 500)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = transfer(dest, 500)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = transfer(dest, 1)
+	expectDenied(err, "daily limit")
+
+	spent, err := wallet.GetRPCDailySpent("127.0.0.1")
+	if err != nil || spent != 1000 {
+		t.Fatalf("unexpected daily spent %d %v", spent, err)
+	}
+
+	err = wallet.DelRPCLimit("127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = transfer(dest, 2)
+	expectDenied(err, "transfer limit")
+
+	err = wallet.DelRPCLimit(RPC_ANY_CLIENT)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// once an address is allowed the others are denied
+	err = wallet.AddRPCAllowedAddress(newTestDestination(crypto.ZEROHASH), "other")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = transfer(dest, 1)
+	expectDenied(err, "not an allowed destination")
+
+	err = wallet.AddRPCAllowedAddress(dest, "dest")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = transfer(dest, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *approvals != 3 {
+		t.Fatalf("%d approvals asked instead of 3", *approvals)
+	}
+
+	entries, err := wallet.GetRPCLog(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 13 {
+		t.Fatalf("%d log entries instead of 13", len(entries))
+	}
+
+	last := entries[0]
+	if !last.Approved || last.Method != "Transfer" || last.Client != "127.0.0.1" || last.Amount != 1 ||
+		last.Destinations != dest || last.TxId == "" {
+		t.Fatalf("unexpected log entry %+v", last)
+	}
+
+	first := entries[len(entries)-1]
+	if !first.Approved || !first.Read || first.Method != "GetHeight" {
+		t.Fatalf("unexpected log entry %+v", first)
+	}
+
+	for _, entry := range entries {
+		if entry.TxId == result.TXID && (entry.Amount != 500 || !entry.Approved) {
+			t.Fatalf("unexpected log entry %+v", entry)
+		}
+	}
+
+	err = wallet.ClearRPCLog()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err = wallet.GetRPCLog(100)
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("expected an empty log %d %v", len(entries), err)
+	}
+}
+
+// the polled reads don't push the approvals out of the log
+func TestRPCReadLog(t *testing.T) {
+	wallet := openTestWallet(t, 0)
+
+	err := wallet.InsertRPCLog(RPCLogEntry{Client: "127.0.0.1", Method: "Transfer", Amount: 10, TxId: "tx", Approved: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < RPC_READ_LOG_SIZE+10; i++ {
+		err = wallet.InsertRPCLog(RPCLogEntry{Client: "127.0.0.1", Method: "GetHeight", Approved: true, Read: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := wallet.GetRPCLog(RPC_READ_LOG_SIZE + 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != RPC_READ_LOG_SIZE+1 || entries[len(entries)-1].Method != "Transfer" {
+		t.Fatalf("%d log entries instead of %d", len(entries), RPC_READ_LOG_SIZE+1)
+	}
+}
+
+func TestRPCRequest(t *testing.T) {
+	scId := crypto.HashHexToHash("a1b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7f8a9b0c1d2e3f4a5b6c7d8e9f0a1b2")
+	request := RPCRequest{
+		Transfers: []rpc.Transfer{
+			{Destination: "dero1a", Amount: 100},
+			{Destination: "dero1b", Burn: 20},
+			{SCID: scId, Destination: "dero1c", Amount: 5000},
+		},
+	}
+
+	if request.Amount() != 120 {
+		t.Fatalf("amount is %d instead of 120", request.Amount())
+	}
+
+	if !request.HasTokens() || (RPCRequest{Transfers: request.Transfers[:2]}).HasTokens() {
+		t.Fatal("unexpected tokens")
+	}
+
+	destinations := request.Destinations()
+	if len(destinations) != 2 || destinations[0] != "dero1a" || destinations[1] != "dero1c" {
+		t.Fatalf("unexpected destinations %v", destinations)
+	}
+}
@@ -0,0 +1,586 @@
+package wallet_manager
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/creachadair/jrpc2"
+	"github.com/creachadair/jrpc2/channel"
+	"github.com/creachadair/jrpc2/handler"
+	"github.com/creachadair/jrpc2/jhttp"
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/deroproject/derohe/glue/rwc"
+	"github.com/deroproject/derohe/rpc"
+	"github.com/deroproject/derohe/transaction"
+	"github.com/gorilla/websocket"
+	"github.com/secretsystems/secret-wallet/settings"
+)
+
+// requests counted in the status as recent
+const RPC_RECENT_WINDOW = 10 * time.Minute
+
+// same default as the derohe wallet
+const RPC_DEFAULT_RINGSIZE = 16
+
+type RPCServerConfig struct {
+	// host:port - use a port of 0 to pick a free one
+	Bind     string
+	User     string
+	Password string
+	// served with the certificate - nil is plain http
+	Certificate *tls.Certificate
+	// sha256 of the certificate shown to users for pinning
+	Fingerprint string
+}
+
+type RPCServerStatus struct {
+	Running     bool
+	Addr        string
+	TLS         bool
+	Fingerprint string
+	StartedAt   time.Time
+	Err         error
+	Requests    map[string]uint64
+	Total       uint64
+	Recent      int
+	AuthFailed  uint64
+	LastRequest time.Time
+}
+
+// wallet json rpc over http (/json_rpc) and websocket (/ws) - same api as the derohe wallet rpc server
+type RPCServer struct {
+	wallet   *Wallet
+	config   RPCServerConfig
+	listener net.Listener
+	server   *http.Server
+	bridge   jhttp.Bridge
+	methods  handler.Map
+
+	lock        sync.Mutex
+	running     bool
+	startedAt   time.Time
+	err         error
+	requests    map[string]uint64
+	recent      []time.Time
+	authFailed  uint64
+	lastRequest time.Time
+	// hijacked websocket connections are not closed by the http server shutdown
+	conns map[*websocket.Conn]bool
+}
+
+// lowercase names used by older clients
+var rpcMethodAliases = map[string]string{
+	"getaddress":               "GetAddress",
+	"getbalance":               "GetBalance",
+	"getheight":                "GetHeight",
+	"get_transfer_by_txid":     "GetTransferbyTXID",
+	"get_transfers":            "GetTransfers",
+	"make_integrated_address":  "MakeIntegratedAddress",
+	"split_integrated_address": "SplitIntegratedAddress",
+	"query_key":                "QueryKey",
+	"transfer":                 "Transfer",
+	"transfer_split":           "Transfer",
+	"scinvoke":                 "SC_Invoke",
+}
+
+// method name without the websocket service prefix (WALLET.GetAddress) or the old alias
+func RPCMethodName(method string) string {
+	if i := strings.Index(method, "."); i != -1 {
+		method = method[i+1:]
+	}
+
+	name, ok := rpcMethodAliases[method]
+	if ok {
+		return name
+	}
+
+	return method
+}
+
+// the listener is opened before returning so a bind error is not silently ignored
+func (w *Wallet) StartRPCServer(config RPCServerConfig) (*RPCServer, error) {
+	if w.IsWatchOnly() {
+		return nil, ErrWatchOnly
+	}
+
+	if config.User == "" || config.Password == "" {
+		return nil, fmt.Errorf("rpc username and password are required")
+	}
+
+	listener, err := net.Listen("tcp", config.Bind)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Certificate != nil {
+		listener = tls.NewListener(listener, &tls.Config{
+			Certificates: []tls.Certificate{*config.Certificate},
+			MinVersion:   tls.VersionTLS12,
+		})
+	}
+
+	s := &RPCServer{
+		wallet:    w,
+		config:    config,
+		listener:  listener,
+		methods:   w.rpcMethods(),
+		running:   true,
+		startedAt: time.Now(),
+		requests:  make(map[string]uint64),
+		conns:     make(map[*websocket.Conn]bool),
+	}
+
+	s.bridge = jhttp.NewBridge(s, &jhttp.BridgeOptions{
+		Client: &jrpc2.ClientOptions{EncodeContext: encodeRPCClient},
+		Server: &jrpc2.ServerOptions{DecodeContext: decodeRPCClient},
+	})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/json_rpc", s.auth(s.bridge.ServeHTTP))
+	mux.HandleFunc("/ws", s.auth(s.serveWS))
+	mux.HandleFunc("/", func(rw http.ResponseWriter, r *http.Request) {
+		io.WriteString(rw, "DERO BLOCKCHAIN Hello world!")
+	})
+
+	s.server = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		err := s.server.Serve(listener)
+
+		s.lock.Lock()
+		s.running = false
+		if !errors.Is(err, http.ErrServerClosed) {
+			s.err = err
+		}
+		s.lock.Unlock()
+	}()
+
+	return s, nil
+}
+
+// config from the app settings - the certificate is generated in the app dir when tls is on
+func NewRPCServerConfig(credentials RPCCredentials) (config RPCServerConfig, err error) {
+	config = RPCServerConfig{
+		Bind:     settings.App.RPCBind,
+		User:     credentials.User,
+		Password: credentials.Password,
+	}
+
+	if settings.App.RPCTLS {
+		var host string
+		host, _, err = net.SplitHostPort(config.Bind)
+		if err != nil {
+			return
+		}
+
+		var cert tls.Certificate
+		cert, config.Fingerprint, err = LoadRPCCertificate(settings.AppDir, host)
+		if err != nil {
+			return
+		}
+
+		config.Certificate = &cert
+	}
+
+	return
+}
+
+// starts the server of a wallet with saved credentials and auto start on
+func (w *Wallet) AutoStartRPCServer() error {
+	if w.IsWatchOnly() || w.Server != nil {
+		return nil
+	}
+
+	credentials, found, err := w.GetRPCCredentials()
+	if err != nil || !found || !credentials.AutoStart {
+		return err
+	}
+
+	config, err := NewRPCServerConfig(credentials)
+	if err != nil {
+		return err
+	}
+
+	server, err := w.StartRPCServer(config)
+	if err != nil {
+		return err
+	}
+
+	w.Server = server
+	return nil
+}
+
+func (s *RPCServer) Stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	s.bridge.Close()
+	err := s.server.Shutdown(ctx)
+
+	s.lock.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.lock.Unlock()
+
+	return err
+}
+
+func (s *RPCServer) Addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *RPCServer) Status() RPCServerStatus {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	status := RPCServerStatus{
+		Running:     s.running,
+		Addr:        s.Addr(),
+		TLS:         s.config.Certificate != nil,
+		Fingerprint: s.config.Fingerprint,
+		StartedAt:   s.startedAt,
+		Err:         s.err,
+		Requests:    make(map[string]uint64),
+		AuthFailed:  s.authFailed,
+		LastRequest: s.lastRequest,
+	}
+
+	for method, count := range s.requests {
+		status.Requests[method] = count
+		status.Total += count
+	}
+
+	since := time.Now().Add(-RPC_RECENT_WINDOW)
+	for _, t := range s.recent {
+		if t.After(since) {
+			status.Recent++
+		}
+	}
+
+	return status
+}
+
+// methods sorted by request count
+func (status RPCServerStatus) Methods() []string {
+	var methods []string
+	for method := range status.Requests {
+		methods = append(methods, method)
+	}
+
+	sort.Slice(methods, func(i, j int) bool {
+		a, b := status.Requests[methods[i]], status.Requests[methods[j]]
+		if a != b {
+			return a > b
+		}
+
+		return methods[i] < methods[j]
+	})
+
+	return methods
+}
+
+func (s *RPCServer) countRequest(method string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := time.Now()
+	s.requests[method]++
+	s.lastRequest = now
+
+	since := now.Add(-RPC_RECENT_WINDOW)
+	for len(s.recent) > 0 && !s.recent[0].After(since) {
+		s.recent = s.recent[1:]
+	}
+
+	s.recent = append(s.recent, now)
+}
+
+func (s *RPCServer) auth(next http.HandlerFunc) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		user, password, ok := r.BasicAuth()
+		if !ok ||
+			subtle.ConstantTimeCompare([]byte(user), []byte(s.config.User)) != 1 ||
+			subtle.ConstantTimeCompare([]byte(password), []byte(s.config.Password)) != 1 {
+			s.lock.Lock()
+			s.authFailed++
+			s.lock.Unlock()
+
+			rw.WriteHeader(http.StatusUnauthorized)
+			io.WriteString(rw, "Authorization Required")
+			return
+		}
+
+		next(rw, r)
+	}
+}
+
+var rpcUpgrader = websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }}
+
+func (s *RPCServer) serveWS(rw http.ResponseWriter, r *http.Request) {
+	conn, err := rpcUpgrader.Upgrade(rw, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	s.lock.Lock()
+	s.conns[conn] = true
+	s.lock.Unlock()
+
+	defer func() {
+		s.lock.Lock()
+		delete(s.conns, conn)
+		s.lock.Unlock()
+	}()
+
+	client := rpcClientFromRequest(r)
+	options := &jrpc2.ServerOptions{NewContext: func() context.Context {
+		return context.WithValue(context.Background(), rpcClientKey{}, client)
+	}}
+
+	inputOutput := rwc.New(conn)
+	server := jrpc2.NewServer(s, options).Start(channel.RawJSON(inputOutput, inputOutput))
+	server.Wait()
+}
+
+// implements jrpc2.Assigner - every call goes through here to be counted and checked by the policy
+func (s *RPCServer) Assign(ctx context.Context, method string) jrpc2.Handler {
+	name := RPCMethodName(method)
+	h, ok := s.methods[name]
+	if !ok {
+		return nil
+	}
+
+	return handler.Func(func(ctx context.Context, req *jrpc2.Request) (result interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic occured. stack trace %s", debug.Stack())
+			}
+		}()
+
+		s.countRequest(name)
+		return s.serve(ctx, name, req, h)
+	})
+}
+
+func (s *RPCServer) Names() []string {
+	return s.methods.Names()
+}
+
+func (w *Wallet) rpcMethods() handler.Map {
+	return handler.Map{
+		"Echo": handler.New(func(ctx context.Context, args []string) string {
+			return "WALLET " + strings.Join(args, " ")
+		}),
+		"Ping": handler.New(func(ctx context.Context) string {
+			return "Pong "
+		}),
+		"GetAddress": handler.New(func(ctx context.Context) (rpc.GetAddress_Result, error) {
+			return rpc.GetAddress_Result{Address: w.Memory.GetAddress().String()}, nil
+		}),
+		"GetBalance": handler.New(func(ctx context.Context, p rpc.GetBalance_Params) (result rpc.GetBalance_Result, err error) {
+			err = w.Memory.Sync_Wallet_Memory_With_Daemon_internal(p.SCID)
+			if err != nil {
+				return
+			}
+
+			mature, locked := w.Memory.Get_Balance_scid(p.SCID)
+			result.Balance = mature + locked
+			result.Unlocked_Balance = mature
+			return
+		}),
+		"GetHeight": handler.New(func(ctx context.Context) (rpc.GetHeight_Result, error) {
+			return rpc.GetHeight_Result{Height: w.Memory.Get_Height()}, nil
+		}),
+		"GetTransferbyTXID": handler.New(func(ctx context.Context, p rpc.Get_Transfer_By_TXID_Params) (result rpc.Get_Transfer_By_TXID_Result, err error) {
+			if len(p.TXID) != 64 {
+				err = fmt.Errorf("%s not 64 hex bytes", p.TXID)
+				return
+			}
+
+			result.SCID, result.Entry = w.Memory.Get_Payments_TXID(p.SCID, p.TXID)
+			if result.Entry.Height == 0 {
+				err = fmt.Errorf("transaction not found. TXID %s", p.TXID)
+			}
+
+			return
+		}),
+		"GetTransfers": handler.New(func(ctx context.Context, p rpc.Get_Transfers_Params) (result rpc.Get_Transfers_Result, err error) {
+			result.Entries = w.Memory.Show_Transfers(p.SCID, p.Coinbase, p.In, p.Out, p.Min_Height, p.Max_Height, p.Sender, p.Receiver, p.DestinationPort, p.SourcePort)
+			return
+		}),
+		"MakeIntegratedAddress": handler.New(func(ctx context.Context, p rpc.Make_Integrated_Address_Params) (result rpc.Make_Integrated_Address_Result, err error) {
+			addr := w.Memory.GetAddress()
+			if p.Address != "" {
+				var paramAddr *rpc.Address
+				paramAddr, err = rpc.NewAddress(p.Address)
+				if err != nil {
+					return
+				}
+
+				addr = *paramAddr
+			}
+
+			addr.Arguments = p.Payload_RPC
+			_, err = addr.MarshalText()
+			if err != nil {
+				return
+			}
+
+			result.Integrated_Address = addr.String()
+			result.Payload_RPC = p.Payload_RPC
+			return
+		}),
+		"SplitIntegratedAddress": handler.New(func(ctx context.Context, p rpc.Split_Integrated_Address_Params) (result rpc.Split_Integrated_Address_Result, err error) {
+			addr, err := rpc.NewAddress(p.Integrated_Address)
+			if err != nil {
+				return
+			}
+
+			if !addr.IsIntegratedAddress() {
+				err = fmt.Errorf("address %s is not an integrated address", addr.String())
+				return
+			}
+
+			result.Address = addr.BaseAddress().String()
+			result.Payload_RPC = addr.Arguments
+			return
+		}),
+		"QueryKey": handler.New(func(ctx context.Context, p rpc.Query_Key_Params) (result rpc.Query_Key_Result, err error) {
+			if strings.ToLower(p.Key_type) != "mnemonic" {
+				err = fmt.Errorf("invalid key type, must be mnemonic")
+				return
+			}
+
+			result.Key = w.Memory.GetSeed()
+			return
+		}),
+		"Transfer":  handler.New(w.rpcTransfer),
+		"SC_Invoke": handler.New(w.rpcSCInvoke),
+	}
+}
+
+var rpcTransferLock sync.Mutex
+
+// the tx is stored with the other outgoing txs so it shows in the wallet history
+func (w *Wallet) rpcTransfer(ctx context.Context, p rpc.Transfer_Params) (result rpc.Transfer_Result, err error) {
+	rpcTransferLock.Lock()
+	defer rpcTransferLock.Unlock()
+
+	for _, t := range p.Transfers {
+		_, err = t.Payload_RPC.CheckPack(transaction.PAYLOAD0_LIMIT)
+		if err != nil {
+			return
+		}
+	}
+
+	if !w.Memory.GetMode() {
+		err = fmt.Errorf("wallet is in offline mode")
+		return
+	}
+
+	ringsize := p.Ringsize
+	if ringsize == 0 {
+		ringsize = RPC_DEFAULT_RINGSIZE
+	}
+
+	tx, _, _, err := w.BuildTransaction(p.Transfers, ringsize, rpcTransferSCArgs(p), false)
+	if err != nil {
+		return
+	}
+
+	err = w.InsertOutgoingTx(tx)
+	if err != nil {
+		return
+	}
+
+	err = w.Memory.SendTransaction(tx)
+	if err != nil {
+		return
+	}
+
+	result.TXID = tx.GetHash().String()
+	return
+}
+
+// the sc code can be sent base64 encoded because of json limitations
+func rpcTransferSCArgs(p rpc.Transfer_Params) rpc.Arguments {
+	scArgs := append(rpc.Arguments{}, p.SC_RPC...)
+
+	code := p.SC_Code
+	if code != "" {
+		data, err := base64.StdEncoding.DecodeString(code)
+		if err == nil {
+			code = string(data)
+		}
+	}
+
+	if code != "" && p.SC_ID == "" {
+		scArgs = append(scArgs, rpc.Argument{Name: rpc.SCACTION, DataType: rpc.DataUint64, Value: uint64(rpc.SC_INSTALL)})
+		scArgs = append(scArgs, rpc.Argument{Name: rpc.SCCODE, DataType: rpc.DataString, Value: code})
+	}
+
+	if p.SC_ID != "" {
+		scArgs = append(scArgs, rpc.Argument{Name: rpc.SCACTION, DataType: rpc.DataUint64, Value: uint64(rpc.SC_CALL)})
+		scArgs = append(scArgs, rpc.Argument{Name: rpc.SCID, DataType: rpc.DataHash, Value: crypto.HashHexToHash(p.SC_ID)})
+		if code != "" {
+			scArgs = append(scArgs, rpc.Argument{Name: rpc.SCCODE, DataType: rpc.DataString, Value: code})
+		}
+	}
+
+	if len(scArgs) == 0 {
+		return nil
+	}
+
+	return scArgs
+}
+
+func (w *Wallet) rpcSCInvoke(ctx context.Context, p rpc.SC_Invoke_Params) (result rpc.Transfer_Result, err error) {
+	if p.SC_ID == "" {
+		err = fmt.Errorf("SCID cannot be empty")
+		return
+	}
+
+	var transfers []rpc.Transfer
+	if p.SC_DERO_Deposit > 0 {
+		var addr string
+		addr, err = w.GetRandomAddress(crypto.ZEROHASH)
+		if err != nil {
+			return
+		}
+
+		transfers = append(transfers, rpc.Transfer{Destination: addr, Burn: p.SC_DERO_Deposit})
+	}
+
+	if p.SC_TOKEN_Deposit > 0 {
+		scId := crypto.HashHexToHash(p.SC_ID)
+
+		var addr string
+		addr, err = w.GetRandomAddress(scId)
+		if err != nil {
+			return
+		}
+
+		transfers = append(transfers, rpc.Transfer{SCID: scId, Destination: addr, Burn: p.SC_TOKEN_Deposit})
+	}
+
+	return w.rpcTransfer(ctx, rpc.Transfer_Params{
+		Transfers: transfers,
+		SC_ID:     p.SC_ID,
+		SC_RPC:    p.SC_RPC,
+		Ringsize:  p.Ringsize,
+	})
+}
@@ -0,0 +1,318 @@
+package wallet_manager
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/deroproject/derohe/rpc"
+	"github.com/deroproject/derohe/transaction"
+	"github.com/secretsystems/secret-wallet/mock_daemon"
+)
+
+// the error is the json rpc error of the call
+func callTestRPC(t *testing.T, client *http.Client, url string, password string, method string, params interface{}, result interface{}) (int, error) {
+	t.Helper()
+
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := http.NewRequest("POST", url+"/json_rpc", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth("user", password)
+
+	res, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return res.StatusCode, nil
+	}
+
+	var response struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+
+	err = json.NewDecoder(res.Body).Decode(&response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if response.Error != nil {
+		return res.StatusCode, fmt.Errorf("%s", response.Error.Message)
+	}
+
+	err = json.Unmarshal(response.Result, result)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return res.StatusCode, nil
+}
+
+func startTestRPCServer(t *testing.T, wallet *Wallet, config RPCServerConfig) *RPCServer {
+	t.Helper()
+
+	config.Bind = "127.0.0.1:0"
+	config.User = "user"
+	config.Password = "pass"
+
+	server, err := wallet.StartRPCServer(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { server.Stop() })
+	return server
+}
+
+// answers every approval and counts them
+func setTestRPCApproval(t *testing.T, approve bool) *int {
+	t.Helper()
+
+	count := 0
+	OnRPCApproval = func(ctx context.Context, request RPCRequest) bool {
+		count++
+		return approve
+	}
+
+	t.Cleanup(func() { OnRPCApproval = nil })
+	return &count
+}
+
+func TestRPCServer(t *testing.T) {
+	wallet := openTestWallet(t, 1000)
+	server := startTestRPCServer(t, wallet, RPCServerConfig{})
+	url := "http://" + server.Addr()
+	setTestRPCApproval(t, true)
+
+	var addrResult rpc.GetAddress_Result
+	status, err := callTestRPC(t, http.DefaultClient, url, "pass", "getaddress", nil, &addrResult)
+	if err != nil || status != http.StatusOK || addrResult.Address != wallet.Info.Addr {
+		t.Fatalf("unexpected address %d %+v %v", status, addrResult, err)
+	}
+
+	status, _ = callTestRPC(t, http.DefaultClient, url, "wrong", "GetAddress", nil, &addrResult)
+	if status != http.StatusUnauthorized {
+		t.Fatalf("expected unauthorized got %d", status)
+	}
+
+	var transferResult rpc.Transfer_Result
+	_, err = callTestRPC(t, http.DefaultClient, url, "pass", "transfer", rpc.Transfer_Params{
+		Transfers: []rpc.Transfer{{Destination: newTestDestination(crypto.ZEROHASH), Amount: 1}},
+		Ringsize:  2,
+	}, &transferResult)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, sent := daemon.GetTx(transferResult.TXID)
+	if !sent {
+		t.Fatalf("tx [%s] was not sent", transferResult.TXID)
+	}
+
+	// stored like the txs sent from the app
+	getTestOutgoingTx(t, wallet, transferResult.TXID)
+
+	stats := server.Status()
+	if !stats.Running || stats.Total != 2 || stats.Recent != 2 || stats.AuthFailed != 1 ||
+		stats.Requests["GetAddress"] != 1 || stats.Requests["Transfer"] != 1 {
+		t.Fatalf("unexpected status %+v", stats)
+	}
+
+	// the port is taken - the error is returned instead of being logged
+	_, err = wallet.StartRPCServer(RPCServerConfig{Bind: server.Addr(), User: "user", Password: "pass"})
+	if err == nil {
+		t.Fatal("expected the address to be in use")
+	}
+
+	err = server.Stop()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = http.Get(url)
+	if err == nil {
+		t.Fatal("expected the server to be stopped")
+	}
+}
+
+func TestRPCSCInvokeTokenDeposit(t *testing.T) {
+	wallet := openTestWallet(t, 100000)
+	scId := crypto.HashHexToHash(strings.Repeat("ab", 32))
+	setTestBalance(t, wallet, scId, 500)
+	daemon.SetRandomAddresses(scId, mock_daemon.RandomAddresses(20))
+	server := startTestRPCServer(t, wallet, RPCServerConfig{})
+	setTestRPCApproval(t, true)
+
+	// the token burn needs a ring member as destination
+	var result rpc.Transfer_Result
+	_, err := callTestRPC(t, http.DefaultClient, "http://"+server.Addr(), "pass", "scinvoke", rpc.SC_Invoke_Params{
+		SC_ID:            scId.String(),
+		SC_RPC:           rpc.Arguments{{Name: "entrypoint", DataType: rpc.DataString, Value: "Deposit"}},
+		SC_TOKEN_Deposit: 50,
+		Ringsize:         2,
+	}, &result)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sentTx, sent := daemon.GetTx(result.TXID)
+	if !sent {
+		t.Fatalf("tx [%s] was not sent", result.TXID)
+	}
+
+	data, _ := hex.DecodeString(sentTx.Hex)
+	var tx transaction.Transaction
+	err = tx.Deserialize(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tx.TransactionType != transaction.SC_TX {
+		t.Fatalf("tx type is %s instead of SC_TX", tx.TransactionType)
+	}
+
+	burned := false
+	for _, payload := range tx.Payloads {
+		if payload.SCID == scId && payload.BurnValue == 50 {
+			burned = true
+		}
+	}
+
+	if !burned {
+		t.Fatal("expected the token deposit to be burned")
+	}
+}
+
+func TestRPCServerTLS(t *testing.T) {
+	wallet := openTestWallet(t, 0)
+	dir := t.TempDir()
+
+	cert, fingerprint, err := LoadRPCCertificate(dir, "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the saved certificate is reused
+	_, sameFingerprint, err := LoadRPCCertificate(dir, "localhost")
+	if err != nil || sameFingerprint != fingerprint {
+		t.Fatalf("expected the same certificate %s %s %v", fingerprint, sameFingerprint, err)
+	}
+
+	// and replaced if it doesn't cover the host
+	_, otherFingerprint, err := LoadRPCCertificate(dir, "192.168.1.5")
+	if err != nil || otherFingerprint == fingerprint {
+		t.Fatalf("expected a new certificate %s %v", otherFingerprint, err)
+	}
+
+	cert, fingerprint, err = LoadRPCCertificate(dir, "192.168.1.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := startTestRPCServer(t, wallet, RPCServerConfig{Certificate: &cert, Fingerprint: fingerprint})
+
+	var peerFingerprint string
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+		InsecureSkipVerify: true,
+		VerifyConnection: func(state tls.ConnectionState) error {
+			sum := sha256.Sum256(state.PeerCertificates[0].Raw)
+			peerFingerprint = hex.EncodeToString(sum[:])
+			return nil
+		},
+	}}}
+
+	var heightResult rpc.GetHeight_Result
+	_, err = callTestRPC(t, client, "https://"+server.Addr(), "pass", "WALLET.GetHeight", nil, &heightResult)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if peerFingerprint != fingerprint {
+		t.Fatalf("unexpected certificate %s instead of %s", peerFingerprint, fingerprint)
+	}
+
+	if !server.Status().TLS {
+		t.Fatal("expected tls status")
+	}
+}
+
+func TestRPCCredentials(t *testing.T) {
+	wallet := openTestWallet(t, 0)
+
+	_, found, err := wallet.GetRPCCredentials()
+	if err != nil || found {
+		t.Fatalf("expected no credentials %v %v", found, err)
+	}
+
+	credentials := RPCCredentials{User: "alice", Password: "secret password", AutoStart: true}
+	err = wallet.StoreRPCCredentials(credentials)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var data string
+	err = wallet.DB.QueryRow(`SELECT data FROM rpc_credentials`).Scan(&data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, _ := hex.DecodeString(data)
+	if strings.Contains(string(raw), credentials.Password) || strings.Contains(data, credentials.User) {
+		t.Fatal("credentials are stored in clear")
+	}
+
+	saved, found, err := wallet.GetRPCCredentials()
+	if err != nil || !found || saved != credentials {
+		t.Fatalf("unexpected credentials %+v %v %v", saved, found, err)
+	}
+
+	err = wallet.DelRPCCredentials()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, found, err = wallet.GetRPCCredentials()
+	if err != nil || found {
+		t.Fatalf("expected the credentials to be removed %v %v", found, err)
+	}
+}
+
+func TestRPCMethodName(t *testing.T) {
+	for method, expected := range map[string]string{
+		"getbalance":       "GetBalance",
+		"WALLET.GetHeight": "GetHeight",
+		"scinvoke":         "SC_Invoke",
+		"DERO.Ping":        "Ping",
+	} {
+		name := RPCMethodName(method)
+		if name != expected {
+			t.Fatal(fmt.Errorf("%s is %s instead of %s", method, name, expected))
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package wallet_manager
+
+import (
+	"fmt"
+	"sort"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/deroproject/derohe/dvm"
+	"github.com/deroproject/derohe/rpc"
+)
+
+type SCBurn struct {
+	SCID   string
+	Amount uint64
+}
+
+// the dvm only accepts calls to functions starting with an uppercase letter
+func IsSCEntrypoint(name string) bool {
+	r, _ := utf8.DecodeRuneInString(name)
+	return r < unicode.MaxASCII && unicode.IsUpper(r)
+}
+
+// exported functions sorted by name
+func SCEntrypoints(code string) ([]dvm.Function, error) {
+	contract, _, err := dvm.ParseSmartContract(code)
+	if err != nil {
+		return nil, err
+	}
+
+	var functions []dvm.Function
+	for name, function := range contract.Functions {
+		if IsSCEntrypoint(name) {
+			functions = append(functions, function)
+		}
+	}
+
+	sort.Slice(functions, func(i, j int) bool {
+		return functions[i].Name < functions[j].Name
+	})
+
+	return functions, nil
+}
+
+// burns are the assets sent to the contract - DERO is read with DEROVALUE() and tokens with ASSETVALUE()
+func SCCallPayload(scId string, function dvm.Function, values map[string]interface{}, burns []SCBurn, destination string) (transfers []rpc.Transfer, scArgs rpc.Arguments, err error) {
+	if !IsSCEntrypoint(function.Name) {
+		err = fmt.Errorf("[%s] is not an exported function", function.Name)
+		return
+	}
+
+	args, err := scFunctionArgs(function, values)
+	if err != nil {
+		return
+	}
+
+	burned := make(map[string]bool)
+	for _, burn := range burns {
+		if burn.Amount == 0 {
+			continue
+		}
+
+		hash := crypto.HashHexToHash(burn.SCID)
+		if hash.String() != burn.SCID {
+			err = fmt.Errorf("invalid burn asset [%s]", burn.SCID)
+			return
+		}
+
+		if burned[burn.SCID] {
+			err = fmt.Errorf("asset [%s] is burned twice", burn.SCID)
+			return
+		}
+
+		burned[burn.SCID] = true
+		transfers = append(transfers, rpc.Transfer{SCID: hash, Destination: destination, Burn: burn.Amount})
+	}
+
+	scArgs = append(scCallArgs(scId, function.Name), args...)
+	return
+}
@@ -0,0 +1,106 @@
+package wallet_manager
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/deroproject/derohe/rpc"
+)
+
+const testConsoleCode = `Function Initialize() Uint64
+10 RETURN 0
+End Function
+
+Function Deposit(memo String, count Uint64, value Uint64) Uint64
+10 RETURN 0
+End Function
+
+Function Withdraw() Uint64
+10 RETURN 0
+End Function
+
+Function store(key String) Uint64
+10 RETURN 0
+End Function`
+
+func TestSCEntrypoints(t *testing.T) {
+	functions, err := SCEntrypoints(testConsoleCode)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, function := range functions {
+		names = append(names, function.Name)
+	}
+
+	if strings.Join(names, ",") != "Deposit,Initialize,Withdraw" {
+		t.Fatalf("unexpected entrypoints %v", names)
+	}
+}
+
+func TestSCCallPayload(t *testing.T) {
+	functions, err := SCEntrypoints(testConsoleCode)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deposit := functions[0]
+	scId := strings.Repeat("c1", 32)
+	token := strings.Repeat("c2", 32)
+	destination := "destination"
+
+	_, _, err = SCCallPayload(scId, deposit, map[string]interface{}{"memo": "hi", "count": "ten"}, nil, destination)
+	if err == nil {
+		t.Fatal("expected a number error")
+	}
+
+	burns := []SCBurn{
+		{SCID: crypto.ZEROHASH.String(), Amount: 50000},
+		{SCID: token, Amount: 0},
+	}
+
+	transfers, scArgs, err := SCCallPayload(scId, deposit, map[string]interface{}{"memo": "hi", "count": "10"}, burns, destination)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(transfers) != 1 || transfers[0].SCID != crypto.ZEROHASH || transfers[0].Burn != 50000 || transfers[0].Destination != destination {
+		t.Fatalf("unexpected transfers %+v", transfers)
+	}
+
+	if scArgs.Value("entrypoint", rpc.DataString) != "Deposit" ||
+		scArgs.Value(rpc.SCID, rpc.DataHash) != crypto.HashHexToHash(scId) ||
+		scArgs.Value("memo", rpc.DataString) != "hi" ||
+		scArgs.Value("count", rpc.DataUint64) != uint64(10) ||
+		scArgs.Has("value", rpc.DataUint64) {
+		t.Fatalf("unexpected sc args %+v", scArgs)
+	}
+
+	burns = append(burns, SCBurn{SCID: crypto.ZEROHASH.String(), Amount: 1})
+	_, _, err = SCCallPayload(scId, deposit, map[string]interface{}{"memo": "hi", "count": "10"}, burns, destination)
+	if err == nil {
+		t.Fatal("expected a duplicated burn error")
+	}
+}
+
+func TestGasEstimate(t *testing.T) {
+	wallet := openTestWallet(t, 0)
+
+	daemon.GasCompute = 1200
+	daemon.GasStorage = 300
+	defer func() {
+		daemon.GasCompute = 0
+		daemon.GasStorage = 0
+	}()
+
+	result, err := wallet.GasEstimate(nil, 2, scCallArgs(strings.Repeat("c1", 32), "Withdraw"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.GasCompute != 1200 || result.GasStorage != 300 {
+		t.Fatalf("unexpected gas estimate %+v", result)
+	}
+}
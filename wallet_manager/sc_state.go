@@ -0,0 +1,190 @@
+package wallet_manager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/deroproject/derohe/rpc"
+	"github.com/secretsystems/secret-wallet/utils"
+)
+
+// a stored contract variable - the daemon returns String values hex encoded
+type SCVariable struct {
+	Key         string
+	Uint64Key   bool
+	Value       string
+	Uint64Value bool
+}
+
+func newSCVariable(key string, uint64Key bool, value interface{}) (SCVariable, error) {
+	variable := SCVariable{Key: key, Uint64Key: uint64Key}
+	switch v := value.(type) {
+	case string:
+		variable.Value = v
+	case json.Number:
+		// a float64 can't hold every uint64
+		value, err := strconv.ParseUint(v.String(), 10, 64)
+		if err != nil {
+			return variable, fmt.Errorf("invalid uint64 value for [%s]", key)
+		}
+
+		variable.Value = strconv.FormatUint(value, 10)
+		variable.Uint64Value = true
+	case uint64:
+		variable.Value = strconv.FormatUint(v, 10)
+		variable.Uint64Value = true
+	default:
+		return variable, fmt.Errorf("unknown value type for [%s]", key)
+	}
+
+	return variable, nil
+}
+
+// compressed public keys are shown as addresses and printable text as a string
+// anything else is left hex encoded
+func (v SCVariable) Decoded() string {
+	if v.Uint64Value {
+		return v.Value
+	}
+
+	if len(v.Value) == 66 {
+		addr, err := utils.DecodeAddress(v.Value)
+		if err == nil {
+			return addr
+		}
+	}
+
+	decoded, err := utils.DecodeString(v.Value)
+	if err != nil || !isPrintable(decoded) {
+		return v.Value
+	}
+
+	return decoded
+}
+
+func (v SCVariable) Equal(other SCVariable) bool {
+	return v.Value == other.Value && v.Uint64Value == other.Uint64Value
+}
+
+func isPrintable(value string) bool {
+	if !utf8.ValidString(value) {
+		return false
+	}
+
+	for _, r := range value {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// the variables are not cached so the browser always shows the current state
+// string keys come first sorted by name then uint64 keys in numeric order
+func GetSCVariables(scId string) ([]SCVariable, error) {
+	client := RPC_Client.RPC
+	if client == nil {
+		return nil, ErrNoNode
+	}
+
+	var data json.RawMessage
+	err := client.CallResult(context.Background(), "DERO.GetSC", rpc.GetSC_Params{
+		SCID:      scId,
+		Variables: true,
+	}, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	// the uint64 values are decoded as numbers instead of float64
+	var result rpc.GetSC_Result
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	err = decoder.Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+
+	var variables []SCVariable
+	for key, value := range result.VariableStringKeys {
+		variable, err := newSCVariable(key, false, value)
+		if err != nil {
+			return nil, err
+		}
+
+		variables = append(variables, variable)
+	}
+
+	for key, value := range result.VariableUint64Keys {
+		variable, err := newSCVariable(strconv.FormatUint(key, 10), true, value)
+		if err != nil {
+			return nil, err
+		}
+
+		variables = append(variables, variable)
+	}
+
+	sort.Slice(variables, func(i, j int) bool {
+		a, b := variables[i], variables[j]
+		if a.Uint64Key != b.Uint64Key {
+			return !a.Uint64Key
+		}
+
+		if a.Uint64Key {
+			x, _ := strconv.ParseUint(a.Key, 10, 64)
+			y, _ := strconv.ParseUint(b.Key, 10, 64)
+			return x < y
+		}
+
+		return a.Key < b.Key
+	})
+
+	return variables, nil
+}
+
+// search matches the key, the raw value or the decoded value without case
+// pages start at 0 and the returned page is clamped to the last one
+func FilterSCVariables(variables []SCVariable, search string, page int, pageSize int) (result []SCVariable, currentPage int, pageCount int) {
+	search = strings.ToLower(strings.TrimSpace(search))
+
+	var filtered []SCVariable
+	for _, variable := range variables {
+		if search == "" ||
+			strings.Contains(strings.ToLower(variable.Key), search) ||
+			strings.Contains(strings.ToLower(variable.Value), search) ||
+			strings.Contains(strings.ToLower(variable.Decoded()), search) {
+			filtered = append(filtered, variable)
+		}
+	}
+
+	pageCount = (len(filtered) + pageSize - 1) / pageSize
+	if pageCount == 0 {
+		return nil, 0, 0
+	}
+
+	currentPage = page
+	if currentPage >= pageCount {
+		currentPage = pageCount - 1
+	}
+
+	if currentPage < 0 {
+		currentPage = 0
+	}
+
+	start := currentPage * pageSize
+	end := start + pageSize
+	if end > len(filtered) {
+		end = len(filtered)
+	}
+
+	result = filtered[start:end]
+	return
+}
@@ -0,0 +1,183 @@
+package wallet_manager
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/deroproject/derohe/rpc"
+)
+
+func TestSCVariableDecoded(t *testing.T) {
+	addr := "dero1qyvqpdftj8r6005xs20rnflakmwa5pdxg9vcjzdcuywq2t8skqhvwqglt6x0g"
+
+	tests := []struct {
+		variable SCVariable
+		expected string
+	}{
+		{SCVariable{Value: "42", Uint64Value: true}, "42"},
+		{SCVariable{Value: encodeTestString("hello")}, "hello"},
+		{SCVariable{Value: encodeTestAddress(t, addr)}, addr},
+		{SCVariable{Value: "00ff"}, "00ff"},
+		{SCVariable{Value: "not hex"}, "not hex"},
+	}
+
+	for _, test := range tests {
+		decoded := test.variable.Decoded()
+		if decoded != test.expected {
+			t.Fatalf("expected [%s] got [%s]", test.expected, decoded)
+		}
+	}
+}
+
+func TestGetSCVariables(t *testing.T) {
+	scId := strings.Repeat("d1", 32)
+	daemon.SetSC(scId, rpc.GetSC_Result{
+		VariableStringKeys: map[string]interface{}{
+			"name":  encodeTestString("Test"),
+			"count": uint64(7),
+			"max":   uint64(18446744073709551615),
+		},
+		VariableUint64Keys: map[uint64]interface{}{
+			10: encodeTestString("ten"),
+			2:  uint64(2),
+		},
+	})
+
+	variables, err := GetSCVariables(scId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var keys []string
+	for _, variable := range variables {
+		keys = append(keys, fmt.Sprintf("%s=%s", variable.Key, variable.Decoded()))
+	}
+
+	if strings.Join(keys, ",") != "count=7,max=18446744073709551615,name=Test,2=2,10=ten" {
+		t.Fatalf("unexpected variables %v", keys)
+	}
+
+	if !variables[0].Uint64Value || variables[2].Uint64Value || !variables[3].Uint64Key || variables[2].Uint64Key {
+		t.Fatalf("unexpected types %+v", variables)
+	}
+}
+
+func TestFilterSCVariables(t *testing.T) {
+	var variables []SCVariable
+	for i := 0; i < 25; i++ {
+		variables = append(variables, SCVariable{Key: fmt.Sprintf("key_%02d", i), Value: encodeTestString(fmt.Sprintf("value %d", i))})
+	}
+
+	result, page, pageCount := FilterSCVariables(variables, "", 2, 10)
+	if len(result) != 5 || page != 2 || pageCount != 3 || result[0].Key != "key_20" {
+		t.Fatalf("unexpected page %d/%d %+v", page, pageCount, result)
+	}
+
+	// out of range pages are clamped
+	result, page, _ = FilterSCVariables(variables, "", 9, 10)
+	if page != 2 || len(result) != 5 {
+		t.Fatalf("unexpected page %d %+v", page, result)
+	}
+
+	// the decoded value is searched
+	result, _, pageCount = FilterSCVariables(variables, "VALUE 1", 0, 10)
+	if len(result) != 10 || pageCount != 2 {
+		t.Fatalf("unexpected search result %d %+v", pageCount, result)
+	}
+
+	result, page, pageCount = FilterSCVariables(variables, "missing", 0, 10)
+	if len(result) != 0 || page != 0 || pageCount != 0 {
+		t.Fatalf("unexpected empty search %d/%d %+v", page, pageCount, result)
+	}
+}
+
+func TestPollSCWatchlist(t *testing.T) {
+	wallet := openTestWallet(t, 0)
+
+	scId := strings.Repeat("d2", 32)
+	daemon.SetSC(scId, rpc.GetSC_Result{
+		VariableStringKeys: map[string]interface{}{
+			"owner": encodeTestString("alice"),
+		},
+	})
+
+	err := wallet.AddSCWatch(scId, "owner", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = wallet.AddSCWatch(scId, "5", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// added twice is ignored
+	err = wallet.AddSCWatch(scId, "owner", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changed, err := wallet.PollSCWatchlist()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if changed != 1 {
+		t.Fatalf("expected the first owner value got %d changes", changed)
+	}
+
+	changed, err = wallet.PollSCWatchlist()
+	if err != nil || changed != 0 {
+		t.Fatalf("expected no changes got %d %v", changed, err)
+	}
+
+	daemon.SetSC(scId, rpc.GetSC_Result{
+		VariableStringKeys: map[string]interface{}{
+			"owner": encodeTestString("bob"),
+		},
+		VariableUint64Keys: map[uint64]interface{}{
+			5: uint64(100),
+		},
+	})
+
+	changed, err = wallet.PollSCWatchlist()
+	if err != nil || changed != 2 {
+		t.Fatalf("expected 2 changes got %d %v", changed, err)
+	}
+
+	watches, err := wallet.GetSCWatchlist()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(watches) != 2 {
+		t.Fatalf("unexpected watches %+v", watches)
+	}
+
+	owner := watches[0]
+	if owner.Key != "owner" || owner.Variable() == nil || owner.Variable().Decoded() != "bob" || owner.CheckedTimestamp == 0 {
+		t.Fatalf("unexpected owner watch %+v", owner)
+	}
+
+	changes, err := wallet.GetSCWatchChanges(owner.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(changes) != 2 ||
+		changes[0].OldValue.String != "alice" || changes[0].NewValue.String != "bob" ||
+		changes[1].OldValue.Valid || changes[1].NewValue.String != "alice" {
+		t.Fatalf("unexpected changes %+v", changes)
+	}
+
+	err = wallet.DelSCWatch(owner.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err = wallet.GetSCWatchChanges(owner.ID)
+	if err != nil || len(changes) != 0 {
+		t.Fatalf("expected the changes to be removed %+v %v", changes, err)
+	}
+}
@@ -0,0 +1,283 @@
+package wallet_manager
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/deroproject/derohe/walletapi"
+)
+
+const SC_WATCHLIST_POLL_INTERVAL = 60 * time.Second
+
+// contract variables followed by the wallet - value is the last one seen or NULL if the key is not set
+// every change is stored decoded in sc_watchlist_changes to keep the history readable
+func initDatabaseSCWatchlist(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS sc_watchlist (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			sc_id VARCHAR,
+			key VARCHAR,
+			uint64_key BOOLEAN,
+			value VARCHAR,
+			uint64_value BOOLEAN,
+			checked_timestamp BIGINT,
+			UNIQUE (sc_id,key,uint64_key)
+		);
+
+		CREATE TABLE IF NOT EXISTS sc_watchlist_changes (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			watch_id INTEGER,
+			old_value VARCHAR,
+			new_value VARCHAR,
+			timestamp BIGINT
+		);
+	`)
+	return err
+}
+
+type SCWatch struct {
+	ID               int64
+	SCID             string
+	Key              string
+	Uint64Key        bool
+	Value            sql.NullString
+	Uint64Value      bool
+	CheckedTimestamp int64
+}
+
+// nil if the key is not set in the contract
+func (watch SCWatch) Variable() *SCVariable {
+	if !watch.Value.Valid {
+		return nil
+	}
+
+	return &SCVariable{
+		Key:         watch.Key,
+		Uint64Key:   watch.Uint64Key,
+		Value:       watch.Value.String,
+		Uint64Value: watch.Uint64Value,
+	}
+}
+
+type SCWatchChange struct {
+	ID        int64
+	WatchID   int64
+	OldValue  sql.NullString
+	NewValue  sql.NullString
+	Timestamp int64
+}
+
+func (w *Wallet) AddSCWatch(scId string, key string, uint64Key bool) error {
+	_, err := w.DB.Exec(`
+		INSERT OR IGNORE INTO sc_watchlist (sc_id,key,uint64_key,uint64_value,checked_timestamp)
+		VALUES (?,?,?,false,0);
+	`, scId, key, uint64Key)
+	return err
+}
+
+func (w *Wallet) DelSCWatch(id int64) error {
+	_, err := w.DB.Exec(`
+		DELETE FROM sc_watchlist_changes
+		WHERE watch_id = ?;
+	`, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.DB.Exec(`
+		DELETE FROM sc_watchlist
+		WHERE id = ?;
+	`, id)
+	return err
+}
+
+func (w *Wallet) GetSCWatchlist() ([]SCWatch, error) {
+	query := sq.Select("id", "sc_id", "key", "uint64_key", "value", "uint64_value", "checked_timestamp").
+		From("sc_watchlist").
+		OrderBy("sc_id ASC", "id ASC")
+
+	rows, err := query.RunWith(w.DB).Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var watches []SCWatch
+	for rows.Next() {
+		var watch SCWatch
+		err = rows.Scan(
+			&watch.ID, &watch.SCID, &watch.Key, &watch.Uint64Key,
+			&watch.Value, &watch.Uint64Value, &watch.CheckedTimestamp,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		watches = append(watches, watch)
+	}
+
+	return watches, nil
+}
+
+// latest first
+func (w *Wallet) GetSCWatchChanges(watchId int64) ([]SCWatchChange, error) {
+	query := sq.Select("id", "watch_id", "old_value", "new_value", "timestamp").
+		From("sc_watchlist_changes").
+		Where(sq.Eq{"watch_id": watchId}).
+		OrderBy("id DESC")
+
+	rows, err := query.RunWith(w.DB).Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []SCWatchChange
+	for rows.Next() {
+		var change SCWatchChange
+		err = rows.Scan(&change.ID, &change.WatchID, &change.OldValue, &change.NewValue, &change.Timestamp)
+		if err != nil {
+			return nil, err
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+// each contract is fetched once per poll - a contract failing to load doesn't stop the others
+// the first value seen is recorded as a change from NULL
+func (w *Wallet) PollSCWatchlist() (changed int, err error) {
+	watches, err := w.GetSCWatchlist()
+	if err != nil {
+		return
+	}
+
+	states := make(map[string]map[string]SCVariable)
+	var pollErr error
+	for _, watch := range watches {
+		state, ok := states[watch.SCID]
+		if !ok {
+			state, err = fetchSCWatchState(watch.SCID)
+			states[watch.SCID] = state
+			if err != nil {
+				pollErr = err
+				continue
+			}
+		}
+
+		if state == nil {
+			continue
+		}
+
+		var current *SCVariable
+		variable, ok := state[scWatchStateKey(watch.Key, watch.Uint64Key)]
+		if ok {
+			current = &variable
+		}
+
+		previous := watch.Variable()
+		same := (previous == nil && current == nil) ||
+			(previous != nil && current != nil && previous.Equal(*current))
+
+		err = w.updateSCWatch(watch, previous, current, !same)
+		if err != nil {
+			return
+		}
+
+		if !same {
+			changed++
+		}
+	}
+
+	err = pollErr
+	return
+}
+
+func (w *Wallet) updateSCWatch(watch SCWatch, previous *SCVariable, current *SCVariable, record bool) error {
+	now := time.Now().UnixMilli()
+
+	var value sql.NullString
+	uint64Value := false
+	if current != nil {
+		value = sql.NullString{String: current.Value, Valid: true}
+		uint64Value = current.Uint64Value
+	}
+
+	if record {
+		var oldValue, newValue sql.NullString
+		if previous != nil {
+			oldValue = sql.NullString{String: previous.Decoded(), Valid: true}
+		}
+
+		if current != nil {
+			newValue = sql.NullString{String: current.Decoded(), Valid: true}
+		}
+
+		_, err := w.DB.Exec(`
+			INSERT INTO sc_watchlist_changes (watch_id,old_value,new_value,timestamp)
+			VALUES (?,?,?,?);
+		`, watch.ID, oldValue, newValue, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := w.DB.Exec(`
+		UPDATE sc_watchlist
+		SET value = ?, uint64_value = ?, checked_timestamp = ?
+		WHERE id = ?;
+	`, value, uint64Value, now, watch.ID)
+	return err
+}
+
+func scWatchStateKey(key string, uint64Key bool) string {
+	if uint64Key {
+		return "u:" + key
+	}
+
+	return "s:" + key
+}
+
+// checks the watchlist until the wallet is closed - onChange is called from the polling goroutine
+func (w *Wallet) StartSCWatchlistPolling(onChange func(changed int)) {
+	go func() {
+		for {
+			select {
+			case <-w.Memory.Quit:
+				return
+			case <-time.After(SC_WATCHLIST_POLL_INTERVAL):
+			}
+
+			if !walletapi.Connected {
+				continue
+			}
+
+			changed, err := w.PollSCWatchlist()
+			if err != nil {
+				fmt.Println(err)
+			}
+
+			if changed > 0 && onChange != nil {
+				onChange(changed)
+			}
+		}
+	}()
+}
+
+func fetchSCWatchState(scId string) (map[string]SCVariable, error) {
+	variables, err := GetSCVariables(scId)
+	if err != nil {
+		return nil, err
+	}
+
+	state := make(map[string]SCVariable)
+	for _, variable := range variables {
+		state[scWatchStateKey(variable.Key, variable.Uint64Key)] = variable
+	}
+
+	return state, nil
+}
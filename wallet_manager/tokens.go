@@ -129,7 +129,7 @@ func (token *Token) GetImageOp() (imgOp paint.ImageOp, err error) {
 
 		var imgData []byte
 		var exists bool
-		exists, err = caching.Get(relCachePath, cacheFileName, &imgData)
+		exists, err = caching.Get(caching.IMAGES, relCachePath, cacheFileName, &imgData)
 		if err != nil {
 			return
 		}
@@ -148,7 +148,7 @@ func (token *Token) GetImageOp() (imgOp paint.ImageOp, err error) {
 				return
 			}
 
-			err = caching.Store(relCachePath, cacheFileName, imgData)
+			err = caching.Store(caching.IMAGES, relCachePath, cacheFileName, imgData)
 			if err != nil {
 				return
 			}
@@ -263,31 +263,71 @@ func (token *Token) Parse(scId string, scResult rpc.GetSC_Result) error {
 	return nil
 }
 
+// The code is cached forever and the variables for a short time so supply, owner and metadata changes are picked up.
+// cached is true only if both were read from the cache.
 func GetSC(scId string) (result rpc.GetSC_Result, cached bool, err error) {
-	cacheFileName := "get_sc"
-	relCachePath := filepath.Join("tokens", scId)
-	cached, err = caching.Get(relCachePath, cacheFileName, &result)
+	relCachePath := scId
+
+	var code string
+	codeCached, err := caching.Get(caching.SC_CODE, relCachePath, "code", &code)
+	if err != nil {
+		return
+	}
+
+	varsCached, err := caching.Get(caching.SC_VARIABLES, relCachePath, "get_sc", &result)
 	if err != nil {
 		return
 	}
 
-	if cached {
+	if codeCached && varsCached {
+		result.Code = code
+		cached = true
 		return
 	}
 
+	result = rpc.GetSC_Result{}
 	err = RPC_Client.RPC.CallResult(context.Background(), "DERO.GetSC", rpc.GetSC_Params{
 		SCID:      scId,
 		Variables: true,
-		Code:      true,
+		Code:      !codeCached,
 	}, &result)
 	if err != nil {
 		return
 	}
 
-	err = caching.Store(relCachePath, cacheFileName, result)
+	if codeCached {
+		result.Code = code
+	} else {
+		err = caching.Store(caching.SC_CODE, relCachePath, "code", result.Code)
+		if err != nil {
+			return
+		}
+	}
+
+	variables := result
+	variables.Code = ""
+	err = caching.Store(caching.SC_VARIABLES, relCachePath, "get_sc", variables)
 	return
 }
 
+// next GetSC call reads the variables from the node and the token image is fetched again
+func ClearSCCache(scId string) error {
+	err := caching.Clear(caching.SC_VARIABLES, scId)
+	if err != nil {
+		return err
+	}
+
+	err = caching.Clear(caching.IMAGES, filepath.Join("tokens", scId))
+	if err != nil {
+		return err
+	}
+
+	imageMemCacheMutex.Lock()
+	delete(imageMemCache, scId)
+	imageMemCacheMutex.Unlock()
+	return nil
+}
+
 func GetTokenBySCID(scId string) (token *Token, err error) {
 	if scId == crypto.ZEROHASH.String() {
 		token = DeroToken()
@@ -0,0 +1,243 @@
+package wallet_manager
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/deroproject/derohe/rpc"
+	"github.com/secretsystems/secret-wallet/mock_daemon"
+	"github.com/secretsystems/secret-wallet/sc"
+	"github.com/secretsystems/secret-wallet/sc/dex_sc"
+	"github.com/secretsystems/secret-wallet/sc/g45_sc"
+)
+
+func nullInt64(value int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: value, Valid: true}
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: true}
+}
+
+func encodeTestString(value string) string {
+	return hex.EncodeToString([]byte(value))
+}
+
+func encodeTestAddress(t *testing.T, value string) string {
+	addr, err := rpc.NewAddress(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return hex.EncodeToString(addr.PublicKey.EncodeCompressed())
+}
+
+// we don't ship the contract sources so the standard hash is replaced by the hash of a fake code
+func useTestCode(t *testing.T, standardHash *string, code string) string {
+	hash := sha256.Sum256([]byte(code))
+	previous := *standardHash
+	*standardHash = hex.EncodeToString(hash[:])
+	t.Cleanup(func() { *standardHash = previous })
+	return code
+}
+
+func TestTokenParse(t *testing.T) {
+	minter := mock_daemon.RandomAddress()
+
+	tests := []struct {
+		scType    sc.SCType
+		code      string
+		variables map[string]interface{}
+		expected  Token
+	}{
+		{
+			scType: sc.G45_NFT_TYPE,
+			code:   useTestCode(t, &g45_sc.G45_NFT_PRIVATE_SHA256, "// G45-NFT"),
+			variables: map[string]interface{}{
+				"timestamp":      float64(1690000000),
+				"collection":     encodeTestString("collection_scid"),
+				"metadataFormat": encodeTestString("json"),
+				"metadata":       encodeTestString(`{"name":"Dero Seal #1","image":"ipfs://seal"}`),
+				"owner":          encodeTestString(minter),
+				"minter":         encodeTestAddress(t, minter),
+			},
+			expected: Token{Name: "Dero Seal #1", Decimals: 0, MaxSupply: nullInt64(1), ImageUrl: nullString("ipfs://seal")},
+		},
+		{
+			scType: sc.G45_AT_TYPE,
+			code:   useTestCode(t, &g45_sc.G45_AT_PRIVATE_SHA256, "// G45-AT"),
+			variables: map[string]interface{}{
+				"timestamp":        float64(1690000000),
+				"collection":       encodeTestString(""),
+				"frozenMetadata":   float64(0),
+				"frozenMint":       float64(0),
+				"frozenCollection": float64(1),
+				"metadataFormat":   encodeTestString("json"),
+				"metadata":         encodeTestString(`{"name":"Asset","symbol":"AST","image":"https://img"}`),
+				"maxSupply":        float64(1000),
+				"totalSupply":      float64(10),
+				"decimals":         float64(2),
+				"minter":           encodeTestAddress(t, minter),
+				"originalMinter":   encodeTestAddress(t, minter),
+				"owner_" + minter:  float64(10),
+			},
+			expected: Token{Name: "Asset", Decimals: 2, MaxSupply: nullInt64(1000), ImageUrl: nullString("https://img"), Symbol: nullString("AST")},
+		},
+		{
+			scType: sc.G45_FAT_TYPE,
+			code:   useTestCode(t, &g45_sc.G45_FAT_PRIVATE_SHA256, "// G45-FAT"),
+			variables: map[string]interface{}{
+				"timestamp":         float64(1690000000),
+				"collection":        encodeTestString(""),
+				"frozenMetadata":    float64(1),
+				"frozenCollection":  float64(0),
+				"metadataFormat":    encodeTestString("json"),
+				"metadata":          encodeTestString(`{"name":"Fat","symbol":"FAT","image":"https://fat"}`),
+				"maxSupply":         float64(500),
+				"totalSupply":       float64(500),
+				"decimals":          float64(5),
+				"minter":            encodeTestAddress(t, minter),
+				"owner_" + minter:   float64(400),
+				"owner_someoneelse": float64(100),
+			},
+			expected: Token{Name: "Fat", Decimals: 5, MaxSupply: nullInt64(500), ImageUrl: nullString("https://fat"), Symbol: nullString("FAT")},
+		},
+		{
+			scType: sc.G45_C_TYPE,
+			code:   useTestCode(t, &g45_sc.G45_C_SHA256, "// G45-C"),
+			variables: map[string]interface{}{
+				"metadata": encodeTestString(`{"name":"Collection"}`),
+			},
+			// a collection is not a token - only the type is set
+			expected: Token{},
+		},
+		{
+			scType: sc.DEX_SC_TYPE,
+			code:   useTestCode(t, &dex_sc.DEX_SC_SHA256, "// DEX-SC"),
+			variables: map[string]interface{}{
+				"name":            encodeTestString("Dero Wrapped Ether"),
+				"decimals":        float64(9),
+				"image_url":       encodeTestString("https://weth"),
+				"symbol":          encodeTestString("DWETH"),
+				"totalsupply":     float64(1000000),
+				"native_symbol":   encodeTestString("WETH"),
+				"native_decimals": float64(18),
+				"quorum":          float64(2),
+				"numTrustees":     float64(3),
+				"version":         encodeTestString("1.0.0"),
+				"bridgeOpen":      float64(1),
+				"bridgeFee":       float64(100),
+			},
+			expected: Token{Name: "Dero Wrapped Ether", Decimals: 9, ImageUrl: nullString("https://weth"), Symbol: nullString("DWETH")},
+		},
+		{
+			scType: sc.UNKNOWN_TYPE,
+			code:   "Function Initialize() Uint64\n10 RETURN 0\nEnd Function",
+			variables: map[string]interface{}{
+				"name":   encodeTestString("Unknown"),
+				"image":  encodeTestString("https://unknown"),
+				"symbol": encodeTestString("UNK"),
+			},
+			expected: Token{Name: "Unknown", ImageUrl: nullString("https://unknown"), Symbol: nullString("UNK")},
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(string(test.scType), func(t *testing.T) {
+			// use the mock daemon so the variables are json decoded like the real ones
+			scId := strings.Repeat(hex.EncodeToString([]byte{byte(i + 1)}), 32)
+			daemon.SetSC(scId, rpc.GetSC_Result{Code: test.code, VariableStringKeys: test.variables})
+
+			token, err := GetTokenBySCID(scId)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if token.StandardType != test.scType {
+				t.Fatalf("type is %s instead of %s", token.StandardType, test.scType)
+			}
+
+			if token.SCID != scId {
+				t.Fatalf("scid is %s instead of %s", token.SCID, scId)
+			}
+
+			if token.Name != test.expected.Name ||
+				token.Decimals != test.expected.Decimals ||
+				token.MaxSupply != test.expected.MaxSupply ||
+				token.ImageUrl != test.expected.ImageUrl ||
+				token.Symbol != test.expected.Symbol {
+				t.Fatalf("unexpected token %+v", token)
+			}
+
+			if !token.AddedTimestamp.Valid {
+				t.Fatal("added timestamp is not set")
+			}
+
+			// second time comes from the cache
+			calls := daemon.Calls("DERO.GetSC")
+			_, cached, err := GetSC(scId)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cached || daemon.Calls("DERO.GetSC") != calls {
+				t.Fatal("sc result was not cached")
+			}
+		})
+	}
+}
+
+func TestTokenParseInvalidMetadata(t *testing.T) {
+	code := useTestCode(t, &g45_sc.G45_NFT_PRIVATE_SHA256, "// G45-NFT")
+	minter := mock_daemon.RandomAddress()
+
+	token := Token{}
+	err := token.Parse(strings.Repeat("ff", 32), rpc.GetSC_Result{
+		Code: code,
+		VariableStringKeys: map[string]interface{}{
+			"timestamp":      float64(0),
+			"collection":     encodeTestString(""),
+			"metadataFormat": encodeTestString("json"),
+			"metadata":       encodeTestString("not json"),
+			"owner":          encodeTestString(minter),
+			"minter":         encodeTestAddress(t, minter),
+		},
+	})
+	if err == nil {
+		t.Fatal("expected a metadata error")
+	}
+}
+
+func TestGetSCCacheInvalidation(t *testing.T) {
+	scId := strings.Repeat("ab", 32)
+	daemon.SetSC(scId, rpc.GetSC_Result{Code: "// code", VariableStringKeys: map[string]interface{}{"name": encodeTestString("Before")}})
+
+	_, cached, err := GetSC(scId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cached {
+		t.Fatal("first call should not be cached")
+	}
+
+	// the code is never fetched again since a deployed contract can't change
+	daemon.SetSC(scId, rpc.GetSC_Result{Code: "// other code", VariableStringKeys: map[string]interface{}{"name": encodeTestString("After")}})
+
+	err = ClearSCCache(scId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, cached, err := GetSC(scId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cached || result.Code != "// code" || result.VariableStringKeys["name"] != encodeTestString("After") {
+		t.Fatalf("unexpected sc result %+v", result)
+	}
+}
@@ -2,12 +2,14 @@ package wallet_manager
 
 import (
 	"database/sql"
-	"math"
-	"sort"
-	"sync"
+	"encoding/json"
+	"fmt"
+	"strings"
 
+	sq "github.com/Masterminds/squirrel"
 	"github.com/deroproject/derohe/cryptography/crypto"
 	"github.com/deroproject/derohe/rpc"
+	"github.com/secretsystems/secret-wallet/app_db/schema_version"
 )
 
 type Entry struct {
@@ -28,141 +30,442 @@ type GetEntriesParams struct {
 	Receiver                 sql.NullString
 	BurnGreaterOrEqualThan   sql.NullInt64
 	AmountGreaterOrEqualThan sql.NullInt64
+	AmountLessOrEqualThan    sql.NullInt64
+	DateFrom                 sql.NullTime
+	DateTo                   sql.NullTime
+	DstPort                  *uint64
+	DstPortFrom              *uint64
+	DstPortTo                *uint64
+	Search                   sql.NullString // comment, payload args, txid and addresses
 	TXID                     sql.NullString
 	BlockHash                sql.NullString
 	SC_CALL                  *SCCallParams
+	Or                       []GetEntriesParams // at least one group must match - offset and limit are ignored
 	Offset                   sql.NullInt64
 	Limit                    sql.NullInt64
 }
 
-func filterEntries(allEntries []rpc.Entry, start, end int, entrySCID crypto.Hash, params GetEntriesParams, entryChan chan<- Entry, wg *sync.WaitGroup) {
-	defer wg.Done()
+// entries of EntriesNative mirrored in the data.db
+// idx is the position of the entry in the EntriesNative slice of the scid
+func initDatabaseEntries(db *sql.DB) error {
+	version, err := schema_version.GetVersion(db, "entries")
+	if err != nil {
+		return err
+	}
 
-	for i := start; i < end; i++ {
-		e := allEntries[i]
-		add := true
+	if version == 0 {
+		_, err = db.Exec(`
+			CREATE TABLE IF NOT EXISTS entries (
+				sc_id VARCHAR,
+				idx INTEGER,
+				tx_id VARCHAR,
+				block_hash VARCHAR,
+				height BIGINT,
+				topo_height BIGINT,
+				pos INTEGER,
+				time BIGINT,
+				direction VARCHAR,
+				sender VARCHAR,
+				destination VARCHAR,
+				amount VARCHAR,
+				burn VARCHAR,
+				fees VARCHAR,
+				src_port VARCHAR,
+				dst_port VARCHAR,
+				comment VARCHAR,
+				sc_call_id VARCHAR,
+				sc_call_entrypoint VARCHAR,
+				payload VARCHAR,
+				data BLOB,
+				PRIMARY KEY (sc_id, idx)
+			);
 
-		if params.Coinbase.Valid {
-			add = e.Coinbase == params.Coinbase.Bool
+			CREATE INDEX IF NOT EXISTS entries_time ON entries (sc_id, time);
+			CREATE INDEX IF NOT EXISTS entries_tx_id ON entries (tx_id);
+			CREATE INDEX IF NOT EXISTS entries_direction ON entries (direction);
+			CREATE INDEX IF NOT EXISTS entries_sender ON entries (sender);
+			CREATE INDEX IF NOT EXISTS entries_destination ON entries (destination);
+			CREATE INDEX IF NOT EXISTS entries_dst_port ON entries (dst_port);
+			CREATE INDEX IF NOT EXISTS entries_sc_call ON entries (sc_call_id, sc_call_entrypoint);
+		`)
+		if err != nil {
+			return err
 		}
 
-		if params.In.Valid {
-			add = e.Incoming == params.In.Bool
+		version = 1
+		err = schema_version.StoreVersion(db, "entries", version)
+		if err != nil {
+			return err
 		}
+	}
 
-		if params.Out.Valid {
-			add = (!e.Incoming && !e.Coinbase) == params.Out.Bool
-		}
+	return nil
+}
 
-		if params.Sender.Valid {
-			add = e.Sender == params.Sender.String
-		}
+// sqlite integers are signed so uint64 values are stored as zero padded text
+// the padding keeps the ordering and comparisons of numbers
+func formatEntryNumber(value uint64) string {
+	return fmt.Sprintf("%020d", value)
+}
 
-		if params.Receiver.Valid {
-			add = e.Destination == params.Receiver.String
-		}
+func formatEntryMinNumber(value int64) string {
+	if value < 0 {
+		value = 0
+	}
+
+	return formatEntryNumber(uint64(value))
+}
+
+func entryDirection(e rpc.Entry) string {
+	if e.Coinbase {
+		return "coinbase"
+	}
+
+	if e.Incoming {
+		return "in"
+	}
+
+	return "out"
+}
 
-		if params.AmountGreaterOrEqualThan.Valid {
-			add = e.Amount >= uint64(params.AmountGreaterOrEqualThan.Int64)
+func entryArgString(e rpc.Entry, name string) sql.NullString {
+	for _, arg := range e.Payload_RPC {
+		if arg.Name != name {
+			continue
 		}
 
-		if params.BurnGreaterOrEqualThan.Valid {
-			add = e.Burn >= uint64(params.BurnGreaterOrEqualThan.Int64)
+		switch value := arg.Value.(type) {
+		case string:
+			return sql.NullString{String: value, Valid: true}
+		case crypto.Hash:
+			return sql.NullString{String: value.String(), Valid: true}
 		}
+	}
+
+	return sql.NullString{}
+}
+
+// payload arg values for the text search
+func entryPayloadText(e rpc.Entry) string {
+	var values []string
+	for _, arg := range e.Payload_RPC {
+		values = append(values, fmt.Sprint(arg.Value))
+	}
+
+	return strings.Join(values, " ")
+}
 
-		if params.TXID.Valid {
-			add = e.TXID == params.TXID.String
+func rowsScanEntries(rows *sql.Rows) ([]Entry, error) {
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		var scId string
+		var data []byte
+		err := rows.Scan(&scId, &data)
+		if err != nil {
+			return nil, err
 		}
 
-		if params.BlockHash.Valid {
-			add = e.BlockHash == params.BlockHash.String
+		var entry Entry
+		err = json.Unmarshal(data, &entry.Entry)
+		if err != nil {
+			return nil, err
 		}
 
-		if params.SC_CALL != nil {
-			add = false
-			for _, arg := range e.Payload_RPC {
-				if params.SC_CALL.SCID.Valid && arg.Name == "SC_ID" {
-					scId, ok := arg.Value.(string)
-					if ok && scId == params.SC_CALL.SCID.String {
-						add = true
-					}
-				}
+		entry.SCID = crypto.HashHexToHash(scId)
+		entries = append(entries, entry)
+	}
 
-				if params.SC_CALL.Entrypoint.Valid && arg.Name == "entrypoint" {
-					entrypoint, ok := arg.Value.(string)
-					if ok && entrypoint == params.SC_CALL.Entrypoint.String {
-						add = true
-						break
-					}
-				}
-			}
-		}
+	err := rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
 
-		if add {
-			entry := Entry{Entry: e, SCID: entrySCID}
-			entryChan <- entry
+// last synced entry of a scid
+type entryPosition struct {
+	Idx        int
+	TXID       string
+	TopoHeight int64
+	Pos        int
+}
+
+func (w *Wallet) getLastEntryPositions() (map[crypto.Hash]entryPosition, error) {
+	rows, err := w.DB.Query(`
+		SELECT e.sc_id, e.idx, e.tx_id, e.topo_height, e.pos FROM entries e
+		INNER JOIN (
+			SELECT sc_id, MAX(idx) AS idx FROM entries
+			GROUP BY sc_id
+		) l ON e.sc_id = l.sc_id AND e.idx = l.idx;
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	positions := make(map[crypto.Hash]entryPosition)
+	for rows.Next() {
+		var scId string
+		var position entryPosition
+		err = rows.Scan(&scId, &position.Idx, &position.TXID, &position.TopoHeight, &position.Pos)
+		if err != nil {
+			return nil, err
 		}
+
+		positions[crypto.HashHexToHash(scId)] = position
 	}
+
+	return positions, rows.Err()
 }
 
-func (w *Wallet) GetEntries(SCID *crypto.Hash, params GetEntriesParams) []Entry {
-	w.Memory.Lock()
-	defer w.Memory.Unlock()
+// copies the new entries of each scid since the last sync
+// entries are synced again from the start if the wallet discarded them (rescan, clean wallet, etc...)
+func (w *Wallet) SyncEntries() error {
+	w.entriesLock.Lock()
+	defer w.entriesLock.Unlock()
 
+	type syncEntries struct {
+		start   int
+		entries []rpc.Entry
+	}
+
+	positions, err := w.getLastEntryPositions()
+	if err != nil {
+		return err
+	}
+
+	changes := make(map[crypto.Hash]syncEntries)
+	var removed []crypto.Hash
+
+	w.Memory.Lock()
 	account := w.Memory.GetAccount()
-	var wg sync.WaitGroup
-	entryChan := make(chan Entry)
+	for scId, entries := range account.EntriesNative {
+		start := 0
+		position, synced := positions[scId]
+		if synced {
+			idx := position.Idx
+			if idx < len(entries) &&
+				entries[idx].TXID == position.TXID &&
+				entries[idx].TopoHeight == position.TopoHeight &&
+				entries[idx].Pos == position.Pos {
+				start = idx + 1
+			}
 
-	var filteredEntries []Entry
-	done := make(chan bool)
-	go func() {
-		for e := range entryChan {
-			filteredEntries = append(filteredEntries, e)
+			if start == len(entries) {
+				continue
+			}
 		}
 
-		done <- true
-	}()
+		changes[scId] = syncEntries{
+			start:   start,
+			entries: append([]rpc.Entry(nil), entries[start:]...),
+		}
+	}
 
-	workSize := 100
-	for entrySCID, entries := range account.EntriesNative {
-		if SCID != nil && entrySCID != *SCID {
-			continue
+	for scId := range positions {
+		_, ok := account.EntriesNative[scId]
+		if !ok {
+			removed = append(removed, scId)
 		}
+	}
+	w.Memory.Unlock()
 
-		workers := int(math.Max(1, float64(len(entries)/workSize)))
-		for i := 0; i < workers; i++ {
-			start := i * workSize
-			end := (i + 1) * workSize
-			if i == workers-1 {
-				end = len(entries)
-			}
+	if len(changes) == 0 && len(removed) == 0 {
+		return nil
+	}
+
+	tx, err := w.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
-			wg.Add(1)
-			go filterEntries(entries, start, end, entrySCID, params, entryChan, &wg)
+	stmt, err := tx.Prepare(`
+		INSERT INTO entries (sc_id, idx, tx_id, block_hash, height, topo_height, pos, time, direction,
+			sender, destination, amount, burn, fees, src_port, dst_port, comment, payload, sc_call_id, sc_call_entrypoint, data)
+		VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?);
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, scId := range removed {
+		_, err = tx.Exec(`DELETE FROM entries WHERE sc_id = ?;`, scId.String())
+		if err != nil {
+			return err
 		}
 	}
 
-	wg.Wait()
-	close(entryChan)
-	<-done
+	for scId, change := range changes {
+		_, err = tx.Exec(`DELETE FROM entries WHERE sc_id = ? AND idx >= ?;`, scId.String(), change.start)
+		if err != nil {
+			return err
+		}
 
-	sort.Slice(filteredEntries, func(a, b int) bool {
-		return filteredEntries[a].Time.Unix() > filteredEntries[b].Time.Unix()
-	})
+		for i, e := range change.entries {
+			data, err := json.Marshal(e)
+			if err != nil {
+				return err
+			}
 
-	if params.Offset.Valid {
-		offset := params.Offset.Int64
-		if len(filteredEntries) > int(offset) {
-			filteredEntries = filteredEntries[offset:]
+			_, err = stmt.Exec(scId.String(), change.start+i, e.TXID, e.BlockHash, e.Height, e.TopoHeight, e.Pos,
+				e.Time.Unix(), entryDirection(e), e.Sender, e.Destination,
+				formatEntryNumber(e.Amount), formatEntryNumber(e.Burn), formatEntryNumber(e.Fees),
+				formatEntryNumber(e.SourcePort), formatEntryNumber(e.DestinationPort),
+				entryArgString(e, rpc.RPC_COMMENT), entryPayloadText(e), entryArgString(e, "SC_ID"), entryArgString(e, "entrypoint"), data)
+			if err != nil {
+				return err
+			}
 		}
 	}
 
+	return tx.Commit()
+}
+
+func (w *Wallet) GetEntries(SCID *crypto.Hash, params GetEntriesParams) ([]Entry, error) {
+	err := w.SyncEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	query := sq.Select("sc_id", "data").From("entries")
+
+	if SCID != nil {
+		query = query.Where(sq.Eq{"sc_id": SCID.String()})
+	}
+
+	query = query.Where(params.where())
+
+	query = query.OrderBy("time DESC", "idx DESC")
+
 	if params.Limit.Valid {
-		limit := params.Limit.Int64
-		if len(filteredEntries) > int(limit) {
-			filteredEntries = filteredEntries[:limit]
+		query = query.Limit(uint64(params.Limit.Int64))
+		if params.Offset.Valid {
+			query = query.Offset(uint64(params.Offset.Int64))
 		}
+	} else if params.Offset.Valid {
+		// sqlite does not support an offset without a limit
+		query = query.Suffix(fmt.Sprintf("LIMIT -1 OFFSET %d", params.Offset.Int64))
+	}
+
+	rows, err := query.RunWith(w.DB).Query()
+	if err != nil {
+		return nil, err
+	}
+
+	return rowsScanEntries(rows)
+}
+
+func entryDirectionFilter(direction string, value bool) sq.Sqlizer {
+	if value {
+		return sq.Eq{"direction": direction}
+	}
+
+	return sq.NotEq{"direction": direction}
+}
+
+// escapes the like wildcards of the search
+func entrySearchPattern(search string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+	return "%" + replacer.Replace(search) + "%"
+}
+
+// all the set fields must match and at least one of the Or groups if any
+func (p GetEntriesParams) where() sq.And {
+	where := sq.And{}
+
+	if p.Coinbase.Valid {
+		where = append(where, entryDirectionFilter("coinbase", p.Coinbase.Bool))
+	}
+
+	if p.In.Valid {
+		where = append(where, entryDirectionFilter("in", p.In.Bool))
+	}
+
+	if p.Out.Valid {
+		where = append(where, entryDirectionFilter("out", p.Out.Bool))
+	}
+
+	if p.Sender.Valid {
+		where = append(where, sq.Eq{"sender": p.Sender.String})
+	}
+
+	if p.Receiver.Valid {
+		where = append(where, sq.Eq{"destination": p.Receiver.String})
+	}
+
+	if p.AmountGreaterOrEqualThan.Valid {
+		where = append(where, sq.GtOrEq{"amount": formatEntryMinNumber(p.AmountGreaterOrEqualThan.Int64)})
+	}
+
+	if p.AmountLessOrEqualThan.Valid {
+		where = append(where, sq.LtOrEq{"amount": formatEntryMinNumber(p.AmountLessOrEqualThan.Int64)})
+	}
+
+	if p.BurnGreaterOrEqualThan.Valid {
+		where = append(where, sq.GtOrEq{"burn": formatEntryMinNumber(p.BurnGreaterOrEqualThan.Int64)})
+	}
+
+	if p.DateFrom.Valid {
+		where = append(where, sq.GtOrEq{"time": p.DateFrom.Time.Unix()})
+	}
+
+	if p.DateTo.Valid {
+		where = append(where, sq.LtOrEq{"time": p.DateTo.Time.Unix()})
+	}
+
+	if p.DstPort != nil {
+		where = append(where, sq.Eq{"dst_port": formatEntryNumber(*p.DstPort)})
+	}
+
+	if p.DstPortFrom != nil {
+		where = append(where, sq.GtOrEq{"dst_port": formatEntryNumber(*p.DstPortFrom)})
+	}
+
+	if p.DstPortTo != nil {
+		where = append(where, sq.LtOrEq{"dst_port": formatEntryNumber(*p.DstPortTo)})
+	}
+
+	if p.TXID.Valid {
+		where = append(where, sq.Eq{"tx_id": p.TXID.String})
+	}
+
+	if p.BlockHash.Valid {
+		where = append(where, sq.Eq{"block_hash": p.BlockHash.String})
+	}
+
+	if p.Search.Valid {
+		pattern := entrySearchPattern(p.Search.String)
+		search := sq.Or{}
+		for _, column := range []string{"comment", "payload", "tx_id", "sender", "destination"} {
+			search = append(search, sq.Expr(fmt.Sprintf(`%s LIKE ? ESCAPE '\'`, column), pattern))
+		}
+
+		where = append(where, search)
+	}
+
+	if p.SC_CALL != nil {
+		if p.SC_CALL.SCID.Valid {
+			where = append(where, sq.Eq{"sc_call_id": p.SC_CALL.SCID.String})
+		}
+
+		if p.SC_CALL.Entrypoint.Valid {
+			where = append(where, sq.Eq{"sc_call_entrypoint": p.SC_CALL.Entrypoint.String})
+		}
+	}
+
+	if len(p.Or) > 0 {
+		or := sq.Or{}
+		for _, group := range p.Or {
+			or = append(or, group.where())
+		}
+
+		where = append(where, or)
 	}
 
-	return filteredEntries
+	return where
 }
@@ -0,0 +1,238 @@
+package wallet_manager
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/deroproject/derohe/rpc"
+)
+
+func newTestEntry(i int, incoming bool, amount uint64) rpc.Entry {
+	return rpc.Entry{
+		Height:     uint64(i),
+		TopoHeight: int64(i),
+		TXID:       fmt.Sprintf("%064x", i),
+		Incoming:   incoming,
+		Amount:     amount,
+		Time:       time.Unix(int64(1700000000+i), 0),
+	}
+}
+
+func getTestEntriesCount(t *testing.T, wallet *Wallet) (count int) {
+	t.Helper()
+
+	err := wallet.DB.QueryRow(`SELECT COUNT(*) FROM entries;`).Scan(&count)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return
+}
+
+// entries must be in the same order as the ids of newTestEntry
+func matchTestEntries(entries []Entry, ids []int) bool {
+	if len(entries) != len(ids) {
+		return false
+	}
+
+	for i, entry := range entries {
+		if entry.TXID != fmt.Sprintf("%064x", ids[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestGetEntries(t *testing.T) {
+	wallet := openTestWallet(t, 0)
+
+	for i := 0; i < 10; i++ {
+		addTestEntry(wallet, newTestEntry(i, i%2 == 0, uint64(i*100)))
+	}
+
+	// token entries with an amount higher than an sqlite integer
+	scId := crypto.HashHexToHash(strings.Repeat("ab", 32))
+	account := wallet.Memory.GetAccount()
+	account.EntriesNative[scId] = []rpc.Entry{newTestEntry(20, true, 1<<63+1)}
+
+	tests := []struct {
+		name   string
+		scId   *crypto.Hash
+		params GetEntriesParams
+		txIds  []int
+	}{
+		{name: "all", scId: &crypto.ZEROHASH, txIds: []int{9, 8, 7, 6, 5, 4, 3, 2, 1, 0}},
+		{name: "all tokens", txIds: []int{20, 9, 8, 7, 6, 5, 4, 3, 2, 1, 0}},
+		{name: "in", scId: &crypto.ZEROHASH, params: GetEntriesParams{In: sql.NullBool{Bool: true, Valid: true}}, txIds: []int{8, 6, 4, 2, 0}},
+		{name: "out", scId: &crypto.ZEROHASH, params: GetEntriesParams{Out: sql.NullBool{Bool: true, Valid: true}}, txIds: []int{9, 7, 5, 3, 1}},
+		{
+			name: "in and amount",
+			scId: &crypto.ZEROHASH,
+			params: GetEntriesParams{
+				In:                       sql.NullBool{Bool: true, Valid: true},
+				AmountGreaterOrEqualThan: sql.NullInt64{Int64: 500, Valid: true},
+			},
+			txIds: []int{8, 6},
+		},
+		{name: "big amount", params: GetEntriesParams{AmountGreaterOrEqualThan: sql.NullInt64{Int64: 1 << 62, Valid: true}}, txIds: []int{20}},
+		{name: "txid", params: GetEntriesParams{TXID: sql.NullString{String: fmt.Sprintf("%064x", 3), Valid: true}}, txIds: []int{3}},
+		{
+			name: "page",
+			scId: &crypto.ZEROHASH,
+			params: GetEntriesParams{
+				Offset: sql.NullInt64{Int64: 2, Valid: true},
+				Limit:  sql.NullInt64{Int64: 3, Valid: true},
+			},
+			txIds: []int{7, 6, 5},
+		},
+		{name: "offset", scId: &crypto.ZEROHASH, params: GetEntriesParams{Offset: sql.NullInt64{Int64: 8, Valid: true}}, txIds: []int{1, 0}},
+	}
+
+	for _, test := range tests {
+		entries, err := wallet.GetEntries(test.scId, test.params)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !matchTestEntries(entries, test.txIds) {
+			t.Errorf("%s: unexpected entries %v", test.name, entries)
+		}
+	}
+
+	entries, err := wallet.GetEntries(&scId, GetEntriesParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 1 || entries[0].SCID != scId || entries[0].Amount != 1<<63+1 {
+		t.Fatalf("unexpected token entries %+v", entries)
+	}
+}
+
+func TestGetEntriesFilters(t *testing.T) {
+	wallet := openTestWallet(t, 0)
+
+	for i := 0; i < 6; i++ {
+		entry := newTestEntry(i, i < 3, uint64(i*100))
+		entry.DestinationPort = uint64(1<<63) + uint64(i)
+		entry.Sender = fmt.Sprintf("sender%d", i)
+		entry.Payload_RPC = rpc.Arguments{
+			{Name: rpc.RPC_COMMENT, DataType: rpc.DataString, Value: fmt.Sprintf("order_%d 100%%", i)},
+		}
+		addTestEntry(wallet, entry)
+	}
+
+	port := uint64(1 << 63)
+	otherPort := uint64(1)
+	portFrom := uint64(1<<63 + 1)
+	portTo := uint64(1<<63 + 3)
+
+	tests := []struct {
+		name   string
+		params GetEntriesParams
+		txIds  []int
+	}{
+		{
+			name: "amount range",
+			params: GetEntriesParams{
+				AmountGreaterOrEqualThan: sql.NullInt64{Int64: 100, Valid: true},
+				AmountLessOrEqualThan:    sql.NullInt64{Int64: 300, Valid: true},
+			},
+			txIds: []int{3, 2, 1},
+		},
+		{
+			name: "date range",
+			params: GetEntriesParams{
+				DateFrom: sql.NullTime{Time: time.Unix(1700000002, 0), Valid: true},
+				DateTo:   sql.NullTime{Time: time.Unix(1700000004, 0), Valid: true},
+			},
+			txIds: []int{4, 3, 2},
+		},
+		{name: "dst port", params: GetEntriesParams{DstPort: &port}, txIds: []int{0}},
+		{name: "other dst port", params: GetEntriesParams{DstPort: &otherPort}},
+		{name: "dst port range", params: GetEntriesParams{DstPortFrom: &portFrom, DstPortTo: &portTo}, txIds: []int{3, 2, 1}},
+		{name: "dst port from", params: GetEntriesParams{DstPortFrom: &portTo}, txIds: []int{5, 4, 3}},
+		{name: "comment", params: GetEntriesParams{Search: sql.NullString{String: "order_4", Valid: true}}, txIds: []int{4}},
+		{name: "sender", params: GetEntriesParams{Search: sql.NullString{String: "sender1", Valid: true}}, txIds: []int{1}},
+		// wildcards are escaped
+		{name: "wildcard", params: GetEntriesParams{Search: sql.NullString{String: "r_1%", Valid: true}}},
+		{
+			name: "or groups",
+			params: GetEntriesParams{
+				In: sql.NullBool{Bool: true, Valid: true},
+				Or: []GetEntriesParams{
+					{Sender: sql.NullString{String: "sender0", Valid: true}},
+					{AmountGreaterOrEqualThan: sql.NullInt64{Int64: 200, Valid: true}},
+				},
+			},
+			txIds: []int{2, 0},
+		},
+	}
+
+	for _, test := range tests {
+		entries, err := wallet.GetEntries(&crypto.ZEROHASH, test.params)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !matchTestEntries(entries, test.txIds) {
+			t.Errorf("%s: unexpected entries %v", test.name, entries)
+		}
+	}
+}
+
+func TestSyncEntries(t *testing.T) {
+	wallet := openTestWallet(t, 0)
+
+	for i := 0; i < 5; i++ {
+		addTestEntry(wallet, newTestEntry(i, true, 100))
+	}
+
+	err := wallet.SyncEntries()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// only new entries are added
+	addTestEntry(wallet, newTestEntry(5, true, 100))
+	err = wallet.SyncEntries()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if getTestEntriesCount(t, wallet) != 6 {
+		t.Fatalf("expected 6 entries, got %d", getTestEntriesCount(t, wallet))
+	}
+
+	// the wallet discarded its entries and synced other ones
+	account := wallet.Memory.GetAccount()
+	account.EntriesNative[crypto.ZEROHASH] = []rpc.Entry{newTestEntry(10, true, 100), newTestEntry(11, false, 100)}
+	err = wallet.SyncEntries()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := wallet.GetEntries(nil, GetEntriesParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 2 || entries[0].TXID != fmt.Sprintf("%064x", 11) {
+		t.Fatalf("entries were not synced again %+v", entries)
+	}
+
+	delete(account.EntriesNative, crypto.ZEROHASH)
+	err = wallet.SyncEntries()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if getTestEntriesCount(t, wallet) != 0 {
+		t.Fatal("entries of a removed token should be deleted")
+	}
+}
@@ -6,10 +6,10 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
-	"math"
 	"math/rand"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/deroproject/derohe/config"
@@ -19,11 +19,9 @@ import (
 	"github.com/deroproject/derohe/rpc"
 	"github.com/deroproject/derohe/transaction"
 	"github.com/deroproject/derohe/walletapi"
-	"github.com/deroproject/derohe/walletapi/rpcserver"
 	"github.com/secretsystems/secret-wallet/app_db"
 	"github.com/secretsystems/secret-wallet/app_db/schema_version"
 	"github.com/secretsystems/secret-wallet/settings"
-	"github.com/secretsystems/secret-wallet/utils"
 
 	"database/sql"
 
@@ -34,22 +32,51 @@ type Wallet struct {
 	Info   app_db.WalletInfo
 	Memory *walletapi.Wallet_Disk
 	DB     *sql.DB
-	Server *rpcserver.RPCServer
+	Server *RPCServer
+	XSWD   *XSWDServer
+
+	balancesLock  sync.RWMutex
+	knownBalances map[crypto.Hash]bool // watch-only balances that could be decrypted
+
+	entriesLock sync.Mutex
 }
 
 var OpenedWallet *Wallet
 
 func CloseOpenedWallet() {
+	closeOpenedWallet(false)
+}
+
+// returns once wallet.db is saved and closed - use it before the wallet files are replaced
+func CloseOpenedWalletAndWait() {
+	closeOpenedWallet(true)
+}
+
+func closeOpenedWallet(wait bool) {
 	if OpenedWallet != nil {
 
 		wallet := OpenedWallet
+		if wallet.Server != nil {
+			wallet.Server.Stop()
+		}
+
+		if wallet.XSWD != nil {
+			wallet.XSWD.Stop()
+		}
+
+		closed := make(chan struct{})
 		go func() {
 			close(wallet.Memory.Quit) // make sure to close goroutines when wallet is in online mode
 			wallet.Memory.Close_Encrypted_Wallet()
+			close(closed)
 		}()
 		wallet.DB.Close()
 
 		OpenedWallet = nil
+
+		if wait {
+			<-closed
+		}
 	}
 }
 
@@ -121,6 +148,41 @@ open_wallet:
 		return err
 	}
 
+	err = initDatabaseInvoices(db)
+	if err != nil {
+		return err
+	}
+
+	err = initDatabaseEntries(db)
+	if err != nil {
+		return err
+	}
+
+	err = initDatabaseDEXPairs(db)
+	if err != nil {
+		return err
+	}
+
+	err = initDatabaseMyContracts(db)
+	if err != nil {
+		return err
+	}
+
+	err = initDatabaseSCWatchlist(db)
+	if err != nil {
+		return err
+	}
+
+	err = initDatabaseRPCCredentials(db)
+	if err != nil {
+		return err
+	}
+
+	err = initDatabaseRPCPolicy(db)
+	if err != nil {
+		return err
+	}
+
 	account := memory.GetAccount()
 	// fix: looks like EntriesNative is not instantiated on startup but only in InsertReplace func???
 	if account.EntriesNative == nil {
@@ -179,7 +241,7 @@ func CreateWalletFromPath(name string, password string, path string) error {
 		return err
 	}
 
-	return createWallet(wallet.Wallet_Memory, name)
+	return createWallet(wallet.Wallet_Memory, name, false)
 }
 
 func CreateWalletFromData(name string, password string, data []byte) error {
@@ -188,7 +250,7 @@ func CreateWalletFromData(name string, password string, data []byte) error {
 		return err
 	}
 
-	return createWallet(walletMemory, name)
+	return createWallet(walletMemory, name, false)
 }
 
 func CreateWalletFromSeed(name string, password string, seed string) error {
@@ -197,7 +259,7 @@ func CreateWalletFromSeed(name string, password string, seed string) error {
 		return err
 	}
 
-	return createWallet(wallet, name)
+	return createWallet(wallet, name, false)
 }
 
 func CreateWalletFromHexSeed(name string, password, hexSeed string) error {
@@ -216,7 +278,7 @@ func CreateWalletFromHexSeed(name string, password, hexSeed string) error {
 		return err
 	}
 
-	return createWallet(wallet, name)
+	return createWallet(wallet, name, false)
 }
 
 func CreateRandomWallet(name string, password string) error {
@@ -225,7 +287,7 @@ func CreateRandomWallet(name string, password string) error {
 		return err
 	}
 
-	return createWallet(wallet, name)
+	return createWallet(wallet, name, false)
 }
 
 func (w *Wallet) Rename(newName string) error {
@@ -261,13 +323,13 @@ func (w *Wallet) ResetBalanceResult(scId string) {
 }
 
 func (w *Wallet) ChangePassword(password string, newPassword string) error {
-	seed := w.Memory.GetAccount().Keys.Secret
-	newMemory, err := walletapi.Create_Encrypted_Wallet_Memory(newPassword, seed)
-	if err != nil {
-		return err
+	if !w.Memory.Check_Password(password) {
+		return fmt.Errorf("Invalid Password")
 	}
 
-	return saveWalletData(newMemory)
+	// the opened wallet is saved to disk with the new password
+	// recreating it from the seed would lose the network, the history and the watch-only keys
+	return w.Memory.Set_Encrypted_Wallet_Password(newPassword)
 }
 
 type RingMembers struct {
@@ -289,7 +351,7 @@ func (w *Wallet) BuildRingMembers(transfers []rpc.Transfer, ringsize uint64) (ri
 		bitsNeeded := make([]int, ringsize)
 
 		var selfEncryptedBalance *crypto.ElGamal
-		bitsNeeded[0], _, _, selfEncryptedBalance, err = w.Memory.GetEncryptedBalanceAtTopoHeight(transfer.SCID, -1, walletAddr)
+		bitsNeeded[0], selfEncryptedBalance, err = w.getRingMemberBalance(transfer.SCID, walletAddr)
 		if err != nil {
 			return
 		}
@@ -310,7 +372,7 @@ func (w *Wallet) BuildRingMembers(transfers []rpc.Transfer, ringsize uint64) (ri
 		}
 
 		var destEncryptedBalance *crypto.ElGamal
-		bitsNeeded[1], _, _, destEncryptedBalance, err = w.Memory.GetEncryptedBalanceAtTopoHeight(transfer.SCID, -1, destAddr.String())
+		bitsNeeded[1], destEncryptedBalance, err = w.getRingMemberBalance(transfer.SCID, destAddr.String())
 		if err != nil {
 			return
 		}
@@ -319,7 +381,15 @@ func (w *Wallet) BuildRingMembers(transfers []rpc.Transfer, ringsize uint64) (ri
 		ring = append(ring, destAddr.PublicKey.G1())
 		ringAddrs[transfer.Destination] = true
 
+		loadTries := 0
 	loadRingMembers:
+		loadTries++
+		if loadTries > 10 {
+			// avoid looping forever if the daemon does not have enough registered addresses
+			err = fmt.Errorf("not enough ring members for ringsize %d", ringsize)
+			return
+		}
+
 		var addrList []string
 		addrList, err = w.GetRandomAddresses(transfer.SCID)
 		if err != nil {
@@ -348,7 +418,7 @@ func (w *Wallet) BuildRingMembers(transfers []rpc.Transfer, ringsize uint64) (ri
 			}
 
 			var memberEncryptedBalance *crypto.ElGamal
-			bitsNeeded[len(ringBalances)], _, _, memberEncryptedBalance, err = w.Memory.GetEncryptedBalanceAtTopoHeight(transfer.SCID, -1, addr)
+			bitsNeeded[len(ringBalances)], memberEncryptedBalance, err = w.getRingMemberBalance(transfer.SCID, addr)
 			if err != nil {
 				return
 			}
@@ -379,15 +449,7 @@ func (w *Wallet) BuildRingMembers(transfers []rpc.Transfer, ringsize uint64) (ri
 }
 
 func (w *Wallet) GetGasEstimate(transfers []rpc.Transfer, ringsize uint64, scArgs rpc.Arguments) (uint64, error) {
-	signer := w.Memory.GetAddress().String()
-
-	var result rpc.GasEstimate_Result
-	err := RPC_Client.RPC.CallResult(context.Background(), "DERO.GetGasEstimate", rpc.GasEstimate_Params{
-		Transfers: transfers,
-		SC_RPC:    scArgs,
-		Ringsize:  ringsize,
-		Signer:    signer,
-	}, &result)
+	result, err := w.GasEstimate(transfers, ringsize, scArgs)
 	if err != nil {
 		return 0, err
 	}
@@ -395,145 +457,47 @@ func (w *Wallet) GetGasEstimate(transfers []rpc.Transfer, ringsize uint64, scArg
 	return result.GasStorage, nil
 }
 
-func (w *Wallet) BuildTransaction(transfers []rpc.Transfer, ringsize uint64, scArgs rpc.Arguments, dryRun bool) (tx *transaction.Transaction, txFees uint64, gasFees uint64, err error) {
-	if len(scArgs) > 0 {
-		// smart contract call to test if it can succeed and return gas fees for the dry run
-		gasFees, err = w.GetGasEstimate(transfers, ringsize, scArgs)
-		if err != nil {
-			return
-		}
-	}
-
-	// need at least one Dero transfers
-	hasBase := false
-	for _, t := range transfers {
-		if t.SCID.IsZero() {
-			hasBase = true
-			break
-		}
-	}
-
-	if !hasBase {
-		var randomAddr string
-		randomAddr, err = w.GetRandomAddress(crypto.ZEROHASH)
-		if err != nil {
-			return
-		}
+// dry run of the sc call by the node - dvm errors are returned before anything is sent
+func (w *Wallet) GasEstimate(transfers []rpc.Transfer, ringsize uint64, scArgs rpc.Arguments) (result rpc.GasEstimate_Result, err error) {
+	signer := w.Memory.GetAddress().String()
 
-		transfers = append(transfers, rpc.Transfer{
-			SCID:        crypto.ZEROHASH,
-			Destination: randomAddr,
-			Amount:      0,
-		})
-	}
+	err = RPC_Client.RPC.CallResult(context.Background(), "DERO.GetGasEstimate", rpc.GasEstimate_Params{
+		Transfers: transfers,
+		SC_RPC:    scArgs,
+		Ringsize:  ringsize,
+		Signer:    signer,
+	}, &result)
+	return
+}
 
-	var ringMembers RingMembers
-	ringMembers, err = w.BuildRingMembers(transfers, ringsize)
-	if err != nil {
-		return
+func scCallArgs(scId string, entrypoint string) rpc.Arguments {
+	return rpc.Arguments{
+		{Name: rpc.SCACTION, DataType: rpc.DataUint64, Value: uint64(rpc.SC_CALL)},
+		{Name: rpc.SCID, DataType: rpc.DataHash, Value: crypto.HashHexToHash(scId)},
+		{Name: "entrypoint", DataType: rpc.DataString, Value: entrypoint},
 	}
+}
 
-	topoHeight := w.Memory.Get_TopoHeight()
-
-	var encryptedBalance rpc.GetEncryptedBalance_Result
-	encryptedBalance, err = w.Memory.GetSelfEncryptedBalanceAtTopoHeight(crypto.ZEROHASH, topoHeight)
-	if err != nil {
+func (w *Wallet) BuildTransaction(transfers []rpc.Transfer, ringsize uint64, scArgs rpc.Arguments, dryRun bool) (tx *transaction.Transaction, txFees uint64, gasFees uint64, err error) {
+	if w.IsWatchOnly() {
+		err = ErrWatchOnly
 		return
 	}
 
-	height := uint64(encryptedBalance.Height)
-	blockHash := encryptedBalance.BlockHash
-	treeHash := encryptedBalance.Merkle_Balance_TreeHash
-
-	var treeHashRaw []byte
-	treeHashRaw, err = hex.DecodeString(treeHash)
+	unsignedTx, err := w.PrepareTransaction(transfers, ringsize, scArgs)
 	if err != nil {
 		return
 	}
 
-	// get len of for Dero transfers - the fees are only applied to Dero asset statements and not other tokens
-	deroTransfers := 0
-	assetsAmount := make(map[crypto.Hash]uint64, 0)
-	for _, transfer := range transfers {
-		_, ok := assetsAmount[transfer.SCID]
-		if !ok {
-			assetsAmount[transfer.SCID] = 0
-		}
-
-		assetsAmount[transfer.SCID] += transfer.Amount + transfer.Burn
-		if transfer.SCID.IsZero() {
-			deroTransfers++
-		}
-	}
-
-	for asset, amount := range assetsAmount {
-		balance, _ := w.Memory.Get_Balance_scid(asset)
-		if amount > balance {
-			if asset.IsZero() {
-				err = fmt.Errorf("you don't have enough Dero")
-			} else {
-				err = fmt.Errorf("you don't have enough asset funds of [%s]", utils.ReduceTxId(asset.String()))
-			}
-
-			return
-		}
-	}
-
-	// build a dry transaction to get transaction size and calculate fees
-	// set fees to 1 to avoid automatic fees in statement
-	// fee value is store in tx but its too small for making any adjustments
-	tx = w.Memory.BuildTransaction(
-		transfers,
-		ringMembers.RingsBalances,
-		ringMembers.Rings,
-		blockHash,
-		height,
-		scArgs,
-		treeHashRaw,
-		ringMembers.MaxBits,
-		1,
-	)
-	if tx == nil {
-		err = fmt.Errorf("can't build transaction")
-		return
-	}
-
-	txSize := uint64(len(tx.Serialize()))
-	txFees = w.CalculateTxFees(txSize)
-	totalFees := txFees + gasFees
-
-	if dryRun {
-		return
-	}
-
-	// set fees in BuildTransaction applies it to all Dero transfers -_-
-	// split the fees amongst all Dero transfers
-	feesPerTransfer := uint64(math.Ceil(float64(totalFees) / float64(deroTransfers)))
-
-	tx = w.Memory.BuildTransaction(
-		transfers,
-		ringMembers.RingsBalances,
-		ringMembers.Rings,
-		blockHash,
-		height,
-		scArgs,
-		treeHashRaw,
-		ringMembers.MaxBits,
-		feesPerTransfer,
-	)
-
-	if tx == nil {
-		err = fmt.Errorf("can't build transaction")
-		return
-	}
-
+	gasFees = unsignedTx.GasFees
+	tx, txFees, err = w.SignTransaction(unsignedTx, dryRun)
 	return
 }
 
 func (w *Wallet) CalculateTxFees(sizeInBytes uint64) (fees uint64) {
 	size := sizeInBytes / 1024
 
-	if size%1024 != 0 {
+	if sizeInBytes%1024 != 0 {
 		size += 1 // add full kb for any rest
 	}
 
@@ -551,7 +515,7 @@ func StoreRegistrationTx(addr string, tx *transaction.Transaction) error {
 	return app_db.UpdateWalletInfo(walletInfo)
 }
 
-func createWallet(wallet *walletapi.Wallet_Memory, name string) error {
+func createWallet(wallet *walletapi.Wallet_Memory, name string, watchOnly bool) error {
 	wallet.SetNetwork(globals.IsMainnet())
 
 	addr := wallet.GetAddress().String()
@@ -560,6 +524,7 @@ func createWallet(wallet *walletapi.Wallet_Memory, name string) error {
 		Name:        name,
 		Timestamp:   time.Now().Unix(),
 		OrderNumber: -1,
+		WatchOnly:   watchOnly,
 	}
 
 	err := app_db.InsertWalletInfo(walletInfo)
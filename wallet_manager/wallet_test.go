@@ -0,0 +1,349 @@
+package wallet_manager
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/deroproject/derohe/rpc"
+	"github.com/deroproject/derohe/transaction"
+	"github.com/deroproject/derohe/walletapi"
+	"github.com/secretsystems/secret-wallet/app_db"
+	"github.com/secretsystems/secret-wallet/mock_daemon"
+	"github.com/secretsystems/secret-wallet/settings"
+)
+
+var daemon *mock_daemon.Daemon
+
+func TestMain(m *testing.M) {
+	os.Exit(runTests(m))
+}
+
+func runTests(m *testing.M) int {
+	appDir, err := os.MkdirTemp("", "secret-wallet-test")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(appDir)
+
+	settings.AppDir = appDir
+	settings.NetworkDir = appDir
+	settings.WalletsDir = filepath.Join(appDir, "wallets")
+	settings.CacheDir = filepath.Join(appDir, "cache")
+
+	err = app_db.Load()
+	if err != nil {
+		panic(err)
+	}
+	defer app_db.DB.Close()
+
+	daemon = mock_daemon.New()
+	defer daemon.Close()
+
+	err = walletapi.Connect(daemon.Endpoint)
+	if err != nil {
+		panic(err)
+	}
+
+	err = ConnectRPCClient(daemon.Endpoint)
+	if err != nil {
+		panic(err)
+	}
+	defer CloseRPCClient()
+
+	return m.Run()
+}
+
+// creates and opens a new wallet connected to the mock daemon with a Dero balance
+func openTestWallet(t *testing.T, balance uint64) *Wallet {
+	t.Helper()
+
+	err := CreateRandomWallet(t.Name(), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wallets, err := app_db.GetWallets()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var addr string
+	for _, info := range wallets {
+		if info.Name == t.Name() {
+			addr = info.Addr
+		}
+	}
+
+	err = OpenWallet(addr, "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wallet := OpenedWallet
+	t.Cleanup(CloseOpenedWallet)
+
+	// registered at the current height so there is no history to sync
+	daemon.SetRegistration(addr, daemon.Height)
+	daemon.SetRandomAddresses(crypto.ZEROHASH, mock_daemon.RandomAddresses(20))
+	wallet.Memory.SetOnlineMode()
+	setTestBalance(t, wallet, crypto.ZEROHASH, balance)
+
+	return wallet
+}
+
+func setTestBalance(t *testing.T, wallet *Wallet, scId crypto.Hash, balance uint64) {
+	t.Helper()
+
+	daemon.SetBalance(scId, wallet.Info.Addr, balance)
+	err := wallet.Memory.Sync_Wallet_Memory_With_Daemon_internal(scId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	value, _ := wallet.Memory.Get_Balance_scid(scId)
+	if value != balance {
+		t.Fatalf("wallet balance is %d instead of %d", value, balance)
+	}
+}
+
+func newTestDestination(scId crypto.Hash) string {
+	addr := mock_daemon.RandomAddress()
+	daemon.SetBalance(scId, addr, 0)
+	return addr
+}
+
+func TestBuildRingMembers(t *testing.T) {
+	wallet := openTestWallet(t, 100000)
+	dest := newTestDestination(crypto.ZEROHASH)
+	daemon.SetBits(dest, 20)
+
+	transfers := []rpc.Transfer{
+		{SCID: crypto.ZEROHASH, Destination: dest, Amount: 1000},
+		{SCID: crypto.ZEROHASH, Destination: dest, Amount: 2000},
+	}
+
+	ringMembers, err := wallet.BuildRingMembers(transfers, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ringMembers.Rings) != len(transfers) ||
+		len(ringMembers.RingsBalances) != len(transfers) ||
+		len(ringMembers.RingsAddrs) != len(transfers) {
+		t.Fatalf("expected %d rings", len(transfers))
+	}
+
+	destAddr, _ := rpc.NewAddress(dest)
+	for i := range transfers {
+		ring := ringMembers.Rings[i]
+		if len(ring) != 16 || len(ringMembers.RingsBalances[i]) != 16 {
+			t.Fatalf("ring %d has %d members instead of 16", i, len(ring))
+		}
+
+		// the members are unique
+		if len(ringMembers.RingsAddrs[i]) != 16 {
+			t.Fatalf("ring %d has %d unique addresses instead of 16", i, len(ringMembers.RingsAddrs[i]))
+		}
+
+		// sender is always first and receiver second
+		if ring[0].String() != wallet.Memory.GetAccount().Keys.Public.G1().String() {
+			t.Fatalf("ring %d does not start with the sender", i)
+		}
+
+		if ring[1].String() != destAddr.PublicKey.G1().String() {
+			t.Fatalf("ring %d second member is not the receiver", i)
+		}
+	}
+
+	// the highest bits of all members plus 6
+	if ringMembers.MaxBits != 26 {
+		t.Fatalf("max bits is %d instead of 26", ringMembers.MaxBits)
+	}
+}
+
+func TestBuildRingMembersFallbackToBaseAsset(t *testing.T) {
+	wallet := openTestWallet(t, 100000)
+	scId := crypto.HashHexToHash(strings.Repeat("ab", 32))
+	dest := newTestDestination(scId)
+
+	// not enough addresses for the token - use the Dero ones
+	daemon.SetRandomAddresses(scId, mock_daemon.RandomAddresses(1))
+
+	ringMembers, err := wallet.BuildRingMembers([]rpc.Transfer{{SCID: scId, Destination: dest, Amount: 1}}, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ringMembers.RingsAddrs[0]) != 8 {
+		t.Fatalf("ring has %d members instead of 8", len(ringMembers.RingsAddrs[0]))
+	}
+}
+
+func TestBuildRingMembersErrors(t *testing.T) {
+	wallet := openTestWallet(t, 100000)
+
+	_, err := wallet.BuildRingMembers([]rpc.Transfer{{Destination: wallet.Info.Addr, Amount: 1}}, 2)
+	if err == nil || err.Error() != "can't send to self" {
+		t.Fatalf("expected can't send to self error, got %v", err)
+	}
+
+	_, err = wallet.BuildRingMembers([]rpc.Transfer{{Destination: mock_daemon.RandomAddress(), Amount: 1}}, 2)
+	if err == nil {
+		t.Fatal("expected an error for an unregistered destination")
+	}
+
+	// the daemon only knows 20 addresses + wallet + destination
+	_, err = wallet.BuildRingMembers([]rpc.Transfer{{Destination: newTestDestination(crypto.ZEROHASH), Amount: 1}}, 32)
+	if err == nil || !strings.HasPrefix(err.Error(), "not enough ring members") {
+		t.Fatalf("expected not enough ring members error, got %v", err)
+	}
+}
+
+func TestCalculateTxFees(t *testing.T) {
+	wallet := &Wallet{}
+
+	tests := []struct {
+		size uint64
+		fees uint64
+	}{
+		{size: 0, fees: 1},
+		{size: 1, fees: 21},
+		{size: 1023, fees: 21},
+		{size: 1024, fees: 21},
+		{size: 1025, fees: 41},
+		{size: 2048, fees: 41},
+		{size: 2500, fees: 61},
+	}
+
+	for _, test := range tests {
+		fees := wallet.CalculateTxFees(test.size)
+		if fees != test.fees {
+			t.Errorf("%d bytes fees are %d instead of %d", test.size, fees, test.fees)
+		}
+	}
+}
+
+func TestBuildTransactionFees(t *testing.T) {
+	wallet := openTestWallet(t, 100000)
+	dest := newTestDestination(crypto.ZEROHASH)
+
+	scCall := rpc.Arguments{
+		{Name: rpc.SCACTION, DataType: rpc.DataUint64, Value: uint64(rpc.SC_CALL)},
+		{Name: rpc.SCID, DataType: rpc.DataHash, Value: crypto.HashHexToHash(strings.Repeat("cd", 32))},
+		{Name: "entrypoint", DataType: rpc.DataString, Value: "Test"},
+	}
+
+	tests := []struct {
+		name      string
+		transfers []rpc.Transfer
+		scArgs    rpc.Arguments
+		gas       uint64
+	}{
+		{
+			name:      "one transfer",
+			transfers: []rpc.Transfer{{Destination: dest, Amount: 1000}},
+		},
+		{
+			name: "fees split between transfers",
+			transfers: []rpc.Transfer{
+				{Destination: dest, Amount: 1000},
+				{Destination: dest, Amount: 2000},
+				{Destination: dest, Amount: 3000},
+			},
+		},
+		{
+			name:      "sc call with gas",
+			transfers: []rpc.Transfer{{Destination: dest, Burn: 100}},
+			scArgs:    scCall,
+			gas:       1234,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			daemon.GasStorage = test.gas
+
+			dryTx, txFees, gasFees, err := wallet.BuildTransaction(test.transfers, 2, test.scArgs, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if gasFees != test.gas {
+				t.Fatalf("gas fees are %d instead of %d", gasFees, test.gas)
+			}
+
+			if txFees != wallet.CalculateTxFees(uint64(len(dryTx.Serialize()))) {
+				t.Fatalf("tx fees %d don't match the dry tx size", txFees)
+			}
+
+			// the dry run only has a one Deri fee
+			if dryTx.Fees() != uint64(len(test.transfers)) {
+				t.Fatalf("dry tx fees are %d", dryTx.Fees())
+			}
+
+			tx, _, _, err := wallet.BuildTransaction(test.transfers, 2, test.scArgs, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// the total is split and rounded up amongst all Dero transfers
+			deroTransfers := uint64(len(test.transfers))
+			feesPerTransfer := uint64(math.Ceil(float64(txFees+gasFees) / float64(deroTransfers)))
+			if tx.Fees() != feesPerTransfer*deroTransfers {
+				t.Fatalf("tx fees are %d instead of %d", tx.Fees(), feesPerTransfer*deroTransfers)
+			}
+
+			if tx.Fees() < txFees+gasFees {
+				t.Fatalf("tx fees %d are lower than needed %d", tx.Fees(), txFees+gasFees)
+			}
+
+			if len(test.scArgs) > 0 && tx.TransactionType != transaction.SC_TX {
+				t.Fatalf("tx type is %s instead of SC_TX", tx.TransactionType)
+			}
+		})
+	}
+
+	daemon.GasStorage = 0
+}
+
+func TestBuildTransactionTokenOnly(t *testing.T) {
+	wallet := openTestWallet(t, 100000)
+	scId := crypto.HashHexToHash(strings.Repeat("ef", 32))
+	setTestBalance(t, wallet, scId, 500)
+	dest := newTestDestination(scId)
+	daemon.SetRandomAddresses(scId, mock_daemon.RandomAddresses(20))
+
+	tx, txFees, _, err := wallet.BuildTransaction([]rpc.Transfer{{SCID: scId, Destination: dest, Amount: 10}}, 2, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a Dero transfer is added to pay the fees
+	if len(tx.Payloads) != 2 {
+		t.Fatalf("tx has %d payloads instead of 2", len(tx.Payloads))
+	}
+
+	if tx.Fees() != txFees {
+		t.Fatalf("tx fees are %d instead of %d", tx.Fees(), txFees)
+	}
+}
+
+func TestBuildTransactionNotEnoughFunds(t *testing.T) {
+	wallet := openTestWallet(t, 1000)
+	dest := newTestDestination(crypto.ZEROHASH)
+
+	_, _, _, err := wallet.BuildTransaction([]rpc.Transfer{{Destination: dest, Amount: 1001}}, 2, nil, true)
+	if err == nil || err.Error() != "you don't have enough Dero" {
+		t.Fatalf("expected not enough Dero error, got %v", err)
+	}
+
+	scId := crypto.HashHexToHash(strings.Repeat("12", 32))
+	_, _, _, err = wallet.BuildTransaction([]rpc.Transfer{{SCID: scId, Destination: dest, Amount: 1}}, 2, nil, true)
+	if err == nil || !strings.HasPrefix(err.Error(), "you don't have enough asset funds") {
+		t.Fatalf("expected not enough asset funds error, got %v", err)
+	}
+}
@@ -0,0 +1,281 @@
+package wallet_manager
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/deroproject/derohe/errormsg"
+	"github.com/deroproject/derohe/globals"
+	"github.com/deroproject/derohe/rpc"
+	"github.com/deroproject/derohe/walletapi"
+)
+
+// Watch-only wallets are created from a public address.
+// The secret key is unknown so txs can't be signed and balances can't be decrypted.
+// The walletapi sync loop must never run for them - it would bruteforce the balances forever.
+
+var ErrWatchOnly = fmt.Errorf("this is a watch-only wallet and it can't sign transactions")
+
+func CreateWatchOnlyWallet(name string, password string, addr string) error {
+	address, err := rpc.NewAddress(strings.TrimSpace(addr))
+	if err != nil {
+		return err
+	}
+
+	if address.IsIntegratedAddress() {
+		return fmt.Errorf("use the wallet address instead of an integrated address")
+	}
+
+	if address.IsMainnet() != globals.IsMainnet() {
+		return fmt.Errorf("the address is not from the current network")
+	}
+
+	wallet, err := newWatchOnlyMemory(password, address.PublicKey)
+	if err != nil {
+		return err
+	}
+
+	return createWallet(wallet, name, true)
+}
+
+// the account public key is replaced by the watched address - the random secret is never used
+func newWatchOnlyMemory(password string, publicKey *crypto.Point) (*walletapi.Wallet_Memory, error) {
+	wallet, err := walletapi.Create_Encrypted_Wallet_Random_Memory(password)
+	if err != nil {
+		return nil, err
+	}
+
+	wallet.GetAccount().Keys.Public = new(crypto.Point).Set(publicKey)
+	return wallet, nil
+}
+
+func (w *Wallet) IsWatchOnly() bool {
+	return w.Info.WatchOnly
+}
+
+// returns false if the balance can't be decrypted
+func (w *Wallet) GetBalance(scId crypto.Hash) (balance uint64, known bool) {
+	balance, _ = w.Memory.Get_Balance_scid(scId)
+	if !w.IsWatchOnly() {
+		return balance, true
+	}
+
+	w.balancesLock.RLock()
+	known = w.knownBalances[scId]
+	w.balancesLock.RUnlock()
+	return
+}
+
+func isErrUnregistered(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), strings.ToLower(errormsg.ErrAccountUnregistered.Error()))
+}
+
+func (w *Wallet) getEncryptedBalance(scId crypto.Hash) (result rpc.GetEncryptedBalance_Result, err error) {
+	return getEncryptedBalance(scId, w.Memory.GetAddress().String())
+}
+
+func getEncryptedBalance(scId crypto.Hash, addr string) (result rpc.GetEncryptedBalance_Result, err error) {
+	err = RPC_Client.RPC.CallResult(context.Background(), "DERO.GetEncryptedBalance", rpc.GetEncryptedBalance_Params{
+		SCID:       scId,
+		Address:    addr,
+		TopoHeight: -1,
+	}, &result)
+	if err != nil {
+		return
+	}
+
+	if result.Status == errormsg.ErrAccountUnregistered.Error() {
+		err = errormsg.ErrAccountUnregistered
+	}
+
+	return
+}
+
+// watch-only wallets are never in walletapi online mode so the ring balances are requested directly
+func (w *Wallet) getRingMemberBalance(scId crypto.Hash, addr string) (bits int, balance *crypto.ElGamal, err error) {
+	if !w.IsWatchOnly() {
+		bits, _, _, balance, err = w.Memory.GetEncryptedBalanceAtTopoHeight(scId, -1, addr)
+		return
+	}
+
+	result, err := getEncryptedBalance(scId, addr)
+	if err != nil {
+		// same as walletapi - an account that never received a token has a zero balance
+		if isErrUnregistered(err) && !scId.IsZero() {
+			var address *rpc.Address
+			address, err = rpc.NewAddress(addr)
+			if err != nil {
+				return
+			}
+
+			balance = crypto.ConstructElGamal(address.PublicKey.G1(), crypto.ElGamal_BASE_G)
+			return 0, balance, nil
+		}
+
+		return
+	}
+
+	data, err := hex.DecodeString(result.Data)
+	if err != nil {
+		return
+	}
+
+	var nonceBalance crypto.NonceBalance
+	nonceBalance.Unmarshal(data)
+	return result.Bits, nonceBalance.Balance, nil
+}
+
+// a balance is only known if it was never touched since the registration
+// the daemon stores it as (public key, G) which decrypts to zero
+func (w *Wallet) isZeroBalance(result rpc.GetEncryptedBalance_Result) (bool, error) {
+	data, err := hex.DecodeString(result.Data)
+	if err != nil {
+		return false, err
+	}
+
+	var nonceBalance crypto.NonceBalance
+	nonceBalance.Unmarshal(data)
+
+	publicKey := w.Memory.GetAccount().Keys.Public
+	zeroBalance := crypto.ConstructElGamal(publicKey.G1(), crypto.ElGamal_BASE_G)
+	return bytes.Equal(nonceBalance.Balance.Serialize(), zeroBalance.Serialize()), nil
+}
+
+// syncs the registration status, heights and the balances that can be known without the secret key
+func (w *Wallet) SyncWatchOnly() error {
+	account := w.Memory.GetAccount()
+
+	result, err := w.getEncryptedBalance(crypto.ZEROHASH)
+	if err != nil {
+		if isErrUnregistered(err) {
+			account.Lock()
+			account.Registered = false
+			account.Unlock()
+			return nil
+		}
+
+		return err
+	}
+
+	account.Lock()
+	account.Registered = true
+	account.Height = uint64(result.Height)
+	account.TopoHeight = result.Topoheight
+
+	// Get_Height and Get_TopoHeight read the cached Dero result
+	replaced := false
+	for i, balanceResult := range account.Balance_Result {
+		if balanceResult.SCID.IsZero() {
+			account.Balance_Result[i] = result
+			replaced = true
+			break
+		}
+	}
+
+	if !replaced {
+		account.Balance_Result = append(account.Balance_Result, result)
+	}
+	account.Unlock()
+
+	knownBalances := make(map[crypto.Hash]bool)
+	knownBalances[crypto.ZEROHASH], err = w.isZeroBalance(result)
+	if err != nil {
+		return err
+	}
+
+	tokens, err := w.GetTokens(GetTokensParams{})
+	if err != nil {
+		return err
+	}
+
+	for _, token := range tokens {
+		scId := token.GetHash()
+		if _, ok := knownBalances[scId]; ok {
+			continue // the same token can be in multiple folders
+		}
+
+		tokenResult, err := w.getEncryptedBalance(scId)
+		if err != nil {
+			// the account never received the token
+			if isErrUnregistered(err) {
+				knownBalances[scId] = true
+				continue
+			}
+
+			return err
+		}
+
+		knownBalances[scId], err = w.isZeroBalance(tokenResult)
+		if err != nil {
+			return err
+		}
+	}
+
+	account.Lock()
+	if account.Balance == nil {
+		account.Balance = make(map[crypto.Hash]uint64)
+	}
+
+	for scId := range knownBalances {
+		account.Balance[scId] = 0
+	}
+	account.Balance_Mature = 0
+	account.Unlock()
+
+	w.balancesLock.Lock()
+	w.knownBalances = knownBalances
+	w.balancesLock.Unlock()
+
+	return nil
+}
+
+// replaces the walletapi sync loop until the wallet is closed
+func (w *Wallet) StartWatchOnlySync() {
+	go func() {
+		for {
+			if walletapi.Connected {
+				err := w.SyncWatchOnly()
+				if err != nil {
+					fmt.Println(err)
+				}
+			}
+
+			select {
+			case <-w.Memory.Quit:
+				return
+			case <-time.After(5 * time.Second):
+			}
+		}
+	}()
+}
+
+// returns the names owned by the wallet in the name service smart contract
+// names are stored with the raw owner address so we don't need the wallet history
+func (w *Wallet) GetRegisteredNames(nameServiceSCID crypto.Hash) ([]string, error) {
+	var result rpc.GetSC_Result
+	err := RPC_Client.RPC.CallResult(context.Background(), "DERO.GetSC", rpc.GetSC_Params{
+		SCID:      nameServiceSCID.String(),
+		Variables: true,
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	owner := hex.EncodeToString(w.Memory.GetAddress().PublicKey.EncodeCompressed())
+
+	var names []string
+	for key, value := range result.VariableStringKeys {
+		if value, ok := value.(string); ok && value == owner {
+			names = append(names, key)
+		}
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,163 @@
+package wallet_manager
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/deroproject/derohe/globals"
+	"github.com/deroproject/derohe/rpc"
+	"github.com/deroproject/derohe/walletapi"
+	"github.com/secretsystems/secret-wallet/mock_daemon"
+	"github.com/secretsystems/secret-wallet/settings"
+)
+
+func openTestWatchOnlyWallet(t *testing.T, addr string) *Wallet {
+	t.Helper()
+
+	err := CreateWatchOnlyWallet(t.Name(), "password", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = OpenWallet(addr, "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wallet := OpenedWallet
+	t.Cleanup(CloseOpenedWallet)
+	return wallet
+}
+
+func TestCreateWatchOnlyWallet(t *testing.T) {
+	addr := mock_daemon.RandomAddress()
+	wallet := openTestWatchOnlyWallet(t, addr)
+
+	if !wallet.IsWatchOnly() || wallet.Memory.GetAddress().String() != addr {
+		t.Fatalf("unexpected watch-only wallet %+v", wallet.Info)
+	}
+
+	_, _, _, err := wallet.BuildTransaction([]rpc.Transfer{{Destination: mock_daemon.RandomAddress(), Amount: 1}}, 2, nil, true)
+	if err != ErrWatchOnly {
+		t.Fatalf("expected watch-only error, got %v", err)
+	}
+
+	// the public key is kept after changing the password
+	err = wallet.ChangePassword("password", "new password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(settings.WalletsDir, addr, "wallet.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	memory, err := walletapi.Open_Encrypted_Wallet_Memory("new password", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	memory.SetNetwork(globals.IsMainnet())
+	if memory.GetAddress().String() != addr {
+		t.Fatal("address changed with the password")
+	}
+
+	tests := []struct {
+		name string
+		addr string
+	}{
+		{name: "invalid address", addr: "dero1abcd"},
+		{name: "integrated address", addr: wallet.Memory.GetRandomIAddress8().String()},
+	}
+
+	for _, test := range tests {
+		err := CreateWatchOnlyWallet(test.name, "password", test.addr)
+		if err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+	}
+}
+
+func TestSyncWatchOnly(t *testing.T) {
+	addr := mock_daemon.RandomAddress()
+	wallet := openTestWatchOnlyWallet(t, addr)
+
+	err := wallet.SyncWatchOnly()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if wallet.Memory.IsRegistered() {
+		t.Fatal("wallet should not be registered")
+	}
+
+	// registered with the zero balance of the registration
+	daemon.SetRegistration(addr, 50)
+	scId := crypto.HashHexToHash(strings.Repeat("cd", 32))
+	err = wallet.InsertToken(Token{SCID: scId.String(), Name: "Token", Decimals: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = wallet.SyncWatchOnly()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !wallet.Memory.IsRegistered() || wallet.Memory.Get_Height() != uint64(daemon.Height) {
+		t.Fatalf("wallet is not synced at height %d", wallet.Memory.Get_Height())
+	}
+
+	for _, hash := range []crypto.Hash{crypto.ZEROHASH, scId} {
+		balance, known := wallet.GetBalance(hash)
+		if !known || balance != 0 {
+			t.Fatalf("balance of %s should be a known zero", hash)
+		}
+	}
+
+	// received funds can't be decrypted without the secret key
+	daemon.SetBalance(crypto.ZEROHASH, addr, 1000)
+	err = wallet.SyncWatchOnly()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, known := wallet.GetBalance(crypto.ZEROHASH)
+	if known {
+		t.Fatal("balance should not be known")
+	}
+
+	_, known = wallet.GetBalance(scId)
+	if !known {
+		t.Fatal("token balance should still be known")
+	}
+}
+
+func TestGetRegisteredNames(t *testing.T) {
+	addr := mock_daemon.RandomAddress()
+	wallet := openTestWatchOnlyWallet(t, addr)
+
+	scId := crypto.HashHexToHash(strings.Repeat("00", 31) + "01")
+	daemon.SetSC(scId.String(), rpc.GetSC_Result{
+		VariableStringKeys: map[string]interface{}{
+			"C":      encodeTestString("Function Initialize() Uint64"),
+			"bob":    encodeTestAddress(t, addr),
+			"alice":  encodeTestAddress(t, addr),
+			"carol":  encodeTestAddress(t, mock_daemon.RandomAddress()),
+			"number": uint64(1),
+		},
+	})
+
+	names, err := wallet.GetRegisteredNames(scId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(names, ",") != "alice,bob" {
+		t.Fatalf("unexpected names %v", names)
+	}
+}
@@ -0,0 +1,523 @@
+package wallet_manager
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/creachadair/jrpc2"
+	"github.com/creachadair/jrpc2/channel"
+	"github.com/creachadair/jrpc2/handler"
+	"github.com/deroproject/derohe/glue/rwc"
+	"github.com/gorilla/websocket"
+	"github.com/secretsystems/secret-wallet/app_db"
+	"github.com/secretsystems/secret-wallet/settings"
+)
+
+// same port and path as the other dero wallets so dApps find it
+const XSWD_BIND = "127.0.0.1:44326"
+const XSWD_PATH = "/xswd"
+
+// rpc policy clients of the apps start with it so they are not mixed with the hosts of the rpc server
+const XSWD_CLIENT_PREFIX = "xswd:"
+
+// time given to an app to send its registration after connecting
+const XSWD_REGISTRATION_TIMEOUT = 10 * time.Second
+
+// permission values of the registration - same as the derohe xswd protocol
+const (
+	xswdRequestAsk = iota
+	xswdRequestAllow
+	xswdRequestDeny
+	xswdRequestAlwaysAllow
+	xswdRequestAlwaysDeny
+)
+
+// first message sent by an app
+type XSWDApplicationData struct {
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Url         string         `json:"url"`
+	Permissions map[string]int `json:"permissions"`
+	Signature   []byte         `json:"signature"`
+}
+
+// reply to the registration - the connection is closed when not accepted
+type XSWDAuthorizationResponse struct {
+	Message  string `json:"message"`
+	Accepted bool   `json:"accepted"`
+}
+
+type XSWDConnection struct {
+	App         app_db.XSWDApp
+	ConnectedAt time.Time
+}
+
+// asks the user to accept an app that is not remembered - nil rejects them
+var OnXSWDRegistration func(ctx context.Context, app app_db.XSWDApp) bool
+
+// asks the user for a method the app has to ask for
+var OnXSWDApproval func(ctx context.Context, app app_db.XSWDApp, request RPCRequest) bool
+
+// wallet methods an app can be given a permission for
+func XSWDMethods() []string {
+	return new(Wallet).rpcMethods().Names()
+}
+
+// websocket server of the dApps - every app registers first and its calls are checked against the permissions
+// remembered in app.db, the limits and the allowlist of the rpc policy apply with XSWDPolicyClient as client
+type XSWDServer struct {
+	wallet   *Wallet
+	listener net.Listener
+	server   *http.Server
+	methods  handler.Map
+
+	lock  sync.Mutex
+	conns map[string]*xswdConn
+}
+
+type xswdConn struct {
+	app         app_db.XSWDApp
+	conn        *websocket.Conn
+	connectedAt time.Time
+}
+
+func (w *Wallet) StartXSWDServer(bind string) (*XSWDServer, error) {
+	if w.IsWatchOnly() {
+		return nil, ErrWatchOnly
+	}
+
+	listener, err := net.Listen("tcp", bind)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &XSWDServer{
+		wallet:   w,
+		listener: listener,
+		methods:  w.rpcMethods(),
+		conns:    make(map[string]*xswdConn),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(XSWD_PATH, s.serveWS)
+
+	s.server = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go s.server.Serve(listener)
+
+	return s, nil
+}
+
+// starts the server when it's turned on in the settings
+func (w *Wallet) AutoStartXSWDServer() error {
+	if !settings.App.XSWD || w.IsWatchOnly() || w.XSWD != nil {
+		return nil
+	}
+
+	server, err := w.StartXSWDServer(XSWD_BIND)
+	if err != nil {
+		return err
+	}
+
+	w.XSWD = server
+	return nil
+}
+
+func (s *XSWDServer) Stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := s.server.Shutdown(ctx)
+
+	s.lock.Lock()
+	for _, c := range s.conns {
+		c.conn.Close()
+	}
+	s.lock.Unlock()
+
+	return err
+}
+
+func (s *XSWDServer) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// connected apps sorted by name
+func (s *XSWDServer) Connections() []XSWDConnection {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var connections []XSWDConnection
+	for _, c := range s.conns {
+		connections = append(connections, XSWDConnection{App: c.app, ConnectedAt: c.connectedAt})
+	}
+
+	sort.Slice(connections, func(i, j int) bool {
+		return connections[i].App.Name < connections[j].App.Name
+	})
+
+	return connections
+}
+
+func (s *XSWDServer) IsConnected(appId string) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	_, ok := s.conns[appId]
+	return ok
+}
+
+// forgets the app and closes its connection - it has to register again
+func (s *XSWDServer) Revoke(appId string) error {
+	err := app_db.DelXSWDApp(appId)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	c, ok := s.conns[appId]
+	s.lock.Unlock()
+
+	if ok {
+		c.conn.Close()
+	}
+
+	return nil
+}
+
+// saved and used by the next call of a connected app
+func (s *XSWDServer) SetPermission(appId string, method string, permission app_db.XSWDPermission) error {
+	if permission == app_db.XSWD_PERMISSION_ALWAYS && !XSWDCanAlwaysAllow(method) {
+		return fmt.Errorf("%s can't be always allowed", method)
+	}
+
+	err := app_db.SetXSWDPermission(appId, method, permission)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	c, ok := s.conns[appId]
+	if ok {
+		permissions := make(map[string]app_db.XSWDPermission)
+		for m, p := range c.app.Permissions {
+			permissions[m] = p
+		}
+
+		permissions[method] = permission
+		c.app.Permissions = permissions
+	}
+
+	return nil
+}
+
+func (s *XSWDServer) connectedApp(appId string) (app app_db.XSWDApp, ok bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	c, ok := s.conns[appId]
+	if ok {
+		app = c.app
+	}
+
+	return
+}
+
+// scheme://host of a url - the path and query are not part of the origin
+func xswdOrigin(rawUrl string) (string, error) {
+	u, err := url.Parse(rawUrl)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("invalid origin %q", rawUrl)
+	}
+
+	return strings.ToLower(u.Scheme + "://" + u.Host), nil
+}
+
+// returns the origin of the page - empty when the app is not in a browser
+func validateXSWDApp(data XSWDApplicationData, originHeader string) (origin string, err error) {
+	id, err := hex.DecodeString(data.ID)
+	if err != nil || len(id) != 32 {
+		err = fmt.Errorf("invalid application id")
+		return
+	}
+
+	if len(data.Name) == 0 || len(data.Name) > 255 {
+		err = fmt.Errorf("invalid application name")
+		return
+	}
+
+	if len(data.Description) == 0 || len(data.Description) > 255 {
+		err = fmt.Errorf("invalid application description")
+		return
+	}
+
+	if len(data.Url) > 255 {
+		err = fmt.Errorf("invalid application url")
+		return
+	}
+
+	if originHeader == "" {
+		return
+	}
+
+	// a web page can't claim to be another site
+	origin, err = xswdOrigin(originHeader)
+	if err != nil {
+		return
+	}
+
+	urlOrigin, err := xswdOrigin(data.Url)
+	if err != nil || urlOrigin != origin {
+		err = fmt.Errorf("application url does not match the origin %s", origin)
+	}
+
+	return
+}
+
+// the app name and id are picked by the app so the limits and the daily spending are kept for the verified origin
+// of the page - the apps outside a browser have nothing to verify and share one client
+func XSWDPolicyClient(app app_db.XSWDApp) string {
+	if app.Origin == "" {
+		return XSWD_CLIENT_PREFIX + "local"
+	}
+
+	return XSWD_CLIENT_PREFIX + app.Origin
+}
+
+// methods that can spend or reveal the seed are asked every time they are not denied
+func XSWDCanAlwaysAllow(method string) bool {
+	return RPCReadMethods[method]
+}
+
+// the app only gets what the user would give without a prompt - reads it asked to always allow and the denials
+func newXSWDApp(data XSWDApplicationData, methods handler.Map) app_db.XSWDApp {
+	now := time.Now().Unix()
+	app := app_db.XSWDApp{
+		ID:                data.ID,
+		Name:              data.Name,
+		Description:       data.Description,
+		Url:               data.Url,
+		Permissions:       make(map[string]app_db.XSWDPermission),
+		Timestamp:         now,
+		LastSeenTimestamp: now,
+	}
+
+	for method, requested := range data.Permissions {
+		method = RPCMethodName(method)
+		if methods[method] == nil {
+			continue
+		}
+
+		switch requested {
+		case xswdRequestAlwaysAllow:
+			if XSWDCanAlwaysAllow(method) {
+				app.Permissions[method] = app_db.XSWD_PERMISSION_ALWAYS
+			}
+		case xswdRequestDeny, xswdRequestAlwaysDeny:
+			app.Permissions[method] = app_db.XSWD_PERMISSION_DENY
+		}
+	}
+
+	return app
+}
+
+// a remembered app is accepted without asking again only from the same web page - the id, name and url are public
+// so an app without an origin or from another one is asked again and gets the permissions it requests
+func (s *XSWDServer) register(data XSWDApplicationData, originHeader string) (app app_db.XSWDApp, err error) {
+	origin, err := validateXSWDApp(data, originHeader)
+	if err != nil {
+		return
+	}
+
+	if s.IsConnected(data.ID) {
+		err = fmt.Errorf("application is already connected")
+		return
+	}
+
+	app, found, err := app_db.GetXSWDApp(data.ID)
+	if err != nil {
+		return
+	}
+
+	if found && origin != "" && app.Origin == origin && app.Name == data.Name && app.Url == data.Url {
+		app.LastSeenTimestamp = time.Now().Unix()
+		err = app_db.UpdateXSWDAppLastSeen(app.ID, app.LastSeenTimestamp)
+		return
+	}
+
+	app = newXSWDApp(data, s.methods)
+	app.Origin = origin
+
+	var ask func(ctx context.Context) bool
+	if OnXSWDRegistration != nil {
+		ask = func(ctx context.Context) bool { return OnXSWDRegistration(ctx, app) }
+	}
+
+	err = askRPCApproval(context.Background(), ask)
+	if err != nil {
+		return
+	}
+
+	err = app_db.StoreXSWDApp(app)
+	return
+}
+
+var xswdUpgrader = websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }}
+
+func (s *XSWDServer) serveWS(rw http.ResponseWriter, r *http.Request) {
+	conn, err := xswdUpgrader.Upgrade(rw, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	var data XSWDApplicationData
+	conn.SetReadDeadline(time.Now().Add(XSWD_REGISTRATION_TIMEOUT))
+	err = conn.ReadJSON(&data)
+	if err != nil {
+		return
+	}
+	conn.SetReadDeadline(time.Time{})
+
+	app, err := s.register(data, r.Header.Get("Origin"))
+	if err != nil {
+		conn.WriteJSON(XSWDAuthorizationResponse{Message: err.Error(), Accepted: false})
+		return
+	}
+
+	s.lock.Lock()
+	// the same app could have registered during the prompt
+	_, connected := s.conns[app.ID]
+	if !connected {
+		s.conns[app.ID] = &xswdConn{app: app, conn: conn, connectedAt: time.Now()}
+	}
+	s.lock.Unlock()
+
+	if connected {
+		conn.WriteJSON(XSWDAuthorizationResponse{Message: "application is already connected", Accepted: false})
+		return
+	}
+
+	defer func() {
+		s.lock.Lock()
+		delete(s.conns, app.ID)
+		s.lock.Unlock()
+	}()
+
+	err = conn.WriteJSON(XSWDAuthorizationResponse{Message: "User has authorized the application", Accepted: true})
+	if err != nil {
+		return
+	}
+
+	inputOutput := rwc.New(conn)
+	server := jrpc2.NewServer(xswdAssigner{server: s, appId: app.ID}, nil).Start(channel.RawJSON(inputOutput, inputOutput))
+	server.Wait()
+}
+
+// implements jrpc2.Assigner for one app - DERO. methods are sent to the connected node
+type xswdAssigner struct {
+	server *XSWDServer
+	appId  string
+}
+
+func (a xswdAssigner) Assign(ctx context.Context, method string) jrpc2.Handler {
+	if strings.HasPrefix(method, "DERO.") {
+		return handler.Func(xswdDaemonCall)
+	}
+
+	if method == "HasMethod" {
+		return handler.New(func(ctx context.Context, p struct {
+			Name string `json:"name"`
+		}) bool {
+			return a.server.methods[RPCMethodName(p.Name)] != nil
+		})
+	}
+
+	name := RPCMethodName(method)
+	h, ok := a.server.methods[name]
+	if !ok {
+		return nil
+	}
+
+	return handler.Func(func(ctx context.Context, req *jrpc2.Request) (result interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic occured. stack trace %s", debug.Stack())
+			}
+		}()
+
+		return a.server.serve(ctx, a.appId, name, req, h)
+	})
+}
+
+func (a xswdAssigner) Names() []string {
+	return append(a.server.methods.Names(), "HasMethod")
+}
+
+func xswdDaemonCall(ctx context.Context, req *jrpc2.Request) (interface{}, error) {
+	client := RPC_Client.RPC
+	if client == nil {
+		return nil, ErrNoNode
+	}
+
+	var params json.RawMessage
+	if req.HasParams() {
+		err := req.UnmarshalParams(&params)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var result json.RawMessage
+	if params == nil {
+		err := client.CallResult(ctx, req.Method(), nil, &result)
+		return result, err
+	}
+
+	err := client.CallResult(ctx, req.Method(), params, &result)
+	return result, err
+}
+
+// the permission is read on every call so a change applies right away
+func (s *XSWDServer) serve(ctx context.Context, appId string, method string, req *jrpc2.Request, h jrpc2.Handler) (interface{}, error) {
+	app, ok := s.connectedApp(appId)
+	if !ok {
+		return nil, fmt.Errorf("%w: application was revoked", ErrRPCDenied)
+	}
+
+	return s.wallet.serveRPCCall(ctx, XSWDPolicyClient(app), method, req, h, func(ctx context.Context, request RPCRequest) error {
+		app, ok := s.connectedApp(appId)
+		if !ok {
+			return fmt.Errorf("%w: application was revoked", ErrRPCDenied)
+		}
+
+		switch app.Permission(method) {
+		case app_db.XSWD_PERMISSION_ALWAYS:
+			// an app.db edited by hand can't skip the prompt of a transfer
+			if XSWDCanAlwaysAllow(method) {
+				return nil
+			}
+		case app_db.XSWD_PERMISSION_DENY:
+			return fmt.Errorf("%w: %s is denied for this application", ErrRPCDenied, method)
+		}
+
+		var ask func(ctx context.Context) bool
+		if OnXSWDApproval != nil {
+			ask = func(ctx context.Context) bool { return OnXSWDApproval(ctx, app, request) }
+		}
+
+		return askRPCApproval(ctx, ask)
+	})
+}
@@ -0,0 +1,317 @@
+package wallet_manager
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/creachadair/jrpc2"
+	"github.com/creachadair/jrpc2/channel"
+	"github.com/deroproject/derohe/cryptography/crypto"
+	"github.com/deroproject/derohe/glue/rwc"
+	"github.com/deroproject/derohe/rpc"
+	"github.com/gorilla/websocket"
+	"github.com/secretsystems/secret-wallet/app_db"
+)
+
+func newTestXSWDApp() XSWDApplicationData {
+	return XSWDApplicationData{
+		ID:          "a1b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7f8a9b0c1d2e3f4a5b6c7d8e9f0a1b2",
+		Name:        "dApp",
+		Description: "test app",
+		Url:         "http://localhost:8080",
+		Permissions: map[string]int{
+			"GetAddress": xswdRequestAlwaysAllow,
+			"Transfer":   xswdRequestAlwaysAllow,
+			"QueryKey":   xswdRequestDeny,
+		},
+	}
+}
+
+// registers the app and returns a client on the connection when accepted
+func connectTestXSWD(t *testing.T, server *XSWDServer, data XSWDApplicationData, origin string) (*jrpc2.Client, XSWDAuthorizationResponse) {
+	t.Helper()
+
+	header := http.Header{}
+	if origin != "" {
+		header.Set("Origin", origin)
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws://"+server.Addr()+XSWD_PATH, header)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = conn.WriteJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var response XSWDAuthorizationResponse
+	err = conn.ReadJSON(&response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !response.Accepted {
+		conn.Close()
+		return nil, response
+	}
+
+	inputOutput := rwc.New(conn)
+	client := jrpc2.NewClient(channel.RawJSON(inputOutput, inputOutput), nil)
+	t.Cleanup(func() {
+		conn.Close()
+		client.Close()
+	})
+
+	return client, response
+}
+
+func setTestXSWDRegistration(t *testing.T, accept bool) *int {
+	t.Helper()
+
+	count := 0
+	OnXSWDRegistration = func(ctx context.Context, app app_db.XSWDApp) bool {
+		count++
+		return accept
+	}
+
+	t.Cleanup(func() { OnXSWDRegistration = nil })
+	return &count
+}
+
+func TestXSWDServer(t *testing.T) {
+	wallet := openTestWallet(t, 100000)
+	server, err := wallet.StartXSWDServer("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { server.Stop() })
+
+	data := newTestXSWDApp()
+	ctx := context.Background()
+
+	expectRejected := func(response XSWDAuthorizationResponse, reason string) {
+		t.Helper()
+		if response.Accepted || !strings.Contains(response.Message, reason) {
+			t.Fatalf("expected rejected [%s] got %+v", reason, response)
+		}
+	}
+
+	// no one to ask
+	_, response := connectTestXSWD(t, server, data, "")
+	expectRejected(response, "approvals are not available")
+
+	invalid := newTestXSWDApp()
+	invalid.ID = "abc"
+	_, response = connectTestXSWD(t, server, invalid, "")
+	expectRejected(response, "invalid application id")
+
+	registrations := setTestXSWDRegistration(t, true)
+
+	_, response = connectTestXSWD(t, server, data, "http://evil.example")
+	expectRejected(response, "does not match the origin")
+
+	// the url only starts with the origin
+	_, response = connectTestXSWD(t, server, data, "http://localhost:80")
+	expectRejected(response, "does not match the origin")
+
+	client, response := connectTestXSWD(t, server, data, "http://localhost:8080")
+	if !response.Accepted || *registrations != 1 {
+		t.Fatalf("app was not accepted %+v", response)
+	}
+
+	_, response = connectTestXSWD(t, server, data, "")
+	expectRejected(response, "already connected")
+
+	// only the reads and the denials requested by the app are kept
+	app, found, err := app_db.GetXSWDApp(data.ID)
+	if err != nil || !found {
+		t.Fatalf("app was not stored %v", err)
+	}
+
+	if app.Origin != "http://localhost:8080" {
+		t.Fatalf("unexpected origin %s", app.Origin)
+	}
+
+	if app.Permission("GetAddress") != app_db.XSWD_PERMISSION_ALWAYS ||
+		app.Permission("Transfer") != app_db.XSWD_PERMISSION_ASK ||
+		app.Permission("QueryKey") != app_db.XSWD_PERMISSION_DENY {
+		t.Fatalf("unexpected permissions %+v", app.Permissions)
+	}
+
+	connections := server.Connections()
+	if len(connections) != 1 || connections[0].App.ID != data.ID {
+		t.Fatalf("unexpected connections %+v", connections)
+	}
+
+	var addrResult rpc.GetAddress_Result
+	err = client.CallResult(ctx, "GetAddress", nil, &addrResult)
+	if err != nil || addrResult.Address != wallet.Info.Addr {
+		t.Fatalf("unexpected address %+v %v", addrResult, err)
+	}
+
+	var infoResult rpc.GetInfo_Result
+	err = client.CallResult(ctx, "DERO.GetInfo", nil, &infoResult)
+	if err != nil || infoResult.TopoHeight != int64(daemon.Height) {
+		t.Fatalf("unexpected node info %+v %v", infoResult, err)
+	}
+
+	var hasMethod bool
+	err = client.CallResult(ctx, "HasMethod", map[string]string{"name": "SC_Invoke"}, &hasMethod)
+	if err != nil || !hasMethod {
+		t.Fatalf("SC_Invoke is missing %v", err)
+	}
+
+	var keyResult rpc.Query_Key_Result
+	err = client.CallResult(ctx, "QueryKey", rpc.Query_Key_Params{Key_type: "mnemonic"}, &keyResult)
+	if err == nil || !strings.Contains(err.Error(), "denied for this application") {
+		t.Fatalf("expected denied got %v", err)
+	}
+
+	transfer := func() error {
+		var result rpc.Transfer_Result
+		return client.CallResult(ctx, "Transfer", rpc.Transfer_Params{
+			Transfers: []rpc.Transfer{{Destination: newTestDestination(crypto.ZEROHASH), Amount: 1}},
+			Ringsize:  2,
+		}, &result)
+	}
+
+	err = transfer()
+	if err == nil || !strings.Contains(err.Error(), "approvals are not available") {
+		t.Fatalf("expected denied got %v", err)
+	}
+
+	approvals := 0
+	OnXSWDApproval = func(ctx context.Context, app app_db.XSWDApp, request RPCRequest) bool {
+		approvals++
+		return request.Client == "xswd:http://localhost:8080"
+	}
+	t.Cleanup(func() { OnXSWDApproval = nil })
+
+	err = transfer()
+	if err != nil || approvals != 1 {
+		t.Fatalf("transfer failed %d %v", approvals, err)
+	}
+
+	err = server.SetPermission(data.ID, "Transfer", app_db.XSWD_PERMISSION_ALWAYS)
+	if err == nil {
+		t.Fatal("transfer was always allowed")
+	}
+
+	// a new permission is used by the next call
+	err = server.SetPermission(data.ID, "Transfer", app_db.XSWD_PERMISSION_DENY)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = transfer()
+	if err == nil || approvals != 1 {
+		t.Fatalf("expected denied got %d %v", approvals, err)
+	}
+
+	entries, err := wallet.GetRPCLog(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, entry := range entries {
+		if entry.Client != "xswd:http://localhost:8080" {
+			t.Fatalf("unexpected log entry %+v", entry)
+		}
+	}
+
+	// a revoked app is disconnected and asked again
+	err = server.Revoke(data.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, found, err = app_db.GetXSWDApp(data.ID)
+	if err != nil || found {
+		t.Fatalf("app was not revoked %v", err)
+	}
+
+	for i := 0; server.IsConnected(data.ID); i++ {
+		if i == 50 {
+			t.Fatal("app is still connected")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	_, response = connectTestXSWD(t, server, data, "")
+	if !response.Accepted || *registrations != 2 {
+		t.Fatalf("app was not registered again %d %+v", *registrations, response)
+	}
+}
+
+// the id, name and url of a remembered app are public - only its page skips the prompt
+func TestXSWDRememberedOrigin(t *testing.T) {
+	wallet := openTestWallet(t, 100000)
+	server, err := wallet.StartXSWDServer("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { server.Stop() })
+
+	data := newTestXSWDApp()
+	registrations := setTestXSWDRegistration(t, true)
+
+	reconnect := func(origin string, expected int) {
+		t.Helper()
+
+		_, response := connectTestXSWD(t, server, data, origin)
+		if !response.Accepted || *registrations != expected {
+			t.Fatalf("unexpected registrations %d %+v", *registrations, response)
+		}
+
+		server.lock.Lock()
+		server.conns[data.ID].conn.Close()
+		server.lock.Unlock()
+
+		for i := 0; server.IsConnected(data.ID); i++ {
+			if i == 50 {
+				t.Fatal("app is still connected")
+			}
+
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	reconnect("http://localhost:8080", 1)
+	reconnect("http://LOCALHOST:8080", 1)
+
+	// not from a web page
+	reconnect("", 2)
+
+	app, _, err := app_db.GetXSWDApp(data.ID)
+	if err != nil || app.Origin != "" {
+		t.Fatalf("unexpected origin %s %v", app.Origin, err)
+	}
+
+	// without an origin it's asked every time
+	reconnect("", 3)
+	reconnect("http://localhost:8080", 4)
+	reconnect("http://localhost:8080", 4)
+}
+
+func TestXSWDPolicyClient(t *testing.T) {
+	app := app_db.XSWDApp{ID: newTestXSWDApp().ID, Name: "dApp", Origin: "https://dapp.example"}
+
+	// another name or id from the same page keeps the limits and the daily spending
+	other := app
+	other.ID = strings.Repeat("ab", 32)
+	other.Name = "trusted app"
+	if XSWDPolicyClient(other) != XSWDPolicyClient(app) || XSWDPolicyClient(app) != "xswd:https://dapp.example" {
+		t.Fatalf("unexpected clients %s %s", XSWDPolicyClient(app), XSWDPolicyClient(other))
+	}
+
+	local := app_db.XSWDApp{ID: app.ID, Name: "dApp"}
+	if XSWDPolicyClient(local) != "xswd:local" {
+		t.Fatalf("unexpected local client %s", XSWDPolicyClient(local))
+	}
+}